@@ -11,6 +11,8 @@ import (
 	abci "github.com/cometbft/cometbft/abci/types"
 	cmtproto "github.com/cometbft/cometbft/api/cometbft/types/v1"
 	"github.com/cosmos/gogoproto/proto"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc/codes"
 	grpcstatus "google.golang.org/grpc/status"
 
@@ -501,8 +503,10 @@ func (app *BaseApp) ProcessProposal(req *abci.ProcessProposalRequest) (resp *abc
 	// processed the first block, as we want to avoid overwriting the finalizeState
 	// after state changes during InitChain.
 	if req.Height > app.initialHeight {
-		// abort any running OE
+		// abort any running OE, and forget the aborted executions so that the
+		// proposal is executed again if it is processed again
 		app.optimisticExec.Abort()
+		app.optimisticExec.Reset()
 		app.setState(execModeFinalize, header)
 	}
 
@@ -722,9 +726,15 @@ func (app *BaseApp) VerifyVoteExtension(req *abci.VerifyVoteExtensionRequest) (r
 // Execution flow or by the FinalizeBlock ABCI method. The context received is
 // only used to handle early cancellation, for anything related to state app.finalizeBlockState.Context()
 // must be used.
-func (app *BaseApp) internalFinalizeBlock(ctx context.Context, req *abci.FinalizeBlockRequest) (*abci.FinalizeBlockResponse, error) {
+func (app *BaseApp) internalFinalizeBlock(ctx context.Context, req *abci.FinalizeBlockRequest) (_ *abci.FinalizeBlockResponse, err error) {
 	var events []abci.Event
 
+	ctx, span := telemetry.StartSpan(ctx, "FinalizeBlock",
+		attribute.Int64("height", req.Height),
+		attribute.Int("txs", len(req.Txs)),
+	)
+	defer func() { telemetry.EndSpan(span, err) }()
+
 	if err := app.checkHalt(req.Height, req.Time); err != nil {
 		return nil, err
 	}
@@ -776,6 +786,10 @@ func (app *BaseApp) internalFinalizeBlock(ctx context.Context, req *abci.Finaliz
 			LastCommit:      sdk.ToSDKCommitInfo(req.DecidedLastCommit),
 		}))
 
+	// the spans of the block are children of the FinalizeBlock span
+	app.finalizeBlockState.SetContext(app.finalizeBlockState.Context().
+		WithContext(trace.ContextWithSpan(app.finalizeBlockState.Context().Context(), span)))
+
 	// GasMeter must be set after we get a context with updated consensus params.
 	gasMeter := app.getBlockGasMeter(app.finalizeBlockState.Context())
 	app.finalizeBlockState.SetContext(app.finalizeBlockState.Context().WithBlockGasMeter(gasMeter))
@@ -887,8 +901,12 @@ func (app *BaseApp) FinalizeBlock(req *abci.FinalizeBlockRequest) (res *abci.Fin
 	defer func() {
 		// call the streaming service hooks with the FinalizeBlock messages
 		for _, streamingListener := range app.streamingManager.ABCIListeners {
-			if err := streamingListener.ListenFinalizeBlock(app.finalizeBlockState.Context(), *req, *res); err != nil {
-				app.logger.Error("ListenFinalizeBlock listening hook failed", "height", req.Height, "err", err)
+			if listenErr := streamingListener.ListenFinalizeBlock(app.finalizeBlockState.Context(), *req, *res); listenErr != nil {
+				app.logger.Error("ListenFinalizeBlock listening hook failed", "height", req.Height, "err", listenErr)
+				if stopNodeOnErr(streamingListener) {
+					res, err = nil, fmt.Errorf("ListenFinalizeBlock listening hook failed: %w", listenErr)
+					return
+				}
 			}
 		}
 	}()
@@ -896,11 +914,10 @@ func (app *BaseApp) FinalizeBlock(req *abci.FinalizeBlockRequest) (res *abci.Fin
 	if app.optimisticExec.Initialized() {
 		// check if the hash we got is the same as the one we are executing
 		aborted := app.optimisticExec.AbortIfNeeded(req.Hash)
-		// Wait for the OE to finish, regardless of whether it was aborted or not
-		res, err = app.optimisticExec.WaitResult()
 
 		// only return if we are not aborting
 		if !aborted {
+			res, err = app.optimisticExec.WaitResult()
 			if res != nil {
 				res.AppHash = app.workingHash()
 			}
@@ -908,7 +925,9 @@ func (app *BaseApp) FinalizeBlock(req *abci.FinalizeBlockRequest) (res *abci.Fin
 			return res, err
 		}
 
-		// if it was aborted, we need to reset the state
+		// if it was aborted, we need to wait for the OE writing to the FinalizeBlock
+		// state to finish, and reset the state
+		app.optimisticExec.Abort()
 		app.finalizeBlockState = nil
 		app.optimisticExec.Reset()
 	}
@@ -960,7 +979,9 @@ func (app *BaseApp) Commit() (*abci.CommitResponse, error) {
 		rms.SetCommitHeader(header)
 	}
 
+	_, span := telemetry.StartSpan(app.finalizeBlockState.Context().Context(), "store.commit", attribute.Int64("height", header.Height))
 	app.cms.Commit()
+	span.End()
 
 	resp := &abci.CommitResponse{
 		RetainHeight: retainHeight,
@@ -975,6 +996,9 @@ func (app *BaseApp) Commit() (*abci.CommitResponse, error) {
 		for _, abciListener := range abciListeners {
 			if err := abciListener.ListenCommit(ctx, *resp, changeSet); err != nil {
 				app.logger.Error("Commit listening hook failed", "height", blockHeight, "err", err)
+				if stopNodeOnErr(abciListener) {
+					return nil, fmt.Errorf("commit listening hook failed: %w", err)
+				}
 			}
 		}
 	}
@@ -1006,10 +1030,12 @@ func (app *BaseApp) workingHash() []byte {
 	// Write the FinalizeBlock state into branched storage and commit the MultiStore.
 	// The write to the FinalizeBlock state writes all state transitions to the root
 	// MultiStore (app.cms) so when Commit() is called it persists those values.
+	_, span := telemetry.StartSpan(app.finalizeBlockState.Context().Context(), "store.write")
 	app.finalizeBlockState.ms.Write()
 
 	// Get the hash of all writes in order to return the apphash to the comet in finalizeBlock.
 	commitHash := app.cms.WorkingHash()
+	span.End()
 	app.logger.Debug("hash of all writes", "workingHash", fmt.Sprintf("%X", commitHash))
 
 	return commitHash
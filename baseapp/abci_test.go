@@ -36,6 +36,7 @@ import (
 	"cosmossdk.io/x/auth/signing"
 
 	"github.com/cosmos/cosmos-sdk/baseapp"
+	"github.com/cosmos/cosmos-sdk/baseapp/oe"
 	baseapptestutil "github.com/cosmos/cosmos-sdk/baseapp/testutil"
 	"github.com/cosmos/cosmos-sdk/baseapp/testutil/mock"
 	cryptocodec "github.com/cosmos/cosmos-sdk/crypto/codec"
@@ -632,6 +633,39 @@ func TestABCI_CheckTx(t *testing.T) {
 	require.Nil(t, storedBytes)
 }
 
+func TestABCI_CheckTxDecoder(t *testing.T) {
+	anteKey := []byte("ante-key")
+	errRejected := errors.New("rejected by the check tx decoder")
+	suite := NewBaseAppSuite(t,
+		func(bapp *baseapp.BaseApp) { bapp.SetAnteHandler(anteHandlerTxTest(t, capKey1, anteKey)) },
+		func(bapp *baseapp.BaseApp) {
+			bapp.SetCheckTxDecoder(func([]byte) (sdk.Tx, error) { return nil, errRejected })
+		},
+	)
+	baseapptestutil.RegisterCounterServer(suite.baseApp.MsgServiceRouter(), CounterServerImpl{t, capKey1, []byte("deliver-key")})
+
+	_, err := suite.baseApp.InitChain(&abci.InitChainRequest{
+		ConsensusParams: &cmtproto.ConsensusParams{},
+	})
+	require.NoError(t, err)
+
+	txBytes, err := suite.txConfig.TxEncoder()(newTxCounter(t, suite.txConfig, 0, 0))
+	require.NoError(t, err)
+
+	r, err := suite.baseApp.CheckTx(&abci.CheckTxRequest{Tx: txBytes, Type: abci.CHECK_TX_TYPE_CHECK})
+	require.NoError(t, err)
+	require.False(t, r.IsOK())
+	require.Contains(t, r.Log, errRejected.Error())
+
+	// the transactions of blocks are decoded with the TxDecoder
+	res, err := suite.baseApp.FinalizeBlock(&abci.FinalizeBlockRequest{
+		Height: 1,
+		Txs:    [][]byte{txBytes},
+	})
+	require.NoError(t, err)
+	require.True(t, res.TxResults[0].IsOK(), fmt.Sprintf("%v", res))
+}
+
 func TestABCI_FinalizeBlock_DeliverTx(t *testing.T) {
 	anteKey := []byte("ante-key")
 	anteOpt := func(bapp *baseapp.BaseApp) { bapp.SetAnteHandler(anteHandlerTxTest(t, capKey1, anteKey)) }
@@ -2411,6 +2445,54 @@ func TestOptimisticExecution(t *testing.T) {
 	require.Equal(t, int64(50), suite.baseApp.LastBlockHeight())
 }
 
+func TestOptimisticExecutionMaxCandidates(t *testing.T) {
+	app := baseapp.NewBaseApp(t.Name(), log.NewTestLogger(t), dbm.NewMemDB(), nil, baseapp.SetOptimisticExecution(oe.WithMaxCandidates(2)))
+	require.EqualError(t, app.LoadLatestVersion(), "BaseApp executes a single optimistic execution candidate, 2 requested")
+
+	NewBaseAppSuite(t, baseapp.SetOptimisticExecution(oe.WithMaxCandidates(1)))
+}
+
+func TestOptimisticExecutionReprocessedProposal(t *testing.T) {
+	suite := NewBaseAppSuite(t, baseapp.SetOptimisticExecution())
+	baseapptestutil.RegisterKeyValueServer(suite.baseApp.MsgServiceRouter(), MsgKeyValueImpl{})
+
+	_, err := suite.baseApp.InitChain(&abci.InitChainRequest{
+		ConsensusParams: &cmtproto.ConsensusParams{},
+	})
+	require.NoError(t, err)
+
+	// the first block isn't executed optimistically
+	_, err = suite.baseApp.FinalizeBlock(&abci.FinalizeBlockRequest{Height: 1})
+	require.NoError(t, err)
+	_, err = suite.baseApp.Commit()
+	require.NoError(t, err)
+
+	_, _, addr := testdata.KeyTestPubAddr()
+	builder := suite.txConfig.NewTxBuilder()
+	require.NoError(t, builder.SetMsgs(&baseapptestutil.MsgKeyValue{Key: []byte("key"), Value: []byte("value"), Signer: addr.String()}))
+	setTxSignature(t, builder, 0)
+	txBytes, err := suite.txConfig.TxEncoder()(builder.GetTx())
+	require.NoError(t, err)
+
+	// the proposal is processed again in a later round, which aborts its execution and
+	// resets the state: it must be executed again instead of reusing the aborted execution
+	reqProcProp := abci.ProcessProposalRequest{Txs: [][]byte{txBytes}, Height: 2, Hash: []byte("hash")}
+	for i := 0; i < 2; i++ {
+		resp, err := suite.baseApp.ProcessProposal(&reqProcProp)
+		require.NoError(t, err)
+		require.Equal(t, abci.PROCESS_PROPOSAL_STATUS_ACCEPT, resp.Status)
+	}
+
+	res, err := suite.baseApp.FinalizeBlock(&abci.FinalizeBlockRequest{Txs: reqProcProp.Txs, Height: 2, Hash: reqProcProp.Hash})
+	require.NoError(t, err)
+	require.Len(t, res.TxResults, 1)
+	require.Equal(t, uint32(0), res.TxResults[0].Code)
+	_, err = suite.baseApp.Commit()
+	require.NoError(t, err)
+
+	require.Equal(t, []byte("value"), suite.baseApp.CommitMultiStore().GetKVStore(capKey2).Get([]byte("key")))
+}
+
 func TestABCI_Proposal_FailReCheckTx(t *testing.T) {
 	pool := mempool.NewPriorityMempool[int64](mempool.PriorityNonceMempoolConfig[int64]{
 		TxPriority:      mempool.NewDefaultTxPriority(),
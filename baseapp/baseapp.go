@@ -14,6 +14,7 @@ import (
 	"github.com/cometbft/cometbft/crypto/tmhash"
 	dbm "github.com/cosmos/cosmos-db"
 	"github.com/cosmos/gogoproto/proto"
+	"go.opentelemetry.io/otel/attribute"
 	"golang.org/x/exp/maps"
 	"google.golang.org/protobuf/reflect/protoreflect"
 
@@ -27,6 +28,7 @@ import (
 	storetypes "cosmossdk.io/store/types"
 
 	"github.com/cosmos/cosmos-sdk/baseapp/oe"
+	"github.com/cosmos/cosmos-sdk/baseapp/streamqueue"
 	"github.com/cosmos/cosmos-sdk/codec"
 	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
 	servertypes "github.com/cosmos/cosmos-sdk/server/types"
@@ -58,6 +60,29 @@ const (
 	execModeFinalize                            // Finalize a block proposal
 )
 
+func (m execMode) String() string {
+	switch m {
+	case execModeCheck:
+		return "check"
+	case execModeReCheck:
+		return "recheck"
+	case execModeSimulate:
+		return "simulate"
+	case execModePrepareProposal:
+		return "prepare_proposal"
+	case execModeProcessProposal:
+		return "process_proposal"
+	case execModeVoteExtension:
+		return "vote_extension"
+	case execModeVerifyVoteExtension:
+		return "verify_vote_extension"
+	case execModeFinalize:
+		return "finalize"
+	default:
+		return strconv.Itoa(int(m))
+	}
+}
+
 var _ servertypes.ABCI = (*BaseApp)(nil)
 
 // BaseApp reflects the ABCI application implementation.
@@ -74,6 +99,7 @@ type BaseApp struct {
 	msgServiceRouter  *MsgServiceRouter           // router for redirecting Msg service messages
 	interfaceRegistry codectypes.InterfaceRegistry
 	txDecoder         sdk.TxDecoder // unmarshal []byte into sdk.Tx
+	checkTxDecoder    sdk.TxDecoder // optional decoder of the transactions received by CheckTx
 	txEncoder         sdk.TxEncoder // marshal sdk.Tx into []byte
 
 	mempool     mempool.Mempool // application side mempool
@@ -178,6 +204,10 @@ type BaseApp struct {
 	// streamingManager for managing instances and configuration of ABCIListener services
 	streamingManager storetypes.StreamingManager
 
+	// streamingQueue is the durable queue between the app and the ABCIListener services,
+	// when enabled.
+	streamingQueue *streamqueue.Queue
+
 	chainID string
 
 	cdc codec.Codec
@@ -429,6 +459,10 @@ func (app *BaseApp) Init() error {
 		return errors.New("commit multi-store must not be nil")
 	}
 
+	if app.optimisticExec != nil && app.optimisticExec.MaxCandidates() > 1 {
+		return fmt.Errorf("BaseApp executes a single optimistic execution candidate, %d requested", app.optimisticExec.MaxCandidates())
+	}
+
 	emptyHeader := cmtproto.Header{ChainID: app.chainID}
 
 	// needed for the export command which inits from store but never calls initchain
@@ -705,10 +739,20 @@ func (app *BaseApp) cacheTxContext(ctx sdk.Context, txBytes []byte) (sdk.Context
 	return ctx.WithMultiStore(msCache), msCache
 }
 
-func (app *BaseApp) preBlock(req *abci.FinalizeBlockRequest) error {
+// writeCache writes the store branch to its parent, in a span of the context.
+func writeCache(ctx sdk.Context, msCache storetypes.CacheMultiStore) {
+	_, span := telemetry.StartSpan(ctx.Context(), "store.write")
+	msCache.Write()
+	span.End()
+}
+
+func (app *BaseApp) preBlock(req *abci.FinalizeBlockRequest) (err error) {
 	if app.preBlocker != nil {
 		ctx := app.finalizeBlockState.Context()
-		if err := app.preBlocker(ctx, req); err != nil {
+		spanCtx, span := telemetry.StartSpan(ctx.Context(), "PreBlock")
+		defer func() { telemetry.EndSpan(span, err) }()
+
+		if err := app.preBlocker(ctx.WithContext(spanCtx), req); err != nil {
 			return err
 		}
 		// ConsensusParams can change in preblocker, so we need to
@@ -729,7 +773,10 @@ func (app *BaseApp) beginBlock(_ *abci.FinalizeBlockRequest) (sdk.BeginBlock, er
 	)
 
 	if app.beginBlocker != nil {
-		resp, err = app.beginBlocker(app.finalizeBlockState.Context())
+		ctx := app.finalizeBlockState.Context()
+		spanCtx, span := telemetry.StartSpan(ctx.Context(), "BeginBlock")
+		resp, err = app.beginBlocker(ctx.WithContext(spanCtx))
+		telemetry.EndSpan(span, err)
 		if err != nil {
 			return resp, err
 		}
@@ -791,7 +838,10 @@ func (app *BaseApp) endBlock(_ context.Context) (sdk.EndBlock, error) {
 	var endblock sdk.EndBlock
 
 	if app.endBlocker != nil {
-		eb, err := app.endBlocker(app.finalizeBlockState.Context())
+		ctx := app.finalizeBlockState.Context()
+		spanCtx, span := telemetry.StartSpan(ctx.Context(), "EndBlock")
+		eb, err := app.endBlocker(ctx.WithContext(spanCtx))
+		telemetry.EndSpan(span, err)
 		if err != nil {
 			return endblock, err
 		}
@@ -825,6 +875,20 @@ func (app *BaseApp) runTx(mode execMode, txBytes []byte) (gInfo sdk.GasInfo, res
 	var gasWanted uint64
 
 	ctx := app.getContextForTx(mode, txBytes)
+	spanCtx, span := telemetry.StartSpan(ctx.Context(), "tx", attribute.String("mode", mode.String()))
+	if span.IsRecording() {
+		span.SetAttributes(attribute.String("hash", fmt.Sprintf("%X", tmhash.Sum(txBytes))))
+		ctx = ctx.WithContext(spanCtx)
+	}
+	defer func() {
+		if span.IsRecording() {
+			span.SetAttributes(
+				attribute.Int64("gas_wanted", int64(gInfo.GasWanted)),
+				attribute.Int64("gas_used", int64(gInfo.GasUsed)),
+			)
+		}
+		telemetry.EndSpan(span, err)
+	}()
 	ms := ctx.MultiStore()
 
 	// only run the tx if there is block gas remaining
@@ -866,7 +930,11 @@ func (app *BaseApp) runTx(mode execMode, txBytes []byte) (gInfo sdk.GasInfo, res
 		defer consumeBlockGas()
 	}
 
-	tx, err := app.txDecoder(txBytes)
+	txDecoder := app.txDecoder
+	if mode == execModeCheck && app.checkTxDecoder != nil {
+		txDecoder = app.checkTxDecoder
+	}
+	tx, err := txDecoder(txBytes)
 	if err != nil {
 		return sdk.GasInfo{}, nil, nil, err
 	}
@@ -925,7 +993,7 @@ func (app *BaseApp) runTx(mode execMode, txBytes []byte) (gInfo sdk.GasInfo, res
 			return gInfo, nil, nil, err
 		}
 
-		msCache.Write()
+		writeCache(ctx, msCache)
 		anteEvents = events.ToABCIEvents()
 	}
 
@@ -981,7 +1049,7 @@ func (app *BaseApp) runTx(mode execMode, txBytes []byte) (gInfo sdk.GasInfo, res
 			// When block gas exceeds, it'll panic and won't commit the cached store.
 			consumeBlockGas()
 
-			msCache.Write()
+			writeCache(ctx, msCache)
 		}
 
 		if len(anteEvents) > 0 && (mode == execModeFinalize || mode == execModeSimulate) {
@@ -1143,6 +1211,18 @@ func (app *BaseApp) TxEncode(tx sdk.Tx) ([]byte, error) {
 func (app *BaseApp) Close() error {
 	var errs []error
 
+	// abort any running OE before closing the stores it reads
+	app.optimisticExec.Abort()
+
+	// Close app.streamingQueue first, so that no block is delivered to the listeners once
+	// the state is closed
+	if app.streamingQueue != nil {
+		app.logger.Info("Closing streaming queue")
+		if err := app.streamingQueue.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
 	// Close app.db (opened by cosmos-sdk/server/start.go call to openDB)
 	if app.db != nil {
 		app.logger.Info("Closing application.db")
@@ -0,0 +1,202 @@
+package baseapp
+
+import (
+	"context"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+	cmttypes "github.com/cometbft/cometbft/types"
+
+	"cosmossdk.io/math"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/mempool"
+)
+
+// LaneProposalHandler defines the ABCI PrepareProposal and ProcessProposal handlers
+// building blocks from the lanes of a LanedMempool.
+//
+// Lanes are filled in priority order, each with the valid transactions of its mempool, up
+// to its share of the block bytes and gas. The space a lane doesn't use is left to the
+// following lanes, so that a lane reserving space for some transactions, e.g. oracle or
+// IBC relayer transactions, is placed before a default lane matching every transaction.
+type LaneProposalHandler struct {
+	mempool    *mempool.LanedMempool
+	txVerifier ProposalTxVerifier
+	txSelector *laneTxSelector
+	handler    *DefaultProposalHandler
+}
+
+// NewLaneProposalHandler creates the proposal handlers of the lanes of the mempool.
+func NewLaneProposalHandler(mp *mempool.LanedMempool, txVerifier ProposalTxVerifier) *LaneProposalHandler {
+	txSelector := &laneTxSelector{}
+	handler := NewDefaultProposalHandler(mp, txVerifier)
+	handler.SetTxSelector(txSelector)
+	return &LaneProposalHandler{
+		mempool:    mp,
+		txVerifier: txVerifier,
+		txSelector: txSelector,
+		handler:    handler,
+	}
+}
+
+// PrepareProposalHandler returns the PrepareProposal handler filling the lanes in
+// priority order. The transactions of every lane are selected as by the default handler.
+func (h *LaneProposalHandler) PrepareProposalHandler() sdk.PrepareProposalHandler {
+	return func(ctx sdk.Context, req *abci.PrepareProposalRequest) (*abci.PrepareProposalResponse, error) {
+		maxTxBytes := uint64(req.MaxTxBytes)
+		maxBlockGas := blockMaxGas(ctx)
+
+		defer h.txSelector.Clear()
+
+		for _, lane := range h.mempool.Lanes() {
+			h.txSelector.startLane(laneLimit(lane.MaxBlockSpace, maxTxBytes), laneLimit(lane.MaxBlockSpace, maxBlockGas))
+			if err := h.handler.selectMempoolTxs(ctx, lane.Mempool, req.Txs, maxTxBytes, maxBlockGas); err != nil {
+				return nil, err
+			}
+		}
+
+		return &abci.PrepareProposalResponse{Txs: h.txSelector.SelectedTxs(ctx)}, nil
+	}
+}
+
+// ProcessProposalHandler returns the ProcessProposal handler verifying that a proposal
+// respects the lane rules. Besides being valid, the transactions must:
+//
+// 1. Match a lane.
+// 2. Be ordered by lane, in priority order.
+// 3. Not exceed the share of the block bytes and gas of their lane. The block bytes are the
+// MaxTxBytes given to PrepareProposal, see proposalMaxTxBytes.
+func (h *LaneProposalHandler) ProcessProposalHandler() sdk.ProcessProposalHandler {
+	return func(ctx sdk.Context, req *abci.ProcessProposalRequest) (*abci.ProcessProposalResponse, error) {
+		maxTxBytes := proposalMaxTxBytes(ctx, req)
+		maxBlockGas := blockMaxGas(ctx)
+
+		reject := &abci.ProcessProposalResponse{Status: abci.PROCESS_PROPOSAL_STATUS_REJECT}
+		lanes := h.mempool.Lanes()
+		var (
+			currentLane                        int
+			laneTxBytes, laneTxGas, totalTxGas uint64
+		)
+		for _, txBz := range req.Txs {
+			tx, err := h.txVerifier.ProcessProposalVerifyTx(txBz)
+			if err != nil {
+				return reject, nil
+			}
+
+			i, ok := h.mempool.LaneIndex(ctx, tx)
+			if !ok || i < currentLane {
+				return reject, nil
+			}
+			if i > currentLane {
+				currentLane, laneTxBytes, laneTxGas = i, 0, 0
+			}
+
+			laneTxBytes += uint64(cmttypes.ComputeProtoSizeForTxs([]cmttypes.Tx{txBz}))
+			if maxTxBytes > 0 && laneTxBytes > laneLimit(lanes[i].MaxBlockSpace, maxTxBytes) {
+				return reject, nil
+			}
+
+			if maxBlockGas > 0 {
+				txGas := txGasLimit(tx)
+				laneTxGas += txGas
+				totalTxGas += txGas
+				if laneTxGas > laneLimit(lanes[i].MaxBlockSpace, maxBlockGas) || totalTxGas > maxBlockGas {
+					return reject, nil
+				}
+			}
+		}
+
+		return &abci.ProcessProposalResponse{Status: abci.PROCESS_PROPOSAL_STATUS_ACCEPT}, nil
+	}
+}
+
+// laneTxSelector is a TxSelector bounding the bytes and gas of the transactions of the
+// current lane, on top of the ones of the block.
+type laneTxSelector struct {
+	totalTxBytes uint64
+	totalTxGas   uint64
+	selectedTxs  [][]byte
+
+	laneTxBytes    uint64
+	laneTxGas      uint64
+	maxLaneTxBytes uint64
+	maxLaneTxGas   uint64
+}
+
+var _ TxSelector = (*laneTxSelector)(nil)
+
+// startLane starts selecting the transactions of a lane with the given limits.
+func (ts *laneTxSelector) startLane(maxLaneTxBytes, maxLaneTxGas uint64) {
+	ts.laneTxBytes = 0
+	ts.laneTxGas = 0
+	ts.maxLaneTxBytes = maxLaneTxBytes
+	ts.maxLaneTxGas = maxLaneTxGas
+}
+
+func (ts *laneTxSelector) SelectedTxs(_ context.Context) [][]byte {
+	txs := make([][]byte, len(ts.selectedTxs))
+	copy(txs, ts.selectedTxs)
+	return txs
+}
+
+func (ts *laneTxSelector) Clear() {
+	*ts = laneTxSelector{}
+}
+
+func (ts *laneTxSelector) SelectTxForProposal(_ context.Context, maxTxBytes, maxBlockGas uint64, memTx sdk.Tx, txBz []byte) bool {
+	txSize := uint64(cmttypes.ComputeProtoSizeForTxs([]cmttypes.Tx{txBz}))
+	txGas := txGasLimit(memTx)
+
+	// only add the transaction to the proposal if both the lane and the block have enough
+	// capacity
+	fitsBytes := ts.totalTxBytes+txSize <= maxTxBytes && ts.laneTxBytes+txSize <= ts.maxLaneTxBytes
+	fitsGas := maxBlockGas == 0 || (ts.totalTxGas+txGas <= maxBlockGas && ts.laneTxGas+txGas <= ts.maxLaneTxGas)
+	if fitsBytes && fitsGas {
+		ts.totalTxBytes += txSize
+		ts.laneTxBytes += txSize
+		if maxBlockGas > 0 {
+			ts.totalTxGas += txGas
+			ts.laneTxGas += txGas
+		}
+		ts.selectedTxs = append(ts.selectedTxs, txBz)
+	}
+
+	// check if we've reached the capacity of the lane or of the block; if so, we cannot
+	// select any more transactions in this lane
+	return ts.laneTxBytes >= ts.maxLaneTxBytes || ts.totalTxBytes >= maxTxBytes ||
+		(maxBlockGas > 0 && (ts.laneTxGas >= ts.maxLaneTxGas || ts.totalTxGas >= maxBlockGas))
+}
+
+// blockMaxGas returns the maximum gas of a block, or 0 if it is unlimited.
+func blockMaxGas(ctx sdk.Context) uint64 {
+	if b := ctx.ConsensusParams().Block; b != nil && b.MaxGas > 0 { // nolint:staticcheck // ignore linting error
+		return uint64(b.MaxGas)
+	}
+	return 0
+}
+
+// proposalMaxTxBytes returns the MaxTxBytes CometBFT passes to PrepareProposal for the
+// proposal, or 0 if the block bytes are unlimited: the block max bytes minus the block
+// overhead, header and last commit. The evidence of the proposal isn't known, so that the
+// limit is the one of a proposal without evidence, which never rejects a valid proposal.
+func proposalMaxTxBytes(ctx sdk.Context, req *abci.ProcessProposalRequest) uint64 {
+	b := ctx.ConsensusParams().Block
+	if b == nil || b.MaxBytes <= 0 { // nolint:staticcheck // ignore linting error
+		return 0
+	}
+
+	// like PrepareProposal, it panics if the block max bytes can't hold the header and commit
+	return uint64(cmttypes.MaxDataBytesNoEvidence(b.MaxBytes, len(req.ProposedLastCommit.Votes)))
+}
+
+// laneLimit returns the share of a block limit available to a lane.
+func laneLimit(maxBlockSpace math.LegacyDec, limit uint64) uint64 {
+	return maxBlockSpace.MulInt(math.NewIntFromUint64(limit)).TruncateInt().Uint64()
+}
+
+func txGasLimit(tx sdk.Tx) uint64 {
+	if gasTx, ok := tx.(GasTx); ok {
+		return gasTx.GetGas()
+	}
+	return 0
+}
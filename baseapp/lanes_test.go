@@ -0,0 +1,144 @@
+package baseapp_test
+
+import (
+	abci "github.com/cometbft/cometbft/abci/types"
+	cmtproto "github.com/cometbft/cometbft/api/cometbft/types/v1"
+	cmttypes "github.com/cometbft/cometbft/types"
+	"github.com/golang/mock/gomock"
+
+	"cosmossdk.io/math"
+	authtx "cosmossdk.io/x/auth/tx"
+
+	"github.com/cosmos/cosmos-sdk/baseapp"
+	baseapptestutil "github.com/cosmos/cosmos-sdk/baseapp/testutil"
+	"github.com/cosmos/cosmos-sdk/baseapp/testutil/mock"
+	codectestutil "github.com/cosmos/cosmos-sdk/codec/testutil"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/mempool"
+)
+
+func (s *ABCIUtilsTestSuite) TestLaneProposalHandler() {
+	cdc := codectestutil.CodecOptions{}.NewCodec()
+	baseapptestutil.RegisterInterfaces(cdc.InterfaceRegistry())
+	signingCtx := cdc.InterfaceRegistry().SigningContext()
+	txConfig := authtx.NewTxConfig(cdc, signingCtx.AddressCodec(), signingCtx.ValidatorAddressCodec(), authtx.DefaultSignModes)
+
+	oracleSecret, userSecret := []byte("secret1"), []byte("secret2")
+	oracle := sdk.AccAddress(secp256k1.GenPrivKeyFromSecret(oracleSecret).PubKey().Bytes())
+
+	txs := []sdk.Tx{
+		buildMsg(s.T(), txConfig, []byte(`0`), [][]byte{oracleSecret}, []uint64{1}),
+		buildMsg(s.T(), txConfig, []byte(`12345678910`), [][]byte{oracleSecret}, []uint64{2}),
+		buildMsg(s.T(), txConfig, []byte(`32`), [][]byte{userSecret}, []uint64{1}),
+	}
+	txBzs := make([][]byte, len(txs))
+	for i, tx := range txs {
+		bz, err := txConfig.TxEncoder()(tx)
+		s.Require().NoError(err)
+		txBzs[i] = bz
+	}
+	// the sizes of the transactions are 180, 190 and 181 bytes
+	s.Require().Equal(oracle, sdk.AccAddress(txs[0].(sdk.FeeTx).FeePayer()))
+
+	newMempool := func() *mempool.LanedMempool {
+		newPriorityMempool := func() mempool.Mempool {
+			return mempool.NewPriorityMempool(mempool.PriorityNonceMempoolConfig[int64]{
+				TxPriority:      mempool.NewDefaultTxPriority(),
+				SignerExtractor: mempool.NewDefaultSignerExtractionAdapter(),
+			})
+		}
+		mp, err := mempool.NewLanedMempool(
+			mempool.Lane{Name: "oracle", Mempool: newPriorityMempool(), Match: mempool.MatchFeePayers(oracle), MaxBlockSpace: math.LegacyNewDecWithPrec(5, 1)},
+			mempool.Lane{Name: "default", Mempool: newPriorityMempool(), Match: mempool.MatchAll(), MaxBlockSpace: math.LegacyOneDec()},
+		)
+		s.Require().NoError(err)
+		return mp
+	}
+
+	ctrl := gomock.NewController(s.T())
+	app := mock.NewMockProposalTxVerifier(ctrl)
+	for i, tx := range txs {
+		app.EXPECT().PrepareProposalVerifyTx(tx).Return(txBzs[i], nil).AnyTimes()
+		app.EXPECT().ProcessProposalVerifyTx(txBzs[i]).Return(tx, nil).AnyTimes()
+	}
+
+	s.Run("prepare proposal", func() {
+		mp := newMempool()
+		// the user transaction has the highest priority, but the oracle lane is filled first
+		s.Require().NoError(mp.Insert(s.ctx.WithPriority(10), txs[2]))
+		s.Require().NoError(mp.Insert(s.ctx.WithPriority(1), txs[0]))
+		s.Require().NoError(mp.Insert(s.ctx.WithPriority(1), txs[1]))
+
+		handler := baseapp.NewLaneProposalHandler(mp, app).PrepareProposalHandler()
+		// the oracle lane can't use more than half of the block, so the second oracle
+		// transaction is left out
+		resp, err := handler(s.ctx, &abci.PrepareProposalRequest{MaxTxBytes: 180 + 190 + 181})
+		s.Require().NoError(err)
+		s.Require().Equal([][]byte{txBzs[0], txBzs[2]}, resp.Txs)
+
+		resp, err = handler(s.ctx, &abci.PrepareProposalRequest{MaxTxBytes: 2 * (180 + 190)})
+		s.Require().NoError(err)
+		s.Require().Equal([][]byte{txBzs[0], txBzs[1], txBzs[2]}, resp.Txs)
+	})
+
+	// blockParams returns the block params for which CometBFT passes maxTxBytes to
+	// PrepareProposal, without validators nor evidence.
+	blockParams := func(maxTxBytes int64) cmtproto.ConsensusParams {
+		maxBytes := maxTxBytes + cmttypes.MaxOverheadForBlock + cmttypes.MaxHeaderBytes + cmttypes.MaxCommitBytes(0)
+		return cmtproto.ConsensusParams{Block: &cmtproto.BlockParams{MaxBytes: maxBytes}}
+	}
+
+	s.Run("process proposal", func() {
+		handler := baseapp.NewLaneProposalHandler(newMempool(), app).ProcessProposalHandler()
+		ctx := s.ctx.WithConsensusParams(blockParams(180 + 190 + 181))
+
+		testCases := map[string]struct {
+			txs    [][]byte
+			status abci.ProcessProposalStatus
+		}{
+			"lanes in order": {
+				txs:    [][]byte{txBzs[0], txBzs[2]},
+				status: abci.PROCESS_PROPOSAL_STATUS_ACCEPT,
+			},
+			"lanes out of order": {
+				txs:    [][]byte{txBzs[2], txBzs[0]},
+				status: abci.PROCESS_PROPOSAL_STATUS_REJECT,
+			},
+			"lane exceeding its block space": {
+				txs:    [][]byte{txBzs[0], txBzs[1], txBzs[2]},
+				status: abci.PROCESS_PROPOSAL_STATUS_REJECT,
+			},
+		}
+		for name, tc := range testCases {
+			s.Run(name, func() {
+				resp, err := handler(ctx, &abci.ProcessProposalRequest{Txs: tc.txs})
+				s.Require().NoError(err)
+				s.Require().Equal(tc.status, resp.Status)
+			})
+		}
+	})
+	s.Run("process proposal lane boundary", func() {
+		// the oracle lane holds the first oracle transaction with 360 bytes of transactions,
+		// but not with 359: the proposals built by PrepareProposal are accepted, and the
+		// others rejected
+		for _, maxTxBytes := range []int64{2 * 180, 2*180 - 1} {
+			mp := newMempool()
+			s.Require().NoError(mp.Insert(s.ctx.WithPriority(1), txs[0]))
+			lanes := baseapp.NewLaneProposalHandler(mp, app)
+
+			resp, err := lanes.PrepareProposalHandler()(s.ctx, &abci.PrepareProposalRequest{MaxTxBytes: maxTxBytes})
+			s.Require().NoError(err)
+			prepared := len(resp.Txs) == 1
+
+			status := abci.PROCESS_PROPOSAL_STATUS_REJECT
+			if prepared {
+				status = abci.PROCESS_PROPOSAL_STATUS_ACCEPT
+			}
+			processResp, err := lanes.ProcessProposalHandler()(s.ctx.WithConsensusParams(blockParams(maxTxBytes)), &abci.ProcessProposalRequest{Txs: [][]byte{txBzs[0]}})
+			s.Require().NoError(err)
+			s.Require().Equal(status, processResp.Status, "max tx bytes %d", maxTxBytes)
+			s.Require().Equal(maxTxBytes == 2*180, prepared)
+		}
+	})
+}
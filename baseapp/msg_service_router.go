@@ -8,6 +8,7 @@ import (
 	abci "github.com/cometbft/cometbft/abci/types"
 	gogogrpc "github.com/cosmos/gogoproto/grpc"
 	"github.com/cosmos/gogoproto/proto"
+	"go.opentelemetry.io/otel/attribute"
 	"google.golang.org/grpc"
 	"google.golang.org/protobuf/runtime/protoiface"
 
@@ -16,6 +17,7 @@ import (
 	"github.com/cosmos/cosmos-sdk/baseapp/internal/protocompat"
 	"github.com/cosmos/cosmos-sdk/codec"
 	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	"github.com/cosmos/cosmos-sdk/telemetry"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
 )
@@ -191,8 +193,27 @@ func (msr *MsgServiceRouter) registerMsgServiceHandler(sd *grpc.ServiceDesc, met
 		)
 	}
 
-	msr.routes[requestTypeName] = func(ctx sdk.Context, msg sdk.Msg) (*sdk.Result, error) {
-		ctx = ctx.WithEventManager(sdk.NewEventManager())
+	msr.routes[requestTypeName] = func(ctx sdk.Context, msg sdk.Msg) (_ *sdk.Result, err error) {
+		spanCtx, span := telemetry.StartSpan(ctx.Context(), "msg", attribute.String("type", requestTypeName))
+		start, gasBefore := telemetry.Now(), ctx.GasMeter().GasConsumed()
+		defer func() {
+			// the message fails if the handler panics, e.g. when running out of gas
+			r := recover()
+			if r != nil {
+				err = fmt.Errorf("panic: %v", r)
+			}
+			gasUsed := ctx.GasMeter().GasConsumed() - gasBefore
+			if span.IsRecording() {
+				span.SetAttributes(attribute.Int64("gas_used", int64(gasUsed)))
+			}
+			telemetry.EndSpan(span, err)
+			telemetry.MeasureMsg(requestTypeName, start, gasUsed, err)
+			if r != nil {
+				panic(r)
+			}
+		}()
+
+		ctx = ctx.WithContext(spanCtx).WithEventManager(sdk.NewEventManager())
 		interceptor := func(goCtx context.Context, _ interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
 			goCtx = context.WithValue(goCtx, sdk.SdkContextKey, ctx)
 			return handler(goCtx, msg)
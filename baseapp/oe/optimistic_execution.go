@@ -1,3 +1,7 @@
+// Package oe implements the optimistic execution of the proposals: a proposal accepted in
+// ProcessProposal is executed speculatively, before it is decided, so that the result is
+// ready when FinalizeBlock is called. The execution is aborted if another block is
+// decided.
 package oe
 
 import (
@@ -9,118 +13,201 @@ import (
 	"time"
 
 	abci "github.com/cometbft/cometbft/abci/types"
+	"github.com/hashicorp/go-metrics"
 
 	"cosmossdk.io/core/log"
+
+	"github.com/cosmos/cosmos-sdk/telemetry"
 )
 
+// ExecuteFunc is the function that is called by the OE to execute a block
+// speculatively, returning a result of type T.
+type ExecuteFunc[T any] func(context.Context, *abci.FinalizeBlockRequest) (T, error)
+
 // FinalizeBlockFunc is the function that is called by the OE to finalize the
 // block. It is the same as the one in the ABCI app.
-type FinalizeBlockFunc func(context.Context, *abci.FinalizeBlockRequest) (*abci.FinalizeBlockResponse, error)
-
-// OptimisticExecution is a struct that contains the OE context. It is used to
-// run the FinalizeBlock function in a goroutine, and to abort it if needed.
-type OptimisticExecution struct {
-	finalizeBlockFunc FinalizeBlockFunc // ABCI FinalizeBlock function with a context
-	logger            log.Logger
-
-	mtx         sync.Mutex
-	stopCh      chan struct{}
-	request     *abci.FinalizeBlockRequest
-	response    *abci.FinalizeBlockResponse
-	err         error
-	cancelFunc  func() // cancel function for the context
-	initialized bool   // A boolean value indicating whether the struct has been initialized
+type FinalizeBlockFunc = ExecuteFunc[*abci.FinalizeBlockResponse]
+
+// OptimisticExecution is the optimistic execution of the FinalizeBlock function of an
+// ABCI app.
+type OptimisticExecution = Executor[*abci.FinalizeBlockResponse]
+
+// config is the configuration of an Executor.
+type config struct {
+	// maxCandidates is the maximum number of proposals executed concurrently.
+	maxCandidates int
 
 	// debugging/testing options
 	abortRate int // number from 0 to 100 that determines the percentage of OE that should be aborted
 }
 
-// NewOptimisticExecution initializes the Optimistic Execution context but does not start it.
-func NewOptimisticExecution(logger log.Logger, fn FinalizeBlockFunc, opts ...func(*OptimisticExecution)) *OptimisticExecution {
-	logger = logger.With(log.ModuleKey, "oe")
-	oe := &OptimisticExecution{logger: logger, finalizeBlockFunc: fn}
-	for _, opt := range opts {
-		opt(oe)
-	}
-	return oe
-}
+// Option configures an Executor.
+type Option func(*config)
 
 // WithAbortRate sets the abort rate for the OE. The abort rate is a number from
 // 0 to 100 that determines the percentage of OE that should be aborted.
 // This is for testing purposes only and must not be used in production.
-func WithAbortRate(rate int) func(*OptimisticExecution) {
-	return func(oe *OptimisticExecution) {
-		oe.abortRate = rate
+func WithAbortRate(rate int) Option {
+	return func(cfg *config) {
+		cfg.abortRate = rate
 	}
 }
 
+// WithMaxCandidates sets the maximum number of proposals executed concurrently, 1 by
+// default. When a proposal is executed while the maximum is reached, the execution of the
+// oldest proposal is aborted. Executing several proposals concurrently is only possible
+// if their executions don't share any state, until one of them is decided.
+func WithMaxCandidates(n int) Option {
+	return func(cfg *config) {
+		if n > 0 {
+			cfg.maxCandidates = n
+		}
+	}
+}
+
+// candidate is the speculative execution of a proposal.
+type candidate[T any] struct {
+	request    *abci.FinalizeBlockRequest
+	stopCh     chan struct{}
+	cancelFunc func() // cancel function for the context
+	start      time.Time
+	response   T
+	err        error
+}
+
+// Executor is a struct that contains the OE context. It is used to run the
+// execute function of the proposals in goroutines, and to abort them if needed.
+type Executor[T any] struct {
+	executeFunc ExecuteFunc[T]
+	logger      log.Logger
+	cfg         config
+
+	mtx sync.Mutex
+	// candidates are the proposals being executed, oldest first.
+	candidates []*candidate[T]
+	// evicted are the candidates aborted to execute newer proposals, which may still be
+	// running.
+	evicted []*candidate[T]
+	// selected is the candidate of the decided block, set by AbortIfNeeded.
+	selected *candidate[T]
+}
+
+// NewOptimisticExecution initializes the Optimistic Execution context but does not start it.
+func NewOptimisticExecution(logger log.Logger, fn FinalizeBlockFunc, opts ...Option) *OptimisticExecution {
+	return NewExecutor(logger, fn, opts...)
+}
+
+// NewExecutor initializes the optimistic execution of the proposals with the given
+// function, but does not start it.
+func NewExecutor[T any](logger log.Logger, fn ExecuteFunc[T], opts ...Option) *Executor[T] {
+	cfg := config{maxCandidates: 1}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return &Executor[T]{
+		logger:      logger.With(log.ModuleKey, "oe"),
+		executeFunc: fn,
+		cfg:         cfg,
+	}
+}
+
+// MaxCandidates returns the maximum number of proposals executed concurrently.
+func (oe *Executor[T]) MaxCandidates() int {
+	return oe.cfg.maxCandidates
+}
+
 // Reset resets the OE context. Must be called whenever we want to invalidate
-// the current OE.
-func (oe *OptimisticExecution) Reset() {
+// the current OE. Running executions must be aborted first.
+func (oe *Executor[T]) Reset() {
+	if oe == nil {
+		return
+	}
+
 	oe.mtx.Lock()
 	defer oe.mtx.Unlock()
-	oe.request = nil
-	oe.response = nil
-	oe.err = nil
-	oe.initialized = false
+	oe.candidates = nil
+	oe.evicted = nil
+	oe.selected = nil
 }
 
-func (oe *OptimisticExecution) Enabled() bool {
+func (oe *Executor[T]) Enabled() bool {
 	return oe != nil
 }
 
 // Initialized returns true if the OE was initialized, meaning that it contains
 // a request and it was run or it is running.
-func (oe *OptimisticExecution) Initialized() bool {
+func (oe *Executor[T]) Initialized() bool {
 	if oe == nil {
 		return false
 	}
 	oe.mtx.Lock()
 	defer oe.mtx.Unlock()
 
-	return oe.initialized
+	return len(oe.candidates) > 0
 }
 
-// Execute initializes the OE and starts it in a goroutine.
-func (oe *OptimisticExecution) Execute(req *abci.ProcessProposalRequest) {
+// Execute starts the execution of the proposal in a goroutine. If the maximum number of
+// candidates is reached, the execution of the oldest one is aborted. A proposal already
+// being executed isn't executed again.
+func (oe *Executor[T]) Execute(req *abci.ProcessProposalRequest) {
 	oe.mtx.Lock()
 	defer oe.mtx.Unlock()
 
-	oe.stopCh = make(chan struct{})
-	oe.request = &abci.FinalizeBlockRequest{
-		Txs:                req.Txs,
-		DecidedLastCommit:  req.ProposedLastCommit,
-		Misbehavior:        req.Misbehavior,
-		Hash:               req.Hash,
-		Height:             req.Height,
-		Time:               req.Time,
-		NextValidatorsHash: req.NextValidatorsHash,
-		ProposerAddress:    req.ProposerAddress,
+	for _, c := range oe.candidates {
+		if c.request.Height == req.Height && bytes.Equal(c.request.Hash, req.Hash) {
+			return
+		}
+	}
+	for len(oe.candidates) >= oe.cfg.maxCandidates {
+		evicted := oe.candidates[0]
+		oe.candidates = oe.candidates[1:]
+		oe.logger.Debug("OE evicted", "height", evicted.request.Height, "hash", hex.EncodeToString(evicted.request.Hash))
+		evicted.cancelFunc()
+		oe.evicted = append(oe.evicted, evicted)
+		telemetry.IncrCounter(1, "oe", "evicted")
 	}
 
-	oe.logger.Debug("OE started", "height", req.Height, "hash", hex.EncodeToString(req.Hash), "time", req.Time.String())
 	ctx, cancel := context.WithCancel(context.Background())
-	oe.cancelFunc = cancel
-	oe.initialized = true
+	c := &candidate[T]{
+		request: &abci.FinalizeBlockRequest{
+			Txs:                req.Txs,
+			DecidedLastCommit:  req.ProposedLastCommit,
+			Misbehavior:        req.Misbehavior,
+			Hash:               req.Hash,
+			Height:             req.Height,
+			Time:               req.Time,
+			NextValidatorsHash: req.NextValidatorsHash,
+			ProposerAddress:    req.ProposerAddress,
+		},
+		stopCh:     make(chan struct{}),
+		cancelFunc: cancel,
+		start:      time.Now(),
+	}
+	oe.candidates = append(oe.candidates, c)
+	telemetry.IncrCounter(1, "oe", "started")
+	telemetry.SetGauge(float32(len(oe.candidates)), "oe", "candidates")
+
+	oe.logger.Debug("OE started", "height", req.Height, "hash", hex.EncodeToString(req.Hash), "time", req.Time.String())
 
 	go func() {
-		start := time.Now()
-		resp, err := oe.finalizeBlockFunc(ctx, oe.request)
+		resp, err := oe.executeFunc(ctx, c.request)
 
 		oe.mtx.Lock()
+		defer oe.mtx.Unlock()
 
-		executionTime := time.Since(start)
-		oe.logger.Debug("OE finished", "duration", executionTime.String(), "height", oe.request.Height, "hash", hex.EncodeToString(oe.request.Hash))
-		oe.response, oe.err = resp, err
+		executionTime := time.Since(c.start)
+		oe.logger.Debug("OE finished", "duration", executionTime.String(), "height", c.request.Height, "hash", hex.EncodeToString(c.request.Hash))
+		telemetry.MeasureSince(c.start, "oe", "execution")
+		c.response, c.err = resp, err
 
-		close(oe.stopCh)
-		oe.mtx.Unlock()
+		close(c.stopCh)
 	}()
 }
 
-// AbortIfNeeded aborts the OE if the request hash is not the same as the one in
-// the running OE. Returns true if the OE was aborted.
-func (oe *OptimisticExecution) AbortIfNeeded(reqHash []byte) bool {
+// AbortIfNeeded selects the execution of the decided block, and aborts the executions of
+// the other proposals. Returns true if the decided block isn't being executed, in which
+// case every execution is aborted.
+func (oe *Executor[T]) AbortIfNeeded(reqHash []byte) bool {
 	if oe == nil {
 		return false
 	}
@@ -128,33 +215,88 @@ func (oe *OptimisticExecution) AbortIfNeeded(reqHash []byte) bool {
 	oe.mtx.Lock()
 	defer oe.mtx.Unlock()
 
-	if !bytes.Equal(oe.request.Hash, reqHash) {
-		oe.logger.Error("OE aborted due to hash mismatch", "oe_hash", hex.EncodeToString(oe.request.Hash), "req_hash", hex.EncodeToString(reqHash), "oe_height", oe.request.Height, "req_height", oe.request.Height)
-		oe.cancelFunc()
+	oe.selected = nil
+	for _, c := range oe.candidates {
+		if bytes.Equal(c.request.Hash, reqHash) {
+			oe.selected = c
+			continue
+		}
+		c.cancelFunc()
+	}
+
+	switch {
+	case oe.selected == nil:
+		oe.logger.Error("OE aborted due to hash mismatch", "oe_hashes", oe.candidateHashes(), "req_hash", hex.EncodeToString(reqHash))
+		telemetry.IncrCounterWithLabels([]string{"oe", "aborted"}, 1, []metrics.Label{telemetry.NewLabel("reason", "hash_mismatch")})
 		return true
-	} else if oe.abortRate > 0 && rand.Intn(100) < oe.abortRate {
+	case oe.cfg.abortRate > 0 && rand.Intn(100) < oe.cfg.abortRate:
 		// this is for test purposes only, we can emulate a certain percentage of
 		// OE needed to be aborted.
-		oe.cancelFunc()
+		oe.selected.cancelFunc()
+		oe.selected = nil
 		oe.logger.Error("OE aborted due to test abort rate")
+		telemetry.IncrCounterWithLabels([]string{"oe", "aborted"}, 1, []metrics.Label{telemetry.NewLabel("reason", "abort_rate")})
 		return true
 	}
 
+	telemetry.IncrCounter(1, "oe", "hit")
 	return false
 }
 
-// Abort aborts the OE unconditionally and waits for it to finish.
-func (oe *OptimisticExecution) Abort() {
-	if oe == nil || oe.cancelFunc == nil {
+// Abort aborts the OE unconditionally and waits for every execution to finish, including
+// the cancelled ones.
+func (oe *Executor[T]) Abort() {
+	if oe == nil {
 		return
 	}
 
-	oe.cancelFunc()
-	<-oe.stopCh
+	oe.mtx.Lock()
+	for _, c := range oe.candidates {
+		c.cancelFunc()
+	}
+	oe.mtx.Unlock()
+
+	oe.wait()
+}
+
+// WaitResult waits for the execution selected by AbortIfNeeded to finish and returns its
+// result. The cancelled executions aren't waited for, and the zero value is returned if
+// none was selected: use Abort to wait for them.
+func (oe *Executor[T]) WaitResult() (T, error) {
+	oe.mtx.Lock()
+	target := oe.selected
+	oe.mtx.Unlock()
+
+	if target == nil {
+		var zero T
+		return zero, nil
+	}
+
+	<-target.stopCh
+	oe.mtx.Lock()
+	defer oe.mtx.Unlock()
+	return target.response, target.err
+}
+
+// wait waits for every execution to finish, including the evicted ones.
+func (oe *Executor[T]) wait() {
+	oe.mtx.Lock()
+	pending := append(append([]*candidate[T]{}, oe.evicted...), oe.candidates...)
+	oe.mtx.Unlock()
+
+	for _, c := range pending {
+		<-c.stopCh
+	}
+
+	oe.mtx.Lock()
+	oe.evicted = nil
+	oe.mtx.Unlock()
 }
 
-// WaitResult waits for the OE to finish and returns the result.
-func (oe *OptimisticExecution) WaitResult() (*abci.FinalizeBlockResponse, error) {
-	<-oe.stopCh
-	return oe.response, oe.err
+func (oe *Executor[T]) candidateHashes() []string {
+	hashes := make([]string, len(oe.candidates))
+	for i, c := range oe.candidates {
+		hashes[i] = hex.EncodeToString(c.request.Hash)
+	}
+	return hashes
 }
@@ -23,12 +23,81 @@ func TestOptimisticExecution(t *testing.T) {
 	})
 	assert.True(t, oe.Initialized())
 
+	assert.False(t, oe.AbortIfNeeded([]byte("test")))
 	resp, err := oe.WaitResult()
 	assert.Nil(t, resp)
 	assert.EqualError(t, err, "test error")
 
-	assert.False(t, oe.AbortIfNeeded([]byte("test")))
 	assert.True(t, oe.AbortIfNeeded([]byte("wrong_hash")))
 
 	oe.Reset()
 }
+
+func TestOptimisticExecutionCandidates(t *testing.T) {
+	release := make(chan struct{})
+	executed := make(chan string, 3)
+	oe := NewExecutor(log.NewNopLogger(), func(ctx context.Context, req *abci.FinalizeBlockRequest) (string, error) {
+		executed <- string(req.Hash)
+		select {
+		case <-release:
+			return string(req.Hash), nil
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}, WithMaxCandidates(2))
+
+	oe.Execute(&abci.ProcessProposalRequest{Height: 1, Hash: []byte("a")})
+	oe.Execute(&abci.ProcessProposalRequest{Height: 1, Hash: []byte("b")})
+	// a proposal already being executed isn't executed again
+	oe.Execute(&abci.ProcessProposalRequest{Height: 1, Hash: []byte("b")})
+	// the oldest candidate is evicted
+	oe.Execute(&abci.ProcessProposalRequest{Height: 1, Hash: []byte("c")})
+	for range 3 {
+		<-executed
+	}
+	assert.Equal(t, []string{"62", "63"}, oe.candidateHashes())
+
+	assert.False(t, oe.AbortIfNeeded([]byte("b")))
+	close(release)
+	resp, err := oe.WaitResult()
+	assert.NoError(t, err)
+	assert.Equal(t, "b", resp)
+
+	oe.Reset()
+	assert.False(t, oe.Initialized())
+
+	oe.Execute(&abci.ProcessProposalRequest{Height: 2, Hash: []byte("d")})
+	assert.True(t, oe.AbortIfNeeded([]byte("e")))
+	oe.Abort()
+	oe.Reset()
+}
+
+func TestOptimisticExecutionSkipsCancelled(t *testing.T) {
+	release := make(chan struct{})
+	oe := NewExecutor(log.NewNopLogger(), func(_ context.Context, req *abci.FinalizeBlockRequest) (string, error) {
+		if string(req.Hash) == "slow" {
+			// the execution doesn't check for cancellation
+			<-release
+		}
+		return string(req.Hash), nil
+	}, WithMaxCandidates(2))
+
+	oe.Execute(&abci.ProcessProposalRequest{Height: 1, Hash: []byte("slow")})
+	oe.Execute(&abci.ProcessProposalRequest{Height: 1, Hash: []byte("fast")})
+
+	// the cancelled execution isn't waited for
+	assert.False(t, oe.AbortIfNeeded([]byte("fast")))
+	resp, err := oe.WaitResult()
+	assert.NoError(t, err)
+	assert.Equal(t, "fast", resp)
+
+	// nothing is waited for when every execution is cancelled
+	assert.True(t, oe.AbortIfNeeded([]byte("other")))
+	resp, err = oe.WaitResult()
+	assert.NoError(t, err)
+	assert.Empty(t, resp)
+
+	close(release)
+	oe.Abort()
+	oe.Reset()
+}
@@ -112,8 +112,10 @@ func SetStoreLoader(loader StoreLoader) func(*BaseApp) {
 	return func(app *BaseApp) { app.SetStoreLoader(loader) }
 }
 
-// SetOptimisticExecution enables optimistic execution.
-func SetOptimisticExecution(opts ...func(*oe.OptimisticExecution)) func(*BaseApp) {
+// SetOptimisticExecution enables optimistic execution. BaseApp executes a single
+// proposal at a time, as the execution writes to the FinalizeBlock state: loading the
+// app fails when more candidates are requested with oe.WithMaxCandidates.
+func SetOptimisticExecution(opts ...oe.Option) func(*BaseApp) {
 	return func(app *BaseApp) {
 		app.optimisticExec = oe.NewOptimisticExecution(app.logger, app.internalFinalizeBlock, opts...)
 	}
@@ -319,6 +321,13 @@ func (app *BaseApp) SetTxDecoder(txDecoder sdk.TxDecoder) {
 	app.txDecoder = txDecoder
 }
 
+// SetCheckTxDecoder sets the TxDecoder of the transactions received by CheckTx, e.g.
+// enforcing a node local decode policy. The transactions of proposals and blocks are
+// always decoded with the TxDecoder, which must be deterministic.
+func (app *BaseApp) SetCheckTxDecoder(txDecoder sdk.TxDecoder) {
+	app.checkTxDecoder = txDecoder
+}
+
 // SetTxEncoder sets the TxEncoder if it wasn't provided in the BaseApp constructor.
 func (app *BaseApp) SetTxEncoder(txEncoder sdk.TxEncoder) {
 	app.txEncoder = txEncoder
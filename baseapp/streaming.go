@@ -1,17 +1,23 @@
 package baseapp
 
 import (
+	"context"
 	"fmt"
+	"path/filepath"
 	"sort"
 	"strings"
 
+	abci "github.com/cometbft/cometbft/abci/types"
+	dbm "github.com/cosmos/cosmos-db"
 	"github.com/spf13/cast"
 
 	"cosmossdk.io/store/streaming"
 	storetypes "cosmossdk.io/store/types"
 
+	"github.com/cosmos/cosmos-sdk/baseapp/streamqueue"
 	"github.com/cosmos/cosmos-sdk/client/flags"
 	servertypes "github.com/cosmos/cosmos-sdk/server/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
 )
 
 const (
@@ -20,10 +26,22 @@ const (
 	StreamingABCIPluginTomlKey        = "plugin"
 	StreamingABCIKeysTomlKey          = "keys"
 	StreamingABCIStopNodeOnErrTomlKey = "stop-node-on-err"
+
+	StreamingQueueTomlKey              = "queue"
+	StreamingQueueEnableTomlKey        = "enable"
+	StreamingQueueRetentionTomlKey     = "retention"
+	StreamingQueueMaxLagTomlKey        = "max-lag"
+	StreamingQueueRetryIntervalTomlKey = "retry-interval"
+	StreamingQueueReplayHeightTomlKey  = "replay-height"
+	StreamingQueueDBBackendTomlKey     = "db-backend"
 )
 
 // RegisterStreamingServices registers streaming services with the BaseApp.
 func (app *BaseApp) RegisterStreamingServices(appOpts servertypes.AppOptions, keys map[string]*storetypes.KVStoreKey) error {
+	if err := app.openStreamingQueue(appOpts); err != nil {
+		return fmt.Errorf("failed to open streaming queue: %w", err)
+	}
+
 	// register streaming services
 	streamingCfg := cast.ToStringMap(appOpts.Get(StreamingTomlKey))
 	for service := range streamingCfg {
@@ -35,7 +53,7 @@ func (app *BaseApp) RegisterStreamingServices(appOpts servertypes.AppOptions, ke
 			if err != nil {
 				return fmt.Errorf("failed to load streaming plugin: %w", err)
 			}
-			if err := app.registerStreamingPlugin(appOpts, keys, plugin); err != nil {
+			if err := app.registerStreamingPlugin(appOpts, keys, pluginName, plugin); err != nil {
 				return fmt.Errorf("failed to register streaming plugin %w", err)
 			}
 		}
@@ -48,6 +66,7 @@ func (app *BaseApp) RegisterStreamingServices(appOpts servertypes.AppOptions, ke
 func (app *BaseApp) registerStreamingPlugin(
 	appOpts servertypes.AppOptions,
 	keys map[string]*storetypes.KVStoreKey,
+	pluginName string,
 	streamingPlugin interface{},
 ) error {
 	v, ok := streamingPlugin.(storetypes.ABCIListener)
@@ -55,28 +74,154 @@ func (app *BaseApp) registerStreamingPlugin(
 		return fmt.Errorf("unexpected plugin type %T", v)
 	}
 
-	app.registerABCIListenerPlugin(appOpts, keys, v)
-	return nil
+	return app.registerABCIListenerPlugin(appOpts, keys, pluginName, v)
 }
 
 // registerABCIListenerPlugin registers plugins that implement the ABCIListener interface.
 func (app *BaseApp) registerABCIListenerPlugin(
 	appOpts servertypes.AppOptions,
 	keys map[string]*storetypes.KVStoreKey,
+	pluginName string,
 	abciListener storetypes.ABCIListener,
-) {
+) error {
 	stopNodeOnErrKey := fmt.Sprintf("%s.%s.%s", StreamingTomlKey, StreamingABCITomlKey, StreamingABCIStopNodeOnErrTomlKey)
 	stopNodeOnErr := cast.ToBool(appOpts.Get(stopNodeOnErrKey))
 	keysKey := fmt.Sprintf("%s.%s.%s", StreamingTomlKey, StreamingABCITomlKey, StreamingABCIKeysTomlKey)
 	exposeKeysStr := cast.ToStringSlice(appOpts.Get(keysKey))
 	exposedKeys := exposeStoreKeysSorted(exposeKeysStr, keys)
-	app.cms.AddListeners(exposedKeys)
-	app.SetStreamingManager(
-		storetypes.StreamingManager{
-			ABCIListeners: []storetypes.ABCIListener{abciListener},
-			StopNodeOnErr: stopNodeOnErr,
-		},
-	)
+	return app.AddQueuedABCIListener(pluginName, exposedKeys, abciListener, stopNodeOnErr)
+}
+
+// AddABCIListener adds an ABCIListener to the streaming manager, next to the listeners
+// already registered. The listener only receives the changes of the stores with the given
+// keys. If stopNodeOnErr is set, an error of the listener stops the node, otherwise it is
+// logged.
+func (app *BaseApp) AddABCIListener(keys []storetypes.StoreKey, abciListener storetypes.ABCIListener, stopNodeOnErr bool) {
+	app.cms.AddListeners(keys)
+	app.streamingManager.ABCIListeners = append(app.streamingManager.ABCIListeners, newFilteredABCIListener(keys, abciListener, stopNodeOnErr))
+}
+
+// AddQueuedABCIListener adds an ABCIListener receiving the blocks through the durable
+// streaming queue, when enabled, and exposes the changes of the stores with the given keys
+// to it. The name identifies the cursor of the listener in the queue, and must not change
+// across restarts. The listener is added to the streaming manager with AddABCIListener if
+// the queue is disabled, stopNodeOnErr only applying then: failing deliveries of the queue
+// are retried.
+func (app *BaseApp) AddQueuedABCIListener(name string, keys []storetypes.StoreKey, abciListener storetypes.ABCIListener, stopNodeOnErr bool) error {
+	if app.streamingQueue == nil {
+		app.AddABCIListener(keys, abciListener, stopNodeOnErr)
+		return nil
+	}
+
+	app.cms.AddListeners(keys)
+	storeKeys := make([]string, 0, len(keys))
+	for _, key := range keys {
+		storeKeys = append(storeKeys, key.Name())
+	}
+	return app.streamingQueue.AddListener(name, storeKeys, abciListener)
+}
+
+// StreamingQueue returns the durable streaming queue, or nil if it is disabled.
+func (app *BaseApp) StreamingQueue() *streamqueue.Queue {
+	return app.streamingQueue
+}
+
+// openStreamingQueue opens the durable streaming queue in the data directory of the node
+// if it is enabled, and adds it to the streaming manager.
+func (app *BaseApp) openStreamingQueue(appOpts servertypes.AppOptions) error {
+	if app.streamingQueue != nil || !cast.ToBool(appOpts.Get(streamingQueueOption(StreamingQueueEnableTomlKey))) {
+		return nil
+	}
+
+	cfg := streamqueue.DefaultConfig()
+	if v := appOpts.Get(streamingQueueOption(StreamingQueueRetentionTomlKey)); v != nil {
+		cfg.Retention = cast.ToUint64(v)
+	}
+	if v := appOpts.Get(streamingQueueOption(StreamingQueueMaxLagTomlKey)); v != nil {
+		cfg.MaxLag = cast.ToUint64(v)
+	}
+	if v := appOpts.Get(streamingQueueOption(StreamingQueueRetryIntervalTomlKey)); v != nil {
+		cfg.RetryInterval = cast.ToDuration(v)
+	}
+	cfg.ReplayHeight = cast.ToInt64(appOpts.Get(streamingQueueOption(StreamingQueueReplayHeightTomlKey)))
+
+	backend := cast.ToString(appOpts.Get(streamingQueueOption(StreamingQueueDBBackendTomlKey)))
+	if backend == "" {
+		backend = cast.ToString(appOpts.Get("app-db-backend"))
+	}
+	if backend == "" {
+		backend = string(dbm.GoLevelDBBackend)
+	}
+
+	dataDir := filepath.Join(cast.ToString(appOpts.Get(flags.FlagHome)), "data")
+	db, err := dbm.NewDB("streaming", dbm.BackendType(backend), dataDir)
+	if err != nil {
+		return err
+	}
+	queue, err := streamqueue.NewQueue(db, app.logger, cfg)
+	if err != nil {
+		_ = db.Close()
+		return err
+	}
+
+	app.streamingQueue = queue
+	app.streamingManager.ABCIListeners = append(app.streamingManager.ABCIListeners, queue)
+	return nil
+}
+
+// filteredABCIListener is an ABCIListener added with AddABCIListener. It receives the
+// changes of its own stores only, and the streaming manager of its context carries its own
+// error policy.
+type filteredABCIListener struct {
+	listener      storetypes.ABCIListener
+	storeKeys     map[string]struct{}
+	stopNodeOnErr bool
+}
+
+func newFilteredABCIListener(keys []storetypes.StoreKey, listener storetypes.ABCIListener, stopNodeOnErr bool) *filteredABCIListener {
+	storeKeys := make(map[string]struct{}, len(keys))
+	for _, key := range keys {
+		storeKeys[key.Name()] = struct{}{}
+	}
+	return &filteredABCIListener{listener: listener, storeKeys: storeKeys, stopNodeOnErr: stopNodeOnErr}
+}
+
+// ListenFinalizeBlock implements storetypes.ABCIListener.
+func (l *filteredABCIListener) ListenFinalizeBlock(ctx context.Context, req abci.FinalizeBlockRequest, res abci.FinalizeBlockResponse) error {
+	return l.listener.ListenFinalizeBlock(l.context(ctx), req, res)
+}
+
+// ListenCommit implements storetypes.ABCIListener.
+func (l *filteredABCIListener) ListenCommit(ctx context.Context, res abci.CommitResponse, changeSet []*storetypes.StoreKVPair) error {
+	filtered := make([]*storetypes.StoreKVPair, 0, len(changeSet))
+	for _, pair := range changeSet {
+		if _, ok := l.storeKeys[pair.StoreKey]; ok {
+			filtered = append(filtered, pair)
+		}
+	}
+	return l.listener.ListenCommit(l.context(ctx), res, filtered)
+}
+
+// context sets the error policy of the listener in the streaming manager of the context,
+// which is read by the streaming plugins.
+func (l *filteredABCIListener) context(ctx context.Context) context.Context {
+	sdkCtx, ok := ctx.(sdk.Context)
+	if !ok {
+		return ctx
+	}
+	sm := sdkCtx.StreamingManager()
+	sm.StopNodeOnErr = l.stopNodeOnErr
+	return sdkCtx.WithStreamingManager(sm)
+}
+
+// stopNodeOnErr returns whether an error of the listener must stop the node.
+func stopNodeOnErr(listener storetypes.ABCIListener) bool {
+	l, ok := listener.(*filteredABCIListener)
+	return ok && l.stopNodeOnErr
+}
+
+func streamingQueueOption(key string) string {
+	return fmt.Sprintf("%s.%s.%s", StreamingTomlKey, StreamingQueueTomlKey, key)
 }
 
 func exposeAll(list []string) bool {
@@ -2,6 +2,7 @@ package baseapp_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"testing"
 
@@ -13,6 +14,7 @@ import (
 
 	"github.com/cosmos/cosmos-sdk/baseapp"
 	baseapptestutil "github.com/cosmos/cosmos-sdk/baseapp/testutil"
+	sdk "github.com/cosmos/cosmos-sdk/types"
 )
 
 var _ storetypes.ABCIListener = (*MockABCIListener)(nil)
@@ -146,3 +148,61 @@ func Test_Ctx_with_StreamingManager(t *testing.T) {
 		require.NoError(t, err)
 	}
 }
+
+type failingABCIListener struct {
+	err           error
+	stopNodeOnErr bool
+}
+
+func (l *failingABCIListener) ListenFinalizeBlock(ctx context.Context, _ abci.FinalizeBlockRequest, _ abci.FinalizeBlockResponse) error {
+	l.stopNodeOnErr = ctx.(storetypes.Context).StreamingManager().StopNodeOnErr
+	return nil
+}
+
+func (l *failingABCIListener) ListenCommit(_ context.Context, _ abci.CommitResponse, _ []*storetypes.StoreKVPair) error {
+	return l.err
+}
+
+func TestABCI_AddABCIListener(t *testing.T) {
+	for _, stopNodeOnErr := range []bool{false, true} {
+		t.Run(fmt.Sprintf("stop node on err %t", stopNodeOnErr), func(t *testing.T) {
+			capListener := NewMockABCIListener("cap")
+			distListener := NewMockABCIListener("dist")
+			failingListener := &failingABCIListener{err: errors.New("listener failed")}
+			distOpt := func(bapp *baseapp.BaseApp) { bapp.MountStores(distKey1) }
+			listenersOpt := func(bapp *baseapp.BaseApp) {
+				bapp.AddABCIListener([]storetypes.StoreKey{capKey1}, &capListener, false)
+				bapp.AddABCIListener([]storetypes.StoreKey{distKey1}, &distListener, false)
+				bapp.AddABCIListener(nil, failingListener, stopNodeOnErr)
+			}
+			beginBlockerOpt := func(bapp *baseapp.BaseApp) {
+				bapp.SetBeginBlocker(func(ctx sdk.Context) (sdk.BeginBlock, error) {
+					ctx.KVStore(capKey1).Set([]byte("cap-key"), []byte("cap-value"))
+					ctx.KVStore(distKey1).Set([]byte("dist-key"), []byte("dist-value"))
+					return sdk.BeginBlock{}, nil
+				})
+			}
+			suite := NewBaseAppSuite(t, distOpt, listenersOpt, beginBlockerOpt)
+
+			_, err := suite.baseApp.InitChain(&abci.InitChainRequest{
+				ConsensusParams: &tmproto.ConsensusParams{},
+			})
+			require.NoError(t, err)
+
+			_, err = suite.baseApp.FinalizeBlock(&abci.FinalizeBlockRequest{Height: 1})
+			require.NoError(t, err)
+			require.Equal(t, stopNodeOnErr, failingListener.stopNodeOnErr)
+
+			_, err = suite.baseApp.Commit()
+			if stopNodeOnErr {
+				require.ErrorContains(t, err, "listener failed")
+			} else {
+				require.NoError(t, err)
+			}
+
+			// every listener only receives the changes of its own stores
+			require.Equal(t, []*storetypes.StoreKVPair{{StoreKey: capKey1.Name(), Key: []byte("cap-key"), Value: []byte("cap-value")}}, capListener.ChangeSet)
+			require.Equal(t, []*storetypes.StoreKVPair{{StoreKey: distKey1.Name(), Key: []byte("dist-key"), Value: []byte("dist-value")}}, distListener.ChangeSet)
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package streamqueue
+
+import (
+	"context"
+
+	"cosmossdk.io/log"
+	storetypes "cosmossdk.io/store/types"
+)
+
+var _ storetypes.Context = deliveryContext{}
+
+// deliveryContext is the context passed to the listeners when delivering a block. The
+// listeners may expect a storetypes.Context, as the one passed by the app.
+type deliveryContext struct {
+	context.Context
+	height int64
+	logger log.Logger
+}
+
+func newDeliveryContext(height int64, logger log.Logger) deliveryContext {
+	return deliveryContext{Context: context.Background(), height: height, logger: logger}
+}
+
+// BlockHeight implements storetypes.Context.
+func (c deliveryContext) BlockHeight() int64 { return c.height }
+
+// Logger implements storetypes.Context.
+func (c deliveryContext) Logger() log.Logger { return c.logger }
+
+// StreamingManager implements storetypes.Context. Failing deliveries are retried by the
+// queue, so listeners must not stop the node.
+func (c deliveryContext) StreamingManager() storetypes.StreamingManager {
+	return storetypes.StreamingManager{StopNodeOnErr: false}
+}
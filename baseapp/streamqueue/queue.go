@@ -0,0 +1,537 @@
+// Package streamqueue implements a durable queue between the app and its streaming
+// listeners.
+//
+// The blocks streamed by the app are written to an on-disk queue before the app moves on,
+// and delivered asynchronously to every listener, which has its own cursor: the height of
+// the last block it received. Cursors are persisted once a block is delivered, so that
+// listeners resume where they stopped after a restart, and a block failing to be delivered
+// is retried until it succeeds. Blocks are thus delivered at least once, in order.
+//
+// Blocks are retained until every listener received them, and Retention blocks more, so
+// that listeners can replay them. Without listeners, the last Retention blocks are
+// retained. When MaxLag is set, the app waits for the listeners lagging more than MaxLag
+// blocks behind, applying back-pressure to consensus instead of growing the queue without
+// bound.
+package streamqueue
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+	dbm "github.com/cosmos/cosmos-db"
+	"github.com/hashicorp/go-metrics"
+
+	"cosmossdk.io/log"
+	storetypes "cosmossdk.io/store/types"
+
+	"github.com/cosmos/cosmos-sdk/telemetry"
+)
+
+var _ storetypes.ABCIListener = (*Queue)(nil)
+
+var (
+	recordPrefix = []byte{0x01}
+	cursorPrefix = []byte{0x02}
+	latestKey    = []byte{0x03}
+	replayPrefix = []byte{0x04}
+)
+
+// Config configures a Queue.
+type Config struct {
+	// Retention is the number of blocks retained once delivered to every listener, which
+	// can be replayed.
+	Retention uint64
+	// MaxLag is the number of blocks a listener can lag behind before the app waits for
+	// it. The app never waits if it is 0.
+	MaxLag uint64
+	// RetryInterval is the interval between the attempts to deliver a block.
+	RetryInterval time.Duration
+	// ReplayHeight is the height from which the listeners receive the blocks again when
+	// added, if non-zero. The replay is applied once per listener: it is ignored once
+	// applied, until the height is changed, and when the height isn't retained.
+	ReplayHeight int64
+}
+
+// DefaultConfig returns the default configuration of a Queue.
+func DefaultConfig() Config {
+	return Config{
+		Retention:     100,
+		RetryInterval: time.Second,
+	}
+}
+
+// Queue is an ABCIListener writing the blocks to a durable queue, from which they are
+// delivered to the listeners added to it.
+type Queue struct {
+	db     dbm.DB
+	logger log.Logger
+	cfg    Config
+
+	mtx  sync.Mutex
+	cond *sync.Cond
+	// latest is the height of the last queued block.
+	latest int64
+	// oldest is the height of the oldest retained block.
+	oldest    int64
+	listeners map[string]*cursor
+	closed    bool
+	wg        sync.WaitGroup
+	done      chan struct{}
+
+	// pending is the block being executed, set by ListenFinalizeBlock.
+	pending *record
+}
+
+// cursor tracks the delivery of the blocks to a listener.
+type cursor struct {
+	name     string
+	listener storetypes.ABCIListener
+	// storeKeys are the names of the stores whose changes are delivered, or nil for all.
+	storeKeys map[string]struct{}
+	// height is the height of the last block delivered to the listener.
+	height int64
+	// err is the error loading the next block, which stopped the delivery.
+	err error
+}
+
+// NewQueue opens the queue stored in the given database.
+func NewQueue(db dbm.DB, logger log.Logger, cfg Config) (*Queue, error) {
+	q := &Queue{
+		db:        db,
+		logger:    logger.With(log.ModuleKey, "streamqueue"),
+		cfg:       cfg,
+		listeners: make(map[string]*cursor),
+		done:      make(chan struct{}),
+	}
+	q.cond = sync.NewCond(&q.mtx)
+
+	latest, err := q.getHeight(latestKey)
+	if err != nil {
+		return nil, err
+	}
+	q.latest = latest
+
+	iter, err := db.Iterator(recordPrefix, storetypes.PrefixEndBytes(recordPrefix))
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+	q.oldest = latest + 1
+	if iter.Valid() {
+		q.oldest = int64(binary.BigEndian.Uint64(iter.Key()[len(recordPrefix):]))
+	}
+	return q, nil
+}
+
+// AddListener adds a listener, which receives the blocks following its cursor, with the
+// changes of the given stores, or of all the streamed stores if none is given. A new
+// listener receives the blocks queued from now on. The listener receives the blocks again
+// from ReplayHeight if it wasn't replayed from it yet.
+func (q *Queue) AddListener(name string, storeKeys []string, listener storetypes.ABCIListener) error {
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+
+	if _, ok := q.listeners[name]; ok {
+		return fmt.Errorf("listener %s already added", name)
+	}
+	height, err := q.getHeight(cursorKey(name))
+	if err != nil {
+		return err
+	}
+	if height == 0 {
+		height = q.latest
+	}
+	if height < q.oldest-1 {
+		q.logger.Error("blocks were pruned before being delivered", "listener", name, "cursor", height, "oldest", q.oldest)
+		height = q.oldest - 1
+	}
+
+	replay, err := q.applyReplayHeight(name)
+	if err != nil {
+		return err
+	}
+	if replay {
+		height = q.cfg.ReplayHeight - 1
+	}
+
+	c := &cursor{name: name, listener: listener, height: height}
+	if len(storeKeys) > 0 {
+		c.storeKeys = make(map[string]struct{}, len(storeKeys))
+		for _, key := range storeKeys {
+			c.storeKeys[key] = struct{}{}
+		}
+	}
+	if err := q.setCursor(c, height); err != nil {
+		return err
+	}
+	if replay {
+		// the replay is only applied once
+		if err := q.db.SetSync(replayKey(name), encodeHeight(q.cfg.ReplayHeight)); err != nil {
+			return err
+		}
+	}
+	q.listeners[name] = c
+	q.wg.Add(1)
+	go q.deliver(c)
+	return nil
+}
+
+// applyReplayHeight returns whether the listener must receive the blocks again from
+// ReplayHeight: the replay wasn't applied yet, and the height is retained. A height which
+// isn't retained is logged, and never prevents the listener from being added.
+func (q *Queue) applyReplayHeight(name string) (bool, error) {
+	if q.cfg.ReplayHeight <= 0 {
+		return false, nil
+	}
+	replayed, err := q.getHeight(replayKey(name))
+	if err != nil || replayed == q.cfg.ReplayHeight {
+		return false, err
+	}
+	if err := q.checkRetained(q.cfg.ReplayHeight); err != nil {
+		q.logger.Error("ignoring the replay height", "listener", name, "err", err)
+		return false, nil
+	}
+	return true, nil
+}
+
+// Replay makes the listener receive the blocks again from the given height, which must
+// be retained. The delivery is resumed if it was stopped by a block failing to load.
+func (q *Queue) Replay(name string, height int64) error {
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+
+	c, ok := q.listeners[name]
+	if !ok {
+		return fmt.Errorf("unknown listener %s", name)
+	}
+	if err := q.checkRetained(height); err != nil {
+		return err
+	}
+	if err := q.setCursor(c, height-1); err != nil {
+		return err
+	}
+	if c.err != nil && !q.closed {
+		c.err = nil
+		q.wg.Add(1)
+		go q.deliver(c)
+	}
+	q.cond.Broadcast()
+	return nil
+}
+
+// Cursor returns the height of the last block delivered to the listener. It returns an
+// error if the delivery stopped because the next block failed to load.
+func (q *Queue) Cursor(name string) (int64, error) {
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+
+	c, ok := q.listeners[name]
+	if !ok {
+		return 0, fmt.Errorf("unknown listener %s", name)
+	}
+	if c.err != nil {
+		return c.height, fmt.Errorf("delivery to listener %s stopped: %w", name, c.err)
+	}
+	return c.height, nil
+}
+
+// ListenFinalizeBlock implements storetypes.ABCIListener.
+func (q *Queue) ListenFinalizeBlock(_ context.Context, req abci.FinalizeBlockRequest, res abci.FinalizeBlockResponse) error {
+	q.pending = &record{finalizeReq: req, finalizeRes: res}
+	return nil
+}
+
+// ListenCommit implements storetypes.ABCIListener. It writes the block to the queue,
+// then waits for the listeners lagging more than MaxLag blocks behind, returning an error
+// if the delivery to one of them stopped.
+func (q *Queue) ListenCommit(_ context.Context, res abci.CommitResponse, changeSet []*storetypes.StoreKVPair) error {
+	r := q.pending
+	q.pending = nil
+	if r == nil {
+		return errors.New("commit without FinalizeBlock")
+	}
+	r.commitRes, r.changeSet = res, changeSet
+	height := r.finalizeReq.Height
+
+	bz, err := r.marshal()
+	if err != nil {
+		return err
+	}
+
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+
+	if height <= q.latest {
+		// the block is replayed after a restart, and was already queued
+		return nil
+	}
+	if q.latest > 0 && height != q.latest+1 {
+		q.logger.Error("blocks missing from the queue", "latest", q.latest, "height", height)
+		telemetry.IncrCounter(float32(height-q.latest-1), "streaming", "queue", "missing_blocks")
+	}
+
+	batch := q.db.NewBatch()
+	defer batch.Close()
+	if err := batch.Set(recordKey(height), bz); err != nil {
+		return err
+	}
+	if err := batch.Set(latestKey, encodeHeight(height)); err != nil {
+		return err
+	}
+	if err := batch.WriteSync(); err != nil {
+		return err
+	}
+	if q.latest == 0 || q.oldest > q.latest {
+		q.oldest = height
+	}
+	q.latest = height
+	q.prune()
+	q.cond.Broadcast()
+	q.reportMetrics()
+
+	if q.cfg.MaxLag == 0 {
+		return nil
+	}
+	start := time.Now()
+	defer telemetry.MeasureSince(start, "streaming", "queue", "backpressure")
+	for !q.closed {
+		lagging, err := q.lagging()
+		if err != nil || !lagging {
+			return err
+		}
+		q.cond.Wait()
+	}
+	return nil
+}
+
+// Close stops the delivery of the blocks, and closes the database.
+func (q *Queue) Close() error {
+	q.mtx.Lock()
+	if q.closed {
+		q.mtx.Unlock()
+		return nil
+	}
+	q.closed = true
+	close(q.done)
+	q.cond.Broadcast()
+	q.mtx.Unlock()
+
+	q.wg.Wait()
+	return q.db.Close()
+}
+
+// deliver delivers the blocks to a listener until the queue is closed, or a block can't be
+// loaded. The cursor is then left before that block, so that it is delivered again once
+// the listener is replayed or the node restarted.
+func (q *Queue) deliver(c *cursor) {
+	defer q.wg.Done()
+
+	for {
+		ok, err := q.deliverNext(c)
+		if err != nil {
+			q.logger.Error("stopping the delivery", "listener", c.name, "err", err)
+			q.mtx.Lock()
+			c.err = err
+			q.cond.Broadcast()
+			q.mtx.Unlock()
+			return
+		}
+		if !ok {
+			return
+		}
+	}
+}
+
+// deliverNext waits for the block following the cursor of a listener and delivers it. It
+// returns false if the queue was closed first, and the error loading the block, in which
+// case the cursor isn't advanced.
+func (q *Queue) deliverNext(c *cursor) (bool, error) {
+	q.mtx.Lock()
+	for !q.closed && c.height >= q.latest {
+		q.cond.Wait()
+	}
+	if q.closed {
+		q.mtx.Unlock()
+		return false, nil
+	}
+	if c.height < q.oldest-1 {
+		// the queue was started after the chain, the first blocks were never queued
+		if err := q.setCursor(c, q.oldest-1); err != nil {
+			q.logger.Error("failed to save cursor", "listener", c.name, "err", err)
+		}
+	}
+	height := c.height + 1
+	q.mtx.Unlock()
+
+	r, err := q.loadRecord(height)
+	if err != nil {
+		return false, fmt.Errorf("failed to load block %d: %w", height, err)
+	}
+	if !q.deliverRecord(c, r) {
+		return false, nil
+	}
+
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+	// the cursor may have been moved by Replay while delivering
+	if c.height == height-1 {
+		if err := q.setCursor(c, height); err != nil {
+			q.logger.Error("failed to save cursor", "listener", c.name, "height", height, "err", err)
+		}
+	}
+	q.prune()
+	q.reportMetrics()
+	q.cond.Broadcast()
+	return true, nil
+}
+
+// deliverRecord delivers a block to a listener, retrying until it succeeds. It returns
+// false if the queue was closed first.
+func (q *Queue) deliverRecord(c *cursor, r *record) bool {
+	height := r.finalizeReq.Height
+	ctx := newDeliveryContext(height, q.logger)
+	changeSet := c.filter(r.changeSet)
+	for {
+		err := c.listener.ListenFinalizeBlock(ctx, r.finalizeReq, r.finalizeRes)
+		if err == nil {
+			err = c.listener.ListenCommit(ctx, r.commitRes, changeSet)
+		}
+		if err == nil {
+			return true
+		}
+
+		q.logger.Error("failed to deliver block, retrying", "listener", c.name, "height", height, "err", err)
+		telemetry.IncrCounterWithLabels([]string{"streaming", "queue", "delivery_errors"}, 1, listenerLabels(c))
+		select {
+		case <-q.done:
+			return false
+		case <-time.After(q.cfg.RetryInterval):
+		}
+	}
+}
+
+// filter returns the changes of the stores streamed to the listener.
+func (c *cursor) filter(changeSet []*storetypes.StoreKVPair) []*storetypes.StoreKVPair {
+	if c.storeKeys == nil {
+		return changeSet
+	}
+	filtered := make([]*storetypes.StoreKVPair, 0, len(changeSet))
+	for _, pair := range changeSet {
+		if _, ok := c.storeKeys[pair.StoreKey]; ok {
+			filtered = append(filtered, pair)
+		}
+	}
+	return filtered
+}
+
+// checkRetained returns an error if the blocks from the given height can't be replayed.
+func (q *Queue) checkRetained(height int64) error {
+	if height < q.oldest || height > q.latest+1 {
+		return fmt.Errorf("height %d is not retained, retained heights are %d to %d", height, q.oldest, q.latest)
+	}
+	return nil
+}
+
+// lagging returns whether a listener lags more than MaxLag blocks behind, and an error
+// if the delivery to such a listener stopped, so that it would never catch up.
+func (q *Queue) lagging() (bool, error) {
+	lagging := false
+	for _, c := range q.listeners {
+		if uint64(q.latest-c.height) <= q.cfg.MaxLag {
+			continue
+		}
+		if c.err != nil {
+			return true, fmt.Errorf("delivery to listener %s stopped: %w", c.name, c.err)
+		}
+		lagging = true
+	}
+	return lagging, nil
+}
+
+// prune deletes the blocks delivered to every listener, except the retained ones.
+func (q *Queue) prune() {
+	delivered := q.latest
+	for _, c := range q.listeners {
+		if c.height < delivered {
+			delivered = c.height
+		}
+	}
+	until := delivered - int64(q.cfg.Retention)
+	if until < q.oldest {
+		return
+	}
+
+	batch := q.db.NewBatch()
+	defer batch.Close()
+	for height := q.oldest; height <= until; height++ {
+		if err := batch.Delete(recordKey(height)); err != nil {
+			q.logger.Error("failed to prune queued blocks", "err", err)
+			return
+		}
+	}
+	if err := batch.Write(); err != nil {
+		q.logger.Error("failed to prune queued blocks", "err", err)
+		return
+	}
+	q.oldest = until + 1
+}
+
+// reportMetrics reports the size of the queue and the lag of the listeners.
+func (q *Queue) reportMetrics() {
+	telemetry.SetGauge(float32(q.latest-q.oldest+1), "streaming", "queue", "size")
+	for _, c := range q.listeners {
+		telemetry.SetGaugeWithLabels([]string{"streaming", "queue", "lag"}, float32(q.latest-c.height), listenerLabels(c))
+	}
+}
+
+func (q *Queue) loadRecord(height int64) (*record, error) {
+	bz, err := q.db.Get(recordKey(height))
+	if err != nil {
+		return nil, err
+	}
+	if bz == nil {
+		return nil, fmt.Errorf("block %d not found", height)
+	}
+	return unmarshalRecord(bz)
+}
+
+func (q *Queue) setCursor(c *cursor, height int64) error {
+	if err := q.db.SetSync(cursorKey(c.name), encodeHeight(height)); err != nil {
+		return err
+	}
+	c.height = height
+	return nil
+}
+
+func (q *Queue) getHeight(key []byte) (int64, error) {
+	bz, err := q.db.Get(key)
+	if err != nil || bz == nil {
+		return 0, err
+	}
+	return int64(binary.BigEndian.Uint64(bz)), nil
+}
+
+func listenerLabels(c *cursor) []metrics.Label {
+	return []metrics.Label{telemetry.NewLabel("listener", c.name)}
+}
+
+func recordKey(height int64) []byte {
+	return append(append([]byte{}, recordPrefix...), encodeHeight(height)...)
+}
+
+func cursorKey(name string) []byte {
+	return append(append([]byte{}, cursorPrefix...), name...)
+}
+
+func replayKey(name string) []byte {
+	return append(append([]byte{}, replayPrefix...), name...)
+}
+
+func encodeHeight(height int64) []byte {
+	return binary.BigEndian.AppendUint64(nil, uint64(height))
+}
@@ -0,0 +1,284 @@
+package streamqueue_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+	dbm "github.com/cosmos/cosmos-db"
+	"github.com/stretchr/testify/require"
+
+	"cosmossdk.io/log"
+	storetypes "cosmossdk.io/store/types"
+
+	"github.com/cosmos/cosmos-sdk/baseapp/streamqueue"
+)
+
+var _ storetypes.ABCIListener = (*recordingListener)(nil)
+
+// recordingListener records the blocks delivered to it, failing the first deliveries.
+type recordingListener struct {
+	mtx      sync.Mutex
+	height   int64
+	heights  []int64
+	changes  [][]*storetypes.StoreKVPair
+	failures int
+	release  chan struct{}
+}
+
+func (l *recordingListener) ListenFinalizeBlock(_ context.Context, req abci.FinalizeBlockRequest, _ abci.FinalizeBlockResponse) error {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	l.height = req.Height
+	return nil
+}
+
+func (l *recordingListener) ListenCommit(_ context.Context, _ abci.CommitResponse, changeSet []*storetypes.StoreKVPair) error {
+	if l.release != nil {
+		<-l.release
+	}
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	if l.failures > 0 {
+		l.failures--
+		return errors.New("unavailable")
+	}
+	l.heights = append(l.heights, l.height)
+	l.changes = append(l.changes, changeSet)
+	return nil
+}
+
+func (l *recordingListener) delivered() []int64 {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	return append([]int64{}, l.heights...)
+}
+
+func openQueue(t *testing.T, dir string, cfg streamqueue.Config) *streamqueue.Queue {
+	t.Helper()
+	db, err := dbm.NewGoLevelDB("streaming", dir, nil)
+	require.NoError(t, err)
+	queue, err := streamqueue.NewQueue(db, log.NewNopLogger(), cfg)
+	require.NoError(t, err)
+	return queue
+}
+
+func testConfig() streamqueue.Config {
+	cfg := streamqueue.DefaultConfig()
+	cfg.RetryInterval = time.Millisecond
+	return cfg
+}
+
+func commit(t *testing.T, queue *streamqueue.Queue, height int64) {
+	t.Helper()
+	changeSet := []*storetypes.StoreKVPair{
+		{StoreKey: "bank", Key: []byte{byte(height)}, Value: []byte("bank")},
+		{StoreKey: "gov", Key: []byte{byte(height)}, Value: []byte("gov")},
+	}
+	require.NoError(t, queue.ListenFinalizeBlock(context.Background(), abci.FinalizeBlockRequest{Height: height}, abci.FinalizeBlockResponse{}))
+	require.NoError(t, queue.ListenCommit(context.Background(), abci.CommitResponse{}, changeSet))
+}
+
+func requireDelivered(t *testing.T, listener *recordingListener, heights ...int64) {
+	t.Helper()
+	require.Eventually(t, func() bool {
+		return len(listener.delivered()) >= len(heights)
+	}, 5*time.Second, time.Millisecond)
+	require.Equal(t, heights, listener.delivered())
+}
+
+func TestQueueDelivery(t *testing.T) {
+	queue := openQueue(t, t.TempDir(), testConfig())
+	defer queue.Close()
+
+	all, bank := &recordingListener{}, &recordingListener{failures: 3}
+	require.NoError(t, queue.AddListener("all", nil, all))
+	require.NoError(t, queue.AddListener("bank", []string{"bank"}, bank))
+	require.Error(t, queue.AddListener("bank", nil, bank))
+
+	for height := int64(1); height <= 3; height++ {
+		commit(t, queue, height)
+	}
+
+	requireDelivered(t, all, 1, 2, 3)
+	require.Len(t, all.changes[0], 2)
+	// failing deliveries are retried
+	requireDelivered(t, bank, 1, 2, 3)
+	require.Equal(t, []*storetypes.StoreKVPair{{StoreKey: "bank", Key: []byte{3}, Value: []byte("bank")}}, bank.changes[2])
+
+	cursor, err := queue.Cursor("bank")
+	require.NoError(t, err)
+	require.Equal(t, int64(3), cursor)
+}
+
+func TestQueueResume(t *testing.T) {
+	dir := t.TempDir()
+	queue := openQueue(t, dir, testConfig())
+
+	failing := &recordingListener{}
+	require.NoError(t, queue.AddListener("indexer", nil, failing))
+	commit(t, queue, 1)
+	requireDelivered(t, failing, 1)
+	failing.mtx.Lock()
+	failing.failures = 1000
+	failing.mtx.Unlock()
+	commit(t, queue, 2)
+	require.NoError(t, queue.Close())
+
+	// the listener resumes from its cursor, and receives the block it failed to receive
+	queue = openQueue(t, dir, testConfig())
+	defer queue.Close()
+	listener := &recordingListener{}
+	require.NoError(t, queue.AddListener("indexer", nil, listener))
+	commit(t, queue, 3)
+	// blocks already queued are ignored
+	commit(t, queue, 3)
+	requireDelivered(t, listener, 2, 3)
+}
+
+func TestQueueReplay(t *testing.T) {
+	cfg := testConfig()
+	cfg.Retention = 2
+	queue := openQueue(t, t.TempDir(), cfg)
+	defer queue.Close()
+
+	listener := &recordingListener{}
+	require.NoError(t, queue.AddListener("indexer", nil, listener))
+	for height := int64(1); height <= 5; height++ {
+		commit(t, queue, height)
+	}
+	requireDelivered(t, listener, 1, 2, 3, 4, 5)
+
+	// only the last blocks are retained
+	require.Eventually(t, func() bool {
+		return queue.Replay("indexer", 2) != nil
+	}, 5*time.Second, time.Millisecond)
+	require.Error(t, queue.Replay("unknown", 4))
+	require.NoError(t, queue.Replay("indexer", 4))
+	requireDelivered(t, listener, 1, 2, 3, 4, 5, 4, 5)
+}
+
+func TestQueueReplayHeight(t *testing.T) {
+	dir := t.TempDir()
+	queue := openQueue(t, dir, testConfig())
+	listener := &recordingListener{}
+	require.NoError(t, queue.AddListener("indexer", nil, listener))
+	for height := int64(1); height <= 3; height++ {
+		commit(t, queue, height)
+	}
+	requireDelivered(t, listener, 1, 2, 3)
+	require.NoError(t, queue.Close())
+
+	// the listener receives the blocks again from the replay height
+	cfg := testConfig()
+	cfg.ReplayHeight = 2
+	queue = openQueue(t, dir, cfg)
+	listener = &recordingListener{}
+	require.NoError(t, queue.AddListener("indexer", nil, listener))
+	requireDelivered(t, listener, 2, 3)
+	require.NoError(t, queue.Close())
+
+	// the replay is only applied once
+	queue = openQueue(t, dir, cfg)
+	listener = &recordingListener{}
+	require.NoError(t, queue.AddListener("indexer", nil, listener))
+	commit(t, queue, 4)
+	requireDelivered(t, listener, 4)
+	require.NoError(t, queue.Close())
+
+	// a height which isn't retained is ignored
+	cfg.ReplayHeight = 10
+	queue = openQueue(t, dir, cfg)
+	defer queue.Close()
+	listener = &recordingListener{}
+	require.NoError(t, queue.AddListener("indexer", nil, listener))
+	commit(t, queue, 5)
+	requireDelivered(t, listener, 5)
+}
+
+func TestQueueRetentionWithoutListeners(t *testing.T) {
+	cfg := testConfig()
+	cfg.Retention = 2
+	queue := openQueue(t, t.TempDir(), cfg)
+	defer queue.Close()
+
+	for height := int64(1); height <= 5; height++ {
+		commit(t, queue, height)
+	}
+
+	// only the last blocks are retained
+	listener := &recordingListener{}
+	require.NoError(t, queue.AddListener("indexer", nil, listener))
+	require.Error(t, queue.Replay("indexer", 3))
+	require.NoError(t, queue.Replay("indexer", 4))
+	requireDelivered(t, listener, 4, 5)
+}
+
+func TestQueueBackPressure(t *testing.T) {
+	cfg := testConfig()
+	cfg.MaxLag = 1
+	queue := openQueue(t, t.TempDir(), cfg)
+	defer queue.Close()
+
+	listener := &recordingListener{release: make(chan struct{})}
+	require.NoError(t, queue.AddListener("indexer", nil, listener))
+	commit(t, queue, 1)
+
+	committed := make(chan struct{})
+	go func() {
+		commit(t, queue, 2)
+		close(committed)
+	}()
+	// the app waits for the listener lagging 2 blocks behind
+	select {
+	case <-committed:
+		t.Fatal("commit did not wait for the listener")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	listener.release <- struct{}{}
+	<-committed
+	close(listener.release)
+	requireDelivered(t, listener, 1, 2)
+}
+
+func TestQueueCorruptedBlock(t *testing.T) {
+	dir := t.TempDir()
+	queue := openQueue(t, dir, testConfig())
+	commit(t, queue, 1)
+	commit(t, queue, 2)
+	require.NoError(t, queue.Close())
+
+	db, err := dbm.NewGoLevelDB("streaming", dir, nil)
+	require.NoError(t, err)
+	require.NoError(t, db.Set([]byte{0x01, 0, 0, 0, 0, 0, 0, 0, 2}, []byte("corrupted")))
+	require.NoError(t, db.Close())
+
+	cfg := testConfig()
+	cfg.MaxLag = 1
+	cfg.ReplayHeight = 1
+	queue = openQueue(t, dir, cfg)
+	defer queue.Close()
+	listener := &recordingListener{}
+	require.NoError(t, queue.AddListener("indexer", nil, listener))
+	requireDelivered(t, listener, 1)
+
+	// the delivery stops before the block which can't be loaded
+	require.Eventually(t, func() bool {
+		_, err := queue.Cursor("indexer")
+		return err != nil
+	}, 5*time.Second, time.Millisecond)
+	cursor, _ := queue.Cursor("indexer")
+	require.Equal(t, int64(1), cursor)
+
+	// the app doesn't wait for the stopped listener, but fails
+	require.NoError(t, queue.ListenFinalizeBlock(context.Background(), abci.FinalizeBlockRequest{Height: 3}, abci.FinalizeBlockResponse{}))
+	require.ErrorContains(t, queue.ListenCommit(context.Background(), abci.CommitResponse{}, nil), "indexer")
+
+	// replaying resumes the delivery
+	require.NoError(t, queue.Replay("indexer", 1))
+	requireDelivered(t, listener, 1, 1)
+}
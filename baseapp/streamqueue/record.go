@@ -0,0 +1,72 @@
+package streamqueue
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+	protoio "github.com/cosmos/gogoproto/io"
+
+	storetypes "cosmossdk.io/store/types"
+)
+
+// maxRecordItemSize is the maximum size of an item of a record.
+const maxRecordItemSize = 1 << 30
+
+// record is a block queued for the listeners.
+type record struct {
+	finalizeReq abci.FinalizeBlockRequest
+	finalizeRes abci.FinalizeBlockResponse
+	commitRes   abci.CommitResponse
+	changeSet   []*storetypes.StoreKVPair
+}
+
+// marshal encodes the record as a stream of delimited protobuf messages: the FinalizeBlock
+// request and response, the Commit response, then the state changes.
+func (r *record) marshal() ([]byte, error) {
+	buf := new(bytes.Buffer)
+	writer := protoio.NewDelimitedWriter(buf)
+	if err := writer.WriteMsg(&r.finalizeReq); err != nil {
+		return nil, err
+	}
+	if err := writer.WriteMsg(&r.finalizeRes); err != nil {
+		return nil, err
+	}
+	if err := writer.WriteMsg(&r.commitRes); err != nil {
+		return nil, err
+	}
+	for _, pair := range r.changeSet {
+		if err := writer.WriteMsg(pair); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// unmarshalRecord decodes a record encoded by marshal.
+func unmarshalRecord(bz []byte) (*record, error) {
+	r := &record{}
+	reader := protoio.NewDelimitedReader(bytes.NewReader(bz), maxRecordItemSize)
+	if err := reader.ReadMsg(&r.finalizeReq); err != nil {
+		return nil, fmt.Errorf("read FinalizeBlock request: %w", err)
+	}
+	if err := reader.ReadMsg(&r.finalizeRes); err != nil {
+		return nil, fmt.Errorf("read FinalizeBlock response: %w", err)
+	}
+	if err := reader.ReadMsg(&r.commitRes); err != nil {
+		return nil, fmt.Errorf("read Commit response: %w", err)
+	}
+	for {
+		pair := &storetypes.StoreKVPair{}
+		err := reader.ReadMsg(pair)
+		if errors.Is(err, io.EOF) {
+			return r, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read state change: %w", err)
+		}
+		r.changeSet = append(r.changeSet, pair)
+	}
+}
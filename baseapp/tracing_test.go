@@ -0,0 +1,108 @@
+package baseapp_test
+
+import (
+	"testing"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+	cmtproto "github.com/cometbft/cometbft/api/cometbft/types/v1"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/cosmos/cosmos-sdk/baseapp"
+	baseapptestutil "github.com/cosmos/cosmos-sdk/baseapp/testutil"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// anteTestDecorator runs the ante handler of the tests as a decorator.
+type anteTestDecorator struct {
+	handler sdk.AnteHandler
+}
+
+func (d anteTestDecorator) AnteHandle(ctx sdk.Context, tx sdk.Tx, simulate bool, next sdk.AnteHandler) (sdk.Context, error) {
+	newCtx, err := d.handler(ctx, tx, simulate)
+	if err != nil {
+		return newCtx, err
+	}
+	return next(newCtx, tx, simulate)
+}
+
+func TestABCI_FinalizeBlock_Tracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(provider) })
+
+	anteKey := []byte("ante-key")
+	anteOpt := func(bapp *baseapp.BaseApp) {
+		bapp.SetAnteHandler(sdk.ChainAnteDecorators(anteTestDecorator{anteHandlerTxTest(t, capKey1, anteKey)}))
+	}
+	suite := NewBaseAppSuite(t, anteOpt)
+
+	_, err := suite.baseApp.InitChain(&abci.InitChainRequest{
+		ConsensusParams: &cmtproto.ConsensusParams{},
+	})
+	require.NoError(t, err)
+
+	deliverKey := []byte("deliver-key")
+	baseapptestutil.RegisterCounterServer(suite.baseApp.MsgServiceRouter(), CounterServerImpl{t, capKey1, deliverKey})
+
+	tx := newTxCounter(t, suite.txConfig, 0, 0)
+	txBytes, err := suite.txConfig.TxEncoder()(tx)
+	require.NoError(t, err)
+
+	res, err := suite.baseApp.FinalizeBlock(&abci.FinalizeBlockRequest{
+		Height: 1,
+		Txs:    [][]byte{txBytes},
+	})
+	require.NoError(t, err)
+	require.True(t, res.TxResults[0].IsOK())
+	_, err = suite.baseApp.Commit()
+	require.NoError(t, err)
+
+	spans := make(map[string]sdktrace.ReadOnlySpan)
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+	for _, name := range []string{"FinalizeBlock", "tx", "ante", "msg", "store.write", "store.commit"} {
+		require.Contains(t, spans, name)
+	}
+
+	block := spans["FinalizeBlock"].SpanContext()
+	txSpan := spans["tx"]
+	require.Equal(t, block.SpanID(), txSpan.Parent().SpanID())
+	require.Equal(t, txSpan.SpanContext().SpanID(), spans["ante"].Parent().SpanID())
+	// the message is executed after the ante handler, as a child of the tx
+	require.Equal(t, txSpan.SpanContext().SpanID(), spans["msg"].Parent().SpanID())
+	require.Equal(t, block.TraceID(), spans["store.commit"].SpanContext().TraceID())
+
+	requireAttribute(t, spans["msg"], "type", sdk.MsgTypeURL(&baseapptestutil.MsgCounter{}))
+	requireAttribute(t, spans["tx"], "mode", "finalize")
+	requireAttribute(t, spans["ante"], "decorator", "baseapp_test.anteTestDecorator")
+	// the gas used by the decorators and the messages is recorded, they both access the store
+	require.Positive(t, int64Attribute(t, spans["ante"], "gas_used"))
+	require.Positive(t, int64Attribute(t, spans["msg"], "gas_used"))
+}
+
+func requireAttribute(t *testing.T, span sdktrace.ReadOnlySpan, key, value string) {
+	t.Helper()
+	for _, attr := range span.Attributes() {
+		if string(attr.Key) == key {
+			require.Equal(t, value, attr.Value.AsString())
+			return
+		}
+	}
+	require.Failf(t, "missing attribute", "span %s has no attribute %s", span.Name(), key)
+}
+
+func int64Attribute(t *testing.T, span sdktrace.ReadOnlySpan, key string) int64 {
+	t.Helper()
+	for _, attr := range span.Attributes() {
+		if string(attr.Key) == key {
+			return attr.Value.AsInt64()
+		}
+	}
+	require.Failf(t, "missing attribute", "span %s has no attribute %s", span.Name(), key)
+	return 0
+}
@@ -0,0 +1,138 @@
+package baseapp
+
+import (
+	"fmt"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+	cmttypes "github.com/cometbft/cometbft/types"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/voteext"
+)
+
+// VoteExtensionHandler defines the ABCI handlers of the vote extensions multiplexing the
+// providers registered with a voteext.Manager.
+//
+// The proposal handlers wrap the ones of the application: the PrepareProposal handler
+// injects the results aggregated from the vote extensions as the first transaction of the
+// proposal, and the ProcessProposal handler verifies them, before calling the wrapped
+// handlers with the other transactions. The PreBlocker applies the results before the
+// transactions of the block are executed. The injected pseudo-transaction is then
+// executed as the other transactions, and fails to decode.
+type VoteExtensionHandler struct {
+	manager  *voteext.Manager
+	valStore ValidatorStore
+}
+
+// NewVoteExtensionHandler creates the ABCI handlers of the providers registered with the
+// manager.
+func NewVoteExtensionHandler(manager *voteext.Manager, valStore ValidatorStore) *VoteExtensionHandler {
+	return &VoteExtensionHandler{
+		manager:  manager,
+		valStore: valStore,
+	}
+}
+
+// ExtendVoteHandler returns the ExtendVote handler multiplexing the values of the
+// providers. Providers failing to return a value are left out of the vote extension.
+func (h *VoteExtensionHandler) ExtendVoteHandler() sdk.ExtendVoteHandler {
+	return func(ctx sdk.Context, req *abci.ExtendVoteRequest) (*abci.ExtendVoteResponse, error) {
+		ext, err := h.manager.ExtendVote(ctx, req.Height)
+		if err != nil {
+			ctx.Logger().Error("failed to extend vote", "height", req.Height, "err", err)
+		}
+		return &abci.ExtendVoteResponse{VoteExtension: ext}, nil
+	}
+}
+
+// VerifyVoteExtensionHandler returns the VerifyVoteExtension handler verifying the values
+// of the vote extension with their providers.
+func (h *VoteExtensionHandler) VerifyVoteExtensionHandler() sdk.VerifyVoteExtensionHandler {
+	return func(ctx sdk.Context, req *abci.VerifyVoteExtensionRequest) (*abci.VerifyVoteExtensionResponse, error) {
+		if err := h.manager.VerifyVoteExtension(ctx, req.Height, req.VoteExtension); err != nil {
+			ctx.Logger().Error("rejected vote extension", "height", req.Height, "validator", fmt.Sprintf("%X", req.ValidatorAddress), "err", err)
+			return &abci.VerifyVoteExtensionResponse{Status: abci.VERIFY_VOTE_EXTENSION_STATUS_REJECT}, nil
+		}
+		return &abci.VerifyVoteExtensionResponse{Status: abci.VERIFY_VOTE_EXTENSION_STATUS_ACCEPT}, nil
+	}
+}
+
+// PrepareProposalHandler returns a PrepareProposal handler injecting the results
+// aggregated from the vote extensions of the last commit as the first transaction of the
+// proposal, followed by the transactions selected by the given handler.
+func (h *VoteExtensionHandler) PrepareProposalHandler(next sdk.PrepareProposalHandler) sdk.PrepareProposalHandler {
+	return func(ctx sdk.Context, req *abci.PrepareProposalRequest) (*abci.PrepareProposalResponse, error) {
+		cp := ctx.ConsensusParams() // nolint:staticcheck // ignore linting error
+		if !voteext.InProposal(&cp, req.Height) {
+			return next(ctx, req)
+		}
+
+		if err := ValidateVoteExtensions(ctx, h.valStore, req.LocalLastCommit); err != nil {
+			return nil, fmt.Errorf("invalid vote extensions: %w", err)
+		}
+		injectedTx, err := h.manager.BuildInjectedTx(ctx, req.LocalLastCommit)
+		if err != nil {
+			return nil, fmt.Errorf("failed to aggregate vote extensions: %w", err)
+		}
+		size := cmttypes.ComputeProtoSizeForTxs([]cmttypes.Tx{injectedTx})
+		if size > req.MaxTxBytes {
+			return nil, fmt.Errorf("injected vote extensions exceed the max tx bytes: %d > %d", size, req.MaxTxBytes)
+		}
+
+		nextReq := *req
+		nextReq.MaxTxBytes -= size
+		resp, err := next(ctx, &nextReq)
+		if err != nil {
+			return nil, err
+		}
+		resp.Txs = append([][]byte{injectedTx}, resp.Txs...)
+		return resp, nil
+	}
+}
+
+// ProcessProposalHandler returns a ProcessProposal handler verifying the results injected
+// as the first transaction of the proposal, then the other transactions with the given
+// handler.
+func (h *VoteExtensionHandler) ProcessProposalHandler(next sdk.ProcessProposalHandler) sdk.ProcessProposalHandler {
+	return func(ctx sdk.Context, req *abci.ProcessProposalRequest) (*abci.ProcessProposalResponse, error) {
+		cp := ctx.ConsensusParams() // nolint:staticcheck // ignore linting error
+		if !voteext.InProposal(&cp, req.Height) {
+			return next(ctx, req)
+		}
+
+		reject := &abci.ProcessProposalResponse{Status: abci.PROCESS_PROPOSAL_STATUS_REJECT}
+		if len(req.Txs) == 0 || !voteext.IsInjectedTx(req.Txs[0]) {
+			ctx.Logger().Error("proposal without injected vote extensions", "height", req.Height)
+			return reject, nil
+		}
+		extCommit, err := h.manager.VerifyInjectedTx(ctx, req.Txs[0])
+		if err != nil {
+			ctx.Logger().Error("invalid injected vote extensions", "height", req.Height, "err", err)
+			return reject, nil
+		}
+		if err := ValidateVoteExtensions(ctx, h.valStore, extCommit); err != nil {
+			ctx.Logger().Error("invalid injected vote extensions", "height", req.Height, "err", err)
+			return reject, nil
+		}
+
+		nextReq := *req
+		nextReq.Txs = req.Txs[1:]
+		return next(ctx, &nextReq)
+	}
+}
+
+// PreBlocker returns a PreBlocker applying the results injected in the block with their
+// providers, then calling the given PreBlocker, if any.
+func (h *VoteExtensionHandler) PreBlocker(next sdk.PreBlocker) sdk.PreBlocker {
+	return func(ctx sdk.Context, req *abci.FinalizeBlockRequest) error {
+		if len(req.Txs) > 0 && voteext.IsInjectedTx(req.Txs[0]) {
+			if err := h.manager.ApplyInjectedTx(ctx, req.Txs[0]); err != nil {
+				return fmt.Errorf("failed to apply injected vote extensions: %w", err)
+			}
+		}
+		if next == nil {
+			return nil
+		}
+		return next(ctx, req)
+	}
+}
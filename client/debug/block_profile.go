@@ -0,0 +1,633 @@
+package debug
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+	"github.com/cometbft/cometbft/crypto/tmhash"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"cosmossdk.io/store/gaskv"
+)
+
+// BlockProfile is the report of the re-execution of a block by the profile-block command.
+type BlockProfile struct {
+	Height  int64  `json:"height"`
+	ChainID string `json:"chain_id"`
+	// Duration covers the whole execution of the block, including the computation of
+	// the app hash.
+	Duration time.Duration `json:"duration"`
+	// GasUsed is the gas used by the transactions of the block.
+	GasUsed int64 `json:"gas_used"`
+	// AppHash is the app hash of the re-execution, and ExpectedAppHash the one committed
+	// by the chain, if the next block is stored. They differ when the application does
+	// not execute the block like the one which committed it.
+	AppHash         string `json:"app_hash"`
+	ExpectedAppHash string `json:"expected_app_hash,omitempty"`
+	// Phases are the steps of the block other than its transactions, e.g. PreBlock,
+	// BeginBlock, EndBlock and the write of the block state, in order.
+	Phases []StepProfile `json:"phases"`
+	// Txs are the transactions of the block, in order.
+	Txs []TxProfile `json:"txs"`
+	// Stores are the accesses to each store during the block, sorted by store name.
+	Stores []StoreAccesses `json:"stores"`
+}
+
+// TxProfile is the profile of the execution of a transaction.
+type TxProfile struct {
+	Index     int           `json:"index"`
+	Hash      string        `json:"hash"`
+	Code      uint32        `json:"code"`
+	Codespace string        `json:"codespace,omitempty"`
+	GasWanted int64         `json:"gas_wanted"`
+	GasUsed   int64         `json:"gas_used"`
+	Duration  time.Duration `json:"duration"`
+	// AnteDecorators are the ante decorators run, in order. Their duration, gas and
+	// store accesses exclude the ones of the next decorators, which they call.
+	AnteDecorators []StepProfile `json:"ante_decorators"`
+	// Msgs are the messages executed, in order.
+	Msgs []StepProfile `json:"msgs"`
+	// Stores are the accesses to each store during the transaction, sorted by store name.
+	Stores []StoreAccesses `json:"stores"`
+}
+
+// StepProfile is the profile of a step of the execution of a block.
+type StepProfile struct {
+	Name     string          `json:"name"`
+	Duration time.Duration   `json:"duration"`
+	GasUsed  int64           `json:"gas_used,omitempty"`
+	Error    string          `json:"error,omitempty"`
+	Stores   []StoreAccesses `json:"stores,omitempty"`
+}
+
+// StoreAccesses counts the accesses to a store through the gas metered stores.
+type StoreAccesses struct {
+	Store   string `json:"store"`
+	Reads   int    `json:"reads"`
+	Writes  int    `json:"writes"`
+	Has     int    `json:"has"`
+	Deletes int    `json:"deletes"`
+	// Iterations counts the iterator creations and steps.
+	Iterations int `json:"iterations"`
+	// ReadBytes is the size of the keys and values read, including by the iterators,
+	// and WriteBytes the size of the keys and values written.
+	ReadBytes  int `json:"read_bytes"`
+	WriteBytes int `json:"write_bytes"`
+}
+
+// Total returns the number of accesses to the store.
+func (a StoreAccesses) Total() int {
+	return a.Reads + a.Writes + a.Has + a.Deletes + a.Iterations
+}
+
+func (a *StoreAccesses) add(access gaskv.Access, n int) {
+	switch access {
+	case gaskv.AccessRead:
+		a.Reads++
+		a.ReadBytes += n
+	case gaskv.AccessWrite:
+		a.Writes++
+		a.WriteBytes += n
+	case gaskv.AccessHas:
+		a.Has++
+	case gaskv.AccessDelete:
+		a.Deletes++
+	case gaskv.AccessIterate:
+		a.Iterations++
+		a.ReadBytes += n
+	}
+}
+
+func (a *StoreAccesses) merge(b StoreAccesses) {
+	a.Reads += b.Reads
+	a.Writes += b.Writes
+	a.Has += b.Has
+	a.Deletes += b.Deletes
+	a.Iterations += b.Iterations
+	a.ReadBytes += b.ReadBytes
+	a.WriteBytes += b.WriteBytes
+}
+
+// storeAccesses are the accesses to each store, by store name.
+type storeAccesses map[string]*StoreAccesses
+
+func (s storeAccesses) merge(o storeAccesses) {
+	for name, a := range o {
+		if s[name] == nil {
+			s[name] = &StoreAccesses{Store: name}
+		}
+		s[name].merge(*a)
+	}
+}
+
+func (s storeAccesses) total() int {
+	var total int
+	for _, a := range s {
+		total += a.Total()
+	}
+	return total
+}
+
+// sorted returns the accesses sorted by store name.
+func (s storeAccesses) sorted() []StoreAccesses {
+	sorted := make([]StoreAccesses, 0, len(s))
+	for _, a := range s {
+		sorted = append(sorted, *a)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Store < sorted[j].Store })
+	return sorted
+}
+
+// blockProfiler records the spans of the execution of a block, and the accesses to
+// the gas metered stores, each attributed to the innermost span running. It is a
+// span processor of the OpenTelemetry SDK, and its observeAccess method a
+// gaskv.AccessObserver.
+type blockProfiler struct {
+	mtx      sync.Mutex
+	running  []trace.SpanID // innermost last
+	spans    []sdktrace.ReadOnlySpan
+	accesses map[trace.SpanID]storeAccesses // the zero span ID holds the accesses outside spans
+}
+
+var _ sdktrace.SpanProcessor = (*blockProfiler)(nil)
+
+func newBlockProfiler() *blockProfiler {
+	return &blockProfiler{accesses: make(map[trace.SpanID]storeAccesses)}
+}
+
+// OnStart implements sdktrace.SpanProcessor.
+func (p *blockProfiler) OnStart(_ context.Context, s sdktrace.ReadWriteSpan) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	p.running = append(p.running, s.SpanContext().SpanID())
+}
+
+// OnEnd implements sdktrace.SpanProcessor.
+func (p *blockProfiler) OnEnd(s sdktrace.ReadOnlySpan) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	id := s.SpanContext().SpanID()
+	for i := len(p.running) - 1; i >= 0; i-- {
+		if p.running[i] == id {
+			p.running = append(p.running[:i], p.running[i+1:]...)
+			break
+		}
+	}
+	p.spans = append(p.spans, s)
+}
+
+// Shutdown implements sdktrace.SpanProcessor.
+func (p *blockProfiler) Shutdown(context.Context) error { return nil }
+
+// ForceFlush implements sdktrace.SpanProcessor.
+func (p *blockProfiler) ForceFlush(context.Context) error { return nil }
+
+func (p *blockProfiler) observeAccess(storeName string, access gaskv.Access, n int) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	var id trace.SpanID
+	if len(p.running) > 0 {
+		id = p.running[len(p.running)-1]
+	}
+	if p.accesses[id] == nil {
+		p.accesses[id] = make(storeAccesses)
+	}
+	if p.accesses[id][storeName] == nil {
+		p.accesses[id][storeName] = &StoreAccesses{Store: storeName}
+	}
+	p.accesses[id][storeName].add(access, n)
+}
+
+// spanNode is a recorded span, with its children in start order.
+type spanNode struct {
+	span     sdktrace.ReadOnlySpan
+	children []*spanNode
+	// accesses are the store accesses of the span itself, excluding its children.
+	accesses storeAccesses
+}
+
+// tree returns the recorded spans as trees, the roots in start order.
+func (p *blockProfiler) tree() []*spanNode {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	spans := make([]sdktrace.ReadOnlySpan, len(p.spans))
+	copy(spans, p.spans)
+	sort.SliceStable(spans, func(i, j int) bool { return spans[i].StartTime().Before(spans[j].StartTime()) })
+
+	nodes := make(map[trace.SpanID]*spanNode, len(spans))
+	for _, s := range spans {
+		nodes[s.SpanContext().SpanID()] = &spanNode{span: s, accesses: p.accesses[s.SpanContext().SpanID()]}
+	}
+
+	var roots []*spanNode
+	for _, s := range spans {
+		node := nodes[s.SpanContext().SpanID()]
+		if parent, ok := nodes[s.Parent().SpanID()]; ok {
+			parent.children = append(parent.children, node)
+		} else {
+			roots = append(roots, node)
+		}
+	}
+	return roots
+}
+
+// unattributedAccesses returns the store accesses made outside of any span.
+func (p *blockProfiler) unattributedAccesses() storeAccesses {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	return p.accesses[trace.SpanID{}]
+}
+
+func (n *spanNode) name() string { return n.span.Name() }
+
+func (n *spanNode) duration() time.Duration {
+	return n.span.EndTime().Sub(n.span.StartTime())
+}
+
+func (n *spanNode) attribute(key string) (attribute.Value, bool) {
+	for _, attr := range n.span.Attributes() {
+		if string(attr.Key) == key {
+			return attr.Value, true
+		}
+	}
+	return attribute.Value{}, false
+}
+
+func (n *spanNode) stringAttribute(key string) string {
+	value, _ := n.attribute(key)
+	return value.AsString()
+}
+
+func (n *spanNode) gasUsed() (int64, bool) {
+	value, ok := n.attribute("gas_used")
+	return value.AsInt64(), ok
+}
+
+func (n *spanNode) error() string {
+	if n.span.Status().Code == codes.Error {
+		return n.span.Status().Description
+	}
+	return ""
+}
+
+// frame returns the name of the span in the stacks of the profiles: its name
+// followed by its ante decorator or message type, if any.
+func (n *spanNode) frame() string {
+	switch n.name() {
+	case "ante":
+		return "ante " + n.stringAttribute("decorator")
+	case "msg":
+		return "msg " + n.stringAttribute("type")
+	default:
+		return n.name()
+	}
+}
+
+// storeAccesses returns the store accesses of the span and of its children, except
+// the children skipped.
+func (n *spanNode) storeAccesses(skip func(*spanNode) bool) storeAccesses {
+	accesses := make(storeAccesses)
+	accesses.merge(n.accesses)
+	for _, child := range n.children {
+		if skip == nil || !skip(child) {
+			accesses.merge(child.storeAccesses(nil))
+		}
+	}
+	return accesses
+}
+
+// selfDuration returns the duration of the span, excluding the time spent in the
+// children which aren't kept.
+func (n *spanNode) selfDuration(keep func(*spanNode) bool) time.Duration {
+	self := n.duration()
+	for _, child := range n.children {
+		if keep != nil && keep(child) {
+			continue
+		}
+		// the children may end after their parent, e.g. the write of the block state
+		start, end := child.span.StartTime(), child.span.EndTime()
+		if start.Before(n.span.StartTime()) {
+			start = n.span.StartTime()
+		}
+		if end.After(n.span.EndTime()) {
+			end = n.span.EndTime()
+		}
+		if end.After(start) {
+			self -= end.Sub(start)
+		}
+	}
+	return max(self, 0)
+}
+
+// selfGas returns the gas used by the span, excluding the gas used by the children
+// which aren't kept. It is zero for the spans which don't record their gas.
+func (n *spanNode) selfGas(keep func(*spanNode) bool) int64 {
+	self, ok := n.gasUsed()
+	if !ok {
+		return 0
+	}
+	for _, child := range n.children {
+		if keep != nil && keep(child) {
+			continue
+		}
+		if gas, ok := child.gasUsed(); ok {
+			self -= gas
+		}
+	}
+	return max(self, 0)
+}
+
+func isAnte(n *spanNode) bool { return n.name() == "ante" }
+
+// nextAnte returns the span of the next ante decorator, called by the decorator of
+// the span, if any.
+func (n *spanNode) nextAnte() *spanNode {
+	for _, child := range n.children {
+		if isAnte(child) {
+			return child
+		}
+	}
+	return nil
+}
+
+// blockProfile builds the profile of a block from the trees of its spans, and from
+// its FinalizeBlock request and response.
+func blockProfile(roots []*spanNode, unattributed storeAccesses, req *abci.FinalizeBlockRequest, res *abci.FinalizeBlockResponse) BlockProfile {
+	profile := BlockProfile{
+		Height:  req.Height,
+		AppHash: fmt.Sprintf("%X", res.AppHash),
+		Phases:  []StepProfile{},
+		Txs:     make([]TxProfile, len(req.Txs)),
+	}
+
+	stores := make(storeAccesses)
+	stores.merge(unattributed)
+	txSpans := make(map[string]*spanNode)
+	for _, root := range roots {
+		stores.merge(root.storeAccesses(nil))
+		if root.name() != "FinalizeBlock" {
+			continue
+		}
+		for _, child := range root.children {
+			if child.name() == "tx" {
+				txSpans[child.stringAttribute("hash")] = child
+				continue
+			}
+			profile.Phases = append(profile.Phases, StepProfile{
+				Name:     child.name(),
+				Duration: child.duration(),
+				Error:    child.error(),
+				Stores:   child.storeAccesses(nil).sorted(),
+			})
+		}
+	}
+	profile.Stores = stores.sorted()
+
+	for i, tx := range req.Txs {
+		txProfile := TxProfile{
+			Index:          i,
+			Hash:           fmt.Sprintf("%X", tmhash.Sum(tx)),
+			AnteDecorators: []StepProfile{},
+			Msgs:           []StepProfile{},
+			Stores:         []StoreAccesses{},
+		}
+		if i < len(res.TxResults) {
+			result := res.TxResults[i]
+			txProfile.Code = result.Code
+			txProfile.Codespace = result.Codespace
+			txProfile.GasWanted = result.GasWanted
+			txProfile.GasUsed = result.GasUsed
+			profile.GasUsed += result.GasUsed
+		}
+
+		// the transactions which can't be decoded aren't executed
+		if span, ok := txSpans[txProfile.Hash]; ok {
+			txProfile.Duration = span.duration()
+			txProfile.Stores = span.storeAccesses(nil).sorted()
+			for _, child := range span.children {
+				switch child.name() {
+				case "ante":
+					// each decorator calls the next one, whose span is a child of its own
+					for ante := child; ante != nil; ante = ante.nextAnte() {
+						keep := func(n *spanNode) bool { return !isAnte(n) }
+						txProfile.AnteDecorators = append(txProfile.AnteDecorators, StepProfile{
+							Name:     ante.stringAttribute("decorator"),
+							Duration: ante.selfDuration(keep),
+							GasUsed:  ante.selfGas(keep),
+							Error:    ante.error(),
+							Stores:   ante.storeAccesses(isAnte).sorted(),
+						})
+					}
+				case "msg":
+					gasUsed, _ := child.gasUsed()
+					txProfile.Msgs = append(txProfile.Msgs, StepProfile{
+						Name:     child.stringAttribute("type"),
+						Duration: child.duration(),
+						GasUsed:  gasUsed,
+						Error:    child.error(),
+						Stores:   child.storeAccesses(nil).sorted(),
+					})
+				}
+			}
+		}
+		profile.Txs[i] = txProfile
+	}
+
+	return profile
+}
+
+// stackSample is the time, the gas and the store accesses spent in a stack of spans
+// itself, excluding its children.
+type stackSample struct {
+	frames   []string // the root first
+	wall     time.Duration
+	gas      int64
+	accesses int
+}
+
+// stackSamples returns the samples of the stacks of spans of the trees, merging the
+// identical stacks, e.g. the ones of the same message type in different
+// transactions. They are sorted by stack.
+func stackSamples(roots []*spanNode) []stackSample {
+	samples := make(map[string]*stackSample)
+	var walk func(n *spanNode, frames []string)
+	walk = func(n *spanNode, frames []string) {
+		frames = append(frames[:len(frames):len(frames)], n.frame())
+		key := strings.Join(frames, ";")
+		sample, ok := samples[key]
+		if !ok {
+			sample = &stackSample{frames: frames}
+			samples[key] = sample
+		}
+		sample.wall += n.selfDuration(nil)
+		sample.gas += n.selfGas(nil)
+		sample.accesses += n.accesses.total()
+		for _, child := range n.children {
+			walk(child, frames)
+		}
+	}
+	for _, root := range roots {
+		walk(root, nil)
+	}
+
+	keys := make([]string, 0, len(samples))
+	for key := range samples {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	sorted := make([]stackSample, len(keys))
+	for i, key := range keys {
+		sorted[i] = *samples[key]
+	}
+	return sorted
+}
+
+// writeFoldedStacks writes the wall time of the stacks, in nanoseconds, in the folded
+// stacks format of the flame graph tools.
+func writeFoldedStacks(w io.Writer, samples []stackSample) error {
+	for _, sample := range samples {
+		if sample.wall == 0 {
+			continue
+		}
+		if _, err := fmt.Fprintf(w, "%s %d\n", strings.Join(sample.frames, ";"), sample.wall.Nanoseconds()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Field numbers of the messages of the pprof profile.proto.
+const (
+	pprofProfileSampleType        = 1
+	pprofProfileSample            = 2
+	pprofProfileLocation          = 4
+	pprofProfileFunction          = 5
+	pprofProfileStringTable       = 6
+	pprofProfileTimeNanos         = 9
+	pprofProfileDurationNanos     = 10
+	pprofProfilePeriodType        = 11
+	pprofProfilePeriod            = 12
+	pprofProfileDefaultSampleType = 14
+
+	pprofValueTypeType = 1
+	pprofValueTypeUnit = 2
+
+	pprofSampleLocationID = 1
+	pprofSampleValue      = 2
+
+	pprofLocationID   = 1
+	pprofLocationLine = 4
+
+	pprofLineFunctionID = 1
+
+	pprofFunctionID   = 1
+	pprofFunctionName = 2
+)
+
+// writePprof writes the samples as a gzipped pprof profile, whose sample types are
+// the wall time, the gas and the store accesses. Each span is a function of the
+// profile.
+func writePprof(w io.Writer, samples []stackSample, start time.Time, duration time.Duration) error {
+	strs := []string{""}
+	strIndex := map[string]int{"": 0}
+	str := func(s string) uint64 {
+		i, ok := strIndex[s]
+		if !ok {
+			i = len(strs)
+			strs = append(strs, s)
+			strIndex[s] = i
+		}
+		return uint64(i)
+	}
+	valueType := func(typ, unit string) []byte {
+		var b []byte
+		b = protowire.AppendTag(b, pprofValueTypeType, protowire.VarintType)
+		b = protowire.AppendVarint(b, str(typ))
+		b = protowire.AppendTag(b, pprofValueTypeUnit, protowire.VarintType)
+		return protowire.AppendVarint(b, str(unit))
+	}
+	appendMessage := func(b []byte, num protowire.Number, m []byte) []byte {
+		b = protowire.AppendTag(b, num, protowire.BytesType)
+		return protowire.AppendBytes(b, m)
+	}
+	appendVarint := func(b []byte, num protowire.Number, v uint64) []byte {
+		b = protowire.AppendTag(b, num, protowire.VarintType)
+		return protowire.AppendVarint(b, v)
+	}
+
+	var profile []byte
+	profile = appendMessage(profile, pprofProfileSampleType, valueType("wall", "nanoseconds"))
+	profile = appendMessage(profile, pprofProfileSampleType, valueType("gas", "gas"))
+	profile = appendMessage(profile, pprofProfileSampleType, valueType("store_accesses", "count"))
+
+	// a function, and its location, per frame name
+	functions := make(map[string]uint64)
+	var functionNames []string
+	for _, sample := range samples {
+		var locations, values []byte
+		for i := len(sample.frames) - 1; i >= 0; i-- { // the leaf first
+			id, ok := functions[sample.frames[i]]
+			if !ok {
+				functionNames = append(functionNames, sample.frames[i])
+				id = uint64(len(functionNames))
+				functions[sample.frames[i]] = id
+			}
+			locations = protowire.AppendVarint(locations, id)
+		}
+		values = protowire.AppendVarint(values, uint64(sample.wall.Nanoseconds()))
+		values = protowire.AppendVarint(values, uint64(sample.gas))
+		values = protowire.AppendVarint(values, uint64(sample.accesses))
+
+		var b []byte
+		b = appendMessage(b, pprofSampleLocationID, locations)
+		b = appendMessage(b, pprofSampleValue, values)
+		profile = appendMessage(profile, pprofProfileSample, b)
+	}
+
+	for i, name := range functionNames {
+		id := uint64(i + 1)
+
+		var line, location []byte
+		line = appendVarint(line, pprofLineFunctionID, id)
+		location = appendVarint(location, pprofLocationID, id)
+		location = appendMessage(location, pprofLocationLine, line)
+		profile = appendMessage(profile, pprofProfileLocation, location)
+
+		var function []byte
+		function = appendVarint(function, pprofFunctionID, id)
+		function = appendVarint(function, pprofFunctionName, str(name))
+		profile = appendMessage(profile, pprofProfileFunction, function)
+	}
+
+	profile = appendVarint(profile, pprofProfileTimeNanos, uint64(start.UnixNano()))
+	profile = appendVarint(profile, pprofProfileDurationNanos, uint64(duration.Nanoseconds()))
+	profile = appendMessage(profile, pprofProfilePeriodType, valueType("wall", "nanoseconds"))
+	profile = appendVarint(profile, pprofProfilePeriod, 1)
+	profile = appendVarint(profile, pprofProfileDefaultSampleType, str("wall"))
+
+	// the string table is written last, once all the strings are indexed
+	for _, s := range strs {
+		profile = protowire.AppendTag(profile, pprofProfileStringTable, protowire.BytesType)
+		profile = protowire.AppendString(profile, s)
+	}
+
+	gz := gzip.NewWriter(w)
+	if _, err := gz.Write(profile); err != nil {
+		return err
+	}
+	return gz.Close()
+}
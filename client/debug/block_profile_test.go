@@ -0,0 +1,161 @@
+package debug
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+	"github.com/cometbft/cometbft/crypto/tmhash"
+	dbm "github.com/cosmos/cosmos-db"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"cosmossdk.io/store/dbadapter"
+	"cosmossdk.io/store/gaskv"
+	storetypes "cosmossdk.io/store/types"
+
+	"github.com/cosmos/cosmos-sdk/telemetry"
+)
+
+func TestBlockProfile(t *testing.T) {
+	profiler := newBlockProfiler()
+	provider := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(profiler)))
+	t.Cleanup(func() { otel.SetTracerProvider(provider) })
+	gaskv.SetAccessObserver(profiler.observeAccess)
+	t.Cleanup(func() { gaskv.SetAccessObserver(nil) })
+
+	st := gaskv.NewStoreWithName(dbadapter.Store{DB: dbm.NewMemDB()}, storetypes.NewInfiniteGasMeter(), storetypes.KVGasConfig(), "bank")
+	key, value := []byte("key"), []byte("value")
+	txs := [][]byte{[]byte("tx"), []byte("undecodable")}
+
+	// the spans of a block as recorded by BaseApp, the second transaction isn't executed
+	ctx, block := telemetry.StartSpan(context.Background(), "FinalizeBlock")
+	_, beginBlock := telemetry.StartSpan(ctx, "BeginBlock")
+	st.Set(key, value)
+	beginBlock.End()
+
+	txCtx, tx := telemetry.StartSpan(ctx, "tx", attribute.String("hash", fmt.Sprintf("%X", tmhash.Sum(txs[0]))))
+	anteCtx, first := telemetry.StartSpan(txCtx, "ante", attribute.String("decorator", "first"))
+	require.Equal(t, value, st.Get(key))
+	_, second := telemetry.StartSpan(anteCtx, "ante", attribute.String("decorator", "second"))
+	require.True(t, st.Has(key))
+	endSpan(second, 10)
+	endSpan(first, 25)
+	_, msg := telemetry.StartSpan(txCtx, "msg", attribute.String("type", "/test.MsgTest"))
+	st.Set(key, value)
+	st.Delete(key)
+	endSpan(msg, 40)
+	endSpan(tx, 70)
+	block.End()
+
+	roots := profiler.tree()
+	res := &abci.FinalizeBlockResponse{
+		TxResults: []*abci.ExecTxResult{
+			{GasWanted: 100, GasUsed: 70},
+			{Code: 2, Codespace: "sdk"},
+		},
+		AppHash: []byte{1, 2},
+	}
+	profile := blockProfile(roots, profiler.unattributedAccesses(), &abci.FinalizeBlockRequest{Height: 10, Txs: txs}, res)
+
+	require.Equal(t, int64(10), profile.Height)
+	require.Equal(t, "0102", profile.AppHash)
+	require.Equal(t, int64(70), profile.GasUsed)
+	require.Len(t, profile.Phases, 1)
+	require.Equal(t, "BeginBlock", profile.Phases[0].Name)
+	require.Equal(t, []StoreAccesses{{Store: "bank", Writes: 1, WriteBytes: 8}}, profile.Phases[0].Stores)
+	require.Equal(t, []StoreAccesses{{Store: "bank", Reads: 1, Writes: 2, Has: 1, Deletes: 1, ReadBytes: 8, WriteBytes: 16}}, profile.Stores)
+
+	require.Len(t, profile.Txs, 2)
+	executed := profile.Txs[0]
+	require.Equal(t, fmt.Sprintf("%X", tmhash.Sum(txs[0])), executed.Hash)
+	require.Equal(t, int64(100), executed.GasWanted)
+	require.Equal(t, int64(70), executed.GasUsed)
+	require.Positive(t, executed.Duration)
+	// the duration, gas and store accesses of a decorator exclude the ones of the next one
+	require.Len(t, executed.AnteDecorators, 2)
+	require.Equal(t, "first", executed.AnteDecorators[0].Name)
+	require.Equal(t, int64(15), executed.AnteDecorators[0].GasUsed)
+	require.Equal(t, []StoreAccesses{{Store: "bank", Reads: 1, ReadBytes: 8}}, executed.AnteDecorators[0].Stores)
+	require.Equal(t, "second", executed.AnteDecorators[1].Name)
+	require.Equal(t, int64(10), executed.AnteDecorators[1].GasUsed)
+	require.Equal(t, []StoreAccesses{{Store: "bank", Has: 1}}, executed.AnteDecorators[1].Stores)
+	require.Len(t, executed.Msgs, 1)
+	require.Equal(t, "/test.MsgTest", executed.Msgs[0].Name)
+	require.Equal(t, int64(40), executed.Msgs[0].GasUsed)
+	require.Equal(t, []StoreAccesses{{Store: "bank", Writes: 1, Deletes: 1, WriteBytes: 8}}, executed.Msgs[0].Stores)
+	require.Equal(t, []StoreAccesses{{Store: "bank", Reads: 1, Writes: 1, Has: 1, Deletes: 1, ReadBytes: 8, WriteBytes: 8}}, executed.Stores)
+
+	undecodable := profile.Txs[1]
+	require.Equal(t, uint32(2), undecodable.Code)
+	require.Equal(t, "sdk", undecodable.Codespace)
+	require.Empty(t, undecodable.AnteDecorators)
+	require.Zero(t, undecodable.Duration)
+
+	samples := make(map[string]stackSample)
+	for _, sample := range stackSamples(roots) {
+		samples[fmt.Sprint(sample.frames)] = sample
+	}
+	require.Len(t, samples, 6)
+	require.Equal(t, int64(5), samples["[FinalizeBlock tx]"].gas) // used outside of the ante handler and the message
+	require.Equal(t, int64(15), samples["[FinalizeBlock tx ante first]"].gas)
+	require.Equal(t, int64(10), samples["[FinalizeBlock tx ante first ante second]"].gas)
+	require.Equal(t, 2, samples["[FinalizeBlock tx msg /test.MsgTest]"].accesses)
+
+	var folded bytes.Buffer
+	require.NoError(t, writeFoldedStacks(&folded, stackSamples(roots)))
+	require.Contains(t, folded.String(), "FinalizeBlock;tx;msg /test.MsgTest ")
+
+	var buf bytes.Buffer
+	require.NoError(t, writePprof(&buf, stackSamples(roots), time.Now(), time.Second))
+	strs, numSamples := decodePprof(t, &buf)
+	require.Equal(t, 6, numSamples)
+	require.Equal(t, "", strs[0])
+	require.Subset(t, strs, []string{"wall", "nanoseconds", "gas", "store_accesses", "count", "FinalizeBlock", "tx", "ante first", "ante second", "msg /test.MsgTest", "BeginBlock"})
+}
+
+// endSpan ends a span recording the gas used, like BaseApp does.
+func endSpan(span trace.Span, gasUsed int64) {
+	span.SetAttributes(attribute.Int64("gas_used", gasUsed))
+	span.End()
+}
+
+// decodePprof returns the string table and the number of samples of a gzipped pprof profile.
+func decodePprof(t *testing.T, r io.Reader) (strs []string, numSamples int) {
+	t.Helper()
+	gz, err := gzip.NewReader(r)
+	require.NoError(t, err)
+	b, err := io.ReadAll(gz)
+	require.NoError(t, err)
+
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		require.GreaterOrEqual(t, n, 0)
+		b = b[n:]
+		switch {
+		case num == pprofProfileStringTable:
+			s, n := protowire.ConsumeString(b)
+			require.GreaterOrEqual(t, n, 0)
+			strs = append(strs, s)
+			b = b[n:]
+		case num == pprofProfileSample:
+			numSamples++
+			fallthrough
+		default:
+			n := protowire.ConsumeFieldValue(num, typ, b)
+			require.GreaterOrEqual(t, n, 0)
+			b = b[n:]
+		}
+	}
+	return strs, numSamples
+}
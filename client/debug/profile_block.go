@@ -0,0 +1,313 @@
+package debug
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime/pprof"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+	cmtcfg "github.com/cometbft/cometbft/config"
+	sm "github.com/cometbft/cometbft/state"
+	"github.com/cometbft/cometbft/store"
+	"github.com/spf13/cobra"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+
+	"cosmossdk.io/log"
+	"cosmossdk.io/store/gaskv"
+
+	"github.com/cosmos/cosmos-sdk/client/flags"
+	"github.com/cosmos/cosmos-sdk/server"
+	servertypes "github.com/cosmos/cosmos-sdk/server/types"
+	"github.com/cosmos/cosmos-sdk/version"
+)
+
+const flagProfileOutputDir = "output-dir"
+
+// ProfileBlockCmd returns a command re-executing a committed block against the state
+// of its parent block, and profiling its execution.
+func ProfileBlockCmd[T servertypes.Application](appCreator servertypes.AppCreator[T]) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "profile-block [height]",
+		Short: "Re-execute a committed block against the state of its parent block and profile it",
+		Long: `Re-execute a committed block against the state of its parent block and profile it.
+The block is read from the block store of the node, and executed in-process by the application
+against the historical state of the previous height, which must not have been pruned. The result
+is never committed, the node state is left untouched. The node must be stopped.
+
+The duration and gas of the block phases, of each transaction, ante decorator and message, and the
+accesses to each store are reported, along with the app hash of the re-execution and the one
+committed by the chain, which differ when the binary doesn't execute the block like the one which
+committed it. The following files are written to the output directory:
+
+- block-<height>.json: the report, as printed with --output json
+- block-<height>.pb.gz: a pprof profile of the wall time, gas and store accesses of each step
+- block-<height>.folded: the wall time of each step, in the folded stacks format of flame graphs
+- block-<height>.cpu.pb.gz: a pprof CPU profile of the execution
+
+The profiles are opened with "go tool pprof", e.g. "go tool pprof -http :8080 block-<height>.pb.gz".`,
+		Example: fmt.Sprintf("%s debug profile-block 1000 --output-dir ./profiles", version.AppName),
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			serverCtx := server.GetServerContextFromCmd(cmd)
+			config := serverCtx.Config
+
+			height, err := strconv.ParseInt(args[0], 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid height %s: %w", args[0], err)
+			}
+			outputDir, err := cmd.Flags().GetString(flagProfileOutputDir)
+			if err != nil {
+				return err
+			}
+			output, err := cmd.Flags().GetString(flags.FlagOutput)
+			if err != nil {
+				return err
+			}
+
+			block, err := loadCommittedBlock(config, height)
+			if err != nil {
+				return err
+			}
+
+			db, err := server.OpenDB(config.RootDir, server.GetAppDBBackend(serverCtx.Viper))
+			if err != nil {
+				return err
+			}
+			// the streaming services of the node must not receive the re-executed block
+			appOpts, err := server.WithoutStreaming(serverCtx.Viper)
+			if err != nil {
+				return err
+			}
+			// the inter-block cache would keep wrapping the stores of the latest height
+			appOpts.Set(server.FlagInterBlockCache, false)
+			app := appCreator(log.NewNopLogger(), db, nil, appOpts)
+			defer app.Close()
+
+			// the block is executed against the state of its parent, and never committed
+			if err := app.CommitMultiStore().LoadVersion(height - 1); err != nil {
+				return fmt.Errorf("failed to load the state at height %d, it may have been pruned: %w", height-1, err)
+			}
+
+			if err := os.MkdirAll(outputDir, 0o755); err != nil {
+				return err
+			}
+			profile, err := profileBlock(app, block, filepath.Join(outputDir, fmt.Sprintf("block-%d", height)))
+			if err != nil {
+				return err
+			}
+
+			if output == flags.OutputFormatJSON {
+				enc := json.NewEncoder(cmd.OutOrStdout())
+				enc.SetIndent("", "  ")
+				return enc.Encode(profile)
+			}
+			printBlockProfile(cmd.OutOrStdout(), profile)
+			cmd.Printf("\nProfiles written to %s\n", outputDir)
+			return nil
+		},
+	}
+
+	cmd.Flags().String(flagProfileOutputDir, ".", "Directory the report and the profiles are written to")
+	cmd.Flags().StringP(flags.FlagOutput, "o", flags.OutputFormatText, "Output format (text|json)")
+
+	return cmd
+}
+
+// committedBlock is a committed block, with what is needed to execute it again.
+type committedBlock struct {
+	chainID string
+	req     *abci.FinalizeBlockRequest
+	// appHash is the app hash committed after the block, if the next block is stored.
+	appHash []byte
+}
+
+// loadCommittedBlock returns the FinalizeBlock request of a committed block, built from
+// the block store and the state store of the node like CometBFT does.
+func loadCommittedBlock(config *cmtcfg.Config, height int64) (committedBlock, error) {
+	blockStoreDB, err := cmtcfg.DefaultDBProvider(&cmtcfg.DBContext{ID: "blockstore", Config: config})
+	if err != nil {
+		return committedBlock{}, err
+	}
+	blockStore := store.NewBlockStore(blockStoreDB, store.WithDBKeyLayout(config.Storage.ExperimentalKeyLayout))
+	defer blockStore.Close()
+
+	stateDB, err := cmtcfg.DefaultDBProvider(&cmtcfg.DBContext{ID: "state", Config: config})
+	if err != nil {
+		return committedBlock{}, err
+	}
+	defer stateDB.Close()
+	stateStore := sm.NewStore(stateDB, sm.StoreOptions{
+		DiscardABCIResponses: config.Storage.DiscardABCIResponses,
+		DBKeyLayout:          config.Storage.ExperimentalKeyLayout,
+	})
+
+	state, err := stateStore.Load()
+	if err != nil {
+		return committedBlock{}, err
+	}
+	if state.IsEmpty() {
+		return committedBlock{}, errors.New("the node has no state")
+	}
+	if height <= state.InitialHeight {
+		return committedBlock{}, fmt.Errorf("the first block %d of the chain is executed against the genesis state and can't be profiled", state.InitialHeight)
+	}
+	if height > state.LastBlockHeight {
+		return committedBlock{}, fmt.Errorf("block %d isn't committed, the last committed block is %d", height, state.LastBlockHeight)
+	}
+
+	block, _ := blockStore.LoadBlock(height)
+	if block == nil {
+		return committedBlock{}, fmt.Errorf("block %d isn't stored, the blocks stored are %d to %d", height, blockStore.Base(), blockStore.Height())
+	}
+	lastValidators, err := stateStore.LoadValidators(height - 1)
+	if err != nil {
+		return committedBlock{}, fmt.Errorf("failed to load the validators of height %d: %w", height-1, err)
+	}
+
+	committed := committedBlock{
+		chainID: state.ChainID,
+		req: &abci.FinalizeBlockRequest{
+			Hash:               block.Hash(),
+			NextValidatorsHash: block.NextValidatorsHash,
+			ProposerAddress:    block.ProposerAddress,
+			Height:             block.Height,
+			Time:               block.Time,
+			DecidedLastCommit:  sm.BuildLastCommitInfo(block, lastValidators, state.InitialHeight),
+			Misbehavior:        block.Evidence.Evidence.ToABCI(),
+			Txs:                block.Txs.ToSliceOfBytes(),
+		},
+	}
+	if meta := blockStore.LoadBlockMeta(height + 1); meta != nil {
+		committed.appHash = meta.Header.AppHash
+	}
+
+	return committed, nil
+}
+
+// profileBlock executes the block, recording its spans, its store accesses and a CPU
+// profile, and writes the report and the profiles to the files with the given path
+// prefix.
+func profileBlock(app servertypes.ABCI, block committedBlock, pathPrefix string) (BlockProfile, error) {
+	profiler := newBlockProfiler()
+	provider := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(profiler)))
+	defer otel.SetTracerProvider(provider)
+	gaskv.SetAccessObserver(profiler.observeAccess)
+	defer gaskv.SetAccessObserver(nil)
+
+	cpuProfile, err := os.Create(pathPrefix + ".cpu.pb.gz")
+	if err != nil {
+		return BlockProfile{}, err
+	}
+	defer cpuProfile.Close()
+	if err := pprof.StartCPUProfile(cpuProfile); err != nil {
+		return BlockProfile{}, err
+	}
+
+	start := time.Now()
+	res, err := app.FinalizeBlock(block.req)
+	duration := time.Since(start)
+	pprof.StopCPUProfile()
+	if err != nil {
+		return BlockProfile{}, fmt.Errorf("failed to execute block %d: %w", block.req.Height, err)
+	}
+
+	roots := profiler.tree()
+	profile := blockProfile(roots, profiler.unattributedAccesses(), block.req, res)
+	profile.ChainID = block.chainID
+	profile.Duration = duration
+	if block.appHash != nil {
+		profile.ExpectedAppHash = fmt.Sprintf("%X", block.appHash)
+	}
+
+	bz, err := json.MarshalIndent(profile, "", "  ")
+	if err != nil {
+		return BlockProfile{}, err
+	}
+	if err := os.WriteFile(pathPrefix+".json", bz, 0o644); err != nil {
+		return BlockProfile{}, err
+	}
+
+	samples := stackSamples(roots)
+	if err := writeProfileFile(pathPrefix+".pb.gz", func(w io.Writer) error {
+		return writePprof(w, samples, start, duration)
+	}); err != nil {
+		return BlockProfile{}, err
+	}
+	if err := writeProfileFile(pathPrefix+".folded", func(w io.Writer) error {
+		return writeFoldedStacks(w, samples)
+	}); err != nil {
+		return BlockProfile{}, err
+	}
+
+	return profile, nil
+}
+
+func writeProfileFile(path string, write func(io.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func printBlockProfile(out io.Writer, profile BlockProfile) {
+	fmt.Fprintf(out, "Block %d of %s: %s, %d txs, %d gas\n", profile.Height, profile.ChainID, profile.Duration, len(profile.Txs), profile.GasUsed)
+	switch {
+	case profile.ExpectedAppHash == "":
+		fmt.Fprintf(out, "App hash: %s\n", profile.AppHash)
+	case profile.ExpectedAppHash == profile.AppHash:
+		fmt.Fprintf(out, "App hash: %s, as committed\n", profile.AppHash)
+	default:
+		fmt.Fprintf(out, "App hash: %s, differs from the committed %s\n", profile.AppHash, profile.ExpectedAppHash)
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(out, "\nPhases:")
+	for _, p := range profile.Phases {
+		fmt.Fprintf(w, "  %s\t%s\t%d store accesses\t%s\n", p.Name, p.Duration, totalAccesses(p.Stores), p.Error)
+	}
+	w.Flush()
+
+	fmt.Fprintln(out, "\nTransactions:")
+	if len(profile.Txs) == 0 {
+		fmt.Fprintln(out, "  none")
+	}
+	for _, tx := range profile.Txs {
+		fmt.Fprintf(w, "  #%d %s\t%s\t%d/%d gas\t%d store accesses\tcode %d\n", tx.Index, tx.Hash, tx.Duration, tx.GasUsed, tx.GasWanted, totalAccesses(tx.Stores), tx.Code)
+		for _, msg := range tx.Msgs {
+			fmt.Fprintf(w, "    %s\t%s\t%d gas\t%d store accesses\t%s\n", msg.Name, msg.Duration, msg.GasUsed, totalAccesses(msg.Stores), msg.Error)
+		}
+	}
+	w.Flush()
+
+	fmt.Fprintln(out, "\nStores:")
+	if len(profile.Stores) == 0 {
+		fmt.Fprintln(out, "  none")
+	}
+	for _, s := range profile.Stores {
+		fmt.Fprintf(w, "  %s\t%d reads\t%d writes\t%d has\t%d deletes\t%d iterations\t%d bytes read\t%d bytes written\n",
+			s.Store, s.Reads, s.Writes, s.Has, s.Deletes, s.Iterations, s.ReadBytes, s.WriteBytes)
+	}
+	w.Flush()
+}
+
+func totalAccesses(stores []StoreAccesses) int {
+	var total int
+	for _, s := range stores {
+		total += s.Total()
+	}
+	return total
+}
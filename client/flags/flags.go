@@ -3,10 +3,13 @@ package flags
 import (
 	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 
+	signingv1beta1 "cosmossdk.io/api/cosmos/tx/signing/v1beta1"
+
 	"github.com/cosmos/cosmos-sdk/crypto/keyring"
 )
 
@@ -87,9 +90,12 @@ const (
 	// This differs from FlagOutputDocument that is used to set the output file.
 	FlagOutput = "output"
 	// Logging flags
-	FlagLogLevel   = "log_level"
-	FlagLogFormat  = "log_format"
-	FlagLogNoColor = "log_no_color"
+	FlagLogLevel        = "log_level"
+	FlagLogFormat       = "log_format"
+	FlagLogNoColor      = "log_no_color"
+	FlagLogRedact       = "log_redact"
+	FlagLogSampleBurst  = "log_sample_burst"
+	FlagLogSamplePeriod = "log_sample_period"
 )
 
 // List of supported output formats
@@ -135,7 +141,7 @@ func AddTxFlagsToCmd(cmd *cobra.Command) {
 	f.Bool(FlagGenerateOnly, false, "Build an unsigned transaction and write it to STDOUT (when enabled, the local Keybase only accessed when providing a key name)")
 	f.Bool(FlagOffline, false, "Offline mode (does not allow any online functionality)")
 	f.BoolP(FlagSkipConfirmation, "y", false, "Skip tx broadcasting prompt confirmation")
-	f.String(FlagSignMode, "", "Choose sign mode (direct|amino-json|direct-aux|textual), this is an advanced feature")
+	f.String(FlagSignMode, "", SignModeUsage(nil))
 	f.Uint64(FlagTimeoutHeight, 0, "Set a block timeout height to prevent the tx from being committed past a certain height")
 	f.Bool(FlagUnordered, false, "Enable unordered transaction delivery; must be used in conjunction with --timeout-height")
 	f.String(FlagFeePayer, "", "Fee payer pays fees for the transaction instead of deducting from the signer")
@@ -201,3 +207,85 @@ func ParseGasSetting(gasStr string) (GasSetting, error) {
 		return GasSetting{false, gas}, nil
 	}
 }
+
+// SignModeFlagValue returns the --sign-mode flag value of the given sign mode,
+// e.g. "direct" for SIGN_MODE_DIRECT or "eip-191" for SIGN_MODE_EIP_191.
+// Sign modes unknown to the SignMode enum, such as custom sign modes registered
+// by modules, are returned as their number.
+func SignModeFlagValue(mode signingv1beta1.SignMode) string {
+	if mode == signingv1beta1.SignMode_SIGN_MODE_LEGACY_AMINO_JSON {
+		return SignModeLegacyAminoJSON
+	}
+
+	name, ok := signingv1beta1.SignMode_name[int32(mode)]
+	if !ok {
+		return strconv.Itoa(int(mode))
+	}
+
+	return strings.ReplaceAll(strings.ToLower(strings.TrimPrefix(name, "SIGN_MODE_")), "_", "-")
+}
+
+// defaultSignModes are the sign modes listed by the --sign-mode flag usage until it
+// is set from the sign modes supported by the app with SetSignModeUsage.
+var defaultSignModes = []signingv1beta1.SignMode{
+	signingv1beta1.SignMode_SIGN_MODE_DIRECT,
+	signingv1beta1.SignMode_SIGN_MODE_LEGACY_AMINO_JSON,
+	signingv1beta1.SignMode_SIGN_MODE_DIRECT_AUX,
+	signingv1beta1.SignMode_SIGN_MODE_TEXTUAL,
+}
+
+// SignModeUsage returns the usage of the --sign-mode flag, listing the given sign
+// modes, or the default ones if none is given.
+func SignModeUsage(modes []signingv1beta1.SignMode) string {
+	if len(modes) == 0 {
+		modes = defaultSignModes
+	}
+
+	values := make([]string, 0, len(modes))
+	for _, mode := range modes {
+		if mode == signingv1beta1.SignMode_SIGN_MODE_UNSPECIFIED {
+			continue
+		}
+		values = append(values, SignModeFlagValue(mode))
+	}
+
+	return fmt.Sprintf("Choose sign mode (%s), this is an advanced feature", strings.Join(values, "|"))
+}
+
+// SetSignModeUsage sets the usage of the --sign-mode flag of cmd and of all its
+// subcommands from the sign modes supported by the app, including the custom sign
+// modes registered by modules.
+func SetSignModeUsage(cmd *cobra.Command, modes []signingv1beta1.SignMode) {
+	usage := SignModeUsage(modes)
+	var set func(*cobra.Command)
+	set = func(c *cobra.Command) {
+		if f := c.Flags().Lookup(FlagSignMode); f != nil {
+			f.Usage = usage
+		}
+		for _, sub := range c.Commands() {
+			set(sub)
+		}
+	}
+	set(cmd)
+}
+
+// ParseSignMode parses a --sign-mode flag value into one of the supported sign
+// modes. It accepts the flag value returned by SignModeFlagValue, the enum name
+// (e.g. SIGN_MODE_DIRECT) or the sign mode number.
+func ParseSignMode(signModeStr string, supported []signingv1beta1.SignMode) (signingv1beta1.SignMode, error) {
+	for _, mode := range supported {
+		if signModeStr == SignModeFlagValue(mode) || signModeStr == mode.String() {
+			return mode, nil
+		}
+	}
+
+	if n, err := strconv.ParseInt(signModeStr, 10, 32); err == nil {
+		for _, mode := range supported {
+			if int64(mode) == n {
+				return mode, nil
+			}
+		}
+	}
+
+	return signingv1beta1.SignMode_SIGN_MODE_UNSPECIFIED, fmt.Errorf("unsupported sign mode %q", signModeStr)
+}
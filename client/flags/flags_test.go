@@ -3,8 +3,11 @@ package flags_test
 import (
 	"testing"
 
+	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/require"
 
+	signingv1beta1 "cosmossdk.io/api/cosmos/tx/signing/v1beta1"
+
 	"github.com/cosmos/cosmos-sdk/client/flags"
 )
 
@@ -36,3 +39,63 @@ func TestParseGasSetting(t *testing.T) {
 		})
 	}
 }
+
+func TestParseSignMode(t *testing.T) {
+	customMode := signingv1beta1.SignMode(712)
+	supported := []signingv1beta1.SignMode{
+		signingv1beta1.SignMode_SIGN_MODE_DIRECT,
+		signingv1beta1.SignMode_SIGN_MODE_LEGACY_AMINO_JSON,
+		signingv1beta1.SignMode_SIGN_MODE_EIP_191,
+		customMode,
+	}
+
+	testCases := []struct {
+		name      string
+		input     string
+		expected  signingv1beta1.SignMode
+		expectErr bool
+	}{
+		{"flag value", flags.SignModeDirect, signingv1beta1.SignMode_SIGN_MODE_DIRECT, false},
+		{"amino json flag value", flags.SignModeLegacyAminoJSON, signingv1beta1.SignMode_SIGN_MODE_LEGACY_AMINO_JSON, false},
+		{"eip191 flag value", flags.SignModeEIP191, signingv1beta1.SignMode_SIGN_MODE_EIP_191, false},
+		{"enum name", "SIGN_MODE_DIRECT", signingv1beta1.SignMode_SIGN_MODE_DIRECT, false},
+		{"custom sign mode number", "712", customMode, false},
+		{"unsupported sign mode", flags.SignModeTextual, signingv1beta1.SignMode_SIGN_MODE_UNSPECIFIED, true},
+		{"unknown sign mode", "foo", signingv1beta1.SignMode_SIGN_MODE_UNSPECIFIED, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mode, err := flags.ParseSignMode(tc.input, supported)
+			if tc.expectErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, tc.expected, mode)
+				require.Equal(t, tc.expected, mustParse(t, flags.SignModeFlagValue(mode), supported))
+			}
+		})
+	}
+}
+
+func mustParse(t *testing.T, signModeStr string, supported []signingv1beta1.SignMode) signingv1beta1.SignMode {
+	t.Helper()
+	mode, err := flags.ParseSignMode(signModeStr, supported)
+	require.NoError(t, err)
+	return mode
+}
+
+func TestSetSignModeUsage(t *testing.T) {
+	rootCmd := &cobra.Command{Use: "root"}
+	txCmd := &cobra.Command{Use: "send"}
+	flags.AddTxFlagsToCmd(txCmd)
+	rootCmd.AddCommand(txCmd)
+	require.Equal(t, "Choose sign mode (direct|amino-json|direct-aux|textual), this is an advanced feature", txCmd.Flags().Lookup(flags.FlagSignMode).Usage)
+
+	flags.SetSignModeUsage(rootCmd, []signingv1beta1.SignMode{
+		signingv1beta1.SignMode_SIGN_MODE_DIRECT,
+		signingv1beta1.SignMode_SIGN_MODE_EIP_191,
+		signingv1beta1.SignMode(712),
+	})
+	require.Equal(t, "Choose sign mode (direct|eip-191|712), this is an advanced feature", txCmd.Flags().Lookup(flags.FlagSignMode).Usage)
+}
@@ -72,6 +72,17 @@ func NewFactoryCLI(clientCtx client.Context, flagSet *pflag.FlagSet) (Factory, e
 		signMode = signing.SignMode_SIGN_MODE_TEXTUAL
 	case flags.SignModeEIP191:
 		signMode = signing.SignMode_SIGN_MODE_EIP_191
+	case "":
+	default:
+		// the sign mode may be a custom one registered by a module
+		if clientCtx.TxConfig == nil {
+			return Factory{}, fmt.Errorf("unsupported sign mode %q", clientCtx.SignModeStr)
+		}
+		mode, err := flags.ParseSignMode(clientCtx.SignModeStr, clientCtx.TxConfig.SignModeHandler().SupportedModes())
+		if err != nil {
+			return Factory{}, err
+		}
+		signMode = signing.SignMode(mode)
 	}
 
 	var accNum, accSeq uint64
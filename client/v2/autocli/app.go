@@ -74,7 +74,21 @@ func (appOptions AppOptions) EnhanceRootCommand(rootCmd *cobra.Command) error {
 		AddTxConnFlags:    sdkflags.AddTxFlagsToCmd,
 	}
 
-	return appOptions.EnhanceRootCommandWithBuilder(rootCmd, builder)
+	if appOptions.ClientCtx.TxConfig != nil {
+		builder.SignModes = appOptions.ClientCtx.TxConfig.SignModeHandler().SupportedModes()
+	}
+
+	if err := appOptions.EnhanceRootCommandWithBuilder(rootCmd, builder); err != nil {
+		return err
+	}
+
+	// the --sign-mode flag of the hand-written and the autocli transaction commands
+	// lists the sign modes supported by the app
+	if len(builder.SignModes) > 0 {
+		sdkflags.SetSignModeUsage(rootCmd, builder.SignModes)
+	}
+
+	return nil
 }
 
 func (appOptions AppOptions) EnhanceRootCommandWithBuilder(rootCmd *cobra.Command, builder *Builder) error {
@@ -4,6 +4,7 @@ import (
 	"github.com/spf13/cobra"
 	"google.golang.org/grpc"
 
+	signingv1beta1 "cosmossdk.io/api/cosmos/tx/signing/v1beta1"
 	"cosmossdk.io/client/v2/autocli/flag"
 )
 
@@ -19,6 +20,10 @@ type Builder struct {
 	// AddQueryConnFlags and AddTxConnFlags are functions that add flags to query and transaction commands
 	AddQueryConnFlags func(*cobra.Command)
 	AddTxConnFlags    func(*cobra.Command)
+
+	// SignModes are the sign modes supported by the app, including custom sign modes
+	// registered by modules. They are used to complete the --sign-mode flag of transaction commands.
+	SignModes []signingv1beta1.SignMode
 }
 
 // ValidateAndComplete the builder fields.
@@ -23,6 +23,7 @@ import (
 	govtypes "cosmossdk.io/x/gov/types"
 
 	"github.com/cosmos/cosmos-sdk/client"
+	sdkflags "github.com/cosmos/cosmos-sdk/client/flags"
 	clienttx "github.com/cosmos/cosmos-sdk/client/tx"
 )
 
@@ -178,6 +179,12 @@ func (b *Builder) BuildMsgMethodCommand(descriptor protoreflect.MethodDescriptor
 		b.AddTxConnFlags(cmd)
 	}
 
+	if len(b.SignModes) > 0 && cmd.Flags().Lookup(flags.FlagSignMode) != nil {
+		if err := cmd.RegisterFlagCompletionFunc(flags.FlagSignMode, b.completeSignMode); err != nil {
+			return nil, err
+		}
+	}
+
 	// silence usage only for inner txs & queries commands
 	if cmd != nil {
 		cmd.SilenceUsage = true
@@ -192,6 +199,16 @@ func (b *Builder) BuildMsgMethodCommand(descriptor protoreflect.MethodDescriptor
 	return cmd, nil
 }
 
+// completeSignMode completes the --sign-mode flag with the sign modes supported by the app.
+func (b *Builder) completeSignMode(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	signModes := make([]string, 0, len(b.SignModes))
+	for _, mode := range b.SignModes {
+		signModes = append(signModes, sdkflags.SignModeFlagValue(mode))
+	}
+
+	return signModes, cobra.ShellCompDirectiveNoFileComp
+}
+
 // handleGovProposal sets the authority field of the message to the gov module address and creates a gov proposal.
 func (b *Builder) handleGovProposal(
 	options *autocliv1.RpcCommandOptions,
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/spf13/cobra"
@@ -130,6 +131,16 @@ func normalizeJSON(content []byte) ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
+func TestMsgSignModeCompletion(t *testing.T) {
+	fixture := initFixture(t)
+	fixture.b.SignModes = fixture.clientCtx.TxConfig.SignModeHandler().SupportedModes()
+
+	out, err := runCmd(fixture, buildModuleMsgCommand, cobra.ShellCompRequestCmd, "send", "--sign-mode", "")
+	assert.NilError(t, err)
+	assert.Assert(t, strings.Contains(out.String(), "direct\n"))
+	assert.Assert(t, strings.Contains(out.String(), "amino-json\n"))
+}
+
 func TestMsgOptionsError(t *testing.T) {
 	fixture := initFixture(t)
 
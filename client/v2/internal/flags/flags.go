@@ -17,6 +17,9 @@ const (
 	// FlagNoProposal is the flag convert a gov proposal command into a normal command.
 	// This is used to allow user of chains with custom authority to not use gov submit proposals for usual proposal commands.
 	FlagNoProposal = "no-proposal"
+
+	// FlagSignMode is the flag to set the sign mode used to sign the transaction.
+	FlagSignMode = "sign-mode"
 )
 
 // List of supported output formats
@@ -0,0 +1,158 @@
+package collections
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+)
+
+// Entry is a key-value pair of a collection, decoded from its store representation.
+// It is used by indexers to map state changes to typed tables.
+type Entry struct {
+	// Collection is the name of the collection the entry belongs to.
+	Collection string
+	// Key holds the parts of the key: a single one for simple keys, one per part
+	// for multipart keys (Pair, Triple), and none for items.
+	Key []any
+	// Value is the decoded value. It is nil for key sets and removed entries.
+	Value any
+	// Remove is true if the entry was removed.
+	Remove bool
+}
+
+// EntryLayout describes the entries of a collection: the Go types of the parts of
+// their keys, and of their values.
+type EntryLayout struct {
+	// Collection is the collection whose entries are described.
+	Collection Collection
+	// KeyTypes holds the types of the parts of the keys, matching Entry.Key.
+	KeyTypes []reflect.Type
+	// ValueType is the type of the values. It is nil for key sets.
+	ValueType reflect.Type
+}
+
+// indexable is an optional interface implemented by the collections whose entries
+// can be decoded for indexers, i.e. the ones of this package.
+type indexable interface {
+	keyTypes() []reflect.Type
+	valueType() reflect.Type
+	decodeEntry(key, value []byte, remove bool) (Entry, error)
+}
+
+// multipartKeyCodec is implemented by the key codecs of multipart keys, whose parts
+// are indexed separately.
+type multipartKeyCodec interface {
+	partTypes() []reflect.Type
+	parts(key any) []any
+}
+
+// EntryLayouts returns the layouts of the entries of the collections of the schema,
+// ordered by collection name. The collections whose entries can't be decoded are
+// skipped.
+func (s Schema) EntryLayouts() []EntryLayout {
+	layouts := make([]EntryLayout, 0, len(s.collectionsOrdered))
+	for _, name := range s.collectionsOrdered {
+		coll := s.collectionsByName[name]
+		ic, ok := coll.(indexable)
+		if !ok {
+			continue
+		}
+		layouts = append(layouts, EntryLayout{
+			Collection: coll,
+			KeyTypes:   ic.keyTypes(),
+			ValueType:  ic.valueType(),
+		})
+	}
+	return layouts
+}
+
+// DecodeEntry decodes a raw key-value pair of the store of the schema. It returns false
+// if the key doesn't belong to any collection of the schema whose entries can be decoded.
+// The value is not decoded if the pair was removed.
+func (s Schema) DecodeEntry(key, value []byte, remove bool) (Entry, bool, error) {
+	for prefix, coll := range s.collectionsByPrefix {
+		// prefixes of a schema don't overlap, so at most one collection matches
+		if !bytes.HasPrefix(key, []byte(prefix)) {
+			continue
+		}
+		ic, ok := coll.(indexable)
+		if !ok {
+			return Entry{}, false, nil
+		}
+		entry, err := ic.decodeEntry(key[len(prefix):], value, remove)
+		if err != nil {
+			return Entry{}, true, fmt.Errorf("%w: collection %s: %w", ErrEncoding, coll.GetName(), err)
+		}
+		return entry, true, nil
+	}
+	return Entry{}, false, nil
+}
+
+func (c collectionImpl[K, V]) keyTypes() []reflect.Type {
+	switch kc := any(c.m.kc).(type) {
+	case noKey:
+		return nil
+	case multipartKeyCodec:
+		return kc.partTypes()
+	default:
+		return []reflect.Type{typeOf[K]()}
+	}
+}
+
+func (c collectionImpl[K, V]) valueType() reflect.Type {
+	if t := typeOf[V](); t != reflect.TypeOf(NoValue{}) {
+		return t
+	}
+	return nil
+}
+
+func (c collectionImpl[K, V]) decodeEntry(key, value []byte, remove bool) (Entry, error) {
+	read, k, err := c.m.kc.Decode(key)
+	if err != nil {
+		return Entry{}, err
+	}
+	if read != len(key) {
+		return Entry{}, fmt.Errorf("key decoder didn't consume all bytes: %d of %d", read, len(key))
+	}
+
+	entry := Entry{Collection: c.m.name, Remove: remove}
+	switch kc := any(c.m.kc).(type) {
+	case noKey:
+	case multipartKeyCodec:
+		entry.Key = kc.parts(k)
+	default:
+		entry.Key = []any{k}
+	}
+	if remove || c.valueType() == nil {
+		return entry, nil
+	}
+
+	entry.Value, err = c.m.vc.Decode(value)
+	if err != nil {
+		return Entry{}, err
+	}
+	return entry, nil
+}
+
+func (p pairKeyCodec[K1, K2]) partTypes() []reflect.Type {
+	return []reflect.Type{typeOf[K1](), typeOf[K2]()}
+}
+
+func (p pairKeyCodec[K1, K2]) parts(key any) []any {
+	pair := key.(Pair[K1, K2])
+	return []any{pair.K1(), pair.K2()}
+}
+
+func (t tripleKeyCodec[K1, K2, K3]) partTypes() []reflect.Type {
+	return []reflect.Type{typeOf[K1](), typeOf[K2](), typeOf[K3]()}
+}
+
+func (t tripleKeyCodec[K1, K2, K3]) parts(key any) []any {
+	triple := key.(Triple[K1, K2, K3])
+	return []any{triple.K1(), triple.K2(), triple.K3()}
+}
+
+// typeOf returns the reflect.Type of T, including when T is an interface.
+func typeOf[T any]() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
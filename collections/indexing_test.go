@@ -0,0 +1,80 @@
+package collections
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaEntryLayouts(t *testing.T) {
+	sk, _ := deps()
+	sb := NewSchemaBuilder(sk)
+	NewMap(sb, NewPrefix(1), "balances", PairKeyCodec(StringKey, StringKey), Uint64Value)
+	NewKeySet(sb, NewPrefix(2), "denoms", StringKey)
+	NewItem(sb, NewPrefix(3), "params", StringValue)
+	schema, err := sb.Build()
+	require.NoError(t, err)
+
+	layouts := schema.EntryLayouts()
+	require.Len(t, layouts, 3)
+
+	require.Equal(t, "balances", layouts[0].Collection.GetName())
+	require.Equal(t, []reflect.Type{reflect.TypeOf(""), reflect.TypeOf("")}, layouts[0].KeyTypes)
+	require.Equal(t, reflect.TypeOf(uint64(0)), layouts[0].ValueType)
+
+	require.Equal(t, "denoms", layouts[1].Collection.GetName())
+	require.Equal(t, []reflect.Type{reflect.TypeOf("")}, layouts[1].KeyTypes)
+	require.Nil(t, layouts[1].ValueType)
+
+	require.Equal(t, "params", layouts[2].Collection.GetName())
+	require.Empty(t, layouts[2].KeyTypes)
+	require.Equal(t, reflect.TypeOf(""), layouts[2].ValueType)
+}
+
+func TestSchemaDecodeEntry(t *testing.T) {
+	sk, ctx := deps()
+	sb := NewSchemaBuilder(sk)
+	balances := NewMap(sb, NewPrefix(1), "balances", PairKeyCodec(StringKey, StringKey), Uint64Value)
+	denoms := NewKeySet(sb, NewPrefix(2), "denoms", StringKey)
+	params := NewItem(sb, NewPrefix(3), "params", StringValue)
+	schema, err := sb.Build()
+	require.NoError(t, err)
+
+	require.NoError(t, balances.Set(ctx, Join("alice", "atom"), 100))
+	require.NoError(t, denoms.Set(ctx, "atom"))
+	require.NoError(t, params.Set(ctx, "p"))
+
+	iter, err := sk.OpenKVStore(ctx).Iterator(nil, nil)
+	require.NoError(t, err)
+	defer iter.Close()
+
+	var entries []Entry
+	for ; iter.Valid(); iter.Next() {
+		entry, ok, err := schema.DecodeEntry(iter.Key(), iter.Value(), false)
+		require.NoError(t, err)
+		require.True(t, ok)
+		entries = append(entries, entry)
+	}
+	require.Equal(t, []Entry{
+		{Collection: "balances", Key: []any{"alice", "atom"}, Value: uint64(100)},
+		{Collection: "denoms", Key: []any{"atom"}},
+		{Collection: "params", Value: "p"},
+	}, entries)
+
+	// removals aren't decoded
+	entry, ok, err := schema.DecodeEntry(append([]byte{2}, []byte("atom")...), nil, true)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, Entry{Collection: "denoms", Key: []any{"atom"}, Remove: true}, entry)
+
+	// keys outside of the schema are ignored
+	_, ok, err = schema.DecodeEntry([]byte{9, 1}, nil, false)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	// malformed keys are reported
+	_, ok, err = schema.DecodeEntry([]byte{1, 0xff}, []byte{}, false)
+	require.ErrorIs(t, err, ErrEncoding)
+	require.True(t, ok)
+}
@@ -0,0 +1,382 @@
+package genesis
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"cosmossdk.io/core/appmodule"
+)
+
+// DefaultChunkSize is the default number of array items written to each NDJSON chunk
+// by a directory genesis target.
+const DefaultChunkSize = 100_000
+
+// chunkExt is the extension of the NDJSON chunk files.
+const chunkExt = ".ndjson"
+
+// DirTarget returns a genesis target writing each field in a file of dir. Fields holding
+// a JSON array are written as NDJSON chunks of at most chunkSize items, in the <field>
+// directory, the other fields are written as is in <field>.json. The writers hold at
+// most one array item in memory.
+func DirTarget(dir string, chunkSize int) appmodule.GenesisTarget {
+	if chunkSize <= 0 {
+		chunkSize = DefaultChunkSize
+	}
+
+	return func(field string) (io.WriteCloser, error) {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, err
+		}
+
+		return &dirWriter{dir: dir, field: field, chunkSize: chunkSize}, nil
+	}
+}
+
+// dirWriter writes a field of a directory genesis target. The first byte written
+// tells if the field is an array, which is split in chunks, or another value.
+type dirWriter struct {
+	dir       string
+	field     string
+	chunkSize int
+
+	// file is the file of a non-array field
+	file *bufio.Writer
+	f    *os.File
+
+	// array is the state of an array field
+	array *arraySplitter
+}
+
+// Write implements io.Writer.
+func (w *dirWriter) Write(p []byte) (int, error) {
+	if w.file == nil && w.array == nil {
+		trimmed := bytes.TrimLeft(p, " \t\r\n")
+		switch {
+		case len(trimmed) == 0:
+			// wait for the first byte of the value
+			return len(p), nil
+		case trimmed[0] == '[':
+			chunks := &chunkWriter{dir: filepath.Join(w.dir, w.field), size: w.chunkSize}
+			if err := os.MkdirAll(chunks.dir, 0o755); err != nil {
+				return 0, err
+			}
+			w.array = &arraySplitter{onItem: chunks.writeItem, close: chunks.close}
+		default:
+			f, err := os.Create(filepath.Join(w.dir, w.field+".json"))
+			if err != nil {
+				return 0, err
+			}
+			w.f, w.file = f, bufio.NewWriter(f)
+		}
+	}
+
+	if w.array != nil {
+		if err := w.array.write(p); err != nil {
+			return 0, fmt.Errorf("field %s: %w", w.field, err)
+		}
+		return len(p), nil
+	}
+
+	return w.file.Write(p)
+}
+
+// Close implements io.Closer.
+func (w *dirWriter) Close() error {
+	switch {
+	case w.array != nil:
+		return w.array.finish()
+	case w.file != nil:
+		if err := w.file.Flush(); err != nil {
+			_ = w.f.Close()
+			return err
+		}
+		return w.f.Close()
+	default:
+		// nothing was written, the field is absent
+		return nil
+	}
+}
+
+// arraySplitter splits a stream of a JSON array in its items.
+type arraySplitter struct {
+	onItem func(item []byte) error
+	close  func() error
+
+	started  bool // the opening bracket was read
+	ended    bool // the closing bracket was read
+	depth    int
+	inString bool
+	escaped  bool
+	item     []byte
+}
+
+func (s *arraySplitter) write(p []byte) error {
+	for _, c := range p {
+		if s.ended {
+			if !isSpace(c) {
+				return fmt.Errorf("unexpected %q after the end of the array", c)
+			}
+			continue
+		}
+
+		if !s.started {
+			if isSpace(c) {
+				continue
+			}
+			if c != '[' {
+				return fmt.Errorf("expected [ got %q", c)
+			}
+			s.started = true
+			continue
+		}
+
+		if s.inString {
+			s.item = append(s.item, c)
+			switch {
+			case s.escaped:
+				s.escaped = false
+			case c == '\\':
+				s.escaped = true
+			case c == '"':
+				s.inString = false
+			}
+			continue
+		}
+
+		switch c {
+		case '"':
+			s.inString = true
+		case '[', '{':
+			s.depth++
+		case ']', '}':
+			if s.depth == 0 {
+				if c != ']' {
+					return fmt.Errorf("unexpected %q", c)
+				}
+				s.ended = true
+				return s.flush(false)
+			}
+			s.depth--
+		case ',':
+			if s.depth == 0 {
+				if err := s.flush(true); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+
+		s.item = append(s.item, c)
+	}
+
+	return nil
+}
+
+// flush passes the current item to onItem. An item is required before a comma.
+func (s *arraySplitter) flush(required bool) error {
+	item := bytes.TrimSpace(s.item)
+	s.item = s.item[:0]
+	if len(item) == 0 {
+		if required {
+			return errors.New("empty array item")
+		}
+		return nil
+	}
+
+	// NDJSON requires each item on a single line
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, item); err != nil {
+		return err
+	}
+
+	return s.onItem(buf.Bytes())
+}
+
+func (s *arraySplitter) finish() error {
+	if !s.ended {
+		_ = s.close()
+		return errors.New("unterminated array")
+	}
+
+	return s.close()
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n'
+}
+
+// chunkWriter writes items to the NDJSON chunks of a directory.
+type chunkWriter struct {
+	dir   string
+	size  int
+	index int
+	count int
+	w     *bufio.Writer
+	f     *os.File
+}
+
+func (c *chunkWriter) writeItem(item []byte) error {
+	if c.f != nil && c.count == c.size {
+		if err := c.close(); err != nil {
+			return err
+		}
+	}
+
+	if c.f == nil {
+		f, err := os.Create(filepath.Join(c.dir, chunkName(c.index)))
+		if err != nil {
+			return err
+		}
+		c.f, c.w, c.count = f, bufio.NewWriter(f), 0
+		c.index++
+	}
+
+	c.count++
+	if _, err := c.w.Write(item); err != nil {
+		return err
+	}
+	return c.w.WriteByte('\n')
+}
+
+func (c *chunkWriter) close() error {
+	if c.f == nil {
+		return nil
+	}
+
+	f := c.f
+	c.f = nil
+	if err := c.w.Flush(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func chunkName(index int) string {
+	return fmt.Sprintf("%06d%s", index, chunkExt)
+}
+
+// DirSource returns a genesis source reading the fields written by DirTarget in dir.
+// Fields split in NDJSON chunks are read back as a single JSON array, streamed from
+// the chunks.
+func DirSource(dir string) appmodule.GenesisSource {
+	return func(field string) (io.ReadCloser, error) {
+		f, err := os.Open(filepath.Join(dir, field+".json"))
+		if err == nil {
+			return f, nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+
+		chunksDir := filepath.Join(dir, field)
+		entries, err := os.ReadDir(chunksDir)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var chunks []string
+		for _, e := range entries {
+			if !e.IsDir() && strings.HasSuffix(e.Name(), chunkExt) {
+				chunks = append(chunks, filepath.Join(chunksDir, e.Name()))
+			}
+		}
+		sort.Strings(chunks)
+
+		return &chunksReader{chunks: chunks, pending: []byte("[")}, nil
+	}
+}
+
+// chunksReader reads NDJSON chunks as a JSON array.
+type chunksReader struct {
+	chunks []string
+	f      *os.File
+	r      *bufio.Reader
+
+	pending []byte // bytes to return before reading further
+	items   int
+	done    bool
+}
+
+// Read implements io.Reader.
+func (c *chunksReader) Read(p []byte) (int, error) {
+	for len(c.pending) == 0 {
+		if c.done {
+			return 0, io.EOF
+		}
+		if err := c.next(); err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, c.pending)
+	c.pending = c.pending[n:]
+	return n, nil
+}
+
+// next reads the next item of the chunks in pending, or the closing bracket.
+func (c *chunksReader) next() error {
+	for {
+		if c.r == nil {
+			if len(c.chunks) == 0 {
+				c.pending, c.done = []byte("]"), true
+				return nil
+			}
+
+			f, err := os.Open(c.chunks[0])
+			if err != nil {
+				return err
+			}
+			c.chunks = c.chunks[1:]
+			c.f, c.r = f, bufio.NewReader(f)
+		}
+
+		line, err := c.r.ReadBytes('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			if c.items > 0 {
+				c.pending = append([]byte(","), line...)
+			} else {
+				c.pending = line
+			}
+			c.items++
+		}
+
+		if errors.Is(err, io.EOF) {
+			if err := c.f.Close(); err != nil {
+				return err
+			}
+			c.f, c.r = nil, nil
+		}
+
+		if len(c.pending) > 0 {
+			return nil
+		}
+	}
+}
+
+// Close implements io.Closer.
+func (c *chunksReader) Close() error {
+	c.done, c.pending = true, nil
+	if c.f == nil {
+		return nil
+	}
+
+	f := c.f
+	c.f, c.r = nil, nil
+	return f.Close()
+}
@@ -0,0 +1,99 @@
+package genesis
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDirTarget(t *testing.T) {
+	dir := t.TempDir()
+	target := DirTarget(dir, 2)
+
+	writeField := func(field string, chunks ...string) {
+		w, err := target(field)
+		require.NoError(t, err)
+		for _, chunk := range chunks {
+			_, err = w.Write([]byte(chunk))
+			require.NoError(t, err)
+		}
+		require.NoError(t, w.Close())
+	}
+
+	writeField("foo", "1")
+	writeField("bar", `"abc"`)
+	// arrays are split, whatever the writes boundaries
+	writeField("items", ` [{"key":"a,]","value":`, `{"x":[1, 2]}},`, "\n  2,\"b\\\"\",\n", `{"key": "c"}]`)
+	writeField("empty", "[", "]")
+
+	require.FileExists(t, filepath.Join(dir, "foo.json"))
+	require.FileExists(t, filepath.Join(dir, "bar.json"))
+
+	chunk, err := os.ReadFile(filepath.Join(dir, "items", "000000.ndjson"))
+	require.NoError(t, err)
+	require.Equal(t, "{\"key\":\"a,]\",\"value\":{\"x\":[1,2]}}\n2\n", string(chunk))
+	chunk, err = os.ReadFile(filepath.Join(dir, "items", "000001.ndjson"))
+	require.NoError(t, err)
+	require.Equal(t, "\"b\\\"\"\n{\"key\":\"c\"}\n", string(chunk))
+
+	source := DirSource(dir)
+	expectJSON(t, source, "foo", "1")
+	expectJSON(t, source, "bar", `"abc"`)
+	expectJSON(t, source, "items", `[{"key":"a,]","value":{"x":[1,2]}},2,"b\"",{"key":"c"}]`)
+	expectJSON(t, source, "empty", `[]`)
+
+	rdr, err := source("missing")
+	require.NoError(t, err)
+	require.Nil(t, rdr)
+}
+
+func TestDirTargetInvalidArray(t *testing.T) {
+	w, err := DirTarget(t.TempDir(), 0)("items")
+	require.NoError(t, err)
+
+	_, err = w.Write([]byte(`[1,,2]`))
+	require.ErrorContains(t, err, "empty array item")
+
+	w, err = DirTarget(t.TempDir(), 0)("items")
+	require.NoError(t, err)
+	_, err = w.Write([]byte(`[1, 2`))
+	require.NoError(t, err)
+	require.ErrorContains(t, w.Close(), "unterminated array")
+}
+
+func TestDirSourceReadAll(t *testing.T) {
+	dir := t.TempDir()
+	w, err := DirTarget(dir, 10)("items")
+	require.NoError(t, err)
+	_, err = w.Write([]byte("["))
+	require.NoError(t, err)
+	for i := 0; i < 25; i++ {
+		if i > 0 {
+			_, err = w.Write([]byte(","))
+			require.NoError(t, err)
+		}
+		_, err = w.Write([]byte(`"item"`))
+		require.NoError(t, err)
+	}
+	_, err = w.Write([]byte("]"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	entries, err := os.ReadDir(filepath.Join(dir, "items"))
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+
+	rdr, err := DirSource(dir)("items")
+	require.NoError(t, err)
+	bz, err := io.ReadAll(rdr)
+	require.NoError(t, err)
+	require.NoError(t, rdr.Close())
+
+	var items []string
+	require.NoError(t, json.Unmarshal(bz, &items))
+	require.Len(t, items, 25)
+}
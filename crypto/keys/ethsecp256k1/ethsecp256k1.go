@@ -0,0 +1,181 @@
+package ethsecp256k1
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+
+	"github.com/cometbft/cometbft/crypto"
+	secp256k1dcrd "github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+
+	errorsmod "cosmossdk.io/errors"
+	"cosmossdk.io/x/tx/signing/eip712"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+)
+
+var (
+	_ cryptotypes.PrivKey  = &PrivKey{}
+	_ codec.AminoMarshaler = &PrivKey{}
+)
+
+const (
+	PrivKeySize = 32
+	keyType     = "eth_secp256k1"
+	PrivKeyName = "cosmos/PrivKeyEthSecp256k1"
+	PubKeyName  = "cosmos/PubKeyEthSecp256k1"
+)
+
+// Bytes returns the byte representation of the Private Key.
+func (privKey *PrivKey) Bytes() []byte {
+	return privKey.Key
+}
+
+// PubKey returns the compressed public key of the private key.
+func (privKey *PrivKey) PubKey() cryptotypes.PubKey {
+	priv := secp256k1dcrd.PrivKeyFromBytes(privKey.Key)
+	return &PubKey{Key: priv.PubKey().SerializeCompressed()}
+}
+
+// Equals - you probably don't need to use this.
+// Runs in constant time based on length of the keys.
+func (privKey *PrivKey) Equals(other cryptotypes.LedgerPrivKey) bool {
+	return privKey.Type() == other.Type() && subtle.ConstantTimeCompare(privKey.Bytes(), other.Bytes()) == 1
+}
+
+func (privKey *PrivKey) Type() string {
+	return keyType
+}
+
+// Sign creates an ECDSA signature on curve secp256k1 of the Keccak-256 hash of
+// msg, as Ethereum wallets do. The returned signature is of the form R || S || V
+// (in lower-S form), V being the recovery id 0 or 1.
+func (privKey *PrivKey) Sign(msg []byte) ([]byte, error) {
+	if len(privKey.Key) != PrivKeySize {
+		return nil, errors.New("invalid privkey size")
+	}
+	priv := secp256k1dcrd.PrivKeyFromBytes(privKey.Key)
+	sig := ecdsa.SignCompact(priv, eip712.Keccak256(msg), false)
+
+	// move the compact recovery code, 27 + recovery id, to the end
+	return append(sig[1:], sig[0]-27), nil
+}
+
+// MarshalAmino overrides Amino binary marshaling.
+func (privKey PrivKey) MarshalAmino() ([]byte, error) {
+	return privKey.Key, nil
+}
+
+// UnmarshalAmino overrides Amino binary marshaling.
+func (privKey *PrivKey) UnmarshalAmino(bz []byte) error {
+	if len(bz) != PrivKeySize {
+		return errors.New("invalid privkey size")
+	}
+	privKey.Key = bz
+
+	return nil
+}
+
+// MarshalAminoJSON overrides Amino JSON marshaling.
+func (privKey PrivKey) MarshalAminoJSON() ([]byte, error) {
+	// When we marshal to Amino JSON, we don't marshal the "key" field itself,
+	// just its contents (i.e. the key bytes).
+	return privKey.MarshalAmino()
+}
+
+// UnmarshalAminoJSON overrides Amino JSON marshaling.
+func (privKey *PrivKey) UnmarshalAminoJSON(bz []byte) error {
+	return privKey.UnmarshalAmino(bz)
+}
+
+// GenPrivKey generates a new Ethereum-style secp256k1 private key.
+// It uses OS randomness to generate the private key.
+func GenPrivKey() *PrivKey {
+	priv, err := secp256k1dcrd.GeneratePrivateKey()
+	if err != nil {
+		panic(err)
+	}
+
+	return &PrivKey{Key: priv.Serialize()}
+}
+
+//-------------------------------------
+
+var (
+	_ cryptotypes.PubKey   = &PubKey{}
+	_ codec.AminoMarshaler = &PubKey{}
+)
+
+// PubKeySize is comprised of 32 bytes for one field element
+// (the x-coordinate), plus one byte for the parity of the y-coordinate.
+const PubKeySize = 33
+
+// Address returns the Ethereum address of the public key: the last 20 bytes of
+// the Keccak-256 hash of the uncompressed public key.
+func (pubKey *PubKey) Address() crypto.Address {
+	if len(pubKey.Key) != PubKeySize {
+		panic("length of pubkey is incorrect")
+	}
+
+	addr, err := eip712.PubKeyToAddress(pubKey.Key)
+	if err != nil {
+		panic(err)
+	}
+	return crypto.Address(addr)
+}
+
+// Bytes returns the pubkey byte format.
+func (pubKey *PubKey) Bytes() []byte {
+	return pubKey.Key
+}
+
+func (pubKey *PubKey) String() string {
+	return fmt.Sprintf("PubKeyEthSecp256k1{%X}", pubKey.Key)
+}
+
+func (pubKey *PubKey) Type() string {
+	return keyType
+}
+
+func (pubKey *PubKey) Equals(other cryptotypes.PubKey) bool {
+	return pubKey.Type() == other.Type() && bytes.Equal(pubKey.Bytes(), other.Bytes())
+}
+
+// VerifySignature verifies a signature of the form R || S || V, V being the
+// recovery id 0 or 1, of the Keccak-256 hash of msg.
+// It rejects signatures which are not in lower-S form or whose recovery id doesn't
+// recover the key, so that a signature has a single valid encoding.
+func (pubKey *PubKey) VerifySignature(msg, sig []byte) bool {
+	return eip712.VerifySignature(pubKey.Key, msg, sig)
+}
+
+// MarshalAmino overrides Amino binary marshaling.
+func (pubKey PubKey) MarshalAmino() ([]byte, error) {
+	return pubKey.Key, nil
+}
+
+// UnmarshalAmino overrides Amino binary marshaling.
+func (pubKey *PubKey) UnmarshalAmino(bz []byte) error {
+	if len(bz) != PubKeySize {
+		return errorsmod.Wrap(sdkerrors.ErrInvalidPubKey, "invalid pubkey size")
+	}
+	pubKey.Key = bz
+
+	return nil
+}
+
+// MarshalAminoJSON overrides Amino JSON marshaling.
+func (pubKey PubKey) MarshalAminoJSON() ([]byte, error) {
+	// When we marshal to Amino JSON, we don't marshal the "key" field itself,
+	// just its contents (i.e. the key bytes).
+	return pubKey.MarshalAmino()
+}
+
+// UnmarshalAminoJSON overrides Amino JSON marshaling.
+func (pubKey *PubKey) UnmarshalAminoJSON(bz []byte) error {
+	return pubKey.UnmarshalAmino(bz)
+}
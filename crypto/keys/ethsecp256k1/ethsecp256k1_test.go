@@ -0,0 +1,60 @@
+package ethsecp256k1_test
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"cosmossdk.io/x/tx/signing/eip712"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	"github.com/cosmos/cosmos-sdk/codec/types"
+	cryptocodec "github.com/cosmos/cosmos-sdk/crypto/codec"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/ethsecp256k1"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+)
+
+func TestAddress(t *testing.T) {
+	bz, err := hex.DecodeString("4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318")
+	require.NoError(t, err)
+	privKey := &ethsecp256k1.PrivKey{Key: bz}
+
+	require.Equal(t, "2c7536e3605d9c16a7a3d7b1898e529396a65c23", hex.EncodeToString(privKey.PubKey().Address()))
+}
+
+func TestSignAndVerify(t *testing.T) {
+	privKey := ethsecp256k1.GenPrivKey()
+	pubKey := privKey.PubKey()
+	msg := []byte("hello world")
+
+	sig, err := privKey.Sign(msg)
+	require.NoError(t, err)
+	require.Len(t, sig, 65)
+	require.True(t, pubKey.VerifySignature(msg, sig))
+	// the signature has a single valid encoding
+	require.False(t, pubKey.VerifySignature(msg, sig[:64]))
+	require.False(t, pubKey.VerifySignature(msg, append(sig[:64:64], sig[64]+27)))
+	require.False(t, pubKey.VerifySignature([]byte("other message"), sig))
+	require.False(t, ethsecp256k1.GenPrivKey().PubKey().VerifySignature(msg, sig))
+
+	// the public key can be recovered from the signature
+	recovered, err := eip712.RecoverPubKey(msg, sig)
+	require.NoError(t, err)
+	require.Equal(t, pubKey.Bytes(), recovered)
+}
+
+func TestMarshalAny(t *testing.T) {
+	registry := types.NewInterfaceRegistry()
+	cryptocodec.RegisterInterfaces(registry)
+	cdc := codec.NewProtoCodec(registry)
+
+	pubKey := ethsecp256k1.GenPrivKey().PubKey()
+	bz, err := cdc.MarshalInterface(pubKey)
+	require.NoError(t, err)
+
+	var decoded cryptotypes.PubKey
+	require.NoError(t, cdc.UnmarshalInterface(bz, &decoded))
+	require.True(t, pubKey.Equals(decoded))
+	require.Equal(t, "eth_secp256k1", decoded.Type())
+}
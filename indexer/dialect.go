@@ -0,0 +1,61 @@
+package indexer
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Dialect defines the SQL types the indexer uses for a database.
+type Dialect struct {
+	// Name is the name of the dialect.
+	Name string
+	// BytesType is the type of byte slices, including addresses.
+	BytesType string
+	// DecimalType is the type of arbitrary precision numbers, including uint64.
+	DecimalType string
+	// JSONType is the type of the values stored as JSON, like protobuf messages.
+	JSONType string
+	// TimeType is the type of timestamps.
+	TimeType string
+}
+
+var (
+	// Postgres is the dialect of PostgreSQL compatible databases.
+	Postgres = Dialect{
+		Name:        "postgres",
+		BytesType:   "BYTEA",
+		DecimalType: "NUMERIC",
+		JSONType:    "JSONB",
+		TimeType:    "TIMESTAMPTZ",
+	}
+
+	// SQLite is the dialect of SQLite databases, usable for local testing. Decimals
+	// are stored as text, as SQLite numbers are limited to 64 bits.
+	SQLite = Dialect{
+		Name:        "sqlite3",
+		BytesType:   "BLOB",
+		DecimalType: "TEXT",
+		JSONType:    "TEXT",
+		TimeType:    "TIMESTAMP",
+	}
+)
+
+// DialectForDriver returns the dialect of the databases opened with the given SQL driver.
+// The indexer doesn't register any driver: the app imports the one of its database, e.g.
+// github.com/lib/pq for PostgreSQL, or github.com/mattn/go-sqlite3, which requires cgo,
+// for SQLite.
+func DialectForDriver(driver string) (Dialect, error) {
+	switch driver {
+	case "postgres", "pgx":
+		return Postgres, nil
+	case "sqlite3":
+		return SQLite, nil
+	default:
+		return Dialect{}, fmt.Errorf("unsupported indexer driver %q", driver)
+	}
+}
+
+// quoteIdentifier quotes a table or column name.
+func quoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
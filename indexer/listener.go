@@ -0,0 +1,256 @@
+package indexer
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	abci "github.com/cometbft/cometbft/api/cometbft/abci/v1"
+
+	"cosmossdk.io/collections"
+	"cosmossdk.io/log"
+	storetypes "cosmossdk.io/store/types"
+)
+
+var _ storetypes.ABCIListener = (*Listener)(nil)
+
+// Options configures a Listener.
+type Options struct {
+	// Schemas are the collections schemas of the modules to index, by store key.
+	Schemas map[string]collections.Schema
+
+	// StoreKeys are the keys of the stores of the indexed modules, by name. They are
+	// used to load the state of the modules when catching up.
+	StoreKeys map[string]*storetypes.KVStoreKey
+
+	// LoadState returns the committed state at the given height. It is used to catch
+	// up when the indexer starts after StartHeight or misses blocks.
+	LoadState func(height int64) (storetypes.MultiStore, error)
+
+	// StartHeight is the first height to index. Blocks before it are ignored.
+	StartHeight int64
+}
+
+// Listener is an ABCIListener indexing the state changes of modules to a SQL database.
+// The changes are decoded using the collections schemas of the modules, and written to
+// a table per collection, see table for their layout.
+//
+// Every block is indexed in a single transaction, which also records the block in the
+// indexer_blocks table. Blocks already indexed are skipped, so that blocks replayed on
+// restart are idempotent. When blocks are missing before the committed one, the tables
+// are rebuilt from the committed state instead.
+type Listener struct {
+	db      *sql.DB
+	dialect Dialect
+	logger  log.Logger
+	opts    Options
+
+	// storeKeys are the indexed store keys, sorted.
+	storeKeys []string
+	tables    map[string]map[string]*table
+
+	// height and time of the block being indexed, set by ListenFinalizeBlock.
+	height int64
+	time   time.Time
+}
+
+// NewListener creates a listener writing to the given database, and creates its tables
+// if they don't exist.
+func NewListener(ctx context.Context, db *sql.DB, dialect Dialect, logger log.Logger, opts Options) (*Listener, error) {
+	if opts.LoadState == nil {
+		return nil, errors.New("indexer: LoadState must be set")
+	}
+
+	l := &Listener{
+		db:      db,
+		dialect: dialect,
+		logger:  logger.With(log.ModuleKey, "indexer"),
+		opts:    opts,
+		tables:  make(map[string]map[string]*table, len(opts.Schemas)),
+	}
+	for storeKey, schema := range opts.Schemas {
+		if _, ok := opts.StoreKeys[storeKey]; !ok {
+			return nil, fmt.Errorf("indexer: no store key %s", storeKey)
+		}
+		l.storeKeys = append(l.storeKeys, storeKey)
+		l.tables[storeKey] = make(map[string]*table)
+		for _, layout := range schema.EntryLayouts() {
+			l.tables[storeKey][layout.Collection.GetName()] = newTable(dialect, storeKey, layout)
+		}
+	}
+	sort.Strings(l.storeKeys)
+
+	if err := l.createTables(ctx); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// createTables creates the tables of the indexer.
+func (l *Listener) createTables(ctx context.Context) error {
+	tx, err := l.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() //nolint:errcheck // no-op after commit
+
+	statements := []string{fmt.Sprintf(
+		"CREATE TABLE IF NOT EXISTS %s (%s BIGINT PRIMARY KEY, %s %s NOT NULL)",
+		quoteIdentifier(blocksTable), quoteIdentifier("height"), quoteIdentifier("time"), l.dialect.TimeType,
+	)}
+	for _, storeKey := range l.storeKeys {
+		for _, t := range l.sortedTables(storeKey) {
+			statements = append(statements, t.createSQL())
+		}
+	}
+	for _, statement := range statements {
+		if _, err := tx.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("indexer: %s: %w", statement, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// LastIndexedHeight returns the height of the last indexed block, or 0 if none was.
+func (l *Listener) LastIndexedHeight(ctx context.Context) (int64, error) {
+	return lastIndexedHeight(ctx, l.db)
+}
+
+// ListenFinalizeBlock implements storetypes.ABCIListener. It records the block being
+// indexed.
+func (l *Listener) ListenFinalizeBlock(_ context.Context, req abci.FinalizeBlockRequest, _ abci.FinalizeBlockResponse) error {
+	l.height, l.time = req.Height, req.Time
+	return nil
+}
+
+// ListenCommit implements storetypes.ABCIListener. It indexes the changes of the block.
+func (l *Listener) ListenCommit(ctx context.Context, _ abci.CommitResponse, changeSet []*storetypes.StoreKVPair) error {
+	height := l.height
+	if height < l.opts.StartHeight {
+		return nil
+	}
+
+	tx, err := l.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() //nolint:errcheck // no-op after commit
+
+	last, err := lastIndexedHeight(ctx, tx)
+	if err != nil {
+		return err
+	}
+	switch {
+	case height <= last:
+		// the block is replayed
+		return nil
+	case last == height-1:
+		if err := l.indexChanges(ctx, tx, height, changeSet); err != nil {
+			return err
+		}
+	default:
+		l.logger.Info("catching up from committed state", "last_indexed_height", last, "height", height)
+		if err := l.indexState(ctx, tx, height); err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, fmt.Sprintf(
+		"INSERT INTO %s (%s, %s) VALUES ($1, $2)", quoteIdentifier(blocksTable), quoteIdentifier("height"), quoteIdentifier("time"),
+	), height, l.time.UTC())
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// indexChanges writes the changes of a block.
+func (l *Listener) indexChanges(ctx context.Context, tx *sql.Tx, height int64, changeSet []*storetypes.StoreKVPair) error {
+	for _, pair := range changeSet {
+		schema, ok := l.opts.Schemas[pair.StoreKey]
+		if !ok {
+			continue
+		}
+		if err := l.writeEntry(ctx, tx, schema, pair.StoreKey, pair.Key, pair.Value, pair.Delete, height); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// indexState rebuilds the tables from the committed state at the given height.
+func (l *Listener) indexState(ctx context.Context, tx *sql.Tx, height int64) error {
+	ms, err := l.opts.LoadState(height)
+	if err != nil {
+		return fmt.Errorf("indexer: load state at height %d: %w", height, err)
+	}
+
+	for _, storeKey := range l.storeKeys {
+		for _, t := range l.sortedTables(storeKey) {
+			if _, err := tx.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s", quoteIdentifier(t.name))); err != nil {
+				return err
+			}
+		}
+
+		if err := l.indexStore(ctx, tx, ms, storeKey, height); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// indexStore writes all the entries of a store.
+func (l *Listener) indexStore(ctx context.Context, tx *sql.Tx, ms storetypes.MultiStore, storeKey string, height int64) error {
+	schema := l.opts.Schemas[storeKey]
+	iter := ms.GetKVStore(l.opts.StoreKeys[storeKey]).Iterator(nil, nil)
+	defer iter.Close()
+
+	for ; iter.Valid(); iter.Next() {
+		if err := l.writeEntry(ctx, tx, schema, storeKey, iter.Key(), iter.Value(), false, height); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeEntry decodes a raw key-value pair and writes it to the table of its collection.
+// Pairs which can't be decoded fail the block, which isn't recorded as indexed, so that the
+// tables never silently miss a change.
+func (l *Listener) writeEntry(ctx context.Context, tx *sql.Tx, schema collections.Schema, storeKey string, key, value []byte, remove bool, height int64) error {
+	entry, ok, err := schema.DecodeEntry(key, value, remove)
+	if err != nil {
+		return fmt.Errorf("indexer: decode %s key %X at height %d: %w", storeKey, key, height, err)
+	}
+	if !ok {
+		return nil
+	}
+	return l.tables[storeKey][entry.Collection].write(ctx, tx, entry, height)
+}
+
+// sortedTables returns the tables of a store, ordered by name.
+func (l *Listener) sortedTables(storeKey string) []*table {
+	tables := make([]*table, 0, len(l.tables[storeKey]))
+	for _, t := range l.tables[storeKey] {
+		tables = append(tables, t)
+	}
+	sort.Slice(tables, func(i, j int) bool { return tables[i].name < tables[j].name })
+	return tables
+}
+
+// queryer is implemented by *sql.DB and *sql.Tx.
+type queryer interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// lastIndexedHeight returns the height of the last indexed block, or 0 if none was.
+func lastIndexedHeight(ctx context.Context, q queryer) (int64, error) {
+	var height sql.NullInt64
+	err := q.QueryRowContext(ctx, fmt.Sprintf("SELECT MAX(%s) FROM %s", quoteIdentifier("height"), quoteIdentifier(blocksTable))).Scan(&height)
+	if err != nil {
+		return 0, err
+	}
+	return height.Int64, nil
+}
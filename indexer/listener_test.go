@@ -0,0 +1,233 @@
+package indexer_test
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	abci "github.com/cometbft/cometbft/api/cometbft/abci/v1"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/require"
+
+	"cosmossdk.io/collections"
+	"cosmossdk.io/indexer"
+	"cosmossdk.io/log"
+	"cosmossdk.io/math"
+	storetypes "cosmossdk.io/store/types"
+
+	"github.com/cosmos/cosmos-sdk/runtime"
+	"github.com/cosmos/cosmos-sdk/testutil"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+var (
+	alice = sdk.AccAddress("alice")
+	bob   = sdk.AccAddress("bob")
+)
+
+type fixture struct {
+	ctx  sdk.Context
+	cms  storetypes.CommitMultiStore
+	key  *storetypes.KVStoreKey
+	time time.Time
+
+	schema   collections.Schema
+	balances collections.Map[collections.Pair[sdk.AccAddress, string], math.Int]
+	denoms   collections.KeySet[string]
+	params   collections.Item[uint64]
+}
+
+func newFixture(t *testing.T) *fixture {
+	t.Helper()
+	key := storetypes.NewKVStoreKey("bank")
+	testCtx := testutil.DefaultContextWithDB(t, key, storetypes.NewTransientStoreKey("transient_bank"))
+	testCtx.CMS.AddListeners([]storetypes.StoreKey{key})
+
+	sb := collections.NewSchemaBuilder(runtime.NewKVStoreService(key))
+	f := &fixture{
+		ctx:      testCtx.Ctx,
+		cms:      testCtx.CMS,
+		key:      key,
+		time:     time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		balances: collections.NewMap(sb, collections.NewPrefix(1), "balances", collections.PairKeyCodec(sdk.AccAddressKey, collections.StringKey), sdk.IntValue),
+		denoms:   collections.NewKeySet(sb, collections.NewPrefix(2), "denoms", collections.StringKey),
+		params:   collections.NewItem(sb, collections.NewPrefix(3), "params", collections.Uint64Value),
+	}
+	schema, err := sb.Build()
+	require.NoError(t, err)
+	f.schema = schema
+	return f
+}
+
+func (f *fixture) newListener(t *testing.T, startHeight int64) (*indexer.Listener, *sql.DB) {
+	t.Helper()
+	db, err := sql.Open("sqlite3", "file:"+filepath.Join(t.TempDir(), "indexer.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	listener, err := indexer.NewListener(context.Background(), db, indexer.SQLite, log.NewNopLogger(), indexer.Options{
+		Schemas:   map[string]collections.Schema{f.key.Name(): f.schema},
+		StoreKeys: map[string]*storetypes.KVStoreKey{f.key.Name(): f.key},
+		LoadState: func(height int64) (storetypes.MultiStore, error) {
+			return f.cms.CacheMultiStoreWithVersion(height)
+		},
+		StartHeight: startHeight,
+	})
+	require.NoError(t, err)
+	return listener, db
+}
+
+// commit commits the state and streams the block to the listeners.
+func (f *fixture) commit(t *testing.T, listeners ...*indexer.Listener) {
+	t.Helper()
+	commitID := f.cms.Commit()
+	changeSet := f.cms.PopStateCache()
+	for _, listener := range listeners {
+		req := abci.FinalizeBlockRequest{Height: commitID.Version, Time: f.time.Add(time.Duration(commitID.Version) * time.Second)}
+		require.NoError(t, listener.ListenFinalizeBlock(f.ctx, req, abci.FinalizeBlockResponse{}))
+		require.NoError(t, listener.ListenCommit(f.ctx, abci.CommitResponse{}, changeSet))
+	}
+}
+
+type balanceRow struct {
+	addr   []byte
+	denom  string
+	amount string
+	height int64
+}
+
+func queryBalances(t *testing.T, db *sql.DB) []balanceRow {
+	t.Helper()
+	rows, err := db.Query(`SELECT "key_1", "key_2", "value", "block_height" FROM "bank_balances" ORDER BY "key_1", "key_2"`)
+	require.NoError(t, err)
+	defer rows.Close()
+
+	var balances []balanceRow
+	for rows.Next() {
+		var row balanceRow
+		require.NoError(t, rows.Scan(&row.addr, &row.denom, &row.amount, &row.height))
+		balances = append(balances, row)
+	}
+	require.NoError(t, rows.Err())
+	return balances
+}
+
+func queryStrings(t *testing.T, db *sql.DB, query string) []string {
+	t.Helper()
+	rows, err := db.Query(query)
+	require.NoError(t, err)
+	defer rows.Close()
+
+	var values []string
+	for rows.Next() {
+		var value string
+		require.NoError(t, rows.Scan(&value))
+		values = append(values, value)
+	}
+	require.NoError(t, rows.Err())
+	return values
+}
+
+func TestListener(t *testing.T) {
+	f := newFixture(t)
+	listener, db := f.newListener(t, 1)
+
+	require.NoError(t, f.balances.Set(f.ctx, collections.Join(alice, "atom"), math.NewInt(100)))
+	require.NoError(t, f.denoms.Set(f.ctx, "atom"))
+	require.NoError(t, f.params.Set(f.ctx, 5))
+	f.commit(t, listener)
+
+	require.Equal(t, []balanceRow{{alice, "atom", "100", 1}}, queryBalances(t, db))
+	require.Equal(t, []string{"atom"}, queryStrings(t, db, `SELECT "key" FROM "bank_denoms"`))
+	require.Equal(t, []string{"5"}, queryStrings(t, db, `SELECT "value" FROM "bank_params"`))
+
+	require.NoError(t, f.balances.Set(f.ctx, collections.Join(alice, "atom"), math.NewInt(50)))
+	require.NoError(t, f.balances.Set(f.ctx, collections.Join(bob, "atom"), math.NewInt(1)))
+	require.NoError(t, f.denoms.Remove(f.ctx, "atom"))
+	require.NoError(t, f.params.Set(f.ctx, 6))
+	f.commit(t, listener)
+
+	require.Equal(t, []balanceRow{{alice, "atom", "50", 2}, {bob, "atom", "1", 2}}, queryBalances(t, db))
+	require.Empty(t, queryStrings(t, db, `SELECT "key" FROM "bank_denoms"`))
+	require.Equal(t, []string{"6"}, queryStrings(t, db, `SELECT "value" FROM "bank_params"`))
+
+	height, err := listener.LastIndexedHeight(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, int64(2), height)
+
+	// replaying an indexed block is a no-op
+	replayed := []*storetypes.StoreKVPair{{StoreKey: f.key.Name(), Delete: true, Key: append([]byte{1}, []byte("whatever")...)}}
+	require.NoError(t, listener.ListenFinalizeBlock(f.ctx, abci.FinalizeBlockRequest{Height: 2}, abci.FinalizeBlockResponse{}))
+	require.NoError(t, listener.ListenCommit(f.ctx, abci.CommitResponse{}, replayed))
+	require.Equal(t, []balanceRow{{alice, "atom", "50", 2}, {bob, "atom", "1", 2}}, queryBalances(t, db))
+}
+
+func TestListenerDecodeError(t *testing.T) {
+	f := newFixture(t)
+	listener, db := f.newListener(t, 1)
+
+	require.NoError(t, f.params.Set(f.ctx, 5))
+	f.commit(t, listener)
+
+	// a change which can't be decoded fails the block
+	require.NoError(t, f.balances.Set(f.ctx, collections.Join(alice, "atom"), math.NewInt(100)))
+	f.ctx.KVStore(f.key).Set([]byte{3}, []byte("invalid"))
+	commitID := f.cms.Commit()
+	require.NoError(t, listener.ListenFinalizeBlock(f.ctx, abci.FinalizeBlockRequest{Height: commitID.Version, Time: f.time}, abci.FinalizeBlockResponse{}))
+	require.ErrorContains(t, listener.ListenCommit(f.ctx, abci.CommitResponse{}, f.cms.PopStateCache()), "indexer: decode bank key 03 at height 2")
+
+	height, err := listener.LastIndexedHeight(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, int64(1), height)
+	require.Empty(t, queryBalances(t, db))
+	require.Equal(t, []string{"5"}, queryStrings(t, db, `SELECT "value" FROM "bank_params"`))
+
+	// the failed block is caught up from the committed state once it can be decoded
+	require.NoError(t, f.params.Set(f.ctx, 6))
+	f.commit(t, listener)
+
+	require.Equal(t, []balanceRow{{alice, "atom", "100", 3}}, queryBalances(t, db))
+	require.Equal(t, []string{"6"}, queryStrings(t, db, `SELECT "value" FROM "bank_params"`))
+}
+
+func TestListenerCatchUp(t *testing.T) {
+	f := newFixture(t)
+
+	require.NoError(t, f.balances.Set(f.ctx, collections.Join(alice, "atom"), math.NewInt(100)))
+	require.NoError(t, f.denoms.Set(f.ctx, "atom"))
+	f.commit(t)
+
+	require.NoError(t, f.balances.Set(f.ctx, collections.Join(bob, "atom"), math.NewInt(1)))
+	require.NoError(t, f.params.Set(f.ctx, 5))
+	f.commit(t)
+
+	// blocks before the start height are ignored
+	listener, db := f.newListener(t, 4)
+	require.NoError(t, f.denoms.Set(f.ctx, "stake"))
+	f.commit(t, listener)
+	height, err := listener.LastIndexedHeight(context.Background())
+	require.NoError(t, err)
+	require.Zero(t, height)
+
+	// the first indexed block loads the whole state
+	require.NoError(t, f.balances.Set(f.ctx, collections.Join(alice, "atom"), math.NewInt(99)))
+	f.commit(t, listener)
+
+	require.Equal(t, []balanceRow{{alice, "atom", "99", 4}, {bob, "atom", "1", 4}}, queryBalances(t, db))
+	require.Equal(t, []string{"atom", "stake"}, queryStrings(t, db, `SELECT "key" FROM "bank_denoms" ORDER BY "key"`))
+	require.Equal(t, []string{"5"}, queryStrings(t, db, `SELECT "value" FROM "bank_params"`))
+
+	// missed blocks are caught up from the committed state
+	require.NoError(t, f.balances.Remove(f.ctx, collections.Join(bob, "atom")))
+	f.commit(t)
+	require.NoError(t, f.params.Set(f.ctx, 6))
+	f.commit(t, listener)
+
+	require.Equal(t, []balanceRow{{alice, "atom", "99", 6}}, queryBalances(t, db))
+	require.Equal(t, []string{"6"}, queryStrings(t, db, `SELECT "value" FROM "bank_params"`))
+	height, err = listener.LastIndexedHeight(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, int64(6), height)
+}
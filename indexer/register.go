@@ -0,0 +1,108 @@
+package indexer
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cast"
+
+	"cosmossdk.io/collections"
+	storetypes "cosmossdk.io/store/types"
+
+	"github.com/cosmos/cosmos-sdk/baseapp"
+	servertypes "github.com/cosmos/cosmos-sdk/server/types"
+)
+
+const (
+	IndexerTomlKey            = "indexer"
+	IndexerDriverTomlKey      = "driver"
+	IndexerDSNTomlKey         = "dsn"
+	IndexerKeysTomlKey        = "keys"
+	IndexerStartHeightTomlKey = "start-height"
+)
+
+// RegisterWithBaseApp registers the indexer configured in the streaming.indexer section
+// of the app options with the BaseApp. The schemas are the collections schemas of the
+// modules which can be indexed, by store key. It is a no-op if no driver is configured.
+//
+// The indexer receives the blocks through the durable streaming queue, and resumes from its
+// cursor after a restart, so that it never blocks consensus, including when catching up
+// from the committed state. It fails if the queue is disabled or no start height is set.
+func RegisterWithBaseApp(
+	app *baseapp.BaseApp,
+	appOpts servertypes.AppOptions,
+	keys map[string]*storetypes.KVStoreKey,
+	schemas map[string]collections.Schema,
+) error {
+	driver := strings.TrimSpace(cast.ToString(appOpts.Get(indexerOption(IndexerDriverTomlKey))))
+	if driver == "" {
+		return nil
+	}
+	dialect, err := DialectForDriver(driver)
+	if err != nil {
+		return err
+	}
+	if app.StreamingQueue() == nil {
+		return fmt.Errorf("indexer: the streaming queue must be enabled in %s.%s", baseapp.StreamingTomlKey, baseapp.StreamingQueueTomlKey)
+	}
+	startHeight := cast.ToInt64(appOpts.Get(indexerOption(IndexerStartHeightTomlKey)))
+	if startHeight <= 0 {
+		return fmt.Errorf("indexer: %s must be positive", indexerOption(IndexerStartHeightTomlKey))
+	}
+
+	indexedSchemas, err := selectSchemas(cast.ToStringSlice(appOpts.Get(indexerOption(IndexerKeysTomlKey))), schemas)
+	if err != nil {
+		return err
+	}
+	storeKeys := make([]storetypes.StoreKey, 0, len(indexedSchemas))
+	for storeKey := range indexedSchemas {
+		if _, ok := keys[storeKey]; !ok {
+			return fmt.Errorf("indexer: no store key %s", storeKey)
+		}
+		storeKeys = append(storeKeys, keys[storeKey])
+	}
+	// sort storeKeys for deterministic output
+	sort.Slice(storeKeys, func(i, j int) bool { return storeKeys[i].Name() < storeKeys[j].Name() })
+
+	db, err := sql.Open(driver, cast.ToString(appOpts.Get(indexerOption(IndexerDSNTomlKey))))
+	if err != nil {
+		return fmt.Errorf("indexer: open database: %w", err)
+	}
+	listener, err := NewListener(context.Background(), db, dialect, app.Logger(), Options{
+		Schemas:   indexedSchemas,
+		StoreKeys: keys,
+		LoadState: func(height int64) (storetypes.MultiStore, error) {
+			return app.CommitMultiStore().CacheMultiStoreWithVersion(height)
+		},
+		StartHeight: startHeight,
+	})
+	if err != nil {
+		_ = db.Close()
+		return err
+	}
+
+	return app.AddQueuedABCIListener(IndexerTomlKey, storeKeys, listener, false)
+}
+
+// selectSchemas returns the schemas of the given store keys, or all of them for "*".
+func selectSchemas(keys []string, schemas map[string]collections.Schema) (map[string]collections.Schema, error) {
+	selected := make(map[string]collections.Schema, len(keys))
+	for _, key := range keys {
+		if key == "*" {
+			return schemas, nil
+		}
+		schema, ok := schemas[key]
+		if !ok {
+			return nil, fmt.Errorf("indexer: no collections schema for store key %s", key)
+		}
+		selected[key] = schema
+	}
+	return selected, nil
+}
+
+func indexerOption(key string) string {
+	return fmt.Sprintf("%s.%s.%s", baseapp.StreamingTomlKey, IndexerTomlKey, key)
+}
@@ -0,0 +1,197 @@
+package indexer
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"cosmossdk.io/collections"
+	"cosmossdk.io/math"
+)
+
+const (
+	// blocksTable is the name of the table of the indexed blocks.
+	blocksTable = "indexer_blocks"
+	// blockHeightColumn is the name of the column holding the height of the block which
+	// last wrote a row.
+	blockHeightColumn = "block_height"
+)
+
+var (
+	timeType = reflect.TypeOf(time.Time{})
+	// decimalTypes are the types stored as decimals, using their String method.
+	decimalTypes = map[reflect.Type]bool{
+		reflect.TypeOf(math.Int{}):       true,
+		reflect.TypeOf(math.Uint{}):      true,
+		reflect.TypeOf(math.LegacyDec{}): true,
+	}
+)
+
+// column is a column of a collection table.
+type column struct {
+	name    string
+	sqlType string
+	// convert converts a key part or value to its SQL representation.
+	convert func(any) (any, error)
+}
+
+// table is the table a collection is indexed to. Its rows hold the key parts in
+// columns key (simple keys) or key_1..key_n (multipart keys), the value in column
+// value (except for key sets), and the height of the block which wrote them. Items
+// have no key column, and a single row.
+type table struct {
+	name       string
+	keyColumns []column
+	// valueColumn is nil for key sets.
+	valueColumn *column
+
+	upsertSQL string
+	deleteSQL string
+}
+
+// newTable creates the table a collection of the store with the given key is indexed to.
+func newTable(dialect Dialect, storeKey string, layout collections.EntryLayout) *table {
+	coll := layout.Collection
+	t := &table{name: storeKey + "_" + coll.GetName()}
+
+	for i, keyType := range layout.KeyTypes {
+		name := "key"
+		if len(layout.KeyTypes) > 1 {
+			name = fmt.Sprintf("key_%d", i+1)
+		}
+		t.keyColumns = append(t.keyColumns, newColumn(dialect, name, keyType, nil))
+	}
+	if layout.ValueType != nil {
+		valueColumn := newColumn(dialect, "value", layout.ValueType, coll.ValueCodec().EncodeJSON)
+		t.valueColumn = &valueColumn
+	}
+
+	columns := t.columns()
+	names := make([]string, 0, len(columns))
+	placeholders := make([]string, 0, len(columns))
+	for i, c := range columns {
+		names = append(names, quoteIdentifier(c.name))
+		placeholders = append(placeholders, fmt.Sprintf("$%d", i+1))
+	}
+	t.upsertSQL = fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", quoteIdentifier(t.name), strings.Join(names, ", "), strings.Join(placeholders, ", "))
+
+	if len(t.keyColumns) == 0 {
+		// items are replaced, as they have a single row
+		t.deleteSQL = fmt.Sprintf("DELETE FROM %s", quoteIdentifier(t.name))
+		return t
+	}
+
+	keyNames := names[:len(t.keyColumns)]
+	updates := make([]string, 0, len(columns)-len(t.keyColumns))
+	for _, name := range names[len(t.keyColumns):] {
+		updates = append(updates, fmt.Sprintf("%s = excluded.%s", name, name))
+	}
+	t.upsertSQL += fmt.Sprintf(" ON CONFLICT (%s) DO UPDATE SET %s", strings.Join(keyNames, ", "), strings.Join(updates, ", "))
+
+	conditions := make([]string, 0, len(keyNames))
+	for i, name := range keyNames {
+		conditions = append(conditions, fmt.Sprintf("%s = $%d", name, i+1))
+	}
+	t.deleteSQL = fmt.Sprintf("DELETE FROM %s WHERE %s", quoteIdentifier(t.name), strings.Join(conditions, " AND "))
+	return t
+}
+
+// columns returns the columns of the table, key columns first.
+func (t *table) columns() []column {
+	columns := append([]column{}, t.keyColumns...)
+	if t.valueColumn != nil {
+		columns = append(columns, *t.valueColumn)
+	}
+	return append(columns, column{name: blockHeightColumn, sqlType: "BIGINT NOT NULL"})
+}
+
+// createSQL returns the statement creating the table.
+func (t *table) createSQL() string {
+	defs := make([]string, 0, len(t.keyColumns)+3)
+	keyNames := make([]string, 0, len(t.keyColumns))
+	for _, c := range t.columns() {
+		defs = append(defs, fmt.Sprintf("%s %s", quoteIdentifier(c.name), c.sqlType))
+	}
+	for _, c := range t.keyColumns {
+		keyNames = append(keyNames, quoteIdentifier(c.name))
+	}
+	if len(keyNames) > 0 {
+		defs = append(defs, fmt.Sprintf("PRIMARY KEY (%s)", strings.Join(keyNames, ", ")))
+	}
+	return fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s)", quoteIdentifier(t.name), strings.Join(defs, ", "))
+}
+
+// write writes an entry of the collection at the given height.
+func (t *table) write(ctx context.Context, tx *sql.Tx, entry collections.Entry, height int64) error {
+	if len(entry.Key) != len(t.keyColumns) {
+		return fmt.Errorf("table %s: expected %d key parts, got %d", t.name, len(t.keyColumns), len(entry.Key))
+	}
+	args := make([]any, 0, len(t.keyColumns)+2)
+	for i, part := range entry.Key {
+		arg, err := t.keyColumns[i].convert(part)
+		if err != nil {
+			return fmt.Errorf("table %s: column %s: %w", t.name, t.keyColumns[i].name, err)
+		}
+		args = append(args, arg)
+	}
+
+	if entry.Remove {
+		_, err := tx.ExecContext(ctx, t.deleteSQL, args...)
+		return err
+	}
+	if len(t.keyColumns) == 0 {
+		if _, err := tx.ExecContext(ctx, t.deleteSQL); err != nil {
+			return err
+		}
+	}
+	if t.valueColumn != nil {
+		arg, err := t.valueColumn.convert(entry.Value)
+		if err != nil {
+			return fmt.Errorf("table %s: column %s: %w", t.name, t.valueColumn.name, err)
+		}
+		args = append(args, arg)
+	}
+	args = append(args, height)
+	_, err := tx.ExecContext(ctx, t.upsertSQL, args...)
+	return err
+}
+
+// newColumn returns the column holding values of the given type. Values of types
+// without a SQL equivalent are stored as JSON if encodeJSON is provided, and as text
+// otherwise.
+func newColumn(dialect Dialect, name string, typ reflect.Type, encodeJSON func(any) ([]byte, error)) column {
+	c := column{name: name}
+	switch {
+	case typ == timeType:
+		c.sqlType, c.convert = dialect.TimeType, func(v any) (any, error) { return v, nil }
+	case decimalTypes[typ]:
+		c.sqlType, c.convert = dialect.DecimalType, func(v any) (any, error) { return v.(fmt.Stringer).String(), nil }
+	case typ.Kind() == reflect.Slice && typ.Elem().Kind() == reflect.Uint8:
+		c.sqlType, c.convert = dialect.BytesType, func(v any) (any, error) { return reflect.ValueOf(v).Bytes(), nil }
+	case typ.Kind() == reflect.String:
+		c.sqlType, c.convert = "TEXT", func(v any) (any, error) { return reflect.ValueOf(v).String(), nil }
+	case typ.Kind() == reflect.Bool:
+		c.sqlType, c.convert = "BOOLEAN", func(v any) (any, error) { return reflect.ValueOf(v).Bool(), nil }
+	case typ.Kind() >= reflect.Int && typ.Kind() <= reflect.Int64:
+		c.sqlType, c.convert = "BIGINT", func(v any) (any, error) { return reflect.ValueOf(v).Int(), nil }
+	case typ.Kind() >= reflect.Uint8 && typ.Kind() <= reflect.Uint32:
+		c.sqlType, c.convert = "BIGINT", func(v any) (any, error) { return int64(reflect.ValueOf(v).Uint()), nil }
+	case typ.Kind() == reflect.Uint || typ.Kind() == reflect.Uint64:
+		c.sqlType, c.convert = dialect.DecimalType, func(v any) (any, error) { return strconv.FormatUint(reflect.ValueOf(v).Uint(), 10), nil }
+	case encodeJSON != nil:
+		c.sqlType, c.convert = dialect.JSONType, func(v any) (any, error) {
+			bz, err := encodeJSON(v)
+			if err != nil {
+				return nil, err
+			}
+			return string(bz), nil
+		}
+	default:
+		c.sqlType, c.convert = "TEXT", func(v any) (any, error) { return fmt.Sprint(v), nil }
+	}
+	return c
+}
@@ -0,0 +1,171 @@
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/rs/zerolog"
+)
+
+// LevelFilter filters the log entries by the level of their module, like the filter
+// returned by ParseLogLevel, but the levels can be changed at runtime. It is safe for
+// concurrent use.
+type LevelFilter struct {
+	mtx    sync.Mutex // serializes the updates
+	levels atomic.Pointer[map[string]zerolog.Level]
+}
+
+// NewLevelFilter returns a LevelFilter with the given levels, in the format of
+// ParseLogLevel.
+func NewLevelFilter(levelStr string) (*LevelFilter, error) {
+	f := &LevelFilter{}
+	if err := f.SetLevel(levelStr); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// SetLevel replaces the levels of the filter with the given ones, in the format of
+// ParseLogLevel.
+func (f *LevelFilter) SetLevel(levelStr string) error {
+	levels, err := parseLevels(levelStr)
+	if err != nil {
+		return err
+	}
+
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.levels.Store(&levels)
+	return nil
+}
+
+// SetModuleLevel sets the level of a module, or the default level if the module
+// is "*", keeping the levels of the other modules.
+func (f *LevelFilter) SetModuleLevel(module, level string) error {
+	if module == "" {
+		return fmt.Errorf("empty module")
+	}
+	zllevel, err := zerolog.ParseLevel(level)
+	if err != nil {
+		return fmt.Errorf("invalid log level %s: %w", level, err)
+	}
+
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	levels := make(map[string]zerolog.Level)
+	for k, v := range *f.levels.Load() {
+		levels[k] = v
+	}
+	levels[module] = zllevel
+	f.levels.Store(&levels)
+	return nil
+}
+
+// String returns the levels of the filter in the format of ParseLogLevel, the
+// default level last.
+func (f *LevelFilter) String() string {
+	levels := *f.levels.Load()
+	pairs := make([]string, 0, len(levels))
+	for module, level := range levels {
+		if module != defaultLogLevelKey {
+			pairs = append(pairs, module+":"+level.String())
+		}
+	}
+	sort.Strings(pairs)
+	if level, ok := levels[defaultLogLevelKey]; ok {
+		pairs = append(pairs, defaultLogLevelKey+":"+level.String())
+	}
+	return strings.Join(pairs, ",")
+}
+
+// Filter returns true if the log entries of the given module and level are
+// filtered out. It is a FilterFunc.
+func (f *LevelFilter) Filter(module, level string) bool {
+	zllevel, err := zerolog.ParseLevel(level)
+	if err != nil {
+		panic(err)
+	}
+	return f.filtered(module, zllevel)
+}
+
+func (f *LevelFilter) filtered(module string, level zerolog.Level) bool {
+	levels := *f.levels.Load()
+	minLevel, ok := levels[module]
+	if !ok {
+		// check if there is a default level filter
+		minLevel, ok = levels[defaultLogLevelKey]
+		if !ok {
+			return false
+		}
+	}
+	return level < minLevel
+}
+
+// levelRequest is the body of the requests changing the levels of a LevelFilter.
+type levelRequest struct {
+	// Module is the module whose level is set. All the levels are replaced if it is
+	// empty.
+	Module string `json:"module,omitempty"`
+	// Level is the level of the module, or the levels of all the modules in the
+	// format of ParseLogLevel.
+	Level string `json:"level"`
+}
+
+// ServeHTTP serves the log levels of the filter: GET returns them, and PUT or POST
+// changes the level of a module, or all the levels, from a JSON body such as
+// {"module": "consensus", "level": "debug"} or {"level": "consensus:debug,*:info"}.
+func (f *LevelFilter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut, http.MethodPost:
+		var req levelRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeLevelError(w, http.StatusBadRequest, fmt.Errorf("invalid request: %w", err))
+			return
+		}
+
+		var err error
+		if req.Module != "" {
+			err = f.SetModuleLevel(req.Module, req.Level)
+		} else {
+			err = f.SetLevel(req.Level)
+		}
+		if err != nil {
+			writeLevelError(w, http.StatusBadRequest, err)
+			return
+		}
+	default:
+		writeLevelError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(levelRequest{Level: f.String()})
+}
+
+// ListenLevelAdmin listens on the given address for the endpoint serving the levels
+// of a LevelFilter. The endpoint isn't authenticated, so only loopback addresses,
+// such as localhost:1318, are accepted.
+func ListenLevelAdmin(address string) (net.Listener, error) {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid log admin address %q: %w", address, err)
+	}
+	if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+		return nil, fmt.Errorf("log admin address %q is not a loopback address", address)
+	}
+
+	return net.Listen("tcp", address)
+}
+
+func writeLevelError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,97 @@
+package log_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"cosmossdk.io/log"
+)
+
+func TestLevelFilter(t *testing.T) {
+	_, err := log.NewLevelFilter("consensus:foo")
+	assert.ErrorContains(t, err, "invalid log level foo")
+
+	filter, err := log.NewLevelFilter("info")
+	assert.NilError(t, err)
+	assert.Equal(t, "*:info", filter.String())
+
+	buf := new(bytes.Buffer)
+	logger := log.NewLogger(buf, log.LevelFilterOption(filter), log.ColorOption(false))
+	consensus := logger.With(log.ModuleKey, "consensus")
+	consensus.Debug("debug consensus")
+	logger.Info("info")
+	assert.Assert(t, !strings.Contains(buf.String(), "debug consensus"))
+	assert.Assert(t, strings.Contains(buf.String(), "info"))
+
+	// the levels are changed at runtime, for the existing loggers
+	assert.NilError(t, filter.SetModuleLevel("consensus", "debug"))
+	assert.NilError(t, filter.SetModuleLevel(log.ModuleKey, "error"))
+	assert.Equal(t, "consensus:debug,module:error,*:info", filter.String())
+	consensus.Debug("debug consensus")
+	logger.Debug("debug other")
+	assert.Assert(t, strings.Contains(buf.String(), "debug consensus"))
+	assert.Assert(t, !strings.Contains(buf.String(), "debug other"))
+
+	assert.NilError(t, filter.SetLevel("mempool:debug,*:error"))
+	assert.Assert(t, filter.Filter("consensus", "info"))
+	assert.Assert(t, !filter.Filter("mempool", "debug"))
+	assert.ErrorContains(t, filter.SetModuleLevel("consensus", "foo"), "invalid log level foo")
+
+	got, ok := log.GetLevelFilter(consensus)
+	assert.Assert(t, ok)
+	assert.Equal(t, filter, got)
+	_, ok = log.GetLevelFilter(log.NewNopLogger())
+	assert.Assert(t, !ok)
+}
+
+func TestLevelFilterHTTP(t *testing.T) {
+	filter, err := log.NewLevelFilter("*:info")
+	assert.NilError(t, err)
+
+	serve := func(method, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		filter.ServeHTTP(rec, httptest.NewRequest(method, "/log/level", strings.NewReader(body)))
+		return rec
+	}
+
+	rec := serve(http.MethodGet, "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `{"level":"*:info"}`+"\n", rec.Body.String())
+
+	rec = serve(http.MethodPut, `{"module":"consensus","level":"debug"}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `{"level":"consensus:debug,*:info"}`+"\n", rec.Body.String())
+
+	rec = serve(http.MethodPost, `{"level":"error"}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "*:error", filter.String())
+
+	rec = serve(http.MethodPut, `{"level":"consensus:foo"}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, "*:error", filter.String())
+
+	rec = serve(http.MethodDelete, "")
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
+
+func TestListenLevelAdmin(t *testing.T) {
+	for _, address := range []string{"0.0.0.0:0", ":0", "example.com:0", "localhost"} {
+		_, err := log.ListenLevelAdmin(address)
+		assert.ErrorContains(t, err, "log admin address", address)
+	}
+
+	for _, address := range []string{"localhost:0", "127.0.0.1:0", "[::1]:0"} {
+		listener, err := log.ListenLevelAdmin(address)
+		if address == "[::1]:0" && err != nil && !strings.Contains(err.Error(), "log admin address") {
+			// IPv6 isn't available
+			continue
+		}
+		assert.NilError(t, err, address)
+		assert.NilError(t, listener.Close())
+	}
+}
@@ -57,6 +57,11 @@ func WithJSONMarshal(marshaler func(v any) ([]byte, error)) {
 
 type zeroLogWrapper struct {
 	*zerolog.Logger
+
+	// module is the module of the logger, set with the ModuleKey.
+	module      string
+	levelFilter *LevelFilter
+	redactor    *redactor
 }
 
 // NewLogger returns a new logger that writes to the given destination.
@@ -105,42 +110,80 @@ func NewLogger(dst io.Writer, options ...Option) Logger {
 
 	logger = logger.Hook(logCfg.Hooks...)
 
-	return zeroLogWrapper{&logger}
+	return zeroLogWrapper{
+		Logger:      &logger,
+		levelFilter: logCfg.LevelFilter,
+		redactor:    newRedactor(logCfg.Redact),
+	}
 }
 
 // NewCustomLogger returns a new logger with the given zerolog logger.
 func NewCustomLogger(logger zerolog.Logger) Logger {
-	return zeroLogWrapper{&logger}
+	return zeroLogWrapper{Logger: &logger}
+}
+
+// GetLevelFilter returns the level filter of a logger created with the
+// LevelFilterOption, if any.
+func GetLevelFilter(logger Logger) (*LevelFilter, bool) {
+	l, ok := logger.(zeroLogWrapper)
+	if !ok || l.levelFilter == nil {
+		return nil, false
+	}
+	return l.levelFilter, true
 }
 
 // Info takes a message and a set of key/value pairs and logs with level INFO.
 // The key of the tuple must be a string.
 func (l zeroLogWrapper) Info(msg string, keyVals ...interface{}) {
-	l.Logger.Info().Fields(keyVals).Msg(msg)
+	if l.filtered(zerolog.InfoLevel) {
+		return
+	}
+	l.Logger.Info().Fields(l.redactor.redact(keyVals)).Msg(msg)
 }
 
 // Warn takes a message and a set of key/value pairs and logs with level WARN.
 // The key of the tuple must be a string.
 func (l zeroLogWrapper) Warn(msg string, keyVals ...interface{}) {
-	l.Logger.Warn().Fields(keyVals).Msg(msg)
+	if l.filtered(zerolog.WarnLevel) {
+		return
+	}
+	l.Logger.Warn().Fields(l.redactor.redact(keyVals)).Msg(msg)
 }
 
 // Error takes a message and a set of key/value pairs and logs with level ERROR.
 // The key of the tuple must be a string.
 func (l zeroLogWrapper) Error(msg string, keyVals ...interface{}) {
-	l.Logger.Error().Fields(keyVals).Msg(msg)
+	if l.filtered(zerolog.ErrorLevel) {
+		return
+	}
+	l.Logger.Error().Fields(l.redactor.redact(keyVals)).Msg(msg)
 }
 
 // Debug takes a message and a set of key/value pairs and logs with level DEBUG.
 // The key of the tuple must be a string.
 func (l zeroLogWrapper) Debug(msg string, keyVals ...interface{}) {
-	l.Logger.Debug().Fields(keyVals).Msg(msg)
+	if l.filtered(zerolog.DebugLevel) {
+		return
+	}
+	l.Logger.Debug().Fields(l.redactor.redact(keyVals)).Msg(msg)
 }
 
 // With returns a new wrapped logger with additional context provided by a set.
 func (l zeroLogWrapper) With(keyVals ...interface{}) Logger {
-	logger := l.Logger.With().Fields(keyVals).Logger()
-	return zeroLogWrapper{&logger}
+	logger := l.Logger.With().Fields(l.redactor.redact(keyVals)).Logger()
+	for i := 0; i+1 < len(keyVals); i += 2 {
+		if key, ok := keyVals[i].(string); ok && key == ModuleKey {
+			l.module = fmt.Sprint(keyVals[i+1])
+		}
+	}
+	l.Logger = &logger
+	return l
+}
+
+// filtered returns true if the entries of the given level are filtered out by the
+// level filter of the logger.
+func (l zeroLogWrapper) filtered(level zerolog.Level) bool {
+	return l.levelFilter != nil && l.levelFilter.filtered(l.module, level)
 }
 
 // Impl returns the underlying zerolog logger.
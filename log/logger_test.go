@@ -54,3 +54,19 @@ func TestLoggerOptionHooks(t *testing.T) {
 	logger.Info("hello world")
 	assert.Assert(t, strings.Contains(buf.String(), "hello world"))
 }
+
+func TestLoggerOptionRedact(t *testing.T) {
+	buf := new(bytes.Buffer)
+	logger := log.NewLogger(buf, log.RedactOption("*mnemonic*", "peer_ip", "[bad"), log.ColorOption(false))
+
+	keyVals := []interface{}{"Mnemonic", "abandon abandon", "peer_ip", "10.0.0.1", "[bad", "value", "height", 10}
+	logger.With("user_mnemonic", "ability able").Info("debug dump", keyVals...)
+	out := buf.String()
+	for _, secret := range []string{"abandon", "ability", "10.0.0.1", "=value"} {
+		assert.Assert(t, !strings.Contains(out, secret), out)
+	}
+	assert.Equal(t, 4, strings.Count(out, log.RedactedValue))
+	assert.Assert(t, strings.Contains(out, "height=10"))
+	// the values of the caller are left untouched
+	assert.Equal(t, "abandon abandon", keyVals[1])
+}
@@ -19,13 +19,15 @@ var defaultConfig = Config{
 
 // Config defines configuration for the logger.
 type Config struct {
-	Level      zerolog.Level
-	Filter     FilterFunc
-	OutputJSON bool
-	Color      bool
-	StackTrace bool
-	TimeFormat string
-	Hooks      []zerolog.Hook
+	Level       zerolog.Level
+	Filter      FilterFunc
+	LevelFilter *LevelFilter
+	OutputJSON  bool
+	Color       bool
+	StackTrace  bool
+	TimeFormat  string
+	Hooks       []zerolog.Hook
+	Redact      []string
 }
 
 type Option func(*Config)
@@ -45,6 +47,15 @@ func LevelOption(level zerolog.Level) Option {
 	}
 }
 
+// LevelFilterOption sets the per-module levels of the Logger, which can be changed at
+// runtime through the filter. The entries filtered out are discarded before being
+// formatted. The filter of a Logger is returned by GetLevelFilter.
+func LevelFilterOption(filter *LevelFilter) Option {
+	return func(cfg *Config) {
+		cfg.LevelFilter = filter
+	}
+}
+
 // OutputJSONOption sets the output of the logger to JSON.
 // By default, the logger outputs to a human-readable format.
 func OutputJSONOption() Option {
@@ -96,3 +107,20 @@ func HooksOption(hooks ...zerolog.Hook) Option {
 		cfg.Hooks = append(cfg.Hooks, hooks...)
 	}
 }
+
+// SamplingOption rate limits the noisy messages of the Logger: at most burst entries
+// with the same message are written per period, see MessageSampler.
+func SamplingOption(burst uint32, period time.Duration) Option {
+	return func(cfg *Config) {
+		cfg.Hooks = append(cfg.Hooks, NewMessageSampler(burst, period))
+	}
+}
+
+// RedactOption redacts the values of the fields whose key matches one of the given
+// case-insensitive shell patterns, such as "*mnemonic*", replacing them with
+// RedactedValue. The patterns are matched with path.Match.
+func RedactOption(patterns ...string) Option {
+	return func(cfg *Config) {
+		cfg.Redact = append(cfg.Redact, patterns...)
+	}
+}
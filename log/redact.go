@@ -0,0 +1,63 @@
+package log
+
+import (
+	"path"
+	"strings"
+)
+
+// RedactedValue replaces the values of the redacted fields.
+const RedactedValue = "[REDACTED]"
+
+// redactor replaces the values of the fields whose key matches one of its patterns.
+type redactor struct {
+	// patterns are lower case shell patterns, as supported by path.Match.
+	patterns []string
+}
+
+func newRedactor(patterns []string) *redactor {
+	r := &redactor{}
+	for _, p := range patterns {
+		if p = strings.ToLower(strings.TrimSpace(p)); p != "" {
+			r.patterns = append(r.patterns, p)
+		}
+	}
+	if len(r.patterns) == 0 {
+		return nil
+	}
+	return r
+}
+
+// redact returns the key/value pairs with the values of the matching keys replaced
+// by RedactedValue. The given slice is not modified.
+func (r *redactor) redact(keyVals []interface{}) []interface{} {
+	if r == nil {
+		return keyVals
+	}
+
+	var redacted []interface{}
+	for i := 0; i+1 < len(keyVals); i += 2 {
+		key, ok := keyVals[i].(string)
+		if !ok || !r.matches(key) {
+			continue
+		}
+		if redacted == nil {
+			redacted = append([]interface{}(nil), keyVals...)
+		}
+		redacted[i+1] = RedactedValue
+	}
+	if redacted == nil {
+		return keyVals
+	}
+	return redacted
+}
+
+func (r *redactor) matches(key string) bool {
+	key = strings.ToLower(key)
+	for _, p := range r.patterns {
+		// a malformed pattern matches the key literally
+		if ok, err := path.Match(p, key); ok || (err != nil && p == key) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,93 @@
+package log
+
+import (
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// SampledOutKey is the key of the number of entries of a message discarded by the
+// sampling since the previous entry written.
+const SampledOutKey = "sampled_out"
+
+// maxSampledMessages bounds the number of messages tracked by a MessageSampler, the
+// messages whose period is over are evicted beyond it.
+const maxSampledMessages = 1024
+
+// MessageSampler is a zerolog hook rate limiting noisy messages: at most burst
+// entries with the same message are written per period, the others are discarded.
+// The first entry of a message written after some were discarded reports their
+// number in the SampledOutKey field. Errors and entries of higher levels are never
+// discarded.
+type MessageSampler struct {
+	burst  uint32
+	period time.Duration
+	now    func() time.Time
+
+	mtx      sync.Mutex
+	messages map[string]*sampledMessage
+}
+
+// sampledMessage is the sampling state of a message.
+type sampledMessage struct {
+	periodEnd  time.Time
+	count      uint32
+	sampledOut uint64
+}
+
+// NewMessageSampler returns a MessageSampler writing at most burst entries of each
+// message per period.
+func NewMessageSampler(burst uint32, period time.Duration) *MessageSampler {
+	return &MessageSampler{
+		burst:    burst,
+		period:   period,
+		now:      time.Now,
+		messages: make(map[string]*sampledMessage),
+	}
+}
+
+// Run implements zerolog.Hook.
+func (s *MessageSampler) Run(e *zerolog.Event, level zerolog.Level, msg string) {
+	if level >= zerolog.ErrorLevel {
+		return
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	now := s.now()
+	m, ok := s.messages[msg]
+	if !ok {
+		if len(s.messages) >= maxSampledMessages {
+			s.evict(now)
+		}
+		m = &sampledMessage{}
+		s.messages[msg] = m
+	}
+	if !now.Before(m.periodEnd) {
+		m.periodEnd = now.Add(s.period)
+		m.count = 0
+	}
+
+	m.count++
+	if m.count > s.burst {
+		m.sampledOut++
+		e.Discard()
+		return
+	}
+	if m.sampledOut > 0 {
+		e.Uint64(SampledOutKey, m.sampledOut)
+		m.sampledOut = 0
+	}
+}
+
+// evict removes the messages whose period is over and which have no discarded
+// entry to report.
+func (s *MessageSampler) evict(now time.Time) {
+	for msg, m := range s.messages {
+		if !now.Before(m.periodEnd) && m.sampledOut == 0 {
+			delete(s.messages, msg)
+		}
+	}
+}
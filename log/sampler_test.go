@@ -0,0 +1,36 @@
+package log_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+
+	"cosmossdk.io/log"
+)
+
+func TestLoggerOptionSampling(t *testing.T) {
+	buf := new(bytes.Buffer)
+	logger := log.NewLogger(buf, log.SamplingOption(2, 50*time.Millisecond), log.ColorOption(false), log.OutputJSONOption())
+
+	for i := 0; i < 5; i++ {
+		logger.Info("noisy")
+		logger.Error("failure")
+	}
+	logger.Info("quiet")
+	assert.Equal(t, 2, strings.Count(buf.String(), `"message":"noisy"`))
+	// errors are never sampled
+	assert.Equal(t, 5, strings.Count(buf.String(), `"message":"failure"`))
+	assert.Equal(t, 1, strings.Count(buf.String(), `"message":"quiet"`))
+
+	// the next period reports the entries discarded
+	time.Sleep(60 * time.Millisecond)
+	buf.Reset()
+	logger.Info("noisy")
+	assert.Assert(t, strings.Contains(buf.String(), `"sampled_out":3`), buf.String())
+	buf.Reset()
+	logger.Info("noisy")
+	assert.Assert(t, !strings.Contains(buf.String(), log.SampledOutKey), buf.String())
+}
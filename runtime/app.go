@@ -56,6 +56,9 @@ type App struct {
 	// initChainer is the init chainer function defined by the app config.
 	// this is only required if the chain wants to add special InitChainer logic.
 	initChainer sdk.InitChainer
+	// genesisDir is the directory of the genesis file, the directory of a streamed
+	// genesis is relative to it.
+	genesisDir string
 }
 
 // RegisterModules registers the provided modules with the module manager and
@@ -188,6 +191,10 @@ func (a *App) PrepareCheckStater(ctx sdk.Context) {
 
 // InitChainer initializes the chain.
 func (a *App) InitChainer(ctx sdk.Context, req *abci.InitChainRequest) (*abci.InitChainResponse, error) {
+	if streamed, ok := module.ParseStreamedGenesis(req.AppStateBytes); ok {
+		return a.ModuleManager.InitGenesisFromDir(ctx, streamed.Path(a.genesisDir), streamed.Hash)
+	}
+
 	var genesisState map[string]json.RawMessage
 	if err := json.Unmarshal(req.AppStateBytes, &genesisState); err != nil {
 		return nil, err
@@ -3,9 +3,11 @@ package runtime
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"slices"
 
 	"github.com/cosmos/gogoproto/proto"
+	"github.com/spf13/cast"
 	"google.golang.org/protobuf/reflect/protodesc"
 	"google.golang.org/protobuf/reflect/protoregistry"
 
@@ -25,8 +27,10 @@ import (
 	storetypes "cosmossdk.io/store/types"
 
 	"github.com/cosmos/cosmos-sdk/baseapp"
+	"github.com/cosmos/cosmos-sdk/client/flags"
 	"github.com/cosmos/cosmos-sdk/codec"
 	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	servertypes "github.com/cosmos/cosmos-sdk/server/types"
 	"github.com/cosmos/cosmos-sdk/std"
 	"github.com/cosmos/cosmos-sdk/types/module"
 	"github.com/cosmos/cosmos-sdk/types/msgservice"
@@ -162,6 +166,7 @@ type AppInputs struct {
 	BaseAppOptions    []BaseAppOption
 	InterfaceRegistry codectypes.InterfaceRegistry
 	LegacyAmino       legacy.Amino
+	AppOptions        servertypes.AppOptions `optional:"true"`
 }
 
 func SetupAppBuilder(inputs AppInputs) {
@@ -171,6 +176,9 @@ func SetupAppBuilder(inputs AppInputs) {
 	app.appConfig = inputs.AppConfig
 	app.logger = inputs.Logger
 	app.ModuleManager = inputs.ModuleManager
+	if inputs.AppOptions != nil {
+		app.genesisDir = filepath.Join(cast.ToString(inputs.AppOptions.Get(flags.FlagHome)), "config")
+	}
 	app.ModuleManager.RegisterInterfaces(inputs.InterfaceRegistry)
 	app.ModuleManager.RegisterLegacyAminoCodec(inputs.LegacyAmino)
 }
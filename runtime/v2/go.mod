@@ -10,6 +10,7 @@ replace (
 	cosmossdk.io/log => ../../log
 	cosmossdk.io/server/v2/appmanager => ../../server/v2/appmanager
 	cosmossdk.io/server/v2/stf => ../../server/v2/stf
+	cosmossdk.io/store => ../../store
 	cosmossdk.io/store/v2 => ../../store/v2
 	cosmossdk.io/x/accounts => ../../x/accounts
 	cosmossdk.io/x/auth => ../../x/auth
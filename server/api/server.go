@@ -2,6 +2,7 @@ package api
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
 	"net/http"
@@ -39,8 +40,9 @@ type Server struct {
 	// Close() can be called asynchronously and access shared memory
 	// via the listener. Therefore, we sync access to Start and Close with
 	// this mutex to avoid data races.
-	mtx      sync.Mutex
-	listener net.Listener
+	mtx              sync.Mutex
+	listener         net.Listener
+	logAdminListener net.Listener
 }
 
 // CustomGRPCHeaderMatcher for mapping request headers to
@@ -111,6 +113,14 @@ func (s *Server) Start(ctx context.Context, cfg config.Config) error {
 	}
 
 	s.listener = listener
+
+	if cfg.API.LogAdminAddress != "" {
+		if err := s.startLogAdmin(cfg.API.LogAdminAddress); err != nil {
+			_ = listener.Close()
+			s.mtx.Unlock()
+			return err
+		}
+	}
 	s.mtx.Unlock()
 
 	// register grpc-gateway routes
@@ -151,6 +161,9 @@ func (s *Server) Start(ctx context.Context, cfg config.Config) error {
 func (s *Server) Close() error {
 	s.mtx.Lock()
 	defer s.mtx.Unlock()
+	if s.logAdminListener != nil {
+		_ = s.logAdminListener.Close()
+	}
 	return s.listener.Close()
 }
 
@@ -178,6 +191,36 @@ func (s *Server) registerMetrics() {
 	s.Router.HandleFunc("/metrics", metricsHandler).Methods("GET")
 }
 
+// startLogAdmin serves the endpoint of the log levels of the logger, if they can be
+// changed at runtime. The endpoint isn't authenticated, so it is served on its own
+// listener, bound to a loopback address, instead of the API server listener.
+func (s *Server) startLogAdmin(address string) error {
+	levelFilter, ok := log.GetLevelFilter(s.logger)
+	if !ok {
+		s.logger.Error("log levels can't be changed at runtime, not starting the log admin endpoint")
+		return nil
+	}
+
+	listener, err := log.ListenLevelAdmin(address)
+	if err != nil {
+		return err
+	}
+	s.logAdminListener = listener
+
+	router := mux.NewRouter()
+	router.Handle("/log/level", levelFilter).Methods("GET", "PUT", "POST")
+	srv := &http.Server{Handler: router, ReadHeaderTimeout: 10 * time.Second}
+
+	go func() {
+		s.logger.Info("starting log admin endpoint...", "address", address)
+		if err := srv.Serve(listener); err != nil && !errors.Is(err, net.ErrClosed) {
+			s.logger.Error("failed to serve the log admin endpoint", "err", err)
+		}
+	}()
+
+	return nil
+}
+
 // errorResponse defines the attributes of a JSON error response.
 type errorResponse struct {
 	Code  int    `json:"code,omitempty"`
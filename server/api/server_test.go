@@ -0,0 +1,68 @@
+package api_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"cosmossdk.io/log"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/server/api"
+	"github.com/cosmos/cosmos-sdk/server/config"
+)
+
+// freeAddress returns a loopback address with a free port.
+func freeAddress(t *testing.T) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	require.NoError(t, listener.Close())
+	return listener.Addr().String()
+}
+
+func TestLogAdmin(t *testing.T) {
+	filter, err := log.NewLevelFilter("info")
+	require.NoError(t, err)
+	logger := log.NewLogger(io.Discard, log.LevelFilterOption(filter))
+
+	cfg := config.DefaultConfig()
+	apiAddress, logAdminAddress := freeAddress(t), freeAddress(t)
+	cfg.API.Address = "tcp://" + apiAddress
+	cfg.API.LogAdminAddress = logAdminAddress
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	errCh := make(chan error, 1)
+	go func() { errCh <- api.New(client.Context{}, logger, nil).Start(ctx, *cfg) }()
+
+	var res *http.Response
+	require.Eventually(t, func() bool {
+		res, err = http.Post("http://"+logAdminAddress+"/log/level", "application/json", strings.NewReader(`{"module": "consensus", "level": "debug"}`))
+		return err == nil
+	}, 5*time.Second, 50*time.Millisecond)
+	require.NoError(t, res.Body.Close())
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.Equal(t, "consensus:debug,*:info", filter.String())
+
+	// the endpoint isn't served by the API server
+	res, err = http.Get("http://" + apiAddress + "/log/level")
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+	require.NotEqual(t, http.StatusOK, res.StatusCode)
+
+	cancel()
+	require.NoError(t, <-errCh)
+
+	// non loopback addresses are rejected
+	cfg.API.Address = "tcp://" + freeAddress(t)
+	cfg.API.LogAdminAddress = "0.0.0.0:0"
+	err = api.New(client.Context{}, logger, nil).Start(context.Background(), *cfg)
+	require.ErrorContains(t, err, "is not a loopback address")
+}
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"context"
+	"time"
 
 	cmtcli "github.com/cometbft/cometbft/libs/cli"
 	"github.com/rs/zerolog"
@@ -29,6 +30,9 @@ func Execute(rootCmd *cobra.Command, envPrefix, defaultHome string) error {
 	// NOTE: The default logger is only checking for the "json" value, any other value will default to plain text.
 	rootCmd.PersistentFlags().String(flags.FlagLogFormat, "plain", "The logging format (json|plain)")
 	rootCmd.PersistentFlags().Bool(flags.FlagLogNoColor, false, "Disable colored logs")
+	rootCmd.PersistentFlags().StringSlice(flags.FlagLogRedact, []string{"*mnemonic*", "*password*", "*priv_key*", "*private_key*"}, "The case-insensitive shell patterns of the log keys whose values are redacted (e.g. '*mnemonic*,*_ip')")
+	rootCmd.PersistentFlags().Uint32(flags.FlagLogSampleBurst, 0, "The maximum number of log entries with the same message written per sampling period, 0 disables the sampling (errors are never sampled)")
+	rootCmd.PersistentFlags().Duration(flags.FlagLogSamplePeriod, time.Second, "The log sampling period")
 
 	executor := cmtcli.PrepareBaseCmd(rootCmd, envPrefix, defaultHome)
 	return executor.ExecuteContext(ctx)
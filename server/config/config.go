@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"math"
+	"time"
 
 	"github.com/spf13/viper"
 
@@ -119,6 +120,12 @@ type APIConfig struct {
 	// RPCMaxBodyBytes defines the CometBFT maximum request body (in bytes)
 	RPCMaxBodyBytes uint `mapstructure:"rpc-max-body-bytes"`
 
+	// LogAdminAddress defines the address of the /log/level endpoint, reading and
+	// changing the per-module log levels at runtime. The endpoint isn't
+	// authenticated, so it is served on its own listener, which must be bound to a
+	// loopback address. It is disabled if empty.
+	LogAdminAddress string `mapstructure:"log-admin-address"`
+
 	// TODO: TLS/Proxy configuration.
 	//
 	// Ref: https://github.com/cosmos/cosmos-sdk/issues/6420
@@ -162,11 +169,35 @@ type MempoolConfig struct {
 	MaxTxs int `mapstructure:"max-txs"`
 }
 
+// TxDecodeConfig defines the policy enforced when decoding transactions, before
+// the ante handlers run. Zero values impose no limits.
+type TxDecodeConfig struct {
+	// MaxMsgs is the maximum number of messages of a transaction.
+	MaxMsgs int `mapstructure:"max-msgs"`
+
+	// MaxMsgSize is the maximum size in bytes of an encoded message.
+	MaxMsgSize int `mapstructure:"max-msg-size"`
+
+	// MaxAnyDepth is the maximum nesting depth of Anys, the messages of a
+	// transaction being at depth 1.
+	MaxAnyDepth int `mapstructure:"max-any-depth"`
+
+	// AllowedTypeURLs, if not empty, are the only type URLs accepted for the
+	// messages of a transaction and the Anys nested in them.
+	AllowedTypeURLs []string `mapstructure:"allowed-type-urls"`
+
+	// DeniedTypeURLs are the type URLs rejected for the messages of a
+	// transaction and the Anys nested in them.
+	DeniedTypeURLs []string `mapstructure:"denied-type-urls"`
+}
+
 // State Streaming configuration
 type (
 	// StreamingConfig defines application configuration for external streaming services
 	StreamingConfig struct {
-		ABCI ABCIListenerConfig `mapstructure:"abci"`
+		ABCI    ABCIListenerConfig `mapstructure:"abci"`
+		Indexer IndexerConfig      `mapstructure:"indexer"`
+		Queue   QueueConfig        `mapstructure:"queue"`
 	}
 	// ABCIListenerConfig defines application configuration for ABCIListener streaming service
 	ABCIListenerConfig struct {
@@ -174,6 +205,23 @@ type (
 		Plugin        string   `mapstructure:"plugin"`
 		StopNodeOnErr bool     `mapstructure:"stop-node-on-err"`
 	}
+	// IndexerConfig defines application configuration for the SQL indexer streaming service
+	IndexerConfig struct {
+		Driver      string   `mapstructure:"driver"`
+		DSN         string   `mapstructure:"dsn"`
+		Keys        []string `mapstructure:"keys"`
+		StartHeight int64    `mapstructure:"start-height"`
+	}
+	// QueueConfig defines application configuration for the durable queue between the app
+	// and the streaming listeners
+	QueueConfig struct {
+		Enable        bool          `mapstructure:"enable"`
+		Retention     uint64        `mapstructure:"retention"`
+		MaxLag        uint64        `mapstructure:"max-lag"`
+		RetryInterval time.Duration `mapstructure:"retry-interval"`
+		ReplayHeight  int64         `mapstructure:"replay-height"`
+		DBBackend     string        `mapstructure:"db-backend"`
+	}
 )
 
 // Config defines the server's top level configuration
@@ -187,6 +235,7 @@ type Config struct {
 	StateSync StateSyncConfig  `mapstructure:"state-sync"`
 	Streaming StreamingConfig  `mapstructure:"streaming"`
 	Mempool   MempoolConfig    `mapstructure:"mempool"`
+	TxDecode  TxDecodeConfig   `mapstructure:"tx-decode"`
 }
 
 // SetMinGasPrices sets the validator's minimum gas prices.
@@ -227,6 +276,12 @@ func DefaultConfig() *Config {
 		Telemetry: telemetry.Config{
 			Enabled:      false,
 			GlobalLabels: [][]string{},
+			Tracing: telemetry.TracingConfig{
+				Enabled:    false,
+				Endpoint:   "localhost:4318",
+				Insecure:   true,
+				SampleRate: 1,
+			},
 		},
 		API: APIConfig{
 			Enable:             false,
@@ -251,10 +306,21 @@ func DefaultConfig() *Config {
 				Keys:          []string{},
 				StopNodeOnErr: true,
 			},
+			Indexer: IndexerConfig{
+				Keys: []string{},
+			},
+			Queue: QueueConfig{
+				Retention:     100,
+				RetryInterval: time.Second,
+			},
 		},
 		Mempool: MempoolConfig{
 			MaxTxs: -1,
 		},
+		TxDecode: TxDecodeConfig{
+			AllowedTypeURLs: []string{},
+			DeniedTypeURLs:  []string{},
+		},
 	}
 }
 
@@ -277,6 +343,12 @@ func (c Config) ValidateBasic() error {
 			"cannot enable state sync snapshots with '%s' pruning setting", pruningtypes.PruningOptionEverything,
 		)
 	}
+	if c.TxDecode.MaxMsgs < 0 || c.TxDecode.MaxMsgSize < 0 || c.TxDecode.MaxAnyDepth < 0 {
+		return sdkerrors.ErrAppConfig.Wrap("tx-decode limits cannot be negative")
+	}
+	if c.Telemetry.Tracing.SampleRate < 0 || c.Telemetry.Tracing.SampleRate > 1 {
+		return sdkerrors.ErrAppConfig.Wrap("tracing sample-rate must be between 0 and 1")
+	}
 
 	return nil
 }
@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
@@ -52,6 +53,19 @@ func TestStreamingConfig(t *testing.T) {
 				Plugin:        "plugin-A",
 				StopNodeOnErr: false,
 			},
+			Indexer: IndexerConfig{
+				Driver:      "sqlite3",
+				DSN:         "file:indexer.db",
+				Keys:        []string{"bank"},
+				StartHeight: 10,
+			},
+			Queue: QueueConfig{
+				Enable:        true,
+				Retention:     50,
+				MaxLag:        20,
+				RetryInterval: 5 * time.Second,
+				ReplayHeight:  7,
+			},
 		},
 	}
 
@@ -69,6 +83,15 @@ func TestStreamingConfig(t *testing.T) {
 		`keys = ["one", "two", ]`,
 		`plugin = "plugin-A"`,
 		`stop-node-on-err = false`,
+		`driver = "sqlite3"`,
+		`dsn = "file:indexer.db"`,
+		`keys = ["bank", ]`,
+		`start-height = 10`,
+		`enable = true`,
+		`retention = 50`,
+		`max-lag = 20`,
+		`retry-interval = "5s"`,
+		`replay-height = 7`,
 	}
 
 	for _, line := range expectedLines {
@@ -238,3 +261,31 @@ func TestAppConfig(t *testing.T) {
 	require.NoError(t, v.Unmarshal(appCfg))
 	require.EqualValues(t, appCfg, defAppConfig)
 }
+
+func TestTxDecodeWriteRead(t *testing.T) {
+	expected := TxDecodeConfig{
+		MaxMsgs:         10,
+		MaxMsgSize:      4096,
+		MaxAnyDepth:     3,
+		AllowedTypeURLs: []string{"/cosmos.bank.v1beta1.MsgSend", "/cosmos.authz.v1beta1.MsgExec"},
+		DeniedTypeURLs:  []string{},
+	}
+
+	confFile := filepath.Join(t.TempDir(), "app.toml")
+	conf := DefaultConfig()
+	conf.TxDecode = expected
+	require.NoError(t, WriteConfigFile(confFile, conf))
+
+	vpr := viper.New()
+	vpr.SetConfigFile(confFile)
+	require.NoError(t, vpr.ReadInConfig(), "reading config file into viper")
+
+	cfg, err := ParseConfig(vpr)
+	require.NoError(t, err, "parsing config")
+	require.Equal(t, expected, cfg.TxDecode)
+
+	cfg.MinGasPrices = "0stake"
+	require.NoError(t, cfg.ValidateBasic())
+	cfg.TxDecode.MaxMsgs = -1
+	require.ErrorContains(t, cfg.ValidateBasic(), "tx-decode limits cannot be negative")
+}
@@ -37,6 +37,7 @@ import (
 	"google.golang.org/grpc/credentials/insecure"
 
 	"cosmossdk.io/log"
+	"cosmossdk.io/store/gaskv"
 	pruningtypes "cosmossdk.io/store/pruning/types"
 
 	"github.com/cosmos/cosmos-sdk/client"
@@ -103,6 +104,14 @@ const (
 
 	FlagMempoolMaxTxs = "mempool.max-txs"
 
+	// tx decode policy flags
+
+	FlagTxDecodeMaxMsgs         = "tx-decode.max-msgs"
+	FlagTxDecodeMaxMsgSize      = "tx-decode.max-msg-size"
+	FlagTxDecodeMaxAnyDepth     = "tx-decode.max-any-depth"
+	FlagTxDecodeAllowedTypeURLs = "tx-decode.allowed-type-urls"
+	FlagTxDecodeDeniedTypeURLs  = "tx-decode.denied-type-urls"
+
 	// testnet keys
 
 	KeyIsTestnet             = "is-testnet"
@@ -230,6 +239,16 @@ func start[T types.Application](svrCtx *Context, clientCtx client.Context, appCr
 		return err
 	}
 
+	shutdownTracing, err := telemetry.StartTracing(context.Background(), svrCfg.Telemetry)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			svrCtx.Logger.Error("failed to shutdown tracing", "err", err)
+		}
+	}()
+
 	emitServerInfoMetrics()
 
 	if !withCmt {
@@ -560,7 +579,14 @@ func startAPIServer(
 }
 
 func startTelemetry(cfg serverconfig.Config) (*telemetry.Metrics, error) {
-	return telemetry.New(cfg.Telemetry)
+	metrics, err := telemetry.New(cfg.Telemetry)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Telemetry.Enabled {
+		gaskv.EnableMetrics(cfg.Telemetry.GlobalLabels)
+	}
+	return metrics, nil
 }
 
 // wrapCPUProfile starts CPU profiling, if enabled, and executes the provided
@@ -642,6 +668,9 @@ func startApp[T types.Application](svrCtx *Context, appCreator types.AppCreator[
 		app = *appPtr
 	} else {
 		app = appCreator(svrCtx.Logger, db, traceWriter, svrCtx.Viper)
+		if err = applyTestnetFork(svrCtx, app); err != nil {
+			return app, traceCleanupFn, err
+		}
 	}
 
 	cleanupFn = func() {
@@ -0,0 +1,525 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+	cmtproto "github.com/cometbft/cometbft/api/cometbft/types/v1"
+	cmtcfg "github.com/cometbft/cometbft/config"
+	cmtcrypto "github.com/cometbft/cometbft/crypto"
+	cmted25519 "github.com/cometbft/cometbft/crypto/ed25519"
+	"github.com/cometbft/cometbft/p2p"
+	pvm "github.com/cometbft/cometbft/privval"
+	sm "github.com/cometbft/cometbft/state"
+	"github.com/cometbft/cometbft/store"
+	cmttypes "github.com/cometbft/cometbft/types"
+	cmttime "github.com/cometbft/cometbft/types/time"
+	"github.com/spf13/cobra"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/client/flags"
+	"github.com/cosmos/cosmos-sdk/crypto/hd"
+	"github.com/cosmos/cosmos-sdk/crypto/keyring"
+	"github.com/cosmos/cosmos-sdk/server/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/cosmos/cosmos-sdk/version"
+	genutiltypes "github.com/cosmos/cosmos-sdk/x/genutil/types"
+)
+
+const (
+	flagForkValidators     = "validators"
+	flagForkValidatorPower = "validator-power"
+	flagForkOutputDir      = "output-dir"
+
+	// testnetForkFile is the file of the data directory recording the testnet fork
+	// applied by the application when it starts at the forked height.
+	testnetForkFile = "testnet_fork.json"
+)
+
+// testnetForkValidator is a validator of a testnet fork, with its keys and home.
+type testnetForkValidator struct {
+	types.TestnetValidator
+
+	home    string
+	privKey cmtcrypto.PrivKey
+	nodeID  string
+}
+
+// TestnetForkCmd returns a command forking the state of the node into a testnet whose
+// validators use locally generated keys. This is useful to create private replicas of
+// a mainnet, without exporting and importing its state.
+//
+// The fork is applied by the start command of this package, when the application starts
+// at the forked height. Only the applications of this package, whose state is stored in a
+// store v1 multistore (rootmulti), are supported: the nodes of server/v2 applications,
+// using store/v2, can't be forked in place.
+func TestnetForkCmd[T types.Application](appCreator types.AppCreator[T]) *cobra.Command {
+	return newTestnetForkCmd(func(cmd *cobra.Command) (*cmtcfg.Config, int64, []byte, error) {
+		serverCtx := GetServerContextFromCmd(cmd)
+
+		db, err := OpenDB(serverCtx.Config.RootDir, GetAppDBBackend(serverCtx.Viper))
+		if err != nil {
+			return nil, 0, nil, err
+		}
+		app := appCreator(serverCtx.Logger, db, nil, serverCtx.Viper)
+		info, err := app.Info(&abci.InfoRequest{})
+		if closeErr := app.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return nil, 0, nil, fmt.Errorf("failed to get the application info: %w", err)
+		}
+
+		return serverCtx.Config, info.LastBlockHeight, info.LastBlockAppHash, nil
+	})
+}
+
+// newTestnetForkCmd returns the fork-testnet command of a node whose last committed
+// application state is read by lastBlock, returning the configuration of the node and
+// the height and hash of the state.
+func newTestnetForkCmd(lastBlock func(cmd *cobra.Command) (*cmtcfg.Config, int64, []byte, error)) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "fork-testnet [new-chain-id]",
+		Short: "Fork the state of the node into a testnet run by locally generated validators",
+		Long: `Fork the state of the node into a testnet run by locally generated validators, without exporting
+and importing it. The CometBFT state of the node is modified in place: its chain-id is replaced, and
+its validator set is replaced by validators with locally generated keys, all with the same power.
+The first validator is run by the node, whose validator key is replaced, the previous one being
+kept in a .bak file. The other validators get their own home directory in the output directory,
+with a copy of the configuration and data of the node. The key of the operator of each validator
+is generated in the keyring of its home.
+
+The application state is forked when the nodes start: the current validators are jailed and
+tombstoned, and the new validators are created and bonded, updating the staking, slashing and
+distribution states consistently. The fork is committed with the first block of the testnet.
+
+The nodes are then started with the "start" command. When there are several validators, configure
+the addresses and peers of their nodes first: more than two thirds of the voting power must be
+online for the testnet to produce blocks.
+
+Only applications using the store v1 multistore (rootmulti) and implementing the testnet forker of
+the server, such as SimApp, are supported. The nodes of server/v2 applications, using store/v2,
+can't be forked in place: export their state and edit it with "genesis edit" instead.`,
+		Example: fmt.Sprintf("%s fork-testnet local-testnet --validators 4 --output-dir ./testnet", version.AppName),
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			clientCtx := client.GetClientContextFromCmd(cmd)
+
+			numValidators, err := cmd.Flags().GetInt(flagForkValidators)
+			if err != nil {
+				return err
+			}
+			if numValidators < 1 {
+				return fmt.Errorf("the testnet needs at least one validator, got %d", numValidators)
+			}
+			power, err := cmd.Flags().GetInt64(flagForkValidatorPower)
+			if err != nil {
+				return err
+			}
+			if power <= 0 {
+				return fmt.Errorf("the power of the validators must be positive, got %d", power)
+			}
+			keyringBackend, _ := cmd.Flags().GetString(flags.FlagKeyringBackend)
+
+			config, appHeight, appHash, err := lastBlock(cmd)
+			if err != nil {
+				return err
+			}
+			outputDir, _ := cmd.Flags().GetString(flagForkOutputDir)
+			if outputDir == "" {
+				outputDir = filepath.Join(config.RootDir, "testnet-fork")
+			}
+
+			skipConfirmation, _ := cmd.Flags().GetBool("skip-confirmation")
+			if !skipConfirmation {
+				// Confirmation prompt to prevent accidental modification of state.
+				reader := bufio.NewReader(os.Stdin)
+				fmt.Println("This operation will modify state in your data folder and cannot be undone. Do you want to continue? (y/n)")
+				text, _ := reader.ReadString('\n')
+				response := strings.TrimSpace(strings.ToLower(text))
+				if response != "y" && response != "yes" {
+					fmt.Println("Operation canceled.")
+					return nil
+				}
+			}
+
+			validators := make([]testnetForkValidator, numValidators)
+			for i := range validators {
+				val := &validators[i]
+				val.Moniker = fmt.Sprintf("validator%d", i)
+				val.Power = power
+				val.home = config.RootDir
+				if i > 0 {
+					val.home = filepath.Join(outputDir, val.Moniker)
+				}
+				val.privKey = cmted25519.GenPrivKey()
+				val.ConsPubKey = val.privKey.PubKey().Bytes()
+
+				operator, err := testnetOperatorKey(clientCtx, val.home, keyringBackend, val.Moniker)
+				if err != nil {
+					return fmt.Errorf("failed to create the operator key of %s: %w", val.Moniker, err)
+				}
+				if val.OperatorAddress, err = clientCtx.AddressCodec.BytesToString(operator); err != nil {
+					return err
+				}
+			}
+
+			fork, err := forkCometState(config, appHeight, appHash, args[0], validators)
+			if err != nil {
+				return err
+			}
+			if err := writeTestnetFork(config.DBDir(), fork); err != nil {
+				return err
+			}
+
+			// the key of the node may be the one of a validator of the forked chain
+			if _, err := os.Stat(config.PrivValidatorKeyFile()); err == nil {
+				if err := os.Rename(config.PrivValidatorKeyFile(), config.PrivValidatorKeyFile()+".bak"); err != nil {
+					return err
+				}
+			}
+			pvm.NewFilePV(validators[0].privKey, config.PrivValidatorKeyFile(), config.PrivValidatorStateFile()).Save()
+			nodeKey, err := p2p.LoadOrGenNodeKey(config.NodeKeyFile())
+			if err != nil {
+				return err
+			}
+			validators[0].nodeID = string(nodeKey.ID())
+
+			for i := range validators[1:] {
+				if err := initTestnetForkHome(config, &validators[i+1]); err != nil {
+					return fmt.Errorf("failed to set up the home of %s: %w", validators[i+1].Moniker, err)
+				}
+			}
+
+			cmd.Printf("Forked the state at height %d into testnet %s\n", fork.Height, fork.ChainID)
+			for _, val := range validators {
+				cmd.Printf("%s: operator %s, node %s, home %s\n", val.Moniker, val.OperatorAddress, val.nodeID, val.home)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().Int(flagForkValidators, 1, "Number of validators of the testnet")
+	cmd.Flags().Int64(flagForkValidatorPower, 100, "Consensus power of each validator")
+	cmd.Flags().String(flagForkOutputDir, "", "Directory of the homes of the validators other than the one of the node (default: <home>/testnet-fork)")
+	cmd.Flags().String(flags.FlagKeyringBackend, keyring.BackendTest, "Select keyring's backend (os|file|test)")
+	cmd.Flags().Bool("skip-confirmation", false, "Skip the confirmation prompt")
+
+	return cmd
+}
+
+// testnetOperatorKey returns the address of the operator key of a validator, which is
+// generated in the keyring of its home if it does not exist yet. The mnemonic of a new
+// key is saved in the key_seed.json file of the home.
+func testnetOperatorKey(clientCtx client.Context, home, keyringBackend, name string) (sdk.AccAddress, error) {
+	kb, err := keyring.New(sdk.KeyringServiceName(), keyringBackend, home, os.Stdin, clientCtx.Codec)
+	if err != nil {
+		return nil, err
+	}
+
+	record, err := kb.Key(name)
+	if errors.Is(err, sdkerrors.ErrKeyNotFound) {
+		var mnemonic string
+		record, mnemonic, err = kb.NewMnemonic(name, keyring.English, sdk.GetFullBIP44Path(), keyring.DefaultBIP39Passphrase, hd.Secp256k1)
+		if err != nil {
+			return nil, err
+		}
+
+		bz, err := json.Marshal(map[string]string{"secret": mnemonic})
+		if err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(filepath.Join(home, "key_seed.json"), bz, 0o600); err != nil {
+			return nil, err
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	return record.GetAddress()
+}
+
+// forkCometState replaces the chain-id and the validator set of the CometBFT state of
+// the node, whose application state is at the given height and hash, and returns the
+// corresponding testnet fork of the application state. The last commit is signed again
+// by the new validators, so that they can build on it.
+func forkCometState(
+	config *cmtcfg.Config,
+	appHeight int64,
+	appHash []byte,
+	chainID string,
+	validators []testnetForkValidator,
+) (types.TestnetFork, error) {
+	blockStoreDB, err := cmtcfg.DefaultDBProvider(&cmtcfg.DBContext{ID: "blockstore", Config: config})
+	if err != nil {
+		return types.TestnetFork{}, err
+	}
+	blockStore := store.NewBlockStore(blockStoreDB, store.WithDBKeyLayout(config.Storage.ExperimentalKeyLayout))
+	defer blockStore.Close()
+
+	stateDB, err := cmtcfg.DefaultDBProvider(&cmtcfg.DBContext{ID: "state", Config: config})
+	if err != nil {
+		return types.TestnetFork{}, err
+	}
+	defer stateDB.Close()
+	stateStore := sm.NewStore(stateDB, sm.StoreOptions{
+		DiscardABCIResponses: config.Storage.DiscardABCIResponses,
+		DBKeyLayout:          config.Storage.ExperimentalKeyLayout,
+	})
+
+	state, err := stateStore.Load()
+	if err != nil {
+		return types.TestnetFork{}, err
+	}
+	if state.IsEmpty() {
+		return types.TestnetFork{}, errors.New("the node has no state to fork")
+	}
+
+	// a block saved but not executed when the node was stopped is discarded
+	if blockStore.Height() == state.LastBlockHeight+1 {
+		if err := blockStore.DeleteLatestBlock(); err != nil {
+			return types.TestnetFork{}, err
+		}
+	}
+	height := state.LastBlockHeight
+	if appHeight != height || blockStore.Height() != height {
+		return types.TestnetFork{}, fmt.Errorf(
+			"the heights of the application (%d), of the CometBFT state (%d) and of the block store (%d) differ: start the node until they are in sync",
+			appHeight, height, blockStore.Height(),
+		)
+	}
+	if !bytes.Equal(appHash, state.AppHash) {
+		return types.TestnetFork{}, fmt.Errorf("the application hash %X differs from the one of the CometBFT state %X", appHash, state.AppHash)
+	}
+
+	fork := types.TestnetFork{
+		ChainID: chainID,
+		Height:  height,
+		Time:    state.LastBlockTime,
+	}
+	cmtValidators := make([]*cmttypes.Validator, len(validators))
+	privKeys := make(map[string]cmtcrypto.PrivKey, len(validators))
+	for i, val := range validators {
+		fork.Validators = append(fork.Validators, val.TestnetValidator)
+		cmtValidators[i] = cmttypes.NewValidator(val.privKey.PubKey(), val.Power)
+		privKeys[string(cmtValidators[i].Address)] = val.privKey
+	}
+	valSet := cmttypes.NewValidatorSet(cmtValidators)
+
+	// sign the last block with the new validators
+	extensionsEnabled := state.ConsensusParams.Feature.VoteExtensionsEnabled(height)
+	commit := &cmttypes.ExtendedCommit{Height: height, BlockID: state.LastBlockID}
+	timestamp := cmttime.Now()
+	for i, val := range valSet.Validators {
+		vote := (&cmttypes.Vote{
+			Type:             cmtproto.PrecommitType,
+			Height:           height,
+			BlockID:          state.LastBlockID,
+			Timestamp:        timestamp,
+			ValidatorAddress: val.Address,
+			ValidatorIndex:   int32(i),
+		}).ToProto()
+
+		privKey := privKeys[string(val.Address)]
+		sig, err := privKey.Sign(cmttypes.VoteSignBytes(chainID, vote))
+		if err != nil {
+			return types.TestnetFork{}, err
+		}
+		commitSig := cmttypes.ExtendedCommitSig{CommitSig: cmttypes.CommitSig{
+			BlockIDFlag:      cmttypes.BlockIDFlagCommit,
+			ValidatorAddress: val.Address,
+			Timestamp:        timestamp,
+			Signature:        sig,
+		}}
+		if extensionsEnabled {
+			if commitSig.ExtensionSignature, err = privKey.Sign(cmttypes.VoteExtensionSignBytes(chainID, vote)); err != nil {
+				return types.TestnetFork{}, err
+			}
+		}
+		commit.ExtendedSignatures = append(commit.ExtendedSignatures, commitSig)
+	}
+
+	if extensionsEnabled {
+		// the extended commit is only saved along with its block
+		block, _ := blockStore.LoadBlock(height)
+		if block == nil {
+			return types.TestnetFork{}, fmt.Errorf("block %d not found", height)
+		}
+		parts, err := block.MakePartSet(cmttypes.BlockPartSizeBytes)
+		if err != nil {
+			return types.TestnetFork{}, err
+		}
+		if !parts.Header().Equals(state.LastBlockID.PartSetHeader) {
+			return types.TestnetFork{}, fmt.Errorf("the parts of block %d differ from the ones of the CometBFT state", height)
+		}
+		if err := blockStore.DeleteLatestBlock(); err != nil {
+			return types.TestnetFork{}, err
+		}
+		blockStore.SaveBlockWithExtendedCommit(block, parts, commit)
+	} else if err := blockStore.SaveSeenCommit(height, commit.ToCommit()); err != nil {
+		return types.TestnetFork{}, err
+	}
+
+	state.ChainID = chainID
+	state.LastValidators = valSet
+	state.Validators = valSet
+	state.NextValidators = valSet.CopyIncrementProposerPriority(1)
+	state.LastHeightValidatorsChanged = height + 1
+	if err := stateStore.Bootstrap(state); err != nil {
+		return types.TestnetFork{}, err
+	}
+
+	// the votes of the consensus WAL belong to the forked chain
+	if err := os.RemoveAll(filepath.Dir(config.Consensus.WalFile())); err != nil {
+		return types.TestnetFork{}, err
+	}
+
+	genFilePath := config.GenesisFile()
+	appGen, err := genutiltypes.AppGenesisFromFile(genFilePath)
+	if err != nil {
+		return types.TestnetFork{}, err
+	}
+	appGen.ChainID = chainID
+	if err := appGen.ValidateAndComplete(); err != nil {
+		return types.TestnetFork{}, err
+	}
+	if err := appGen.SaveAs(genFilePath); err != nil {
+		return types.TestnetFork{}, err
+	}
+
+	// CometBFT checks that the genesis file matches the one the node was started with
+	genDoc, err := getGenDocProvider(config)()
+	if err != nil {
+		return types.TestnetFork{}, err
+	}
+	if err := stateDB.SetSync([]byte("genesisDocHash"), genDoc.Sha256Checksum); err != nil {
+		return types.TestnetFork{}, err
+	}
+
+	return fork, nil
+}
+
+// initTestnetForkHome sets up the home of a validator of a testnet fork, other than
+// the one of the node, with a copy of the configuration and data of the node.
+func initTestnetForkHome(config *cmtcfg.Config, val *testnetForkValidator) error {
+	skip := map[string]bool{
+		config.PrivValidatorKeyFile():          true,
+		config.PrivValidatorKeyFile() + ".bak": true,
+		config.PrivValidatorStateFile():        true,
+		config.NodeKeyFile():                   true,
+	}
+	if err := copyDir(filepath.Join(config.RootDir, "config"), filepath.Join(val.home, "config"), skip); err != nil {
+		return err
+	}
+	if err := copyDir(config.DBDir(), filepath.Join(val.home, config.DBPath), skip); err != nil {
+		return err
+	}
+
+	pvm.NewFilePV(
+		val.privKey,
+		filepath.Join(val.home, config.PrivValidatorKey),
+		filepath.Join(val.home, config.PrivValidatorState),
+	).Save()
+	nodeKey, err := p2p.LoadOrGenNodeKey(filepath.Join(val.home, config.NodeKey))
+	if err != nil {
+		return err
+	}
+	val.nodeID = string(nodeKey.ID())
+
+	return nil
+}
+
+// copyDir copies a directory recursively, except the given files.
+func copyDir(src, dst string, skip map[string]bool) error {
+	return filepath.WalkDir(src, func(path string, d os.DirEntry, err error) error {
+		if err != nil || skip[path] {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if d.IsDir() {
+			return os.MkdirAll(target, 0o755)
+		}
+
+		in, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer in.Close()
+		fi, err := in.Stat()
+		if err != nil {
+			return err
+		}
+		out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, fi.Mode())
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(out, in); err != nil {
+			_ = out.Close()
+			return err
+		}
+		return out.Close()
+	})
+}
+
+// writeTestnetFork records a testnet fork in the data directory.
+func writeTestnetFork(dataDir string, fork types.TestnetFork) error {
+	bz, err := json.MarshalIndent(fork, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(dataDir, testnetForkFile), bz, 0o600)
+}
+
+// readTestnetFork reads the testnet fork recorded in the data directory by the
+// fork-testnet command, if any.
+func readTestnetFork(dataDir string) (*types.TestnetFork, error) {
+	bz, err := os.ReadFile(filepath.Join(dataDir, testnetForkFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var fork types.TestnetFork
+	if err := json.Unmarshal(bz, &fork); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", testnetForkFile, err)
+	}
+
+	return &fork, nil
+}
+
+// applyTestnetFork applies the testnet fork recorded in the data directory to the
+// application, if it is still at the forked height. The fork is then committed with
+// the first block of the testnet.
+func applyTestnetFork(svrCtx *Context, app types.Application) error {
+	fork, err := readTestnetFork(svrCtx.Config.DBDir())
+	if err != nil || fork == nil {
+		return err
+	}
+	if app.CommitMultiStore().LastCommitID().Version != fork.Height {
+		return nil
+	}
+
+	forker, ok := app.(types.TestnetForker)
+	if !ok {
+		return fmt.Errorf("the application does not support testnet forks, found in %s", testnetForkFile)
+	}
+
+	svrCtx.Logger.Info("applying testnet fork", "chain_id", fork.ChainID, "height", fork.Height, "validators", len(fork.Validators))
+	return forker.ForkTestnet(*fork)
+}
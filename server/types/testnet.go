@@ -0,0 +1,40 @@
+package types
+
+import "time"
+
+type (
+	// TestnetFork describes the fork of the state of a node into a testnet, whose
+	// validator set is replaced by locally generated keys.
+	TestnetFork struct {
+		// ChainID is the chain-id of the testnet.
+		ChainID string `json:"chain_id"`
+		// Height is the height of the forked state. The fork is applied on top of
+		// it, in the first block of the testnet.
+		Height int64 `json:"height"`
+		// Time is the time of the block at the forked height.
+		Time time.Time `json:"time"`
+		// Validators is the validator set of the testnet.
+		Validators []TestnetValidator `json:"validators"`
+	}
+
+	// TestnetValidator is a validator of a testnet fork.
+	TestnetValidator struct {
+		Moniker string `json:"moniker"`
+		// OperatorAddress is the account address of the operator of the validator.
+		OperatorAddress string `json:"operator_address"`
+		// ConsPubKey is the ed25519 consensus public key of the validator.
+		ConsPubKey []byte `json:"cons_pub_key"`
+		// Power is the consensus power of the validator.
+		Power int64 `json:"power"`
+	}
+
+	// TestnetForker is implemented by applications whose state can be forked into
+	// a testnet by the fork-testnet command.
+	TestnetForker interface {
+		// ForkTestnet replaces the validator set of the application state by the
+		// validators of the fork. The changes are not committed: they are committed
+		// with the first block of the testnet, so the fork must be deterministic, as
+		// it is applied by every node of the testnet.
+		ForkTestnet(fork TestnetFork) error
+	}
+)
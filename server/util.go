@@ -17,7 +17,6 @@ import (
 	cmtcmd "github.com/cometbft/cometbft/cmd/cometbft/commands"
 	cmtcfg "github.com/cometbft/cometbft/config"
 	dbm "github.com/cosmos/cosmos-db"
-	"github.com/rs/zerolog"
 	"github.com/spf13/cast"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
@@ -31,6 +30,7 @@ import (
 	"cosmossdk.io/store/snapshots"
 	snapshottypes "cosmossdk.io/store/snapshots/types"
 	storetypes "cosmossdk.io/store/types"
+	authtx "cosmossdk.io/x/auth/tx"
 
 	"github.com/cosmos/cosmos-sdk/baseapp"
 	"github.com/cosmos/cosmos-sdk/client/flags"
@@ -181,25 +181,25 @@ func CreateSDKLogger(ctx *Context, out io.Writer) (log.Logger, error) {
 		// We use CometBFT flag (cmtcli.TraceFlag) for trace logging.
 		log.TraceOption(ctx.Viper.GetBool(FlagTrace)))
 
-	// check and set filter level or keys for the logger if any
+	if patterns := ctx.Viper.GetStringSlice(flags.FlagLogRedact); len(patterns) > 0 {
+		opts = append(opts, log.RedactOption(patterns...))
+	}
+	if burst := ctx.Viper.GetUint32(flags.FlagLogSampleBurst); burst > 0 {
+		opts = append(opts, log.SamplingOption(burst, ctx.Viper.GetDuration(flags.FlagLogSamplePeriod)))
+	}
+
+	// check and set the level or the per-module levels of the logger if any, which
+	// can be changed at runtime
 	logLvlStr := ctx.Viper.GetString(flags.FlagLogLevel)
 	if logLvlStr == "" {
 		return log.NewLogger(out, opts...), nil
 	}
 
-	logLvl, err := zerolog.ParseLevel(logLvlStr)
-	switch {
-	case err != nil:
-		// If the log level is not a valid zerolog level, then we try to parse it as a key filter.
-		filterFunc, err := log.ParseLogLevel(logLvlStr)
-		if err != nil {
-			return nil, err
-		}
-
-		opts = append(opts, log.FilterOption(filterFunc))
-	default:
-		opts = append(opts, log.LevelOption(logLvl))
+	levelFilter, err := log.NewLevelFilter(logLvlStr)
+	if err != nil {
+		return nil, err
 	}
+	opts = append(opts, log.LevelFilterOption(levelFilter))
 
 	return log.NewLogger(out, opts...), nil
 }
@@ -447,6 +447,21 @@ func GetAppDBBackend(opts types.AppOptions) dbm.BackendType {
 	return dbm.GoLevelDBBackend
 }
 
+// WithoutStreaming returns a copy of the viper without the streaming configuration, for
+// commands building the application outside of the running node, so that the ABCI
+// streaming plugins aren't started, the indexer doesn't write to its database and the
+// streaming queue in the data directory isn't opened, against its resources.
+func WithoutStreaming(v *viper.Viper) (*viper.Viper, error) {
+	settings := v.AllSettings()
+	delete(settings, baseapp.StreamingTomlKey)
+
+	nv := viper.New()
+	if err := nv.MergeConfigMap(settings); err != nil {
+		return nil, err
+	}
+	return nv, nil
+}
+
 func skipInterface(iface net.Interface) bool {
 	if iface.Flags&net.FlagUp == 0 {
 		return true // interface down
@@ -554,6 +569,18 @@ func DefaultBaseappOptions(appOpts types.AppOptions) []func(*baseapp.BaseApp) {
 	}
 }
 
+// TxDecodePolicy returns the transactions decode policy configured in the
+// tx-decode section of app.toml. The zero policy imposes no limits.
+func TxDecodePolicy(appOpts types.AppOptions) authtx.DecodePolicy {
+	return authtx.DecodePolicy{
+		MaxMsgs:         cast.ToInt(appOpts.Get(FlagTxDecodeMaxMsgs)),
+		MaxMsgSize:      cast.ToInt(appOpts.Get(FlagTxDecodeMaxMsgSize)),
+		MaxAnyDepth:     cast.ToInt(appOpts.Get(FlagTxDecodeMaxAnyDepth)),
+		AllowedTypeURLs: cast.ToStringSlice(appOpts.Get(FlagTxDecodeAllowedTypeURLs)),
+		DeniedTypeURLs:  cast.ToStringSlice(appOpts.Get(FlagTxDecodeDeniedTypeURLs)),
+	}
+}
+
 func GetSnapshotStore(appOpts types.AppOptions) (*snapshots.Store, error) {
 	homeDir := cast.ToString(appOpts.Get(flags.FlagHome))
 	snapshotDir := filepath.Join(homeDir, "data", "snapshots")
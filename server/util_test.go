@@ -16,6 +16,9 @@ import (
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/require"
 
+	"cosmossdk.io/log"
+	authtx "cosmossdk.io/x/auth/tx"
+
 	"github.com/cosmos/cosmos-sdk/client"
 	"github.com/cosmos/cosmos-sdk/client/flags"
 	codectestutil "github.com/cosmos/cosmos-sdk/codec/testutil"
@@ -49,6 +52,22 @@ func TestGetAppDBBackend(t *testing.T) {
 	require.Equal(t, server.GetAppDBBackend(v), db.BackendType("dbtype2"))
 }
 
+func TestWithoutStreaming(t *testing.T) {
+	v := viper.New()
+	v.Set("app-db-backend", "pebbledb")
+	v.Set("streaming.abci.plugin", "abci_v1")
+	v.Set("streaming.queue.enable", true)
+	v.Set("streaming.indexer.enable", true)
+
+	nv, err := server.WithoutStreaming(v)
+	require.NoError(t, err)
+	require.Equal(t, db.PebbleDBBackend, server.GetAppDBBackend(nv))
+	require.Nil(t, nv.Get("streaming"))
+	require.Nil(t, nv.Get("streaming.abci.plugin"))
+	// the original viper is left untouched
+	require.Equal(t, "abci_v1", v.GetString("streaming.abci.plugin"))
+}
+
 func TestInterceptConfigsPreRunHandlerCreatesConfigFilesWhenMissing(t *testing.T) {
 	tempDir := t.TempDir()
 	cmd := server.StartCmd[servertypes.Application](nil)
@@ -493,3 +512,48 @@ func (m mapGetter) Get(key string) interface{} {
 }
 
 var _ servertypes.AppOptions = mapGetter{}
+
+func TestTxDecodePolicy(t *testing.T) {
+	require.Equal(t, authtx.DecodePolicy{}, server.TxDecodePolicy(mapGetter{}))
+
+	policy := server.TxDecodePolicy(mapGetter{
+		server.FlagTxDecodeMaxMsgs:         "5",
+		server.FlagTxDecodeMaxMsgSize:      1024,
+		server.FlagTxDecodeMaxAnyDepth:     2,
+		server.FlagTxDecodeAllowedTypeURLs: []interface{}{"/cosmos.bank.v1beta1.MsgSend"},
+		server.FlagTxDecodeDeniedTypeURLs:  []string{"/cosmos.gov.v1.MsgVote"},
+	})
+	require.Equal(t, authtx.DecodePolicy{
+		MaxMsgs:         5,
+		MaxMsgSize:      1024,
+		MaxAnyDepth:     2,
+		AllowedTypeURLs: []string{"/cosmos.bank.v1beta1.MsgSend"},
+		DeniedTypeURLs:  []string{"/cosmos.gov.v1.MsgVote"},
+	}, policy)
+}
+
+func TestCreateSDKLogger(t *testing.T) {
+	ctx := server.NewDefaultContext()
+	ctx.Viper.Set(flags.FlagLogLevel, "consensus:debug,*:info")
+	ctx.Viper.Set(flags.FlagLogNoColor, true)
+	ctx.Viper.Set(flags.FlagLogRedact, []string{"*mnemonic*"})
+
+	var out strings.Builder
+	logger, err := server.CreateSDKLogger(ctx, &out)
+	require.NoError(t, err)
+	logger.With("module", "consensus").Debug("consensus dump", "mnemonic", "abandon abandon")
+	logger.Debug("other dump")
+	require.Contains(t, out.String(), "consensus dump")
+	require.Contains(t, out.String(), "mnemonic=[REDACTED]")
+	require.NotContains(t, out.String(), "other dump")
+
+	levelFilter, ok := log.GetLevelFilter(logger)
+	require.True(t, ok)
+	require.NoError(t, levelFilter.SetModuleLevel("*", "debug"))
+	logger.Debug("other dump")
+	require.Contains(t, out.String(), "other dump")
+
+	ctx.Viper.Set(flags.FlagLogLevel, "consensus:foo")
+	_, err = server.CreateSDKLogger(ctx, &out)
+	require.Error(t, err)
+}
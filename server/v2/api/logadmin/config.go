@@ -0,0 +1,19 @@
+package logadmin
+
+func DefaultConfig() *Config {
+	return &Config{
+		Enable: false,
+		// the endpoint isn't authenticated, so it only listens on loopback addresses
+		Address: "localhost:1318",
+	}
+}
+
+// Config defines configuration for the log admin server.
+type Config struct {
+	// Enable defines if the log admin server should be enabled.
+	Enable bool `mapstructure:"enable" toml:"enable" comment:"Enable defines if the log admin server, reading and changing the per-module log levels at runtime at /log/level, should be enabled."`
+
+	// Address defines the log admin server address to bind to. It must be a
+	// loopback address, as the endpoint isn't authenticated.
+	Address string `mapstructure:"address" toml:"address" comment:"Address defines the log admin server address to bind to. It must be a loopback address, as the endpoint isn't authenticated."`
+}
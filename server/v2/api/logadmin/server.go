@@ -0,0 +1,90 @@
+package logadmin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/spf13/viper"
+
+	"cosmossdk.io/log"
+	serverv2 "cosmossdk.io/server/v2"
+)
+
+const serverName = "log-admin"
+
+// LogAdminServer serves the /log/level endpoint, reading and changing the per-module
+// levels of a logger created by serverv2.NewLogger at runtime.
+type LogAdminServer struct {
+	logger log.Logger
+
+	srv    *http.Server
+	config *Config
+}
+
+// New returns the log admin server of the levels of the given logger.
+// Note, the caller is responsible for starting the server.
+func New(logger log.Logger, v *viper.Viper) (LogAdminServer, error) {
+	cfg := DefaultConfig()
+	if v != nil {
+		if err := v.Sub(serverName).Unmarshal(&cfg); err != nil {
+			return LogAdminServer{}, fmt.Errorf("failed to unmarshal config: %w", err)
+		}
+	}
+
+	router := mux.NewRouter()
+	if cfg.Enable {
+		if err := serverv2.RegisterLogLevel(router, logger); err != nil {
+			return LogAdminServer{}, err
+		}
+	}
+
+	return LogAdminServer{
+		srv:    &http.Server{Handler: router, ReadHeaderTimeout: 10 * time.Second},
+		config: cfg,
+		logger: logger.With(log.ModuleKey, serverName),
+	}, nil
+}
+
+func (s LogAdminServer) Name() string {
+	return serverName
+}
+
+func (s LogAdminServer) Start(ctx context.Context) error {
+	if !s.config.Enable {
+		return nil
+	}
+
+	listener, err := log.ListenLevelAdmin(s.config.Address)
+	if err != nil {
+		return err
+	}
+
+	s.logger.Info("starting log admin server...", "address", s.config.Address)
+	if err := s.srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		s.logger.Error("failed to start log admin server", "err", err)
+		return err
+	}
+
+	return nil
+}
+
+func (s LogAdminServer) Stop(ctx context.Context) error {
+	if !s.config.Enable {
+		return nil
+	}
+
+	s.logger.Info("stopping log admin server...", "address", s.config.Address)
+	return s.srv.Shutdown(ctx)
+}
+
+func (s LogAdminServer) Config() any {
+	if s.config == nil {
+		return DefaultConfig()
+	}
+
+	return s.config
+}
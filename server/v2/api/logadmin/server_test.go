@@ -0,0 +1,68 @@
+package logadmin_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+
+	"cosmossdk.io/log"
+	serverv2 "cosmossdk.io/server/v2"
+	"cosmossdk.io/server/v2/api/logadmin"
+)
+
+func newLogger(t *testing.T) log.Logger {
+	t.Helper()
+	v := viper.New()
+	v.Set(serverv2.FlagLogLevel, "info")
+	logger, err := serverv2.NewLogger(v, io.Discard)
+	require.NoError(t, err)
+	return logger
+}
+
+func TestLogAdminServer(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	address := listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	logger := newLogger(t)
+	v := viper.New()
+	v.Set("log-admin.enable", true)
+	v.Set("log-admin.address", address)
+	srv, err := logadmin.New(logger, v)
+	require.NoError(t, err)
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- srv.Start(context.Background()) }()
+
+	var res *http.Response
+	require.Eventually(t, func() bool {
+		res, err = http.Post("http://"+address+"/log/level", "application/json", strings.NewReader(`{"module": "consensus", "level": "debug"}`))
+		return err == nil
+	}, 5*time.Second, 50*time.Millisecond)
+	require.NoError(t, res.Body.Close())
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	filter, ok := log.GetLevelFilter(logger)
+	require.True(t, ok)
+	require.Equal(t, "consensus:debug,*:info", filter.String())
+
+	require.NoError(t, srv.Stop(context.Background()))
+	require.NoError(t, <-errCh)
+
+	// non loopback addresses are rejected
+	v.Set("log-admin.address", "0.0.0.0:0")
+	srv, err = logadmin.New(logger, v)
+	require.NoError(t, err)
+	require.ErrorContains(t, srv.Start(context.Background()), "is not a loopback address")
+
+	// the logger must be created by serverv2.NewLogger
+	_, err = logadmin.New(log.NewNopLogger(), v)
+	require.ErrorContains(t, err, "can't be changed at runtime")
+}
@@ -12,6 +12,8 @@ import (
 	metricsprom "github.com/hashicorp/go-metrics/prometheus"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/common/expfmt"
+
+	"cosmossdk.io/server/v2/stf"
 )
 
 // GlobalLabels defines the set of global labels that will be applied to all
@@ -119,6 +121,10 @@ func New(cfg Config) (_ *Metrics, rerr error) {
 		return nil, err
 	}
 
+	// emit the gas used and latency of the messages and the bytes read and written
+	// per store key
+	stf.EnableMetrics(GlobalLabels)
+
 	return m, nil
 }
 
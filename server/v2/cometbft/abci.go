@@ -7,6 +7,7 @@ import (
 	"sync/atomic"
 
 	abci "github.com/cometbft/cometbft/abci/types"
+	cmttypes "github.com/cometbft/cometbft/types"
 
 	coreappmgr "cosmossdk.io/core/app"
 	"cosmossdk.io/core/comet"
@@ -23,6 +24,9 @@ import (
 	"cosmossdk.io/server/v2/streaming"
 	"cosmossdk.io/store/v2/snapshots"
 	consensustypes "cosmossdk.io/x/consensus/types"
+
+	"github.com/cosmos/cosmos-sdk/baseapp/oe"
+	"github.com/cosmos/cosmos-sdk/types/voteext"
 )
 
 const (
@@ -53,6 +57,15 @@ type Consensus[T transaction.Tx] struct {
 	verifyVoteExt          handlers.VerifyVoteExtensionhandler
 	extendVote             handlers.ExtendVoteHandler
 
+	// voteExtensions multiplexes the vote extensions of its providers, and injects their
+	// results in the proposals. It is nil unless set with SetVoteExtensions.
+	voteExtensions *voteext.Manager
+	valStore       voteext.ValidatorStore
+
+	// optimisticExec executes the proposals accepted in ProcessProposal speculatively. It
+	// is nil unless enabled by the configuration.
+	optimisticExec *oe.Executor[*executedBlock[T]]
+
 	chainID string
 }
 
@@ -64,7 +77,7 @@ func NewConsensus[T transaction.Tx](
 	txCodec transaction.Codec[T],
 	logger log.Logger,
 ) *Consensus[T] {
-	return &Consensus[T]{
+	c := &Consensus[T]{
 		mempool: mp,
 		store:   store,
 		app:     app,
@@ -72,6 +85,12 @@ func NewConsensus[T transaction.Tx](
 		txCodec: txCodec,
 		logger:  logger,
 	}
+	if cfg.OptimisticExecution {
+		// the blocks are executed without being committed, several proposals can be
+		// executed concurrently.
+		c.optimisticExec = oe.NewExecutor(logger, c.executeBlock, oe.WithMaxCandidates(cfg.OptimisticExecutionMaxCandidates))
+	}
+	return c
 }
 
 func (c *Consensus[T]) SetMempool(mp mempool.Mempool[T]) {
@@ -310,6 +329,16 @@ func (c *Consensus[T]) PrepareProposal(
 		return nil, errors.New("PrepareProposal called with invalid height")
 	}
 
+	injectedTx, err := c.buildInjectedTx(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if injectedTx != nil {
+		r := *req
+		r.MaxTxBytes -= cmttypes.ComputeProtoSizeForTxs([]cmttypes.Tx{injectedTx})
+		req = &r
+	}
+
 	decodedTxs := make([]T, len(req.Txs))
 	for _, tx := range req.Txs {
 		decTx, err := c.txCodec.Decode(tx)
@@ -333,9 +362,12 @@ func (c *Consensus[T]) PrepareProposal(
 		return nil, err
 	}
 
-	encodedTxs := make([][]byte, len(txs))
-	for i, tx := range txs {
-		encodedTxs[i] = tx.Bytes()
+	encodedTxs := make([][]byte, 0, len(txs)+1)
+	if injectedTx != nil {
+		encodedTxs = append(encodedTxs, injectedTx)
+	}
+	for _, tx := range txs {
+		encodedTxs = append(encodedTxs, tx.Bytes())
 	}
 
 	return &abci.PrepareProposalResponse{
@@ -349,6 +381,20 @@ func (c *Consensus[T]) ProcessProposal(
 	ctx context.Context,
 	req *abci.ProcessProposalRequest,
 ) (*abci.ProcessProposalResponse, error) {
+	origReq := req
+	rawTxs, err := c.verifyInjectedTx(ctx, req)
+	if err != nil {
+		c.logger.Error("invalid injected vote extensions", "height", req.Height, "err", err)
+		return &abci.ProcessProposalResponse{
+			Status: abci.PROCESS_PROPOSAL_STATUS_REJECT,
+		}, nil
+	}
+	if len(rawTxs) != len(req.Txs) {
+		r := *req
+		r.Txs = rawTxs
+		req = &r
+	}
+
 	decodedTxs := make([]T, len(req.Txs))
 	for _, tx := range req.Txs {
 		decTx, err := c.txCodec.Decode(tx)
@@ -367,7 +413,7 @@ func (c *Consensus[T]) ProcessProposal(
 		LastCommit:      toCoreCommitInfo(req.ProposedLastCommit),
 	})
 
-	err := c.processProposalHandler(ciCtx, c.app, decodedTxs, req)
+	err = c.processProposalHandler(ciCtx, c.app, decodedTxs, req)
 	if err != nil {
 		c.logger.Error("failed to process proposal", "height", req.Height, "time", req.Time, "hash", fmt.Sprintf("%X", req.Hash), "err", err)
 		return &abci.ProcessProposalResponse{
@@ -375,6 +421,13 @@ func (c *Consensus[T]) ProcessProposal(
 		}, nil
 	}
 
+	// Only execute optimistic execution if the proposal is accepted and OE is enabled.
+	// The genesis state is committed in InitChain, so the first block can be executed
+	// optimistically as well.
+	if c.optimisticExec.Enabled() {
+		c.optimisticExec.Execute(origReq)
+	}
+
 	return &abci.ProcessProposalResponse{
 		Status: abci.PROCESS_PROPOSAL_STATUS_ACCEPT,
 	}, nil
@@ -412,40 +465,30 @@ func (c *Consensus[T]) FinalizeBlock(
 	// 	LastCommit:      sdktypes.ToSDKCommitInfo(req.DecidedLastCommit),
 	// })
 
-	// TODO(tip): can we expect some txs to not decode? if so, what we do in this case? this does not seem to be the case,
-	// considering that prepare and process always decode txs, assuming they're the ones providing txs we should never
-	// have a tx that fails decoding.
-	decodedTxs, err := decodeTxs(req.Txs, c.txCodec)
-	if err != nil {
-		return nil, err
-	}
-
-	cid, err := c.store.LastCommitID()
-	if err != nil {
-		return nil, err
-	}
-
-	blockReq := &coreappmgr.BlockRequest[T]{
-		Height:  uint64(req.Height),
-		Time:    req.Time,
-		Hash:    req.Hash,
-		AppHash: cid.Hash,
-		ChainId: c.chainID,
-		Txs:     decodedTxs,
-		// ConsensusMessages: []transaction.Msg{cometInfo},
+	var (
+		block *executedBlock[T]
+		err   error
+	)
+	if c.optimisticExec.Initialized() {
+		// check if the hash we got is the same as one of the proposals we are executing
+		// the executions of the other proposals don't share any state with this one, so
+		// they are cancelled without waiting for them
+		if !c.optimisticExec.AbortIfNeeded(req.Hash) {
+			block, err = c.optimisticExec.WaitResult()
+		}
+		c.optimisticExec.Reset()
+		if err != nil {
+			return nil, err
+		}
 	}
-
-	ciCtx := contextWithCometInfo(ctx, comet.Info{
-		Evidence:        toCoreEvidence(req.Misbehavior),
-		ValidatorsHash:  req.NextValidatorsHash,
-		ProposerAddress: req.ProposerAddress,
-		LastCommit:      toCoreCommitInfo(req.DecidedLastCommit),
-	})
-
-	resp, newState, err := c.app.DeliverBlock(ciCtx, blockReq)
-	if err != nil {
-		return nil, err
+	if block == nil {
+		// if no OE is running, just run the block (this is either a block replay or a OE that got aborted)
+		block, err = c.executeBlock(ctx, req)
+		if err != nil {
+			return nil, err
+		}
 	}
+	resp, newState, rawTxs, decodedTxs := block.resp, block.newState, block.rawTxs, block.decodedTxs
 
 	// after we get the changeset we can produce the commit hash,
 	// from the store.
@@ -467,7 +510,7 @@ func (c *Consensus[T]) FinalizeBlock(
 	events = append(events, resp.EndBlockEvents...)
 
 	// listen to state streaming changes in accordance with the block
-	err = c.streamDeliverBlockChanges(ctx, req.Height, req.Txs, resp.TxResults, events, stateChanges)
+	err = c.streamDeliverBlockChanges(ctx, req.Height, rawTxs, resp.TxResults, events, stateChanges)
 	if err != nil {
 		return nil, err
 	}
@@ -489,7 +532,84 @@ func (c *Consensus[T]) FinalizeBlock(
 		return nil, err
 	}
 
-	return finalizeBlockResponse(resp, cp, appHash, c.cfg.IndexEvents)
+	fbResp, err := finalizeBlockResponse(resp, cp, appHash, c.cfg.IndexEvents)
+	if err != nil {
+		return nil, err
+	}
+	if block.injected {
+		// keep a result for every transaction of the block
+		fbResp.TxResults = append([]*abci.ExecTxResult{{}}, fbResp.TxResults...)
+	}
+	return fbResp, nil
+}
+
+// executedBlock is the result of the execution of a block, before it is committed.
+type executedBlock[T transaction.Tx] struct {
+	resp       *coreappmgr.BlockResponse
+	newState   store.WriterMap
+	rawTxs     [][]byte
+	decodedTxs []T
+	// injected is whether the first transaction of the block injects the results of the
+	// vote extensions, and isn't executed.
+	injected bool
+}
+
+// executeBlock executes the block without committing it. It is called by FinalizeBlock,
+// or speculatively by the optimistic execution.
+func (c *Consensus[T]) executeBlock(ctx context.Context, req *abci.FinalizeBlockRequest) (*executedBlock[T], error) {
+	// TODO(tip): can we expect some txs to not decode? if so, what we do in this case? this does not seem to be the case,
+	// considering that prepare and process always decode txs, assuming they're the ones providing txs we should never
+	// have a tx that fails decoding.
+	// the results injected from the vote extensions are applied by the PreBlock of the
+	// modules, instead of being executed as a transaction.
+	ctx, rawTxs, injected := c.withInjectedTx(ctx, req.Txs)
+	decodedTxs, err := decodeTxs(rawTxs, c.txCodec)
+	if err != nil {
+		return nil, err
+	}
+
+	cid, err := c.store.LastCommitID()
+	if err != nil {
+		return nil, err
+	}
+
+	blockReq := &coreappmgr.BlockRequest[T]{
+		Height:  uint64(req.Height),
+		Time:    req.Time,
+		Hash:    req.Hash,
+		AppHash: cid.Hash,
+		ChainId: c.chainID,
+		Txs:     decodedTxs,
+		// ConsensusMessages: []transaction.Msg{cometInfo},
+	}
+
+	ciCtx := contextWithCometInfo(ctx, comet.Info{
+		Evidence:        toCoreEvidence(req.Misbehavior),
+		ValidatorsHash:  req.NextValidatorsHash,
+		ProposerAddress: req.ProposerAddress,
+		LastCommit:      toCoreCommitInfo(req.DecidedLastCommit),
+	})
+
+	// an optimistic execution may be cancelled before it starts, or while the block is
+	// delivered: its result isn't used
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	resp, newState, err := c.app.DeliverBlock(ciCtx, blockReq)
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return &executedBlock[T]{
+		resp:       resp,
+		newState:   newState,
+		rawTxs:     rawTxs,
+		decodedTxs: decodedTxs,
+		injected:   injected,
+	}, nil
 }
 
 // Commit implements types.Application.
@@ -577,7 +697,10 @@ func (c *Consensus[T]) ExtendVote(ctx context.Context, req *abci.ExtendVoteReque
 	resp, err := c.extendVote(ctx, latestStore, req)
 	if err != nil {
 		c.logger.Error("failed to verify vote extension", "height", req.Height, "err", err)
-		return &abci.ExtendVoteResponse{}, nil
+		if resp == nil {
+			resp = &abci.ExtendVoteResponse{}
+		}
+		return resp, nil
 	}
 
 	return resp, err
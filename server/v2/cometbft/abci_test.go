@@ -0,0 +1,235 @@
+package cometbft
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+	cmtproto "github.com/cometbft/cometbft/api/cometbft/types/v1"
+	"github.com/stretchr/testify/require"
+
+	coreappmgr "cosmossdk.io/core/app"
+	"cosmossdk.io/core/log"
+	"cosmossdk.io/core/store"
+	"cosmossdk.io/core/transaction"
+	"cosmossdk.io/server/v2/appmanager"
+	"cosmossdk.io/server/v2/cometbft/handlers"
+	"cosmossdk.io/server/v2/cometbft/mempool"
+	storev2 "cosmossdk.io/store/v2"
+	"cosmossdk.io/store/v2/proof"
+	consensustypes "cosmossdk.io/x/consensus/types"
+)
+
+var blockActor = []byte("block")
+
+// mockSTF executes blocks by recording their hash in the state.
+type mockSTF struct {
+	appmanager.StateTransitionFunction[transaction.Tx]
+
+	// held are the blocks whose execution receives twice from the channel: once when it
+	// starts, and once to finish
+	held map[string]chan struct{}
+
+	mu       sync.Mutex
+	executed [][]byte
+}
+
+func (s *mockSTF) DeliverBlock(_ context.Context, block *coreappmgr.BlockRequest[transaction.Tx], _ store.ReaderMap) (*coreappmgr.BlockResponse, store.WriterMap, error) {
+	if hold, ok := s.held[string(block.Hash)]; ok {
+		<-hold
+		<-hold
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.executed = append(s.executed, block.Hash)
+	return &coreappmgr.BlockResponse{}, mockState{changes: []store.StateChanges{{
+		Actor:        blockActor,
+		StateChanges: []store.KVPair{{Key: []byte("hash"), Value: block.Hash}},
+	}}}, nil
+}
+
+func (s *mockSTF) Query(context.Context, store.ReaderMap, uint64, transaction.Msg) (transaction.Msg, error) {
+	return &consensustypes.QueryParamsResponse{Params: &cmtproto.ConsensusParams{
+		Block:     &cmtproto.BlockParams{MaxBytes: 1 << 20, MaxGas: -1},
+		Evidence:  &cmtproto.EvidenceParams{MaxAgeNumBlocks: 100, MaxAgeDuration: time.Hour},
+		Validator: &cmtproto.ValidatorParams{PubKeyTypes: []string{"ed25519"}},
+		Version:   &cmtproto.VersionParams{},
+	}}, nil
+}
+
+func (s *mockSTF) executedHashes() [][]byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([][]byte{}, s.executed...)
+}
+
+type mockState struct {
+	store.WriterMap
+	changes []store.StateChanges
+}
+
+func (s mockState) GetStateChanges() ([]store.StateChanges, error) {
+	return s.changes, nil
+}
+
+// mockStore records the committed changesets.
+type mockStore struct {
+	mu        sync.Mutex
+	committed []*store.Changeset
+}
+
+func (s *mockStore) version() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return uint64(len(s.committed))
+}
+
+func (s *mockStore) GetLatestVersion() (uint64, error) { return s.version(), nil }
+
+func (s *mockStore) StateLatest() (uint64, store.ReaderMap, error) { return s.version(), nil, nil }
+
+func (s *mockStore) StateAt(uint64) (store.ReaderMap, error) { return nil, nil }
+
+func (s *mockStore) Commit(cs *store.Changeset) (store.Hash, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.committed = append(s.committed, cs)
+	return []byte{byte(len(s.committed))}, nil
+}
+
+func (s *mockStore) LastCommitID() (proof.CommitID, error) {
+	return proof.CommitID{Version: s.version()}, nil
+}
+
+func (s *mockStore) Query([]byte, uint64, []byte, bool) (storev2.QueryResult, error) {
+	return storev2.QueryResult{}, errors.New("not implemented")
+}
+
+func (s *mockStore) GetStateStorage() storev2.VersionedDatabase { return nil }
+
+func (s *mockStore) GetStateCommitment() storev2.Committer { return nil }
+
+type mockTxCodec struct{}
+
+func (mockTxCodec) Decode([]byte) (transaction.Tx, error) { return nil, errors.New("not implemented") }
+
+func (mockTxCodec) DecodeJSON([]byte) (transaction.Tx, error) {
+	return nil, errors.New("not implemented")
+}
+
+func newTestConsensus(t *testing.T, cfg Config) (*Consensus[transaction.Tx], *mockSTF, *mockStore) {
+	t.Helper()
+
+	stf := &mockSTF{}
+	st := &mockStore{}
+	app, err := appmanager.Builder[transaction.Tx]{STF: stf, DB: st}.Build()
+	require.NoError(t, err)
+
+	c := NewConsensus[transaction.Tx](app, mempool.NoOpMempool[transaction.Tx]{}, st, cfg, mockTxCodec{}, log.NewNopLogger())
+	c.SetProcessProposalHandler(handlers.NoOpProcessProposal[transaction.Tx]())
+	return c, stf, st
+}
+
+func TestConsensusOptimisticExecution(t *testing.T) {
+	proposal := func(hash string) *abci.ProcessProposalRequest {
+		return &abci.ProcessProposalRequest{Height: 1, Hash: []byte(hash), Time: time.Unix(100, 0)}
+	}
+	decided := func(hash string) *abci.FinalizeBlockRequest {
+		return &abci.FinalizeBlockRequest{Height: 1, Hash: []byte(hash), Time: time.Unix(100, 0)}
+	}
+
+	testCases := []struct {
+		name      string
+		proposals []string
+		decided   string
+		// executed are the hashes of the blocks executed when FinalizeBlock returns; the
+		// cancelled executions may not have run yet
+		executed []string
+	}{
+		{
+			name:      "hit",
+			proposals: []string{"a"},
+			decided:   "a",
+			executed:  []string{"a"},
+		},
+		{
+			name:      "abort",
+			proposals: []string{"a"},
+			decided:   "b",
+			executed:  []string{"b"},
+		},
+		{
+			name:      "hit among several candidates",
+			proposals: []string{"a", "b"},
+			decided:   "a",
+			executed:  []string{"a"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, stf, st := newTestConsensus(t, Config{OptimisticExecution: true, OptimisticExecutionMaxCandidates: 2})
+
+			for _, hash := range tc.proposals {
+				res, err := c.ProcessProposal(context.Background(), proposal(hash))
+				require.NoError(t, err)
+				require.Equal(t, abci.PROCESS_PROPOSAL_STATUS_ACCEPT, res.Status)
+			}
+			require.True(t, c.optimisticExec.Initialized())
+
+			res, err := c.FinalizeBlock(context.Background(), decided(tc.decided))
+			require.NoError(t, err)
+			require.Equal(t, []byte{1}, res.AppHash)
+			require.False(t, c.optimisticExec.Initialized())
+
+			executed := stf.executedHashes()
+			// the candidates are executed concurrently
+			for _, hash := range tc.executed {
+				require.Contains(t, executed, []byte(hash))
+			}
+
+			// the state of the decided block is committed
+			require.Len(t, st.committed, 1)
+			require.Equal(t, []byte(tc.decided), st.committed[0].Changes[0].StateChanges[0].Value)
+		})
+	}
+}
+
+func TestConsensusOptimisticExecutionSkipsCancelled(t *testing.T) {
+	c, stf, st := newTestConsensus(t, Config{OptimisticExecution: true, OptimisticExecutionMaxCandidates: 2})
+	hold := make(chan struct{})
+	stf.held = map[string]chan struct{}{"b": hold}
+
+	for _, hash := range []string{"a", "b"} {
+		_, err := c.ProcessProposal(context.Background(), &abci.ProcessProposalRequest{Height: 1, Hash: []byte(hash), Time: time.Unix(100, 0)})
+		require.NoError(t, err)
+	}
+	hold <- struct{}{}
+
+	// the held execution of b isn't waited for
+	_, err := c.FinalizeBlock(context.Background(), &abci.FinalizeBlockRequest{Height: 1, Hash: []byte("a"), Time: time.Unix(100, 0)})
+	require.NoError(t, err)
+	require.Len(t, st.committed, 1)
+	require.Equal(t, []byte("a"), st.committed[0].Changes[0].StateChanges[0].Value)
+
+	hold <- struct{}{}
+	require.Eventually(t, func() bool { return len(stf.executedHashes()) == 2 }, time.Second, time.Millisecond)
+	require.Len(t, st.committed, 1)
+}
+
+func TestConsensusWithoutOptimisticExecution(t *testing.T) {
+	c, stf, st := newTestConsensus(t, Config{})
+
+	_, err := c.ProcessProposal(context.Background(), &abci.ProcessProposalRequest{Height: 1, Hash: []byte("a")})
+	require.NoError(t, err)
+	require.Empty(t, stf.executedHashes())
+
+	_, err = c.FinalizeBlock(context.Background(), &abci.FinalizeBlockRequest{Height: 1, Hash: []byte("a")})
+	require.NoError(t, err)
+	require.Equal(t, [][]byte{[]byte("a")}, stf.executedHashes())
+	require.Len(t, st.committed, 1)
+}
@@ -1,10 +1,17 @@
 package cometbft
 
 import (
+	"fmt"
+	"sort"
+
 	cmtcfg "github.com/cometbft/cometbft/config"
+	"github.com/spf13/viper"
 
+	"cosmossdk.io/collections"
 	"cosmossdk.io/server/v2/api/grpc"
 	"cosmossdk.io/server/v2/cometbft/types"
+	"cosmossdk.io/server/v2/streaming"
+	"cosmossdk.io/store/v2/snapshots"
 )
 
 // Config is the configuration for the CometBFT application
@@ -17,6 +24,20 @@ type Config struct {
 	IndexEvents     map[string]struct{} `mapstructure:"index_events" toml:"index_events"`
 	HaltHeight      uint64              `mapstructure:"halt_height" toml:"halt_height"`
 	HaltTime        uint64              `mapstructure:"halt_time" toml:"halt_time"`
+	// OptimisticExecution enables the speculative execution of the proposals accepted in
+	// ProcessProposal, before they are decided.
+	OptimisticExecution bool `mapstructure:"optimistic_execution" toml:"optimistic_execution"`
+	// OptimisticExecutionMaxCandidates is the maximum number of proposals executed
+	// speculatively at the same time, 1 if not set.
+	OptimisticExecutionMaxCandidates int `mapstructure:"optimistic_execution_max_candidates" toml:"optimistic_execution_max_candidates"`
+	// SnapshotInterval is the interval, in blocks, at which the state sync snapshots are
+	// taken. Snapshots aren't taken if it is 0.
+	SnapshotInterval uint64 `mapstructure:"snapshot_interval" toml:"snapshot_interval"`
+	// SnapshotKeepRecent is the number of recent snapshots kept, all of them if 0.
+	SnapshotKeepRecent uint32 `mapstructure:"snapshot_keep_recent" toml:"snapshot_keep_recent"`
+	// SnapshotChunkedStores takes the snapshots in the chunked stores format, which the
+	// nodes not supporting it can't state sync from.
+	SnapshotChunkedStores bool `mapstructure:"snapshot_chunked_stores" toml:"snapshot_chunked_stores"`
 	// end of app.toml config options
 
 	AddrPeerFilter types.PeerFilter // filter peers by address and port
@@ -34,4 +55,145 @@ type Config struct {
 
 	// Must be set by the application to grant authority to the consensus engine to send messages to the consensus module
 	ConsensusAuthority string
+
+	// Streaming configures the listener the state changes are streamed to, read from the
+	// streaming section of app.toml.
+	Streaming streaming.StreamingConfig
+	// StreamingSchemas are the collections schemas of the modules, by store key, with which
+	// the streamed state changes are decoded. They are set by the application, and the
+	// changes aren't decoded if it is empty.
+	StreamingSchemas map[string]collections.Schema
+}
+
+// AppTomlConfig is the configuration of the CometBFT server in the cometbft section of app.toml.
+type AppTomlConfig struct {
+	MinRetainBlocks uint64   `mapstructure:"min_retain_blocks" toml:"min_retain_blocks" comment:"min_retain_blocks defines the minimum block height offset from the current block being committed, such that all blocks past this offset are pruned from CometBFT. A value of 0 indicates that no blocks should be pruned."`
+	IndexEvents     []string `mapstructure:"index_events" toml:"index_events" comment:"index_events defines the set of events in the form {eventType}.{attributeKey}, which informs CometBFT what to index. If empty, all events will be indexed."`
+	HaltHeight      uint64   `mapstructure:"halt_height" toml:"halt_height" comment:"halt_height contains a non-zero block height at which a node will gracefully halt and shutdown that can be used to assist upgrades and testing."`
+	HaltTime        uint64   `mapstructure:"halt_time" toml:"halt_time" comment:"halt_time contains a non-zero minimum block time (in Unix seconds) at which a node will gracefully halt and shutdown that can be used to assist upgrades and testing."`
+	Transport       string   `mapstructure:"transport" toml:"transport" comment:"transport defines the transport protocol of the ABCI server when the app runs standalone: socket or grpc."`
+	Addr            string   `mapstructure:"addr" toml:"addr" comment:"addr defines the listen address of the ABCI server when the app runs standalone."`
+	Standalone      bool     `mapstructure:"standalone" toml:"standalone" comment:"standalone runs the app as a standalone ABCI server, instead of embedding CometBFT in-process."`
+	Trace           bool     `mapstructure:"trace" toml:"trace" comment:"trace provides full stack traces for the errors of the ABCI queries."`
+	// OptimisticExecution enables the speculative execution of the proposals accepted in
+	// ProcessProposal, before they are decided.
+	OptimisticExecution bool `mapstructure:"optimistic_execution" toml:"optimistic_execution" comment:"optimistic_execution enables the speculative execution of the proposals accepted in ProcessProposal, before they are decided."`
+	// OptimisticExecutionMaxCandidates is the maximum number of proposals executed
+	// speculatively at the same time.
+	OptimisticExecutionMaxCandidates int `mapstructure:"optimistic_execution_max_candidates" toml:"optimistic_execution_max_candidates" comment:"optimistic_execution_max_candidates is the maximum number of proposals executed speculatively at the same time, when several proposals are received for a height."`
+	// SnapshotInterval is the interval, in blocks, at which the state sync snapshots are
+	// taken.
+	SnapshotInterval uint64 `mapstructure:"snapshot_interval" toml:"snapshot_interval" comment:"snapshot_interval specifies the block interval at which local state sync snapshots are taken (0 to disable)."`
+	// SnapshotKeepRecent is the number of recent snapshots kept.
+	SnapshotKeepRecent uint32 `mapstructure:"snapshot_keep_recent" toml:"snapshot_keep_recent" comment:"snapshot_keep_recent specifies the number of recent snapshots to keep and serve (0 to keep all)."`
+	// SnapshotChunkedStores takes the snapshots in the chunked stores format.
+	SnapshotChunkedStores bool `mapstructure:"snapshot_chunked_stores" toml:"snapshot_chunked_stores" comment:"snapshot_chunked_stores takes the snapshots in the chunked stores format, which the nodes not supporting it can't state sync from."`
+
+	// Streaming configures the listener the state changes are streamed to.
+	Streaming streaming.StreamingConfig `mapstructure:"streaming" toml:"streaming" comment:"streaming defines the configuration of the listener the state changes are streamed to."`
+}
+
+// DefaultAppTomlConfig returns the default app.toml configuration of the CometBFT server.
+func DefaultAppTomlConfig() *AppTomlConfig {
+	return &AppTomlConfig{
+		IndexEvents:                      []string{},
+		Transport:                        "socket",
+		Addr:                             "tcp://127.0.0.1:26658",
+		OptimisticExecutionMaxCandidates: 1,
+	}
+}
+
+// appTomlConfig returns the app.toml options of the configuration.
+func (c Config) appTomlConfig() *AppTomlConfig {
+	cfg := &AppTomlConfig{
+		MinRetainBlocks:                  c.MinRetainBlocks,
+		IndexEvents:                      make([]string, 0, len(c.IndexEvents)),
+		HaltHeight:                       c.HaltHeight,
+		HaltTime:                         c.HaltTime,
+		Transport:                        c.Transport,
+		Addr:                             c.Addr,
+		Standalone:                       c.Standalone,
+		Trace:                            c.Trace,
+		OptimisticExecution:              c.OptimisticExecution,
+		OptimisticExecutionMaxCandidates: c.OptimisticExecutionMaxCandidates,
+		SnapshotInterval:                 c.SnapshotInterval,
+		SnapshotKeepRecent:               c.SnapshotKeepRecent,
+		SnapshotChunkedStores:            c.SnapshotChunkedStores,
+		Streaming:                        c.Streaming,
+	}
+	for event := range c.IndexEvents {
+		cfg.IndexEvents = append(cfg.IndexEvents, event)
+	}
+	sort.Strings(cfg.IndexEvents)
+
+	defaults := DefaultAppTomlConfig()
+	if cfg.Transport == "" {
+		cfg.Transport = defaults.Transport
+	}
+	if cfg.Addr == "" {
+		cfg.Addr = defaults.Addr
+	}
+	if cfg.Streaming.ListenerConfig.Keys == nil {
+		cfg.Streaming.ListenerConfig.Keys = []string{}
+	}
+	if cfg.OptimisticExecutionMaxCandidates == 0 {
+		cfg.OptimisticExecutionMaxCandidates = defaults.OptimisticExecutionMaxCandidates
+	}
+	return cfg
+}
+
+// ReadConfig returns cfg updated with the options of the cometbft section of app.toml,
+// and with the start flags, which take precedence, read from v.
+func ReadConfig(v *viper.Viper, cfg Config) (Config, error) {
+	appTomlCfg := cfg.appTomlConfig()
+	if sub := v.Sub(serverName); sub != nil {
+		if err := sub.Unmarshal(appTomlCfg); err != nil {
+			return Config{}, fmt.Errorf("failed to unmarshal config: %w", err)
+		}
+	}
+
+	if v.IsSet(FlagHaltHeight) {
+		appTomlCfg.HaltHeight = v.GetUint64(FlagHaltHeight)
+	}
+	if v.IsSet(FlagHaltTime) {
+		appTomlCfg.HaltTime = v.GetUint64(FlagHaltTime)
+	}
+	if v.IsSet(FlagTrace) {
+		appTomlCfg.Trace = v.GetBool(FlagTrace)
+	}
+	if v.IsSet(FlagOptimisticExecution) {
+		appTomlCfg.OptimisticExecution = v.GetBool(FlagOptimisticExecution)
+	}
+	if v.IsSet(FlagOptimisticExecutionMaxCandidates) {
+		appTomlCfg.OptimisticExecutionMaxCandidates = v.GetInt(FlagOptimisticExecutionMaxCandidates)
+	}
+	if appTomlCfg.OptimisticExecutionMaxCandidates < 1 {
+		return Config{}, fmt.Errorf("%s must be at least 1, got %d", FlagOptimisticExecutionMaxCandidates, appTomlCfg.OptimisticExecutionMaxCandidates)
+	}
+
+	cfg.MinRetainBlocks = appTomlCfg.MinRetainBlocks
+	cfg.IndexEvents = make(map[string]struct{}, len(appTomlCfg.IndexEvents))
+	for _, event := range appTomlCfg.IndexEvents {
+		cfg.IndexEvents[event] = struct{}{}
+	}
+	cfg.HaltHeight = appTomlCfg.HaltHeight
+	cfg.HaltTime = appTomlCfg.HaltTime
+	cfg.Transport = appTomlCfg.Transport
+	cfg.Addr = appTomlCfg.Addr
+	cfg.Standalone = appTomlCfg.Standalone
+	cfg.Trace = appTomlCfg.Trace
+	cfg.OptimisticExecution = appTomlCfg.OptimisticExecution
+	cfg.OptimisticExecutionMaxCandidates = appTomlCfg.OptimisticExecutionMaxCandidates
+	cfg.SnapshotInterval = appTomlCfg.SnapshotInterval
+	cfg.SnapshotKeepRecent = appTomlCfg.SnapshotKeepRecent
+	cfg.SnapshotChunkedStores = appTomlCfg.SnapshotChunkedStores
+	cfg.Streaming = appTomlCfg.Streaming
+	return cfg, nil
+}
+
+// snapshotOptions returns the options of the state sync snapshots.
+func (c Config) snapshotOptions() snapshots.SnapshotOptions {
+	opts := snapshots.NewSnapshotOptions(c.SnapshotInterval, c.SnapshotKeepRecent)
+	opts.ChunkedStores = c.SnapshotChunkedStores
+	return opts
 }
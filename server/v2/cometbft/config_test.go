@@ -0,0 +1,93 @@
+package cometbft
+
+import (
+	"bytes"
+	"testing"
+
+	cmtcfg "github.com/cometbft/cometbft/config"
+	"github.com/pelletier/go-toml/v2"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+
+	"cosmossdk.io/core/log"
+	"cosmossdk.io/core/transaction"
+	"cosmossdk.io/server/v2/streaming"
+	"cosmossdk.io/store/v2/snapshots"
+)
+
+func TestReadConfig(t *testing.T) {
+	// the app.toml section written by the server is read back
+	bz, err := toml.Marshal(map[string]any{serverName: Config{HaltHeight: 10}.appTomlConfig()})
+	require.NoError(t, err)
+	require.Contains(t, string(bz), "optimistic_execution = false")
+	require.Contains(t, string(bz), "optimistic_execution_max_candidates = 1")
+
+	v := viper.New()
+	v.SetConfigType("toml")
+	require.NoError(t, v.ReadConfig(bytes.NewReader(bz)))
+	cfg, err := ReadConfig(v, Config{})
+	require.NoError(t, err)
+	require.Equal(t, uint64(10), cfg.HaltHeight)
+	require.False(t, cfg.OptimisticExecution)
+	require.Equal(t, 1, cfg.OptimisticExecutionMaxCandidates)
+
+	v.Set(serverName+".optimistic_execution", true)
+	v.Set(serverName+".optimistic_execution_max_candidates", 3)
+	cfg, err = ReadConfig(v, Config{})
+	require.NoError(t, err)
+	require.True(t, cfg.OptimisticExecution)
+	require.Equal(t, 3, cfg.OptimisticExecutionMaxCandidates)
+
+	// the start flags take precedence
+	flags := (&CometBFTServer[transaction.Tx]{}).StartCmdFlags()
+	require.NoError(t, flags.Parse([]string{"--" + FlagOptimisticExecution + "=false", "--" + FlagOptimisticExecutionMaxCandidates + "=2"}))
+	require.NoError(t, v.BindPFlags(&flags))
+	cfg, err = ReadConfig(v, Config{})
+	require.NoError(t, err)
+	require.False(t, cfg.OptimisticExecution)
+	require.Equal(t, 2, cfg.OptimisticExecutionMaxCandidates)
+
+	v.Set(FlagOptimisticExecutionMaxCandidates, 0)
+	_, err = ReadConfig(v, Config{})
+	require.ErrorContains(t, err, "optimistic-execution-max-candidates must be at least 1")
+}
+
+func TestSnapshotOptions(t *testing.T) {
+	v := viper.New()
+	v.Set(serverName+".snapshot_interval", 100)
+	v.Set(serverName+".snapshot_keep_recent", 3)
+	v.Set(serverName+".snapshot_chunked_stores", true)
+	cfg, err := ReadConfig(v, Config{CmtConfig: cmtcfg.DefaultConfig()})
+	require.NoError(t, err)
+	require.Equal(t, snapshots.SnapshotOptions{Interval: 100, KeepRecent: 3, ChunkedStores: true}, cfg.snapshotOptions())
+
+	// the server takes the snapshots with the options of app.toml
+	cfg.CmtConfig.SetRoot(t.TempDir())
+	srv := NewCometBFTServer[transaction.Tx](nil, nil, log.NewNopLogger(), cfg, nil)
+	require.Equal(t, uint64(100), srv.App.snapshotManager.GetInterval())
+	require.Equal(t, uint32(3), srv.App.snapshotManager.GetKeepRecent())
+}
+
+func TestReadStreamingConfig(t *testing.T) {
+	// the streaming section written by the server is read back
+	bz, err := toml.Marshal(map[string]any{serverName: Config{}.appTomlConfig()})
+	require.NoError(t, err)
+	require.Contains(t, string(bz), "[cometbft.streaming.listener-config]")
+
+	v := viper.New()
+	v.SetConfigType("toml")
+	require.NoError(t, v.ReadConfig(bytes.NewReader(bz)))
+	cfg, err := ReadConfig(v, Config{})
+	require.NoError(t, err)
+	require.Equal(t, streaming.ListenerConfig{Keys: []string{}}, cfg.Streaming.ListenerConfig)
+
+	v.Set(serverName+".streaming.listener-config.keys", []string{"bank"})
+	v.Set(serverName+".streaming.listener-config.stop-node-on-err", true)
+	cfg, err = ReadConfig(v, Config{})
+	require.NoError(t, err)
+	require.Equal(t, streaming.ListenerConfig{Keys: []string{"bank"}, StopNodeOnErr: true}, cfg.Streaming.ListenerConfig)
+
+	sm, err := newStreamingManager(cfg)
+	require.NoError(t, err)
+	require.True(t, sm.StopNodeOnErr)
+}
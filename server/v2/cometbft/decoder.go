@@ -0,0 +1,96 @@
+package cometbft
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	gogoproto "github.com/cosmos/gogoproto/proto"
+
+	"cosmossdk.io/collections"
+	"cosmossdk.io/server/v2/streaming"
+)
+
+var _ streaming.Decoder = (*collectionsDecoder)(nil)
+
+// collectionsDecoder decodes state changes using the collections schemas of the modules
+// owning the stores, so that consumers can interpret them without the Go code of the
+// modules.
+type collectionsDecoder struct {
+	schemas     map[string]collections.Schema
+	collections map[string]map[string]collections.Collection
+}
+
+// newCollectionsDecoder creates a decoder from the collections schemas of modules, by the
+// address of their store, i.e. their store key.
+func newCollectionsDecoder(schemas map[string]collections.Schema) *collectionsDecoder {
+	d := &collectionsDecoder{
+		schemas:     schemas,
+		collections: make(map[string]map[string]collections.Collection, len(schemas)),
+	}
+	for address, schema := range schemas {
+		d.collections[address] = make(map[string]collections.Collection)
+		for _, coll := range schema.ListCollections() {
+			d.collections[address][coll.GetName()] = coll
+		}
+	}
+	return d
+}
+
+// DecodeChangeSet sets the decoded form of the pairs of the change set. Pairs of stores
+// without a schema, or whose key doesn't belong to any collection, are left undecoded.
+// Pairs failing to decode are left undecoded too, and their errors are returned once
+// the other pairs are decoded.
+func (d *collectionsDecoder) DecodeChangeSet(changeSet []*streaming.StoreKVPair) error {
+	var errs []error
+	for _, pair := range changeSet {
+		decoded, err := d.decode(pair)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		pair.Decoded = decoded
+	}
+	return errors.Join(errs...)
+}
+
+// decode decodes a key-value pair. It returns nil if the pair can't be decoded.
+func (d *collectionsDecoder) decode(pair *streaming.StoreKVPair) (*streaming.DecodedKVPair, error) {
+	address := string(pair.Address)
+	schema, ok := d.schemas[address]
+	if !ok {
+		return nil, nil
+	}
+	entry, ok, err := schema.DecodeEntry(pair.Key, pair.Value, pair.Delete)
+	if err != nil {
+		return nil, fmt.Errorf("store %s: %w", address, err)
+	}
+	if !ok {
+		return nil, nil
+	}
+
+	decoded := &streaming.DecodedKVPair{
+		Collection: entry.Collection,
+		KeyParts:   make([]string, 0, len(entry.Key)),
+	}
+	for _, part := range entry.Key {
+		bz, err := json.Marshal(part)
+		if err != nil {
+			return nil, fmt.Errorf("store %s: collection %s: key: %w", address, entry.Collection, err)
+		}
+		decoded.KeyParts = append(decoded.KeyParts, string(bz))
+	}
+	if entry.Value == nil {
+		return decoded, nil
+	}
+
+	bz, err := d.collections[address][entry.Collection].ValueCodec().EncodeJSON(entry.Value)
+	if err != nil {
+		return nil, fmt.Errorf("store %s: collection %s: value: %w", address, entry.Collection, err)
+	}
+	decoded.ValueJson = string(bz)
+	if msg, ok := entry.Value.(gogoproto.Message); ok {
+		decoded.ValueType = gogoproto.MessageName(msg)
+	}
+	return decoded, nil
+}
@@ -0,0 +1,136 @@
+package cometbft
+
+import (
+	"context"
+	"testing"
+
+	cmtcfg "github.com/cometbft/cometbft/config"
+	"github.com/stretchr/testify/require"
+
+	"cosmossdk.io/collections"
+	"cosmossdk.io/collections/colltest"
+	"cosmossdk.io/core/store"
+	"cosmossdk.io/server/v2/streaming"
+)
+
+func TestCollectionsDecoder(t *testing.T) {
+	storeService, ctx := colltest.MockStore()
+	sb := collections.NewSchemaBuilder(storeService)
+	balances := collections.NewMap(sb, collections.NewPrefix(1), "balances", collections.PairKeyCodec(collections.StringKey, collections.StringKey), collections.Uint64Value)
+	denoms := collections.NewKeySet(sb, collections.NewPrefix(2), "denoms", collections.StringKey)
+	attribute := collections.NewItem(sb, collections.NewPrefix(3), "attribute", collections.NewJSONValueCodec[*streaming.EventAttribute]())
+	schema, err := sb.Build()
+	require.NoError(t, err)
+
+	require.NoError(t, balances.Set(ctx, collections.Join("alice", "atom"), 100))
+	require.NoError(t, denoms.Set(ctx, "atom"))
+	require.NoError(t, attribute.Set(ctx, &streaming.EventAttribute{Key: "k", Value: "v"}))
+
+	iter, err := storeService.OpenKVStore(ctx).Iterator(nil, nil)
+	require.NoError(t, err)
+	var changeSet []*streaming.StoreKVPair
+	for ; iter.Valid(); iter.Next() {
+		changeSet = append(changeSet, &streaming.StoreKVPair{Address: []byte("bank"), Key: iter.Key(), Value: iter.Value()})
+	}
+	require.NoError(t, iter.Close())
+	changeSet = append(changeSet,
+		// removals aren't decoded
+		&streaming.StoreKVPair{Address: []byte("bank"), Key: changeSet[1].Key, Delete: true},
+		// stores without a schema are left undecoded
+		&streaming.StoreKVPair{Address: []byte("gov"), Key: []byte{1}, Value: []byte{2}},
+	)
+
+	decoder := newCollectionsDecoder(map[string]collections.Schema{"bank": schema})
+	require.NoError(t, decoder.DecodeChangeSet(changeSet))
+	require.Equal(t, &streaming.DecodedKVPair{Collection: "balances", KeyParts: []string{`"alice"`, `"atom"`}, ValueJson: `"100"`}, changeSet[0].Decoded)
+	require.Equal(t, &streaming.DecodedKVPair{Collection: "denoms", KeyParts: []string{`"atom"`}}, changeSet[1].Decoded)
+	require.Equal(t, &streaming.DecodedKVPair{
+		Collection: "attribute",
+		KeyParts:   []string{},
+		ValueJson:  `{"key":"k","value":"v"}`,
+		ValueType:  "cosmos.streaming.v1.EventAttribute",
+	}, changeSet[2].Decoded)
+	require.Equal(t, &streaming.DecodedKVPair{Collection: "denoms", KeyParts: []string{`"atom"`}}, changeSet[3].Decoded)
+	require.Nil(t, changeSet[4].Decoded)
+
+	// pairs failing to decode are reported, and the other pairs are still decoded
+	changeSet = []*streaming.StoreKVPair{
+		{Address: []byte("bank"), Key: []byte{1, 0xff}, Value: []byte{}},
+		{Address: []byte("bank"), Key: changeSet[1].Key},
+	}
+	require.ErrorIs(t, decoder.DecodeChangeSet(changeSet), collections.ErrEncoding)
+	require.Nil(t, changeSet[0].Decoded)
+	require.NotNil(t, changeSet[1].Decoded)
+}
+
+func TestNewStreamingManager(t *testing.T) {
+	cfg := Config{
+		CmtConfig: cmtcfg.DefaultConfig(),
+		Streaming: streaming.StreamingConfig{ListenerConfig: streaming.ListenerConfig{StopNodeOnErr: true}},
+	}
+	sm, err := newStreamingManager(cfg)
+	require.NoError(t, err)
+	require.True(t, sm.StopNodeOnErr)
+	require.Empty(t, sm.Listeners)
+	require.Nil(t, sm.Decoder)
+
+	storeService, _ := colltest.MockStore()
+	sb := collections.NewSchemaBuilder(storeService)
+	collections.NewKeySet(sb, collections.NewPrefix(1), "denoms", collections.StringKey)
+	schema, err := sb.Build()
+	require.NoError(t, err)
+	cfg.StreamingSchemas = map[string]collections.Schema{"bank": schema}
+	sm, err = newStreamingManager(cfg)
+	require.NoError(t, err)
+	require.NotNil(t, sm.Decoder)
+}
+
+// recordingListener records the state changes streamed to it.
+type recordingListener struct {
+	changeSets [][]*streaming.StoreKVPair
+}
+
+func (l *recordingListener) ListenDeliverBlock(context.Context, streaming.ListenDeliverBlockRequest) error {
+	return nil
+}
+
+func (l *recordingListener) ListenStateChanges(_ context.Context, changeSet []*streaming.StoreKVPair) error {
+	l.changeSets = append(l.changeSets, changeSet)
+	return nil
+}
+
+func TestStreamDecodedStateChanges(t *testing.T) {
+	storeService, ctx := colltest.MockStore()
+	sb := collections.NewSchemaBuilder(storeService)
+	balances := collections.NewMap(sb, collections.NewPrefix(1), "balances", collections.PairKeyCodec(collections.StringKey, collections.StringKey), collections.Uint64Value)
+	schema, err := sb.Build()
+	require.NoError(t, err)
+	require.NoError(t, balances.Set(ctx, collections.Join("alice", "atom"), 100))
+	key, err := collections.EncodeKeyWithPrefix(collections.NewPrefix(1), balances.KeyCodec(), collections.Join("alice", "atom"))
+	require.NoError(t, err)
+	value, err := collections.Uint64Value.Encode(100)
+	require.NoError(t, err)
+
+	cfg := Config{CmtConfig: cmtcfg.DefaultConfig(), StreamingSchemas: map[string]collections.Schema{"bank": schema}}
+	c, _, _ := newTestConsensus(t, cfg)
+	sm, err := newStreamingManager(cfg)
+	require.NoError(t, err)
+	listener := &recordingListener{}
+	sm.Listeners = []streaming.Listener{listener}
+	c.SetStreamingManager(sm)
+
+	require.NoError(t, c.streamDeliverBlockChanges(context.Background(), 1, nil, nil, nil, []store.StateChanges{
+		{Actor: []byte("bank"), StateChanges: []store.KVPair{{Key: key, Value: value}, {Key: []byte{9}, Value: []byte{1}}}},
+	}))
+
+	// the decoded records are streamed with the raw pairs
+	require.Equal(t, [][]*streaming.StoreKVPair{{
+		{
+			Address: []byte("bank"),
+			Key:     key,
+			Value:   value,
+			Decoded: &streaming.DecodedKVPair{Collection: "balances", KeyParts: []string{`"alice"`, `"atom"`}, ValueJson: `"100"`},
+		},
+		{Address: []byte("bank"), Key: []byte{9}, Value: []byte{1}},
+	}}, listener.changeSets)
+}
@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"context"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+
+	"cosmossdk.io/core/store"
+
+	"github.com/cosmos/cosmos-sdk/types/voteext"
+)
+
+// VoteExtensionHandlers returns the ExtendVote and VerifyVoteExtension handlers
+// multiplexing the providers registered with the manager. Providers failing to return a
+// value are left out of the vote extension.
+func VoteExtensionHandlers(manager *voteext.Manager) (ExtendVoteHandler, VerifyVoteExtensionhandler) {
+	extendVote := func(ctx context.Context, _ store.ReaderMap, req *abci.ExtendVoteRequest) (*abci.ExtendVoteResponse, error) {
+		ext, err := manager.ExtendVote(ctx, req.Height)
+		// the error of a provider doesn't prevent the others from extending the vote
+		return &abci.ExtendVoteResponse{VoteExtension: ext}, err
+	}
+	verifyVoteExt := func(ctx context.Context, _ store.ReaderMap, req *abci.VerifyVoteExtensionRequest) (*abci.VerifyVoteExtensionResponse, error) {
+		if err := manager.VerifyVoteExtension(ctx, req.Height, req.VoteExtension); err != nil {
+			return nil, err
+		}
+		return &abci.VerifyVoteExtensionResponse{Status: abci.VERIFY_VOTE_EXTENSION_STATUS_ACCEPT}, nil
+	}
+	return extendVote, verifyVoteExt
+}
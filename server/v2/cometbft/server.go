@@ -29,6 +29,8 @@ import (
 	genutiltypes "github.com/cosmos/cosmos-sdk/x/genutil/types"
 )
 
+const serverName = "cometbft"
+
 const (
 	flagWithComet     = "with-comet"
 	flagAddress       = "address"
@@ -40,9 +42,15 @@ const (
 	FlagHaltHeight    = "halt-height"
 	FlagHaltTime      = "halt-time"
 	FlagTrace         = "trace"
+
+	FlagOptimisticExecution              = "optimistic-execution"
+	FlagOptimisticExecutionMaxCandidates = "optimistic-execution-max-candidates"
 )
 
-var _ serverv2.ServerModule = (*CometBFTServer[transaction.Tx])(nil)
+var (
+	_ serverv2.ServerModule = (*CometBFTServer[transaction.Tx])(nil)
+	_ serverv2.HasConfig    = (*CometBFTServer[transaction.Tx])(nil)
+)
 
 type CometBFTServer[T transaction.Tx] struct {
 	Node   *node.Node
@@ -90,9 +98,15 @@ func NewCometBFTServer[T transaction.Tx](
 		panic(err)
 	}
 
-	sm := snapshots.NewManager(snapshotStore, snapshots.SnapshotOptions{}, sc, ss, nil, logger) // TODO: set options somehow
+	sm := snapshots.NewManager(snapshotStore, cfg.snapshotOptions(), sc, ss, nil, logger)
 	consensus.SetSnapshotManager(sm)
 
+	streamingManager, err := newStreamingManager(cfg)
+	if err != nil {
+		panic(err)
+	}
+	consensus.SetStreamingManager(streamingManager)
+
 	return &CometBFTServer[T]{
 		logger: logger,
 		App:    consensus,
@@ -101,7 +115,12 @@ func NewCometBFTServer[T transaction.Tx](
 }
 
 func (s *CometBFTServer[T]) Name() string {
-	return "cometbft"
+	return serverName
+}
+
+// Config returns the options of the server written to the cometbft section of app.toml.
+func (s *CometBFTServer[T]) Config() any {
+	return s.config.appTomlConfig()
 }
 
 func (s *CometBFTServer[T]) Start(ctx context.Context) error {
@@ -148,6 +167,10 @@ func (s *CometBFTServer[T]) Start(ctx context.Context) error {
 
 func (s *CometBFTServer[T]) Stop(_ context.Context) error {
 	defer s.cleanupFn()
+	if s.App != nil {
+		// wait for the optimistic executions reading the store before it's closed
+		s.App.optimisticExec.Abort()
+	}
 	if s.Node != nil {
 		return s.Node.Stop()
 	}
@@ -204,6 +227,8 @@ func (s *CometBFTServer[T]) StartCmdFlags() pflag.FlagSet {
 	flags.Uint64(FlagHaltTime, 0, "Minimum block time (in Unix seconds) at which to gracefully halt the chain and shutdown the node")
 	flags.String(flagCPUProfile, "", "Enable CPU profiling and write to the provided file")
 	flags.Bool(FlagTrace, false, "Provide full stack traces for errors in ABCI Log")
+	flags.Bool(FlagOptimisticExecution, false, "Execute the proposals accepted in ProcessProposal speculatively, before they are decided")
+	flags.Int(FlagOptimisticExecutionMaxCandidates, 1, "Maximum number of proposals executed speculatively at the same time")
 	return flags
 }
 
@@ -2,6 +2,7 @@ package cometbft
 
 import (
 	"context"
+	"fmt"
 
 	coreappmgr "cosmossdk.io/core/app"
 	"cosmossdk.io/core/event"
@@ -9,6 +10,29 @@ import (
 	"cosmossdk.io/server/v2/streaming"
 )
 
+// newStreamingManager creates the streaming manager configured by cfg. The plugin of the
+// listener is loaded if set, and the state changes are decoded with the collections
+// schemas of the modules if any is given.
+func newStreamingManager(cfg Config) (streaming.Manager, error) {
+	listenerCfg := cfg.Streaming.ListenerConfig
+	sm := streaming.Manager{StopNodeOnErr: listenerCfg.StopNodeOnErr}
+	if listenerCfg.Plugin != "" {
+		plugin, err := streaming.NewStreamingPlugin(listenerCfg.Plugin, cfg.CmtConfig.LogLevel)
+		if err != nil {
+			return streaming.Manager{}, fmt.Errorf("failed to load streaming plugin %s: %w", listenerCfg.Plugin, err)
+		}
+		listener, ok := plugin.(streaming.Listener)
+		if !ok {
+			return streaming.Manager{}, fmt.Errorf("streaming plugin %s does not implement Listener", listenerCfg.Plugin)
+		}
+		sm.Listeners = []streaming.Listener{listener}
+	}
+	if len(cfg.StreamingSchemas) > 0 {
+		sm.Decoder = newCollectionsDecoder(cfg.StreamingSchemas)
+	}
+	return sm, nil
+}
+
 // streamDeliverBlockChanges will stream all the changes happened during deliver block.
 func (c *Consensus[T]) streamDeliverBlockChanges(
 	ctx context.Context,
@@ -33,6 +57,18 @@ func (c *Consensus[T]) streamDeliverBlockChanges(
 		}
 	}
 
+	if len(c.streaming.Listeners) == 0 {
+		return nil
+	}
+
+	changeSet := intoStreamingKVPairs(stateChanges)
+	if c.streaming.Decoder != nil {
+		if err := c.streaming.Decoder.DecodeChangeSet(changeSet); err != nil {
+			// the raw state changes are still streamed
+			c.logger.Error("failed to decode state changes", "height", height, "err", err)
+		}
+	}
+
 	for _, streamingListener := range c.streaming.Listeners {
 		if err := streamingListener.ListenDeliverBlock(ctx, streaming.ListenDeliverBlockRequest{
 			BlockHeight: height,
@@ -43,7 +79,7 @@ func (c *Consensus[T]) streamDeliverBlockChanges(
 			c.logger.Error("ListenDeliverBlock listening hook failed", "height", height, "err", err)
 		}
 
-		if err := streamingListener.ListenStateChanges(ctx, intoStreamingKVPairs(stateChanges)); err != nil {
+		if err := streamingListener.ListenStateChanges(ctx, changeSet); err != nil {
 			c.logger.Error("ListenStateChanges listening hook failed", "height", height, "err", err)
 		}
 	}
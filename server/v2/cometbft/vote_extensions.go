@@ -0,0 +1,90 @@
+package cometbft
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+	cmttypes "github.com/cometbft/cometbft/types"
+
+	"cosmossdk.io/server/v2/cometbft/handlers"
+
+	"github.com/cosmos/cosmos-sdk/types/voteext"
+)
+
+// SetVoteExtensions sets the manager multiplexing the vote extensions of the providers
+// registered with it, and the validator store verifying the signatures of the vote
+// extensions. The results aggregated from the vote extensions of the last commit are
+// injected as the first transaction of the proposals, and verified when processing them.
+// When finalizing a block, the injected pseudo-transaction is carried by the context
+// instead of being executed, so that the PreBlock of a module applies the results with
+// voteext.Manager.ApplyFromContext.
+func (c *Consensus[T]) SetVoteExtensions(manager *voteext.Manager, valStore voteext.ValidatorStore) {
+	c.voteExtensions = manager
+	c.valStore = valStore
+	c.extendVote, c.verifyVoteExt = handlers.VoteExtensionHandlers(manager)
+}
+
+// buildInjectedTx returns the pseudo-transaction injecting the results aggregated from
+// the vote extensions of the last commit, or nil if the proposal doesn't carry them.
+func (c *Consensus[T]) buildInjectedTx(ctx context.Context, req *abci.PrepareProposalRequest) ([]byte, error) {
+	if c.voteExtensions == nil {
+		return nil, nil
+	}
+	cp, err := c.GetConsensusParams(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !voteext.InProposal(cp, req.Height) {
+		return nil, nil
+	}
+
+	if err := voteext.VerifyExtendedCommit(ctx, c.valStore, c.chainID, req.Height, req.LocalLastCommit); err != nil {
+		return nil, fmt.Errorf("invalid vote extensions: %w", err)
+	}
+	injectedTx, err := c.voteExtensions.BuildInjectedTx(ctx, req.LocalLastCommit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate vote extensions: %w", err)
+	}
+	if size := cmttypes.ComputeProtoSizeForTxs([]cmttypes.Tx{injectedTx}); size > req.MaxTxBytes {
+		return nil, fmt.Errorf("injected vote extensions exceed the max tx bytes: %d > %d", size, req.MaxTxBytes)
+	}
+	return injectedTx, nil
+}
+
+// verifyInjectedTx verifies the results injected as the first transaction of the
+// proposal, if it must carry them, and returns the other transactions.
+func (c *Consensus[T]) verifyInjectedTx(ctx context.Context, req *abci.ProcessProposalRequest) ([][]byte, error) {
+	if c.voteExtensions == nil {
+		return req.Txs, nil
+	}
+	cp, err := c.GetConsensusParams(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !voteext.InProposal(cp, req.Height) {
+		return req.Txs, nil
+	}
+
+	if len(req.Txs) == 0 || !voteext.IsInjectedTx(req.Txs[0]) {
+		return nil, errors.New("proposal without injected vote extensions")
+	}
+	extCommit, err := c.voteExtensions.VerifyInjectedTx(ctx, req.Txs[0])
+	if err != nil {
+		return nil, err
+	}
+	if err := voteext.VerifyExtendedCommit(ctx, c.valStore, c.chainID, req.Height, extCommit); err != nil {
+		return nil, err
+	}
+	return req.Txs[1:], nil
+}
+
+// withInjectedTx returns the context carrying the pseudo-transaction of the block, if
+// any, and the other transactions.
+func (c *Consensus[T]) withInjectedTx(ctx context.Context, txs [][]byte) (context.Context, [][]byte, bool) {
+	if c.voteExtensions == nil || len(txs) == 0 || !voteext.IsInjectedTx(txs[0]) {
+		return ctx, txs, false
+	}
+	return voteext.ContextWithInjectedTx(ctx, txs[0]), txs[1:], true
+}
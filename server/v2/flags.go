@@ -2,10 +2,13 @@ package serverv2
 
 const (
 	// Logging flags
-	FlagLogLevel   = "log_level"
-	FlagLogFormat  = "log_format"
-	FlagLogNoColor = "log_no_color"
-	FlagTrace      = "trace"
+	FlagLogLevel        = "log_level"
+	FlagLogFormat       = "log_format"
+	FlagLogNoColor      = "log_no_color"
+	FlagLogRedact       = "log_redact"
+	FlagLogSampleBurst  = "log_sample_burst"
+	FlagLogSamplePeriod = "log_sample_period"
+	FlagTrace           = "trace"
 
 	OutputFormatJSON = "json"
 )
@@ -1,9 +1,11 @@
 package serverv2
 
 import (
+	"errors"
 	"io"
+	"net/http"
 
-	"github.com/rs/zerolog"
+	"github.com/gorilla/mux"
 	"github.com/spf13/viper"
 
 	"cosmossdk.io/log"
@@ -20,25 +22,37 @@ func NewLogger(v *viper.Viper, out io.Writer) (log.Logger, error) {
 		log.ColorOption(!v.GetBool(FlagLogNoColor)),
 		log.TraceOption(v.GetBool(FlagTrace)))
 
-	// check and set filter level or keys for the logger if any
+	if patterns := v.GetStringSlice(FlagLogRedact); len(patterns) > 0 {
+		opts = append(opts, log.RedactOption(patterns...))
+	}
+	if burst := v.GetUint32(FlagLogSampleBurst); burst > 0 {
+		opts = append(opts, log.SamplingOption(burst, v.GetDuration(FlagLogSamplePeriod)))
+	}
+
+	// check and set the level or the per-module levels of the logger if any, which
+	// can be changed at runtime
 	logLvlStr := v.GetString(FlagLogLevel)
 	if logLvlStr == "" {
 		return log.NewLogger(out, opts...), nil
 	}
 
-	logLvl, err := zerolog.ParseLevel(logLvlStr)
-	switch {
-	case err != nil:
-		// If the log level is not a valid zerolog level, then we try to parse it as a key filter.
-		filterFunc, err := log.ParseLogLevel(logLvlStr)
-		if err != nil {
-			return nil, err
-		}
-
-		opts = append(opts, log.FilterOption(filterFunc))
-	default:
-		opts = append(opts, log.LevelOption(logLvl))
+	levelFilter, err := log.NewLevelFilter(logLvlStr)
+	if err != nil {
+		return nil, err
 	}
+	opts = append(opts, log.LevelFilterOption(levelFilter))
 
 	return log.NewLogger(out, opts...), nil
 }
+
+// RegisterLogLevel registers on the router the /log/level endpoint, reading and
+// changing at runtime the per-module levels of a logger created by NewLogger.
+func RegisterLogLevel(r *mux.Router, logger log.Logger) error {
+	levelFilter, ok := log.GetLevelFilter(logger)
+	if !ok {
+		return errors.New("the log levels of the logger can't be changed at runtime")
+	}
+
+	r.Handle("/log/level", levelFilter).Methods(http.MethodGet, http.MethodPut, http.MethodPost)
+	return nil
+}
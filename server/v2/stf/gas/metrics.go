@@ -0,0 +1,37 @@
+package gas
+
+import (
+	"sync/atomic"
+
+	"github.com/hashicorp/go-metrics"
+)
+
+// metricsLabels holds the global labels of the read and write byte counters of the
+// stores. The counters are only emitted once EnableMetrics was called.
+var metricsLabels atomic.Pointer[[]metrics.Label]
+
+// EnableMetrics enables the counters of the bytes read and written through the gas
+// metered stores, labelled by store key, with the given global labels.
+func EnableMetrics(labels []metrics.Label) {
+	metricsLabels.Store(&labels)
+}
+
+// DisableMetrics disables the counters of the bytes read and written through the
+// gas metered stores.
+func DisableMetrics() {
+	metricsLabels.Store(nil)
+}
+
+// incrBytes increments the byte counter of the given operation of a store, if
+// metrics are enabled.
+func incrBytes(storeName, op string, n int) {
+	labels := metricsLabels.Load()
+	if labels == nil || n == 0 {
+		return
+	}
+	metrics.IncrCounterWithLabels(
+		[]string{"store", "gaskv", op + "_bytes"},
+		float32(n),
+		append([]metrics.Label{{Name: "store_key", Value: storeName}}, *labels...),
+	)
+}
@@ -27,13 +27,21 @@ type Store struct {
 	parent    store.Writer
 	gasMeter  gas.Meter
 	gasConfig StoreConfig
+	name      string
 }
 
 func NewStore(gc StoreConfig, meter gas.Meter, parent store.Writer) *Store {
+	return NewStoreWithName(gc, meter, parent, "")
+}
+
+// NewStoreWithName returns a gas metered store whose bytes read and written are
+// counted under the given store key name.
+func NewStoreWithName(gc StoreConfig, meter gas.Meter, parent store.Writer, name string) *Store {
 	return &Store{
 		parent:    parent,
 		gasMeter:  meter,
 		gasConfig: gc,
+		name:      name,
 	}
 }
 
@@ -49,6 +57,7 @@ func (s *Store) Get(key []byte) ([]byte, error) {
 	if err := s.gasMeter.Consume(s.gasConfig.ReadCostPerByte*gas.Gas(len(value)), DescReadPerByte); err != nil {
 		return nil, err
 	}
+	incrBytes(s.name, "read", len(key)+len(value))
 
 	return value, err
 }
@@ -71,6 +80,7 @@ func (s *Store) Set(key, value []byte) error {
 	if err := s.gasMeter.Consume(s.gasConfig.WriteCostPerByte*gas.Gas(len(value)), DescWritePerByte); err != nil {
 		return err
 	}
+	incrBytes(s.name, "write", len(key)+len(value))
 
 	return s.parent.Set(key, value)
 }
@@ -97,7 +107,7 @@ func (s *Store) Iterator(start, end []byte) (store.Iterator, error) {
 		return nil, err
 	}
 
-	return newIterator(itr, s.gasMeter, s.gasConfig), nil
+	return newIterator(itr, s.gasMeter, s.gasConfig, s.name), nil
 }
 
 func (s *Store) ReverseIterator(start, end []byte) (store.Iterator, error) {
@@ -106,7 +116,7 @@ func (s *Store) ReverseIterator(start, end []byte) (store.Iterator, error) {
 		return nil, err
 	}
 
-	return newIterator(itr, s.gasMeter, s.gasConfig), nil
+	return newIterator(itr, s.gasMeter, s.gasConfig, s.name), nil
 }
 
 var _ store.Iterator = (*iterator)(nil)
@@ -115,13 +125,15 @@ type iterator struct {
 	gasMeter  gas.Meter
 	gasConfig StoreConfig
 	parent    store.Iterator
+	name      string
 }
 
-func newIterator(parent store.Iterator, gm gas.Meter, gc StoreConfig) store.Iterator {
+func newIterator(parent store.Iterator, gm gas.Meter, gc StoreConfig, name string) store.Iterator {
 	return &iterator{
 		parent:    parent,
 		gasConfig: gc,
 		gasMeter:  gm,
+		name:      name,
 	}
 }
 
@@ -172,6 +184,7 @@ func (itr *iterator) consumeGasSeek() error {
 		if err := itr.gasMeter.Consume(itr.gasConfig.ReadCostPerByte*gas.Gas(len(value)), DescValuePerByte); err != nil {
 			return err
 		}
+		incrBytes(itr.name, "read", len(key)+len(value))
 	}
 
 	if err := itr.gasMeter.Consume(itr.gasConfig.IterNextCostFlat, DescIterNextCostFlat); err != nil {
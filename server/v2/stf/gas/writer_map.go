@@ -39,7 +39,7 @@ func (m MeteredWriterMap) GetWriter(actor []byte) (store.Writer, error) {
 		return nil, err
 	}
 
-	meteredState := NewStore(m.config, m.meter, state)
+	meteredState := NewStoreWithName(m.config, m.meter, state, string(actor))
 	m.cacheMeteredStores[string(actor)] = meteredState
 
 	return meteredState, nil
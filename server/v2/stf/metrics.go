@@ -0,0 +1,67 @@
+package stf
+
+import (
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/hashicorp/go-metrics"
+
+	stfgas "cosmossdk.io/server/v2/stf/gas"
+)
+
+// metricsLabels holds the global labels of the metrics of the messages. The metrics
+// are only emitted once EnableMetrics was called.
+var metricsLabels atomic.Pointer[[]metrics.Label]
+
+// versionSegment matches the version segment of a protobuf package, e.g. v1beta1.
+var versionSegment = regexp.MustCompile(`^v\d+((alpha|beta)\d*)?$`)
+
+// EnableMetrics enables the gas used and latency metrics of the messages, and the
+// read and write byte counters of the gas metered stores, with the given global
+// labels.
+func EnableMetrics(labels []metrics.Label) {
+	metricsLabels.Store(&labels)
+	stfgas.EnableMetrics(labels)
+}
+
+// DisableMetrics disables the metrics of the messages and of the stores.
+func DisableMetrics() {
+	metricsLabels.Store(nil)
+	stfgas.DisableMetrics()
+}
+
+// measureMsg emits the gas used and the execution latency of a message, labelled by
+// its type URL, its module and whether it succeeded, if metrics are enabled.
+func measureMsg(typeURL string, start time.Time, gasUsed uint64, err error) {
+	globalLabels := metricsLabels.Load()
+	if globalLabels == nil {
+		return
+	}
+
+	status := "success"
+	if err != nil {
+		status = "failure"
+	}
+	labels := append([]metrics.Label{
+		{Name: "msg_type", Value: typeURL},
+		{Name: "module", Value: msgModule(typeURL)},
+		{Name: "status", Value: status},
+	}, *globalLabels...)
+
+	metrics.AddSampleWithLabels([]string{"msg", "gas_used"}, float32(gasUsed), labels)
+	metrics.MeasureSinceWithLabels([]string{"msg", "latency"}, start.UTC(), labels)
+}
+
+// msgModule returns the module of a message from its type URL, being the last
+// segment of its protobuf package which isn't a version.
+func msgModule(typeURL string) string {
+	segments := strings.Split(strings.TrimPrefix(typeURL, "/"), ".")
+	for i := len(segments) - 2; i >= 0; i-- {
+		if !versionSegment.MatchString(segments[i]) {
+			return segments[i]
+		}
+	}
+	return ""
+}
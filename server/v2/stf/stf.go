@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	appmanager "cosmossdk.io/core/app"
 	appmodulev2 "cosmossdk.io/core/appmodule/v2"
@@ -301,7 +302,7 @@ func (s STF[T]) runTxMsgs(
 	execCtx.setGasLimit(gasLimit)
 	for i, msg := range msgs {
 		execCtx.sender = txSenders[i]
-		resp, err := s.handleMsg(execCtx, msg)
+		resp, err := s.runTxMsg(execCtx, msg)
 		if err != nil {
 			return nil, 0, nil, fmt.Errorf("message execution at index %d failed: %w", i, err)
 		}
@@ -312,6 +313,21 @@ func (s STF[T]) runTxMsgs(
 	return msgResps, consumed, execCtx.events, nil
 }
 
+// runTxMsg executes a message of a transaction, emitting its gas used and latency
+// if metrics are enabled. Queries and consensus messages are not measured.
+func (s STF[T]) runTxMsg(ctx *executionContext, msg transaction.Msg) (resp transaction.Msg, err error) {
+	if metricsLabels.Load() == nil {
+		return s.handleMsg(ctx, msg)
+	}
+
+	start := time.Now()
+	gasBefore := ctx.meter.Limit() - ctx.meter.Remaining()
+	defer func() {
+		measureMsg(msgTypeURL(msg), start, ctx.meter.Limit()-ctx.meter.Remaining()-gasBefore, err)
+	}()
+	return s.handleMsg(ctx, msg)
+}
+
 func (s STF[T]) preBlock(
 	ctx *executionContext,
 	txs []T,
@@ -7,6 +7,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/hashicorp/go-metrics"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/protobuf/types/known/wrapperspb"
 
@@ -205,6 +206,59 @@ func TestSTF(t *testing.T) {
 		stateNotHas(t, newState, "validate")
 		stateNotHas(t, newState, "exec")
 	})
+
+	t.Run("message metrics", func(t *testing.T) {
+		sink := metrics.NewInmemSink(time.Minute, time.Minute)
+		_, err := metrics.NewGlobal(metrics.DefaultConfig("test"), sink)
+		require.NoError(t, err)
+		EnableMetrics(nil)
+		t.Cleanup(DisableMetrics)
+
+		router := NewMsgRouterBuilder()
+		require.NoError(t, router.RegisterHandler(msgTypeURL(mockTx.Msg), func(ctx context.Context, msg appmodulev2.Message) (appmodulev2.Message, error) {
+			kvSet(t, ctx, "exec")
+			return nil, nil
+		}))
+		s := s.clone()
+		s.handleMsg, err = router.Build()
+		require.NoError(t, err)
+
+		queryRouter := NewMsgRouterBuilder()
+		require.NoError(t, queryRouter.RegisterHandler(msgTypeURL(&wrapperspb.StringValue{}), func(ctx context.Context, msg appmodulev2.Message) (appmodulev2.Message, error) {
+			return msg, nil
+		}))
+		s.handleQuery, err = queryRouter.Build()
+		require.NoError(t, err)
+		_, err = s.Query(context.Background(), state, 1000, &wrapperspb.StringValue{})
+		require.NoError(t, err)
+
+		result, _, err := s.DeliverBlock(context.Background(), &appmanager.BlockRequest[mock.Tx]{
+			Height:  uint64(1),
+			Time:    time.Date(2024, 2, 3, 18, 23, 0, 0, time.UTC),
+			AppHash: sum[:],
+			Hash:    sum[:],
+			Txs:     []mock.Tx{mockTx},
+		}, state)
+		require.NoError(t, err)
+		require.NoError(t, result.TxResults[0].Error)
+
+		data := sink.Data()[0]
+		gasUsed := data.Samples["test.msg.gas_used;msg_type=google.protobuf.BoolValue;module=protobuf;status=success"]
+		require.Equal(t, 1, gasUsed.Count)
+		require.NotZero(t, gasUsed.Sum)
+		require.Contains(t, data.Samples, "test.msg.latency;msg_type=google.protobuf.BoolValue;module=protobuf;status=success")
+		// queries are not measured
+		require.Len(t, data.Samples, 2)
+		// "validate" and "exec" are written as both the key and the value by the metered tx
+		require.Equal(t, 24.0, data.Counters["test.store.gaskv.write_bytes;store_key=cookies"].Sum)
+	})
+}
+
+func TestMsgModule(t *testing.T) {
+	require.Equal(t, "bank", msgModule("/cosmos.bank.v1beta1.MsgSend"))
+	require.Equal(t, "lockup", msgModule("/cosmos.accounts.defaults.lockup.v1.MsgDelegate"))
+	require.Equal(t, "testpb", msgModule("/testpb.MsgCounter"))
+	require.Equal(t, "", msgModule("/MsgCounter"))
 }
 
 var actorName = []byte("cookies")
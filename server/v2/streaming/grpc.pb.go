@@ -242,6 +242,10 @@ type StoreKVPair struct {
 	Value []byte `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"`
 	// delete defines if the key was removed.
 	Delete bool `protobuf:"varint,4,opt,name=delete,proto3" json:"delete,omitempty"`
+	// decoded defines the key-value pair decoded using the collections schema of the module
+	// owning the store. It is only set when the streaming manager decodes the state changes,
+	// and the key belongs to a collection.
+	Decoded *DecodedKVPair `protobuf:"bytes,5,opt,name=decoded,proto3" json:"decoded,omitempty"`
 }
 
 func (m *StoreKVPair) Reset()         { *m = StoreKVPair{} }
@@ -305,6 +309,88 @@ func (m *StoreKVPair) GetDelete() bool {
 	return false
 }
 
+func (m *StoreKVPair) GetDecoded() *DecodedKVPair {
+	if m != nil {
+		return m.Decoded
+	}
+	return nil
+}
+
+// DecodedKVPair is a key-value pair decoded using the collections schema of a module.
+type DecodedKVPair struct {
+	// collection defines the name of the collection the key belongs to.
+	Collection string `protobuf:"bytes,1,opt,name=collection,proto3" json:"collection,omitempty"`
+	// key_parts defines the JSON encoded parts of the key: one for simple keys, one per
+	// part for multipart keys, and none for items.
+	KeyParts []string `protobuf:"bytes,2,rep,name=key_parts,json=keyParts,proto3" json:"key_parts,omitempty"`
+	// value_json defines the JSON encoded value, empty in case of removal and for key sets.
+	ValueJson string `protobuf:"bytes,3,opt,name=value_json,json=valueJson,proto3" json:"value_json,omitempty"`
+	// value_type defines the full name of the protobuf message of the value, empty if the
+	// value isn't a protobuf message.
+	ValueType string `protobuf:"bytes,4,opt,name=value_type,json=valueType,proto3" json:"value_type,omitempty"`
+}
+
+func (m *DecodedKVPair) Reset()         { *m = DecodedKVPair{} }
+func (m *DecodedKVPair) String() string { return proto.CompactTextString(m) }
+func (*DecodedKVPair) ProtoMessage()    {}
+func (*DecodedKVPair) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3fc151d30622bb2a, []int{5}
+}
+func (m *DecodedKVPair) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *DecodedKVPair) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_DecodedKVPair.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *DecodedKVPair) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DecodedKVPair.Merge(m, src)
+}
+func (m *DecodedKVPair) XXX_Size() int {
+	return m.Size()
+}
+func (m *DecodedKVPair) XXX_DiscardUnknown() {
+	xxx_messageInfo_DecodedKVPair.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DecodedKVPair proto.InternalMessageInfo
+
+func (m *DecodedKVPair) GetCollection() string {
+	if m != nil {
+		return m.Collection
+	}
+	return ""
+}
+
+func (m *DecodedKVPair) GetKeyParts() []string {
+	if m != nil {
+		return m.KeyParts
+	}
+	return nil
+}
+
+func (m *DecodedKVPair) GetValueJson() string {
+	if m != nil {
+		return m.ValueJson
+	}
+	return ""
+}
+
+func (m *DecodedKVPair) GetValueType() string {
+	if m != nil {
+		return m.ValueType
+	}
+	return ""
+}
+
 // Event is a single event, associated with a transaction.
 type Event struct {
 	Type       string            `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
@@ -315,7 +401,7 @@ func (m *Event) Reset()         { *m = Event{} }
 func (m *Event) String() string { return proto.CompactTextString(m) }
 func (*Event) ProtoMessage()    {}
 func (*Event) Descriptor() ([]byte, []int) {
-	return fileDescriptor_3fc151d30622bb2a, []int{5}
+	return fileDescriptor_3fc151d30622bb2a, []int{6}
 }
 func (m *Event) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -368,7 +454,7 @@ func (m *EventAttribute) Reset()         { *m = EventAttribute{} }
 func (m *EventAttribute) String() string { return proto.CompactTextString(m) }
 func (*EventAttribute) ProtoMessage()    {}
 func (*EventAttribute) Descriptor() ([]byte, []int) {
-	return fileDescriptor_3fc151d30622bb2a, []int{6}
+	return fileDescriptor_3fc151d30622bb2a, []int{7}
 }
 func (m *EventAttribute) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -427,7 +513,7 @@ func (m *ExecTxResult) Reset()         { *m = ExecTxResult{} }
 func (m *ExecTxResult) String() string { return proto.CompactTextString(m) }
 func (*ExecTxResult) ProtoMessage()    {}
 func (*ExecTxResult) Descriptor() ([]byte, []int) {
-	return fileDescriptor_3fc151d30622bb2a, []int{7}
+	return fileDescriptor_3fc151d30622bb2a, []int{8}
 }
 func (m *ExecTxResult) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -518,6 +604,7 @@ func init() {
 	proto.RegisterType((*ListenStateChangesRequest)(nil), "cosmos.streaming.v1.ListenStateChangesRequest")
 	proto.RegisterType((*ListenStateChangesResponse)(nil), "cosmos.streaming.v1.ListenStateChangesResponse")
 	proto.RegisterType((*StoreKVPair)(nil), "cosmos.streaming.v1.StoreKVPair")
+	proto.RegisterType((*DecodedKVPair)(nil), "cosmos.streaming.v1.DecodedKVPair")
 	proto.RegisterType((*Event)(nil), "cosmos.streaming.v1.Event")
 	proto.RegisterType((*EventAttribute)(nil), "cosmos.streaming.v1.EventAttribute")
 	proto.RegisterType((*ExecTxResult)(nil), "cosmos.streaming.v1.ExecTxResult")
@@ -526,45 +613,51 @@ func init() {
 func init() { proto.RegisterFile("cosmos/streaming/v1/grpc.proto", fileDescriptor_3fc151d30622bb2a) }
 
 var fileDescriptor_3fc151d30622bb2a = []byte{
-	// 599 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x54, 0xcd, 0x6e, 0xd3, 0x40,
-	0x10, 0xae, 0x9b, 0x36, 0xa9, 0x27, 0xe1, 0x47, 0x0b, 0x42, 0x6e, 0x55, 0x2c, 0x37, 0x5c, 0x72,
-	0x72, 0xd4, 0x70, 0x41, 0x5c, 0x80, 0x16, 0xa4, 0x4a, 0x70, 0x40, 0x1b, 0x7e, 0x24, 0x2e, 0x61,
-	0x6b, 0x0f, 0x8e, 0x55, 0xd7, 0x36, 0x3b, 0x6b, 0x93, 0xbe, 0x05, 0x67, 0x1e, 0x08, 0x71, 0xec,
-	0x91, 0x23, 0x6a, 0x2f, 0xbc, 0x05, 0x68, 0xd7, 0x6e, 0x93, 0x0a, 0xb7, 0x2a, 0xb7, 0x6f, 0x7e,
-	0xbf, 0x6f, 0x3c, 0xe3, 0x05, 0x37, 0xc8, 0xe8, 0x30, 0xa3, 0x21, 0x29, 0x89, 0xe2, 0x30, 0x4e,
-	0xa3, 0x61, 0xb9, 0x3d, 0x8c, 0x64, 0x1e, 0xf8, 0xb9, 0xcc, 0x54, 0xc6, 0xee, 0x54, 0x71, 0xff,
-	0x3c, 0xee, 0x97, 0xdb, 0xfd, 0xef, 0x16, 0xac, 0xbf, 0x8a, 0x49, 0x61, 0xfa, 0x1c, 0x93, 0xb8,
-	0x44, 0xb9, 0x93, 0x64, 0xc1, 0x01, 0xc7, 0xcf, 0x05, 0x92, 0x62, 0x5b, 0xd0, 0xdb, 0xd7, 0xf6,
-	0x64, 0x8a, 0x71, 0x34, 0x55, 0x8e, 0xe5, 0x59, 0x83, 0x16, 0xef, 0x1a, 0xdf, 0x9e, 0x71, 0xb1,
-	0xdb, 0xd0, 0x52, 0x33, 0x72, 0x96, 0xbd, 0xd6, 0xa0, 0xc7, 0x35, 0x64, 0x23, 0x68, 0x63, 0x89,
-	0xa9, 0x22, 0xa7, 0xe5, 0xb5, 0x06, 0xdd, 0xd1, 0x86, 0xdf, 0x40, 0xec, 0xbf, 0xd0, 0x29, 0xbc,
-	0xce, 0x64, 0x4f, 0x01, 0xd4, 0x6c, 0x22, 0x91, 0x8a, 0x44, 0x91, 0xb3, 0x62, 0xea, 0xb6, 0x9a,
-	0xeb, 0x66, 0x18, 0xbc, 0x99, 0x71, 0x93, 0xc9, 0x6d, 0x55, 0x23, 0xea, 0x6f, 0xc2, 0x46, 0xd3,
-	0x1c, 0x94, 0x67, 0x29, 0x61, 0xff, 0xdb, 0xf9, 0x98, 0x63, 0x25, 0x14, 0xee, 0x4e, 0x45, 0x1a,
-	0x21, 0xfd, 0xc7, 0x98, 0x4f, 0x00, 0x02, 0x53, 0x34, 0x21, 0x54, 0x66, 0xda, 0xee, 0xc8, 0x6b,
-	0x14, 0x38, 0x56, 0x99, 0xc4, 0x97, 0xef, 0x5e, 0x8b, 0x58, 0x72, 0xbb, 0xaa, 0x19, 0xa3, 0x62,
-	0xeb, 0xb0, 0x26, 0xf2, 0x7c, 0x32, 0x15, 0x34, 0x75, 0x5a, 0x9e, 0x35, 0xe8, 0xf1, 0x8e, 0xc8,
-	0xf3, 0x3d, 0x41, 0xd3, 0xb9, 0xf4, 0x8b, 0xda, 0x6a, 0xe9, 0x11, 0x74, 0x17, 0x5a, 0x32, 0x07,
-	0x3a, 0x22, 0x0c, 0x25, 0x12, 0x19, 0x99, 0xba, 0x4d, 0x65, 0xea, 0x4d, 0x1c, 0xe0, 0x91, 0xb3,
-	0x6c, 0xbc, 0x1a, 0xb2, 0xbb, 0xb0, 0x5a, 0x8a, 0xa4, 0xc0, 0x9a, 0xb0, 0x32, 0xd8, 0x3d, 0x68,
-	0x87, 0x98, 0xa0, 0x42, 0x67, 0xc5, 0xb3, 0x06, 0x6b, 0xbc, 0xb6, 0xfa, 0x1f, 0x61, 0xd5, 0x2c,
-	0x85, 0x31, 0x58, 0x51, 0x47, 0x39, 0x9a, 0xfe, 0x36, 0x37, 0x98, 0xed, 0x02, 0x08, 0xa5, 0x64,
-	0xbc, 0x5f, 0x28, 0xa4, 0x7a, 0xfe, 0x07, 0x97, 0x2f, 0xf6, 0xd9, 0x59, 0x2e, 0x5f, 0x28, 0xeb,
-	0x3f, 0x82, 0x9b, 0x17, 0xa3, 0x67, 0x9a, 0x2b, 0xa6, 0x8b, 0x9a, 0x97, 0x8d, 0xaf, 0x32, 0xfa,
-	0xbf, 0x2d, 0xe8, 0x2d, 0x6e, 0x5e, 0x6b, 0x0c, 0xb2, 0xb0, 0xd2, 0x78, 0x83, 0x1b, 0xac, 0x7d,
-	0xa1, 0x50, 0xa2, 0xfe, 0x02, 0x06, 0x6b, 0x82, 0x24, 0x8b, 0xcc, 0x07, 0xb0, 0xb9, 0x86, 0x3a,
-	0x2b, 0x4e, 0x3f, 0x65, 0x66, 0x78, 0x9b, 0x1b, 0xcc, 0xee, 0x03, 0x44, 0x82, 0x26, 0x5f, 0x44,
-	0xaa, 0x30, 0x74, 0x56, 0xcd, 0xfa, 0xed, 0x48, 0xd0, 0x7b, 0xe3, 0xd0, 0xbb, 0xd3, 0xe1, 0x82,
-	0x30, 0x74, 0xda, 0x26, 0xd8, 0x89, 0x04, 0xbd, 0x25, 0x0c, 0x17, 0x8e, 0xbd, 0x73, 0xed, 0x63,
-	0xdf, 0x04, 0x5b, 0xeb, 0xa5, 0x5c, 0x04, 0xe8, 0xac, 0x19, 0x19, 0x73, 0xc7, 0xe8, 0x8f, 0x05,
-	0xb7, 0xaa, 0x73, 0x40, 0x39, 0x46, 0x59, 0xc6, 0x01, 0xb2, 0x02, 0xd8, 0xbf, 0xc7, 0xcd, 0xfc,
-	0x46, 0xae, 0x4b, 0xff, 0xe6, 0x8d, 0xe1, 0xb5, 0xf3, 0xab, 0xd3, 0x9b, 0xd3, 0x2e, 0x1e, 0xe6,
-	0x95, 0xb4, 0x0d, 0x7f, 0xd7, 0x95, 0xb4, 0x4d, 0x17, 0xbf, 0xf3, 0xf8, 0xc7, 0x89, 0x6b, 0x1d,
-	0x9f, 0xb8, 0xd6, 0xaf, 0x13, 0xd7, 0xfa, 0x7a, 0xea, 0x2e, 0x1d, 0x9f, 0xba, 0x4b, 0x3f, 0x4f,
-	0xdd, 0xa5, 0x0f, 0x5e, 0xd5, 0x89, 0xc2, 0x03, 0x3f, 0xce, 0x86, 0x84, 0xb2, 0x44, 0x39, 0x2c,
-	0x47, 0xf3, 0x27, 0x6f, 0xbf, 0x6d, 0xde, 0xba, 0x87, 0x7f, 0x03, 0x00, 0x00, 0xff, 0xff, 0x3b,
-	0xbd, 0x03, 0xda, 0x0d, 0x05, 0x00, 0x00,
+	// 690 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x54, 0x4f, 0x6f, 0xd3, 0x48,
+	0x14, 0xaf, 0x9b, 0x36, 0x89, 0x5f, 0xd2, 0xdd, 0xd5, 0xec, 0x6a, 0xe5, 0x66, 0xbb, 0x96, 0xeb,
+	0xbd, 0xe4, 0x94, 0xa8, 0xd9, 0x0b, 0x42, 0x48, 0x40, 0x5b, 0xa4, 0x0a, 0x38, 0x54, 0x93, 0x02,
+	0x12, 0x97, 0x30, 0xb5, 0x1f, 0x8e, 0x89, 0xeb, 0x31, 0x9e, 0x89, 0x49, 0xbe, 0x02, 0x27, 0xce,
+	0xdc, 0xf9, 0x2a, 0x88, 0x63, 0x8f, 0x1c, 0x51, 0x7b, 0xe1, 0x5b, 0x80, 0x66, 0xc6, 0x69, 0x12,
+	0x91, 0xa2, 0x72, 0x7b, 0x7f, 0xe7, 0xf7, 0x7b, 0xff, 0x06, 0xdc, 0x80, 0x8b, 0x33, 0x2e, 0xba,
+	0x42, 0xe6, 0xc8, 0xce, 0xe2, 0x34, 0xea, 0x16, 0x7b, 0xdd, 0x28, 0xcf, 0x82, 0x4e, 0x96, 0x73,
+	0xc9, 0xc9, 0x9f, 0xc6, 0xdf, 0xb9, 0xf2, 0x77, 0x8a, 0x3d, 0xff, 0xa3, 0x05, 0xdb, 0x8f, 0x63,
+	0x21, 0x31, 0x3d, 0xc4, 0x24, 0x2e, 0x30, 0xdf, 0x4f, 0x78, 0x30, 0xa2, 0xf8, 0x7a, 0x8c, 0x42,
+	0x92, 0x5d, 0x68, 0x9e, 0x2a, 0x7d, 0x30, 0xc4, 0x38, 0x1a, 0x4a, 0xc7, 0xf2, 0xac, 0x76, 0x85,
+	0x36, 0xb4, 0xed, 0x48, 0x9b, 0xc8, 0x1f, 0x50, 0x91, 0x13, 0xe1, 0xac, 0x7b, 0x95, 0x76, 0x93,
+	0x2a, 0x91, 0xf4, 0xa0, 0x8a, 0x05, 0xa6, 0x52, 0x38, 0x15, 0xaf, 0xd2, 0x6e, 0xf4, 0x5a, 0x9d,
+	0x15, 0xc0, 0x9d, 0x07, 0x2a, 0x84, 0x96, 0x91, 0xe4, 0x1e, 0x80, 0x9c, 0x0c, 0x72, 0x14, 0xe3,
+	0x44, 0x0a, 0x67, 0x43, 0xe7, 0xed, 0xae, 0xce, 0x9b, 0x60, 0x70, 0x32, 0xa1, 0x3a, 0x92, 0xda,
+	0xb2, 0x94, 0x84, 0xbf, 0x03, 0xad, 0x55, 0x75, 0x88, 0x8c, 0xa7, 0x02, 0xfd, 0xf7, 0x57, 0x65,
+	0xf6, 0x25, 0x93, 0x78, 0x30, 0x64, 0x69, 0x84, 0xe2, 0x17, 0xca, 0xbc, 0x0b, 0x10, 0xe8, 0xa4,
+	0x81, 0x40, 0xa9, 0xab, 0x6d, 0xf4, 0xbc, 0x95, 0x04, 0xfb, 0x92, 0xe7, 0xf8, 0xe8, 0xe9, 0x31,
+	0x8b, 0x73, 0x6a, 0x9b, 0x9c, 0x3e, 0x4a, 0xb2, 0x0d, 0x75, 0x96, 0x65, 0x83, 0x21, 0x13, 0x43,
+	0xa7, 0xe2, 0x59, 0xed, 0x26, 0xad, 0xb1, 0x2c, 0x3b, 0x62, 0x62, 0x38, 0xa7, 0xbe, 0xcc, 0xad,
+	0xa4, 0xfe, 0xc1, 0x82, 0xc6, 0xc2, 0x9b, 0xc4, 0x81, 0x1a, 0x0b, 0xc3, 0x1c, 0x85, 0x70, 0xac,
+	0xf2, 0x1d, 0xa3, 0xaa, 0x51, 0x8c, 0x70, 0xea, 0xac, 0x6b, 0xab, 0x12, 0xc9, 0x5f, 0xb0, 0x59,
+	0xb0, 0x64, 0x8c, 0x25, 0xa2, 0x51, 0xc8, 0xdf, 0x50, 0x0d, 0x31, 0x41, 0x89, 0xce, 0x86, 0x67,
+	0xb5, 0xeb, 0xb4, 0xd4, 0xc8, 0x1d, 0xa8, 0x85, 0x18, 0xf0, 0x10, 0x43, 0x67, 0xd3, 0xb3, 0xda,
+	0x8d, 0x9e, 0xbf, 0xb2, 0xc0, 0x43, 0x13, 0x53, 0x96, 0x38, 0x4b, 0xf1, 0xdf, 0x5a, 0xb0, 0xb5,
+	0xe4, 0x22, 0x2e, 0x40, 0xc0, 0x93, 0x04, 0x03, 0x19, 0xf3, 0x54, 0x93, 0xb5, 0xe9, 0x82, 0x85,
+	0xfc, 0x03, 0xf6, 0x08, 0xa7, 0x83, 0x8c, 0xe5, 0xd2, 0x2c, 0x90, 0x4d, 0xeb, 0x23, 0x9c, 0x1e,
+	0x2b, 0x9d, 0xfc, 0x0b, 0xa0, 0xd9, 0x0e, 0x5e, 0x09, 0x9e, 0x6a, 0xfe, 0x36, 0xb5, 0xb5, 0xe5,
+	0xa1, 0xe0, 0xe9, 0xdc, 0x2d, 0xa7, 0x99, 0xa9, 0x63, 0xe6, 0x3e, 0x99, 0x66, 0xe8, 0xbf, 0x80,
+	0x4d, 0xbd, 0x60, 0x84, 0xc0, 0x86, 0x8e, 0x30, 0xe8, 0x5a, 0x26, 0x07, 0x00, 0x4c, 0xca, 0x3c,
+	0x3e, 0x1d, 0x4b, 0x14, 0xe5, 0x2c, 0xff, 0xbb, 0x7e, 0x49, 0xef, 0xcf, 0x62, 0xe9, 0x42, 0x9a,
+	0x7f, 0x0b, 0x7e, 0x5b, 0xf6, 0xce, 0xda, 0x6f, 0x90, 0x96, 0xdb, 0xbf, 0xae, 0x6d, 0x46, 0xf1,
+	0xbf, 0x5a, 0xd0, 0x5c, 0xdc, 0x62, 0xc5, 0x51, 0xb5, 0x4d, 0x67, 0x6e, 0x51, 0x2d, 0x2b, 0x5b,
+	0xc8, 0x24, 0x2b, 0x87, 0xa9, 0x65, 0x05, 0x90, 0xf0, 0xa8, 0xec, 0x85, 0x12, 0x55, 0x54, 0x9c,
+	0xbe, 0xe4, 0x65, 0xfd, 0x5a, 0x56, 0x9d, 0x89, 0x98, 0x18, 0xbc, 0x61, 0xa9, 0x2c, 0x07, 0x59,
+	0xa1, 0x76, 0xc4, 0xc4, 0x33, 0x6d, 0x50, 0x7b, 0xa8, 0xdc, 0x63, 0x81, 0xa1, 0x53, 0xd5, 0xce,
+	0x5a, 0xc4, 0xc4, 0x13, 0x81, 0xe1, 0xc2, 0xe1, 0xd6, 0x6e, 0x7c, 0xb8, 0x3b, 0x60, 0x2b, 0xbe,
+	0x22, 0x63, 0x01, 0x3a, 0x75, 0x33, 0x86, 0x2b, 0x43, 0xef, 0x9b, 0x05, 0xbf, 0x9b, 0xd5, 0xc6,
+	0xbc, 0x8f, 0x79, 0x11, 0x07, 0x48, 0xc6, 0x40, 0x7e, 0x3c, 0x54, 0xd2, 0x59, 0x89, 0x75, 0xed,
+	0xcf, 0xd4, 0xea, 0xde, 0x38, 0xde, 0x9c, 0xd1, 0x1c, 0x76, 0xf1, 0xc8, 0x7e, 0x0a, 0xbb, 0xe2,
+	0xa7, 0x68, 0x75, 0x6f, 0x1c, 0x6f, 0x60, 0xf7, 0x6f, 0x7f, 0xba, 0x70, 0xad, 0xf3, 0x0b, 0xd7,
+	0xfa, 0x72, 0xe1, 0x5a, 0xef, 0x2e, 0xdd, 0xb5, 0xf3, 0x4b, 0x77, 0xed, 0xf3, 0xa5, 0xbb, 0xf6,
+	0xdc, 0x33, 0x2f, 0x89, 0x70, 0xd4, 0x89, 0x79, 0x57, 0x60, 0x5e, 0x60, 0xde, 0x2d, 0x7a, 0xf3,
+	0xef, 0xfb, 0xb4, 0xaa, 0xff, 0xed, 0xff, 0xbf, 0x0f, 0x00, 0x9f, 0x11, 0x35, 0xeb, 0xd9, 0x05,
+	0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -867,6 +960,18 @@ func (m *StoreKVPair) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if m.Decoded != nil {
+		{
+			size, err := m.Decoded.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintGrpc(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x2a
+	}
 	if m.Delete {
 		i--
 		if m.Delete {
@@ -901,6 +1006,59 @@ func (m *StoreKVPair) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	return len(dAtA) - i, nil
 }
 
+func (m *DecodedKVPair) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *DecodedKVPair) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *DecodedKVPair) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.ValueType) > 0 {
+		i -= len(m.ValueType)
+		copy(dAtA[i:], m.ValueType)
+		i = encodeVarintGrpc(dAtA, i, uint64(len(m.ValueType)))
+		i--
+		dAtA[i] = 0x22
+	}
+	if len(m.ValueJson) > 0 {
+		i -= len(m.ValueJson)
+		copy(dAtA[i:], m.ValueJson)
+		i = encodeVarintGrpc(dAtA, i, uint64(len(m.ValueJson)))
+		i--
+		dAtA[i] = 0x1a
+	}
+	if len(m.KeyParts) > 0 {
+		for iNdEx := len(m.KeyParts) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.KeyParts[iNdEx])
+			copy(dAtA[i:], m.KeyParts[iNdEx])
+			i = encodeVarintGrpc(dAtA, i, uint64(len(m.KeyParts[iNdEx])))
+			i--
+			dAtA[i] = 0x12
+		}
+	}
+	if len(m.Collection) > 0 {
+		i -= len(m.Collection)
+		copy(dAtA[i:], m.Collection)
+		i = encodeVarintGrpc(dAtA, i, uint64(len(m.Collection)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
 func (m *Event) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
@@ -1164,6 +1322,37 @@ func (m *StoreKVPair) Size() (n int) {
 	if m.Delete {
 		n += 2
 	}
+	if m.Decoded != nil {
+		l = m.Decoded.Size()
+		n += 1 + l + sovGrpc(uint64(l))
+	}
+	return n
+}
+
+func (m *DecodedKVPair) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Collection)
+	if l > 0 {
+		n += 1 + l + sovGrpc(uint64(l))
+	}
+	if len(m.KeyParts) > 0 {
+		for _, s := range m.KeyParts {
+			l = len(s)
+			n += 1 + l + sovGrpc(uint64(l))
+		}
+	}
+	l = len(m.ValueJson)
+	if l > 0 {
+		n += 1 + l + sovGrpc(uint64(l))
+	}
+	l = len(m.ValueType)
+	if l > 0 {
+		n += 1 + l + sovGrpc(uint64(l))
+	}
 	return n
 }
 
@@ -1806,6 +1995,220 @@ func (m *StoreKVPair) Unmarshal(dAtA []byte) error {
 				}
 			}
 			m.Delete = bool(v != 0)
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Decoded", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGrpc
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthGrpc
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthGrpc
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Decoded == nil {
+				m.Decoded = &DecodedKVPair{}
+			}
+			if err := m.Decoded.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipGrpc(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthGrpc
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *DecodedKVPair) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowGrpc
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: DecodedKVPair: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: DecodedKVPair: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Collection", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGrpc
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthGrpc
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthGrpc
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Collection = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field KeyParts", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGrpc
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthGrpc
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthGrpc
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.KeyParts = append(m.KeyParts, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ValueJson", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGrpc
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthGrpc
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthGrpc
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ValueJson = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ValueType", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGrpc
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthGrpc
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthGrpc
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ValueType = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipGrpc(dAtA[iNdEx:])
@@ -8,4 +8,16 @@ type Manager struct {
 
 	// StopNodeOnErr halts the node when ABCI streaming service listening results in an error.
 	StopNodeOnErr bool
+
+	// Decoder decodes the state changes streamed to the listeners, alongside the raw
+	// key-value pairs. State changes aren't decoded if it is nil.
+	Decoder Decoder
+}
+
+// Decoder sets the decoded form of the key-value pairs of state changes, which consumers
+// can interpret without the Go code of the modules.
+type Decoder interface {
+	// DecodeChangeSet sets the decoded form of the pairs it can decode, and returns the
+	// errors of the pairs failing to decode once the other pairs are decoded.
+	DecodeChangeSet(changeSet []*StoreKVPair) error
 }
@@ -18,7 +18,9 @@ import (
 	autocliv1 "cosmossdk.io/api/cosmos/autocli/v1"
 	reflectionv1 "cosmossdk.io/api/cosmos/reflection/v1"
 	"cosmossdk.io/client/v2/autocli"
+	"cosmossdk.io/collections"
 	"cosmossdk.io/core/log"
+	"cosmossdk.io/indexer"
 	storetypes "cosmossdk.io/store/types"
 	"cosmossdk.io/x/accounts"
 	"cosmossdk.io/x/accounts/accountstd"
@@ -180,6 +182,9 @@ type SimApp struct {
 
 	// module configurator
 	configurator module.Configurator // nolint:staticcheck // SA1019: Configurator is deprecated but still used in runtime v1.
+
+	// genesisDir is the directory of the genesis file, the directory of a streamed genesis is relative to it.
+	genesisDir string
 }
 
 func init() {
@@ -328,6 +333,7 @@ func NewSimApp(
 			AddressCodec:          signingCtx.AddressCodec(),
 			ValidatorAddressCodec: signingCtx.ValidatorAddressCodec(),
 		},
+		DecodePolicy: server.TxDecodePolicy(appOpts),
 	}
 	txConfig, err = authtx.NewTxConfigWithOptions(
 		appCodec,
@@ -337,6 +343,10 @@ func NewSimApp(
 		panic(err)
 	}
 	app.txConfig = txConfig
+	// the decode policy only applies to the transactions received by CheckTx
+	if checkTxDecoder := authtx.CheckTxDecoder(txConfig); checkTxDecoder != nil {
+		bApp.SetCheckTxDecoder(checkTxDecoder)
+	}
 
 	app.StakingKeeper = stakingkeeper.NewKeeper(
 		appCodec,
@@ -392,6 +402,7 @@ func NewSimApp(
 		skipUpgradeHeights[int64(h)] = true
 	}
 	homePath := cast.ToString(appOpts.Get(flags.FlagHome))
+	app.genesisDir = filepath.Join(homePath, "config")
 	// set the governance module account as the authority for conducting upgrades
 	app.UpgradeKeeper = upgradekeeper.NewKeeper(runtime.NewEnvironment(runtime.NewKVStoreService(keys[upgradetypes.StoreKey]), logger.With(log.ModuleKey, "x/upgrade"), runtime.EnvWithMsgRouterService(app.MsgServiceRouter()), runtime.EnvWithQueryRouterService(app.GRPCQueryRouter())), skipUpgradeHeights, appCodec, homePath, app.BaseApp, authtypes.NewModuleAddress(govtypes.ModuleName).String())
 
@@ -585,6 +596,11 @@ func NewSimApp(
 	// upgrade.
 	app.setPostHandler()
 
+	// register the indexer streaming service
+	if err := indexer.RegisterWithBaseApp(bApp, appOpts, keys, app.indexerSchemas()); err != nil {
+		panic(err)
+	}
+
 	// At startup, after all modules have been registered, check that all prot
 	// annotations are correct.
 	protoFiles, err := proto.MergedRegistry()
@@ -672,15 +688,23 @@ func (a *SimApp) Configurator() module.Configurator { // nolint:staticcheck // S
 
 // InitChainer application update at chain initialization
 func (app *SimApp) InitChainer(ctx sdk.Context, req *abci.InitChainRequest) (*abci.InitChainResponse, error) {
+	streamed, isStreamed := module.ParseStreamedGenesis(req.AppStateBytes)
+
 	var genesisState GenesisState
-	err := json.Unmarshal(req.AppStateBytes, &genesisState)
-	if err != nil {
-		return nil, err
+	if !isStreamed {
+		if err := json.Unmarshal(req.AppStateBytes, &genesisState); err != nil {
+			return nil, err
+		}
 	}
-	err = app.UpgradeKeeper.SetModuleVersionMap(ctx, app.ModuleManager.GetVersionMap())
+
+	err := app.UpgradeKeeper.SetModuleVersionMap(ctx, app.ModuleManager.GetVersionMap())
 	if err != nil {
 		return nil, err
 	}
+
+	if isStreamed {
+		return app.ModuleManager.InitGenesisFromDir(ctx, streamed.Path(app.genesisDir), streamed.Hash)
+	}
 	return app.ModuleManager.InitGenesis(ctx, genesisState)
 }
 
@@ -745,11 +769,41 @@ func (app *SimApp) GetStoreKeys() []storetypes.StoreKey {
 	return keys
 }
 
+// indexerSchemas returns the collections schemas of the modules which can be indexed,
+// by store key.
+func (app *SimApp) indexerSchemas() map[string]collections.Schema {
+	return map[string]collections.Schema{
+		accounts.StoreKey:      app.AccountsKeeper.Schema,
+		authtypes.StoreKey:     app.AuthKeeper.Schema,
+		banktypes.StoreKey:     app.BankKeeper.Schema,
+		circuittypes.StoreKey:  app.CircuitKeeper.Schema,
+		distrtypes.StoreKey:    app.DistrKeeper.Schema,
+		epochstypes.StoreKey:   app.EpochsKeeper.Schema,
+		evidencetypes.StoreKey: app.EvidenceKeeper.Schema,
+		feegrant.StoreKey:      app.FeeGrantKeeper.Schema,
+		govtypes.StoreKey:      app.GovKeeper.Schema,
+		minttypes.StoreKey:     app.MintKeeper.Schema,
+		pooltypes.StoreKey:     app.PoolKeeper.Schema,
+		slashingtypes.StoreKey: app.SlashingKeeper.Schema,
+		stakingtypes.StoreKey:  app.StakingKeeper.Schema,
+	}
+}
+
 // SimulationManager implements the SimulationApp interface
 func (app *SimApp) SimulationManager() *module.SimulationManager {
 	return app.sm
 }
 
+// GetUpgradeKeeper returns the upgrade keeper, used by the upgrade dry-run command.
+func (app *SimApp) GetUpgradeKeeper() *upgradekeeper.Keeper {
+	return app.UpgradeKeeper
+}
+
+// RegisterInvariants registers the invariants of the modules, asserted by the upgrade dry-run command.
+func (app *SimApp) RegisterInvariants(ir sdk.InvariantRegistry) {
+	app.ModuleManager.RegisterInvariants(ir)
+}
+
 // RegisterAPIRoutes registers all application module routes with the provided
 // API server.
 func (app *SimApp) RegisterAPIRoutes(apiSvr *api.Server, apiConfig config.APIConfig) {
@@ -12,8 +12,10 @@ import (
 	dbm "github.com/cosmos/cosmos-db"
 	"github.com/spf13/cast"
 
+	"cosmossdk.io/collections"
 	"cosmossdk.io/core/legacy"
 	"cosmossdk.io/depinject"
+	"cosmossdk.io/indexer"
 	"cosmossdk.io/log"
 	storetypes "cosmossdk.io/store/types"
 	"cosmossdk.io/x/accounts"
@@ -25,20 +27,31 @@ import (
 	authtypes "cosmossdk.io/x/auth/types"
 	authzkeeper "cosmossdk.io/x/authz/keeper"
 	bankkeeper "cosmossdk.io/x/bank/keeper"
+	banktypes "cosmossdk.io/x/bank/types"
 	circuitkeeper "cosmossdk.io/x/circuit/keeper"
+	circuittypes "cosmossdk.io/x/circuit/types"
 	consensuskeeper "cosmossdk.io/x/consensus/keeper"
 	distrkeeper "cosmossdk.io/x/distribution/keeper"
+	distrtypes "cosmossdk.io/x/distribution/types"
 	epochskeeper "cosmossdk.io/x/epochs/keeper"
+	epochstypes "cosmossdk.io/x/epochs/types"
 	evidencekeeper "cosmossdk.io/x/evidence/keeper"
+	evidencetypes "cosmossdk.io/x/evidence/types"
+	"cosmossdk.io/x/feegrant"
 	feegrantkeeper "cosmossdk.io/x/feegrant/keeper"
 	govkeeper "cosmossdk.io/x/gov/keeper"
+	govtypes "cosmossdk.io/x/gov/types"
 	groupkeeper "cosmossdk.io/x/group/keeper"
 	mintkeeper "cosmossdk.io/x/mint/keeper"
+	minttypes "cosmossdk.io/x/mint/types"
 	nftkeeper "cosmossdk.io/x/nft/keeper"
 	_ "cosmossdk.io/x/protocolpool"
 	poolkeeper "cosmossdk.io/x/protocolpool/keeper"
+	pooltypes "cosmossdk.io/x/protocolpool/types"
 	slashingkeeper "cosmossdk.io/x/slashing/keeper"
+	slashingtypes "cosmossdk.io/x/slashing/types"
 	stakingkeeper "cosmossdk.io/x/staking/keeper"
+	stakingtypes "cosmossdk.io/x/staking/types"
 	upgradekeeper "cosmossdk.io/x/upgrade/keeper"
 
 	"github.com/cosmos/cosmos-sdk/baseapp"
@@ -52,6 +65,7 @@ import (
 	"github.com/cosmos/cosmos-sdk/server/config"
 	servertypes "github.com/cosmos/cosmos-sdk/server/types"
 	testdata_pulsar "github.com/cosmos/cosmos-sdk/testutil/testdata/testpb"
+	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/cosmos/cosmos-sdk/types/module"
 )
 
@@ -136,6 +150,8 @@ func NewSimApp(
 				appOpts,
 				// supply the logger
 				logger,
+				// supply the transactions decode policy enforced in CheckTx
+				server.TxDecodePolicy(appOpts),
 
 				// ADVANCED CONFIGURATION
 
@@ -247,6 +263,9 @@ func NewSimApp(
 	if err := app.RegisterStreamingServices(appOpts, app.kvStoreKeys()); err != nil {
 		panic(err)
 	}
+	if err := indexer.RegisterWithBaseApp(app.BaseApp, appOpts, app.kvStoreKeys(), app.indexerSchemas()); err != nil {
+		panic(err)
+	}
 
 	/****  Module Options ****/
 
@@ -392,11 +411,46 @@ func (app *SimApp) kvStoreKeys() map[string]*storetypes.KVStoreKey {
 	return keys
 }
 
+// indexerSchemas returns the collections schemas of the modules which can be indexed,
+// by store key.
+func (app *SimApp) indexerSchemas() map[string]collections.Schema {
+	schemas := map[string]collections.Schema{
+		accounts.StoreKey:      app.AccountsKeeper.Schema,
+		authtypes.StoreKey:     app.AuthKeeper.Schema,
+		circuittypes.StoreKey:  app.CircuitBreakerKeeper.Schema,
+		distrtypes.StoreKey:    app.DistrKeeper.Schema,
+		epochstypes.StoreKey:   app.EpochsKeeper.Schema,
+		evidencetypes.StoreKey: app.EvidenceKeeper.Schema,
+		feegrant.StoreKey:      app.FeeGrantKeeper.Schema,
+		govtypes.StoreKey:      app.GovKeeper.Schema,
+		minttypes.StoreKey:     app.MintKeeper.Schema,
+		pooltypes.StoreKey:     app.PoolKeeper.Schema,
+		slashingtypes.StoreKey: app.SlashingKeeper.Schema,
+		stakingtypes.StoreKey:  app.StakingKeeper.Schema,
+	}
+	// the bank keeper can be wrapped, its schema is only indexed when it can be reached
+	if bankKeeper, ok := app.BankKeeper.(bankkeeper.BaseKeeper); ok {
+		schemas[banktypes.StoreKey] = bankKeeper.Schema
+	}
+
+	return schemas
+}
+
 // SimulationManager implements the SimulationApp interface
 func (app *SimApp) SimulationManager() *module.SimulationManager {
 	return app.sm
 }
 
+// GetUpgradeKeeper returns the upgrade keeper, used by the upgrade dry-run command.
+func (app *SimApp) GetUpgradeKeeper() *upgradekeeper.Keeper {
+	return app.UpgradeKeeper
+}
+
+// RegisterInvariants registers the invariants of the modules, asserted by the upgrade dry-run command.
+func (app *SimApp) RegisterInvariants(ir sdk.InvariantRegistry) {
+	app.ModuleManager.RegisterInvariants(ir)
+}
+
 // RegisterAPIRoutes registers all application module routes with the provided
 // API server.
 func (app *SimApp) RegisterAPIRoutes(apiSvr *api.Server, apiConfig config.APIConfig) {
@@ -1,28 +1,41 @@
 package simapp
 
 import (
+	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	abci "github.com/cometbft/cometbft/abci/types"
 	cmtproto "github.com/cometbft/cometbft/api/cometbft/types/v1"
 	dbm "github.com/cosmos/cosmos-db"
 	"github.com/cosmos/gogoproto/proto"
 	"github.com/golang/mock/gomock"
+	_ "github.com/mattn/go-sqlite3"
 	"github.com/stretchr/testify/require"
 
 	"cosmossdk.io/core/address"
 	"cosmossdk.io/core/appmodule"
+	"cosmossdk.io/core/header"
 	"cosmossdk.io/depinject"
+	"cosmossdk.io/indexer"
 	"cosmossdk.io/log"
 	"cosmossdk.io/x/accounts"
 	"cosmossdk.io/x/auth"
+	authtypes "cosmossdk.io/x/auth/types"
 	"cosmossdk.io/x/auth/vesting"
 	authzmodule "cosmossdk.io/x/authz/module"
 	"cosmossdk.io/x/bank"
+	bankkeeper "cosmossdk.io/x/bank/keeper"
 	banktypes "cosmossdk.io/x/bank/types"
 	"cosmossdk.io/x/distribution"
+	distrkeeper "cosmossdk.io/x/distribution/keeper"
+	distrtypes "cosmossdk.io/x/distribution/types"
 	"cosmossdk.io/x/epochs"
 	"cosmossdk.io/x/evidence"
 	feegrantmodule "cosmossdk.io/x/feegrant/module"
@@ -32,9 +45,16 @@ import (
 	"cosmossdk.io/x/protocolpool"
 	"cosmossdk.io/x/slashing"
 	"cosmossdk.io/x/staking"
+	stakingkeeper "cosmossdk.io/x/staking/keeper"
+	stakingtypes "cosmossdk.io/x/staking/types"
 	"cosmossdk.io/x/upgrade"
+	upgradetypes "cosmossdk.io/x/upgrade/types"
 
 	"github.com/cosmos/cosmos-sdk/baseapp"
+	"github.com/cosmos/cosmos-sdk/client/flags"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/ed25519"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	servertypes "github.com/cosmos/cosmos-sdk/server/types"
 	"github.com/cosmos/cosmos-sdk/testutil/mock"
 	"github.com/cosmos/cosmos-sdk/testutil/network"
 	simtestutil "github.com/cosmos/cosmos-sdk/testutil/sims"
@@ -42,6 +62,7 @@ import (
 	"github.com/cosmos/cosmos-sdk/types/module"
 	"github.com/cosmos/cosmos-sdk/types/msgservice"
 	"github.com/cosmos/cosmos-sdk/x/genutil"
+	genutiltypes "github.com/cosmos/cosmos-sdk/x/genutil/types"
 )
 
 func TestSimAppExportAndBlockedAddrs(t *testing.T) {
@@ -357,3 +378,354 @@ func TestAddressCodecFactory(t *testing.T) {
 	_, ok = consAddressCodec.(customAddressCodec)
 	require.True(t, ok)
 }
+
+func TestDryRunUpgrade(t *testing.T) {
+	app := NewSimappWithCustomOptions(t, false, SetupOptions{
+		Logger:  log.NewTestLogger(t),
+		DB:      dbm.NewMemDB(),
+		AppOpts: simtestutil.NewAppOptionsWithFlagHome(t.TempDir()),
+	})
+	// commit the genesis state, the upgrade is rehearsed on the committed state
+	_, err := app.FinalizeBlock(&abci.FinalizeBlockRequest{Height: 1})
+	require.NoError(t, err)
+	_, err = app.Commit()
+	require.NoError(t, err)
+
+	ctx := sdk.NewContext(app.CommitMultiStore(), false, log.NewNopLogger()).
+		WithHeaderInfo(header.Info{Height: app.LastBlockHeight()})
+
+	// the upgrade migrates x/bank from its previous version
+	app.UpgradeKeeper.SetUpgradeHandler("dry-run", func(ctx context.Context, _ upgradetypes.Plan, fromVM appmodule.VersionMap) (appmodule.VersionMap, error) {
+		fromVM[banktypes.ModuleName] = bank.AppModule{}.ConsensusVersion() - 1
+		return app.ModuleManager.RunMigrations(ctx, app.Configurator(), fromVM)
+	})
+	app.UpgradeKeeper.SetUpgradeHandler("dry-run-failure", func(context.Context, upgradetypes.Plan, appmodule.VersionMap) (appmodule.VersionMap, error) {
+		return nil, errors.New("failure")
+	})
+	app.UpgradeKeeper.SetUpgradeHandler("dry-run-panic", func(context.Context, upgradetypes.Plan, appmodule.VersionMap) (appmodule.VersionMap, error) {
+		panic("migration failed")
+	})
+
+	var invariants upgradetypes.Invariants
+	app.RegisterInvariants(&invariants)
+	require.NotEmpty(t, invariants)
+
+	plan := upgradetypes.Plan{Name: "dry-run", Height: app.LastBlockHeight() + 1}
+	report, err := app.UpgradeKeeper.DryRunUpgrade(ctx, plan, invariants)
+	require.NoError(t, err)
+	require.Empty(t, report.Error)
+	require.Empty(t, report.InvariantBreaks)
+	require.Len(t, report.Migrations, 1)
+	require.Equal(t, banktypes.ModuleName, report.Migrations[0].Module)
+	require.Equal(t, bank.AppModule{}.ConsensusVersion()-1, report.Migrations[0].FromVersion)
+	require.Equal(t, bank.AppModule{}.ConsensusVersion(), report.Migrations[0].ToVersion)
+	require.Positive(t, report.GasUsed)
+
+	var upgradeChanges upgradetypes.StoreChanges
+	for _, changes := range report.StateChanges {
+		if changes.Store == upgradetypes.StoreKey {
+			upgradeChanges = changes
+		}
+	}
+	require.Positive(t, upgradeChanges.Writes)
+	require.Positive(t, upgradeChanges.Bytes)
+
+	// the upgrade is discarded
+	doneHeight, err := app.UpgradeKeeper.GetDoneHeight(ctx, plan.Name)
+	require.NoError(t, err)
+	require.Zero(t, doneHeight)
+
+	// broken invariants are reported
+	invariants.RegisterRoute("test", "broken", func(sdk.Context) (string, bool) { return "broken", true })
+	report, err = app.UpgradeKeeper.DryRunUpgrade(ctx, plan, invariants)
+	require.NoError(t, err)
+	require.Equal(t, []upgradetypes.InvariantBreak{{Module: "test", Route: "broken", Message: "broken"}}, report.InvariantBreaks)
+
+	// a failing upgrade handler is reported
+	report, err = app.UpgradeKeeper.DryRunUpgrade(ctx, upgradetypes.Plan{Name: "dry-run-failure", Height: plan.Height}, invariants)
+	require.NoError(t, err)
+	require.Equal(t, "failure", report.Error)
+
+	// a panicking upgrade handler is reported
+	report, err = app.UpgradeKeeper.DryRunUpgrade(ctx, upgradetypes.Plan{Name: "dry-run-panic", Height: plan.Height}, invariants)
+	require.NoError(t, err)
+	require.Equal(t, "upgrade handler panicked: migration failed", report.Error)
+
+	_, err = app.UpgradeKeeper.DryRunUpgrade(ctx, upgradetypes.Plan{Name: "unknown", Height: plan.Height}, invariants)
+	require.ErrorContains(t, err, "no upgrade handler registered for unknown")
+}
+
+func TestExportAndInitStreamedGenesis(t *testing.T) {
+	app := NewSimappWithCustomOptions(t, false, SetupOptions{
+		Logger:  log.NewTestLogger(t),
+		DB:      dbm.NewMemDB(),
+		AppOpts: simtestutil.NewAppOptionsWithFlagHome(t.TempDir()),
+	})
+	_, err := app.FinalizeBlock(&abci.FinalizeBlockRequest{Height: 1})
+	require.NoError(t, err)
+	_, err = app.Commit()
+	require.NoError(t, err)
+
+	dir := filepath.Join(t.TempDir(), "app_state")
+	exported, err := app.ExportAppStateAndValidatorsToDir(dir, false, nil, nil)
+	require.NoError(t, err)
+	streamed, ok := module.ParseStreamedGenesis(exported.AppState)
+	require.True(t, ok)
+	require.Equal(t, dir, streamed.Dir)
+	require.NotEmpty(t, streamed.Hash)
+	require.FileExists(t, filepath.Join(dir, banktypes.ModuleName, "balances", "000000.ndjson"))
+
+	// the modules streaming their export write the same state as their JSON export
+	jsonExported, err := app.ExportAppStateAndValidators(false, nil, nil)
+	require.NoError(t, err)
+	var jsonAppState map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(jsonExported.AppState, &jsonAppState))
+	for _, name := range []string{authtypes.ModuleName, banktypes.ModuleName, stakingtypes.ModuleName} {
+		moduleState, err := module.ReadGenesisDirJSON(filepath.Join(dir, name))
+		require.NoError(t, err)
+		require.JSONEq(t, string(jsonAppState[name]), string(moduleState), name)
+	}
+
+	// a new chain is initialized from the module states of the directory, relative to the genesis file
+	home := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(home, "config"), 0o755))
+	require.NoError(t, os.Rename(dir, filepath.Join(home, "config", "app_state")))
+	appState, err := module.StreamedGenesis{Dir: "app_state", Hash: streamed.Hash}.AppState()
+	require.NoError(t, err)
+
+	app2 := NewSimApp(log.NewTestLogger(t), dbm.NewMemDB(), nil, true, simtestutil.NewAppOptionsWithFlagHome(home))
+	res, err := app2.InitChain(&abci.InitChainRequest{
+		ConsensusParams: simtestutil.DefaultConsensusParams,
+		AppStateBytes:   appState,
+		InitialHeight:   exported.Height,
+	})
+	require.NoError(t, err)
+	require.Len(t, res.Validators, len(exported.Validators))
+
+	// the modules importing their genesis from the directory restore the exported state
+	names := []string{authtypes.ModuleName, banktypes.ModuleName, stakingtypes.ModuleName}
+	ctx := app2.NewContextLegacy(false, cmtproto.Header{Height: exported.Height})
+	reAppState, err := app2.ModuleManager.ExportGenesisForModules(ctx, names)
+	require.NoError(t, err)
+	for _, name := range names {
+		require.JSONEq(t, string(jsonAppState[name]), string(reAppState[name]), name)
+	}
+
+	_, err = app2.FinalizeBlock(&abci.FinalizeBlockRequest{Height: exported.Height})
+	require.NoError(t, err)
+	_, err = app2.Commit()
+	require.NoError(t, err)
+}
+
+func TestEditGenesisFork(t *testing.T) {
+	app := NewSimappWithCustomOptions(t, false, SetupOptions{
+		Logger:  log.NewTestLogger(t),
+		DB:      dbm.NewMemDB(),
+		AppOpts: simtestutil.NewAppOptionsWithFlagHome(t.TempDir()),
+	})
+	_, err := app.FinalizeBlock(&abci.FinalizeBlockRequest{Height: 1})
+	require.NoError(t, err)
+	_, err = app.Commit()
+	require.NoError(t, err)
+
+	exported, err := app.ExportAppStateAndValidators(false, nil, nil)
+	require.NoError(t, err)
+	appGenesis := genutiltypes.NewAppGenesisWithVersion("fork", exported.AppState)
+	var appState map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(exported.AppState, &appState))
+
+	// the validator set is replaced by a new validator, and an account is funded
+	pubKey := ed25519.GenPrivKey().PubKey()
+	pubKeyJSON, err := app.AppCodec().MarshalInterfaceJSON(pubKey)
+	require.NoError(t, err)
+	operator := sdk.AccAddress(secp256k1.GenPrivKey().PubKey().Address())
+	funded := sdk.AccAddress(secp256k1.GenPrivKey().PubKey().Address())
+	edits, err := genutil.ParseGenesisEdits([]byte(fmt.Sprintf(`[
+		{"replace_validators": {"validators": [{"operator": %q, "pub_key": %s, "power": 10}]}},
+		{"add_balance": {"address": %q, "coins": "1000stake"}},
+		{"reset_gov_proposals": {}}
+	]`, operator.String(), pubKeyJSON, funded.String())))
+	require.NoError(t, err)
+
+	addressCodec := app.AuthKeeper.AddressCodec()
+	require.NoError(t, genutil.EditGenesis(app.AppCodec(), addressCodec, app.StakingKeeper.ValidatorAddressCodec(),
+		app.StakingKeeper.ConsensusAddressCodec(), sdk.DefaultPowerReduction, appGenesis, appState, edits))
+	require.NoError(t, app.ModuleManager.ValidateGenesis(appState))
+	require.NoError(t, genutil.CheckGenesisInvariants(app.AppCodec(), addressCodec, appState))
+	require.Len(t, appGenesis.Consensus.Validators, 1)
+
+	diff, err := genutil.DiffGenesis(appGenesis, appGenesis, appState, appState, nil)
+	require.NoError(t, err)
+	require.Empty(t, diff.Changes)
+	require.Equal(t, genutil.ValidatorSetSummary{Validators: 1, Power: 10}, diff.NewValidatorSet)
+
+	// the forked chain starts with the new validator, which gets rewards and signing infos
+	stateBytes, err := json.Marshal(appState)
+	require.NoError(t, err)
+	app2 := NewSimApp(log.NewTestLogger(t), dbm.NewMemDB(), nil, true, simtestutil.NewAppOptionsWithFlagHome(t.TempDir()))
+	res, err := app2.InitChain(&abci.InitChainRequest{
+		ConsensusParams: simtestutil.DefaultConsensusParams,
+		AppStateBytes:   stateBytes,
+		InitialHeight:   exported.Height,
+	})
+	require.NoError(t, err)
+	require.Len(t, res.Validators, 1)
+	require.Equal(t, int64(10), res.Validators[0].Power)
+
+	votes := abci.CommitInfo{Votes: []abci.VoteInfo{{
+		Validator:   abci.Validator{Address: pubKey.Address(), Power: 10},
+		BlockIdFlag: cmtproto.BlockIDFlagCommit,
+	}}}
+	for height := exported.Height; height < exported.Height+3; height++ {
+		_, err = app2.FinalizeBlock(&abci.FinalizeBlockRequest{Height: height, DecidedLastCommit: votes, ProposerAddress: pubKey.Address()})
+		require.NoError(t, err)
+		_, err = app2.Commit()
+		require.NoError(t, err)
+	}
+
+	ctx := app2.NewUncachedContext(false, cmtproto.Header{}).WithHeaderInfo(header.Info{Height: app2.LastBlockHeight()})
+	require.Equal(t, "1000stake", app2.BankKeeper.GetBalance(ctx, funded, "stake").String())
+	valAddr, err := app2.StakingKeeper.ValidatorAddressCodec().BytesToString(operator)
+	require.NoError(t, err)
+	rewards, err := distrkeeper.NewQuerier(app2.DistrKeeper).DelegationRewards(ctx, &distrtypes.QueryDelegationRewardsRequest{
+		DelegatorAddress: operator.String(),
+		ValidatorAddress: valAddr,
+	})
+	require.NoError(t, err)
+	require.False(t, rewards.Rewards.IsZero())
+}
+
+func TestForkTestnet(t *testing.T) {
+	app := NewSimappWithCustomOptions(t, false, SetupOptions{
+		Logger:  log.NewTestLogger(t),
+		DB:      dbm.NewMemDB(),
+		AppOpts: simtestutil.NewAppOptionsWithFlagHome(t.TempDir()),
+	})
+	_, err := app.FinalizeBlock(&abci.FinalizeBlockRequest{Height: 1})
+	require.NoError(t, err)
+	_, err = app.Commit()
+	require.NoError(t, err)
+
+	ctx := app.NewUncachedContext(false, cmtproto.Header{})
+	oldValidators, err := app.StakingKeeper.GetLastValidators(ctx)
+	require.NoError(t, err)
+	require.NotEmpty(t, oldValidators)
+
+	// the validator set is replaced by a new validator
+	pubKey := ed25519.GenPrivKey().PubKey()
+	operator := sdk.AccAddress(secp256k1.GenPrivKey().PubKey().Address())
+	require.NoError(t, app.ForkTestnet(servertypes.TestnetFork{
+		ChainID: "testnet",
+		Height:  app.LastBlockHeight(),
+		Time:    time.Now(),
+		Validators: []servertypes.TestnetValidator{{
+			Moniker:         "validator0",
+			OperatorAddress: operator.String(),
+			ConsPubKey:      pubKey.Bytes(),
+			Power:           10,
+		}},
+	}))
+
+	// the forked chain runs with the new validator, which gets rewards and signing infos,
+	// without validator updates as they are already in the CometBFT state
+	votes := abci.CommitInfo{Votes: []abci.VoteInfo{{
+		Validator:   abci.Validator{Address: pubKey.Address(), Power: 10},
+		BlockIdFlag: cmtproto.BlockIDFlagCommit,
+	}}}
+	for height := app.LastBlockHeight() + 1; height < 5; height++ {
+		res, err := app.FinalizeBlock(&abci.FinalizeBlockRequest{Height: height, DecidedLastCommit: votes, ProposerAddress: pubKey.Address()})
+		require.NoError(t, err)
+		require.Empty(t, res.ValidatorUpdates)
+		_, err = app.Commit()
+		require.NoError(t, err)
+	}
+
+	ctx = app.NewUncachedContext(false, cmtproto.Header{}).WithHeaderInfo(header.Info{Height: app.LastBlockHeight()})
+	validators, err := app.StakingKeeper.GetLastValidators(ctx)
+	require.NoError(t, err)
+	require.Len(t, validators, 1)
+	require.Equal(t, "validator0", validators[0].GetMoniker())
+	require.Equal(t, int64(10), validators[0].ConsensusPower(app.StakingKeeper.PowerReduction(ctx)))
+
+	for _, val := range oldValidators {
+		valAddr, err := app.StakingKeeper.ValidatorAddressCodec().StringToBytes(val.GetOperator())
+		require.NoError(t, err)
+		val, err := app.StakingKeeper.GetValidator(ctx, valAddr)
+		require.NoError(t, err)
+		require.True(t, val.IsJailed())
+		require.True(t, val.IsUnbonding())
+		consAddr, err := val.GetConsAddr()
+		require.NoError(t, err)
+		require.True(t, app.SlashingKeeper.IsTombstoned(ctx, consAddr))
+	}
+
+	valAddr, err := app.StakingKeeper.ValidatorAddressCodec().BytesToString(operator)
+	require.NoError(t, err)
+	rewards, err := distrkeeper.NewQuerier(app.DistrKeeper).DelegationRewards(ctx, &distrtypes.QueryDelegationRewardsRequest{
+		DelegatorAddress: operator.String(),
+		ValidatorAddress: valAddr,
+	})
+	require.NoError(t, err)
+	require.False(t, rewards.Rewards.IsZero())
+	require.Equal(t, "10000000stake", app.BankKeeper.GetBalance(ctx, operator, "stake").String())
+
+	for _, invariant := range []sdk.Invariant{
+		bankkeeper.AllInvariants(app.BankKeeper),
+		stakingkeeper.AllInvariants(app.StakingKeeper),
+	} {
+		msg, broken := invariant(ctx)
+		require.False(t, broken, msg)
+	}
+}
+
+func TestIndexer(t *testing.T) {
+	dsn := "file:" + filepath.Join(t.TempDir(), "indexer.db")
+	appOpts := simtestutil.AppOptionsMap{
+		flags.FlagHome:                   t.TempDir(),
+		"streaming.indexer.driver":       "sqlite3",
+		"streaming.indexer.dsn":          dsn,
+		"streaming.indexer.keys":         []string{"*"},
+		"streaming.indexer.start-height": 1,
+		"streaming.queue.enable":         true,
+	}
+
+	app := NewSimappWithCustomOptions(t, false, SetupOptions{
+		Logger:  log.NewTestLogger(t),
+		DB:      dbm.NewMemDB(),
+		AppOpts: appOpts,
+	})
+	_, err := app.FinalizeBlock(&abci.FinalizeBlockRequest{Height: 1})
+	require.NoError(t, err)
+	_, err = app.Commit()
+	require.NoError(t, err)
+
+	// the blocks are indexed asynchronously, through the streaming queue
+	require.Eventually(t, func() bool {
+		cursor, err := app.StreamingQueue().Cursor(indexer.IndexerTomlKey)
+		return err == nil && cursor == 1
+	}, 10*time.Second, 10*time.Millisecond)
+
+	db, err := sql.Open("sqlite3", dsn)
+	require.NoError(t, err)
+	defer db.Close()
+
+	var height int64
+	require.NoError(t, db.QueryRow(`SELECT MAX("height") FROM "indexer_blocks"`).Scan(&height))
+	require.Equal(t, int64(1), height)
+
+	// the genesis state is indexed with the first block
+	ctx := app.NewContext(true)
+	supply, err := app.BankKeeper.TotalSupply(ctx, &banktypes.QueryTotalSupplyRequest{})
+	require.NoError(t, err)
+	for _, coin := range supply.Supply {
+		var amount string
+		require.NoError(t, db.QueryRow(`SELECT "value" FROM "bank_supply" WHERE "key" = $1`, coin.Denom).Scan(&amount))
+		require.Equal(t, coin.Amount.String(), amount)
+	}
+
+	var validators int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM "staking_validators"`).Scan(&validators))
+	stakingValidators, err := app.StakingKeeper.GetAllValidators(ctx)
+	require.NoError(t, err)
+	require.Equal(t, len(stakingValidators), validators)
+}
@@ -15,6 +15,7 @@ import (
 
 	servertypes "github.com/cosmos/cosmos-sdk/server/types"
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/module"
 )
 
 // ExportAppStateAndValidators exports the state of the application for a genesis
@@ -41,6 +42,35 @@ func (app *SimApp) ExportAppStateAndValidators(forZeroHeight bool, jailAllowedAd
 		return servertypes.ExportedApp{}, err
 	}
 
+	return app.exportedApp(ctx, appState, height)
+}
+
+// ExportAppStateAndValidatorsToDir exports the state of the application for a genesis
+// file, streaming the state of each module to a file of dir. The exported app state
+// references dir, see module.StreamedGenesis.
+func (app *SimApp) ExportAppStateAndValidatorsToDir(dir string, forZeroHeight bool, jailAllowedAddrs, modulesToExport []string) (servertypes.ExportedApp, error) {
+	ctx := app.NewContextLegacy(true, cmtproto.Header{Height: app.LastBlockHeight()})
+
+	height := app.LastBlockHeight() + 1
+	if forZeroHeight {
+		height = 0
+		app.prepForZeroHeightGenesis(ctx, jailAllowedAddrs)
+	}
+
+	hash, err := app.ModuleManager.ExportGenesisToDir(ctx, dir, modulesToExport)
+	if err != nil {
+		return servertypes.ExportedApp{}, err
+	}
+
+	appState, err := module.StreamedGenesis{Dir: dir, Hash: hash}.AppState()
+	if err != nil {
+		return servertypes.ExportedApp{}, err
+	}
+
+	return app.exportedApp(ctx, appState, height)
+}
+
+func (app *SimApp) exportedApp(ctx sdk.Context, appState json.RawMessage, height int64) (servertypes.ExportedApp, error) {
 	validators, err := staking.WriteValidators(ctx, app.StakingKeeper)
 	return servertypes.ExportedApp{
 		AppState:        appState,
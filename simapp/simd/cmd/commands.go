@@ -6,6 +6,7 @@ import (
 	"os"
 
 	dbm "github.com/cosmos/cosmos-db"
+	"github.com/spf13/cast"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 
@@ -15,6 +16,7 @@ import (
 	confixcmd "cosmossdk.io/tools/confix/cmd"
 	authcmd "cosmossdk.io/x/auth/client/cli"
 	banktypes "cosmossdk.io/x/bank/types"
+	upgradecli "cosmossdk.io/x/upgrade/client/cli"
 
 	"github.com/cosmos/cosmos-sdk/client"
 	"github.com/cosmos/cosmos-sdk/client/debug"
@@ -38,13 +40,18 @@ func initRootCmd(
 	cfg := sdk.GetConfig()
 	cfg.Seal()
 
+	debugCmd := debug.Cmd()
+	debugCmd.AddCommand(debug.ProfileBlockCmd(newApp))
+
 	rootCmd.AddCommand(
 		genutilcli.InitCmd(moduleManager),
 		NewTestnetCmd(moduleManager, banktypes.GenesisBalancesIterator{}),
-		debug.Cmd(),
+		debugCmd,
 		confixcmd.ConfigCommand(),
 		pruning.Cmd(newApp),
 		snapshot.Cmd(newApp),
+		upgradecli.NewDryRunCmd(newApp),
+		server.TestnetForkCmd(newApp),
 	)
 
 	server.AddCommands(rootCmd, newApp, server.StartCmdOptions[servertypes.Application]{})
@@ -169,6 +176,11 @@ func appExport(
 		simApp = simapp.NewSimApp(logger, db, traceStore, true, appOpts)
 	}
 
+	// the export command sets the directory of the module states when exporting to a directory
+	if dir := cast.ToString(appOpts.Get(genutilcli.AppStateDirOption)); dir != "" {
+		return simApp.ExportAppStateAndValidatorsToDir(dir, forZeroHeight, jailAllowedAddrs, modulesToExport)
+	}
+
 	return simApp.ExportAppStateAndValidators(forZeroHeight, jailAllowedAddrs, modulesToExport)
 }
 
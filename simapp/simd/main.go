@@ -4,6 +4,9 @@ import (
 	"fmt"
 	"os"
 
+	_ "github.com/lib/pq"           // register the postgres driver of the indexer
+	_ "github.com/mattn/go-sqlite3" // register the sqlite3 driver of the indexer
+
 	"cosmossdk.io/simapp"
 	"cosmossdk.io/simapp/simd/cmd"
 
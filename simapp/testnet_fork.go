@@ -0,0 +1,115 @@
+package simapp
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	cmtproto "github.com/cometbft/cometbft/api/cometbft/types/v1"
+
+	"cosmossdk.io/collections"
+	"cosmossdk.io/core/header"
+	"cosmossdk.io/math"
+	minttypes "cosmossdk.io/x/mint/types"
+	slashingtypes "cosmossdk.io/x/slashing/types"
+	stakingkeeper "cosmossdk.io/x/staking/keeper"
+	stakingtypes "cosmossdk.io/x/staking/types"
+
+	"github.com/cosmos/cosmos-sdk/crypto/keys/ed25519"
+	servertypes "github.com/cosmos/cosmos-sdk/server/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+var _ servertypes.TestnetForker = (*SimApp)(nil)
+
+// ForkTestnet replaces the validator set by the validators of a testnet fork.
+// The current validators are jailed and tombstoned, so that they leave the validator
+// set for good while keeping their delegations. The new validators self-delegate
+// their power, and their operators are funded with as many liquid tokens.
+func (app *SimApp) ForkTestnet(fork servertypes.TestnetFork) error {
+	// the fork is applied at the beginning of the first block of the testnet
+	height := fork.Height + 1
+	ctx := app.NewUncachedContext(false, cmtproto.Header{ChainID: fork.ChainID, Height: height, Time: fork.Time}).
+		WithHeaderInfo(header.Info{ChainID: fork.ChainID, Height: height, Time: fork.Time})
+
+	validators, err := app.StakingKeeper.GetAllValidators(ctx)
+	if err != nil {
+		return err
+	}
+	for _, val := range validators {
+		consAddr, err := val.GetConsAddr()
+		if err != nil {
+			return err
+		}
+		if !val.IsJailed() {
+			if err := app.StakingKeeper.Jail(ctx, consAddr); err != nil {
+				return err
+			}
+		}
+
+		info, err := app.SlashingKeeper.ValidatorSigningInfo.Get(ctx, consAddr)
+		if errors.Is(err, collections.ErrNotFound) {
+			consAddrStr, err := app.StakingKeeper.ConsensusAddressCodec().BytesToString(consAddr)
+			if err != nil {
+				return err
+			}
+			info = slashingtypes.NewValidatorSigningInfo(consAddrStr, height, time.Time{}, false, 0)
+		} else if err != nil {
+			return err
+		}
+		info.Tombstoned = true
+		if err := app.SlashingKeeper.ValidatorSigningInfo.Set(ctx, consAddr, info); err != nil {
+			return err
+		}
+	}
+
+	bondDenom, err := app.StakingKeeper.BondDenom(ctx)
+	if err != nil {
+		return err
+	}
+	minCommissionRate, err := app.StakingKeeper.MinCommissionRate(ctx)
+	if err != nil {
+		return err
+	}
+	msgServer := stakingkeeper.NewMsgServerImpl(app.StakingKeeper)
+	for _, val := range fork.Validators {
+		operator, err := app.AuthKeeper.AddressCodec().StringToBytes(val.OperatorAddress)
+		if err != nil {
+			return fmt.Errorf("invalid operator address of validator %s: %w", val.Moniker, err)
+		}
+		valAddr, err := app.StakingKeeper.ValidatorAddressCodec().BytesToString(operator)
+		if err != nil {
+			return err
+		}
+
+		if !app.AuthKeeper.HasAccount(ctx, operator) {
+			app.AuthKeeper.SetAccount(ctx, app.AuthKeeper.NewAccountWithAddress(ctx, operator))
+		}
+		selfDelegation := sdk.NewCoin(bondDenom, app.StakingKeeper.TokensFromConsensusPower(ctx, val.Power))
+		funds := sdk.NewCoins(selfDelegation.Add(selfDelegation))
+		if err := app.BankKeeper.MintCoins(ctx, minttypes.ModuleName, funds); err != nil {
+			return err
+		}
+		if err := app.BankKeeper.SendCoinsFromModuleToAccount(ctx, minttypes.ModuleName, operator, funds); err != nil {
+			return err
+		}
+
+		msg, err := stakingtypes.NewMsgCreateValidator(
+			valAddr, &ed25519.PubKey{Key: val.ConsPubKey}, selfDelegation,
+			stakingtypes.NewDescription(val.Moniker, "", "", "", ""),
+			stakingtypes.NewCommissionRates(minCommissionRate, math.LegacyOneDec(), math.LegacyOneDec()),
+			math.OneInt(),
+		)
+		if err != nil {
+			return err
+		}
+		if _, err := msgServer.CreateValidator(ctx, msg); err != nil {
+			return fmt.Errorf("failed to create validator %s: %w", val.Moniker, err)
+		}
+	}
+
+	// the new validators are bonded right away, as they sign the first block of the
+	// testnet; the validator updates are already in the CometBFT state
+	_, err = app.StakingKeeper.ApplyAndReturnValidatorSetUpdates(ctx)
+	return err
+}
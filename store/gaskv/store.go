@@ -2,26 +2,115 @@ package gaskv
 
 import (
 	"io"
+	"sync/atomic"
+
+	"github.com/hashicorp/go-metrics"
 
 	"cosmossdk.io/store/types"
 )
 
 var _ types.KVStore = &Store{}
 
+// metricsLabels holds the global labels of the read and write byte counters of the
+// stores. The counters are only emitted once EnableMetrics was called.
+var metricsLabels atomic.Pointer[[]metrics.Label]
+
+// EnableMetrics enables the counters of the bytes read and written through the gas
+// stores, labelled by store key, with the given global labels. It must be called
+// once the global metrics are initialized.
+func EnableMetrics(globalLabels [][]string) {
+	labels := make([]metrics.Label, len(globalLabels))
+	for i, gl := range globalLabels {
+		labels[i] = metrics.Label{Name: gl[0], Value: gl[1]}
+	}
+	metricsLabels.Store(&labels)
+}
+
+// DisableMetrics disables the counters of the bytes read and written through the
+// gas stores.
+func DisableMetrics() {
+	metricsLabels.Store(nil)
+}
+
+// Access is a kind of access to a gas store.
+type Access string
+
+const (
+	AccessRead    Access = "read"
+	AccessWrite   Access = "write"
+	AccessHas     Access = "has"
+	AccessDelete  Access = "delete"
+	AccessIterate Access = "iterate" // an iterator creation or step
+)
+
+// AccessObserver is called on every access to a gas store, with the store key name,
+// the kind of access and the number of bytes of the keys and values accessed.
+type AccessObserver func(storeName string, access Access, bytes int)
+
+// accessObserver holds the observer of the accesses to the gas stores, if any.
+var accessObserver atomic.Pointer[AccessObserver]
+
+// SetAccessObserver sets the function called on every access to the gas stores,
+// e.g. to profile the execution of a block. A nil observer removes it.
+func SetAccessObserver(observer AccessObserver) {
+	if observer == nil {
+		accessObserver.Store(nil)
+		return
+	}
+	accessObserver.Store(&observer)
+}
+
+// observe reports an access to a store to the access observer, if any, and counts
+// the bytes read or written, if metrics are enabled.
+func observe(storeName string, access Access, n int) {
+	if observer := accessObserver.Load(); observer != nil {
+		(*observer)(storeName, access, n)
+	}
+
+	switch access {
+	case AccessRead, AccessIterate:
+		incrBytes(storeName, "read", n)
+	case AccessWrite:
+		incrBytes(storeName, "write", n)
+	}
+}
+
+// incrBytes increments the byte counter of the given operation of a store, if
+// metrics are enabled.
+func incrBytes(storeName, op string, n int) {
+	labels := metricsLabels.Load()
+	if labels == nil || n == 0 {
+		return
+	}
+	metrics.IncrCounterWithLabels(
+		[]string{"store", "gaskv", op + "_bytes"},
+		float32(n),
+		append([]metrics.Label{{Name: "store_key", Value: storeName}}, *labels...),
+	)
+}
+
 // Store applies gas tracking to an underlying KVStore. It implements the
 // KVStore interface.
 type Store struct {
 	gasMeter  types.GasMeter
 	gasConfig types.GasConfig
 	parent    types.KVStore
+	name      string
 }
 
 // NewStore returns a reference to a new GasKVStore.
 func NewStore(parent types.KVStore, gasMeter types.GasMeter, gasConfig types.GasConfig) *Store {
+	return NewStoreWithName(parent, gasMeter, gasConfig, "")
+}
+
+// NewStoreWithName returns a reference to a new GasKVStore, whose bytes read and
+// written are counted under the given store key name.
+func NewStoreWithName(parent types.KVStore, gasMeter types.GasMeter, gasConfig types.GasConfig, name string) *Store {
 	kvs := &Store{
 		gasMeter:  gasMeter,
 		gasConfig: gasConfig,
 		parent:    parent,
+		name:      name,
 	}
 	return kvs
 }
@@ -39,6 +128,7 @@ func (gs *Store) Get(key []byte) (value []byte) {
 	// TODO overflow-safe math?
 	gs.gasMeter.ConsumeGas(gs.gasConfig.ReadCostPerByte*types.Gas(len(key)), types.GasReadPerByteDesc)
 	gs.gasMeter.ConsumeGas(gs.gasConfig.ReadCostPerByte*types.Gas(len(value)), types.GasReadPerByteDesc)
+	observe(gs.name, AccessRead, len(key)+len(value))
 
 	return value
 }
@@ -52,11 +142,13 @@ func (gs *Store) Set(key, value []byte) {
 	gs.gasMeter.ConsumeGas(gs.gasConfig.WriteCostPerByte*types.Gas(len(key)), types.GasWritePerByteDesc)
 	gs.gasMeter.ConsumeGas(gs.gasConfig.WriteCostPerByte*types.Gas(len(value)), types.GasWritePerByteDesc)
 	gs.parent.Set(key, value)
+	observe(gs.name, AccessWrite, len(key)+len(value))
 }
 
 // Implements KVStore.
 func (gs *Store) Has(key []byte) bool {
 	gs.gasMeter.ConsumeGas(gs.gasConfig.HasCost, types.GasHasDesc)
+	observe(gs.name, AccessHas, len(key))
 	return gs.parent.Has(key)
 }
 
@@ -65,6 +157,7 @@ func (gs *Store) Delete(key []byte) {
 	// charge gas to prevent certain attack vectors even though space is being freed
 	gs.gasMeter.ConsumeGas(gs.gasConfig.DeleteCost, types.GasDeleteDesc)
 	gs.parent.Delete(key)
+	observe(gs.name, AccessDelete, len(key))
 }
 
 // Iterator implements the KVStore interface. It returns an iterator which
@@ -101,7 +194,9 @@ func (gs *Store) iterator(start, end []byte, ascending bool) types.Iterator {
 	}
 
 	gi := newGasIterator(gs.gasMeter, gs.gasConfig, parent)
+	gi.(*gasIterator).name = gs.name
 	gi.(*gasIterator).consumeSeekGas()
+	gi.(*gasIterator).observeStep()
 
 	return gi
 }
@@ -110,6 +205,7 @@ type gasIterator struct {
 	gasMeter  types.GasMeter
 	gasConfig types.GasConfig
 	parent    types.Iterator
+	name      string
 }
 
 func newGasIterator(gasMeter types.GasMeter, gasConfig types.GasConfig, parent types.Iterator) types.Iterator {
@@ -136,6 +232,7 @@ func (gi *gasIterator) Valid() bool {
 func (gi *gasIterator) Next() {
 	gi.consumeSeekGas()
 	gi.parent.Next()
+	gi.observeStep()
 }
 
 // Key implements the Iterator interface. It returns the current key and it does
@@ -174,3 +271,17 @@ func (gi *gasIterator) consumeSeekGas() {
 	}
 	gi.gasMeter.ConsumeGas(gi.gasConfig.IterNextCostFlat, types.GasIterNextCostFlatDesc)
 }
+
+// observeStep reports an iteration step, reading the bytes of the current key/value
+// pair if the iterator is valid.
+func (gi *gasIterator) observeStep() {
+	if metricsLabels.Load() == nil && accessObserver.Load() == nil {
+		return
+	}
+
+	var n int
+	if gi.Valid() {
+		n = len(gi.Key()) + len(gi.Value())
+	}
+	observe(gi.name, AccessIterate, n)
+}
@@ -3,8 +3,10 @@ package gaskv_test
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	dbm "github.com/cosmos/cosmos-db"
+	"github.com/hashicorp/go-metrics"
 	"github.com/stretchr/testify/require"
 
 	"cosmossdk.io/store/dbadapter"
@@ -118,3 +120,72 @@ func TestGasKVStoreOutOfGasIterator(t *testing.T) {
 	iterator.Next()
 	require.Panics(t, func() { iterator.Value() }, "Expected out-of-gas")
 }
+
+func TestGasKVStoreMetrics(t *testing.T) {
+	sink := metrics.NewInmemSink(time.Minute, time.Minute)
+	_, err := metrics.NewGlobal(metrics.DefaultConfig("test"), sink)
+	require.NoError(t, err)
+	gaskv.EnableMetrics([][]string{{"chain_id", "test-chain"}})
+	t.Cleanup(gaskv.DisableMetrics)
+
+	mem := dbadapter.Store{DB: dbm.NewMemDB()}
+	st := gaskv.NewStoreWithName(mem, types.NewInfiniteGasMeter(), types.KVGasConfig(), "bank")
+	st.Set(keyFmt(1), valFmt(1))
+	require.Equal(t, valFmt(1), st.Get(keyFmt(1)))
+	iterator := st.Iterator(nil, nil)
+	for ; iterator.Valid(); iterator.Next() {
+	}
+	require.NoError(t, iterator.Close())
+
+	counters := sink.Data()[0].Counters
+	size := float64(len(keyFmt(1)) + len(valFmt(1)))
+	require.Equal(t, size, counters["test.store.gaskv.write_bytes;store_key=bank;chain_id=test-chain"].Sum)
+	// read by Get and by the iterator
+	require.Equal(t, 2*size, counters["test.store.gaskv.read_bytes;store_key=bank;chain_id=test-chain"].Sum)
+
+	// nothing is emitted once disabled
+	gaskv.DisableMetrics()
+	st.Set(keyFmt(2), valFmt(2))
+	counters = sink.Data()[0].Counters
+	require.Equal(t, size, counters["test.store.gaskv.write_bytes;store_key=bank;chain_id=test-chain"].Sum)
+}
+
+func TestGasKVStoreAccessObserver(t *testing.T) {
+	accesses := make(map[gaskv.Access]int)
+	bytes := make(map[gaskv.Access]int)
+	gaskv.SetAccessObserver(func(storeName string, access gaskv.Access, n int) {
+		require.Equal(t, "bank", storeName)
+		accesses[access]++
+		bytes[access] += n
+	})
+	t.Cleanup(func() { gaskv.SetAccessObserver(nil) })
+
+	mem := dbadapter.Store{DB: dbm.NewMemDB()}
+	st := gaskv.NewStoreWithName(mem, types.NewInfiniteGasMeter(), types.KVGasConfig(), "bank")
+	st.Set(keyFmt(1), valFmt(1))
+	st.Set(keyFmt(2), valFmt(2))
+	require.Equal(t, valFmt(1), st.Get(keyFmt(1)))
+	require.True(t, st.Has(keyFmt(2)))
+	st.Delete(keyFmt(2))
+	iterator := st.Iterator(nil, nil)
+	for ; iterator.Valid(); iterator.Next() {
+	}
+	require.NoError(t, iterator.Close())
+
+	size := len(keyFmt(1)) + len(valFmt(1))
+	require.Equal(t, map[gaskv.Access]int{
+		gaskv.AccessWrite:   2,
+		gaskv.AccessRead:    1,
+		gaskv.AccessHas:     1,
+		gaskv.AccessDelete:  1,
+		gaskv.AccessIterate: 2, // the creation on the first pair and the step past it
+	}, accesses)
+	require.Equal(t, 2*size, bytes[gaskv.AccessWrite])
+	require.Equal(t, size, bytes[gaskv.AccessRead])
+	require.Equal(t, size, bytes[gaskv.AccessIterate])
+
+	// nothing is observed once the observer is removed
+	gaskv.SetAccessObserver(nil)
+	st.Set(keyFmt(3), valFmt(3))
+	require.Equal(t, 2, accesses[gaskv.AccessWrite])
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"math"
+	"sort"
 
 	protoio "github.com/cosmos/gogoproto/io"
 
@@ -28,6 +29,7 @@ const (
 var (
 	_ store.Committer             = (*CommitStore)(nil)
 	_ snapshots.CommitSnapshotter = (*CommitStore)(nil)
+	_ snapshots.StoreSnapshotter  = (*CommitStore)(nil)
 	_ store.PausablePruner        = (*CommitStore)(nil)
 )
 
@@ -320,6 +322,57 @@ func (c *CommitStore) PausePruning(pause bool) {
 
 // Snapshot implements snapshotstypes.CommitSnapshotter.
 func (c *CommitStore) Snapshot(version uint64, protoWriter protoio.Writer) error {
+	if err := c.validateSnapshotVersion(version); err != nil {
+		return err
+	}
+
+	for storeKey, tree := range c.multiTrees {
+		// TODO: check the parallelism of this loop
+		err := protoWriter.WriteMsg(&snapshotstypes.SnapshotItem{
+			Item: &snapshotstypes.SnapshotItem_Store{
+				Store: &snapshotstypes.SnapshotStoreItem{
+					Name: storeKey,
+				},
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to write store name: %w", err)
+		}
+
+		if err := exportTree(version, tree, protoWriter); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SnapshotStoreKeys implements snapshots.StoreSnapshotter.
+func (c *CommitStore) SnapshotStoreKeys() []string {
+	storeKeys := make([]string, 0, len(c.multiTrees))
+	for storeKey := range c.multiTrees {
+		storeKeys = append(storeKeys, storeKey)
+	}
+	sort.Strings(storeKeys)
+
+	return storeKeys
+}
+
+// SnapshotStore implements snapshots.StoreSnapshotter.
+func (c *CommitStore) SnapshotStore(version uint64, storeKey string, protoWriter protoio.Writer) error {
+	if err := c.validateSnapshotVersion(version); err != nil {
+		return err
+	}
+
+	tree, ok := c.multiTrees[storeKey]
+	if !ok {
+		return fmt.Errorf("store %s not found", storeKey)
+	}
+
+	return exportTree(version, tree, protoWriter)
+}
+
+func (c *CommitStore) validateSnapshotVersion(version uint64) error {
 	if version == 0 {
 		return fmt.Errorf("the snapshot version must be greater than 0")
 	}
@@ -332,46 +385,31 @@ func (c *CommitStore) Snapshot(version uint64, protoWriter protoio.Writer) error
 		return fmt.Errorf("the snapshot version %d is greater than the latest version %d", version, latestVersion)
 	}
 
-	for storeKey, tree := range c.multiTrees {
-		// TODO: check the parallelism of this loop
-		if err := func() error {
-			exporter, err := tree.Export(version)
-			if err != nil {
-				return fmt.Errorf("failed to export tree for version %d: %w", version, err)
-			}
-			defer exporter.Close()
-
-			err = protoWriter.WriteMsg(&snapshotstypes.SnapshotItem{
-				Item: &snapshotstypes.SnapshotItem_Store{
-					Store: &snapshotstypes.SnapshotStoreItem{
-						Name: storeKey,
-					},
-				},
-			})
-			if err != nil {
-				return fmt.Errorf("failed to write store name: %w", err)
-			}
+	return nil
+}
 
-			for {
-				item, err := exporter.Next()
-				if errors.Is(err, ErrorExportDone) {
-					break
-				} else if err != nil {
-					return fmt.Errorf("failed to get the next export node: %w", err)
-				}
+// exportTree writes the nodes of the tree at the given version.
+func exportTree(version uint64, tree Tree, protoWriter protoio.Writer) error {
+	exporter, err := tree.Export(version)
+	if err != nil {
+		return fmt.Errorf("failed to export tree for version %d: %w", version, err)
+	}
+	defer exporter.Close()
 
-				if err = protoWriter.WriteMsg(&snapshotstypes.SnapshotItem{
-					Item: &snapshotstypes.SnapshotItem_IAVL{
-						IAVL: item,
-					},
-				}); err != nil {
-					return fmt.Errorf("failed to write iavl node: %w", err)
-				}
-			}
+	for {
+		item, err := exporter.Next()
+		if errors.Is(err, ErrorExportDone) {
+			break
+		} else if err != nil {
+			return fmt.Errorf("failed to get the next export node: %w", err)
+		}
 
-			return nil
-		}(); err != nil {
-			return err
+		if err = protoWriter.WriteMsg(&snapshotstypes.SnapshotItem{
+			Item: &snapshotstypes.SnapshotItem_IAVL{
+				IAVL: item,
+			},
+		}); err != nil {
+			return fmt.Errorf("failed to write iavl node: %w", err)
 		}
 	}
 
@@ -427,35 +465,8 @@ loop:
 			if importer == nil {
 				return snapshotstypes.SnapshotItem{}, fmt.Errorf("received IAVL node item before store item")
 			}
-			node := item.IAVL
-			if node.Height > int32(math.MaxInt8) {
-				return snapshotstypes.SnapshotItem{}, fmt.Errorf("node height %v cannot exceed %v",
-					item.IAVL.Height, math.MaxInt8)
-			}
-			// Protobuf does not differentiate between []byte{} and nil, but fortunately IAVL does
-			// not allow nil keys nor nil values for leaf nodes, so we can always set them to empty.
-			if node.Key == nil {
-				node.Key = []byte{}
-			}
-			if node.Height == 0 {
-				if node.Value == nil {
-					node.Value = []byte{}
-				}
-
-				// If the node is a leaf node, it will be written to the storage.
-				chStorage <- &corestore.StateChanges{
-					Actor: storeKey,
-					StateChanges: []corestore.KVPair{
-						{
-							Key:   node.Key,
-							Value: node.Value,
-						},
-					},
-				}
-			}
-			err := importer.Add(node)
-			if err != nil {
-				return snapshotstypes.SnapshotItem{}, fmt.Errorf("failed to add node to importer: %w", err)
+			if err := importNode(importer, storeKey, item.IAVL, chStorage); err != nil {
+				return snapshotstypes.SnapshotItem{}, err
 			}
 		default:
 			break loop
@@ -471,6 +482,81 @@ loop:
 	return snapshotItem, c.LoadVersion(version)
 }
 
+// RestoreStore implements snapshots.StoreSnapshotter.
+func (c *CommitStore) RestoreStore(
+	version uint64,
+	storeKey string,
+	protoReader protoio.Reader,
+	chStorage chan<- *corestore.StateChanges,
+) error {
+	tree := c.multiTrees[storeKey]
+	if tree == nil {
+		return fmt.Errorf("store %s not found", storeKey)
+	}
+	importer, err := tree.Import(version)
+	if err != nil {
+		return fmt.Errorf("failed to import tree for version %d: %w", version, err)
+	}
+	defer importer.Close()
+
+	for {
+		snapshotItem := snapshotstypes.SnapshotItem{}
+		err := protoReader.ReadMsg(&snapshotItem)
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return fmt.Errorf("invalid protobuf message: %w", err)
+		}
+
+		node := snapshotItem.GetIAVL()
+		if node == nil {
+			return fmt.Errorf("unexpected snapshot item %T in store %s", snapshotItem.Item, storeKey)
+		}
+		if err := importNode(importer, []byte(storeKey), node, chStorage); err != nil {
+			return err
+		}
+	}
+
+	if err := importer.Commit(); err != nil {
+		return fmt.Errorf("failed to commit importer: %w", err)
+	}
+
+	return nil
+}
+
+// importNode adds the node to the importer, and passes it to the storage if it is a leaf.
+func importNode(importer Importer, storeKey []byte, node *snapshotstypes.SnapshotIAVLItem, chStorage chan<- *corestore.StateChanges) error {
+	if node.Height > int32(math.MaxInt8) {
+		return fmt.Errorf("node height %v cannot exceed %v", node.Height, math.MaxInt8)
+	}
+	// Protobuf does not differentiate between []byte{} and nil, but fortunately IAVL does
+	// not allow nil keys nor nil values for leaf nodes, so we can always set them to empty.
+	if node.Key == nil {
+		node.Key = []byte{}
+	}
+	if node.Height == 0 {
+		if node.Value == nil {
+			node.Value = []byte{}
+		}
+
+		// If the node is a leaf node, it will be written to the storage.
+		chStorage <- &corestore.StateChanges{
+			Actor: storeKey,
+			StateChanges: []corestore.KVPair{
+				{
+					Key:   node.Key,
+					Value: node.Value,
+				},
+			},
+		}
+	}
+	if err := importer.Add(node); err != nil {
+		return fmt.Errorf("failed to add node to importer: %w", err)
+	}
+
+	return nil
+}
+
 func (c *CommitStore) Close() (ferr error) {
 	for _, tree := range c.multiTrees {
 		if err := tree.Close(); err != nil {
@@ -123,6 +123,86 @@ func (s *CommitStoreTestSuite) TestStore_Snapshotter() {
 	}
 }
 
+func (s *CommitStoreTestSuite) TestStore_ChunkedSnapshotter() {
+	storeKeys := []string{storeKey1, storeKey2}
+	commitStore, err := s.NewStore(dbm.NewMemDB(), storeKeys, log.NewNopLogger())
+	s.Require().NoError(err)
+
+	latestVersion := uint64(10)
+	kvCount := 10
+	for i := uint64(1); i <= latestVersion; i++ {
+		kvPairs := make(map[string]corestore.KVPairs)
+		for _, storeKey := range storeKeys {
+			kvPairs[storeKey] = corestore.KVPairs{}
+			for j := 0; j < kvCount; j++ {
+				key := []byte(fmt.Sprintf("key-%d-%d", i, j))
+				value := []byte(fmt.Sprintf("value-%d-%d", i, j))
+				kvPairs[storeKey] = append(kvPairs[storeKey], corestore.KVPair{Key: key, Value: value})
+			}
+		}
+		s.Require().NoError(commitStore.WriteChangeset(corestore.NewChangesetWithPairs(kvPairs)))
+
+		_, err = commitStore.Commit(i)
+		s.Require().NoError(err)
+	}
+	cInfo := commitStore.WorkingCommitInfo(latestVersion)
+
+	// create a snapshot, whose stores are chunked independently
+	sourceStore, err := snapshots.NewStore(s.T().TempDir())
+	s.Require().NoError(err)
+	source := snapshots.NewManager(sourceStore, snapshots.SnapshotOptions{Interval: 1, KeepRecent: 1, ChunkedStores: true}, commitStore, nil, nil, log.NewNopLogger())
+	snapshot, err := source.Create(latestVersion)
+	s.Require().NoError(err)
+	s.Require().Equal(snapshotstypes.FormatChunkedStores, snapshot.Format)
+	s.Require().Equal(uint32(len(storeKeys)), snapshot.Chunks)
+
+	// restore it
+	targetStore, err := s.NewStore(dbm.NewMemDB(), storeKeys, log.NewNopLogger())
+	s.Require().NoError(err)
+	targetSnapshotStore, err := snapshots.NewStore(s.T().TempDir())
+	s.Require().NoError(err)
+	storage := &leavesStorageSnapshotter{leaves: make(map[string]string)}
+	target := snapshots.NewManager(targetSnapshotStore, snapshots.NewSnapshotOptions(1, 1), targetStore, storage, nil, log.NewNopLogger())
+	s.Require().NoError(target.Restore(*snapshot))
+	for i := uint32(0); i < snapshot.Chunks; i++ {
+		chunk, err := source.LoadChunk(snapshot.Height, snapshot.Format, i)
+		s.Require().NoError(err)
+		done, err := target.RestoreChunk(chunk)
+		s.Require().NoError(err)
+		s.Require().Equal(i == snapshot.Chunks-1, done)
+	}
+
+	s.Require().Equal(len(storeKeys)*kvCount*int(latestVersion), len(storage.leaves))
+	restoredVersion, err := targetStore.GetLatestVersion()
+	s.Require().NoError(err)
+	s.Require().Equal(latestVersion, restoredVersion)
+	targetCommitInfo := targetStore.WorkingCommitInfo(latestVersion)
+	for _, storeInfo := range targetCommitInfo.StoreInfos {
+		matched := false
+		for _, latestStoreInfo := range cInfo.StoreInfos {
+			if bytes.Equal(storeInfo.Name, latestStoreInfo.Name) {
+				s.Require().Equal(latestStoreInfo.GetHash(), storeInfo.GetHash())
+				matched = true
+			}
+		}
+		s.Require().True(matched)
+	}
+}
+
+// leavesStorageSnapshotter collects the restored leaves.
+type leavesStorageSnapshotter struct {
+	leaves map[string]string
+}
+
+func (l *leavesStorageSnapshotter) Restore(version uint64, chStorage <-chan *corestore.StateChanges) error {
+	for kv := range chStorage {
+		for _, pair := range kv.StateChanges {
+			l.leaves[fmt.Sprintf("%s_%s", kv.Actor, pair.Key)] = string(pair.Value)
+		}
+	}
+	return nil
+}
+
 func (s *CommitStoreTestSuite) TestStore_Pruning() {
 	storeKeys := []string{storeKey1, storeKey2}
 	pruneOpts := &store.PruneOptions{
@@ -6,7 +6,10 @@ import (
 	"compress/zlib"
 	"crypto/sha256"
 	"errors"
+	"fmt"
 	"io"
+	"sort"
+	"sync"
 	"testing"
 	"time"
 
@@ -161,6 +164,24 @@ func (m *mockStorageSnapshotter) Restore(version uint64, chStorage <-chan *cores
 	return nil
 }
 
+// mockResumableStorageSnapshotter counts the restores and the resumed restores.
+type mockResumableStorageSnapshotter struct {
+	restores int
+	resumes  int
+}
+
+var _ snapshots.ResumableStorageSnapshotter = (*mockResumableStorageSnapshotter)(nil)
+
+func (m *mockResumableStorageSnapshotter) Restore(version uint64, chStorage <-chan *corestore.StateChanges) error {
+	m.restores++
+	return nil
+}
+
+func (m *mockResumableStorageSnapshotter) ResumeRestore(version uint64, chStorage <-chan *corestore.StateChanges) error {
+	m.resumes++
+	return nil
+}
+
 type mockErrorCommitSnapshotter struct{}
 
 var _ snapshots.CommitSnapshotter = (*mockErrorCommitSnapshotter)(nil)
@@ -287,3 +308,110 @@ func (s *extSnapshotter) RestoreExtension(height uint64, format uint32, payloadR
 	// finalize restoration
 	return nil
 }
+
+// mockStoreSnapshotter snapshots and restores stores of IAVL leaves independently.
+type mockStoreSnapshotter struct {
+	mockCommitSnapshotter
+
+	mtx       sync.Mutex
+	stores    map[string][][]byte
+	restores  map[string]int
+	failStore string
+	loaded    uint64
+}
+
+var _ snapshots.StoreSnapshotter = (*mockStoreSnapshotter)(nil)
+
+func newMockStoreSnapshotter(stores map[string][][]byte) *mockStoreSnapshotter {
+	return &mockStoreSnapshotter{
+		stores:   stores,
+		restores: map[string]int{},
+	}
+}
+
+func (m *mockStoreSnapshotter) SnapshotStoreKeys() []string {
+	storeKeys := make([]string, 0, len(m.stores))
+	for storeKey := range m.stores {
+		storeKeys = append(storeKeys, storeKey)
+	}
+	sort.Strings(storeKeys)
+	return storeKeys
+}
+
+func (m *mockStoreSnapshotter) SnapshotStore(version uint64, storeKey string, protoWriter protoio.Writer) error {
+	for _, leaf := range m.stores[storeKey] {
+		err := protoWriter.WriteMsg(&snapshotstypes.SnapshotItem{
+			Item: &snapshotstypes.SnapshotItem_IAVL{
+				IAVL: &snapshotstypes.SnapshotIAVLItem{Key: leaf, Value: leaf},
+			},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *mockStoreSnapshotter) RestoreStore(
+	version uint64, storeKey string, protoReader protoio.Reader, chStorage chan<- *corestore.StateChanges,
+) error {
+	m.mtx.Lock()
+	m.restores[storeKey]++
+	fail := m.failStore == storeKey
+	m.mtx.Unlock()
+	if fail {
+		return errors.New("mock restore store error")
+	}
+
+	leaves := [][]byte{}
+	for {
+		var item snapshotstypes.SnapshotItem
+		err := protoReader.ReadMsg(&item)
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return err
+		}
+		node := item.GetIAVL()
+		if node == nil {
+			return fmt.Errorf("unexpected snapshot item %T", item.Item)
+		}
+		leaves = append(leaves, node.Key)
+		chStorage <- &corestore.StateChanges{
+			Actor:        []byte(storeKey),
+			StateChanges: []corestore.KVPair{{Key: node.Key, Value: node.Value}},
+		}
+	}
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.stores[storeKey] = leaves
+	return nil
+}
+
+func (m *mockStoreSnapshotter) LoadVersion(version uint64) error {
+	m.loaded = version
+	return nil
+}
+
+// countingStorageSnapshotter counts the pairs restored in every store, and the resumed
+// restores.
+type countingStorageSnapshotter struct {
+	pairs   map[string]int
+	resumes int
+}
+
+var _ snapshots.ResumableStorageSnapshotter = (*countingStorageSnapshotter)(nil)
+
+func (m *countingStorageSnapshotter) Restore(version uint64, chStorage <-chan *corestore.StateChanges) error {
+	m.pairs = map[string]int{}
+	for changes := range chStorage {
+		m.pairs[string(changes.Actor)] += len(changes.StateChanges)
+	}
+	return nil
+}
+
+func (m *countingStorageSnapshotter) ResumeRestore(version uint64, chStorage <-chan *corestore.StateChanges) error {
+	m.resumes++
+	return m.Restore(version, chStorage)
+}
@@ -11,6 +11,9 @@ import (
 	"sort"
 	"sync"
 
+	protoio "github.com/cosmos/gogoproto/io"
+	"github.com/cosmos/gogoproto/proto"
+
 	"cosmossdk.io/core/log"
 	corestore "cosmossdk.io/core/store"
 	errorsmod "cosmossdk.io/errors"
@@ -69,6 +72,7 @@ const (
 	chunkBufferSize                 = 4
 	chunkIDBufferSize               = 1024
 	defaultStorageChannelBufferSize = 1024
+	maxConcurrentStoreRestores      = 8
 
 	snapshotMaxItemSize = int(64e6) // SDK has no key/value size limit, so we set an arbitrary limit
 )
@@ -187,9 +191,15 @@ func (m *Manager) Create(height uint64) (*types.Snapshot, error) {
 
 	// Spawn goroutine to generate snapshot chunks and pass their io.ReadClosers through a channel
 	ch := make(chan io.ReadCloser)
-	go m.createSnapshot(height, ch)
+	format := types.CurrentFormat
+	if storeSnapshotter, ok := m.commitSnapshotter.(StoreSnapshotter); ok && m.opts.ChunkedStores {
+		format = types.FormatChunkedStores
+		go m.createChunkedSnapshot(height, storeSnapshotter, ch)
+	} else {
+		go m.createSnapshot(height, ch)
+	}
 
-	return m.store.Save(height, types.CurrentFormat, ch)
+	return m.store.Save(height, format, ch)
 }
 
 // createSnapshot do the heavy work of snapshotting after the validations of request are done
@@ -234,6 +244,57 @@ func (m *Manager) createSnapshot(height uint64, ch chan<- io.ReadCloser) {
 	}
 }
 
+// createChunkedSnapshot writes the chunks of a FormatChunkedStores snapshot to the channel:
+// the chunks of every store, followed by the chunks of every extension.
+func (m *Manager) createChunkedSnapshot(height uint64, snapshotter StoreSnapshotter, ch chan<- io.ReadCloser) {
+	if err := m.writeChunkedSnapshot(height, snapshotter, ch); err != nil {
+		// pass the error to the reader through a dummy chunk
+		pr, pw := io.Pipe()
+		_ = pw.CloseWithError(err) // CloseWithError always returns nil
+		ch <- pr
+	}
+	close(ch)
+}
+
+func (m *Manager) writeChunkedSnapshot(height uint64, snapshotter StoreSnapshotter, ch chan<- io.ReadCloser) error {
+	for _, storeKey := range snapshotter.SnapshotStoreKeys() {
+		sectionWriter := newSectionWriter(ch, &types.SnapshotItem{
+			Item: &types.SnapshotItem_Store{
+				Store: &types.SnapshotStoreItem{
+					Name: storeKey,
+				},
+			},
+		})
+		if err := snapshotter.SnapshotStore(height, storeKey, sectionWriter); err != nil {
+			return errorsmod.Wrapf(err, "store %s snapshot", storeKey)
+		}
+		if err := sectionWriter.Close(); err != nil {
+			return err
+		}
+	}
+	for _, name := range m.sortedExtensionNames() {
+		extension := m.extensions[name]
+		sectionWriter := newSectionWriter(ch, &types.SnapshotItem{
+			Item: &types.SnapshotItem_Extension{
+				Extension: &types.SnapshotExtensionMeta{
+					Name:   name,
+					Format: extension.SnapshotFormat(),
+				},
+			},
+		})
+		payloadWriter := func(payload []byte) error {
+			return types.WriteExtensionPayload(sectionWriter, payload)
+		}
+		if err := extension.SnapshotExtension(height, payloadWriter); err != nil {
+			return errorsmod.Wrapf(err, "extension %s snapshot", name)
+		}
+		if err := sectionWriter.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // CreateMigration creates a migration snapshot and writes it to the given writer.
 // It is used to migrate the state from the original store to the store/v2.
 func (m *Manager) CreateMigration(height uint64, protoWriter WriteCloser) error {
@@ -310,9 +371,12 @@ func (m *Manager) Restore(snapshot types.Snapshot) error {
 	defer m.mtx.Unlock()
 
 	// check multistore supported format preemptive
-	if snapshot.Format != types.CurrentFormat {
+	if !types.IsFormatSupported(snapshot.Format) {
 		return errorsmod.Wrapf(types.ErrUnknownFormat, "snapshot format %v", snapshot.Format)
 	}
+	if _, ok := m.commitSnapshotter.(StoreSnapshotter); !ok && snapshot.Format == types.FormatChunkedStores {
+		return errorsmod.Wrapf(types.ErrUnknownFormat, "snapshot format %v is not supported by the commitment snapshotter", snapshot.Format)
+	}
 	if snapshot.Height == 0 {
 		return errorsmod.Wrap(storeerrors.ErrLogic, "cannot restore snapshot at height 0")
 	}
@@ -335,10 +399,13 @@ func (m *Manager) Restore(snapshot types.Snapshot) error {
 		return errorsmod.Wrapf(err, "failed to create snapshot directory %q", dir)
 	}
 
-	chChunks := m.loadChunkStream(snapshot.Height, snapshot.Format, chChunkIDs)
-
 	go func() {
-		err := m.doRestoreSnapshot(snapshot, chChunks)
+		var err error
+		if snapshot.Format == types.FormatChunkedStores {
+			err = m.doRestoreChunkedSnapshot(snapshot, chChunkIDs)
+		} else {
+			err = m.doRestoreSnapshot(snapshot, m.loadChunkStream(snapshot.Height, snapshot.Format, chChunkIDs))
+		}
 		chDone <- restoreDone{
 			complete: err == nil,
 			err:      err,
@@ -405,8 +472,7 @@ func (m *Manager) doRestoreSnapshot(snapshot types.Snapshot, chChunks <-chan io.
 	storageErrs := make(chan error, 1)
 	go func() {
 		defer close(storageErrs)
-		err := m.storageSnapshotter.Restore(snapshot.Height, chStorage)
-		if err != nil {
+		if err := m.storageSnapshotter.Restore(snapshot.Height, chStorage); err != nil {
 			storageErrs <- err
 		}
 	}()
@@ -450,6 +516,258 @@ func (m *Manager) doRestoreSnapshot(snapshot types.Snapshot, chChunks <-chan io.
 	return nil
 }
 
+// extensionSection is an extension of a FormatChunkedStores snapshot, whose restoration
+// is deferred until all the stores are restored.
+type extensionSection struct {
+	header   *types.SnapshotItem
+	chunkIDs <-chan uint32
+}
+
+// doRestoreChunkedSnapshot restores a FormatChunkedStores snapshot. The stores are restored
+// concurrently, each one as soon as its first chunk is received, while the extensions are
+// restored in order once all the stores are restored. The stores restored by a previous,
+// interrupted restore of the snapshot are not restored again.
+func (m *Manager) doRestoreChunkedSnapshot(snapshot types.Snapshot, chChunkIDs <-chan uint32) error {
+	snapshotter, ok := m.commitSnapshotter.(StoreSnapshotter)
+	if !ok {
+		return errorsmod.Wrapf(types.ErrUnknownFormat, "snapshot format %v is not supported by the commitment snapshotter", snapshot.Format)
+	}
+	dir := m.store.pathSnapshot(snapshot.Height, snapshot.Format)
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return errorsmod.Wrapf(err, "failed to create snapshot directory %q", dir)
+	}
+
+	// chStorage is the channel to pass the KV pairs to the storage snapshotter.
+	chStorage := make(chan *corestore.StateChanges, defaultStorageChannelBufferSize)
+	storageErrs := make(chan error, 1)
+	go func() {
+		defer close(storageErrs)
+		restore := m.storageSnapshotter.Restore
+		if resumable, ok := m.storageSnapshotter.(ResumableStorageSnapshotter); ok {
+			restore = resumable.ResumeRestore
+		}
+		if err := restore(snapshot.Height, chStorage); err != nil {
+			storageErrs <- err
+			// drain the channel, so that the store restorations are not blocked
+			for range chStorage {
+			}
+		}
+	}()
+
+	extensions, err := m.restoreStores(snapshot, snapshotter, chChunkIDs, chStorage)
+	close(chStorage)
+	// wait for storage snapshotter to complete, even if the restore failed, so that it
+	// doesn't outlive the restore operation
+	storageErr := <-storageErrs
+	if err != nil {
+		return err
+	}
+	if storageErr != nil {
+		return errorsmod.Wrap(storageErr, "storage snapshotter")
+	}
+	if err := snapshotter.LoadVersion(snapshot.Height); err != nil {
+		return errorsmod.Wrap(err, "multistore restore")
+	}
+
+	for _, section := range extensions {
+		if err := m.restoreExtensionSection(snapshot, section); err != nil {
+			return err
+		}
+	}
+
+	return m.store.clearRestoredStores(snapshot.Height, snapshot.Format)
+}
+
+// restoreStores dispatches the chunks of a FormatChunkedStores snapshot to the store
+// restorations, and returns the extension sections once all the stores are restored.
+func (m *Manager) restoreStores(
+	snapshot types.Snapshot, snapshotter StoreSnapshotter, chChunkIDs <-chan uint32, chStorage chan<- *corestore.StateChanges,
+) ([]extensionSection, error) {
+	var (
+		wg         sync.WaitGroup
+		errMtx     sync.Mutex
+		restoreErr error
+		extensions []extensionSection
+		// the chunk IDs of the section being received, buffered so that dispatching
+		// never blocks on a store waiting for its restoration to start
+		section       chan uint32
+		sectionHeader types.SnapshotItem
+		seen          = make(map[string]bool)
+		// sem limits the number of stores restored concurrently
+		sem = make(chan struct{}, maxConcurrentStoreRestores)
+	)
+	setErr := func(err error) {
+		errMtx.Lock()
+		defer errMtx.Unlock()
+		if restoreErr == nil {
+			restoreErr = err
+		}
+	}
+	getErr := func() error {
+		errMtx.Lock()
+		defer errMtx.Unlock()
+		return restoreErr
+	}
+
+	err := func() error {
+		defer func() {
+			if section != nil {
+				close(section)
+			}
+		}()
+
+		for chunkID := range chChunkIDs {
+			if err := getErr(); err != nil {
+				return err
+			}
+
+			header, err := m.store.loadChunkHeader(snapshot.Height, snapshot.Format, chunkID)
+			if err != nil {
+				return errorsmod.Wrapf(err, "read header of chunk %d", chunkID)
+			}
+			if section == nil || !proto.Equal(&header, &sectionHeader) {
+				if section != nil {
+					close(section)
+				}
+				section = make(chan uint32, snapshot.Chunks)
+				sectionHeader = header
+
+				switch item := header.Item.(type) {
+				case *types.SnapshotItem_Store:
+					if len(extensions) > 0 {
+						return errorsmod.Wrapf(storeerrors.ErrLogic, "store %s after extensions", item.Store.Name)
+					}
+					if seen[item.Store.Name] {
+						return errorsmod.Wrapf(storeerrors.ErrLogic, "store %s is not contiguous", item.Store.Name)
+					}
+					seen[item.Store.Name] = true
+
+					wg.Add(1)
+					go func(storeKey string, header *types.SnapshotItem, chunkIDs <-chan uint32) {
+						defer wg.Done()
+						sem <- struct{}{}
+						defer func() { <-sem }()
+						if err := m.restoreStore(snapshot, snapshotter, storeKey, header, chunkIDs, chStorage); err != nil {
+							setErr(errorsmod.Wrapf(err, "store %s restore", storeKey))
+						}
+					}(item.Store.Name, &header, section)
+
+				case *types.SnapshotItem_Extension:
+					extensions = append(extensions, extensionSection{header: &header, chunkIDs: section})
+
+				default:
+					return errorsmod.Wrapf(storeerrors.ErrLogic, "unknown snapshot item %T in chunk %d header", header.Item, chunkID)
+				}
+			}
+			section <- chunkID
+		}
+		return nil
+	}()
+
+	wg.Wait()
+	if err != nil {
+		return nil, err
+	}
+	return extensions, getErr()
+}
+
+// restoreStore restores a store from its chunks. If the store was already restored by an
+// interrupted restore of the snapshot, only its leaves are passed again to the storage
+// snapshotter, which may not have persisted them.
+func (m *Manager) restoreStore(
+	snapshot types.Snapshot, snapshotter StoreSnapshotter, storeKey string, header *types.SnapshotItem,
+	chunkIDs <-chan uint32, chStorage chan<- *corestore.StateChanges,
+) error {
+	loadChunk := func(chunkID uint32) (io.ReadCloser, error) {
+		return m.store.loadChunkFile(snapshot.Height, snapshot.Format, chunkID)
+	}
+	reader := newSectionReader(chunkIDs, loadChunk, header)
+	defer reader.Close()
+
+	if m.store.isStoreRestored(snapshot.Height, snapshot.Format, snapshot.Hash, storeKey) {
+		m.logger.Info("store already restored, skipping", "store", storeKey, "height", snapshot.Height)
+		return restoreStoreLeaves(storeKey, reader, chStorage)
+	}
+
+	if err := snapshotter.RestoreStore(snapshot.Height, storeKey, reader, chStorage); err != nil {
+		return err
+	}
+	return m.store.markStoreRestored(snapshot.Height, snapshot.Format, snapshot.Hash, storeKey)
+}
+
+// restoreStoreLeaves passes the leaves of a store to the storage snapshotter.
+func restoreStoreLeaves(storeKey string, reader protoio.Reader, chStorage chan<- *corestore.StateChanges) error {
+	var item types.SnapshotItem
+	for {
+		item.Reset()
+		err := reader.ReadMsg(&item)
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return errorsmod.Wrap(err, "invalid protobuf message")
+		}
+
+		node := item.GetIAVL()
+		if node == nil {
+			return errorsmod.Wrapf(storeerrors.ErrLogic, "unexpected snapshot item %T", item.Item)
+		}
+		if node.Height != 0 {
+			continue
+		}
+		key, value := node.Key, node.Value
+		if key == nil {
+			key = []byte{}
+		}
+		if value == nil {
+			value = []byte{}
+		}
+		chStorage <- &corestore.StateChanges{
+			Actor: []byte(storeKey),
+			StateChanges: []corestore.KVPair{
+				{
+					Key:   key,
+					Value: value,
+				},
+			},
+		}
+	}
+}
+
+// restoreExtensionSection restores an extension from its chunks.
+func (m *Manager) restoreExtensionSection(snapshot types.Snapshot, section extensionSection) error {
+	metadata := section.header.GetExtension()
+	extension, ok := m.extensions[metadata.Name]
+	if !ok {
+		return errorsmod.Wrapf(storeerrors.ErrLogic, "unknown extension snapshotter %s", metadata.Name)
+	}
+	if !IsFormatSupported(extension, metadata.Format) {
+		return errorsmod.Wrapf(types.ErrUnknownFormat, "format %v for extension %s", metadata.Format, metadata.Name)
+	}
+
+	loadChunk := func(chunkID uint32) (io.ReadCloser, error) {
+		return m.store.loadChunkFile(snapshot.Height, snapshot.Format, chunkID)
+	}
+	reader := newSectionReader(section.chunkIDs, loadChunk, section.header)
+	defer reader.Close()
+
+	var item types.SnapshotItem
+	payloadReader := func() ([]byte, error) {
+		item.Reset()
+		if err := reader.ReadMsg(&item); err != nil {
+			return nil, err
+		}
+		payload := item.GetExtensionPayload()
+		if payload == nil {
+			return nil, errorsmod.Wrapf(storeerrors.ErrLogic, "unexpected snapshot item %T", item.Item)
+		}
+		return payload.Payload, nil
+	}
+	if err := extension.RestoreExtension(snapshot.Height, metadata.Format, payloadReader); err != nil {
+		return errorsmod.Wrapf(err, "extension %s restore", metadata.Name)
+	}
+	return nil
+}
+
 // RestoreChunk adds a chunk to an active snapshot restoration, mirroring ABCI ApplySnapshotChunk.
 // Chunks must be given until the restore is complete, returning true, or a chunk errors.
 func (m *Manager) RestoreChunk(chunk []byte) (bool, error) {
@@ -516,6 +834,10 @@ func (m *Manager) RestoreChunk(chunk []byte) (bool, error) {
 
 // RestoreLocalSnapshot restores app state from a local snapshot.
 func (m *Manager) RestoreLocalSnapshot(height uint64, format uint32) error {
+	if format == types.FormatChunkedStores {
+		return m.restoreLocalChunkedSnapshot(height, format)
+	}
+
 	snapshot, ch, err := m.store.Load(height, format)
 	if err != nil {
 		return err
@@ -537,6 +859,35 @@ func (m *Manager) RestoreLocalSnapshot(height uint64, format uint32) error {
 	return m.doRestoreSnapshot(*snapshot, ch)
 }
 
+// restoreLocalChunkedSnapshot restores app state from a local FormatChunkedStores snapshot.
+func (m *Manager) restoreLocalChunkedSnapshot(height uint64, format uint32) error {
+	snapshot, err := m.store.Get(height, format)
+	if err != nil {
+		return err
+	}
+
+	if snapshot == nil {
+		return fmt.Errorf("snapshot doesn't exist, height: %d, format: %d", height, format)
+	}
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	err = m.beginLocked(opRestore)
+	if err != nil {
+		return err
+	}
+	defer m.endLocked()
+
+	chChunkIDs := make(chan uint32, snapshot.Chunks)
+	for i := uint32(0); i < snapshot.Chunks; i++ {
+		chChunkIDs <- i
+	}
+	close(chChunkIDs)
+
+	return m.doRestoreChunkedSnapshot(*snapshot, chChunkIDs)
+}
+
 // sortedExtensionNames sort extension names for deterministic iteration.
 func (m *Manager) sortedExtensionNames() []string {
 	names := make([]string, 0, len(m.extensions))
@@ -1,6 +1,7 @@
 package snapshots_test
 
 import (
+	"bytes"
 	"errors"
 	"testing"
 
@@ -12,7 +13,10 @@ import (
 	"cosmossdk.io/store/v2/snapshots/types"
 )
 
-var opts = snapshots.NewSnapshotOptions(1500, 2)
+var (
+	opts        = snapshots.NewSnapshotOptions(1500, 2)
+	chunkedOpts = snapshots.SnapshotOptions{Interval: 1500, KeepRecent: 2, ChunkedStores: true}
+)
 
 func TestManager_List(t *testing.T) {
 	store := setupStore(t)
@@ -129,8 +133,9 @@ func TestManager_Prune(t *testing.T) {
 func TestManager_Restore(t *testing.T) {
 	store := setupStore(t)
 	target := &mockCommitSnapshotter{}
+	storage := &mockResumableStorageSnapshotter{}
 	extSnapshotter := newExtSnapshotter(0)
-	manager := snapshots.NewManager(store, opts, target, &mockStorageSnapshotter{}, nil, log.NewNopLogger())
+	manager := snapshots.NewManager(store, opts, target, storage, nil, log.NewNopLogger())
 	err := manager.RegisterExtensions(extSnapshotter)
 	require.NoError(t, err)
 
@@ -203,6 +208,10 @@ func TestManager_Restore(t *testing.T) {
 	assert.Equal(t, expectItems, target.items)
 	assert.Equal(t, 10, len(extSnapshotter.state))
 
+	// the storage of the legacy format is restored from scratch, never resumed
+	assert.Equal(t, 1, storage.restores)
+	assert.Zero(t, storage.resumes)
+
 	// The snapshot is saved in local snapshot store
 	snapshots, err := store.List()
 	require.NoError(t, err)
@@ -254,3 +263,136 @@ func TestManager_TakeError(t *testing.T) {
 	_, err = manager.Create(1)
 	require.Error(t, err)
 }
+
+func TestManager_TakeDefaultFormat(t *testing.T) {
+	// the chunked stores format must be enabled in the options
+	manager := snapshots.NewManager(setupStore(t), opts, newMockStoreSnapshotter(chunkedStores()), &mockStorageSnapshotter{}, nil, log.NewNopLogger())
+	snapshot, err := manager.Create(5)
+	require.NoError(t, err)
+	require.Equal(t, types.CurrentFormat, snapshot.Format)
+}
+
+func chunkedStores() map[string][][]byte {
+	// the "large" store is larger than a chunk, so it spans several chunks
+	large := make([][]byte, 0, 1200)
+	for i := 0; i < 1200; i++ {
+		large = append(large, bytes.Repeat([]byte{byte(i)}, 5_000))
+	}
+	return map[string][][]byte{
+		"empty": nil,
+		"large": large,
+		"small": {{1, 2, 3}, {4, 5, 6}},
+	}
+}
+
+func TestManager_TakeChunkedStores(t *testing.T) {
+	store := setupStore(t)
+	stores := chunkedStores()
+	source := newMockStoreSnapshotter(stores)
+	manager := snapshots.NewManager(store, chunkedOpts, source, &mockStorageSnapshotter{}, nil, log.NewNopLogger())
+	require.NoError(t, manager.RegisterExtensions(newExtSnapshotter(10)))
+
+	snapshot, err := manager.Create(5)
+	require.NoError(t, err)
+	require.Equal(t, types.FormatChunkedStores, snapshot.Format)
+	// one chunk for the empty and the small stores and the extension, and two for the large store
+	require.Equal(t, uint32(5), snapshot.Chunks)
+	require.Len(t, snapshot.Metadata.ChunkHashes, 5)
+
+	// the snapshot is deterministic
+	otherManager := snapshots.NewManager(setupStore(t), chunkedOpts, newMockStoreSnapshotter(chunkedStores()), &mockStorageSnapshotter{}, nil, log.NewNopLogger())
+	require.NoError(t, otherManager.RegisterExtensions(newExtSnapshotter(10)))
+	other, err := otherManager.Create(5)
+	require.NoError(t, err)
+	require.Equal(t, snapshot.Hash, other.Hash)
+
+	// restore the snapshot through its chunks
+	target := newMockStoreSnapshotter(map[string][][]byte{})
+	storage := &countingStorageSnapshotter{}
+	extSnapshotter := newExtSnapshotter(0)
+	targetManager := snapshots.NewManager(setupStore(t), opts, target, storage, nil, log.NewNopLogger())
+	require.NoError(t, targetManager.RegisterExtensions(extSnapshotter))
+
+	require.NoError(t, targetManager.Restore(*snapshot))
+	for i := uint32(0); i < snapshot.Chunks; i++ {
+		chunk, err := manager.LoadChunk(snapshot.Height, snapshot.Format, i)
+		require.NoError(t, err)
+		done, err := targetManager.RestoreChunk(chunk)
+		require.NoError(t, err)
+		require.Equal(t, i == snapshot.Chunks-1, done)
+	}
+
+	require.Equal(t, uint64(5), target.loaded)
+	require.Equal(t, stores["large"], target.stores["large"])
+	require.Equal(t, stores["small"], target.stores["small"])
+	require.Empty(t, target.stores["empty"])
+	require.Equal(t, map[string]int{"large": 1200, "small": 2}, storage.pairs)
+	require.Len(t, extSnapshotter.state, 10)
+}
+
+func TestManager_RestoreChunkedStoresResume(t *testing.T) {
+	stores := chunkedStores()
+	source := snapshots.NewManager(setupStore(t), chunkedOpts, newMockStoreSnapshotter(stores), &mockStorageSnapshotter{}, nil, log.NewNopLogger())
+	snapshot, err := source.Create(5)
+	require.NoError(t, err)
+
+	store := setupStore(t)
+	target := newMockStoreSnapshotter(map[string][][]byte{})
+	storage := &countingStorageSnapshotter{}
+	manager := snapshots.NewManager(store, opts, target, storage, nil, log.NewNopLogger())
+
+	restoreFrom := func(source *snapshots.Manager, snapshot *types.Snapshot) error {
+		if err := manager.Restore(*snapshot); err != nil {
+			return err
+		}
+		for i := uint32(0); i < snapshot.Chunks; i++ {
+			chunk, err := source.LoadChunk(snapshot.Height, snapshot.Format, i)
+			require.NoError(t, err)
+			if _, err := manager.RestoreChunk(chunk); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	restore := func() error { return restoreFrom(source, snapshot) }
+
+	// the restore of the small store fails, while the other stores are restored
+	target.failStore = "small"
+	require.Error(t, restore())
+	require.Equal(t, map[string]int{"empty": 1, "large": 1, "small": 1}, target.restores)
+
+	// the resumed restore only restores the small store again, but the storage gets all the pairs
+	target.failStore = ""
+	require.NoError(t, restore())
+	require.Equal(t, map[string]int{"empty": 1, "large": 1, "small": 2}, target.restores)
+	require.Equal(t, stores["small"], target.stores["small"])
+	require.Equal(t, map[string]int{"large": 1200, "small": 2}, storage.pairs)
+
+	// a complete restore is not resumed
+	target.stores = map[string][][]byte{}
+	require.NoError(t, manager.RestoreLocalSnapshot(snapshot.Height, snapshot.Format))
+	require.Equal(t, map[string]int{"empty": 2, "large": 2, "small": 3}, target.restores)
+	require.Equal(t, stores["large"], target.stores["large"])
+	require.Equal(t, 3, storage.resumes)
+
+	// after a failed restore, a different snapshot at the same height and format restores
+	// all its stores
+	target.failStore = "small"
+	require.Error(t, restore())
+	require.Equal(t, map[string]int{"empty": 3, "large": 3, "small": 4}, target.restores)
+
+	otherStores := chunkedStores()
+	otherStores["large"] = otherStores["large"][:10]
+	otherSource := snapshots.NewManager(setupStore(t), chunkedOpts, newMockStoreSnapshotter(otherStores), &mockStorageSnapshotter{}, nil, log.NewNopLogger())
+	other, err := otherSource.Create(5)
+	require.NoError(t, err)
+	require.Equal(t, snapshot.Format, other.Format)
+	require.NotEqual(t, snapshot.Hash, other.Hash)
+
+	target.failStore = ""
+	target.stores = map[string][][]byte{}
+	require.NoError(t, restoreFrom(otherSource, other))
+	require.Equal(t, map[string]int{"empty": 4, "large": 4, "small": 5}, target.restores)
+	require.Equal(t, otherStores["large"], target.stores["large"])
+	require.Equal(t, map[string]int{"large": 10, "small": 2}, storage.pairs)
+}
@@ -8,6 +8,12 @@ type SnapshotOptions struct {
 
 	// KeepRecent defines how many snapshots to keep in heights.
 	KeepRecent uint32
+
+	// ChunkedStores defines if the snapshots are taken in the FormatChunkedStores
+	// format, when the commitment snapshotter is a StoreSnapshotter. The snapshots
+	// are taken in the CurrentFormat otherwise, which is the default since the nodes
+	// not supporting FormatChunkedStores can't state sync from such snapshots.
+	ChunkedStores bool
 }
 
 func NewSnapshotOptions(interval uint64, keepRecent uint32) SnapshotOptions {
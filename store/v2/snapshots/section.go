@@ -0,0 +1,216 @@
+package snapshots
+
+import (
+	"bytes"
+	"compress/zlib"
+	stderrors "errors"
+	"io"
+
+	protoio "github.com/cosmos/gogoproto/io"
+	"github.com/cosmos/gogoproto/proto"
+
+	"cosmossdk.io/errors"
+	storeerrors "cosmossdk.io/store/v2/errors"
+	"cosmossdk.io/store/v2/snapshots/types"
+)
+
+// sectionWriter writes the items of a section, i.e. a store or an extension, of a
+// FormatChunkedStores snapshot. Every chunk is a self-contained compressed delimited
+// protobuf stream, starting with the header item of the section. A chunk is cut once
+// snapshotChunkSize uncompressed bytes are written, so that chunks are identical across
+// nodes.
+type sectionWriter struct {
+	ch     chan<- io.ReadCloser
+	header *types.SnapshotItem
+	chunks int
+
+	buf         *bytes.Buffer
+	zWriter     *zlib.Writer
+	counter     *countWriter
+	protoWriter protoio.Writer
+}
+
+var _ protoio.Writer = (*sectionWriter)(nil)
+
+// newSectionWriter creates a section writer sending the chunks to the channel.
+func newSectionWriter(ch chan<- io.ReadCloser, header *types.SnapshotItem) *sectionWriter {
+	return &sectionWriter{
+		ch:     ch,
+		header: header,
+	}
+}
+
+// WriteMsg implements protoio.Writer interface
+func (w *sectionWriter) WriteMsg(msg proto.Message) error {
+	if w.protoWriter == nil {
+		if err := w.openChunk(); err != nil {
+			return err
+		}
+	}
+	if err := w.protoWriter.WriteMsg(msg); err != nil {
+		return err
+	}
+	if w.counter.written >= snapshotChunkSize {
+		return w.flushChunk()
+	}
+	return nil
+}
+
+// Close flushes the last chunk of the section. A section always has at least one chunk,
+// so that empty stores are restored too.
+func (w *sectionWriter) Close() error {
+	if w.protoWriter == nil && w.chunks == 0 {
+		if err := w.openChunk(); err != nil {
+			return err
+		}
+	}
+	if w.protoWriter != nil {
+		return w.flushChunk()
+	}
+	return nil
+}
+
+// openChunk starts a new chunk, beginning with the header of the section.
+func (w *sectionWriter) openChunk() error {
+	w.buf = new(bytes.Buffer)
+	zWriter, err := zlib.NewWriterLevel(w.buf, snapshotCompressionLevel)
+	if err != nil {
+		return errors.Wrap(err, "zlib failure")
+	}
+	w.zWriter = zWriter
+	w.counter = &countWriter{writer: zWriter}
+	w.protoWriter = protoio.NewDelimitedWriter(w.counter)
+	return w.protoWriter.WriteMsg(w.header)
+}
+
+// flushChunk completes the current chunk and sends it to the channel.
+func (w *sectionWriter) flushChunk() error {
+	if err := w.zWriter.Close(); err != nil {
+		return errors.Wrap(err, "zlib failure")
+	}
+	w.ch <- io.NopCloser(bytes.NewReader(w.buf.Bytes()))
+	w.chunks++
+	w.buf, w.zWriter, w.counter, w.protoWriter = nil, nil, nil, nil
+	return nil
+}
+
+// countWriter counts the bytes written to the underlying writer.
+type countWriter struct {
+	writer  io.Writer
+	written uint64
+}
+
+// Write implements io.Writer.
+func (w *countWriter) Write(p []byte) (int, error) {
+	n, err := w.writer.Write(p)
+	w.written += uint64(n)
+	return n, err
+}
+
+// sectionReader reads the items of a section of a FormatChunkedStores snapshot from its
+// chunks, checking and skipping the header item of every chunk. It returns io.EOF once
+// the chunks channel is closed and all the chunks are read.
+type sectionReader struct {
+	chunkIDs  <-chan uint32
+	loadChunk func(chunkID uint32) (io.ReadCloser, error)
+	header    *types.SnapshotItem
+
+	chunk       io.ReadCloser
+	zReader     io.ReadCloser
+	protoReader protoio.ReadCloser
+}
+
+var _ protoio.Reader = (*sectionReader)(nil)
+
+// newSectionReader creates a section reader loading the chunks with the given IDs.
+func newSectionReader(chunkIDs <-chan uint32, loadChunk func(uint32) (io.ReadCloser, error), header *types.SnapshotItem) *sectionReader {
+	return &sectionReader{
+		chunkIDs:  chunkIDs,
+		loadChunk: loadChunk,
+		header:    header,
+	}
+}
+
+// ReadMsg implements protoio.Reader interface
+func (r *sectionReader) ReadMsg(msg proto.Message) error {
+	for {
+		if r.protoReader == nil {
+			chunkID, ok := <-r.chunkIDs
+			if !ok {
+				return io.EOF
+			}
+			if err := r.openChunk(chunkID); err != nil {
+				return err
+			}
+		}
+
+		err := r.protoReader.ReadMsg(msg)
+		if stderrors.Is(err, io.EOF) {
+			if err := r.closeChunk(); err != nil {
+				return err
+			}
+			continue
+		}
+		return err
+	}
+}
+
+// openChunk opens the chunk and reads its header.
+func (r *sectionReader) openChunk(chunkID uint32) error {
+	chunk, err := r.loadChunk(chunkID)
+	if err != nil {
+		return errors.Wrapf(err, "load chunk %d", chunkID)
+	}
+	zReader, err := zlib.NewReader(chunk)
+	if err != nil {
+		_ = chunk.Close()
+		return errors.Wrapf(err, "zlib failure in chunk %d", chunkID)
+	}
+	r.chunk, r.zReader = chunk, zReader
+	r.protoReader = protoio.NewDelimitedReader(zReader, snapshotMaxItemSize)
+
+	var header types.SnapshotItem
+	if err := r.protoReader.ReadMsg(&header); err != nil {
+		return errors.Wrapf(err, "read header of chunk %d", chunkID)
+	}
+	if !proto.Equal(&header, r.header) {
+		return errors.Wrapf(storeerrors.ErrLogic, "chunk %d belongs to %v, expected %v", chunkID, header.Item, r.header.Item)
+	}
+	return nil
+}
+
+// closeChunk closes the current chunk.
+func (r *sectionReader) closeChunk() error {
+	if r.protoReader == nil {
+		return nil
+	}
+	err := r.protoReader.Close()
+	if err2 := r.zReader.Close(); err2 != nil {
+		err = err2
+	}
+	if err3 := r.chunk.Close(); err3 != nil {
+		err = err3
+	}
+	r.chunk, r.zReader, r.protoReader = nil, nil, nil
+	return err
+}
+
+// Close implements io.Closer interface
+func (r *sectionReader) Close() error {
+	return r.closeChunk()
+}
+
+// readSectionHeader reads the header item of a chunk of a FormatChunkedStores snapshot.
+func readSectionHeader(chunk io.Reader) (types.SnapshotItem, error) {
+	zReader, err := zlib.NewReader(chunk)
+	if err != nil {
+		return types.SnapshotItem{}, errors.Wrap(err, "zlib failure")
+	}
+	defer zReader.Close()
+
+	var header types.SnapshotItem
+	if err := protoio.NewDelimitedReader(zReader, snapshotMaxItemSize).ReadMsg(&header); err != nil {
+		return types.SnapshotItem{}, err
+	}
+	return header, nil
+}
@@ -17,12 +17,44 @@ type CommitSnapshotter interface {
 	Restore(version uint64, format uint32, protoReader protoio.Reader, chStorage chan<- *corestore.StateChanges) (types.SnapshotItem, error)
 }
 
+// StoreSnapshotter is a CommitSnapshotter able to snapshot and restore every store
+// independently. It is required to take snapshots with the FormatChunkedStores format,
+// whose stores are restored concurrently.
+type StoreSnapshotter interface {
+	CommitSnapshotter
+
+	// SnapshotStoreKeys returns the keys of the stores to snapshot, in a deterministic order.
+	SnapshotStoreKeys() []string
+
+	// SnapshotStore writes a snapshot of the store state at the given version. It must
+	// not write the store item, which is written by the manager.
+	SnapshotStore(version uint64, storeKey string, protoWriter protoio.Writer) error
+
+	// RestoreStore restores the store state from the snapshot reader, which returns
+	// io.EOF at the end of the store. It must be safe to restore different stores
+	// concurrently.
+	RestoreStore(version uint64, storeKey string, protoReader protoio.Reader, chStorage chan<- *corestore.StateChanges) error
+
+	// LoadVersion loads the given version once all the stores are restored.
+	LoadVersion(version uint64) error
+}
+
 // StorageSnapshotter defines an API for restoring snapshots of the storage state.
 type StorageSnapshotter interface {
 	// Restore restores the storage state from the given channel.
 	Restore(version uint64, chStorage <-chan *corestore.StateChanges) error
 }
 
+// ResumableStorageSnapshotter is a StorageSnapshotter which can resume the interrupted
+// restore of a FormatChunkedStores snapshot.
+type ResumableStorageSnapshotter interface {
+	StorageSnapshotter
+
+	// ResumeRestore restores the storage state from the given channel, like Restore,
+	// but the storage state may already be partially restored at the given version.
+	ResumeRestore(version uint64, chStorage <-chan *corestore.StateChanges) error
+}
+
 // ExtensionPayloadReader read extension payloads,
 // it returns io.EOF when reached either end of stream or the extension boundaries.
 type ExtensionPayloadReader = func() ([]byte, error)
@@ -3,6 +3,7 @@ package snapshots
 import (
 	"crypto/sha256"
 	"encoding/binary"
+	"encoding/hex"
 	"fmt"
 	"hash"
 	"io"
@@ -197,6 +198,16 @@ func (s *Store) LoadChunk(height uint64, format, chunk uint32) (io.ReadCloser, e
 	return file, err
 }
 
+// loadChunkHeader loads the header item of a chunk of a FormatChunkedStores snapshot.
+func (s *Store) loadChunkHeader(height uint64, format, chunk uint32) (types.SnapshotItem, error) {
+	file, err := s.loadChunkFile(height, format, chunk)
+	if err != nil {
+		return types.SnapshotItem{}, err
+	}
+	defer file.Close()
+	return readSectionHeader(file)
+}
+
 // loadChunkFile loads a chunk from disk, and errors if it does not exist.
 func (s *Store) loadChunkFile(height uint64, format, chunk uint32) (io.ReadCloser, error) {
 	path := s.PathChunk(height, format, chunk)
@@ -343,6 +354,32 @@ func (s *Store) saveSnapshot(snapshot *types.Snapshot) error {
 	return nil
 }
 
+// markStoreRestored records that the store was restored from the snapshot with the given
+// hash, so that an interrupted restore of the snapshot can skip it when resumed.
+func (s *Store) markStoreRestored(height uint64, format uint32, hash []byte, storeKey string) error {
+	dir := s.pathRestoredSnapshot(height, format, hash)
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return errors.Wrapf(err, "failed to create restored stores directory %q", dir)
+	}
+	return os.WriteFile(s.pathRestoredStore(height, format, hash, storeKey), nil, 0o600)
+}
+
+// isStoreRestored returns whether the store was restored from the snapshot with the given
+// hash. The stores restored from another snapshot at the same height and format are not.
+func (s *Store) isStoreRestored(height uint64, format uint32, hash []byte, storeKey string) bool {
+	_, err := os.Stat(s.pathRestoredStore(height, format, hash, storeKey))
+	return err == nil
+}
+
+// clearRestoredStores removes the records of the stores restored from the snapshots at the
+// given height and format, once the restore is complete.
+func (s *Store) clearRestoredStores(height uint64, format uint32) error {
+	if err := os.RemoveAll(s.pathRestoredStores(height, format)); err != nil {
+		return errors.Wrapf(err, "failed to clear restored stores of snapshot for height %v format %v", height, format)
+	}
+	return nil
+}
+
 // pathHeight generates the path to a height, containing multiple snapshot formats.
 func (s *Store) pathHeight(height uint64) string {
 	return filepath.Join(s.dir, strconv.FormatUint(height, 10))
@@ -353,6 +390,23 @@ func (s *Store) pathSnapshot(height uint64, format uint32) string {
 	return filepath.Join(s.pathHeight(height), strconv.FormatUint(uint64(format), 10))
 }
 
+// pathRestoredStores generates the path to the records of the stores restored from the
+// snapshots at a height and format.
+func (s *Store) pathRestoredStores(height uint64, format uint32) string {
+	return filepath.Join(s.pathSnapshot(height, format), "restored")
+}
+
+// pathRestoredSnapshot generates the path to the records of the stores restored from the
+// snapshot with the given hash.
+func (s *Store) pathRestoredSnapshot(height uint64, format uint32, hash []byte) string {
+	return filepath.Join(s.pathRestoredStores(height, format), hex.EncodeToString(hash))
+}
+
+// pathRestoredStore generates the path to the record of a store restored from a snapshot.
+func (s *Store) pathRestoredStore(height uint64, format uint32, hash []byte, storeKey string) string {
+	return filepath.Join(s.pathRestoredSnapshot(height, format, hash), hex.EncodeToString([]byte(storeKey)))
+}
+
 func (s *Store) pathMetadataDir() string {
 	return filepath.Join(s.dir, "metadata")
 }
@@ -1,6 +1,21 @@
 package types
 
-// CurrentFormat is the currently used format for snapshots. Snapshots using the same format
-// must be identical across all nodes for a given height, so this must be bumped when the binary
-// snapshot output changes.
-const CurrentFormat uint32 = 3
+const (
+	// CurrentFormat is the format of snapshots whose items are streamed through a single
+	// protobuf stream, which is split into chunks. It is used when the commitment snapshotter
+	// can't snapshot every store independently. Snapshots using the same format must be
+	// identical across all nodes for a given height, so a new format must be added when the
+	// binary snapshot output changes.
+	CurrentFormat uint32 = 3
+
+	// FormatChunkedStores is the format of snapshots where every store, and every extension,
+	// is chunked independently. Each chunk is a self-contained compressed protobuf stream,
+	// starting with the item of the store or extension it belongs to, so that the stores can
+	// be restored concurrently and an interrupted restore can skip the stores already restored.
+	FormatChunkedStores uint32 = 4
+)
+
+// IsFormatSupported returns whether the manager can restore snapshots of the given format.
+func IsFormatSupported(format uint32) bool {
+	return format == CurrentFormat || format == FormatChunkedStores
+}
@@ -664,3 +664,34 @@ func DBApplyChangeset(
 
 	require.NoError(t, db.ApplyChangeset(version, cs))
 }
+
+func (s *StorageTestSuite) TestDatabase_Restore() {
+	db, err := s.NewDB(s.T().TempDir())
+	s.Require().NoError(err)
+	defer db.Close()
+
+	restore := func(restoreFn func(uint64, <-chan *corestore.StateChanges) error, version uint64, value string) error {
+		ch := make(chan *corestore.StateChanges, 1)
+		ch <- &corestore.StateChanges{
+			Actor:        storeKey1Bytes,
+			StateChanges: []corestore.KVPair{{Key: []byte("key"), Value: []byte(value)}},
+		}
+		close(ch)
+		return restoreFn(version, ch)
+	}
+
+	s.Require().NoError(restore(db.Restore, 5, "value"))
+	bz, err := db.Get(storeKey1Bytes, 5, []byte("key"))
+	s.Require().NoError(err)
+	s.Require().Equal([]byte("value"), bz)
+
+	// the snapshot version must be greater than the latest version
+	s.Require().ErrorContains(restore(db.Restore, 5, "value"), "not greater than latest version")
+
+	// unless an interrupted restore is resumed
+	s.Require().NoError(restore(db.ResumeRestore, 5, "resumed"))
+	bz, err = db.Get(storeKey1Bytes, 5, []byte("key"))
+	s.Require().NoError(err)
+	s.Require().Equal([]byte("resumed"), bz)
+	s.Require().ErrorContains(restore(db.ResumeRestore, 4, "value"), "less than latest version")
+}
@@ -15,9 +15,9 @@ const (
 )
 
 var (
-	_ store.VersionedDatabase      = (*StorageStore)(nil)
-	_ snapshots.StorageSnapshotter = (*StorageStore)(nil)
-	_ store.Pruner                 = (*StorageStore)(nil)
+	_ store.VersionedDatabase               = (*StorageStore)(nil)
+	_ snapshots.ResumableStorageSnapshotter = (*StorageStore)(nil)
+	_ store.Pruner                          = (*StorageStore)(nil)
 )
 
 // StorageStore is a wrapper around the store.VersionedDatabase interface.
@@ -107,6 +107,25 @@ func (ss *StorageStore) Restore(version uint64, chStorage <-chan *corestore.Stat
 		return fmt.Errorf("the snapshot version %d is not greater than latest version %d", version, latestVersion)
 	}
 
+	return ss.restore(version, chStorage)
+}
+
+// ResumeRestore restores the store from the given channel, like Restore, but the
+// latest version may already be the snapshot version, if a previous restore of the
+// snapshot was interrupted. Restoring the same pairs again is harmless.
+func (ss *StorageStore) ResumeRestore(version uint64, chStorage <-chan *corestore.StateChanges) error {
+	latestVersion, err := ss.db.GetLatestVersion()
+	if err != nil {
+		return fmt.Errorf("failed to get latest version: %w", err)
+	}
+	if version < latestVersion {
+		return fmt.Errorf("the snapshot version %d is less than latest version %d", version, latestVersion)
+	}
+
+	return ss.restore(version, chStorage)
+}
+
+func (ss *StorageStore) restore(version uint64, chStorage <-chan *corestore.StateChanges) error {
 	b, err := ss.db.NewBatch(version)
 	if err != nil {
 		return err
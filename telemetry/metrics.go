@@ -84,6 +84,9 @@ type Config struct {
 	// DatadogHostname defines the hostname to use when emitting metrics to
 	// Datadog. Only utilized if MetricsSink is set to "dogstatsd".
 	DatadogHostname string `mapstructure:"datadog-hostname"`
+
+	// Tracing defines the configuration of the OpenTelemetry tracing.
+	Tracing TracingConfig `mapstructure:"tracing"`
 }
 
 // Metrics defines a wrapper around application telemetry functionality. It allows
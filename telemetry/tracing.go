@@ -0,0 +1,80 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracerName is the name of the OpenTelemetry tracer of the SDK.
+const TracerName = "github.com/cosmos/cosmos-sdk"
+
+// TracingConfig defines the configuration options of the OpenTelemetry tracing.
+type TracingConfig struct {
+	// Enabled enables the OpenTelemetry tracing, exporting spans to an OTLP collector.
+	Enabled bool `mapstructure:"enabled"`
+
+	// Endpoint is the host:port of the OTLP/HTTP collector.
+	Endpoint string `mapstructure:"endpoint"`
+
+	// Insecure disables TLS when exporting to the collector.
+	Insecure bool `mapstructure:"insecure"`
+
+	// SampleRate is the fraction of the traces sampled, from 0 to 1. A block is
+	// traced entirely or not at all.
+	SampleRate float64 `mapstructure:"sample-rate"`
+}
+
+// StartTracing sets the global OpenTelemetry tracer provider, exporting the spans
+// to the OTLP collector of the configuration. The returned function flushes the
+// spans and stops the exporter. It is a no-op if tracing isn't enabled.
+func StartTracing(ctx context.Context, telemetryCfg Config) (shutdown func(context.Context) error, err error) {
+	cfg := telemetryCfg.Tracing
+	if !cfg.Enabled {
+		return func(context.Context) error { return nil }, nil
+	}
+	if cfg.SampleRate < 0 || cfg.SampleRate > 1 {
+		return nil, fmt.Errorf("tracing sample rate must be between 0 and 1, got %f", cfg.SampleRate)
+	}
+
+	opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(cfg.Endpoint)}
+	if cfg.Insecure {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+	exporter, err := otlptracehttp.New(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP trace exporter: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRate))),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(telemetryCfg.ServiceName))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+// StartSpan starts a span with the tracer of the SDK, as a child of the span of
+// the context, if any.
+func StartSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(TracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// EndSpan ends the span, recording the error, if any.
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,143 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// collectorStub is an in-process OTLP/HTTP collector recording the exported spans.
+type collectorStub struct {
+	*httptest.Server
+
+	mtx         sync.Mutex
+	serviceName string
+	spans       map[string]*tracepb.Span
+}
+
+func newCollectorStub(t *testing.T) *collectorStub {
+	t.Helper()
+	c := &collectorStub{spans: make(map[string]*tracepb.Span)}
+	c.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/traces" {
+			http.NotFound(w, r)
+			return
+		}
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		var req coltracepb.ExportTraceServiceRequest
+		if err := proto.Unmarshal(body, &req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		c.mtx.Lock()
+		for _, rs := range req.ResourceSpans {
+			for _, attr := range rs.Resource.Attributes {
+				if attr.Key == "service.name" {
+					c.serviceName = attr.Value.GetStringValue()
+				}
+			}
+			for _, ss := range rs.ScopeSpans {
+				for _, span := range ss.Spans {
+					c.spans[span.Name] = span
+				}
+			}
+		}
+		c.mtx.Unlock()
+
+		w.Header().Set("Content-Type", "application/x-protobuf")
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(c.Close)
+	return c
+}
+
+func (c *collectorStub) config(sampleRate float64) Config {
+	return Config{
+		ServiceName: "simd",
+		Tracing: TracingConfig{
+			Enabled:    true,
+			Endpoint:   strings.TrimPrefix(c.URL, "http://"),
+			Insecure:   true,
+			SampleRate: sampleRate,
+		},
+	}
+}
+
+func restoreTracerProvider(t *testing.T) {
+	t.Helper()
+	provider := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(provider) })
+}
+
+func TestStartTracing(t *testing.T) {
+	restoreTracerProvider(t)
+	collector := newCollectorStub(t)
+
+	shutdown, err := StartTracing(context.Background(), collector.config(1))
+	require.NoError(t, err)
+
+	ctx, block := StartSpan(context.Background(), "FinalizeBlock")
+	_, tx := StartSpan(ctx, "tx")
+	EndSpan(tx, errors.New("out of gas"))
+	EndSpan(block, nil)
+
+	// shutting down flushes the spans
+	require.NoError(t, shutdown(context.Background()))
+
+	collector.mtx.Lock()
+	defer collector.mtx.Unlock()
+	require.Equal(t, "simd", collector.serviceName)
+	require.Len(t, collector.spans, 2)
+	require.Equal(t, collector.spans["FinalizeBlock"].SpanId, collector.spans["tx"].ParentSpanId)
+	require.Equal(t, tracepb.Status_STATUS_CODE_ERROR, collector.spans["tx"].Status.Code)
+	require.Equal(t, "out of gas", collector.spans["tx"].Status.Message)
+	require.Equal(t, tracepb.Status_STATUS_CODE_UNSET, collector.spans["FinalizeBlock"].Status.Code)
+}
+
+func TestStartTracingSampling(t *testing.T) {
+	restoreTracerProvider(t)
+	collector := newCollectorStub(t)
+
+	shutdown, err := StartTracing(context.Background(), collector.config(0))
+	require.NoError(t, err)
+
+	ctx, block := StartSpan(context.Background(), "FinalizeBlock")
+	require.False(t, block.IsRecording())
+	// the children of a span not sampled aren't sampled either
+	_, tx := StartSpan(ctx, "tx")
+	require.False(t, tx.IsRecording())
+	EndSpan(tx, nil)
+	EndSpan(block, nil)
+
+	require.NoError(t, shutdown(context.Background()))
+	collector.mtx.Lock()
+	defer collector.mtx.Unlock()
+	require.Empty(t, collector.spans)
+}
+
+func TestStartTracingConfig(t *testing.T) {
+	restoreTracerProvider(t)
+
+	shutdown, err := StartTracing(context.Background(), Config{})
+	require.NoError(t, err)
+	require.NoError(t, shutdown(context.Background()))
+
+	_, err = StartTracing(context.Background(), Config{Tracing: TracingConfig{Enabled: true, SampleRate: 2}})
+	require.Error(t, err)
+}
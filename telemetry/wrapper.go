@@ -1,6 +1,8 @@
 package telemetry
 
 import (
+	"regexp"
+	"strings"
 	"time"
 
 	"github.com/hashicorp/go-metrics"
@@ -13,8 +15,13 @@ const (
 	MetricKeyPrepareCheckStater = "prepare_check_stater"
 	MetricKeyPrecommiter        = "precommiter"
 	MetricLabelNameModule       = "module"
+	MetricLabelNameMsgType      = "msg_type"
+	MetricLabelNameStatus       = "status"
 )
 
+// versionSegment matches the version segment of a protobuf package, e.g. v1beta1.
+var versionSegment = regexp.MustCompile(`^v\d+((alpha|beta)\d*)?$`)
+
 // NewLabel creates a new instance of Label with name and value
 func NewLabel(name, value string) metrics.Label {
 	return metrics.Label{Name: name, Value: value}
@@ -108,3 +115,38 @@ func Now() time.Time {
 
 	return time.Now()
 }
+
+// MeasureMsg provides a wrapper functionality for emitting the gas used and the
+// execution latency of a message, labelled by its type URL, its module and whether
+// it succeeded. If any global labels are defined, they will be added as well.
+func MeasureMsg(typeURL string, start time.Time, gasUsed uint64, err error) {
+	if !IsTelemetryEnabled() {
+		return
+	}
+
+	status := "success"
+	if err != nil {
+		status = "failure"
+	}
+	labels := append([]metrics.Label{
+		NewLabel(MetricLabelNameMsgType, typeURL),
+		NewLabel(MetricLabelNameModule, MsgModule(typeURL)),
+		NewLabel(MetricLabelNameStatus, status),
+	}, globalLabels...)
+
+	metrics.AddSampleWithLabels([]string{"msg", "gas_used"}, float32(gasUsed), labels)
+	metrics.MeasureSinceWithLabels([]string{"msg", "latency"}, start.UTC(), labels)
+}
+
+// MsgModule returns the module of a message from its type URL, being the last
+// segment of its protobuf package which isn't a version, e.g. bank for
+// /cosmos.bank.v1beta1.MsgSend.
+func MsgModule(typeURL string) string {
+	segments := strings.Split(strings.TrimPrefix(typeURL, "/"), ".")
+	for i := len(segments) - 2; i >= 0; i-- {
+		if !versionSegment.MatchString(segments[i]) {
+			return segments[i]
+		}
+	}
+	return ""
+}
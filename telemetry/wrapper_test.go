@@ -1,10 +1,12 @@
 package telemetry
 
 import (
+	"errors"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/hashicorp/go-metrics"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -49,3 +51,32 @@ func TestIsTelemetryEnabled(t *testing.T) {
 	initTelemetry(false)
 	assert.False(t, IsTelemetryEnabled(), "IsTelemetryEnabled() should return false when globalTelemetryEnabled is set to false")
 }
+
+// TestMsgModule tests the module derived from the type URL of a message.
+func TestMsgModule(t *testing.T) {
+	assert.Equal(t, "bank", MsgModule("/cosmos.bank.v1beta1.MsgSend"))
+	assert.Equal(t, "lockup", MsgModule("/cosmos.accounts.defaults.lockup.v1.MsgDelegate"))
+	assert.Equal(t, "testpb", MsgModule("/testpb.MsgCounter"))
+	assert.Equal(t, "", MsgModule("/MsgCounter"))
+}
+
+// TestMeasureMsg tests the labels of the message metrics.
+func TestMeasureMsg(t *testing.T) {
+	setupTest(t)
+	sink := metrics.NewInmemSink(time.Minute, time.Minute)
+	_, err := metrics.NewGlobal(metrics.DefaultConfig("test"), sink)
+	assert.NoError(t, err)
+
+	initTelemetry(true)
+	defer initTelemetry(false)
+
+	MeasureMsg("/cosmos.bank.v1beta1.MsgSend", time.Now(), 100, nil)
+	MeasureMsg("/cosmos.bank.v1beta1.MsgSend", time.Now(), 50, errors.New("insufficient funds"))
+
+	samples := sink.Data()[0].Samples
+	success := samples["test.msg.gas_used;msg_type=/cosmos.bank.v1beta1.MsgSend;module=bank;status=success"]
+	assert.Equal(t, 100.0, success.Sum)
+	failure := samples["test.msg.gas_used;msg_type=/cosmos.bank.v1beta1.MsgSend;module=bank;status=failure"]
+	assert.Equal(t, 50.0, failure.Sum)
+	assert.Contains(t, samples, "test.msg.latency;msg_type=/cosmos.bank.v1beta1.MsgSend;module=bank;status=success")
+}
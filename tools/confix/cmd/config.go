@@ -22,6 +22,7 @@ func ConfigCommand() *cobra.Command {
 		GetCommand(),
 		SetCommand(),
 		ViewCommand(),
+		ValidateCommand(),
 		HomeCommand(),
 	)
 
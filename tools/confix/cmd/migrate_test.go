@@ -35,5 +35,5 @@ func TestMigrateCmd(t *testing.T) {
 	// this should work
 	out, err = clitestutil.ExecTestCLICmd(clientCtx, cmd.MigrateCommand(), []string{"v0.51", filepath.Join(clientCtx.HomeDir, "config", "client.toml"), "--client", "--verbose"})
 	assert.NilError(t, err)
-	assert.Assert(t, strings.Contains(out.String(), "add keyring-default-keyname key"))
+	assert.Assert(t, strings.Contains(out.String(), "add gas-adjustment key"))
 }
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"cosmossdk.io/tools/confix"
+
+	"github.com/cosmos/cosmos-sdk/client"
+)
+
+func ValidateCommand() *cobra.Command {
+	var (
+		flagOutputFormat = "output-format"
+		flagStrict       = "strict"
+		flagEnv          = "env"
+	)
+
+	cmd := &cobra.Command{
+		Use:   "validate [config]",
+		Short: "Validate the config file",
+		Long: `Validate the config file (app.toml or client.toml) against its schema: the type, range and accepted values of each key,
+and semantic rules spanning several keys (pruning, state sync, listen port conflicts, etc.). Unknown keys are reported as warnings,
+and the keys of previous versions, ignored by the node, as info.
+The [config] argument must be the path of the file when using the ` + "`confix`" + ` tool standalone, otherwise it must be the name of the config file without the .toml extension.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			filename := args[0]
+
+			clientCtx := client.GetClientContextFromCmd(cmd)
+			if clientCtx.HomeDir != "" {
+				filename = filepath.Join(clientCtx.HomeDir, "config", filename+tomlSuffix)
+			}
+
+			envs, _ := cmd.Flags().GetStringSlice(flagEnv)
+			file, err := readEffectiveConfig(filename, envs)
+			if err != nil {
+				return err
+			}
+
+			report, err := confix.Validate(filename, file)
+			if err != nil {
+				return err
+			}
+
+			switch format, _ := cmd.Flags().GetString(flagOutputFormat); format {
+			case "json":
+				e := json.NewEncoder(cmd.OutOrStdout())
+				e.SetIndent("", "  ")
+				if err := e.Encode(report); err != nil {
+					return err
+				}
+			case "text":
+				for _, issue := range report.Issues {
+					cmd.Println(issue.String())
+				}
+				cmd.Printf("%s: %d error(s), %d warning(s)\n", report.File, report.Errors(), report.Warnings())
+			default:
+				return fmt.Errorf("unknown output format %q", format)
+			}
+
+			if strict, _ := cmd.Flags().GetBool(flagStrict); report.Errors() > 0 || strict && report.Warnings() > 0 {
+				return fmt.Errorf("%s is invalid", report.File)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().String(flagOutputFormat, "text", "Output format (text|json)")
+	cmd.Flags().Bool(flagStrict, false, "Fail on warnings")
+	cmd.Flags().StringSlice(flagEnv, nil, "Environment overlays to merge over the config file before validating it, in order (e.g. prod)")
+
+	return cmd
+}
@@ -0,0 +1,54 @@
+package cmd_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"cosmossdk.io/tools/confix"
+	"cosmossdk.io/tools/confix/cmd"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	clitestutil "github.com/cosmos/cosmos-sdk/testutil/cli"
+)
+
+func TestValidateCmd(t *testing.T) {
+	clientCtx, cleanup := initClientContext(t)
+	defer cleanup()
+
+	_, err := clitestutil.ExecTestCLICmd(clientCtx, cmd.ValidateCommand(), []string{"unexisting"})
+	assert.ErrorContains(t, err, "no such file or directory")
+
+	_, err = clitestutil.ExecTestCLICmd(clientCtx, cmd.ValidateCommand(), []string{"unsupported"})
+	assert.ErrorContains(t, err, "unknown config")
+
+	out, err := clitestutil.ExecTestCLICmd(clientCtx, cmd.ValidateCommand(), []string{"client"})
+	assert.NilError(t, err)
+	assert.Assert(t, strings.Contains(out.String(), "0 error(s), 0 warning(s)"))
+
+	appConfig := filepath.Join(clientCtx.HomeDir, "config", "app.toml")
+	assert.NilError(t, os.WriteFile(appConfig, []byte("minimum-gas-prices = \"0stake\"\nfoo = \"bar\"\n"), 0o600))
+
+	out, err = clitestutil.ExecTestCLICmd(client.Context{}, cmd.ValidateCommand(), []string{appConfig})
+	assert.NilError(t, err)
+	assert.Assert(t, strings.Contains(out.String(), "warning: foo: [unknown-key]"))
+
+	_, err = clitestutil.ExecTestCLICmd(client.Context{}, cmd.ValidateCommand(), []string{appConfig, "--strict"})
+	assert.Error(t, err, fmt.Sprintf("%s is invalid", appConfig))
+
+	assert.NilError(t, os.WriteFile(appConfig, []byte("pruning = \"sometimes\"\n"), 0o600))
+	out, err = clitestutil.ExecTestCLICmd(clientCtx, cmd.ValidateCommand(), []string{"app", "--output-format", "json"})
+	assert.ErrorContains(t, err, "is invalid")
+
+	var report confix.ValidationReport
+	assert.NilError(t, json.NewDecoder(bytes.NewReader(out.Bytes())).Decode(&report))
+	assert.Equal(t, report.Errors(), 2)
+	assert.Equal(t, report.Issues[0].Key, "minimum-gas-prices")
+	assert.Equal(t, report.Issues[1].Rule, "enum")
+}
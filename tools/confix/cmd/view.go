@@ -1,25 +1,36 @@
 package cmd
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
+	"github.com/creachadair/tomledit"
 	"github.com/pelletier/go-toml/v2"
 	"github.com/spf13/cobra"
 
+	"cosmossdk.io/tools/confix"
+
 	"github.com/cosmos/cosmos-sdk/client"
 )
 
 func ViewCommand() *cobra.Command {
-	flagOutputFormat := "output-format"
+	var (
+		flagOutputFormat = "output-format"
+		flagEnv          = "env"
+		flagProvenance   = "provenance"
+	)
 
 	cmd := &cobra.Command{
 		Use:   "view [config]",
 		Short: "View the config file",
-		Long:  "View the config file. The [config] argument must be the path of the file when using the `confix` tool standalone, otherwise it must be the name of the config file without the .toml extension.",
-		Args:  cobra.ExactArgs(1),
+		Long: `View the config file. The [config] argument must be the path of the file when using the ` + "`confix`" + ` tool standalone, otherwise it must be the name of the config file without the .toml extension.
+Environment overlays (e.g. app.prod.toml for app.toml and the prod environment) are merged over the config file, in order, with --env.
+The file each value of the effective config comes from is shown with --provenance.`,
+		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			filename := args[0]
 
@@ -28,7 +39,17 @@ func ViewCommand() *cobra.Command {
 				filename = filepath.Join(clientCtx.HomeDir, "config", filename+tomlSuffix)
 			}
 
-			file, err := os.ReadFile(filename)
+			envs, _ := cmd.Flags().GetStringSlice(flagEnv)
+			if provenance, _ := cmd.Flags().GetBool(flagProvenance); provenance {
+				cfg, err := confix.LoadEffectiveConfig(filename, envs...)
+				if err != nil {
+					return err
+				}
+
+				return printProvenance(cmd, cfg)
+			}
+
+			file, err := readEffectiveConfig(filename, envs)
 			if err != nil {
 				return err
 			}
@@ -51,6 +72,45 @@ func ViewCommand() *cobra.Command {
 
 	// output flag
 	cmd.Flags().String(flagOutputFormat, "toml", "Output format (json|toml)")
+	cmd.Flags().StringSlice(flagEnv, nil, "Environment overlays to merge over the config file, in order (e.g. prod)")
+	cmd.Flags().Bool(flagProvenance, false, "Show the file each value comes from")
 
 	return cmd
 }
+
+// readEffectiveConfig reads the config file, merged with the overlays of the given environments.
+func readEffectiveConfig(filename string, envs []string) ([]byte, error) {
+	if len(envs) == 0 {
+		return os.ReadFile(filename)
+	}
+
+	cfg, err := confix.LoadEffectiveConfig(filename, envs...)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := tomledit.Format(&buf, cfg.Doc); err != nil {
+		return nil, fmt.Errorf("formatting config: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+func printProvenance(cmd *cobra.Command, cfg *confix.EffectiveConfig) error {
+	if format, _ := cmd.Flags().GetString("output-format"); format == "json" {
+		e := json.NewEncoder(cmd.OutOrStdout())
+		e.SetIndent("", "  ")
+		return e.Encode(cfg.Values)
+	}
+
+	for _, v := range cfg.Values {
+		line := fmt.Sprintf("%s = %s # %s", v.Key, v.Value, v.Source)
+		if len(v.Overrides) > 0 {
+			line += fmt.Sprintf(" (overrides %s)", strings.Join(v.Overrides, ", "))
+		}
+		cmd.Println(line)
+	}
+
+	return nil
+}
@@ -3,6 +3,7 @@ package cmd_test
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -37,3 +38,24 @@ func TestViewCmd(t *testing.T) {
 	assert.NilError(t, err)
 	assert.Assert(t, strings.Contains(out.String(), `"chain-id": "test-chain"`))
 }
+
+func TestViewCmdEnv(t *testing.T) {
+	clientCtx, cleanup := initClientContext(t)
+	defer cleanup()
+
+	clientConfig := filepath.Join(clientCtx.HomeDir, "config", "client.toml")
+	prodConfig := filepath.Join(clientCtx.HomeDir, "config", "client.prod.toml")
+	assert.NilError(t, os.WriteFile(prodConfig, []byte("chain-id = \"prod-chain\"\n"), 0o600))
+
+	_, err := clitestutil.ExecTestCLICmd(clientCtx, cmd.ViewCommand(), []string{"client", "--env", "dev"})
+	assert.ErrorContains(t, err, "failed to load dev overlay")
+
+	out, err := clitestutil.ExecTestCLICmd(clientCtx, cmd.ViewCommand(), []string{"client", "--env", "prod", "--output-format", "json"})
+	assert.NilError(t, err)
+	assert.Assert(t, strings.Contains(out.String(), `"chain-id": "prod-chain"`))
+
+	out, err = clitestutil.ExecTestCLICmd(clientCtx, cmd.ViewCommand(), []string{"client", "--env", "prod", "--provenance"})
+	assert.NilError(t, err)
+	assert.Assert(t, strings.Contains(out.String(), fmt.Sprintf("chain-id = \"prod-chain\" # %s (overrides %s)", prodConfig, clientConfig)))
+	assert.Assert(t, strings.Contains(out.String(), fmt.Sprintf("keyring-backend = \"os\" # %s", clientConfig)))
+}
@@ -0,0 +1,236 @@
+package confix
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/creachadair/tomledit"
+	"github.com/creachadair/tomledit/parser"
+	"github.com/creachadair/tomledit/transform"
+
+	sdktelemetry "github.com/cosmos/cosmos-sdk/telemetry"
+)
+
+// ComponentsVersion is the version of the app.toml split in server/v2 component sections.
+const ComponentsVersion = "v2"
+
+// Component is a server/v2 component, whose config is a section of app.toml named after the component.
+type Component struct {
+	Name string
+	// Legacy maps the keys of the monolithic app.toml (v0.45-v0.51) to their key in the component section.
+	Legacy map[string]string
+	// Shared is true when the monolithic app.toml has a section with the same name.
+	Shared bool
+}
+
+// Components are the server/v2 components known by confix.
+var Components = []Component{
+	{
+		Name: "cometbft",
+		Legacy: map[string]string{
+			"min-retain-blocks": "cometbft.min_retain_blocks",
+			"index-events":      "cometbft.index_events",
+			"halt-height":       "cometbft.halt_height",
+			"halt-time":         "cometbft.halt_time",
+
+			"state-sync.snapshot-interval":    "cometbft.snapshot_interval",
+			"state-sync.snapshot-keep-recent": "cometbft.snapshot_keep_recent",
+		},
+	},
+	{
+		Name: "grpc-server",
+		Legacy: map[string]string{
+			"grpc.enable":            "grpc-server.enable",
+			"grpc.address":           "grpc-server.address",
+			"grpc.max-recv-msg-size": "grpc-server.max-recv-msg-size",
+			"grpc.max-send-msg-size": "grpc-server.max-send-msg-size",
+		},
+	},
+	{
+		Name: "grpc-gateway",
+		Legacy: map[string]string{
+			// the gRPC gateway was served by the API server
+			"api.enable": "grpc-gateway.enable",
+		},
+	},
+	{
+		Name: "log-admin",
+	},
+	{
+		Name: "store",
+		Legacy: map[string]string{
+			"iavl-cache-size": "store.iavl-config.cache_size",
+		},
+		Shared: true,
+	},
+	{
+		Name: "telemetry",
+		Legacy: map[string]string{
+			// server/v2 reads the metrics sink from the type key
+			"telemetry.metrics-sink": "telemetry.type",
+		},
+		Shared: true,
+	},
+}
+
+// DiscoverComponents returns the components configured in the given configuration values.
+func DiscoverComponents(values Values) []Component {
+	var found []Component
+	for _, c := range Components {
+		for key := range values {
+			if strings.HasPrefix(key, c.Name+".") {
+				found = append(found, c)
+				break
+			}
+		}
+	}
+
+	return found
+}
+
+// IsComponentConfig returns true if the configuration values are split in server/v2 component sections.
+// Components sharing their section name with the monolithic app.toml are not enough to tell.
+func IsComponentConfig(values Values) bool {
+	for _, c := range DiscoverComponents(values) {
+		if !c.Shared {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ComponentSchema returns the schema of an app.toml split in server/v2 component sections.
+// The fields are derived from the configs of the server/v2 API components, through the schema
+// data generated from them, then from the keys of the v2 app.toml shipped with confix for the
+// cometbft and store sections.
+func ComponentSchema() Schema {
+	fileName, err := getFileName(ComponentsVersion, AppConfigType)
+	if err != nil {
+		panic(err)
+	}
+	bz, err := data.ReadFile(filepath.Join("data", fileName))
+	if err != nil {
+		panic(fmt.Errorf("failed to read file: %w. This file should have been included in confix", err))
+	}
+	values, err := ParseValues(bz)
+	if err != nil {
+		panic(fmt.Errorf("failed to parse file: %w. This file should have been valid", err))
+	}
+
+	s := Schema{Fields: mustLoadSchemaData(ComponentsVersion, AppConfigType)}
+	s.addFields(SchemaFromValues(values))
+
+	// the template doesn't tell the unsigned integers apart
+	for _, key := range []string{
+		"cometbft.min_retain_blocks", "cometbft.halt_height", "cometbft.halt_time",
+		"cometbft.snapshot_interval", "cometbft.snapshot_keep_recent",
+		"store.ss-pruning-option.keep-recent", "store.ss-pruning-option.interval",
+		"store.sc-pruning-option.keep-recent", "store.sc-pruning-option.interval",
+	} {
+		s.set(key, func(f *FieldSchema) { f.Min = int64Ptr(0) })
+	}
+	s.set("cometbft.transport", func(f *FieldSchema) { f.Enum = []string{"socket", "grpc"} })
+	s.set("store.ss-type", func(f *FieldSchema) { f.Enum = []string{"sqlite", "pebble", "rocksdb"} })
+	s.set("store.sc-type", func(f *FieldSchema) { f.Enum = []string{"iavl", "iavl-v2"} })
+	s.set("telemetry.type", func(f *FieldSchema) {
+		f.Enum = []string{"", sdktelemetry.MetricSinkInMem, sdktelemetry.MetricSinkStatsd, sdktelemetry.MetricSinkDogsStatsd}
+	})
+	s.set("telemetry.prometheus-retention-time", func(f *FieldSchema) { f.Min = int64Ptr(0) })
+	s.set("grpc-server.max-recv-msg-size", func(f *FieldSchema) { f.Min = int64Ptr(0) })
+	s.set("grpc-server.max-send-msg-size", func(f *FieldSchema) { f.Min = int64Ptr(0) })
+	s.set("grpc-server.address", func(f *FieldSchema) { f.Listen, f.ListenEnabledBy = true, "grpc-server.enable" })
+	s.set("cometbft.addr", func(f *FieldSchema) { f.Listen, f.ListenEnabledBy = true, "cometbft.standalone" })
+	s.set("log-admin.address", func(f *FieldSchema) { f.Listen, f.ListenEnabledBy = true, "log-admin.enable" })
+
+	s.Rules = []Rule{
+		{Name: "port-conflict", Check: s.checkPortConflicts},
+	}
+
+	return s
+}
+
+// ComponentPlanBuilder returns the plan migrating a monolithic app.toml to server/v2 component sections.
+// The values of the legacy keys are moved to their component key, then the keys are aligned on the
+// target version defaults, as done by PlanBuilder.
+// client.toml is left untouched by server/v2, so it is migrated by PlanBuilder.
+func ComponentPlanBuilder(from *tomledit.Document, to, planType string) transform.Plan {
+	if planType != AppConfigType {
+		return PlanBuilder(from, to, planType)
+	}
+
+	target, err := LoadLocalConfig(to, planType)
+	if err != nil {
+		panic(fmt.Errorf("failed to parse file: %w. This file should have been valid", err))
+	}
+
+	var legacyKeys []string
+	moves := map[string]string{}
+	for _, c := range Components {
+		for legacyKey, key := range c.Legacy {
+			legacyKeys = append(legacyKeys, legacyKey)
+			moves[legacyKey] = key
+		}
+	}
+	sort.Strings(legacyKeys)
+
+	plan := transform.Plan{}
+	for _, legacyKey := range legacyKeys {
+		legacyKey, key := legacyKey, moves[legacyKey]
+		plan = append(plan, transform.Step{
+			Desc: fmt.Sprintf("move %s to %s", legacyKey, key),
+			T: transform.Func(func(_ context.Context, doc *tomledit.Document) error {
+				return moveKey(doc, target, strings.Split(legacyKey, "."), strings.Split(key, "."))
+			}),
+		})
+	}
+
+	return append(plan, transform.Step{
+		Desc: fmt.Sprintf("align keys with %s", to),
+		T: transform.Func(func(ctx context.Context, doc *tomledit.Document) error {
+			return PlanBuilder(doc, to, planType).Apply(ctx, doc)
+		}),
+	})
+}
+
+// moveKey moves the value of a mapping to a new key, creating its table if needed.
+// The comments of the new key are taken from the target document, and numbers stored as
+// strings in the legacy config are converted when the target key is an integer.
+// Missing mappings are skipped.
+func moveKey(doc, target *tomledit.Document, from, to parser.Key) error {
+	src := doc.First(from...)
+	if src == nil || !src.IsMapping() {
+		return nil
+	}
+
+	value := src.Value
+	src.Remove()
+
+	// copy the key parts, tomledit appends to table names when scanning
+	table := append(parser.Key{}, to[:len(to)-1]...)
+	kv := &parser.KeyValue{Name: parser.Key{to[len(to)-1]}, Value: value}
+	if dst := target.First(to...); dst != nil && dst.IsMapping() {
+		kv.Block = dst.Block
+		if _, err := strconv.ParseInt(dst.Value.String(), 10, 64); err == nil {
+			if s, err := strconv.Unquote(value.String()); err == nil {
+				if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+					kv.Value = parser.MustValue(strconv.FormatInt(i, 10))
+				}
+			}
+		}
+	}
+
+	tab := transform.FindTable(doc, table...)
+	if tab == nil {
+		section := newSection(table)
+		doc.Sections = append(doc.Sections, section)
+		tab = &tomledit.Entry{Section: section}
+	}
+
+	transform.InsertMapping(tab.Section, kv, true)
+	return nil
+}
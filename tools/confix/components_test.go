@@ -0,0 +1,108 @@
+package confix_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"cosmossdk.io/tools/confix"
+)
+
+func componentNames(components []confix.Component) []string {
+	names := []string{}
+	for _, c := range components {
+		names = append(names, c.Name)
+	}
+
+	return names
+}
+
+func TestDiscoverComponents(t *testing.T) {
+	values, err := confix.ParseValues(mustReadConfig(t, "data/v0.50-app.toml"))
+	assert.NilError(t, err)
+	assert.DeepEqual(t, componentNames(confix.DiscoverComponents(values)), []string{"telemetry"})
+	assert.Assert(t, !confix.IsComponentConfig(values))
+
+	values, err = confix.ParseValues(mustReadConfig(t, "data/v2-app.toml"))
+	assert.NilError(t, err)
+	assert.DeepEqual(t, componentNames(confix.DiscoverComponents(values)), []string{"cometbft", "grpc-server", "grpc-gateway", "log-admin", "store", "telemetry"})
+	assert.Assert(t, confix.IsComponentConfig(values))
+
+	values, err = confix.ParseValues([]byte("[grpc-server]\nenable = true\n[mock-server]\nfoo = 1\n"))
+	assert.NilError(t, err)
+	assert.DeepEqual(t, componentNames(confix.DiscoverComponents(values)), []string{"grpc-server"})
+}
+
+func TestValidateComponents(t *testing.T) {
+	err := confix.CheckValid("app.toml", mustReadConfig(t, "data/v2-app.toml"))
+	assert.NilError(t, err)
+
+	report, err := confix.Validate("app.toml", mustReadConfig(t, "data/v2-app.toml"))
+	assert.NilError(t, err)
+	assert.Equal(t, len(report.Issues), 0, "%v", report.Issues)
+
+	config := []byte(`[cometbft]
+standalone = true
+addr = "tcp://0.0.0.0:9090"
+transport = "http"
+[grpc-server]
+enable = true
+address = "localhost:9090"
+[mock-server]
+foo = 1
+`)
+	report, err = confix.Validate("app.toml", config)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, issueKeys(report, confix.SeverityError), []string{"enum:cometbft.transport", "port-conflict:grpc-server.address"})
+	assert.DeepEqual(t, issueKeys(report, confix.SeverityWarning), []string{"unknown-key:mock-server.foo"})
+
+	err = confix.CheckValid("app.toml", config)
+	assert.ErrorContains(t, err, "server config invalid")
+}
+
+func TestMigrateToComponents(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "app.toml")
+	config := string(mustReadConfig(t, "data/v0.50-app.toml"))
+	config = strings.Replace(config, "halt-height = 0", "halt-height = 100", 1)
+	config = strings.Replace(config, `address = "localhost:9090"`, `address = "localhost:9999"`, 1)
+	assert.NilError(t, os.WriteFile(configPath, []byte(config), 0o600))
+
+	from, err := confix.LoadConfig(configPath)
+	assert.NilError(t, err)
+
+	plan := confix.Migrations[confix.ComponentsVersion](from, confix.ComponentsVersion, confix.AppConfigType)
+	assert.NilError(t, confix.Upgrade(context.Background(), plan, configPath, configPath, false))
+
+	values, err := confix.ParseValues(mustReadConfig(t, configPath))
+	assert.NilError(t, err)
+	assert.Assert(t, confix.IsComponentConfig(values))
+
+	// legacy values are moved to their component
+	assert.Equal(t, values["cometbft.halt_height"], int64(100))
+	assert.Equal(t, values["grpc-server.address"], "localhost:9999")
+	assert.Equal(t, values["grpc-server.max-recv-msg-size"], int64(10485760))
+	assert.Equal(t, values["grpc-gateway.enable"], false)
+	assert.Equal(t, values["store.iavl-config.cache_size"], int64(781250))
+	// shared sections are kept
+	assert.Equal(t, values["telemetry.type"], "mem")
+	// other keys are aligned on the defaults
+	assert.Equal(t, values["store.ss-type"], "sqlite")
+	for _, key := range []string{"minimum-gas-prices", "halt-height", "grpc.address", "api.enable", "mempool.max-txs"} {
+		_, ok := values[key]
+		assert.Assert(t, !ok, key)
+	}
+
+	// migrating again is a no-op
+	from, err = confix.LoadConfig(configPath)
+	assert.NilError(t, err)
+	plan = confix.Migrations[confix.ComponentsVersion](from, confix.ComponentsVersion, confix.AppConfigType)
+	assert.NilError(t, confix.Upgrade(context.Background(), plan, configPath, configPath, false))
+
+	migrated, err := confix.ParseValues(mustReadConfig(t, configPath))
+	assert.NilError(t, err)
+	assert.DeepEqual(t, migrated, values)
+}
@@ -27,6 +27,8 @@ var Migrations = MigrationMap{
 	"v0.47": PlanBuilder,
 	"v0.50": PlanBuilder,
 	"v0.51": PlanBuilder,
+	// server/v2 splits app.toml in component sections
+	ComponentsVersion: ComponentPlanBuilder,
 	// "v0.xx.x": PlanBuilder, // add specific migration in case of configuration changes in minor versions
 }
 
@@ -54,17 +56,7 @@ func PlanBuilder(from *tomledit.Document, to, planType string) transform.Plan {
 				step = transform.Step{
 					Desc: fmt.Sprintf("add %s section", kv.Key),
 					T: transform.Func(func(_ context.Context, doc *tomledit.Document) error {
-						title := fmt.Sprintf("###                    %s Configuration                    ###", strings.Title(kv.Key))
-						doc.Sections = append(doc.Sections, &tomledit.Section{
-							Heading: &parser.Heading{
-								Block: parser.Comments{
-									strings.Repeat("#", len(title)),
-									title,
-									strings.Repeat("#", len(title)),
-								},
-								Name: keys,
-							},
-						})
+						doc.Sections = append(doc.Sections, newSection(keys))
 						return nil
 					}),
 				}
@@ -117,6 +109,21 @@ func PlanBuilder(from *tomledit.Document, to, planType string) transform.Plan {
 	return plan
 }
 
+// newSection returns a new section with the given name, headed by a title block.
+func newSection(keys parser.Key) *tomledit.Section {
+	title := fmt.Sprintf("###                    %s Configuration                    ###", strings.Title(keys.String()))
+	return &tomledit.Section{
+		Heading: &parser.Heading{
+			Block: parser.Comments{
+				strings.Repeat("#", len(title)),
+				title,
+				strings.Repeat("#", len(title)),
+			},
+			Name: keys,
+		},
+	}
+}
+
 // NoPlan returns a no-op plan.
 func NoPlan(_ *tomledit.Document, to, planType string) transform.Plan {
 	fmt.Printf("no migration needed to %s\n", to)
@@ -0,0 +1,123 @@
+package confix
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/creachadair/tomledit"
+	"github.com/creachadair/tomledit/parser"
+	"github.com/creachadair/tomledit/transform"
+)
+
+// OverlayPath returns the path of the overlay of a configuration file for an environment,
+// e.g. app.prod.toml for app.toml and the prod environment.
+func OverlayPath(configPath, env string) string {
+	ext := filepath.Ext(configPath)
+	return strings.TrimSuffix(configPath, ext) + "." + env + ext
+}
+
+// EffectiveValue is a value of the effective configuration, with its provenance.
+type EffectiveValue struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+	// Source is the path of the file the value comes from.
+	Source string `json:"source"`
+	// Overrides lists the files whose value was overridden, from the base configuration file.
+	Overrides []string `json:"overrides,omitempty"`
+}
+
+// EffectiveConfig is a configuration file merged with its environment overlays.
+type EffectiveConfig struct {
+	// Doc is the merged document, based on the configuration file.
+	Doc *tomledit.Document
+	// Values are the merged values, in the order of the document.
+	Values []*EffectiveValue
+}
+
+// LoadEffectiveConfig loads the configuration file at configPath and merges the overlays of
+// the given environments over it, in order. Overlays only need to define the overridden keys.
+func LoadEffectiveConfig(configPath string, envs ...string) (*EffectiveConfig, error) {
+	doc, err := LoadConfig(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &EffectiveConfig{Doc: doc}
+	doc.Scan(func(key parser.Key, e *tomledit.Entry) bool {
+		if e.IsMapping() {
+			cfg.Values = append(cfg.Values, &EffectiveValue{Key: key.String(), Value: e.Value.String(), Source: configPath})
+		}
+		return true
+	})
+
+	for _, env := range envs {
+		overlayPath := OverlayPath(configPath, env)
+		overlay, err := LoadConfig(overlayPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load %s overlay: %w", env, err)
+		}
+
+		cfg.merge(overlay, overlayPath)
+	}
+
+	return cfg, nil
+}
+
+// merge merges the mappings of the overlay document over the effective configuration.
+func (c *EffectiveConfig) merge(overlay *tomledit.Document, source string) {
+	values := make(map[string]*EffectiveValue, len(c.Values))
+	for _, v := range c.Values {
+		values[v.Key] = v
+	}
+
+	for _, section := range append([]*tomledit.Section{overlay.Global}, overlay.Sections...) {
+		if section == nil {
+			continue
+		}
+
+		// copy the table name, tomledit appends to table names when scanning
+		table := append(parser.Key{}, section.TableName()...)
+		for _, item := range section.Items {
+			kv, ok := item.(*parser.KeyValue)
+			if !ok {
+				continue
+			}
+
+			key := append(table[:len(table):len(table)], kv.Name...).String()
+			if v, ok := values[key]; ok {
+				v.Overrides = append(v.Overrides, v.Source)
+				v.Value, v.Source = kv.Value.String(), source
+			} else {
+				v := &EffectiveValue{Key: key, Value: kv.Value.String(), Source: source}
+				c.Values = append(c.Values, v)
+				values[key] = v
+			}
+
+			tab := transform.FindTable(c.Doc, table...)
+			switch {
+			case tab != nil:
+			case len(table) == 0:
+				c.Doc.Global = &tomledit.Section{}
+				tab = &tomledit.Entry{Section: c.Doc.Global}
+			default:
+				c.Doc.Sections = append(c.Doc.Sections, &tomledit.Section{Heading: &parser.Heading{Name: table}})
+				tab = &tomledit.Entry{Section: c.Doc.Sections[len(c.Doc.Sections)-1]}
+			}
+
+			setMapping(tab.Section, kv)
+		}
+	}
+}
+
+// setMapping sets the value of a mapping of the section, keeping the comments of an existing mapping.
+func setMapping(section *tomledit.Section, kv *parser.KeyValue) {
+	for _, item := range section.Items {
+		if cur, ok := item.(*parser.KeyValue); ok && cur.Name.Equals(kv.Name) {
+			cur.Value = kv.Value
+			return
+		}
+	}
+
+	transform.InsertMapping(section, &parser.KeyValue{Block: kv.Block, Name: kv.Name, Value: kv.Value}, false)
+}
@@ -0,0 +1,65 @@
+package confix_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"cosmossdk.io/tools/confix"
+)
+
+func TestOverlayPath(t *testing.T) {
+	assert.Equal(t, confix.OverlayPath("/home/.simapp/config/app.toml", "prod"), "/home/.simapp/config/app.prod.toml")
+	assert.Equal(t, confix.OverlayPath("client.toml", "dev"), "client.dev.toml")
+}
+
+func TestLoadEffectiveConfig(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "app.toml")
+	assert.NilError(t, os.WriteFile(configPath, []byte(`# base
+minimum-gas-prices = "0stake"
+
+[grpc-server]
+# Enable defines if the gRPC server should be enabled.
+enable = true
+address = "localhost:9090"
+`), 0o600))
+	assert.NilError(t, os.WriteFile(confix.OverlayPath(configPath, "prod"), []byte(`minimum-gas-prices = "0.025stake"
+
+[grpc-server]
+address = "0.0.0.0:9090"
+
+[telemetry]
+enabled = true
+`), 0o600))
+	assert.NilError(t, os.WriteFile(confix.OverlayPath(configPath, "eu"), []byte(`[grpc-server]
+address = "0.0.0.0:9091"
+`), 0o600))
+
+	_, err := confix.LoadEffectiveConfig(configPath, "unexisting")
+	assert.ErrorContains(t, err, "failed to load unexisting overlay")
+
+	cfg, err := confix.LoadEffectiveConfig(configPath)
+	assert.NilError(t, err)
+	assert.Equal(t, len(cfg.Values), 3)
+
+	cfg, err = confix.LoadEffectiveConfig(configPath, "prod", "eu")
+	assert.NilError(t, err)
+
+	prodPath, euPath := confix.OverlayPath(configPath, "prod"), confix.OverlayPath(configPath, "eu")
+	assert.DeepEqual(t, cfg.Values, []*confix.EffectiveValue{
+		{Key: "minimum-gas-prices", Value: `"0.025stake"`, Source: prodPath, Overrides: []string{configPath}},
+		{Key: "grpc-server.enable", Value: "true", Source: configPath},
+		{Key: "grpc-server.address", Value: `"0.0.0.0:9091"`, Source: euPath, Overrides: []string{configPath, prodPath}},
+		{Key: "telemetry.enabled", Value: "true", Source: prodPath},
+	})
+
+	// the merged document keeps the comments of the base config
+	enable := cfg.Doc.First("grpc-server", "enable")
+	assert.Assert(t, enable != nil)
+	assert.DeepEqual(t, []string(enable.Block), []string{"# Enable defines if the gRPC server should be enabled."})
+	assert.Equal(t, cfg.Doc.First("grpc-server", "address").Value.String(), `"0.0.0.0:9091"`)
+	assert.Equal(t, cfg.Doc.First("telemetry", "enabled").Value.String(), "true")
+}
@@ -0,0 +1,278 @@
+package confix
+
+import (
+	"net"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	pruningtypes "cosmossdk.io/store/pruning/types"
+
+	"github.com/cosmos/cosmos-sdk/telemetry"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+func checkMinGasPrices(cfg Values) []Issue {
+	gasPrices, _ := cfg.String("minimum-gas-prices")
+	if gasPrices == "" {
+		return []Issue{errorIssue("minimum-gas-prices", "min-gas-prices", "must be set, e.g. 0stake")}
+	}
+
+	if _, err := sdk.ParseDecCoins(gasPrices); err != nil {
+		return []Issue{errorIssue("minimum-gas-prices", "min-gas-prices", "invalid gas prices: %v", err)}
+	}
+
+	return nil
+}
+
+// pruningUint parses the pruning options, which are strings holding unsigned integers.
+func pruningUint(cfg Values, key string) (uint64, *Issue) {
+	var s string
+	switch v := cfg[key].(type) {
+	case nil:
+		return 0, nil
+	case string:
+		s = v
+	case int64:
+		s = strconv.FormatInt(v, 10)
+	}
+
+	if s == "" {
+		return 0, nil
+	}
+
+	i, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		issue := errorIssue(key, "type", "%q is not an unsigned integer", s)
+		return 0, &issue
+	}
+
+	return i, nil
+}
+
+func checkPruning(cfg Values) []Issue {
+	var issues []Issue
+	keepRecent, issue := pruningUint(cfg, "pruning-keep-recent")
+	if issue != nil {
+		issues = append(issues, *issue)
+	}
+	interval, issue := pruningUint(cfg, "pruning-interval")
+	if issue != nil {
+		issues = append(issues, *issue)
+	}
+	if len(issues) > 0 {
+		return issues
+	}
+
+	strategy, _ := cfg.String("pruning")
+	if strategy != pruningtypes.PruningOptionCustom {
+		if keepRecent != 0 || interval != 0 {
+			issues = append(issues, warningIssue("pruning", "pruning", "pruning-keep-recent and pruning-interval are ignored unless pruning is %q", pruningtypes.PruningOptionCustom))
+		}
+
+		return issues
+	}
+
+	if err := pruningtypes.NewCustomPruningOptions(keepRecent, interval).Validate(); err != nil {
+		issues = append(issues, errorIssue("pruning", "pruning", "invalid custom pruning: %v", err))
+	}
+
+	return issues
+}
+
+func checkStateSync(cfg Values) []Issue {
+	snapshotInterval, _ := cfg.Int("state-sync.snapshot-interval")
+	if snapshotInterval <= 0 {
+		return nil
+	}
+
+	if strategy, _ := cfg.String("pruning"); strategy == pruningtypes.PruningOptionEverything {
+		return []Issue{errorIssue("state-sync.snapshot-interval", "exclusive", "state sync snapshots cannot be enabled with %q pruning", pruningtypes.PruningOptionEverything)}
+	}
+
+	return nil
+}
+
+func checkHalt(cfg Values) []Issue {
+	haltHeight, _ := cfg.Int("halt-height")
+	haltTime, _ := cfg.Int("halt-time")
+	if haltHeight > 0 && haltTime > 0 {
+		return []Issue{warningIssue("halt-height", "exclusive", "both halt-height and halt-time are set, the node halts at the first one reached")}
+	}
+
+	return nil
+}
+
+func checkTelemetry(cfg Values) []Issue {
+	var issues []Issue
+	sink, _ := cfg.String("telemetry.metrics-sink")
+	if sink == telemetry.MetricSinkStatsd || sink == telemetry.MetricSinkDogsStatsd {
+		if addr, _ := cfg.String("telemetry.statsd-addr"); addr == "" {
+			issues = append(issues, errorIssue("telemetry.statsd-addr", "requires", "must be set when the metrics sink is %q", sink))
+		}
+	}
+
+	if retention, _ := cfg.Int("telemetry.prometheus-retention-time"); retention > 0 {
+		if !cfg.Bool("telemetry.enabled") {
+			issues = append(issues, warningIssue("telemetry.prometheus-retention-time", "requires", "prometheus metrics are disabled unless telemetry.enabled is true"))
+		} else if !cfg.Bool("api.enable") {
+			issues = append(issues, warningIssue("telemetry.prometheus-retention-time", "requires", "prometheus metrics are served by the API server, api.enable must be true"))
+		}
+	}
+
+	return issues
+}
+
+func checkStreaming(cfg Values) []Issue {
+	var issues []Issue
+	if plugin, _ := cfg.String("streaming.abci.plugin"); plugin != "" && len(cfg.Strings("streaming.abci.keys")) == 0 {
+		issues = append(issues, warningIssue("streaming.abci.keys", "requires", "no store key is streamed to the %q plugin", plugin))
+	}
+
+	if driver, _ := cfg.String("streaming.indexer.driver"); driver != "" {
+		if !cfg.Bool("streaming.queue.enable") {
+			issues = append(issues, errorIssue("streaming.indexer.driver", "requires", "the indexer requires streaming.queue.enable to be true"))
+		}
+		if startHeight, _ := cfg.Int("streaming.indexer.start-height"); startHeight <= 0 {
+			issues = append(issues, errorIssue("streaming.indexer.start-height", "requires", "must be set when the indexer is enabled"))
+		}
+		if len(cfg.Strings("streaming.indexer.keys")) == 0 {
+			issues = append(issues, warningIssue("streaming.indexer.keys", "requires", "no store key is indexed"))
+		}
+	}
+
+	if cfg.Bool("streaming.queue.enable") {
+		if interval, ok := cfg.String("streaming.queue.retry-interval"); ok {
+			if d, err := time.ParseDuration(interval); err == nil && d <= 0 {
+				issues = append(issues, errorIssue("streaming.queue.retry-interval", "range", "must be positive when the queue is enabled"))
+			}
+		}
+	} else if replayHeight, _ := cfg.Int("streaming.queue.replay-height"); replayHeight > 0 {
+		issues = append(issues, warningIssue("streaming.queue.replay-height", "requires", "the replay is ignored unless streaming.queue.enable is true"))
+	}
+
+	return issues
+}
+
+func checkTxDecode(cfg Values) []Issue {
+	denied := map[string]bool{}
+	for _, typeURL := range cfg.Strings("tx-decode.denied-type-urls") {
+		denied[typeURL] = true
+	}
+
+	var issues []Issue
+	for _, typeURL := range cfg.Strings("tx-decode.allowed-type-urls") {
+		if denied[typeURL] {
+			issues = append(issues, errorIssue("tx-decode.allowed-type-urls", "exclusive", "%s is both allowed and denied", typeURL))
+		}
+	}
+
+	return issues
+}
+
+// checkPortConflicts reports the enabled listen addresses sharing a port.
+func (s Schema) checkPortConflicts(cfg Values) []Issue {
+	type listener struct {
+		key, host, port string
+	}
+
+	var (
+		issues    []Issue
+		listeners []listener
+	)
+	for key, field := range s.Fields {
+		if !field.Listen || field.ListenEnabledBy != "" && !cfg.Bool(field.ListenEnabledBy) {
+			continue
+		}
+
+		addr, ok := cfg.String(key)
+		if !ok || field.ListenIfSet && addr == "" {
+			continue
+		}
+
+		host, port, err := splitListenAddress(addr)
+		if err != nil {
+			issues = append(issues, errorIssue(key, "address", "invalid listen address %q: %v", addr, err))
+			continue
+		}
+
+		listeners = append(listeners, listener{key: key, host: host, port: port})
+	}
+
+	sort.Slice(listeners, func(i, j int) bool { return listeners[i].key < listeners[j].key })
+	for i, a := range listeners {
+		for _, b := range listeners[i+1:] {
+			if a.port == b.port && a.port != "0" && hostsOverlap(a.host, b.host) {
+				issues = append(issues, errorIssue(b.key, "port-conflict", "port %s is also used by %s", b.port, a.key))
+			}
+		}
+	}
+
+	return issues
+}
+
+// splitListenAddress splits an address, optionally prefixed by a scheme (e.g. tcp://), in host and port.
+func splitListenAddress(addr string) (string, string, error) {
+	if strings.Contains(addr, "://") {
+		u, err := url.Parse(addr)
+		if err != nil {
+			return "", "", err
+		}
+		addr = u.Host
+	}
+
+	return net.SplitHostPort(addr)
+}
+
+// hostsOverlap returns true if listening on both hosts can conflict.
+func hostsOverlap(a, b string) bool {
+	wildcard := func(host string) bool {
+		return host == "" || host == "0.0.0.0" || host == "::"
+	}
+
+	return a == b || wildcard(a) || wildcard(b) ||
+		isLoopback(a) && isLoopback(b)
+}
+
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func checkChainID(cfg Values) []Issue {
+	if chainID, _ := cfg.String("chain-id"); chainID == "" {
+		return []Issue{errorIssue("chain-id", "required", "must be set")}
+	}
+
+	return nil
+}
+
+func checkNode(cfg Values) []Issue {
+	node, ok := cfg.String("node")
+	if !ok || node == "" {
+		return nil
+	}
+
+	u, err := url.Parse(node)
+	if err != nil {
+		return []Issue{errorIssue("node", "address", "invalid node address %q: %v", node, err)}
+	}
+
+	switch u.Scheme {
+	case "tcp", "http", "https", "ws", "wss":
+		if u.Host == "" {
+			return []Issue{errorIssue("node", "address", "invalid node address %q: missing host", node)}
+		}
+	case "unix":
+	default:
+		return []Issue{errorIssue("node", "address", "invalid node address %q: unsupported scheme %q", node, u.Scheme)}
+	}
+
+	return nil
+}
@@ -0,0 +1,415 @@
+package confix
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+
+	pruningtypes "cosmossdk.io/store/pruning/types"
+
+	"github.com/cosmos/cosmos-sdk/telemetry"
+)
+
+//go:generate go -C schemagen run . -out ../data
+
+// ValueType is the expected type of a configuration value.
+type ValueType string
+
+const (
+	TypeBool       ValueType = "bool"
+	TypeInt        ValueType = "int"
+	TypeFloat      ValueType = "float"
+	TypeString     ValueType = "string"
+	TypeDuration   ValueType = "duration"
+	TypeStringList ValueType = "string-list"
+	TypeList       ValueType = "list"
+	TypeTable      ValueType = "table"
+	TypeAny        ValueType = "any"
+)
+
+// FieldSchema describes the expected value of a configuration key.
+type FieldSchema struct {
+	// Key is the dotted path of the key, e.g. grpc.address.
+	Key  string
+	Type ValueType
+	// Min and Max bound integer values, when set.
+	Min, Max *int64
+	// MinFloat and MaxFloat bound float values, when set.
+	MinFloat, MaxFloat *float64
+	// Enum lists the accepted values of a string, when set.
+	Enum []string
+	// ListenEnabledBy marks an address the node listens on, enabled by the given boolean key
+	// (always enabled when empty). Listen addresses are checked for port conflicts.
+	Listen          bool
+	ListenEnabledBy string
+	// ListenIfSet marks a listen address disabled when it's empty.
+	ListenIfSet bool
+}
+
+// Rule is a semantic check spanning one or more keys of a configuration.
+type Rule struct {
+	Name  string
+	Check func(cfg Values) []Issue
+}
+
+// Schema describes the keys of a configuration file and the rules they must follow.
+type Schema struct {
+	Fields map[string]*FieldSchema
+	// Deprecated are the keys of the previous versions of the configuration file, which are
+	// ignored by the current version.
+	Deprecated map[string]bool
+	Rules      []Rule
+}
+
+// SchemaFromStruct derives the fields of a schema from the mapstructure tags of a config struct,
+// the way viper decodes the configuration files.
+func SchemaFromStruct(v any) map[string]*FieldSchema {
+	fields := map[string]*FieldSchema{}
+	addStructFields(fields, "", reflect.TypeOf(v))
+	return fields
+}
+
+func addStructFields(fields map[string]*FieldSchema, prefix string, t reflect.Type) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(f.Tag.Get("mapstructure"), ",")
+		if name == "-" {
+			continue
+		}
+
+		ft := f.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+
+		if ft.Kind() == reflect.Struct && (opts == "squash" || f.Anonymous && name == "") {
+			addStructFields(fields, prefix, ft)
+			continue
+		}
+
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		key := prefix + name
+
+		if ft.Kind() == reflect.Struct {
+			addStructFields(fields, key+".", ft)
+			continue
+		}
+
+		fields[key] = fieldFromType(key, ft)
+	}
+}
+
+func fieldFromType(key string, t reflect.Type) *FieldSchema {
+	field := &FieldSchema{Key: key}
+
+	// viper decodes durations from strings, e.g. "1s"
+	if t == reflect.TypeOf(time.Duration(0)) {
+		field.Type = TypeDuration
+		return field
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		field.Type = TypeBool
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		field.Type = TypeInt
+		bits := t.Bits()
+		field.Min, field.Max = int64Ptr(-1<<(bits-1)), int64Ptr(1<<(bits-1)-1)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		field.Type = TypeInt
+		field.Min = int64Ptr(0)
+		// TOML integers are int64
+		if bits := t.Bits(); bits < 64 {
+			field.Max = int64Ptr(1<<bits - 1)
+		} else {
+			field.Max = int64Ptr(math.MaxInt64)
+		}
+	case reflect.Float32, reflect.Float64:
+		field.Type = TypeFloat
+	case reflect.String:
+		field.Type = TypeString
+	case reflect.Slice, reflect.Array:
+		field.Type = TypeList
+		if t.Elem().Kind() == reflect.String {
+			field.Type = TypeStringList
+		}
+	case reflect.Map:
+		field.Type = TypeTable
+	default:
+		field.Type = TypeAny
+	}
+
+	return field
+}
+
+// SchemaFromValues derives the fields of a schema from the values of a configuration template.
+// The type of each field is the one of its value: integers are not bounded, and empty lists
+// are lists of strings.
+func SchemaFromValues(values Values) map[string]*FieldSchema {
+	fields := map[string]*FieldSchema{}
+	for key, value := range values {
+		field := &FieldSchema{Key: key, Type: TypeAny}
+		switch v := value.(type) {
+		case bool:
+			field.Type = TypeBool
+		case int64:
+			field.Type = TypeInt
+		case float64:
+			field.Type = TypeFloat
+		case string:
+			field.Type = TypeString
+		case []any:
+			field.Type = TypeStringList
+			for _, item := range v {
+				if _, ok := item.(string); !ok {
+					field.Type = TypeList
+					break
+				}
+			}
+		}
+		fields[key] = field
+	}
+
+	return fields
+}
+
+func int64Ptr(i int64) *int64 {
+	return &i
+}
+
+func float64Ptr(x float64) *float64 {
+	return &x
+}
+
+// set applies the given constraints to the field of the schema with the given key.
+func (s Schema) set(key string, f func(*FieldSchema)) {
+	field, ok := s.Fields[key]
+	if !ok {
+		panic(fmt.Errorf("unknown schema field %s", key))
+	}
+
+	f(field)
+}
+
+// latestVersion is the latest version of the monolithic app.toml, before server/v2 split it in component sections.
+const latestVersion = "v0.51"
+
+// addFields adds the fields not part of the schema yet.
+func (s Schema) addFields(fields map[string]*FieldSchema) {
+	for key, field := range fields {
+		if _, ok := s.Fields[key]; !ok {
+			s.Fields[key] = field
+		}
+	}
+}
+
+// setDeprecated marks the keys of the shipped templates of the given config type which are
+// not part of the schema as deprecated.
+func (s *Schema) setDeprecated(configType string) {
+	s.Deprecated = map[string]bool{}
+	for _, values := range mustLoadTemplates(configType) {
+		for key := range values {
+			if _, ok := s.Fields[key]; !ok {
+				s.Deprecated[key] = true
+			}
+		}
+	}
+}
+
+// fieldData is a field of the schema data shipped with confix.
+type fieldData struct {
+	Type ValueType `json:"type"`
+	Min  *int64    `json:"min,omitempty"`
+	Max  *int64    `json:"max,omitempty"`
+}
+
+// schemaDataFileName returns the name of the schema data of the given version and config type.
+func schemaDataFileName(version, configType string) (string, error) {
+	fileName, err := getFileName(version, configType)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSuffix(fileName, ".toml") + ".schema.json", nil
+}
+
+// MarshalSchemaData encodes the types and bounds of the fields derived from a config, the way
+// the schema data shipped with confix is written. The schema data is generated by the schemagen
+// module from the in-tree configs, so that confix doesn't depend on unreleased modules.
+func MarshalSchemaData(fields map[string]*FieldSchema) ([]byte, error) {
+	data := make(map[string]fieldData, len(fields))
+	for key, field := range fields {
+		data[key] = fieldData{Type: field.Type, Min: field.Min, Max: field.Max}
+	}
+
+	bz, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append(bz, '\n'), nil
+}
+
+// mustLoadSchemaData returns the fields of the schema data of the given version and config
+// type shipped with confix.
+func mustLoadSchemaData(version, configType string) map[string]*FieldSchema {
+	fileName, err := schemaDataFileName(version, configType)
+	if err != nil {
+		panic(err)
+	}
+
+	bz, err := data.ReadFile(filepath.Join("data", fileName))
+	if err != nil {
+		panic(fmt.Errorf("failed to read file: %w. This file should have been included in confix", err))
+	}
+
+	var fieldsData map[string]fieldData
+	if err := json.Unmarshal(bz, &fieldsData); err != nil {
+		panic(fmt.Errorf("failed to parse file: %w. This file should have been valid", err))
+	}
+
+	fields := make(map[string]*FieldSchema, len(fieldsData))
+	for key, f := range fieldsData {
+		fields[key] = &FieldSchema{Key: key, Type: f.Type, Min: f.Min, Max: f.Max}
+	}
+
+	return fields
+}
+
+// mustLoadTemplates returns the values of the templates of the given config type shipped with
+// confix, by version.
+func mustLoadTemplates(configType string) map[string]Values {
+	templates := map[string]Values{}
+	for version := range Migrations {
+		if version == ComponentsVersion && configType == AppConfigType {
+			// the component sections are validated against the component schema
+			continue
+		}
+
+		fileName, err := getFileName(version, configType)
+		if err != nil {
+			panic(err)
+		}
+
+		bz, err := data.ReadFile(filepath.Join("data", fileName))
+		if err != nil {
+			// not every version ships every config type
+			continue
+		}
+
+		values, err := ParseValues(bz)
+		if err != nil {
+			panic(fmt.Errorf("failed to parse file: %w. This file should have been valid", err))
+		}
+		templates[version] = values
+	}
+
+	return templates
+}
+
+// latestTemplate returns the values of the template of the given config type of the latest
+// version shipped with confix.
+func latestTemplate(configType string) Values {
+	fileName, err := getFileName(latestVersion, configType)
+	if err != nil {
+		panic(err)
+	}
+
+	bz, err := data.ReadFile(filepath.Join("data", fileName))
+	if err != nil {
+		panic(fmt.Errorf("failed to read file: %w. This file should have been included in confix", err))
+	}
+
+	values, err := ParseValues(bz)
+	if err != nil {
+		panic(fmt.Errorf("failed to parse file: %w. This file should have been valid", err))
+	}
+
+	return values
+}
+
+// AppSchema returns the schema of app.toml. The fields are derived from the server config and
+// its default template, through the schema data generated from them, then from the keys of the
+// latest app.toml shipped with confix, which also holds the custom config of simapp.
+func AppSchema() Schema {
+	s := Schema{Fields: mustLoadSchemaData(latestVersion, AppConfigType)}
+	s.addFields(SchemaFromValues(latestTemplate(AppConfigType)))
+	s.setDeprecated(AppConfigType)
+
+	s.set("pruning", func(f *FieldSchema) {
+		f.Enum = []string{pruningtypes.PruningOptionDefault, pruningtypes.PruningOptionNothing, pruningtypes.PruningOptionEverything, pruningtypes.PruningOptionCustom}
+	})
+	s.set("app-db-backend", func(f *FieldSchema) {
+		f.Enum = []string{"", "goleveldb", "cleveldb", "rocksdb", "badgerdb", "boltdb", "pebbledb", "memdb"}
+	})
+	s.set("telemetry.metrics-sink", func(f *FieldSchema) {
+		f.Enum = []string{"", telemetry.MetricSinkInMem, telemetry.MetricSinkStatsd, telemetry.MetricSinkDogsStatsd}
+	})
+	s.set("telemetry.prometheus-retention-time", func(f *FieldSchema) { f.Min = int64Ptr(0) })
+	s.set("telemetry.tracing.sample-rate", func(f *FieldSchema) { f.MinFloat, f.MaxFloat = float64Ptr(0), float64Ptr(1) })
+	s.set("grpc.max-recv-msg-size", func(f *FieldSchema) { f.Min = int64Ptr(0) })
+	s.set("grpc.max-send-msg-size", func(f *FieldSchema) { f.Min = int64Ptr(0) })
+	// -1 disables the app mempool, 0 makes it unbounded
+	s.set("mempool.max-txs", func(f *FieldSchema) { f.Min = int64Ptr(-1) })
+	for _, key := range []string{"tx-decode.max-msgs", "tx-decode.max-msg-size", "tx-decode.max-any-depth"} {
+		s.set(key, func(f *FieldSchema) { f.Min = int64Ptr(0) })
+	}
+	s.set("streaming.indexer.driver", func(f *FieldSchema) { f.Enum = []string{"", "postgres", "sqlite3"} })
+	s.set("streaming.indexer.start-height", func(f *FieldSchema) { f.Min = int64Ptr(0) })
+	s.set("streaming.queue.replay-height", func(f *FieldSchema) { f.Min = int64Ptr(0) })
+	s.set("streaming.queue.db-backend", func(f *FieldSchema) {
+		f.Enum = []string{"", "goleveldb", "cleveldb", "rocksdb", "badgerdb", "boltdb", "pebbledb", "memdb"}
+	})
+	s.set("api.address", func(f *FieldSchema) { f.Listen, f.ListenEnabledBy = true, "api.enable" })
+	s.set("grpc.address", func(f *FieldSchema) { f.Listen, f.ListenEnabledBy = true, "grpc.enable" })
+	s.set("api.log-admin-address", func(f *FieldSchema) { f.Listen, f.ListenIfSet = true, true })
+
+	s.Rules = []Rule{
+		{Name: "min-gas-prices", Check: checkMinGasPrices},
+		{Name: "pruning", Check: checkPruning},
+		{Name: "state-sync", Check: checkStateSync},
+		{Name: "halt", Check: checkHalt},
+		{Name: "telemetry", Check: checkTelemetry},
+		{Name: "streaming", Check: checkStreaming},
+		{Name: "tx-decode", Check: checkTxDecode},
+		{Name: "port-conflict", Check: s.checkPortConflicts},
+	}
+
+	return s
+}
+
+// ClientSchema returns the schema of client.toml. The fields are derived from the client config
+// and its default template, through the schema data generated from them, then from the keys of
+// the latest client.toml shipped with confix.
+func ClientSchema() Schema {
+	s := Schema{Fields: mustLoadSchemaData(latestVersion, ClientConfigType)}
+	s.addFields(SchemaFromValues(latestTemplate(ClientConfigType)))
+	s.setDeprecated(ClientConfigType)
+
+	s.set("keyring-backend", func(f *FieldSchema) {
+		f.Enum = []string{"os", "file", "kwallet", "pass", "test", "memory"}
+	})
+	s.set("output", func(f *FieldSchema) { f.Enum = []string{"text", "json"} })
+	s.set("broadcast-mode", func(f *FieldSchema) { f.Enum = []string{"sync", "async"} })
+
+	s.Rules = []Rule{
+		{Name: "chain-id", Check: checkChainID},
+		{Name: "node", Check: checkNode},
+	}
+
+	return s
+}
@@ -0,0 +1,109 @@
+// schemagen generates the schema data shipped with confix: the types and bounds of the keys of
+// the configuration files, derived from the in-tree server, client and server/v2 component
+// configs. It is a module of its own, so that confix only depends on released modules.
+//
+// Run it with go generate in tools/confix when one of the configs changes.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/template"
+
+	"cosmossdk.io/server/v2/api/grpc"
+	"cosmossdk.io/server/v2/api/grpcgateway"
+	"cosmossdk.io/server/v2/api/logadmin"
+	"cosmossdk.io/server/v2/api/telemetry"
+	"cosmossdk.io/tools/confix"
+
+	clientcfg "github.com/cosmos/cosmos-sdk/client/config"
+	srvcfg "github.com/cosmos/cosmos-sdk/server/config"
+)
+
+// componentConfigs are the configs of the server/v2 API components, by section of app.toml.
+// The cometbft and store configs are not part of it: their packages register the store/v2
+// snapshot protos, which conflict with the store ones linked in the node binaries.
+type componentConfigs struct {
+	GRPC        grpc.Config        `mapstructure:"grpc-server"`
+	GRPCGateway grpcgateway.Config `mapstructure:"grpc-gateway"`
+	LogAdmin    logadmin.Config    `mapstructure:"log-admin"`
+	Telemetry   telemetry.Config   `mapstructure:"telemetry"`
+}
+
+func main() {
+	out := flag.String("out", "../data", "the confix data directory the schema data is written to")
+	flag.Parse()
+
+	files, err := generate()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	for name, bz := range files {
+		if err := os.WriteFile(filepath.Join(*out, name), bz, 0o600); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+}
+
+// generate returns the schema data files, by file name.
+func generate() (map[string][]byte, error) {
+	appValues, err := renderTemplate(srvcfg.DefaultConfigTemplate, srvcfg.DefaultConfig())
+	if err != nil {
+		return nil, err
+	}
+	clientValues, err := renderTemplate(clientcfg.DefaultClientConfigTemplate, clientcfg.DefaultConfig())
+	if err != nil {
+		return nil, err
+	}
+
+	// the keys of the default templates which aren't decoded in the configs are part of the schema
+	schemas := map[string]map[string]*confix.FieldSchema{
+		"v0.51-app.schema.json":    withFields(confix.SchemaFromStruct(srvcfg.Config{}), confix.SchemaFromValues(appValues)),
+		"v0.51-client.schema.json": withFields(confix.SchemaFromStruct(clientcfg.Config{}), confix.SchemaFromValues(clientValues)),
+		"v2-app.schema.json":       confix.SchemaFromStruct(componentConfigs{}),
+	}
+
+	files := make(map[string][]byte, len(schemas))
+	for name, fields := range schemas {
+		bz, err := confix.MarshalSchemaData(fields)
+		if err != nil {
+			return nil, err
+		}
+		files[name] = bz
+	}
+
+	return files, nil
+}
+
+// withFields adds the fields not part of the schema fields yet.
+func withFields(fields, other map[string]*confix.FieldSchema) map[string]*confix.FieldSchema {
+	for key, field := range other {
+		if _, ok := fields[key]; !ok {
+			fields[key] = field
+		}
+	}
+
+	return fields
+}
+
+// renderTemplate renders a configuration template with the given config, the way the
+// configuration files are written.
+func renderTemplate(tmpl string, cfg any) (confix.Values, error) {
+	t, err := template.New("config").Parse(tmpl)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, cfg); err != nil {
+		return nil, fmt.Errorf("failed to render config template: %w", err)
+	}
+
+	return confix.ParseValues(buf.Bytes())
+}
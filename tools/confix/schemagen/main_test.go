@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"text/template"
+
+	"gotest.tools/v3/assert"
+
+	"cosmossdk.io/tools/confix"
+
+	clientcfg "github.com/cosmos/cosmos-sdk/client/config"
+	srvcfg "github.com/cosmos/cosmos-sdk/server/config"
+)
+
+// TestSchemaDataUpToDate checks that the schema data shipped with confix matches the in-tree configs.
+func TestSchemaDataUpToDate(t *testing.T) {
+	files, err := generate()
+	assert.NilError(t, err)
+
+	for name, bz := range files {
+		shipped, err := os.ReadFile(filepath.Join("..", "data", name))
+		assert.NilError(t, err)
+		assert.Equal(t, string(shipped), string(bz), "%s is out of date, run go generate in tools/confix", name)
+	}
+}
+
+// TestValidateStrictInit validates strictly the config files written by a node on init, with the
+// in-tree default templates.
+func TestValidateStrictInit(t *testing.T) {
+	// app.toml is written the way simd does, with the default template and minimum gas prices
+	srvCfg := srvcfg.DefaultConfig()
+	srvCfg.MinGasPrices = "0stake"
+	clientCfg := clientcfg.DefaultConfig()
+	clientCfg.ChainID = "test-chain"
+
+	for file, rendered := range map[string]string{
+		"app.toml":    mustRenderTemplate(t, srvcfg.DefaultConfigTemplate, srvCfg),
+		"client.toml": mustRenderTemplate(t, clientcfg.DefaultClientConfigTemplate, clientCfg),
+	} {
+		report, err := confix.Validate(file, []byte(rendered))
+		assert.NilError(t, err)
+		assert.Equal(t, report.Errors()+report.Warnings(), 0, "%s: %v", file, report.Issues)
+	}
+}
+
+func mustRenderTemplate(t *testing.T, tmpl string, cfg any) string {
+	t.Helper()
+
+	var buf bytes.Buffer
+	assert.NilError(t, template.Must(template.New("config").Parse(tmpl)).Execute(&buf, cfg))
+	return buf.String()
+}
@@ -67,6 +67,7 @@ func Upgrade(ctx context.Context, plan transform.Plan, configPath, outputPath st
 
 // CheckValid checks whether the specified config appears to be a valid Cosmos SDK config file.
 // It tries to unmarshal the config into both the server and client config structs.
+// An app.toml split in server/v2 component sections is checked against the component schema instead.
 func CheckValid(fileName string, data []byte) error {
 	v := viper.New()
 	v.SetConfigType("toml")
@@ -75,7 +76,23 @@ func CheckValid(fileName string, data []byte) error {
 		return fmt.Errorf("reading config: %w", err)
 	}
 
+	values, err := ParseValues(data)
+	if err != nil {
+		return err
+	}
+
 	switch {
+	case strings.HasSuffix(fileName, AppConfig) && IsComponentConfig(values):
+		report, err := ComponentSchema().Validate(data)
+		if err != nil {
+			return err
+		}
+
+		for _, issue := range report.Issues {
+			if issue.Severity == SeverityError {
+				return fmt.Errorf("server config invalid: %s", issue)
+			}
+		}
 	case strings.HasSuffix(fileName, AppConfig):
 		var cfg srvcfg.Config
 		if err := v.Unmarshal(&cfg); err != nil {
@@ -0,0 +1,336 @@
+package confix
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+// Severity is the severity of a validation issue.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	// SeverityInfo issues are notes which don't make the configuration invalid, even in strict mode.
+	SeverityInfo Severity = "info"
+)
+
+// Issue is a problem found when validating a configuration.
+type Issue struct {
+	Key      string   `json:"key,omitempty"`
+	Severity Severity `json:"severity"`
+	// Rule is the name of the check which found the issue, e.g. type, range, enum, unknown-key or port-conflict.
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+func (i Issue) String() string {
+	if i.Key == "" {
+		return fmt.Sprintf("%s: [%s] %s", i.Severity, i.Rule, i.Message)
+	}
+
+	return fmt.Sprintf("%s: %s: [%s] %s", i.Severity, i.Key, i.Rule, i.Message)
+}
+
+func errorIssue(key, rule, format string, args ...any) Issue {
+	return Issue{Key: key, Severity: SeverityError, Rule: rule, Message: fmt.Sprintf(format, args...)}
+}
+
+func warningIssue(key, rule, format string, args ...any) Issue {
+	return Issue{Key: key, Severity: SeverityWarning, Rule: rule, Message: fmt.Sprintf(format, args...)}
+}
+
+func infoIssue(key, rule, format string, args ...any) Issue {
+	return Issue{Key: key, Severity: SeverityInfo, Rule: rule, Message: fmt.Sprintf(format, args...)}
+}
+
+// ValidationReport lists the issues found in a configuration file.
+type ValidationReport struct {
+	File   string  `json:"file"`
+	Issues []Issue `json:"issues"`
+}
+
+// Errors returns the number of issues with the error severity.
+func (r ValidationReport) Errors() int {
+	return r.count(SeverityError)
+}
+
+// Warnings returns the number of issues with the warning severity.
+func (r ValidationReport) Warnings() int {
+	return r.count(SeverityWarning)
+}
+
+func (r ValidationReport) count(severity Severity) int {
+	n := 0
+	for _, issue := range r.Issues {
+		if issue.Severity == severity {
+			n++
+		}
+	}
+
+	return n
+}
+
+// Values are the values of a configuration file, indexed by their dotted key.
+type Values map[string]any
+
+// String returns the string value of key, if set as a string.
+func (v Values) String(key string) (string, bool) {
+	s, ok := v[key].(string)
+	return s, ok
+}
+
+// Int returns the integer value of key, if set as an integer or as a numeric string.
+func (v Values) Int(key string) (int64, bool) {
+	if s, ok := v[key].(string); ok {
+		i, err := strconv.ParseInt(s, 10, 64)
+		return i, err == nil
+	}
+
+	i, ok := v[key].(int64)
+	return i, ok
+}
+
+// Bool returns the boolean value of key, if set as a boolean.
+func (v Values) Bool(key string) bool {
+	b, _ := v[key].(bool)
+	return b
+}
+
+// Strings returns the string values of a list.
+func (v Values) Strings(key string) []string {
+	list, _ := v[key].([]any)
+	res := make([]string, 0, len(list))
+	for _, item := range list {
+		if s, ok := item.(string); ok {
+			res = append(res, s)
+		}
+	}
+
+	return res
+}
+
+// ParseValues parses a TOML document into its values indexed by their dotted key.
+func ParseValues(data []byte) (Values, error) {
+	var doc map[string]any
+	if err := toml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to decode config file: %w", err)
+	}
+
+	values := Values{}
+	flattenValues(values, "", doc)
+	return values, nil
+}
+
+func flattenValues(values Values, prefix string, table map[string]any) {
+	for key, value := range table {
+		if subTable, ok := value.(map[string]any); ok {
+			flattenValues(values, prefix+key+".", subTable)
+			continue
+		}
+
+		values[prefix+key] = value
+	}
+}
+
+// SchemaFor returns the schema of the configuration file, based on its name.
+func SchemaFor(fileName string) (Schema, error) {
+	switch {
+	case strings.HasSuffix(fileName, AppConfig):
+		return AppSchema(), nil
+	case strings.HasSuffix(fileName, ClientConfig):
+		return ClientSchema(), nil
+	case strings.HasSuffix(fileName, CMTConfig):
+		return Schema{}, errors.New("cometbft config is not supported")
+	default:
+		return Schema{}, fmt.Errorf("unknown config: %s", fileName)
+	}
+}
+
+// Validate validates the configuration file, using the schema of its kind (app.toml or client.toml).
+// An app.toml split in server/v2 component sections is validated against the component schema.
+func Validate(fileName string, data []byte) (ValidationReport, error) {
+	schema, err := SchemaFor(fileName)
+	if err != nil {
+		return ValidationReport{}, err
+	}
+
+	if strings.HasSuffix(fileName, AppConfig) {
+		values, err := ParseValues(data)
+		if err != nil {
+			return ValidationReport{}, err
+		}
+
+		if IsComponentConfig(values) {
+			schema = ComponentSchema()
+		}
+	}
+
+	report, err := schema.Validate(data)
+	report.File = fileName
+	return report, err
+}
+
+// Validate checks the types, ranges and enum values of the keys of the configuration,
+// then the rules of the schema. Unknown keys are reported as warnings, and the keys of previous
+// versions of the configuration as info.
+func (s Schema) Validate(data []byte) (ValidationReport, error) {
+	values, err := ParseValues(data)
+	if err != nil {
+		return ValidationReport{}, err
+	}
+
+	report := ValidationReport{Issues: []Issue{}}
+	for key, value := range values {
+		field, ok := s.Fields[key]
+		if !ok && s.Deprecated[key] {
+			report.Issues = append(report.Issues, infoIssue(key, "deprecated-key", "removed in a later version, it is ignored (run confix migrate to remove it)"))
+			continue
+		} else if !ok {
+			report.Issues = append(report.Issues, warningIssue(key, "unknown-key", "unknown key, it is ignored"))
+			continue
+		}
+
+		issue, ok := field.check(value)
+		if issue.Rule != "" {
+			report.Issues = append(report.Issues, issue)
+		}
+		if !ok {
+			// do not check rules against invalid values
+			delete(values, key)
+		}
+	}
+
+	for _, rule := range s.Rules {
+		report.Issues = append(report.Issues, rule.Check(values)...)
+	}
+
+	severityOrder := map[Severity]int{SeverityError: 0, SeverityWarning: 1, SeverityInfo: 2}
+	sort.SliceStable(report.Issues, func(i, j int) bool {
+		if report.Issues[i].Severity != report.Issues[j].Severity {
+			return severityOrder[report.Issues[i].Severity] < severityOrder[report.Issues[j].Severity]
+		}
+
+		return report.Issues[i].Key < report.Issues[j].Key
+	})
+
+	return report, nil
+}
+
+// check checks the value against the field, and returns the issue found if any.
+// It returns false when the value is invalid.
+func (f FieldSchema) check(value any) (Issue, bool) {
+	switch f.Type {
+	case TypeBool:
+		if _, ok := value.(bool); !ok {
+			return f.typeIssue(value), false
+		}
+	case TypeInt:
+		i, ok := value.(int64)
+		if s, isString := value.(string); isString {
+			// viper decodes numeric strings
+			var err error
+			i, err = strconv.ParseInt(s, 10, 64)
+			ok = err == nil
+		}
+		if !ok {
+			return f.typeIssue(value), false
+		}
+		if f.Min != nil && i < *f.Min {
+			return errorIssue(f.Key, "range", "%d is lower than the minimum %d", i, *f.Min), false
+		}
+		if f.Max != nil && i > *f.Max {
+			return errorIssue(f.Key, "range", "%d is greater than the maximum %d", i, *f.Max), false
+		}
+	case TypeFloat:
+		var x float64
+		switch v := value.(type) {
+		case float64:
+			x = v
+		case int64:
+			x = float64(v)
+		case string:
+			var err error
+			if x, err = strconv.ParseFloat(v, 64); err != nil {
+				return f.typeIssue(value), false
+			}
+		default:
+			return f.typeIssue(value), false
+		}
+		if f.MinFloat != nil && x < *f.MinFloat {
+			return errorIssue(f.Key, "range", "%g is lower than the minimum %g", x, *f.MinFloat), false
+		}
+		if f.MaxFloat != nil && x > *f.MaxFloat {
+			return errorIssue(f.Key, "range", "%g is greater than the maximum %g", x, *f.MaxFloat), false
+		}
+	case TypeDuration:
+		switch v := value.(type) {
+		case int64:
+		case string:
+			if _, err := time.ParseDuration(v); err != nil {
+				return errorIssue(f.Key, "type", "%q is not a duration: %v", v, err), false
+			}
+		default:
+			return f.typeIssue(value), false
+		}
+	case TypeString:
+		s, ok := value.(string)
+		if !ok {
+			return f.typeIssue(value), false
+		}
+		if len(f.Enum) > 0 && !slices.Contains(f.Enum, s) {
+			return errorIssue(f.Key, "enum", "%q is not one of %q", s, f.Enum), false
+		}
+	case TypeStringList:
+		list, ok := value.([]any)
+		if !ok {
+			return f.typeIssue(value), false
+		}
+		for _, item := range list {
+			if _, ok := item.(string); !ok {
+				return errorIssue(f.Key, "type", "expected a list of strings, got an item of type %s", tomlType(item)), false
+			}
+		}
+	case TypeList:
+		if _, ok := value.([]any); !ok {
+			return f.typeIssue(value), false
+		}
+	case TypeTable:
+		if _, ok := value.(map[string]any); !ok {
+			return f.typeIssue(value), false
+		}
+	}
+
+	return Issue{}, true
+}
+
+func (f FieldSchema) typeIssue(value any) Issue {
+	return errorIssue(f.Key, "type", "expected %s, got %s", f.Type, tomlType(value))
+}
+
+// tomlType returns the TOML type of a decoded value.
+func tomlType(value any) string {
+	switch value.(type) {
+	case bool:
+		return "bool"
+	case int64:
+		return "int"
+	case float64:
+		return "float"
+	case string:
+		return "string"
+	case []any:
+		return "list"
+	case map[string]any:
+		return "table"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
@@ -0,0 +1,392 @@
+package confix_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"cosmossdk.io/tools/confix"
+)
+
+// issueKeys returns the rule and key of the issues of the given severity.
+func issueKeys(report confix.ValidationReport, severity confix.Severity) []string {
+	keys := []string{}
+	for _, issue := range report.Issues {
+		if issue.Severity == severity {
+			keys = append(keys, issue.Rule+":"+issue.Key)
+		}
+	}
+
+	return keys
+}
+
+func TestValidate(t *testing.T) {
+	_, err := confix.Validate("foo", []byte{})
+	assert.ErrorContains(t, err, "unknown config")
+
+	_, err = confix.Validate("config.toml", []byte{})
+	assert.Error(t, err, "cometbft config is not supported")
+
+	_, err = confix.Validate("app.toml", []byte("foo = "))
+	assert.ErrorContains(t, err, "failed to decode config file")
+
+	for _, file := range []string{"data/v0.50-app.toml", "data/v0.51-app.toml", "data/v0.50-client.toml", "data/v0.51-client.toml", "testdata/client.toml"} {
+		report, err := confix.Validate(file, mustReadConfig(t, file))
+		assert.NilError(t, err)
+		assert.Equal(t, report.File, file)
+		assert.Equal(t, report.Errors(), 0, "%s: %v", file, report.Issues)
+	}
+
+	// keys removed since v0.45 are reported as deprecated
+	report, err := confix.Validate("app.toml", mustReadConfig(t, "data/v0.45-app.toml"))
+	assert.NilError(t, err)
+	assert.Equal(t, report.Errors(), 0)
+	assert.Equal(t, report.Warnings(), 0)
+	assert.Assert(t, len(issueKeys(report, confix.SeverityInfo)) > 0)
+	for _, issue := range report.Issues {
+		if issue.Key == "rosetta.enable" {
+			assert.Equal(t, issue.Rule, "deprecated-key")
+		}
+	}
+}
+
+// TestValidateStrict checks that the config files shipped with confix have neither errors nor warnings.
+func TestValidateStrict(t *testing.T) {
+	entries, err := os.ReadDir("data")
+	assert.NilError(t, err)
+
+	for _, entry := range entries {
+		// the schema data isn't a config file
+		if filepath.Ext(entry.Name()) != ".toml" {
+			continue
+		}
+
+		file := filepath.Join("data", entry.Name())
+		report, err := confix.Validate(file, mustReadConfig(t, file))
+		assert.NilError(t, err)
+		assert.Equal(t, report.Errors()+report.Warnings(), 0, "%s: %v", file, report.Issues)
+	}
+}
+
+func TestValidateApp(t *testing.T) {
+	testCases := []struct {
+		name     string
+		config   string
+		errors   []string
+		warnings []string
+	}{
+		{
+			name:     "empty",
+			config:   ``,
+			errors:   []string{"min-gas-prices:minimum-gas-prices"},
+			warnings: []string{},
+		},
+		{
+			name: "valid",
+			config: `minimum-gas-prices = "0.025stake"
+pruning = "custom"
+pruning-keep-recent = "100"
+pruning-interval = "10"
+[api]
+enable = true
+address = "tcp://localhost:1317"
+[grpc]
+enable = true
+address = "localhost:9090"
+`,
+			errors:   []string{},
+			warnings: []string{},
+		},
+		{
+			name: "type errors",
+			config: `minimum-gas-prices = "0stake"
+halt-height = "ten"
+inter-block-cache = "true"
+iavl-cache-size = 10
+[grpc]
+max-recv-msg-size = "1024"
+max-send-msg-size = "1MB"
+`,
+			errors:   []string{"type:grpc.max-send-msg-size", "type:halt-height", "type:inter-block-cache"},
+			warnings: []string{},
+		},
+		{
+			name: "range and enum errors",
+			config: `minimum-gas-prices = "0stake"
+pruning = "sometimes"
+app-db-backend = "mysql"
+[mempool]
+max-txs = -2
+[telemetry]
+metrics-sink = "stdout"
+`,
+			errors:   []string{"enum:app-db-backend", "range:mempool.max-txs", "enum:pruning", "enum:telemetry.metrics-sink"},
+			warnings: []string{},
+		},
+		{
+			name: "tracing sample rate out of range",
+			config: `minimum-gas-prices = "0stake"
+[telemetry.tracing]
+sample-rate = 1.5
+`,
+			errors:   []string{"range:telemetry.tracing.sample-rate"},
+			warnings: []string{},
+		},
+		{
+			name: "tracing sample rate",
+			config: `minimum-gas-prices = "0stake"
+[telemetry.tracing]
+sample-rate = 0.25
+`,
+			errors:   []string{},
+			warnings: []string{},
+		},
+		{
+			name: "unknown keys",
+			config: `minimum-gas-prices = "0stake"
+foo = "bar"
+[grpc]
+bar = 1
+`,
+			errors:   []string{},
+			warnings: []string{"unknown-key:foo", "unknown-key:grpc.bar"},
+		},
+		{
+			name: "invalid gas prices",
+			config: `minimum-gas-prices = "stake"
+`,
+			errors:   []string{"min-gas-prices:minimum-gas-prices"},
+			warnings: []string{},
+		},
+		{
+			name: "invalid custom pruning",
+			config: `minimum-gas-prices = "0stake"
+pruning = "custom"
+pruning-keep-recent = "1"
+pruning-interval = "0"
+`,
+			errors:   []string{"pruning:pruning"},
+			warnings: []string{},
+		},
+		{
+			name: "non numeric pruning",
+			config: `minimum-gas-prices = "0stake"
+pruning = "custom"
+pruning-keep-recent = "-1"
+pruning-interval = "10"
+`,
+			errors:   []string{"type:pruning-keep-recent"},
+			warnings: []string{},
+		},
+		{
+			name: "ignored pruning options",
+			config: `minimum-gas-prices = "0stake"
+pruning = "default"
+pruning-keep-recent = "100"
+`,
+			errors:   []string{},
+			warnings: []string{"pruning:pruning"},
+		},
+		{
+			name: "state sync with pruning everything",
+			config: `minimum-gas-prices = "0stake"
+pruning = "everything"
+halt-height = 10
+halt-time = 10
+[state-sync]
+snapshot-interval = 1000
+`,
+			errors:   []string{"exclusive:state-sync.snapshot-interval"},
+			warnings: []string{"exclusive:halt-height"},
+		},
+		{
+			name: "removed grpc-web section",
+			config: `minimum-gas-prices = "0stake"
+[grpc]
+enable = false
+[grpc-web]
+enable = true
+`,
+			errors:   []string{},
+			warnings: []string{},
+		},
+		{
+			name: "telemetry",
+			config: `minimum-gas-prices = "0stake"
+[telemetry]
+enabled = false
+prometheus-retention-time = 60
+metrics-sink = "statsd"
+`,
+			errors:   []string{"requires:telemetry.statsd-addr"},
+			warnings: []string{"requires:telemetry.prometheus-retention-time"},
+		},
+		{
+			name: "streaming without keys",
+			config: `minimum-gas-prices = "0stake"
+[streaming.abci]
+plugin = "file"
+keys = []
+`,
+			errors:   []string{},
+			warnings: []string{"requires:streaming.abci.keys"},
+		},
+		{
+			name: "indexer without queue",
+			config: `minimum-gas-prices = "0stake"
+[streaming.indexer]
+driver = "postgres"
+keys = []
+start-height = 0
+`,
+			errors:   []string{"requires:streaming.indexer.driver", "requires:streaming.indexer.start-height"},
+			warnings: []string{"requires:streaming.indexer.keys"},
+		},
+		{
+			name: "unknown indexer driver",
+			config: `minimum-gas-prices = "0stake"
+[streaming.indexer]
+driver = "mysql"
+keys = ["bank"]
+start-height = 1
+[streaming.queue]
+enable = true
+`,
+			errors:   []string{"enum:streaming.indexer.driver"},
+			warnings: []string{},
+		},
+		{
+			name: "streaming queue",
+			config: `minimum-gas-prices = "0stake"
+[streaming.queue]
+enable = true
+retry-interval = "0s"
+db-backend = "sqlite"
+`,
+			errors:   []string{"enum:streaming.queue.db-backend", "range:streaming.queue.retry-interval"},
+			warnings: []string{},
+		},
+		{
+			name: "replay without streaming queue",
+			config: `minimum-gas-prices = "0stake"
+[streaming.queue]
+enable = false
+replay-height = 10
+`,
+			errors:   []string{},
+			warnings: []string{"requires:streaming.queue.replay-height"},
+		},
+		{
+			name: "tx decode",
+			config: `minimum-gas-prices = "0stake"
+[tx-decode]
+max-msgs = -1
+allowed-type-urls = ["/cosmos.bank.v1beta1.MsgSend"]
+denied-type-urls = ["/cosmos.bank.v1beta1.MsgSend"]
+`,
+			errors:   []string{"exclusive:tx-decode.allowed-type-urls", "range:tx-decode.max-msgs"},
+			warnings: []string{},
+		},
+		{
+			name: "port conflict",
+			config: `minimum-gas-prices = "0stake"
+[api]
+enable = true
+address = "tcp://0.0.0.0:9090"
+[grpc]
+enable = true
+address = "localhost:9090"
+`,
+			errors:   []string{"port-conflict:grpc.address"},
+			warnings: []string{},
+		},
+		{
+			name: "port conflict on disabled listener",
+			config: `minimum-gas-prices = "0stake"
+[api]
+enable = false
+address = "tcp://localhost:9090"
+[grpc]
+enable = true
+address = "localhost:9090"
+`,
+			errors:   []string{},
+			warnings: []string{},
+		},
+		{
+			name: "same port on distinct hosts",
+			config: `minimum-gas-prices = "0stake"
+[api]
+enable = true
+address = "tcp://10.0.0.1:9090"
+[grpc]
+enable = true
+address = "10.0.0.2:9090"
+`,
+			errors:   []string{},
+			warnings: []string{},
+		},
+		{
+			name: "log admin port conflict",
+			config: `minimum-gas-prices = "0stake"
+[api]
+enable = true
+address = "tcp://localhost:1317"
+log-admin-address = "localhost:1317"
+`,
+			errors:   []string{"port-conflict:api.log-admin-address"},
+			warnings: []string{},
+		},
+		{
+			name: "invalid log admin address",
+			config: `minimum-gas-prices = "0stake"
+[api]
+log-admin-address = "localhost"
+`,
+			errors:   []string{"address:api.log-admin-address"},
+			warnings: []string{},
+		},
+		{
+			name: "log admin disabled",
+			config: `minimum-gas-prices = "0stake"
+[api]
+log-admin-address = ""
+`,
+			errors:   []string{},
+			warnings: []string{},
+		},
+		{
+			name: "invalid listen address",
+			config: `minimum-gas-prices = "0stake"
+[grpc]
+enable = true
+address = "localhost"
+`,
+			errors:   []string{"address:grpc.address"},
+			warnings: []string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			report, err := confix.Validate("app.toml", []byte(tc.config))
+			assert.NilError(t, err)
+			assert.DeepEqual(t, issueKeys(report, confix.SeverityError), tc.errors)
+			assert.DeepEqual(t, issueKeys(report, confix.SeverityWarning), tc.warnings)
+		})
+	}
+}
+
+func TestValidateClient(t *testing.T) {
+	report, err := confix.Validate("client.toml", []byte(`chain-id = ""
+keyring-backend = "vault"
+output = "yaml"
+node = "localhost:26657"
+broadcast-mode = "block"
+`))
+	assert.NilError(t, err)
+	assert.DeepEqual(t, issueKeys(report, confix.SeverityError), []string{"enum:broadcast-mode", "required:chain-id", "enum:keyring-backend", "address:node", "enum:output"})
+	assert.Equal(t, report.Warnings(), 0)
+}
@@ -38,6 +38,11 @@ const (
 	EnvTimeFormatLogs           = "COSMOVISOR_TIMEFORMAT_LOGS"
 	EnvCustomPreupgrade         = "COSMOVISOR_CUSTOM_PREUPGRADE"
 	EnvDisableRecase            = "COSMOVISOR_DISABLE_RECASE"
+	EnvRollbackOnFailure        = "COSMOVISOR_ROLLBACK_ON_FAILURE"
+	EnvHealthCheckBlocks        = "COSMOVISOR_HEALTH_CHECK_BLOCKS"
+	EnvHealthCheckMaxRestarts   = "COSMOVISOR_HEALTH_CHECK_MAX_RESTARTS"
+	EnvSignaturePublicKeys      = "COSMOVISOR_SIGNATURE_PUBLIC_KEYS"
+	EnvControlSocket            = "COSMOVISOR_CONTROL_SOCKET"
 )
 
 const (
@@ -46,6 +51,8 @@ const (
 	upgradesDir = "upgrades"
 	currentLink = "current"
 
+	prefetchManifestFileName = "prefetch.json"
+
 	cfgFileName  = "config"
 	cfgExtension = "toml"
 )
@@ -68,6 +75,11 @@ type Config struct {
 	TimeFormatLogs           string        `toml:"cosmovisor_timeformat_logs" mapstructure:"cosmovisor_timeformat_logs" default:"kitchen"`
 	CustomPreUpgrade         string        `toml:"cosmovisor_custom_preupgrade" mapstructure:"cosmovisor_custom_preupgrade" default:""`
 	DisableRecase            bool          `toml:"cosmovisor_disable_recase" mapstructure:"cosmovisor_disable_recase" default:"false"`
+	RollbackOnFailure        bool          `toml:"cosmovisor_rollback_on_failure" mapstructure:"cosmovisor_rollback_on_failure" default:"false"`
+	HealthCheckBlocks        int           `toml:"cosmovisor_health_check_blocks" mapstructure:"cosmovisor_health_check_blocks" default:"5"`
+	HealthCheckMaxRestarts   int           `toml:"cosmovisor_health_check_max_restarts" mapstructure:"cosmovisor_health_check_max_restarts" default:"3"`
+	SignaturePublicKeys      []string      `toml:"cosmovisor_signature_public_keys,omitempty" mapstructure:"cosmovisor_signature_public_keys"`
+	ControlSocket            string        `toml:"cosmovisor_control_socket" mapstructure:"cosmovisor_control_socket" default:""`
 
 	// currently running upgrade
 	currentUpgrade upgradetypes.Plan
@@ -104,6 +116,16 @@ func (cfg *Config) BaseUpgradeDir() string {
 	return filepath.Join(cfg.Root(), upgradesDir)
 }
 
+// UpgradeBinSignature is the path to the detached signature of the binary for the named upgrade
+func (cfg *Config) UpgradeBinSignature(upgradeName string) string {
+	return cfg.UpgradeBin(upgradeName) + SignatureExtension
+}
+
+// PrefetchManifestPath is the path to the manifest of the upgrades to prefetch.
+func (cfg *Config) PrefetchManifestPath() string {
+	return filepath.Join(cfg.Root(), prefetchManifestFileName)
+}
+
 // UpgradeInfoFilePath is the expected upgrade-info filename created by `x/upgrade/keeper`.
 func (cfg *Config) UpgradeInfoFilePath() string {
 	return filepath.Join(cfg.Home, "data", upgradetypes.UpgradeInfoFilename)
@@ -210,6 +232,7 @@ func GetConfigFromEnv() (*Config, error) {
 		Name:             os.Getenv(EnvName),
 		DataBackupPath:   os.Getenv(EnvDataBackupPath),
 		CustomPreUpgrade: os.Getenv(EnvCustomPreupgrade),
+		ControlSocket:    os.Getenv(EnvControlSocket),
 	}
 
 	if cfg.DataBackupPath == "" {
@@ -241,6 +264,9 @@ func GetConfigFromEnv() (*Config, error) {
 	if cfg.DisableRecase, err = BooleanOption(EnvDisableRecase, false); err != nil {
 		errs = append(errs, err)
 	}
+	if cfg.RollbackOnFailure, err = BooleanOption(EnvRollbackOnFailure, false); err != nil {
+		errs = append(errs, err)
+	}
 
 	interval := os.Getenv(EnvInterval)
 	if interval != "" {
@@ -281,6 +307,17 @@ func GetConfigFromEnv() (*Config, error) {
 		errs = append(errs, fmt.Errorf("%s could not be parsed to int: %w", EnvPreupgradeMaxRetries, err))
 	}
 
+	if keys := os.Getenv(EnvSignaturePublicKeys); keys != "" {
+		cfg.SignaturePublicKeys = strings.Split(keys, ",")
+	}
+
+	if cfg.HealthCheckBlocks, err = IntOption(EnvHealthCheckBlocks, 5); err != nil {
+		errs = append(errs, err)
+	}
+	if cfg.HealthCheckMaxRestarts, err = IntOption(EnvHealthCheckMaxRestarts, 3); err != nil {
+		errs = append(errs, err)
+	}
+
 	errs = append(errs, cfg.validate()...)
 	if len(errs) > 0 {
 		return nil, errors.Join(errs...)
@@ -342,6 +379,27 @@ func (cfg *Config) validate() []error {
 		}
 	}
 
+	if cfg.RollbackOnFailure {
+		if cfg.HealthCheckBlocks <= 0 {
+			errs = append(errs, fmt.Errorf("%s must be greater than 0", EnvHealthCheckBlocks))
+		}
+		if cfg.HealthCheckMaxRestarts < 0 {
+			errs = append(errs, fmt.Errorf("%s must not be negative", EnvHealthCheckMaxRestarts))
+		}
+		// the data directory is restored from the backup taken before the upgrade
+		if cfg.UnsafeSkipBackup {
+			errs = append(errs, fmt.Errorf("%s requires a data backup, %s must be false", EnvRollbackOnFailure, EnvSkipBackup))
+		}
+	}
+
+	if _, err := ParsePublicKeys(cfg.SignaturePublicKeys); err != nil {
+		errs = append(errs, fmt.Errorf("%s: %w", EnvSignaturePublicKeys, err))
+	}
+
+	if cfg.ControlSocket != "" && !filepath.IsAbs(cfg.ControlSocket) {
+		errs = append(errs, fmt.Errorf("%s must be an absolute path", EnvControlSocket))
+	}
+
 	// check the DataBackupPath
 	if cfg.UnsafeSkipBackup {
 		return errs
@@ -452,6 +510,21 @@ func BooleanOption(name string, defaultVal bool) (bool, error) {
 	return false, fmt.Errorf("env variable %q must have a boolean value (\"true\" or \"false\"), got %q", name, p)
 }
 
+// IntOption checks and validate env option
+func IntOption(name string, defaultVal int) (int, error) {
+	p := os.Getenv(name)
+	if p == "" {
+		return defaultVal, nil
+	}
+
+	val, err := strconv.Atoi(p)
+	if err != nil {
+		return 0, fmt.Errorf("env variable %q must have an integer value, got %q", name, p)
+	}
+
+	return val, nil
+}
+
 // TimeFormatOptionFromEnv checks and validates the time format option
 func TimeFormatOptionFromEnv(env, defaultVal string) (string, error) {
 	val, set := os.LookupEnv(env)
@@ -545,6 +618,11 @@ func (cfg Config) DetailString() string {
 		{EnvTimeFormatLogs, cfg.TimeFormatLogs},
 		{EnvCustomPreupgrade, cfg.CustomPreUpgrade},
 		{EnvDisableRecase, fmt.Sprintf("%t", cfg.DisableRecase)},
+		{EnvRollbackOnFailure, fmt.Sprintf("%t", cfg.RollbackOnFailure)},
+		{EnvHealthCheckBlocks, fmt.Sprintf("%d", cfg.HealthCheckBlocks)},
+		{EnvHealthCheckMaxRestarts, fmt.Sprintf("%d", cfg.HealthCheckMaxRestarts)},
+		{EnvSignaturePublicKeys, strings.Join(cfg.SignaturePublicKeys, ",")},
+		{EnvControlSocket, cfg.ControlSocket},
 	}
 
 	derivedEntries := []struct{ name, value string }{
@@ -261,6 +261,22 @@ func (s *argsTestSuite) TestValidate() {
 			cfg:   Config{Home: absPath, Name: "bind", DataBackupPath: relPath},
 			valid: false,
 		},
+		"happy with rollback on failure": {
+			cfg:   Config{Home: absPath, Name: "bind", DataBackupPath: absPath, RollbackOnFailure: true, HealthCheckBlocks: 5, HealthCheckMaxRestarts: 3},
+			valid: true,
+		},
+		"rollback on failure without health check blocks": {
+			cfg:   Config{Home: absPath, Name: "bind", DataBackupPath: absPath, RollbackOnFailure: true, HealthCheckMaxRestarts: 3},
+			valid: false,
+		},
+		"rollback on failure with negative max restarts": {
+			cfg:   Config{Home: absPath, Name: "bind", DataBackupPath: absPath, RollbackOnFailure: true, HealthCheckBlocks: 5, HealthCheckMaxRestarts: -1},
+			valid: false,
+		},
+		"rollback on failure with skip data backup": {
+			cfg:   Config{Home: absPath, Name: "bind", UnsafeSkipBackup: true, RollbackOnFailure: true, HealthCheckBlocks: 5},
+			valid: false,
+		},
 	}
 
 	for _, tc := range cases {
@@ -477,6 +493,8 @@ var newConfig = func(
 		CustomPreUpgrade:         customPreUpgrade,
 		DisableRecase:            disableRecase,
 		ShutdownGrace:            time.Duration(shutdownGrace),
+		HealthCheckBlocks:        5,
+		HealthCheckMaxRestarts:   3,
 	}
 }
 
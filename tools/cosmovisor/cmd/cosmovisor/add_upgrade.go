@@ -24,6 +24,7 @@ func NewAddUpgradeCmd() *cobra.Command {
 
 	addUpgrade.Flags().Bool(cosmovisor.FlagForce, false, "overwrite existing upgrade binary / upgrade-info.json file")
 	addUpgrade.Flags().Int64(cosmovisor.FlagUpgradeHeight, 0, "define a height at which to upgrade the binary automatically (without governance proposal)")
+	addUpgrade.Flags().String(cosmovisor.FlagSignature, "", "path to the detached signature of the executable, verified when signature public keys are configured")
 
 	return addUpgrade
 }
@@ -77,6 +78,23 @@ func AddUpgrade(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	if signaturePath, err := cmd.Flags().GetString(cosmovisor.FlagSignature); err != nil {
+		return fmt.Errorf("failed to get signature flag: %w", err)
+	} else if signaturePath != "" {
+		signatureData, err := os.ReadFile(signaturePath)
+		if err != nil {
+			return fmt.Errorf("failed to read signature: %w", err)
+		}
+
+		if err := saveOrAbort(cfg.UpgradeBinSignature(upgradeName), signatureData, force); err != nil {
+			return err
+		}
+	}
+
+	if err := cfg.VerifyUpgradeBinary(upgradeName); err != nil {
+		return fmt.Errorf("failed to verify the upgrade binary: %w", err)
+	}
+
 	logger.Info(fmt.Sprintf("Using %s for %s upgrade", executablePath, upgradeName))
 	logger.Info(fmt.Sprintf("Upgrade binary located at %s", cfg.UpgradeBin(upgradeName)))
 
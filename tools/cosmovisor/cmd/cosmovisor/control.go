@@ -0,0 +1,119 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"cosmossdk.io/tools/cosmovisor"
+)
+
+func NewControlCmd() *cobra.Command {
+	controlCmd := &cobra.Command{
+		Use:   "control",
+		Short: "Query and control a running cosmovisor through its control API.",
+		Long: `Query and control a running cosmovisor through its control API.
+The control API is served on the Unix socket at COSMOVISOR_CONTROL_SOCKET.`,
+	}
+
+	controlCmd.AddCommand(
+		&cobra.Command{
+			Use:          "status",
+			Short:        "Display the status of cosmovisor and of the APP process.",
+			SilenceUsage: true,
+			Args:         cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, _ []string) error {
+				return runControl(cmd, func(client cosmovisor.ControlClient) (cosmovisor.ControlStatus, error) {
+					return client.Status()
+				})
+			},
+		},
+		&cobra.Command{
+			Use:          "pause-download",
+			Short:        "Pause the download of upgrade binaries.",
+			SilenceUsage: true,
+			Args:         cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, _ []string) error {
+				return runControl(cmd, func(client cosmovisor.ControlClient) (cosmovisor.ControlStatus, error) {
+					return client.SetAutoDownloadPaused(true)
+				})
+			},
+		},
+		&cobra.Command{
+			Use:          "resume-download",
+			Short:        "Resume the download of upgrade binaries.",
+			SilenceUsage: true,
+			Args:         cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, _ []string) error {
+				return runControl(cmd, func(client cosmovisor.ControlClient) (cosmovisor.ControlStatus, error) {
+					return client.SetAutoDownloadPaused(false)
+				})
+			},
+		},
+		newScheduleUpgradeCmd(),
+	)
+
+	return controlCmd
+}
+
+func newScheduleUpgradeCmd() *cobra.Command {
+	scheduleCmd := &cobra.Command{
+		Use:          "schedule-upgrade [upgrade-name] [height]",
+		Short:        "Switch to an upgrade binary at a given height, without governance proposal.",
+		Long:         "Switch to an upgrade binary at a given height, without governance proposal. The upgrade binary must be present, e.g. added with add-upgrade.",
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			height, err := strconv.ParseInt(args[1], 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid height: %w", err)
+			}
+
+			force, err := cmd.Flags().GetBool(cosmovisor.FlagForce)
+			if err != nil {
+				return fmt.Errorf("failed to get force flag: %w", err)
+			}
+
+			return runControl(cmd, func(client cosmovisor.ControlClient) (cosmovisor.ControlStatus, error) {
+				return client.ScheduleUpgrade(cosmovisor.ScheduleUpgradeRequest{Name: args[0], Height: height, Force: force})
+			})
+		},
+	}
+
+	scheduleCmd.Flags().Bool(cosmovisor.FlagForce, false, "overwrite an upgrade plan which is not applied yet")
+
+	return scheduleCmd
+}
+
+// runControl calls the control API of the running cosmovisor and prints its status.
+func runControl(cmd *cobra.Command, call func(cosmovisor.ControlClient) (cosmovisor.ControlStatus, error)) error {
+	configPath, err := cmd.Flags().GetString(cosmovisor.FlagCosmovisorConfig)
+	if err != nil {
+		return fmt.Errorf("failed to get config flag: %w", err)
+	}
+
+	cfg, err := cosmovisor.GetConfigFromFile(configPath)
+	if err != nil {
+		return err
+	}
+
+	if cfg.ControlSocket == "" {
+		return errors.New("the control API is disabled, set COSMOVISOR_CONTROL_SOCKET")
+	}
+
+	status, err := call(cosmovisor.NewControlClient(cfg.ControlSocket))
+	if err != nil {
+		return err
+	}
+
+	bz, err := json.MarshalIndent(status, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	cmd.Println(string(bz))
+	return nil
+}
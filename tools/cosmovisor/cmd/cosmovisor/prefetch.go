@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"cosmossdk.io/tools/cosmovisor"
+)
+
+func NewPrefetchCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "prefetch [path to manifest]",
+		Short: "Download the binaries of future upgrades ahead of time.",
+		Long: `Download the binaries of the upgrades listed in a prefetch manifest, and verify their signatures
+when signature public keys are configured (COSMOVISOR_SIGNATURE_PUBLIC_KEYS).
+The manifest defaults to <DAEMON_HOME>/cosmovisor/prefetch.json, which is also used to look up
+the signatures of binaries downloaded at upgrade time.`,
+		SilenceUsage: true,
+		Args:         cobra.MaximumNArgs(1),
+		RunE:         Prefetch,
+	}
+}
+
+// Prefetch downloads the upgrade binaries listed in the prefetch manifest.
+func Prefetch(cmd *cobra.Command, args []string) error {
+	configPath, err := cmd.Flags().GetString(cosmovisor.FlagCosmovisorConfig)
+	if err != nil {
+		return fmt.Errorf("failed to get config flag: %w", err)
+	}
+
+	cfg, err := cosmovisor.GetConfigFromFile(configPath)
+	if err != nil {
+		return err
+	}
+
+	manifestPath := cfg.PrefetchManifestPath()
+	if len(args) > 0 {
+		manifestPath = args[0]
+	}
+
+	manifest, err := cosmovisor.LoadPrefetchManifest(manifestPath)
+	if err != nil {
+		return fmt.Errorf("failed to load prefetch manifest: %w", err)
+	}
+
+	return cosmovisor.Prefetch(cfg.Logger(os.Stdout), cfg, manifest)
+}
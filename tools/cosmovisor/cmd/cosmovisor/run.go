@@ -45,6 +45,18 @@ func run(cfgPath string, args []string, options ...RunOption) error {
 		return err
 	}
 
+	if cfg.ControlSocket != "" {
+		stopControlServer, err := launcher.StartControlServer()
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err := stopControlServer(); err != nil {
+				logger.Error("failed to stop the control API", "error", err)
+			}
+		}()
+	}
+
 	doUpgrade, err := launcher.Run(args, runCfg.StdOut, runCfg.StdErr)
 	// if RestartAfterUpgrade, we launch after a successful upgrade (given that condition launcher.Run returns nil)
 	for cfg.RestartAfterUpgrade && err == nil && doUpgrade {
@@ -0,0 +1,494 @@
+package cosmovisor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"cosmossdk.io/x/upgrade/plan"
+	upgradetypes "cosmossdk.io/x/upgrade/types"
+)
+
+const autoDownloadPausedFileName = "auto-download-paused"
+
+// control API routes
+const (
+	ControlRouteStatus             = "/status"
+	ControlRoutePauseAutoDownload  = "/auto-download/pause"
+	ControlRouteResumeAutoDownload = "/auto-download/resume"
+	ControlRouteUpgrades           = "/upgrades"
+)
+
+// app process states
+const (
+	ProcessStateStopped   = "stopped"
+	ProcessStateRunning   = "running"
+	ProcessStateUpgrading = "upgrading"
+	ProcessStateExited    = "exited"
+)
+
+// ProcessStatus is the state of the app process run by cosmovisor.
+type ProcessStatus struct {
+	State     string    `json:"state"`
+	PID       int       `json:"pid,omitempty"`
+	Bin       string    `json:"bin,omitempty"`
+	StartedAt time.Time `json:"started_at"`
+	ExitedAt  time.Time `json:"exited_at"`
+	LastError string    `json:"last_error,omitempty"`
+}
+
+// processState keeps the state of the app process, shared with the control API.
+type processState struct {
+	mu     sync.Mutex
+	status ProcessStatus
+}
+
+func (s *processState) get() ProcessStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.status
+}
+
+func (s *processState) started(bin string, pid int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.status = ProcessStatus{State: ProcessStateRunning, PID: pid, Bin: bin, StartedAt: time.Now(), LastError: s.status.LastError}
+}
+
+func (s *processState) exited(needsUpdate bool, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.status.State = ProcessStateExited
+	if needsUpdate {
+		s.status.State = ProcessStateUpgrading
+	}
+	s.status.ExitedAt = time.Now()
+	if err != nil {
+		s.status.LastError = err.Error()
+	}
+}
+
+// ControlStatus is the status of cosmovisor returned by the control API.
+type ControlStatus struct {
+	DaemonName string `json:"daemon_name"`
+	// CurrentBin is the binary currently selected, and CurrentUpgrade the name of its upgrade (empty for genesis).
+	CurrentBin     string `json:"current_bin"`
+	CurrentUpgrade string `json:"current_upgrade,omitempty"`
+	// UpgradePlan is the content of the upgrade-info.json file watched by cosmovisor,
+	// and PendingUpgrade is true when this plan is not applied yet.
+	UpgradePlan    *upgradetypes.Plan `json:"upgrade_plan,omitempty"`
+	PendingUpgrade bool               `json:"pending_upgrade"`
+	// AvailableUpgrades are the upgrades whose binary is present in the upgrades directory.
+	AvailableUpgrades []string `json:"available_upgrades"`
+	// PrefetchUpgrades are the upgrades listed in the prefetch manifest.
+	PrefetchUpgrades   []PrefetchUpgrade `json:"prefetch_upgrades,omitempty"`
+	LastBackup         string            `json:"last_backup,omitempty"`
+	AutoDownload       bool              `json:"auto_download"`
+	AutoDownloadPaused bool              `json:"auto_download_paused"`
+	Process            ProcessStatus     `json:"process"`
+}
+
+// ScheduleUpgradeRequest is the request of the control API to switch to an upgrade binary at a given height.
+type ScheduleUpgradeRequest struct {
+	Name   string `json:"name"`
+	Height int64  `json:"height"`
+	// Force overwrites an upgrade plan which is not applied yet.
+	Force bool `json:"force,omitempty"`
+}
+
+// AutoDownloadPausedFilePath is the path of the file marking the download of upgrade binaries as paused.
+func (cfg *Config) AutoDownloadPausedFilePath() string {
+	return filepath.Join(cfg.Root(), autoDownloadPausedFileName)
+}
+
+// AutoDownloadPaused returns true if the download of upgrade binaries is paused.
+func (cfg *Config) AutoDownloadPaused() bool {
+	_, err := os.Stat(cfg.AutoDownloadPausedFilePath())
+	return err == nil
+}
+
+// SetAutoDownloadPaused pauses or resumes the download of upgrade binaries.
+// The pause is persisted, so it survives a restart of cosmovisor.
+func (cfg *Config) SetAutoDownloadPaused(paused bool) error {
+	if !paused {
+		if err := os.Remove(cfg.AutoDownloadPausedFilePath()); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+
+		return nil
+	}
+
+	return os.WriteFile(cfg.AutoDownloadPausedFilePath(), []byte(time.Now().UTC().Format(time.RFC3339)+"\n"), 0o600)
+}
+
+// ScheduleUpgrade writes an upgrade plan switching to the binary of the named upgrade at the given height,
+// without a governance proposal. The binary must already be present in the upgrade directory.
+// An upgrade plan which is not applied yet is only overwritten when force is true.
+func (cfg *Config) ScheduleUpgrade(name string, height int64, force bool) (upgradetypes.Plan, error) {
+	if !cfg.DisableRecase {
+		name = strings.ToLower(name)
+	}
+
+	p := upgradetypes.Plan{Name: name, Height: height}
+	if err := p.ValidateBasic(); err != nil {
+		return p, err
+	}
+
+	if err := plan.EnsureBinary(cfg.UpgradeBin(name)); err != nil {
+		return p, fmt.Errorf("upgrade binary is not available: %w", err)
+	}
+
+	if err := cfg.VerifyUpgradeBinary(name); err != nil {
+		return p, fmt.Errorf("failed to verify the upgrade binary: %w", err)
+	}
+
+	if current, err := parseUpgradeInfoFile(cfg.UpgradeInfoFilePath(), cfg.DisableRecase); err == nil && !force {
+		if applied, _ := cfg.appliedUpgrade(); !strings.EqualFold(current.Name, applied) {
+			return p, fmt.Errorf("upgrade %s is already planned at height %d", current.Name, current.Height)
+		}
+	}
+
+	bz, err := json.Marshal(p)
+	if err != nil {
+		return p, fmt.Errorf("failed to marshal upgrade plan: %w", err)
+	}
+
+	// the upgrade-info.json file is watched, write it atomically
+	tmpFile := cfg.UpgradeInfoFilePath() + ".tmp"
+	if err := os.WriteFile(tmpFile, bz, 0o600); err != nil {
+		return p, err
+	}
+
+	return p, os.Rename(tmpFile, cfg.UpgradeInfoFilePath())
+}
+
+// appliedUpgrade returns the name of the upgrade the current link points to, empty for genesis.
+// Unlike UpgradeInfo, it does not cache the upgrade, so it is safe to call from the control API.
+func (cfg *Config) appliedUpgrade() (string, error) {
+	dest, err := os.Readlink(filepath.Join(cfg.Root(), currentLink))
+	if err != nil {
+		return "", err
+	}
+
+	if filepath.Base(dest) == genesisDir {
+		return "", nil
+	}
+
+	bz, err := os.ReadFile(filepath.Join(dest, upgradetypes.UpgradeInfoFilename))
+	if err != nil {
+		return "", err
+	}
+
+	var u upgradetypes.Plan
+	if err := json.Unmarshal(bz, &u); err != nil {
+		return "", err
+	}
+
+	return u.Name, nil
+}
+
+// lastBackup returns the most recent backup of the data directory.
+func (cfg *Config) lastBackup() string {
+	if cfg.DataBackupPath == "" {
+		return ""
+	}
+
+	matches, _ := filepath.Glob(filepath.Join(cfg.DataBackupPath, "data-backup-*"))
+	var (
+		last    string
+		lastMod time.Time
+	)
+	for _, match := range matches {
+		info, err := os.Stat(match)
+		if err != nil || !info.IsDir() {
+			continue
+		}
+
+		if info.ModTime().After(lastMod) {
+			last, lastMod = match, info.ModTime()
+		}
+	}
+
+	return last
+}
+
+// Status returns the status of cosmovisor and of the app process.
+func (l Launcher) Status() ControlStatus {
+	status := ControlStatus{
+		DaemonName:         l.cfg.Name,
+		CurrentBin:         l.cfg.GenesisBin(),
+		AvailableUpgrades:  []string{},
+		LastBackup:         l.cfg.lastBackup(),
+		AutoDownload:       l.cfg.AllowDownloadBinaries,
+		AutoDownloadPaused: l.cfg.AutoDownloadPaused(),
+		Process:            l.state.get(),
+	}
+
+	if dest, err := os.Readlink(filepath.Join(l.cfg.Root(), currentLink)); err == nil {
+		status.CurrentBin = filepath.Join(dest, "bin", l.cfg.Name)
+	}
+	status.CurrentUpgrade, _ = l.cfg.appliedUpgrade()
+
+	if upgradePlan, err := parseUpgradeInfoFile(l.cfg.UpgradeInfoFilePath(), l.cfg.DisableRecase); err == nil {
+		status.UpgradePlan = &upgradePlan
+		status.PendingUpgrade = !strings.EqualFold(upgradePlan.Name, status.CurrentUpgrade)
+	}
+
+	if entries, err := os.ReadDir(l.cfg.BaseUpgradeDir()); err == nil {
+		for _, entry := range entries {
+			// skip download staging directories
+			if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+				continue
+			}
+
+			if err := plan.EnsureBinary(filepath.Join(l.cfg.BaseUpgradeDir(), entry.Name(), "bin", l.cfg.Name)); err == nil {
+				status.AvailableUpgrades = append(status.AvailableUpgrades, entry.Name())
+			}
+		}
+	}
+
+	if manifest, err := LoadPrefetchManifest(l.cfg.PrefetchManifestPath()); err == nil {
+		status.PrefetchUpgrades = manifest.Upgrades
+	}
+
+	return status
+}
+
+// StartControlServer serves the control API over HTTP on the Unix socket at COSMOVISOR_CONTROL_SOCKET.
+// The returned function stops the server.
+func (l Launcher) StartControlServer() (func() error, error) {
+	socket := l.cfg.ControlSocket
+	// remove the socket left by a previous run
+	if err := removeControlSocket(socket); err != nil {
+		return nil, err
+	}
+
+	listener, err := listenControlSocket(socket)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on the control socket: %w", err)
+	}
+
+	server := &http.Server{Handler: l.controlHandler(), ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			l.logger.Error("control API stopped", "error", err)
+		}
+	}()
+
+	l.logger.Info("control API listening", "socket", socket)
+
+	return func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		return errors.Join(server.Shutdown(ctx), removeControlSocket(socket))
+	}, nil
+}
+
+// listenControlSocket listens on the Unix socket at the given path, which is reserved to the
+// user running cosmovisor. The socket is created in a private directory, where nobody else can
+// connect to it, restricted, and then moved to its path.
+func listenControlSocket(socket string) (*net.UnixListener, error) {
+	dir, err := os.MkdirTemp(filepath.Dir(socket), ".control-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, filepath.Base(socket))
+	listener, err := net.ListenUnix("unix", &net.UnixAddr{Name: path, Net: "unix"})
+	if err != nil {
+		return nil, err
+	}
+	// the socket is moved, it is removed by the server stop function instead
+	listener.SetUnlinkOnClose(false)
+
+	if err := os.Chmod(path, 0o600); err != nil {
+		_ = listener.Close()
+		return nil, err
+	}
+	if err := os.Rename(path, socket); err != nil {
+		_ = listener.Close()
+		return nil, err
+	}
+	return listener, nil
+}
+
+// removeControlSocket removes the control socket at the given path, if any. Other files are
+// never removed.
+func removeControlSocket(socket string) error {
+	info, err := os.Lstat(socket)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if info.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("the control socket %s exists and is not a socket", socket)
+	}
+	if err := os.Remove(socket); err != nil {
+		return fmt.Errorf("failed to remove the control socket: %w", err)
+	}
+	return nil
+}
+
+func (l Launcher) controlHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(ControlRouteStatus, func(w http.ResponseWriter, r *http.Request) {
+		if !allowMethod(w, r, http.MethodGet) {
+			return
+		}
+
+		writeControlResponse(w, http.StatusOK, l.Status())
+	})
+
+	setAutoDownloadPaused := func(paused bool) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if !allowMethod(w, r, http.MethodPost) {
+				return
+			}
+
+			if err := l.cfg.SetAutoDownloadPaused(paused); err != nil {
+				writeControlError(w, http.StatusInternalServerError, err)
+				return
+			}
+
+			l.logger.Info("auto-download updated by the control API", "paused", paused)
+			writeControlResponse(w, http.StatusOK, l.Status())
+		}
+	}
+	mux.HandleFunc(ControlRoutePauseAutoDownload, setAutoDownloadPaused(true))
+	mux.HandleFunc(ControlRouteResumeAutoDownload, setAutoDownloadPaused(false))
+
+	mux.HandleFunc(ControlRouteUpgrades, func(w http.ResponseWriter, r *http.Request) {
+		if !allowMethod(w, r, http.MethodPost) {
+			return
+		}
+
+		var req ScheduleUpgradeRequest
+		if err := json.NewDecoder(io.LimitReader(r.Body, 1<<20)).Decode(&req); err != nil {
+			writeControlError(w, http.StatusBadRequest, fmt.Errorf("invalid request: %w", err))
+			return
+		}
+
+		p, err := l.cfg.ScheduleUpgrade(req.Name, req.Height, req.Force)
+		if err != nil {
+			writeControlError(w, http.StatusConflict, err)
+			return
+		}
+
+		l.logger.Info("upgrade scheduled by the control API", "upgrade", p.Name, "height", p.Height)
+		writeControlResponse(w, http.StatusOK, l.Status())
+	})
+
+	return mux
+}
+
+func allowMethod(w http.ResponseWriter, r *http.Request, method string) bool {
+	if r.Method == method {
+		return true
+	}
+
+	w.Header().Set("Allow", method)
+	writeControlError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+	return false
+}
+
+func writeControlResponse(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeControlError(w http.ResponseWriter, code int, err error) {
+	writeControlResponse(w, code, map[string]string{"error": err.Error()})
+}
+
+// ControlClient is a client of the control API of a running cosmovisor.
+type ControlClient struct {
+	client *http.Client
+}
+
+// NewControlClient returns a client of the control API served on the given Unix socket.
+func NewControlClient(socket string) ControlClient {
+	return ControlClient{client: &http.Client{
+		Timeout: 30 * time.Second,
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, "unix", socket)
+			},
+		},
+	}}
+}
+
+// Status returns the status of cosmovisor.
+func (c ControlClient) Status() (ControlStatus, error) {
+	return c.do(http.MethodGet, ControlRouteStatus, nil)
+}
+
+// SetAutoDownloadPaused pauses or resumes the download of upgrade binaries.
+func (c ControlClient) SetAutoDownloadPaused(paused bool) (ControlStatus, error) {
+	if paused {
+		return c.do(http.MethodPost, ControlRoutePauseAutoDownload, nil)
+	}
+
+	return c.do(http.MethodPost, ControlRouteResumeAutoDownload, nil)
+}
+
+// ScheduleUpgrade schedules a switch to the binary of an upgrade at a given height.
+func (c ControlClient) ScheduleUpgrade(req ScheduleUpgradeRequest) (ControlStatus, error) {
+	return c.do(http.MethodPost, ControlRouteUpgrades, req)
+}
+
+func (c ControlClient) do(method, route string, body any) (ControlStatus, error) {
+	var status ControlStatus
+
+	var reqBody io.Reader
+	if body != nil {
+		bz, err := json.Marshal(body)
+		if err != nil {
+			return status, err
+		}
+		reqBody = bytes.NewReader(bz)
+	}
+
+	// the host is ignored, requests are sent to the socket
+	req, err := http.NewRequest(method, "http://cosmovisor"+route, reqBody)
+	if err != nil {
+		return status, err
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return status, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var errResp struct {
+			Error string `json:"error"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&errResp); err != nil || errResp.Error == "" {
+			return status, fmt.Errorf("control API returned %s", resp.Status)
+		}
+
+		return status, errors.New(errResp.Error)
+	}
+
+	return status, json.NewDecoder(resp.Body).Decode(&status)
+}
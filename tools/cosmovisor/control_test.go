@@ -0,0 +1,172 @@
+//go:build linux
+// +build linux
+
+package cosmovisor_test
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"cosmossdk.io/log"
+	"cosmossdk.io/tools/cosmovisor"
+	upgradetypes "cosmossdk.io/x/upgrade/types"
+)
+
+type controlTestSuite struct {
+	suite.Suite
+}
+
+func TestControlTestSuite(t *testing.T) {
+	suite.Run(t, new(controlTestSuite))
+}
+
+// startControlServer starts the control API of a launcher on the validate testdata.
+func (s *controlTestSuite) startControlServer() (*cosmovisor.Config, cosmovisor.Launcher, cosmovisor.ControlClient) {
+	home := copyTestData(s.T(), "validate")
+	cfg := &cosmovisor.Config{
+		Home:                  home,
+		Name:                  "dummyd",
+		PollInterval:          20,
+		AllowDownloadBinaries: true,
+		DataBackupPath:        home,
+		ControlSocket:         filepath.Join(s.T().TempDir(), "control.sock"),
+	}
+
+	launcher, err := cosmovisor.NewLauncher(log.NewTestLogger(s.T()), cfg)
+	s.Require().NoError(err)
+
+	stop, err := launcher.StartControlServer()
+	s.Require().NoError(err)
+	s.T().Cleanup(func() { s.Require().NoError(stop()) })
+
+	info, err := os.Stat(cfg.ControlSocket)
+	s.Require().NoError(err)
+	s.Require().Equal(os.FileMode(0o600), info.Mode().Perm())
+
+	return cfg, launcher, cosmovisor.NewControlClient(cfg.ControlSocket)
+}
+
+func (s *controlTestSuite) TestControlSocket() {
+	socket := filepath.Join(s.T().TempDir(), "control.sock")
+	cfg := &cosmovisor.Config{Home: copyTestData(s.T(), "validate"), Name: "dummyd", PollInterval: 20, ControlSocket: socket}
+	launcher, err := cosmovisor.NewLauncher(log.NewTestLogger(s.T()), cfg)
+	s.Require().NoError(err)
+
+	// the socket left by a previous run is replaced
+	listener, err := net.Listen("unix", socket)
+	s.Require().NoError(err)
+	listener.(*net.UnixListener).SetUnlinkOnClose(false)
+	s.Require().NoError(listener.Close())
+
+	stop, err := launcher.StartControlServer()
+	s.Require().NoError(err)
+	info, err := os.Stat(socket)
+	s.Require().NoError(err)
+	s.Require().Equal(os.FileMode(0o600), info.Mode().Perm())
+	_, err = cosmovisor.NewControlClient(socket).Status()
+	s.Require().NoError(err)
+
+	s.Require().NoError(stop())
+	s.Require().NoFileExists(socket)
+	// the socket is created in a private directory, removed once the socket is moved
+	entries, err := os.ReadDir(filepath.Dir(socket))
+	s.Require().NoError(err)
+	s.Require().Empty(entries)
+
+	// other files are never removed
+	s.Require().NoError(os.WriteFile(socket, []byte("data"), 0o600))
+	_, err = launcher.StartControlServer()
+	s.Require().ErrorContains(err, "exists and is not a socket")
+	s.Require().FileExists(socket)
+}
+
+func (s *controlTestSuite) TestStatus() {
+	cfg, _, client := s.startControlServer()
+
+	status, err := client.Status()
+	s.Require().NoError(err)
+	s.Require().Equal("dummyd", status.DaemonName)
+	s.Require().Equal(cfg.GenesisBin(), status.CurrentBin)
+	s.Require().Empty(status.CurrentUpgrade)
+	s.Require().Nil(status.UpgradePlan)
+	s.Require().False(status.PendingUpgrade)
+	// nobin has no binary
+	s.Require().Equal([]string{"chain2", "chain3"}, status.AvailableUpgrades)
+	s.Require().Empty(status.LastBackup)
+	s.Require().True(status.AutoDownload)
+	s.Require().False(status.AutoDownloadPaused)
+	s.Require().Equal(cosmovisor.ProcessStateStopped, status.Process.State)
+
+	// the last backup is the most recent one
+	s.Require().NoError(os.Mkdir(filepath.Join(cfg.DataBackupPath, "data-backup-2024-1-1"), 0o755))
+	status, err = client.Status()
+	s.Require().NoError(err)
+	s.Require().Equal(filepath.Join(cfg.DataBackupPath, "data-backup-2024-1-1"), status.LastBackup)
+
+	// the current upgrade follows the current link
+	s.Require().NoError(cfg.SetCurrentUpgrade(upgradetypes.Plan{Name: "chain2", Height: 49}))
+	status, err = client.Status()
+	s.Require().NoError(err)
+	s.Require().Equal(cfg.UpgradeBin("chain2"), status.CurrentBin)
+	s.Require().Equal("chain2", status.CurrentUpgrade)
+}
+
+func (s *controlTestSuite) TestPauseAutoDownload() {
+	cfg, _, client := s.startControlServer()
+
+	status, err := client.SetAutoDownloadPaused(true)
+	s.Require().NoError(err)
+	s.Require().True(status.AutoDownloadPaused)
+	s.Require().True(cfg.AutoDownloadPaused())
+
+	// the binary of an unknown upgrade is not downloaded
+	logger := log.NewTestLogger(s.T())
+	err = cosmovisor.UpgradeBinary(logger, cfg, upgradetypes.Plan{Name: "amazonas", Info: `{"binaries":{"any":"https://example.com/autod"}}`})
+	s.Require().ErrorContains(err, "downloading paused")
+
+	// present binaries are still switched to
+	s.Require().NoError(cosmovisor.UpgradeBinary(logger, cfg, upgradetypes.Plan{Name: "chain2"}))
+
+	status, err = client.SetAutoDownloadPaused(false)
+	s.Require().NoError(err)
+	s.Require().False(status.AutoDownloadPaused)
+	s.Require().False(cfg.AutoDownloadPaused())
+}
+
+func (s *controlTestSuite) TestScheduleUpgrade() {
+	cfg, _, client := s.startControlServer()
+
+	status, err := client.ScheduleUpgrade(cosmovisor.ScheduleUpgradeRequest{Name: "Chain2", Height: 100})
+	s.Require().NoError(err)
+	s.Require().Equal(&upgradetypes.Plan{Name: "chain2", Height: 100}, status.UpgradePlan)
+	s.Require().True(status.PendingUpgrade)
+
+	bz, err := os.ReadFile(cfg.UpgradeInfoFilePath())
+	s.Require().NoError(err)
+	s.Require().JSONEq(`{"name":"chain2","time":"0001-01-01T00:00:00Z","height":100}`, string(bz))
+
+	// a pending plan is not overwritten without force
+	_, err = client.ScheduleUpgrade(cosmovisor.ScheduleUpgradeRequest{Name: "chain3", Height: 200})
+	s.Require().ErrorContains(err, "upgrade chain2 is already planned at height 100")
+
+	status, err = client.ScheduleUpgrade(cosmovisor.ScheduleUpgradeRequest{Name: "chain3", Height: 200, Force: true})
+	s.Require().NoError(err)
+	s.Require().Equal(&upgradetypes.Plan{Name: "chain3", Height: 200}, status.UpgradePlan)
+
+	// an applied plan is overwritten
+	s.Require().NoError(cfg.SetCurrentUpgrade(upgradetypes.Plan{Name: "chain3", Height: 200}))
+	status, err = client.ScheduleUpgrade(cosmovisor.ScheduleUpgradeRequest{Name: "chain2", Height: 300})
+	s.Require().NoError(err)
+	s.Require().True(status.PendingUpgrade)
+
+	// the upgrade binary must be present
+	_, err = client.ScheduleUpgrade(cosmovisor.ScheduleUpgradeRequest{Name: "nobin", Height: 400, Force: true})
+	s.Require().ErrorContains(err, "upgrade binary is not available")
+
+	_, err = client.ScheduleUpgrade(cosmovisor.ScheduleUpgradeRequest{Name: "chain3", Height: 0, Force: true})
+	s.Require().ErrorContains(err, "height must be greater than 0")
+}
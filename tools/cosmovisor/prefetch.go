@@ -0,0 +1,222 @@
+package cosmovisor
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"cosmossdk.io/log"
+	"cosmossdk.io/x/upgrade/plan"
+)
+
+// PrefetchManifest lists the upgrades whose binaries are downloaded ahead of time.
+type PrefetchManifest struct {
+	Upgrades []PrefetchUpgrade `json:"upgrades"`
+}
+
+// PrefetchUpgrade describes where to download the binary of an upgrade and its signature.
+type PrefetchUpgrade struct {
+	// Name of the upgrade, as in the upgrade plan.
+	Name string `json:"name"`
+	// Height of the upgrade, informational only.
+	Height int64 `json:"height,omitempty"`
+	// Binaries maps os/architecture pairs (or "any") to binary download URLs, as in the upgrade plan info.
+	Binaries plan.BinaryDownloadURLMap `json:"binaries"`
+	// Signatures maps os/architecture pairs (or "any") to the URLs of the detached signatures of the binaries.
+	// A signature signs the upgrade binary, not the downloaded archive it may be contained in.
+	Signatures map[string]string `json:"signatures,omitempty"`
+}
+
+// LoadPrefetchManifest reads the prefetch manifest at the given path.
+func LoadPrefetchManifest(path string) (PrefetchManifest, error) {
+	var manifest PrefetchManifest
+	bz, err := os.ReadFile(path)
+	if err != nil {
+		return manifest, err
+	}
+
+	if err := json.Unmarshal(bz, &manifest); err != nil {
+		return manifest, fmt.Errorf("invalid prefetch manifest %s: %w", path, err)
+	}
+
+	return manifest, nil
+}
+
+// ValidateBasic validates the manifest.
+func (m PrefetchManifest) ValidateBasic(enforceChecksum bool) error {
+	names := make(map[string]bool, len(m.Upgrades))
+	for _, upgrade := range m.Upgrades {
+		if upgrade.Name == "" {
+			return errors.New("upgrade name cannot be empty")
+		}
+
+		if names[upgrade.Name] {
+			return fmt.Errorf("upgrade %s is listed twice", upgrade.Name)
+		}
+		names[upgrade.Name] = true
+
+		if err := upgrade.Binaries.ValidateBasic(enforceChecksum); err != nil {
+			return fmt.Errorf("invalid binaries of upgrade %s: %w", upgrade.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// signatureURL returns the URL of the signature of the binary for this os/architecture.
+func (u PrefetchUpgrade) signatureURL() (string, bool) {
+	url, ok := u.Signatures[OSArch()]
+	if !ok {
+		url, ok = u.Signatures["any"]
+	}
+
+	return url, ok
+}
+
+// findPrefetchUpgrade returns the named upgrade from the prefetch manifest, if any.
+func (cfg *Config) findPrefetchUpgrade(name string) (PrefetchUpgrade, bool, error) {
+	manifest, err := LoadPrefetchManifest(cfg.PrefetchManifestPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return PrefetchUpgrade{}, false, nil
+	} else if err != nil {
+		return PrefetchUpgrade{}, false, err
+	}
+
+	for _, upgrade := range manifest.Upgrades {
+		if strings.EqualFold(upgrade.Name, name) {
+			return upgrade, true, nil
+		}
+	}
+
+	return PrefetchUpgrade{}, false, nil
+}
+
+// VerifyUpgradeBinary verifies the signature of the binary of the named upgrade.
+// It is a no-op when no signature public keys are configured.
+func (cfg *Config) VerifyUpgradeBinary(upgradeName string) error {
+	if len(cfg.SignaturePublicKeys) == 0 {
+		return nil
+	}
+
+	return cfg.verifyBinary(cfg.UpgradeBin(upgradeName), cfg.UpgradeBinSignature(upgradeName))
+}
+
+func (cfg *Config) verifyBinary(bin, signaturePath string) error {
+	keys, err := ParsePublicKeys(cfg.SignaturePublicKeys)
+	if err != nil {
+		return err
+	}
+
+	signature, err := os.ReadFile(signaturePath)
+	if err != nil {
+		return fmt.Errorf("failed to read the signature of %s: %w", bin, err)
+	}
+
+	return VerifySignature(keys, bin, signature)
+}
+
+// Prefetch downloads the binaries of the upgrades of the manifest which are not present yet.
+// When signature public keys are configured, the signatures of the binaries are downloaded
+// and verified as well, and binaries failing verification are discarded.
+func Prefetch(logger log.Logger, cfg *Config, manifest PrefetchManifest) error {
+	if err := manifest.ValidateBasic(cfg.DownloadMustHaveChecksum); err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, upgrade := range manifest.Upgrades {
+		if !cfg.DisableRecase {
+			upgrade.Name = strings.ToLower(upgrade.Name)
+		}
+
+		if err := plan.EnsureBinary(cfg.UpgradeBin(upgrade.Name)); err == nil {
+			if err := cfg.VerifyUpgradeBinary(upgrade.Name); err != nil {
+				errs = append(errs, fmt.Errorf("upgrade %s: binary present but not verified: %w", upgrade.Name, err))
+				continue
+			}
+
+			logger.Info("upgrade binary already present", "upgrade", upgrade.Name)
+			continue
+		}
+
+		if err := upgrade.Binaries.CheckURLs(cfg.Name, cfg.DownloadMustHaveChecksum); err != nil {
+			errs = append(errs, fmt.Errorf("upgrade %s: invalid binaries: %w", upgrade.Name, err))
+			continue
+		}
+
+		logger.Info("prefetching upgrade binary", "upgrade", upgrade.Name, "height", upgrade.Height)
+		if err := downloadUpgrade(cfg, upgrade); err != nil {
+			errs = append(errs, fmt.Errorf("upgrade %s: %w", upgrade.Name, err))
+			continue
+		}
+
+		logger.Info("upgrade binary prefetched", "upgrade", upgrade.Name, "path", cfg.UpgradeBin(upgrade.Name))
+	}
+
+	return errors.Join(errs...)
+}
+
+// downloadUpgrade downloads the binary of an upgrade and its signature to a staging
+// directory, verifies the signature, and moves them to the upgrade directory.
+// An unverified binary never lands in the upgrade directory.
+func downloadUpgrade(cfg *Config, upgrade PrefetchUpgrade) error {
+	switch _, err := os.Stat(cfg.UpgradeDir(upgrade.Name)); {
+	case err == nil:
+		return errors.New("upgrade dir already exists, won't overwrite")
+	case !os.IsNotExist(err):
+		return fmt.Errorf("unhandled error: %w", err)
+	}
+
+	url, err := GetBinaryURL(upgrade.Binaries)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(cfg.BaseUpgradeDir(), 0o755); err != nil {
+		return err
+	}
+
+	stagingDir, err := os.MkdirTemp(cfg.BaseUpgradeDir(), ".download-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(stagingDir)
+
+	if err := os.Chmod(stagingDir, 0o755); err != nil {
+		return err
+	}
+
+	if err := plan.DownloadUpgrade(stagingDir, url, cfg.Name); err != nil {
+		return fmt.Errorf("cannot download binary. %w", err)
+	}
+
+	if err := plan.EnsureBinary(filepath.Join(stagingDir, "bin", cfg.Name)); err != nil {
+		return fmt.Errorf("downloaded binary doesn't check out: %w", err)
+	}
+
+	if len(cfg.SignaturePublicKeys) > 0 {
+		signatureURL, ok := upgrade.signatureURL()
+		if !ok {
+			return fmt.Errorf("no signature for os/arch %s, refusing unverified binary", OSArch())
+		}
+
+		signature, err := plan.DownloadURL(signatureURL)
+		if err != nil {
+			return fmt.Errorf("cannot download signature: %w", err)
+		}
+
+		stagedBin := filepath.Join(stagingDir, "bin", cfg.Name)
+		if err := os.WriteFile(stagedBin+SignatureExtension, []byte(signature+"\n"), 0o600); err != nil {
+			return err
+		}
+
+		if err := cfg.verifyBinary(stagedBin, stagedBin+SignatureExtension); err != nil {
+			return fmt.Errorf("refusing unverified binary: %w", err)
+		}
+	}
+
+	return os.Rename(stagingDir, cfg.UpgradeDir(upgrade.Name))
+}
@@ -25,6 +25,7 @@ type Launcher struct {
 	logger log.Logger
 	cfg    *Config
 	fw     *fileWatcher
+	state  *processState
 }
 
 func NewLauncher(logger log.Logger, cfg *Config) (Launcher, error) {
@@ -33,13 +34,34 @@ func NewLauncher(logger log.Logger, cfg *Config) (Launcher, error) {
 		return Launcher{}, err
 	}
 
-	return Launcher{logger: logger, cfg: cfg, fw: fw}, nil
+	return Launcher{logger: logger, cfg: cfg, fw: fw, state: &processState{status: ProcessStatus{State: ProcessStateStopped}}}, nil
 }
 
 // Run launches the app in a subprocess and returns when the subprocess (app)
 // exits (either when it dies, or *after* a successful upgrade.) and upgrade finished.
 // Returns true if the upgrade request was detected and the upgrade process started.
+// When COSMOVISOR_ROLLBACK_ON_FAILURE is set and the app fails during the health check
+// following an upgrade, it is restarted up to COSMOVISOR_HEALTH_CHECK_MAX_RESTARTS times
+// before the upgrade is rolled back.
 func (l Launcher) Run(args []string, stdout, stderr io.Writer) (bool, error) {
+	for {
+		doUpgrade, err := l.run(args, stdout, stderr)
+		if err == nil || !l.cfg.RollbackOnFailure {
+			return doUpgrade, err
+		}
+
+		restart, rerr := l.handleUpgradeFailure(err)
+		if rerr != nil {
+			return false, rerr
+		}
+
+		if !restart {
+			return false, err
+		}
+	}
+}
+
+func (l Launcher) run(args []string, stdout, stderr io.Writer) (bool, error) {
 	bin, err := l.cfg.CurrentBin()
 	if err != nil {
 		return false, fmt.Errorf("error creating symlink to genesis: %w", err)
@@ -56,6 +78,7 @@ func (l Launcher) Run(args []string, stdout, stderr io.Writer) (bool, error) {
 	if err := cmd.Start(); err != nil {
 		return false, fmt.Errorf("launching process %s %s failed: %w", bin, strings.Join(args, " "), err)
 	}
+	l.state.started(bin, cmd.Process.Pid)
 
 	sigs := make(chan os.Signal, 1)
 	signal.Notify(sigs, syscall.SIGQUIT, syscall.SIGTERM)
@@ -67,14 +90,29 @@ func (l Launcher) Run(args []string, stdout, stderr io.Writer) (bool, error) {
 		}
 	}()
 
-	if needsUpdate, err := l.WaitForUpgradeOrExit(cmd); err != nil || !needsUpdate {
+	stopHealthCheck := l.startHealthCheck(bin)
+	needsUpdate, err := l.WaitForUpgradeOrExit(cmd)
+	stopHealthCheck()
+	l.state.exited(needsUpdate, err)
+	if err != nil || !needsUpdate {
 		return false, err
 	}
 
+	// the restored data of a rolled back upgrade still holds its upgrade plan, which must
+	// not trigger the upgrade again
+	rolledBack, err := l.cfg.isRolledBack(l.fw.currentInfo.Name)
+	if err != nil {
+		return false, err
+	}
+	if rolledBack {
+		return false, fmt.Errorf("refusing to apply upgrade %s again, it was rolled back: remove %s to retry it", l.fw.currentInfo.Name, l.cfg.RollbackReportFilePath(l.fw.currentInfo.Name))
+	}
+
 	if !IsSkipUpgradeHeight(args, l.fw.currentInfo) {
 		l.cfg.WaitRestartDelay()
 
-		if err := l.doBackup(); err != nil {
+		backupDir, err := l.doBackup()
+		if err != nil {
 			return false, err
 		}
 
@@ -90,6 +128,17 @@ func (l Launcher) Run(args []string, stdout, stderr io.Writer) (bool, error) {
 			return false, err
 		}
 
+		if l.cfg.RollbackOnFailure {
+			if err := l.cfg.saveHealthCheck(healthCheck{
+				Name:        l.fw.currentInfo.Name,
+				Height:      l.fw.currentInfo.Height,
+				PreviousDir: filepath.Dir(filepath.Dir(bin)),
+				BackupDir:   backupDir,
+			}); err != nil {
+				return false, err
+			}
+		}
+
 		return true, nil
 	}
 
@@ -161,34 +210,37 @@ func (l Launcher) WaitForUpgradeOrExit(cmd *exec.Cmd) (bool, error) {
 	return true, nil
 }
 
-func (l Launcher) doBackup() error {
+// doBackup takes a backup of the data directory and returns its path.
+func (l Launcher) doBackup() (string, error) {
+	var dst string
+
 	// take backup if `UNSAFE_SKIP_BACKUP` is not set.
 	if !l.cfg.UnsafeSkipBackup {
 		// check if upgrade-info.json is not empty.
 		var uInfo upgradetypes.Plan
 		upgradeInfoFile, err := os.ReadFile(l.cfg.UpgradeInfoFilePath())
 		if err != nil {
-			return fmt.Errorf("error while reading upgrade-info.json: %w", err)
+			return "", fmt.Errorf("error while reading upgrade-info.json: %w", err)
 		}
 
 		if err = json.Unmarshal(upgradeInfoFile, &uInfo); err != nil {
-			return err
+			return "", err
 		}
 
 		if uInfo.Name == "" {
-			return fmt.Errorf("upgrade-info.json is empty")
+			return "", fmt.Errorf("upgrade-info.json is empty")
 		}
 
 		// a destination directory, Format YYYY-MM-DD
 		st := time.Now()
 		ymd := fmt.Sprintf("%d-%d-%d", st.Year(), st.Month(), st.Day())
-		dst := filepath.Join(l.cfg.DataBackupPath, fmt.Sprintf("data"+"-backup-%s", ymd))
+		dst = filepath.Join(l.cfg.DataBackupPath, fmt.Sprintf("data"+"-backup-%s", ymd))
 
 		l.logger.Info("starting to take backup of data directory", "backup start time", st)
 
 		// copy the $DAEMON_HOME/data to a backup dir
 		if err = copy.Copy(filepath.Join(l.cfg.Home, "data"), dst); err != nil {
-			return fmt.Errorf("error while taking data backup: %w", err)
+			return "", fmt.Errorf("error while taking data backup: %w", err)
 		}
 
 		// backup is done, lets check endtime to calculate total time taken for backup process
@@ -196,7 +248,7 @@ func (l Launcher) doBackup() error {
 		l.logger.Info("backup completed", "backup saved at", dst, "backup completion time", et, "time taken to complete backup", et.Sub(st))
 	}
 
-	return nil
+	return dst, nil
 }
 
 // doCustomPreUpgrade executes the custom preupgrade script if provided.
@@ -5,9 +5,12 @@ package cosmovisor_test
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io/fs"
+	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -54,6 +57,8 @@ func (s *processTestSuite) TestLaunchProcess() {
 	require.True(doUpgrade)
 	require.Equal("", stderr.String())
 	require.Equal(fmt.Sprintf("Genesis foo bar 1234 %s\nUPGRADE \"chain2\" NEEDED at height: 49: {}\n", upgradeFile), stdout.String())
+	require.Equal(cosmovisor.ProcessStateUpgrading, launcher.Status().Process.State)
+	require.True(launcher.Status().Process.StartedAt.Before(launcher.Status().Process.ExitedAt))
 
 	// ensure this is upgraded now and produces new output
 	currentBin, err = cfg.CurrentBin()
@@ -194,6 +199,82 @@ func (s *processTestSuite) TestPlanShutdownGrace() {
 	require.Equal(cfg.UpgradeBin("chain2"), currentBin)
 }
 
+// TestLaunchProcessWithRollback will run an upgraded binary which keeps failing and check
+// the upgrade is rolled back once the binary was restarted too many times
+func (s *processTestSuite) TestLaunchProcessWithRollback() {
+	// binaries from testdata/rollback directory
+	require := s.Require()
+	home := copyTestData(s.T(), "rollback")
+	cfg := &cosmovisor.Config{Home: home, Name: "dummyd", PollInterval: 20, DataBackupPath: home, RollbackOnFailure: true, HealthCheckBlocks: 5, HealthCheckMaxRestarts: 2}
+	logger := log.NewTestLogger(s.T()).With(log.ModuleKey, "cosmovisor")
+
+	stdout, stderr := newBuffer(), newBuffer()
+	launcher, err := cosmovisor.NewLauncher(logger, cfg)
+	require.NoError(err)
+
+	upgradeFile := cfg.UpgradeInfoFilePath()
+
+	doUpgrade, err := launcher.Run([]string{"foo", "bar", "1234", upgradeFile}, stdout, stderr)
+	require.NoError(err)
+	require.True(doUpgrade)
+	require.FileExists(cfg.HealthCheckFilePath())
+
+	currentBin, err := cfg.CurrentBin()
+	require.NoError(err)
+	require.Equal(cfg.UpgradeBin("chain2"), currentBin)
+
+	// the upgraded binary fails, it is restarted twice and the upgrade is rolled back
+	stdout.Reset()
+	doUpgrade, err = launcher.Run([]string{"second", "run"}, stdout, stderr)
+	require.ErrorContains(err, "upgrade chain2 failed and was rolled back")
+	require.False(doUpgrade)
+	require.Equal(3, strings.Count(stdout.String(), "Chain 2 is broken!\n"))
+
+	currentBin, err = cfg.CurrentBin()
+	require.NoError(err)
+	require.Equal(cfg.GenesisBin(), currentBin)
+	require.NoFileExists(cfg.HealthCheckFilePath())
+
+	// the data directory is restored from the backup, and the failed one is kept
+	require.FileExists(upgradeFile)
+	require.DirExists(filepath.Join(home, "data-failed-chain2"))
+
+	bz, err := os.ReadFile(cfg.RollbackReportFilePath("chain2"))
+	require.NoError(err)
+
+	var report cosmovisor.RollbackReport
+	require.NoError(json.Unmarshal(bz, &report))
+	require.Equal("chain2", report.Upgrade)
+	require.Equal(int64(49), report.Height)
+	require.Equal(2, report.Restarts)
+	require.Equal(filepath.Dir(filepath.Dir(cfg.GenesisBin())), report.RestoredDir)
+	require.Empty(report.RollbackErrors)
+
+	// cosmovisor is restarted: the restored upgrade plan doesn't trigger the rolled back upgrade again
+	launcher, err = cosmovisor.NewLauncher(logger, cfg)
+	require.NoError(err)
+	doUpgrade, err = launcher.Run([]string{"third", "run"}, stdout, stderr)
+	require.ErrorContains(err, "refusing to apply upgrade chain2 again, it was rolled back")
+	require.False(doUpgrade)
+
+	currentBin, err = cfg.CurrentBin()
+	require.NoError(err)
+	require.Equal(cfg.GenesisBin(), currentBin)
+	require.NoFileExists(cfg.HealthCheckFilePath())
+
+	// the upgrade is retried once its report is removed
+	require.NoError(os.Remove(cfg.RollbackReportFilePath("chain2")))
+	launcher, err = cosmovisor.NewLauncher(logger, cfg)
+	require.NoError(err)
+	doUpgrade, err = launcher.Run([]string{"fourth", "run"}, stdout, stderr)
+	require.NoError(err)
+	require.True(doUpgrade)
+
+	currentBin, err = cfg.CurrentBin()
+	require.NoError(err)
+	require.Equal(cfg.UpgradeBin("chain2"), currentBin)
+}
+
 // TestLaunchProcess will try running the script a few times and watch upgrades work properly
 // and args are passed through
 func (s *processTestSuite) TestLaunchProcessWithDownloads() {
@@ -0,0 +1,262 @@
+package cosmovisor
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/otiai10/copy"
+
+	upgradetypes "cosmossdk.io/x/upgrade/types"
+)
+
+const (
+	healthCheckFileName = "upgrade-health-check.json"
+	rollbackReportFmt   = "rollback-report-%s.json"
+)
+
+// healthCheck is the state of the health check following an upgrade.
+// It is persisted in the cosmovisor directory until the upgraded app commits
+// COSMOVISOR_HEALTH_CHECK_BLOCKS blocks past the upgrade height.
+type healthCheck struct {
+	// Name and Height of the upgrade.
+	Name   string `json:"name"`
+	Height int64  `json:"height"`
+	// PreviousDir is the directory the current link pointed to before the upgrade.
+	PreviousDir string `json:"previous_dir"`
+	// BackupDir is the backup of the data directory taken before the upgrade.
+	BackupDir string `json:"backup_dir"`
+	// Restarts is the number of times the upgraded app was restarted after failing.
+	Restarts int `json:"restarts"`
+}
+
+// RollbackReport describes an upgrade rolled back because the upgraded app kept failing.
+type RollbackReport struct {
+	Upgrade        string    `json:"upgrade"`
+	Height         int64     `json:"height"`
+	Restarts       int       `json:"restarts"`
+	Error          string    `json:"error"`
+	FailedDir      string    `json:"failed_dir"`
+	RestoredDir    string    `json:"restored_dir"`
+	BackupDir      string    `json:"backup_dir"`
+	FailedDataDir  string    `json:"failed_data_dir"`
+	RolledBackAt   time.Time `json:"rolled_back_at"`
+	RollbackErrors []string  `json:"rollback_errors,omitempty"`
+}
+
+// HealthCheckFilePath is the path of the file keeping the state of the health check following an upgrade.
+func (cfg *Config) HealthCheckFilePath() string {
+	return filepath.Join(cfg.Root(), healthCheckFileName)
+}
+
+// RollbackReportFilePath is the path of the report written when the named upgrade is rolled back.
+func (cfg *Config) RollbackReportFilePath(upgradeName string) string {
+	return filepath.Join(cfg.Root(), fmt.Sprintf(rollbackReportFmt, url.PathEscape(upgradeName)))
+}
+
+// isRolledBack returns true if the named upgrade was rolled back, i.e. its rollback
+// report exists. A rolled back upgrade is not applied again until its report is removed.
+func (cfg *Config) isRolledBack(upgradeName string) (bool, error) {
+	_, err := os.Stat(cfg.RollbackReportFilePath(upgradeName))
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// loadHealthCheck returns the state of the pending health check, if any.
+func (cfg *Config) loadHealthCheck() (healthCheck, bool, error) {
+	var hc healthCheck
+	bz, err := os.ReadFile(cfg.HealthCheckFilePath())
+	if errors.Is(err, os.ErrNotExist) {
+		return hc, false, nil
+	} else if err != nil {
+		return hc, false, err
+	}
+
+	if err := json.Unmarshal(bz, &hc); err != nil {
+		return hc, false, fmt.Errorf("invalid %s: %w", healthCheckFileName, err)
+	}
+
+	return hc, true, nil
+}
+
+func (cfg *Config) saveHealthCheck(hc healthCheck) error {
+	bz, err := json.Marshal(hc)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(cfg.HealthCheckFilePath(), bz, 0o600)
+}
+
+func (cfg *Config) clearHealthCheck() error {
+	if err := os.Remove(cfg.HealthCheckFilePath()); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+// startHealthCheck monitors the height of the app during the health check following
+// an upgrade, and ends the health check once the app committed enough blocks.
+// The returned function stops the monitoring.
+func (l Launcher) startHealthCheck(bin string) func() {
+	if !l.cfg.RollbackOnFailure {
+		return func() {}
+	}
+
+	hc, found, err := l.cfg.loadHealthCheck()
+	if err != nil {
+		l.logger.Error("failed to load the upgrade health check", "error", err)
+		return func() {}
+	}
+
+	if !found {
+		return func() {}
+	}
+
+	targetHeight := hc.Height + int64(l.cfg.HealthCheckBlocks)
+	l.logger.Info("starting upgrade health check", "upgrade", hc.Name, "target height", targetHeight)
+
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	ticker := time.NewTicker(l.cfg.PollInterval)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				height, err := queryHeight(bin)
+				if err != nil || height < targetHeight {
+					continue
+				}
+
+				if err := l.cfg.clearHealthCheck(); err != nil {
+					l.logger.Error("failed to clear the upgrade health check", "error", err)
+					continue
+				}
+
+				l.logger.Info("upgrade health check passed", "upgrade", hc.Name, "height", height)
+				return
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		wg.Wait()
+	}
+}
+
+// handleUpgradeFailure handles a failure of the app. If the failure happens during
+// the health check following an upgrade, it returns true if the app must be restarted,
+// or rolls back the upgrade once the app was restarted too many times.
+func (l Launcher) handleUpgradeFailure(appErr error) (bool, error) {
+	hc, found, err := l.cfg.loadHealthCheck()
+	if err != nil {
+		return false, err
+	}
+
+	if !found {
+		return false, nil
+	}
+
+	if hc.Restarts < l.cfg.HealthCheckMaxRestarts {
+		hc.Restarts++
+		l.logger.Error("upgraded app failed during the health check, restarting", "upgrade", hc.Name, "error", appErr, "attempt", hc.Restarts)
+		return true, l.cfg.saveHealthCheck(hc)
+	}
+
+	report := l.rollback(hc, appErr)
+	l.logger.Error("upgraded app failed during the health check, upgrade rolled back",
+		"upgrade", report.Upgrade,
+		"height", report.Height,
+		"restarts", report.Restarts,
+		"error", report.Error,
+		"restored dir", report.RestoredDir,
+		"backup dir", report.BackupDir,
+		"failed data dir", report.FailedDataDir,
+		"rollback errors", report.RollbackErrors,
+	)
+
+	if bz, err := json.MarshalIndent(report, "", "  "); err != nil {
+		l.logger.Error("failed to encode the rollback report", "error", err)
+	} else if err := os.WriteFile(l.cfg.RollbackReportFilePath(hc.Name), bz, 0o600); err != nil {
+		l.logger.Error("failed to write the rollback report", "error", err)
+	}
+
+	if len(report.RollbackErrors) > 0 {
+		return false, fmt.Errorf("upgrade %s failed and could not be rolled back, see %s: %w", hc.Name, l.cfg.RollbackReportFilePath(hc.Name), appErr)
+	}
+
+	return false, fmt.Errorf("upgrade %s failed and was rolled back, see %s: %w", hc.Name, l.cfg.RollbackReportFilePath(hc.Name), appErr)
+}
+
+// rollback reverts the current link to the binary running before the upgrade and
+// restores the data directory from the backup taken before the upgrade.
+// The data directory of the failed upgrade is kept next to the restored one.
+func (l Launcher) rollback(hc healthCheck, appErr error) RollbackReport {
+	report := RollbackReport{
+		Upgrade:      hc.Name,
+		Height:       hc.Height,
+		Restarts:     hc.Restarts,
+		Error:        appErr.Error(),
+		RestoredDir:  hc.PreviousDir,
+		BackupDir:    hc.BackupDir,
+		RolledBackAt: time.Now(),
+	}
+
+	addErr := func(err error) {
+		report.RollbackErrors = append(report.RollbackErrors, err.Error())
+	}
+
+	link := filepath.Join(l.cfg.Root(), currentLink)
+	if dir, err := os.Readlink(link); err == nil {
+		report.FailedDir = dir
+	}
+
+	// revert the current link
+	if err := os.Remove(link); err != nil && !errors.Is(err, os.ErrNotExist) {
+		addErr(fmt.Errorf("failed to remove the current link: %w", err))
+	} else if err := os.Symlink(hc.PreviousDir, link); err != nil {
+		addErr(fmt.Errorf("failed to restore the current link: %w", err))
+	}
+	l.cfg.currentUpgrade = upgradetypes.Plan{}
+
+	// restore the data directory
+	if hc.BackupDir == "" {
+		addErr(errors.New("no data backup to restore"))
+	} else {
+		dataDir := filepath.Join(l.cfg.Home, "data")
+		report.FailedDataDir = filepath.Join(l.cfg.Home, fmt.Sprintf("data-failed-%s", url.PathEscape(hc.Name)))
+
+		if err := os.RemoveAll(report.FailedDataDir); err != nil {
+			addErr(fmt.Errorf("failed to remove the previous failed data directory: %w", err))
+		} else if err := os.Rename(dataDir, report.FailedDataDir); err != nil {
+			addErr(fmt.Errorf("failed to move the failed data directory: %w", err))
+		} else if err := copy.Copy(hc.BackupDir, dataDir); err != nil {
+			addErr(fmt.Errorf("failed to restore the data backup: %w", err))
+		}
+	}
+
+	if err := l.cfg.clearHealthCheck(); err != nil {
+		addErr(fmt.Errorf("failed to clear the upgrade health check: %w", err))
+	}
+
+	return report
+}
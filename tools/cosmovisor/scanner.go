@@ -160,7 +160,12 @@ func (fw *fileWatcher) checkHeight() (int64, error) {
 		return 0, nil
 	}
 
-	result, err := exec.Command(fw.currentBin, "status").Output() //nolint:gosec // we want to execute the status command
+	return queryHeight(fw.currentBin)
+}
+
+// queryHeight returns the latest block height reported by the status command of the binary.
+func queryHeight(bin string) (int64, error) {
+	result, err := exec.Command(bin, "status").Output() //nolint:gosec // we want to execute the status command
 	if err != nil {
 		return 0, err
 	}
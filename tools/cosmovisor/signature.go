@@ -0,0 +1,183 @@
+package cosmovisor
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+// SignatureExtension is the extension of the detached signature of an upgrade binary.
+const SignatureExtension = ".minisig"
+
+const (
+	untrustedCommentPrefix = "untrusted comment:"
+	trustedCommentPrefix   = "trusted comment:"
+
+	// minisign signature algorithms, signing the file or its BLAKE2b-512 hash
+	sigAlgEd         = "Ed"
+	sigAlgEdPrehash  = "ED"
+	minisignKeyIDLen = 8
+)
+
+// PublicKey is a public key used to verify the signatures of upgrade binaries.
+// It is either a minisign public key, or a raw ed25519 public key.
+type PublicKey struct {
+	// KeyID is the minisign key ID, empty for raw ed25519 public keys.
+	KeyID []byte
+	Key   ed25519.PublicKey
+}
+
+// ParsePublicKey parses a base64 encoded minisign or raw ed25519 public key.
+// The content of a minisign public key file is accepted as well.
+func ParsePublicKey(s string) (PublicKey, error) {
+	s = lastLine(s)
+	bz, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return PublicKey{}, fmt.Errorf("invalid public key %q: %w", s, err)
+	}
+
+	switch len(bz) {
+	case ed25519.PublicKeySize:
+		return PublicKey{Key: bz}, nil
+	case 2 + minisignKeyIDLen + ed25519.PublicKeySize:
+		if string(bz[:2]) != sigAlgEd {
+			return PublicKey{}, fmt.Errorf("invalid public key %q: unsupported algorithm %q", s, bz[:2])
+		}
+		return PublicKey{KeyID: bz[2 : 2+minisignKeyIDLen], Key: bz[2+minisignKeyIDLen:]}, nil
+	default:
+		return PublicKey{}, fmt.Errorf("invalid public key %q: unexpected length %d", s, len(bz))
+	}
+}
+
+// ParsePublicKeys parses a list of public keys.
+func ParsePublicKeys(keys []string) ([]PublicKey, error) {
+	res := make([]PublicKey, 0, len(keys))
+	for _, key := range keys {
+		pk, err := ParsePublicKey(key)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, pk)
+	}
+
+	return res, nil
+}
+
+// VerifySignature verifies the detached signature of a file with any of the given public keys.
+// The signature is either a minisign signature file, or a base64 encoded raw ed25519 signature.
+func VerifySignature(keys []PublicKey, filePath string, signature []byte) error {
+	if len(keys) == 0 {
+		return errors.New("no public key to verify the signature")
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(signature)), "\n")
+	for i := range lines {
+		lines[i] = strings.TrimSpace(lines[i])
+	}
+
+	if strings.HasPrefix(lines[0], untrustedCommentPrefix) {
+		return verifyMinisign(keys, filePath, lines)
+	}
+
+	if len(lines) != 1 {
+		return errors.New("invalid signature: expected a minisign signature or a base64 encoded ed25519 signature")
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(lines[0])
+	if err != nil || len(sig) != ed25519.SignatureSize {
+		return errors.New("invalid signature: expected a minisign signature or a base64 encoded ed25519 signature")
+	}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		if key.KeyID == nil && ed25519.Verify(key.Key, data, sig) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("signature of %s does not match any public key", filePath)
+}
+
+// verifyMinisign verifies a minisign signature, made of an untrusted comment, the signature,
+// a trusted comment and a global signature of the signature and the trusted comment.
+func verifyMinisign(keys []PublicKey, filePath string, lines []string) error {
+	if len(lines) != 4 || !strings.HasPrefix(lines[2], trustedCommentPrefix) {
+		return errors.New("invalid minisign signature: unexpected format")
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(lines[1])
+	if err != nil || len(sig) != 2+minisignKeyIDLen+ed25519.SignatureSize {
+		return errors.New("invalid minisign signature: unexpected signature encoding")
+	}
+
+	globalSig, err := base64.StdEncoding.DecodeString(lines[3])
+	if err != nil || len(globalSig) != ed25519.SignatureSize {
+		return errors.New("invalid minisign signature: unexpected global signature encoding")
+	}
+
+	alg, keyID, fileSig := string(sig[:2]), sig[2:2+minisignKeyIDLen], sig[2+minisignKeyIDLen:]
+
+	var key *PublicKey
+	for i := range keys {
+		if bytes.Equal(keys[i].KeyID, keyID) {
+			key = &keys[i]
+			break
+		}
+	}
+	if key == nil {
+		return fmt.Errorf("signature of %s is signed by unknown key %X", filePath, keyID)
+	}
+
+	f, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var message []byte
+	switch alg {
+	case sigAlgEd:
+		if message, err = io.ReadAll(f); err != nil {
+			return err
+		}
+	case sigAlgEdPrehash:
+		h, _ := blake2b.New512(nil)
+		if _, err := io.Copy(h, f); err != nil {
+			return err
+		}
+		message = h.Sum(nil)
+	default:
+		return fmt.Errorf("invalid minisign signature: unsupported algorithm %q", alg)
+	}
+
+	if !ed25519.Verify(key.Key, message, fileSig) {
+		return fmt.Errorf("signature of %s does not match key %X", filePath, keyID)
+	}
+
+	trustedComment := strings.TrimPrefix(lines[2], trustedCommentPrefix)
+	trustedComment = strings.TrimPrefix(trustedComment, " ")
+	globalMessage := make([]byte, 0, len(fileSig)+len(trustedComment))
+	globalMessage = append(append(globalMessage, fileSig...), trustedComment...)
+	if !ed25519.Verify(key.Key, globalMessage, globalSig) {
+		return fmt.Errorf("trusted comment of the signature of %s does not match key %X", filePath, keyID)
+	}
+
+	return nil
+}
+
+// lastLine returns the last non empty line of s, e.g. the key of a minisign public key file.
+func lastLine(s string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
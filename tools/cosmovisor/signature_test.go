@@ -0,0 +1,137 @@
+package cosmovisor
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/blake2b"
+)
+
+type testSigner struct {
+	keyID []byte
+	priv  ed25519.PrivateKey
+	pub   ed25519.PublicKey
+}
+
+func newTestSigner(t *testing.T, keyID string) testSigner {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	return testSigner{keyID: []byte(keyID), priv: priv, pub: pub}
+}
+
+// minisignPublicKey returns the content of the minisign public key file.
+func (s testSigner) minisignPublicKey() string {
+	key := append(append([]byte(sigAlgEd), s.keyID...), s.pub...)
+	return fmt.Sprintf("untrusted comment: minisign public key %X\n%s\n", s.keyID, base64.StdEncoding.EncodeToString(key))
+}
+
+// minisign returns a minisign signature of data with the given algorithm.
+func (s testSigner) minisign(data []byte, alg, trustedComment string) []byte {
+	message := data
+	if alg == sigAlgEdPrehash {
+		h := blake2b.Sum512(data)
+		message = h[:]
+	}
+
+	fileSig := ed25519.Sign(s.priv, message)
+	sig := append(append([]byte(alg), s.keyID...), fileSig...)
+	globalSig := ed25519.Sign(s.priv, append(fileSig, trustedComment...))
+
+	return []byte(fmt.Sprintf("untrusted comment: signature from minisign secret key\n%s\ntrusted comment: %s\n%s\n",
+		base64.StdEncoding.EncodeToString(sig), trustedComment, base64.StdEncoding.EncodeToString(globalSig)))
+}
+
+func TestParsePublicKey(t *testing.T) {
+	signer := newTestSigner(t, "12345678")
+
+	pk, err := ParsePublicKey(signer.minisignPublicKey())
+	require.NoError(t, err)
+	require.Equal(t, signer.keyID, pk.KeyID)
+	require.Equal(t, signer.pub, pk.Key)
+
+	pk, err = ParsePublicKey(base64.StdEncoding.EncodeToString(signer.pub))
+	require.NoError(t, err)
+	require.Nil(t, pk.KeyID)
+	require.Equal(t, signer.pub, pk.Key)
+
+	_, err = ParsePublicKey("not base64!")
+	require.Error(t, err)
+
+	_, err = ParsePublicKey(base64.StdEncoding.EncodeToString([]byte("too short")))
+	require.ErrorContains(t, err, "unexpected length")
+
+	key := append(append([]byte("Xx"), signer.keyID...), signer.pub...)
+	_, err = ParsePublicKey(base64.StdEncoding.EncodeToString(key))
+	require.ErrorContains(t, err, "unsupported algorithm")
+}
+
+func TestVerifySignature(t *testing.T) {
+	signer := newTestSigner(t, "12345678")
+	other := newTestSigner(t, "87654321")
+
+	data := []byte("#!/bin/sh\necho upgraded\n")
+	bin := filepath.Join(t.TempDir(), "autod")
+	require.NoError(t, os.WriteFile(bin, data, 0o600))
+
+	keys, err := ParsePublicKeys([]string{signer.minisignPublicKey(), base64.StdEncoding.EncodeToString(signer.pub)})
+	require.NoError(t, err)
+
+	tamperedComment := bytes.Replace(signer.minisign(data, sigAlgEdPrehash, "timestamp:1"), []byte("timestamp:1"), []byte("timestamp:2"), 1)
+
+	cases := map[string]struct {
+		signature []byte
+		expErr    string
+	}{
+		"minisign": {
+			signature: signer.minisign(data, sigAlgEd, "timestamp:1"),
+		},
+		"minisign prehashed": {
+			signature: signer.minisign(data, sigAlgEdPrehash, "timestamp:1\tfile:autod"),
+		},
+		"raw ed25519": {
+			signature: []byte(base64.StdEncoding.EncodeToString(ed25519.Sign(signer.priv, data)) + "\n"),
+		},
+		"minisign of other data": {
+			signature: signer.minisign([]byte("other"), sigAlgEdPrehash, "timestamp:1"),
+			expErr:    "does not match key",
+		},
+		"minisign from unknown key": {
+			signature: other.minisign(data, sigAlgEdPrehash, "timestamp:1"),
+			expErr:    "unknown key",
+		},
+		"minisign with tampered trusted comment": {
+			signature: tamperedComment,
+			expErr:    "trusted comment",
+		},
+		"raw ed25519 from unknown key": {
+			signature: []byte(base64.StdEncoding.EncodeToString(ed25519.Sign(other.priv, data))),
+			expErr:    "does not match any public key",
+		},
+		"garbage": {
+			signature: []byte("garbage"),
+			expErr:    "invalid signature",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := VerifySignature(keys, bin, tc.signature)
+			if tc.expErr != "" {
+				require.ErrorContains(t, err, tc.expErr)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+
+	require.ErrorContains(t, VerifySignature(nil, bin, signer.minisign(data, sigAlgEd, "")), "no public key")
+}
@@ -18,7 +18,11 @@ func UpgradeBinary(logger log.Logger, cfg *Config, p upgradetypes.Plan) error {
 	// simplest case is to switch the link
 	err := plan.EnsureBinary(cfg.UpgradeBin(p.Name))
 	if err == nil {
-		// we have the binary - do it
+		// we have the binary - verify it and do it
+		if err := cfg.VerifyUpgradeBinary(p.Name); err != nil {
+			return fmt.Errorf("refusing to switch to unverified binary: %w", err)
+		}
+
 		return cfg.SetCurrentUpgrade(p)
 	}
 
@@ -27,6 +31,11 @@ func UpgradeBinary(logger log.Logger, cfg *Config, p upgradetypes.Plan) error {
 		return fmt.Errorf("binary not present, downloading disabled: %w", err)
 	}
 
+	// if auto-download is paused through the control API, we fail
+	if cfg.AutoDownloadPaused() {
+		return fmt.Errorf("binary not present, downloading paused: %w", err)
+	}
+
 	// if the dir is there already, don't download either
 	switch fi, err := os.Stat(cfg.UpgradeDir(p.Name)); {
 	case fi != nil: // The directory exists, do not overwrite.
@@ -53,17 +62,20 @@ func UpgradeBinary(logger log.Logger, cfg *Config, p upgradetypes.Plan) error {
 		return err
 	}
 
-	// If not there, then we try to download it... maybe
-	logger.Info("no upgrade binary found, beginning to download it")
-	if err := plan.DownloadUpgrade(cfg.UpgradeDir(p.Name), url, cfg.Name); err != nil {
-		return fmt.Errorf("cannot download binary. %w", err)
+	// the signatures of the binaries are listed in the prefetch manifest
+	upgrade := PrefetchUpgrade{Name: p.Name, Binaries: upgradeInfo.Binaries}
+	if prefetchUpgrade, found, err := cfg.findPrefetchUpgrade(p.Name); err != nil {
+		return err
+	} else if found {
+		upgrade.Signatures = prefetchUpgrade.Signatures
 	}
-	logger.Info("downloading binary complete")
 
-	// and then set the binary again
-	if err := plan.EnsureBinary(cfg.UpgradeBin(p.Name)); err != nil {
-		return fmt.Errorf("downloaded binary doesn't check out: %w", err)
+	// If not there, then we try to download it... maybe
+	logger.Info("no upgrade binary found, beginning to download it", "url", url)
+	if err := downloadUpgrade(cfg, upgrade); err != nil {
+		return err
 	}
+	logger.Info("downloading binary complete")
 
 	return cfg.SetCurrentUpgrade(p)
 }
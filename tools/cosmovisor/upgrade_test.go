@@ -4,6 +4,9 @@
 package cosmovisor_test
 
 import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -197,6 +200,94 @@ func (s *upgradeTestSuite) TestUpgradeBinary() {
 	}
 }
 
+func (s *upgradeTestSuite) TestPrefetch() {
+	logger := log.NewLogger(os.Stdout).With(log.ModuleKey, "cosmovisor")
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	s.Require().NoError(err)
+
+	binPath, err := filepath.Abs("./testdata/repo/raw_binary/autod")
+	s.Require().NoError(err)
+	// sha256sum ./testdata/repo/raw_binary/autod
+	binURL := binPath + "?checksum=sha256:e6bc7851600a2a9917f7bf88eb7bdee1ec162c671101485690b4deb089077b0d"
+	bin, err := os.ReadFile(binPath)
+	s.Require().NoError(err)
+
+	sigDir := s.T().TempDir()
+	validSig := filepath.Join(sigDir, "valid.sig")
+	s.Require().NoError(os.WriteFile(validSig, []byte(base64.StdEncoding.EncodeToString(ed25519.Sign(priv, bin))), 0o600))
+	invalidSig := filepath.Join(sigDir, "invalid.sig")
+	s.Require().NoError(os.WriteFile(invalidSig, []byte(base64.StdEncoding.EncodeToString(ed25519.Sign(priv, []byte("other")))), 0o600))
+
+	home := copyTestData(s.T(), "download")
+	cfg := &cosmovisor.Config{
+		Home:                home,
+		Name:                "autod",
+		SignaturePublicKeys: []string{base64.StdEncoding.EncodeToString(pub)},
+	}
+
+	binaries := map[string]string{cosmovisor.OSArch(): binURL}
+	manifest := cosmovisor.PrefetchManifest{
+		Upgrades: []cosmovisor.PrefetchUpgrade{
+			{Name: "Amazonas", Height: 100, Binaries: binaries, Signatures: map[string]string{"any": validSig}},
+			{Name: "tampered", Height: 200, Binaries: binaries, Signatures: map[string]string{cosmovisor.OSArch(): invalidSig}},
+			{Name: "unsigned", Height: 300, Binaries: binaries},
+		},
+	}
+
+	err = cosmovisor.Prefetch(logger, cfg, manifest)
+	s.Require().ErrorContains(err, "upgrade tampered: refusing unverified binary")
+	s.Require().ErrorContains(err, "upgrade unsigned: no signature")
+
+	// the verified binary is prefetched with its signature
+	s.Require().FileExists(cfg.UpgradeBin("amazonas"))
+	s.Require().FileExists(cfg.UpgradeBinSignature("amazonas"))
+	s.Require().NoError(cfg.VerifyUpgradeBinary("amazonas"))
+
+	// unverified binaries are discarded
+	for _, name := range []string{"tampered", "unsigned"} {
+		s.Require().NoDirExists(cfg.UpgradeDir(name))
+	}
+	entries, err := os.ReadDir(cfg.BaseUpgradeDir())
+	s.Require().NoError(err)
+	s.Require().Len(entries, 1)
+
+	// prefetching again skips present binaries
+	manifest.Upgrades = manifest.Upgrades[:1]
+	s.Require().NoError(cosmovisor.Prefetch(logger, cfg, manifest))
+
+	// the upgrade switches to the prefetched binary
+	s.Require().NoError(cosmovisor.UpgradeBinary(logger, cfg, upgradetypes.Plan{Name: "amazonas"}))
+	currentBin, err := cfg.CurrentBin()
+	s.Require().NoError(err)
+	s.Require().Equal(cfg.UpgradeBin("amazonas"), currentBin)
+
+	// duplicated upgrades are rejected
+	manifest.Upgrades = append(manifest.Upgrades, manifest.Upgrades[0])
+	s.Require().ErrorContains(cosmovisor.Prefetch(logger, cfg, manifest), "listed twice")
+}
+
+func (s *upgradeTestSuite) TestUpgradeBinaryRefusesUnverifiedBinary() {
+	logger := log.NewLogger(os.Stdout).With(log.ModuleKey, "cosmovisor")
+
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	s.Require().NoError(err)
+
+	home := copyTestData(s.T(), "validate")
+	cfg := &cosmovisor.Config{
+		Home:                home,
+		Name:                "dummyd",
+		SignaturePublicKeys: []string{base64.StdEncoding.EncodeToString(pub)},
+	}
+
+	err = cosmovisor.UpgradeBinary(logger, cfg, upgradetypes.Plan{Name: "chain2"})
+	s.Require().ErrorContains(err, "refusing to switch to unverified binary")
+
+	currentBin, err := cfg.CurrentBin()
+	s.Require().NoError(err)
+	s.Require().Equal(cfg.GenesisBin(), currentBin)
+}
+
 func (s *upgradeTestSuite) TestOsArch() {
 	// all download tests will fail if we are not on linux...
 	s.Require().Equal("linux/amd64", cosmovisor.OSArch())
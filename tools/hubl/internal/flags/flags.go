@@ -10,9 +10,35 @@ const (
 	FlagKeyringBackend = "keyring-backend"
 )
 
+// Transaction flags
+const (
+	FlagFrom          = "from"
+	FlagChainID       = "chain-id"
+	FlagFees          = "fees"
+	FlagGasPrices     = "gas-prices"
+	FlagGas           = "gas"
+	FlagGasAdjustment = "gas-adjustment"
+	FlagNote          = "note"
+	FlagTimeoutHeight = "timeout-height"
+	FlagAccountNumber = "account-number"
+	FlagSequence      = "sequence"
+	FlagGenerateOnly  = "generate-only"
+	FlagSignMode      = "sign-mode"
+	FlagDryRun        = "dry-run"
+	FlagYes           = "yes"
+)
+
 const (
 	OutputFormatText = "text"
 	OutputFormatJSON = "json"
 
 	DefaultKeyringBackend = "os"
+
+	SignModeDirect    = "direct"
+	SignModeAminoJSON = "amino-json"
+	SignModeTextual   = "textual"
+
+	GasFlagAuto          = "auto"
+	DefaultGasLimit      = 200000
+	DefaultGasAdjustment = 1.5
 )
@@ -18,7 +18,9 @@ import (
 
 	authv1betav1 "cosmossdk.io/api/cosmos/auth/v1beta1"
 	autocliv1 "cosmossdk.io/api/cosmos/autocli/v1"
+	reflectionv2alpha1 "cosmossdk.io/api/cosmos/base/reflection/v2alpha1"
 	reflectionv1 "cosmossdk.io/api/cosmos/reflection/v1"
+	signingv1beta1 "cosmossdk.io/api/cosmos/tx/signing/v1beta1"
 	"cosmossdk.io/tools/hubl/internal/config"
 )
 
@@ -32,6 +34,9 @@ type ChainInfo struct {
 
 	ProtoFiles    *protoregistry.Files
 	ModuleOptions map[string]*autocliv1.ModuleOptions
+	// SignModes are the sign modes advertised by the chain, including its custom sign
+	// modes. They are empty when the chain doesn't advertise them.
+	SignModes []signingv1beta1.SignMode
 }
 
 func NewChainInfo(configDir, chain string, config *config.ChainConfig) *ChainInfo {
@@ -64,6 +69,14 @@ func (c *ChainInfo) appOptsCacheFilename() (string, error) {
 	return path.Join(cacheDir, fmt.Sprintf("%s.autocli", c.Chain)), nil
 }
 
+func (c *ChainInfo) signModesCacheFilename() (string, error) {
+	cacheDir, err := c.getCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return path.Join(cacheDir, fmt.Sprintf("%s.signmodes", c.Chain)), nil
+}
+
 func (c *ChainInfo) Load(reload bool) error {
 	fdSet := &descriptorpb.FileDescriptorSet{}
 	fdsFilename, err := c.fdsCacheFilename()
@@ -153,6 +166,54 @@ func (c *ChainInfo) Load(reload bool) error {
 		c.ModuleOptions = appOptsRes.ModuleOptions
 	}
 
+	return c.loadSignModes(reload)
+}
+
+// loadSignModes loads the sign modes advertised by the chain through the reflection
+// service, from the cache unless reload is set.
+func (c *ChainInfo) loadSignModes(reload bool) error {
+	signModesFilename, err := c.signModesCacheFilename()
+	if err != nil {
+		return err
+	}
+
+	authnRes := &reflectionv2alpha1.GetAuthnDescriptorResponse{}
+	if _, err := os.Stat(signModesFilename); os.IsNotExist(err) || reload {
+		client, err := c.OpenClient()
+		if err != nil {
+			return err
+		}
+
+		// chains without the reflection service don't advertise their sign modes
+		res, err := reflectionv2alpha1.NewReflectionServiceClient(client).GetAuthnDescriptor(c.Context, &reflectionv2alpha1.GetAuthnDescriptorRequest{})
+		if err == nil {
+			authnRes = res
+		}
+
+		bz, err := proto.Marshal(authnRes)
+		if err != nil {
+			return err
+		}
+
+		if err := os.WriteFile(signModesFilename, bz, 0o600); err != nil {
+			return err
+		}
+	} else {
+		bz, err := os.ReadFile(signModesFilename)
+		if err != nil {
+			return err
+		}
+
+		if err := proto.Unmarshal(bz, authnRes); err != nil {
+			return err
+		}
+	}
+
+	c.SignModes = nil
+	for _, signMode := range authnRes.GetAuthn().GetSignModes() {
+		c.SignModes = append(c.SignModes, signingv1beta1.SignMode(signMode.Number))
+	}
+
 	return nil
 }
 
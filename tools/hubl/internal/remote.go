@@ -11,6 +11,7 @@ import (
 	"google.golang.org/protobuf/reflect/protoregistry"
 	"google.golang.org/protobuf/types/dynamicpb"
 
+	autocliv1 "cosmossdk.io/api/cosmos/autocli/v1"
 	"cosmossdk.io/client/v2/autocli"
 	"cosmossdk.io/client/v2/autocli/flag"
 	"cosmossdk.io/tools/hubl/internal/config"
@@ -54,96 +55,129 @@ func RemoteCommand(config *config.Config, configDir string) ([]*cobra.Command, e
 			continue
 		}
 
-		// add comet commands
-		cometCmds := cmtservice.NewCometBFTCommands()
-		chainInfo.ModuleOptions[cometCmds.Name()] = cometCmds.AutoCLIOptions()
-
-		appOpts := autocli.AppOptions{
-			ModuleOptions: chainInfo.ModuleOptions,
-		}
-
-		addressCodec, validatorAddressCodec, consensusAddressCodec, err := getAddressCodecFromConfig(config, chain)
+		chainCmd, err := newChainCommand(config, configDir, chainInfo)
 		if err != nil {
 			return nil, err
 		}
 
-		kr, err := getKeyring(chain)
-		if err != nil {
-			return nil, err
-		}
+		// add interactive session, which builds new chain commands for every line
+		chainCmd.AddCommand(ReplCommand(chainInfo, func() (*cobra.Command, error) {
+			return newChainCommand(config, configDir, chainInfo)
+		}))
 
-		autoCLIKeyring, err := keyring.NewAutoCLIKeyring(kr)
-		if err != nil {
-			return nil, err
-		}
+		commands = append(commands, chainCmd)
+	}
 
-		builder := &autocli.Builder{
-			Builder: flag.Builder{
-				TypeResolver:          &dynamicTypeResolver{chainInfo},
-				FileResolver:          chainInfo.ProtoFiles,
-				AddressCodec:          addressCodec,
-				ValidatorAddressCodec: validatorAddressCodec,
-				ConsensusAddressCodec: consensusAddressCodec,
-				Keyring:               autoCLIKeyring,
-			},
-			GetClientConn: func(command *cobra.Command) (grpc.ClientConnInterface, error) {
-				return chainInfo.OpenClient()
-			},
-			AddQueryConnFlags: func(command *cobra.Command) {},
-		}
+	return commands, nil
+}
 
-		var (
-			update   bool
-			reconfig bool
-			insecure bool
-			output   string
-		)
-
-		chainCmd := &cobra.Command{
-			Use:   chain,
-			Short: fmt.Sprintf("Commands for the %s chain", chain),
-			RunE: func(cmd *cobra.Command, args []string) error {
-				switch {
-				case reconfig:
-					return reconfigure(cmd, config, configDir, chain)
-				case update:
-					cmd.Printf("Updating AutoCLI data for %s\n", chain)
-					return chainInfo.Load(true)
-				default:
-					return cmd.Help()
-				}
-			},
+func newChainCommand(config *config.Config, configDir string, chainInfo *ChainInfo) (*cobra.Command, error) {
+	chain := chainInfo.Chain
+
+	// add comet commands
+	cometCmds := cmtservice.NewCometBFTCommands()
+	chainInfo.ModuleOptions[cometCmds.Name()] = cometCmds.AutoCLIOptions()
+
+	// transactions are built by hubl, autocli only builds the query commands
+	queryModuleOptions := make(map[string]*autocliv1.ModuleOptions, len(chainInfo.ModuleOptions))
+	for name, modOpts := range chainInfo.ModuleOptions {
+		if modOpts == nil {
+			continue
 		}
-		chainCmd.Flags().BoolVar(&update, flags.FlagUpdate, false, "update the CLI commands for the selected chain (should be used after every chain upgrade)")
-		chainCmd.Flags().BoolVar(&reconfig, flags.FlagConfig, false, "re-configure the selected chain (allows choosing a new gRPC endpoint and refreshes data")
-		chainCmd.Flags().BoolVar(&insecure, flags.FlagInsecure, false, "allow re-configuring the selected chain using an insecure gRPC connection")
-		chainCmd.PersistentFlags().StringVar(&output, flags.FlagOutput, flags.OutputFormatJSON, fmt.Sprintf("output format (%s|%s)", flags.OutputFormatText, flags.OutputFormatJSON))
-
-		// add chain specific keyring
-		chainCmd.AddCommand(KeyringCmd(chainInfo.Chain))
-
-		// add client context
-		clientCtx := client.Context{}.WithKeyring(kr)
-		chainCmd.SetContext(context.WithValue(context.Background(), client.ClientContextKey, &clientCtx))
-
-		if err := appOpts.EnhanceRootCommandWithBuilder(chainCmd, builder); err != nil {
-			// when enriching the command with autocli fails, we add a command that
-			// will print the error and allow the user to reconfigure the chain instead
-			chainCmd.RunE = func(cmd *cobra.Command, args []string) error {
-				cmd.Printf("Error while loading AutoCLI data for %s: %+v\n", chain, err)
-				cmd.Printf("Attempt to reconfigure the chain using the %s flag\n", flags.FlagConfig)
-				if cmd.Flags().Changed(flags.FlagConfig) {
-					return reconfigure(cmd, config, configDir, chain)
-				}
-
-				return nil
+
+		queryModuleOptions[name] = &autocliv1.ModuleOptions{Query: modOpts.Query}
+	}
+
+	appOpts := autocli.AppOptions{
+		ModuleOptions: queryModuleOptions,
+	}
+
+	addressCodec, validatorAddressCodec, consensusAddressCodec, err := getAddressCodecFromConfig(config, chain)
+	if err != nil {
+		return nil, err
+	}
+
+	kr, err := getKeyring(chain)
+	if err != nil {
+		return nil, err
+	}
+
+	autoCLIKeyring, err := keyring.NewAutoCLIKeyring(kr)
+	if err != nil {
+		return nil, err
+	}
+
+	builder := &autocli.Builder{
+		Builder: flag.Builder{
+			TypeResolver:          &dynamicTypeResolver{chainInfo},
+			FileResolver:          chainInfo.ProtoFiles,
+			AddressCodec:          addressCodec,
+			ValidatorAddressCodec: validatorAddressCodec,
+			ConsensusAddressCodec: consensusAddressCodec,
+			Keyring:               autoCLIKeyring,
+		},
+		GetClientConn: func(command *cobra.Command) (grpc.ClientConnInterface, error) {
+			return chainInfo.OpenClient()
+		},
+		AddQueryConnFlags: func(command *cobra.Command) {},
+	}
+
+	var (
+		update   bool
+		reconfig bool
+		insecure bool
+		output   string
+	)
+
+	chainCmd := &cobra.Command{
+		Use:   chain,
+		Short: fmt.Sprintf("Commands for the %s chain", chain),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			switch {
+			case reconfig:
+				return reconfigure(cmd, config, configDir, chain)
+			case update:
+				cmd.Printf("Updating AutoCLI data for %s\n", chain)
+				return chainInfo.Load(true)
+			default:
+				return cmd.Help()
 			}
-		}
+		},
+	}
+	chainCmd.Flags().BoolVar(&update, flags.FlagUpdate, false, "update the CLI commands for the selected chain (should be used after every chain upgrade)")
+	chainCmd.Flags().BoolVar(&reconfig, flags.FlagConfig, false, "re-configure the selected chain (allows choosing a new gRPC endpoint and refreshes data")
+	chainCmd.Flags().BoolVar(&insecure, flags.FlagInsecure, false, "allow re-configuring the selected chain using an insecure gRPC connection")
+	chainCmd.PersistentFlags().StringVar(&output, flags.FlagOutput, flags.OutputFormatJSON, fmt.Sprintf("output format (%s|%s)", flags.OutputFormatText, flags.OutputFormatJSON))
+
+	// add chain specific keyring
+	chainCmd.AddCommand(KeyringCmd(chainInfo.Chain))
+
+	// add client context
+	clientCtx := client.Context{}.WithKeyring(kr)
+	chainCmd.SetContext(context.WithValue(context.Background(), client.ClientContextKey, &clientCtx))
+
+	// add transaction commands, built from the chain descriptors
+	txCmd, err := TxCommand(chainInfo, &builder.Builder, kr)
+	if err == nil {
+		chainCmd.AddCommand(txCmd)
+		err = appOpts.EnhanceRootCommandWithBuilder(chainCmd, builder)
+	}
 
-		commands = append(commands, chainCmd)
+	if err != nil {
+		// when enriching the command with autocli fails, we add a command that
+		// will print the error and allow the user to reconfigure the chain instead
+		chainCmd.RunE = func(cmd *cobra.Command, args []string) error {
+			cmd.Printf("Error while loading AutoCLI data for %s: %+v\n", chain, err)
+			cmd.Printf("Attempt to reconfigure the chain using the %s flag\n", flags.FlagConfig)
+			if cmd.Flags().Changed(flags.FlagConfig) {
+				return reconfigure(cmd, config, configDir, chain)
+			}
+
+			return nil
+		}
 	}
 
-	return commands, nil
+	return chainCmd, nil
 }
 
 func RemoteErrorCommand(cfg *config.Config, configDir, chain string, chainConfig *config.ChainConfig, err error) *cobra.Command {
@@ -0,0 +1,227 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/chzyer/readline"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// ReplCommand returns a command starting an interactive session against a chain.
+// Each line is executed as a command of the chain, with tab completion over the
+// services, messages and fields of the chain.
+// newChainCmd is called for every line, as cobra commands keep their flag values
+// between executions.
+func ReplCommand(chainInfo *ChainInfo, newChainCmd func() (*cobra.Command, error)) *cobra.Command {
+	return &cobra.Command{
+		Use:   "repl",
+		Short: fmt.Sprintf("Start an interactive session against the %s chain", chainInfo.Chain),
+		Long: fmt.Sprintf(`Start an interactive session against the %s chain.
+Commands are entered without the chain name, e.g. "q bank balances [address]" or "tx bank send [from] [to] [amount]".
+Press tab to complete commands and flags, and type "exit" or press Ctrl+D to quit.`, chainInfo.Chain),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			completionCmd, err := newChainCmd()
+			if err != nil {
+				return err
+			}
+
+			cacheDir, err := chainInfo.getCacheDir()
+			if err != nil {
+				return err
+			}
+
+			rl, err := readline.NewEx(&readline.Config{
+				Prompt:          fmt.Sprintf("%s> ", chainInfo.Chain),
+				HistoryFile:     path.Join(cacheDir, fmt.Sprintf("%s.history", chainInfo.Chain)),
+				AutoComplete:    replCompleter{root: completionCmd},
+				InterruptPrompt: "^C",
+				EOFPrompt:       "exit",
+			})
+			if err != nil {
+				return err
+			}
+			defer rl.Close()
+
+			for {
+				line, err := rl.Readline()
+				switch {
+				case errors.Is(err, readline.ErrInterrupt):
+					continue
+				case errors.Is(err, io.EOF):
+					return nil
+				case err != nil:
+					return err
+				}
+
+				lineArgs, err := splitArgs(line)
+				if err != nil {
+					cmd.PrintErrln("Error:", err)
+					continue
+				}
+
+				if len(lineArgs) == 0 {
+					continue
+				}
+
+				if lineArgs[0] == "exit" || lineArgs[0] == "quit" {
+					return nil
+				}
+
+				chainCmd, err := newChainCmd()
+				if err != nil {
+					return err
+				}
+
+				chainCmd.SetArgs(lineArgs)
+				// readline owns the terminal, so commands prompting for input
+				// (e.g. transaction confirmations) read through it
+				chainCmd.SetIn(&lineReader{rl: rl})
+				chainCmd.SetOut(rl.Stdout())
+				chainCmd.SetErr(rl.Stderr())
+				// errors are printed by cobra, the session continues
+				_ = chainCmd.Execute()
+			}
+		},
+	}
+}
+
+// lineReader reads lines through readline.
+type lineReader struct {
+	rl  *readline.Instance
+	buf []byte
+}
+
+func (r *lineReader) Read(p []byte) (int, error) {
+	if len(r.buf) == 0 {
+		// the confirmation prompt is printed by the command itself
+		prompt := r.rl.Config.Prompt
+		r.rl.SetPrompt("")
+		line, err := r.rl.Readline()
+		r.rl.SetPrompt(prompt)
+		if err != nil {
+			return 0, err
+		}
+		r.buf = []byte(line + "\n")
+	}
+
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+// replCompleter completes the sub-commands and flags of the chain commands.
+type replCompleter struct {
+	root *cobra.Command
+}
+
+var _ readline.AutoCompleter = replCompleter{}
+
+func (c replCompleter) Do(line []rune, pos int) ([][]rune, int) {
+	args := strings.Fields(string(line[:pos]))
+	partial := ""
+	if pos > 0 && line[pos-1] != ' ' && len(args) > 0 {
+		partial, args = args[len(args)-1], args[:len(args)-1]
+	}
+
+	cmd, _, err := c.root.Find(args)
+	if err != nil {
+		return nil, 0
+	}
+
+	// do not complete the value of a flag
+	if len(args) > 0 {
+		if flag := lookupFlag(cmd, args[len(args)-1]); flag != nil && flag.NoOptDefVal == "" && !strings.Contains(args[len(args)-1], "=") {
+			return nil, 0
+		}
+	}
+
+	var candidates []string
+	if strings.HasPrefix(partial, "-") {
+		// the inherited flags are merged in the flags of the command once they are looked up
+		seen := map[string]bool{}
+		addFlag := func(flag *pflag.Flag) {
+			if !flag.Hidden && !seen[flag.Name] {
+				seen[flag.Name] = true
+				candidates = append(candidates, "--"+flag.Name)
+			}
+		}
+		cmd.Flags().VisitAll(addFlag)
+		cmd.InheritedFlags().VisitAll(addFlag)
+	} else {
+		for _, subCmd := range cmd.Commands() {
+			if subCmd.IsAvailableCommand() {
+				candidates = append(candidates, subCmd.Name())
+			}
+		}
+	}
+	sort.Strings(candidates)
+
+	var res [][]rune
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, partial) {
+			res = append(res, []rune(candidate[len(partial):]+" "))
+		}
+	}
+
+	return res, len([]rune(partial))
+}
+
+// lookupFlag returns the flag of the command referenced by arg, if any.
+func lookupFlag(cmd *cobra.Command, arg string) *pflag.Flag {
+	switch {
+	case strings.HasPrefix(arg, "--"):
+		name, _, _ := strings.Cut(arg[2:], "=")
+		return cmd.Flags().Lookup(name)
+	case strings.HasPrefix(arg, "-") && len(arg) == 2:
+		return cmd.Flags().ShorthandLookup(arg[1:])
+	default:
+		return nil
+	}
+}
+
+// splitArgs splits a line in arguments, honoring single and double quotes.
+func splitArgs(line string) ([]string, error) {
+	var (
+		args    []string
+		current strings.Builder
+		inArg   bool
+		quote   rune
+	)
+
+	for _, r := range line {
+		switch {
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			current.WriteRune(r)
+		case r == '"' || r == '\'':
+			quote, inArg = r, true
+		case r == ' ' || r == '\t':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated quote %c", quote)
+	}
+
+	if inArg {
+		args = append(args, current.String())
+	}
+
+	return args, nil
+}
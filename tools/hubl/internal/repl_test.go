@@ -0,0 +1,160 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+	"gotest.tools/v3/assert"
+)
+
+func TestSplitArgs(t *testing.T) {
+	testCases := []struct {
+		name string
+		line string
+		args []string
+		err  string
+	}{
+		{
+			name: "empty",
+			line: "  ",
+		},
+		{
+			name: "spaces and tabs",
+			line: " bank  send\tfrom   to ",
+			args: []string{"bank", "send", "from", "to"},
+		},
+		{
+			name: "double quotes",
+			line: `tx --note "a note with spaces"`,
+			args: []string{"tx", "--note", "a note with spaces"},
+		},
+		{
+			name: "single quotes",
+			line: `tx --note='it is "quoted"'`,
+			args: []string{"tx", `--note=it is "quoted"`},
+		},
+		{
+			name: "empty quotes",
+			line: `tx --note ""`,
+			args: []string{"tx", "--note", ""},
+		},
+		{
+			name: "adjacent quotes",
+			line: `a"b c"'d'`,
+			args: []string{"ab cd"},
+		},
+		{
+			name: "unterminated quote",
+			line: `tx --note "a note`,
+			err:  `unterminated quote "`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			args, err := splitArgs(tc.line)
+			if tc.err != "" {
+				assert.ErrorContains(t, err, tc.err)
+				return
+			}
+
+			assert.NilError(t, err)
+			assert.DeepEqual(t, args, tc.args)
+		})
+	}
+}
+
+func TestReplCompleter(t *testing.T) {
+	root := &cobra.Command{Use: "chain"}
+	root.PersistentFlags().String("node", "", "")
+	bank := &cobra.Command{Use: "bank"}
+	send := &cobra.Command{Use: "send", Run: func(*cobra.Command, []string) {}}
+	send.Flags().String("fees", "", "")
+	send.Flags().BoolP("yes", "y", false, "")
+	send.Flags().String("secret", "", "")
+	assert.NilError(t, send.Flags().MarkHidden("secret"))
+	balances := &cobra.Command{Use: "balances", Run: func(*cobra.Command, []string) {}}
+	hidden := &cobra.Command{Use: "backdoor", Hidden: true, Run: func(*cobra.Command, []string) {}}
+	bank.AddCommand(send, balances, hidden)
+	staking := &cobra.Command{Use: "staking", Run: func(*cobra.Command, []string) {}}
+	root.AddCommand(bank, staking)
+
+	testCases := []struct {
+		name        string
+		line        string
+		completions []string
+		length      int
+	}{
+		{
+			name:        "sub-commands",
+			line:        "",
+			completions: []string{"bank ", "staking "},
+		},
+		{
+			name:        "partial sub-command",
+			line:        "ba",
+			completions: []string{"nk "},
+			length:      2,
+		},
+		{
+			name:        "nested sub-commands",
+			line:        "bank ",
+			completions: []string{"balances ", "send "},
+		},
+		{
+			name:        "partial nested sub-command",
+			line:        "bank b",
+			completions: []string{"alances "},
+			length:      1,
+		},
+		{
+			name:        "flags",
+			line:        "bank send --",
+			completions: []string{"fees ", "node ", "yes "},
+			length:      2,
+		},
+		{
+			name:        "partial flag",
+			line:        "bank send --f",
+			completions: []string{"ees "},
+			length:      3,
+		},
+		{
+			name: "flag value",
+			line: "bank send --fees ",
+		},
+		{
+			name:        "after a flag value",
+			line:        "bank send --fees 10uatom --y",
+			completions: []string{"es "},
+			length:      3,
+		},
+		{
+			name:        "after a boolean flag",
+			line:        "bank send -y --n",
+			completions: []string{"ode "},
+			length:      3,
+		},
+		{
+			name:   "no match",
+			line:   "gov",
+			length: 3,
+		},
+	}
+
+	// the same commands are completed several times, like in the REPL
+	completer := replCompleter{root: root}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			line := []rune(tc.line)
+			completions, length := completer.Do(line, len(line))
+
+			var got []string
+			for _, completion := range completions {
+				got = append(got, string(completion))
+			}
+			assert.DeepEqual(t, got, tc.completions)
+			assert.Equal(t, length, tc.length)
+		})
+	}
+}
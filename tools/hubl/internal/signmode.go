@@ -0,0 +1,128 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc"
+
+	signingv1beta1 "cosmossdk.io/api/cosmos/tx/signing/v1beta1"
+	"cosmossdk.io/tools/hubl/internal/flags"
+	txsigning "cosmossdk.io/x/tx/signing"
+	"cosmossdk.io/x/tx/signing/aminojson"
+	"cosmossdk.io/x/tx/signing/direct"
+	"cosmossdk.io/x/tx/signing/textual"
+)
+
+// SignModeHandlerFactory builds the handler of a sign mode for a chain. The handler
+// resolves the messages with the chain descriptors, and can query the chain through
+// conn, which is nil when the transaction is built offline.
+type SignModeHandlerFactory func(chainInfo *ChainInfo, conn grpc.ClientConnInterface) (txsigning.SignModeHandler, error)
+
+// signModeHandlerFactories are the sign modes hubl can sign with.
+var signModeHandlerFactories = map[signingv1beta1.SignMode]SignModeHandlerFactory{
+	signingv1beta1.SignMode_SIGN_MODE_DIRECT: func(*ChainInfo, grpc.ClientConnInterface) (txsigning.SignModeHandler, error) {
+		return direct.SignModeHandler{}, nil
+	},
+	signingv1beta1.SignMode_SIGN_MODE_LEGACY_AMINO_JSON: func(chainInfo *ChainInfo, _ grpc.ClientConnInterface) (txsigning.SignModeHandler, error) {
+		return aminojson.NewSignModeHandler(aminojson.SignModeHandlerOptions{
+			FileResolver: chainInfo.ProtoFiles,
+			TypeResolver: dynamicTypeResolver{chainInfo},
+		}), nil
+	},
+	signingv1beta1.SignMode_SIGN_MODE_TEXTUAL: func(chainInfo *ChainInfo, conn grpc.ClientConnInterface) (txsigning.SignModeHandler, error) {
+		return textual.NewSignModeHandler(textual.SignModeOptions{
+			CoinMetadataQuerier: coinMetadataQuerier(conn),
+			FileResolver:        chainInfo.ProtoFiles,
+			TypeResolver:        dynamicTypeResolver{chainInfo},
+		})
+	},
+}
+
+// RegisterSignModeHandler registers the handler of a sign mode, such as a custom sign
+// mode of a chain, so that the transactions of the chains advertising it can be signed
+// with it. It must be called before the commands are built.
+func RegisterSignModeHandler(signMode signingv1beta1.SignMode, factory SignModeHandlerFactory) {
+	signModeHandlerFactories[signMode] = factory
+}
+
+// signModeHandlers returns the handlers of the sign modes the transactions of the chain
+// can be signed with: the registered sign modes advertised by the chain, or all of them
+// if the chain doesn't advertise its sign modes.
+func signModeHandlers(chainInfo *ChainInfo) map[signingv1beta1.SignMode]SignModeHandlerFactory {
+	if len(chainInfo.SignModes) == 0 {
+		return signModeHandlerFactories
+	}
+
+	handlers := make(map[signingv1beta1.SignMode]SignModeHandlerFactory, len(chainInfo.SignModes))
+	for _, signMode := range chainInfo.SignModes {
+		if factory, ok := signModeHandlerFactories[signMode]; ok {
+			handlers[signMode] = factory
+		}
+	}
+
+	return handlers
+}
+
+// sortedSignModes returns the sign modes of the handlers sorted by number, so that
+// SIGN_MODE_DIRECT comes first.
+func sortedSignModes(handlers map[signingv1beta1.SignMode]SignModeHandlerFactory) []signingv1beta1.SignMode {
+	signModes := make([]signingv1beta1.SignMode, 0, len(handlers))
+	for signMode := range handlers {
+		signModes = append(signModes, signMode)
+	}
+	sort.Slice(signModes, func(i, j int) bool { return signModes[i] < signModes[j] })
+
+	return signModes
+}
+
+// signModeFlagValue returns the --sign-mode flag value of the given sign mode, e.g.
+// "direct" for SIGN_MODE_DIRECT. Custom sign modes, unknown to the SignMode enum, are
+// returned as their number.
+func signModeFlagValue(signMode signingv1beta1.SignMode) string {
+	if signMode == signingv1beta1.SignMode_SIGN_MODE_LEGACY_AMINO_JSON {
+		return flags.SignModeAminoJSON
+	}
+
+	name, ok := signingv1beta1.SignMode_name[int32(signMode)]
+	if !ok {
+		return strconv.Itoa(int(signMode))
+	}
+
+	return strings.ReplaceAll(strings.ToLower(strings.TrimPrefix(name, "SIGN_MODE_")), "_", "-")
+}
+
+// signModeUsage returns the usage of the --sign-mode flag, listing the given sign modes.
+func signModeUsage(signModes []signingv1beta1.SignMode) string {
+	values := make([]string, 0, len(signModes))
+	for _, signMode := range signModes {
+		values = append(values, signModeFlagValue(signMode))
+	}
+
+	return fmt.Sprintf("Choose sign mode (%s)", strings.Join(values, "|"))
+}
+
+// parseSignModeFlag parses a --sign-mode flag value into one of the given sign modes.
+// It accepts the flag value returned by signModeFlagValue or the enum name (e.g.
+// SIGN_MODE_DIRECT).
+func parseSignModeFlag(value string, signModes []signingv1beta1.SignMode) (signingv1beta1.SignMode, error) {
+	if len(signModes) == 0 {
+		return signingv1beta1.SignMode_SIGN_MODE_UNSPECIFIED, errors.New("hubl can't sign with any of the sign modes advertised by the chain")
+	}
+
+	for _, signMode := range signModes {
+		if value == signModeFlagValue(signMode) || value == signMode.String() {
+			return signMode, nil
+		}
+	}
+
+	values := make([]string, 0, len(signModes))
+	for _, signMode := range signModes {
+		values = append(values, signModeFlagValue(signMode))
+	}
+
+	return signingv1beta1.SignMode_SIGN_MODE_UNSPECIFIED, fmt.Errorf("invalid sign mode %q, expected one of %s", value, strings.Join(values, ", "))
+}
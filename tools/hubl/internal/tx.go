@@ -0,0 +1,732 @@
+package internal
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cosmos/cosmos-proto/anyutil"
+	"github.com/iancoleman/strcase"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	grpcstatus "google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+	"google.golang.org/protobuf/types/known/anypb"
+	"sigs.k8s.io/yaml"
+
+	authv1beta1 "cosmossdk.io/api/cosmos/auth/v1beta1"
+	autocliv1 "cosmossdk.io/api/cosmos/autocli/v1"
+	bankv1beta1 "cosmossdk.io/api/cosmos/bank/v1beta1"
+	cmtv1beta1 "cosmossdk.io/api/cosmos/base/tendermint/v1beta1"
+	basev1beta1 "cosmossdk.io/api/cosmos/base/v1beta1"
+	signingv1beta1 "cosmossdk.io/api/cosmos/tx/signing/v1beta1"
+	txv1beta1 "cosmossdk.io/api/cosmos/tx/v1beta1"
+	"cosmossdk.io/client/v2/autocli/flag"
+	sdkmath "cosmossdk.io/math"
+	"cosmossdk.io/tools/hubl/internal/flags"
+	txsigning "cosmossdk.io/x/tx/signing"
+	"cosmossdk.io/x/tx/signing/textual"
+
+	"github.com/cosmos/cosmos-sdk/client/input"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	"github.com/cosmos/cosmos-sdk/crypto/keyring"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	signingtypes "github.com/cosmos/cosmos-sdk/types/tx/signing"
+)
+
+// txCommandBuilder builds transaction commands for the Msg services of a chain.
+// Transactions are built from the chain descriptors, signed with the sign mode
+// set by --sign-mode using the chain keyring and broadcast through the chain
+// gRPC endpoint, so that the chain binary is not needed.
+type txCommandBuilder struct {
+	chainInfo *ChainInfo
+	builder   *flag.Builder
+	keyring   keyring.Keyring
+}
+
+// TxCommand returns the tx command of a chain, with a sub-command for each Msg
+// service method discovered through reflection.
+func TxCommand(chainInfo *ChainInfo, builder *flag.Builder, kr keyring.Keyring) (*cobra.Command, error) {
+	b := &txCommandBuilder{chainInfo: chainInfo, builder: builder, keyring: kr}
+
+	cmd := &cobra.Command{
+		Use:   "tx",
+		Short: "Transaction subcommands",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
+	}
+
+	moduleNames := make([]string, 0, len(chainInfo.ModuleOptions))
+	for name, modOpts := range chainInfo.ModuleOptions {
+		if modOpts != nil && modOpts.Tx != nil {
+			moduleNames = append(moduleNames, name)
+		}
+	}
+	sort.Strings(moduleNames)
+
+	for _, moduleName := range moduleNames {
+		moduleCmd := &cobra.Command{
+			Use:   moduleName,
+			Short: fmt.Sprintf("Transactions commands for the %s module", moduleName),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return cmd.Help()
+			},
+		}
+
+		if err := b.addMsgServiceCommands(moduleCmd, chainInfo.ModuleOptions[moduleName].Tx); err != nil {
+			return nil, fmt.Errorf("failed to build the %s transactions commands: %w", moduleName, err)
+		}
+
+		if moduleCmd.HasSubCommands() {
+			cmd.AddCommand(moduleCmd)
+		}
+	}
+
+	return cmd, nil
+}
+
+func (b *txCommandBuilder) addMsgServiceCommands(cmd *cobra.Command, cmdDescriptor *autocliv1.ServiceCommandDescriptor) error {
+	for cmdName, subCmdDescriptor := range cmdDescriptor.SubCommands {
+		subCmd := &cobra.Command{
+			Use:   cmdName,
+			Short: fmt.Sprintf("Tx commands for the %s service", subCmdDescriptor.Service),
+		}
+		if err := b.addMsgServiceCommands(subCmd, subCmdDescriptor); err != nil {
+			return err
+		}
+
+		cmd.AddCommand(subCmd)
+	}
+
+	if cmdDescriptor.Service == "" {
+		return nil
+	}
+
+	descriptor, err := b.chainInfo.ProtoFiles.FindDescriptorByName(protoreflect.FullName(cmdDescriptor.Service))
+	if err != nil {
+		return fmt.Errorf("can't find service %s: %w", cmdDescriptor.Service, err)
+	}
+	methods := descriptor.(protoreflect.ServiceDescriptor).Methods()
+
+	rpcOptMap := map[protoreflect.Name]*autocliv1.RpcCommandOptions{}
+	for _, option := range cmdDescriptor.RpcCommandOptions {
+		rpcOptMap[protoreflect.Name(option.RpcMethod)] = option
+	}
+
+	for i := 0; i < methods.Len(); i++ {
+		method := methods.Get(i)
+		options, ok := rpcOptMap[method.Name()]
+		if !ok {
+			options = &autocliv1.RpcCommandOptions{}
+		}
+
+		if options.Skip {
+			continue
+		}
+
+		methodCmd, err := b.buildMsgMethodCommand(method, options)
+		if err != nil {
+			return err
+		}
+
+		cmd.AddCommand(methodCmd)
+	}
+
+	return nil
+}
+
+func (b *txCommandBuilder) buildMsgMethodCommand(method protoreflect.MethodDescriptor, options *autocliv1.RpcCommandOptions) (*cobra.Command, error) {
+	use := options.Use
+	if use == "" {
+		use = strcase.ToKebab(string(method.Name()))
+	}
+
+	short := options.Short
+	if short == "" {
+		short = fmt.Sprintf("Execute the %s RPC method", method.Name())
+	}
+
+	long := options.Long
+	if long == "" {
+		long = strings.TrimSpace(method.ParentFile().SourceLocations().ByDescriptor(method).LeadingComments)
+	}
+
+	cmd := &cobra.Command{
+		Use:          use,
+		Short:        short,
+		Long:         long,
+		Example:      options.Example,
+		Aliases:      options.Alias,
+		Deprecated:   options.Deprecated,
+		SilenceUsage: true,
+	}
+
+	binder, err := b.builder.AddMessageFlags(context.Background(), cmd.Flags(), dynamicpb.NewMessageType(method.Input()), options)
+	if err != nil {
+		return nil, err
+	}
+	cmd.Args = binder.CobraArgs
+
+	// the message binder adds the from flag when the signer is not a positional argument
+	if cmd.Flags().Lookup(flags.FlagFrom) == nil {
+		cmd.Flags().StringP(flags.FlagFrom, "f", "", "Name or address with which to sign the message")
+	}
+	addTxFlags(cmd, sortedSignModes(signModeHandlers(b.chainInfo)))
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		msg, err := binder.BuildMessage(args)
+		if err != nil {
+			return err
+		}
+
+		from, err := cmd.Flags().GetString(flags.FlagFrom)
+		if err != nil {
+			return err
+		}
+
+		// the signer can be given as a positional argument or a custom flag
+		switch {
+		case binder.SignerInfo.FieldName == "" || binder.SignerInfo.FieldName == flags.FlagFrom:
+		case binder.SignerInfo.IsFlag:
+			if from, err = cmd.Flags().GetString(binder.SignerInfo.FieldName); err != nil {
+				return err
+			}
+		default:
+			from = args[binder.SignerInfo.PositionalArgIndex]
+		}
+
+		if from == "" {
+			return fmt.Errorf("the --%s flag is required to sign transactions", flags.FlagFrom)
+		}
+
+		return b.generateOrBroadcastTx(cmd, from, msg)
+	}
+
+	return cmd, nil
+}
+
+// addTxFlags adds the transaction flags to cmd, the --sign-mode flag accepting the
+// given sign modes and defaulting to the first one.
+func addTxFlags(cmd *cobra.Command, signModes []signingv1beta1.SignMode) {
+	f := cmd.Flags()
+	f.String(flags.FlagChainID, "", "The chain ID, queried from the node if not set")
+	f.String(flags.FlagFees, "", "Fees to pay along with the transaction, e.g. 10uatom")
+	f.String(flags.FlagGasPrices, "", "Gas prices to determine the transaction fee, e.g. 0.025uatom")
+	f.String(flags.FlagGas, strconv.Itoa(flags.DefaultGasLimit), fmt.Sprintf("Gas limit to set per transaction, or %q to estimate it by simulating the transaction", flags.GasFlagAuto))
+	f.Float64(flags.FlagGasAdjustment, flags.DefaultGasAdjustment, "Factor applied to the simulated gas when the gas limit is estimated")
+	f.String(flags.FlagNote, "", "Note to add a description to the transaction (memo)")
+	f.Uint64(flags.FlagTimeoutHeight, 0, "Block height after which the transaction is not accepted anymore")
+	f.Uint64(flags.FlagAccountNumber, 0, "The account number of the signing account, queried from the node if not set")
+	f.Uint64(flags.FlagSequence, 0, "The sequence number of the signing account, queried from the node if not set")
+	f.Bool(flags.FlagGenerateOnly, false, "Print the unsigned transaction instead of signing and broadcasting it. The signer can be an address which is not in the keyring, and the transaction is generated offline if --account-number and --sequence are set")
+	defaultSignMode := ""
+	if len(signModes) > 0 {
+		defaultSignMode = signModeFlagValue(signModes[0])
+	}
+	f.String(flags.FlagSignMode, defaultSignMode, signModeUsage(signModes))
+	f.Bool(flags.FlagDryRun, false, "Simulate the transaction and print the estimated gas, without broadcasting it")
+	f.BoolP(flags.FlagYes, "y", false, "Skip the transaction confirmation before signing and broadcasting")
+}
+
+// generateOrBroadcastTx builds a transaction for the given message and either
+// prints it, simulates it, or signs and broadcasts it, according to the flags.
+func (b *txCommandBuilder) generateOrBroadcastTx(cmd *cobra.Command, from string, msg protoreflect.Message) error {
+	ctx := cmd.Context()
+	generateOnly, _ := cmd.Flags().GetBool(flags.FlagGenerateOnly)
+	signMode, err := b.parseSignMode(cmd)
+	if err != nil {
+		return err
+	}
+
+	// an unsigned transaction can be generated for an address which is not in the keyring
+	key, signerAddr, err := b.getSigner(from, generateOnly)
+	if err != nil {
+		return err
+	}
+
+	signer, err := b.builder.AddressCodec.BytesToString(signerAddr)
+	if err != nil {
+		return err
+	}
+
+	if err := b.setSignerField(msg, signerAddr); err != nil {
+		return err
+	}
+
+	anyMsg, err := anyutil.New(msg.Interface())
+	if err != nil {
+		return err
+	}
+
+	var signerPubKey *anypb.Any
+	if key != nil {
+		pubKey, err := key.GetPubKey()
+		if err != nil {
+			return err
+		}
+
+		pubKeyAny, err := codectypes.NewAnyWithValue(pubKey)
+		if err != nil {
+			return err
+		}
+		signerPubKey = &anypb.Any{TypeUrl: pubKeyAny.TypeUrl, Value: pubKeyAny.Value}
+	}
+
+	memo, _ := cmd.Flags().GetString(flags.FlagNote)
+	timeoutHeight, _ := cmd.Flags().GetUint64(flags.FlagTimeoutHeight)
+	body := &txv1beta1.TxBody{
+		Messages:      []*anypb.Any{anyMsg},
+		Memo:          memo,
+		TimeoutHeight: timeoutHeight,
+	}
+
+	accountNumber, sequence, err := b.getAccountNumberSequence(cmd, signer)
+	if err != nil {
+		return err
+	}
+
+	authInfo := &txv1beta1.AuthInfo{
+		SignerInfos: []*txv1beta1.SignerInfo{{
+			PublicKey: signerPubKey,
+			ModeInfo: &txv1beta1.ModeInfo{
+				Sum: &txv1beta1.ModeInfo_Single_{
+					Single: &txv1beta1.ModeInfo_Single{Mode: signMode},
+				},
+			},
+			Sequence: sequence,
+		}},
+		Fee: &txv1beta1.Fee{},
+	}
+
+	gasLimit, simulate, err := parseGas(cmd)
+	if err != nil {
+		return err
+	}
+
+	dryRun, _ := cmd.Flags().GetBool(flags.FlagDryRun)
+	if simulate || dryRun {
+		conn, err := b.chainInfo.OpenClient()
+		if err != nil {
+			return err
+		}
+
+		gasUsed, err := simulateTx(ctx, conn, body, authInfo)
+		if err != nil {
+			return fmt.Errorf("failed to simulate the transaction: %w", err)
+		}
+
+		gasAdjustment, _ := cmd.Flags().GetFloat64(flags.FlagGasAdjustment)
+		gasLimit = uint64(gasAdjustment * float64(gasUsed))
+
+		if dryRun {
+			_, err = fmt.Fprintf(cmd.ErrOrStderr(), "gas estimate: %d\n", gasLimit)
+			return err
+		}
+	}
+
+	authInfo.Fee.GasLimit = gasLimit
+	if authInfo.Fee.Amount, err = parseFees(cmd, gasLimit); err != nil {
+		return err
+	}
+
+	unsignedTx := &txv1beta1.Tx{Body: body, AuthInfo: authInfo, Signatures: [][]byte{}}
+	if generateOnly {
+		return b.printMessage(cmd, unsignedTx)
+	}
+
+	if yes, _ := cmd.Flags().GetBool(flags.FlagYes); !yes {
+		bz, err := b.marshalJSON(unsignedTx)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(cmd.ErrOrStderr(), "%s\n\n", bz); err != nil {
+			return err
+		}
+
+		ok, err := input.GetConfirmation("confirm transaction before signing and broadcasting", bufio.NewReader(cmd.InOrStdin()), cmd.ErrOrStderr())
+		if err != nil || !ok {
+			_, _ = fmt.Fprintln(cmd.ErrOrStderr(), "canceled transaction")
+			return err
+		}
+	}
+
+	conn, err := b.chainInfo.OpenClient()
+	if err != nil {
+		return err
+	}
+
+	chainID, err := b.getChainID(cmd, conn)
+	if err != nil {
+		return err
+	}
+
+	signerData := txsigning.SignerData{
+		Address:       signer,
+		ChainID:       chainID,
+		AccountNumber: accountNumber,
+		Sequence:      sequence,
+		PubKey:        signerPubKey,
+	}
+	txRaw, err := b.signTx(ctx, conn, key, signMode, body, authInfo, signerData)
+	if err != nil {
+		return err
+	}
+
+	txBytes, err := proto.MarshalOptions{Deterministic: true}.Marshal(txRaw)
+	if err != nil {
+		return err
+	}
+
+	res, err := txv1beta1.NewServiceClient(conn).BroadcastTx(ctx, &txv1beta1.BroadcastTxRequest{
+		TxBytes: txBytes,
+		Mode:    txv1beta1.BroadcastMode_BROADCAST_MODE_SYNC,
+	})
+	if err != nil {
+		return err
+	}
+
+	return b.printMessage(cmd, res.TxResponse)
+}
+
+// getKey returns the keyring record of the given key name or address.
+func (b *txCommandBuilder) getKey(from string) (*keyring.Record, error) {
+	if record, err := b.keyring.Key(from); err == nil {
+		return record, nil
+	}
+
+	addr, err := b.builder.AddressCodec.StringToBytes(from)
+	if err != nil {
+		return nil, fmt.Errorf("key %s not found in the %s keyring", from, b.chainInfo.Chain)
+	}
+
+	return b.keyring.KeyByAddress(sdk.AccAddress(addr))
+}
+
+// getSigner returns the keyring record and the address of the given key name
+// or address. When generating an unsigned transaction, the signer can be an
+// address which is not in the keyring, in which case the record is nil.
+func (b *txCommandBuilder) getSigner(from string, generateOnly bool) (*keyring.Record, []byte, error) {
+	key, err := b.getKey(from)
+	if err != nil {
+		if !generateOnly {
+			return nil, nil, err
+		}
+
+		addr, addrErr := b.builder.AddressCodec.StringToBytes(from)
+		if addrErr != nil {
+			return nil, nil, err
+		}
+
+		return nil, addr, nil
+	}
+
+	addr, err := key.GetAddress()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return key, addr, nil
+}
+
+// parseSignMode returns the sign mode set in the flags, which must be one of the
+// sign modes the transactions of the chain can be signed with.
+func (b *txCommandBuilder) parseSignMode(cmd *cobra.Command) (signingv1beta1.SignMode, error) {
+	signMode, _ := cmd.Flags().GetString(flags.FlagSignMode)
+	return parseSignModeFlag(signMode, sortedSignModes(signModeHandlers(b.chainInfo)))
+}
+
+// setSignerField sets the signer field of the message to the signing key
+// address if it is not set.
+func (b *txCommandBuilder) setSignerField(msg protoreflect.Message, addr []byte) error {
+	fieldName := flag.GetSignerFieldName(msg.Descriptor())
+	if fieldName == "" {
+		return nil
+	}
+
+	fd := msg.Descriptor().Fields().ByName(protoreflect.Name(fieldName))
+	if fd == nil || fd.Kind() != protoreflect.StringKind || msg.Get(fd).String() != "" {
+		return nil
+	}
+
+	addressCodec := b.builder.AddressCodec
+	if scalarType, ok := flag.GetScalarType(fd); ok {
+		switch scalarType {
+		case flag.ValidatorAddressStringScalarType:
+			addressCodec = b.builder.ValidatorAddressCodec
+		case flag.ConsensusAddressStringScalarType:
+			addressCodec = b.builder.ConsensusAddressCodec
+		}
+	}
+
+	signer, err := addressCodec.BytesToString(addr)
+	if err != nil {
+		return fmt.Errorf("failed to set signer on message: %w", err)
+	}
+
+	msg.Set(fd, protoreflect.ValueOfString(signer))
+	return nil
+}
+
+// getAccountNumberSequence returns the account number and sequence from the
+// flags, or queries them from the node.
+func (b *txCommandBuilder) getAccountNumberSequence(cmd *cobra.Command, address string) (uint64, uint64, error) {
+	accountNumber, _ := cmd.Flags().GetUint64(flags.FlagAccountNumber)
+	sequence, _ := cmd.Flags().GetUint64(flags.FlagSequence)
+	if cmd.Flags().Changed(flags.FlagAccountNumber) && cmd.Flags().Changed(flags.FlagSequence) {
+		return accountNumber, sequence, nil
+	}
+
+	conn, err := b.chainInfo.OpenClient()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	res, err := authv1beta1.NewQueryClient(conn).Account(cmd.Context(), &authv1beta1.QueryAccountRequest{Address: address})
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to query account %s, it must exist on chain to sign transactions, or --%s and --%s must be set: %w", address, flags.FlagAccountNumber, flags.FlagSequence, err)
+	}
+
+	account, err := anyutil.Unpack(res.Account, b.chainInfo.ProtoFiles, dynamicTypeResolver{b.chainInfo})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	queriedAccountNumber, queriedSequence, ok := accountNumberSequence(account.ProtoReflect())
+	if !ok {
+		return 0, 0, fmt.Errorf("account %s of type %s has no account number and sequence", address, res.Account.TypeUrl)
+	}
+
+	if !cmd.Flags().Changed(flags.FlagAccountNumber) {
+		accountNumber = queriedAccountNumber
+	}
+	if !cmd.Flags().Changed(flags.FlagSequence) {
+		sequence = queriedSequence
+	}
+
+	return accountNumber, sequence, nil
+}
+
+// accountNumberSequence returns the account number and sequence of an account,
+// looking into the base account embedded in e.g. vesting accounts.
+func accountNumberSequence(account protoreflect.Message) (uint64, uint64, bool) {
+	fields := account.Descriptor().Fields()
+	accountNumberField, sequenceField := fields.ByName("account_number"), fields.ByName("sequence")
+	if accountNumberField != nil && sequenceField != nil {
+		return account.Get(accountNumberField).Uint(), account.Get(sequenceField).Uint(), true
+	}
+
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		if fd.Kind() != protoreflect.MessageKind || fd.IsList() || fd.IsMap() || !account.Has(fd) {
+			continue
+		}
+
+		if accountNumber, sequence, ok := accountNumberSequence(account.Get(fd).Message()); ok {
+			return accountNumber, sequence, true
+		}
+	}
+
+	return 0, 0, false
+}
+
+// getChainID returns the chain ID from the flags, or queries it from the node.
+func (b *txCommandBuilder) getChainID(cmd *cobra.Command, conn *grpc.ClientConn) (string, error) {
+	if chainID, _ := cmd.Flags().GetString(flags.FlagChainID); chainID != "" {
+		return chainID, nil
+	}
+
+	res, err := cmtv1beta1.NewServiceClient(conn).GetNodeInfo(cmd.Context(), &cmtv1beta1.GetNodeInfoRequest{})
+	if err != nil {
+		return "", fmt.Errorf("failed to query the chain ID, use the --%s flag to set it: %w", flags.FlagChainID, err)
+	}
+
+	if chainID := res.DefaultNodeInfo.GetNetwork(); chainID != "" {
+		return chainID, nil
+	}
+
+	return "", fmt.Errorf("the node did not return a chain ID, use the --%s flag to set it", flags.FlagChainID)
+}
+
+// signTx signs the transaction with the given sign mode.
+func (b *txCommandBuilder) signTx(ctx context.Context, conn *grpc.ClientConn, key *keyring.Record, signMode signingv1beta1.SignMode, body *txv1beta1.TxBody, authInfo *txv1beta1.AuthInfo, signerData txsigning.SignerData) (*txv1beta1.TxRaw, error) {
+	marshalOpts := proto.MarshalOptions{Deterministic: true}
+	bodyBytes, err := marshalOpts.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	authInfoBytes, err := marshalOpts.Marshal(authInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	handler, err := b.signModeHandler(conn, signMode)
+	if err != nil {
+		return nil, err
+	}
+
+	signBytes, err := handler.GetSignBytes(ctx, signerData, txsigning.TxData{
+		Body:          body,
+		AuthInfo:      authInfo,
+		BodyBytes:     bodyBytes,
+		AuthInfoBytes: authInfoBytes,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	signature, _, err := b.keyring.Sign(key.Name, signBytes, signingtypes.SignMode(signMode))
+	if err != nil {
+		return nil, err
+	}
+
+	return &txv1beta1.TxRaw{
+		BodyBytes:     bodyBytes,
+		AuthInfoBytes: authInfoBytes,
+		Signatures:    [][]byte{signature},
+	}, nil
+}
+
+// signModeHandler returns the handler of the given sign mode, which resolves
+// the messages with the chain descriptors.
+func (b *txCommandBuilder) signModeHandler(conn grpc.ClientConnInterface, signMode signingv1beta1.SignMode) (txsigning.SignModeHandler, error) {
+	factory, ok := signModeHandlers(b.chainInfo)[signMode]
+	if !ok {
+		return nil, fmt.Errorf("unsupported sign mode %s", signMode)
+	}
+
+	return factory(b.chainInfo, conn)
+}
+
+// coinMetadataQuerier queries the metadata of the coins rendered by the
+// textual sign mode from the node. Coins without metadata are rendered with
+// their base denom.
+func coinMetadataQuerier(conn grpc.ClientConnInterface) textual.CoinMetadataQueryFn {
+	return func(ctx context.Context, denom string) (*bankv1beta1.Metadata, error) {
+		res, err := bankv1beta1.NewQueryClient(conn).DenomMetadata(ctx, &bankv1beta1.QueryDenomMetadataRequest{Denom: denom})
+		if err != nil {
+			if grpcstatus.Code(err) == codes.NotFound {
+				return nil, nil
+			}
+			return nil, err
+		}
+
+		return res.Metadata, nil
+	}
+}
+
+// simulateTx simulates the transaction and returns the gas it used.
+func simulateTx(ctx context.Context, conn *grpc.ClientConn, body *txv1beta1.TxBody, authInfo *txv1beta1.AuthInfo) (uint64, error) {
+	marshalOpts := proto.MarshalOptions{Deterministic: true}
+	bodyBytes, err := marshalOpts.Marshal(body)
+	if err != nil {
+		return 0, err
+	}
+
+	authInfoBytes, err := marshalOpts.Marshal(authInfo)
+	if err != nil {
+		return 0, err
+	}
+
+	// simulation does not verify signatures, but requires one per signer
+	txBytes, err := marshalOpts.Marshal(&txv1beta1.TxRaw{
+		BodyBytes:     bodyBytes,
+		AuthInfoBytes: authInfoBytes,
+		Signatures:    [][]byte{{}},
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	res, err := txv1beta1.NewServiceClient(conn).Simulate(ctx, &txv1beta1.SimulateRequest{TxBytes: txBytes})
+	if err != nil {
+		return 0, err
+	}
+
+	return res.GasInfo.GetGasUsed(), nil
+}
+
+// parseGas returns the gas limit set in the flags, or whether it must be
+// estimated by simulating the transaction.
+func parseGas(cmd *cobra.Command) (uint64, bool, error) {
+	gas, _ := cmd.Flags().GetString(flags.FlagGas)
+	if gas == flags.GasFlagAuto {
+		return 0, true, nil
+	}
+
+	gasLimit, err := strconv.ParseUint(gas, 10, 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid gas limit %q, expected a number or %q: %w", gas, flags.GasFlagAuto, err)
+	}
+
+	return gasLimit, false, nil
+}
+
+// parseFees returns the fees set in the flags, or computes them from the gas
+// prices and the gas limit.
+func parseFees(cmd *cobra.Command, gasLimit uint64) ([]*basev1beta1.Coin, error) {
+	fees, _ := cmd.Flags().GetString(flags.FlagFees)
+	gasPrices, _ := cmd.Flags().GetString(flags.FlagGasPrices)
+	if fees != "" && gasPrices != "" {
+		return nil, fmt.Errorf("cannot provide both --%s and --%s", flags.FlagFees, flags.FlagGasPrices)
+	}
+
+	var coins sdk.Coins
+	if fees != "" {
+		var err error
+		if coins, err = sdk.ParseCoinsNormalized(fees); err != nil {
+			return nil, fmt.Errorf("invalid fees %q: %w", fees, err)
+		}
+	}
+
+	if gasPrices != "" {
+		prices, err := sdk.ParseDecCoins(gasPrices)
+		if err != nil {
+			return nil, fmt.Errorf("invalid gas prices %q: %w", gasPrices, err)
+		}
+
+		gas := sdkmath.LegacyNewDecFromInt(sdkmath.NewIntFromUint64(gasLimit))
+		for _, price := range prices {
+			coins = coins.Add(sdk.NewCoin(price.Denom, price.Amount.Mul(gas).Ceil().RoundInt()))
+		}
+	}
+
+	res := make([]*basev1beta1.Coin, len(coins))
+	for i, coin := range coins {
+		res[i] = &basev1beta1.Coin{Denom: coin.Denom, Amount: coin.Amount.String()}
+	}
+
+	return res, nil
+}
+
+func (b *txCommandBuilder) marshalJSON(msg proto.Message) ([]byte, error) {
+	return protojson.MarshalOptions{
+		Resolver: dynamicTypeResolver{b.chainInfo},
+		Indent:   "  ",
+	}.Marshal(msg)
+}
+
+// printMessage prints a message in the output format set in the flags.
+func (b *txCommandBuilder) printMessage(cmd *cobra.Command, msg proto.Message) error {
+	bz, err := b.marshalJSON(msg)
+	if err != nil {
+		return err
+	}
+
+	if output, _ := cmd.Flags().GetString(flags.FlagOutput); output == flags.OutputFormatText {
+		if bz, err = yaml.JSONToYAML(bz); err != nil {
+			return err
+		}
+	}
+
+	_, err = fmt.Fprintln(cmd.OutOrStdout(), string(bz))
+	return err
+}
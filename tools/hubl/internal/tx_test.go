@@ -0,0 +1,399 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/cosmos/cosmos-proto/anyutil"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/known/anypb"
+	"gotest.tools/v3/assert"
+
+	authv1beta1 "cosmossdk.io/api/cosmos/auth/v1beta1"
+	bankv1beta1 "cosmossdk.io/api/cosmos/bank/v1beta1"
+	basev1beta1 "cosmossdk.io/api/cosmos/base/v1beta1"
+	signingv1beta1 "cosmossdk.io/api/cosmos/tx/signing/v1beta1"
+	txv1beta1 "cosmossdk.io/api/cosmos/tx/v1beta1"
+	vestingv1beta1 "cosmossdk.io/api/cosmos/vesting/v1beta1"
+	"cosmossdk.io/client/v2/autocli/flag"
+	"cosmossdk.io/tools/hubl/internal/config"
+	"cosmossdk.io/tools/hubl/internal/flags"
+	txsigning "cosmossdk.io/x/tx/signing"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	cryptocodec "github.com/cosmos/cosmos-sdk/crypto/codec"
+	"github.com/cosmos/cosmos-sdk/crypto/hd"
+	"github.com/cosmos/cosmos-sdk/crypto/keyring"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// newTxCmd returns a command with the tx flags, parsed from args.
+func newTxCmd(t *testing.T, args ...string) *cobra.Command {
+	t.Helper()
+
+	cmd := &cobra.Command{}
+	addTxFlags(cmd, sortedSignModes(signModeHandlerFactories))
+	assert.NilError(t, cmd.Flags().Parse(args))
+	return cmd
+}
+
+func TestParseGas(t *testing.T) {
+	testCases := []struct {
+		name     string
+		args     []string
+		gasLimit uint64
+		simulate bool
+		err      string
+	}{
+		{
+			name:     "default",
+			gasLimit: 200000,
+		},
+		{
+			name:     "limit",
+			args:     []string{"--gas", "150000"},
+			gasLimit: 150000,
+		},
+		{
+			name:     "auto",
+			args:     []string{"--gas", "auto"},
+			simulate: true,
+		},
+		{
+			name: "invalid",
+			args: []string{"--gas", "lots"},
+			err:  `invalid gas limit "lots", expected a number or "auto"`,
+		},
+		{
+			name: "negative",
+			args: []string{"--gas", "-1"},
+			err:  `invalid gas limit "-1"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gasLimit, simulate, err := parseGas(newTxCmd(t, tc.args...))
+			if tc.err != "" {
+				assert.ErrorContains(t, err, tc.err)
+				return
+			}
+
+			assert.NilError(t, err)
+			assert.Equal(t, gasLimit, tc.gasLimit)
+			assert.Equal(t, simulate, tc.simulate)
+		})
+	}
+}
+
+func TestParseFees(t *testing.T) {
+	testCases := []struct {
+		name     string
+		args     []string
+		gasLimit uint64
+		fees     []*basev1beta1.Coin
+		err      string
+	}{
+		{
+			name: "none",
+			fees: []*basev1beta1.Coin{},
+		},
+		{
+			name: "fees",
+			args: []string{"--fees", "10uatom,5stake"},
+			fees: []*basev1beta1.Coin{{Denom: "stake", Amount: "5"}, {Denom: "uatom", Amount: "10"}},
+		},
+		{
+			name:     "gas prices",
+			args:     []string{"--gas-prices", "0.025uatom"},
+			gasLimit: 200000,
+			fees:     []*basev1beta1.Coin{{Denom: "uatom", Amount: "5000"}},
+		},
+		{
+			name:     "gas prices rounded up",
+			args:     []string{"--gas-prices", "0.025uatom,0.1stake"},
+			gasLimit: 15,
+			fees:     []*basev1beta1.Coin{{Denom: "stake", Amount: "2"}, {Denom: "uatom", Amount: "1"}},
+		},
+		{
+			name: "fees and gas prices",
+			args: []string{"--fees", "10uatom", "--gas-prices", "0.025uatom"},
+			err:  "cannot provide both --fees and --gas-prices",
+		},
+		{
+			name: "invalid fees",
+			args: []string{"--fees", "10"},
+			err:  `invalid fees "10"`,
+		},
+		{
+			name: "invalid gas prices",
+			args: []string{"--gas-prices", "uatom"},
+			err:  `invalid gas prices "uatom"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fees, err := parseFees(newTxCmd(t, tc.args...), tc.gasLimit)
+			if tc.err != "" {
+				assert.ErrorContains(t, err, tc.err)
+				return
+			}
+
+			assert.NilError(t, err)
+			assert.Equal(t, len(fees), len(tc.fees))
+			for i := range fees {
+				assert.Assert(t, proto.Equal(fees[i], tc.fees[i]), "got %v, expected %v", fees[i], tc.fees[i])
+			}
+		})
+	}
+}
+
+func TestAccountNumberSequence(t *testing.T) {
+	baseAccount := &authv1beta1.BaseAccount{Address: "cosmos1", AccountNumber: 7, Sequence: 3}
+
+	testCases := []struct {
+		name          string
+		account       proto.Message
+		accountNumber uint64
+		sequence      uint64
+		found         bool
+	}{
+		{
+			name:          "base account",
+			account:       baseAccount,
+			accountNumber: 7,
+			sequence:      3,
+			found:         true,
+		},
+		{
+			name:          "module account",
+			account:       &authv1beta1.ModuleAccount{BaseAccount: baseAccount, Name: "distribution"},
+			accountNumber: 7,
+			sequence:      3,
+			found:         true,
+		},
+		{
+			name: "vesting account",
+			account: &vestingv1beta1.ContinuousVestingAccount{
+				BaseVestingAccount: &vestingv1beta1.BaseVestingAccount{BaseAccount: baseAccount},
+				StartTime:          1,
+			},
+			accountNumber: 7,
+			sequence:      3,
+			found:         true,
+		},
+		{
+			name:    "module account without base account",
+			account: &authv1beta1.ModuleAccount{Name: "distribution"},
+		},
+		{
+			name:    "not an account",
+			account: &bankv1beta1.MsgSend{FromAddress: "cosmos1"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			accountNumber, sequence, found := accountNumberSequence(tc.account.ProtoReflect())
+			assert.Equal(t, found, tc.found)
+			assert.Equal(t, accountNumber, tc.accountNumber)
+			assert.Equal(t, sequence, tc.sequence)
+		})
+	}
+}
+
+// newTestTxCommandBuilder returns a tx command builder with an in-memory keyring
+// and a chain without gRPC endpoints, so that any query to the node fails.
+func newTestTxCommandBuilder(t *testing.T) *txCommandBuilder {
+	t.Helper()
+
+	registry := codectypes.NewInterfaceRegistry()
+	cryptocodec.RegisterInterfaces(registry)
+	kr := keyring.NewInMemory(codec.NewProtoCodec(registry))
+
+	addressCodec, validatorAddressCodec, consensusAddressCodec, err := getAddressCodecFromConfig(&config.Config{}, config.GlobalKeyringDirName)
+	assert.NilError(t, err)
+
+	chainInfo := NewChainInfo(t.TempDir(), "test", &config.ChainConfig{})
+	chainInfo.ProtoFiles = protoregistry.GlobalFiles
+
+	return &txCommandBuilder{
+		chainInfo: chainInfo,
+		builder: &flag.Builder{
+			AddressCodec:          addressCodec,
+			ValidatorAddressCodec: validatorAddressCodec,
+			ConsensusAddressCodec: consensusAddressCodec,
+		},
+		keyring: kr,
+	}
+}
+
+func TestParseSignMode(t *testing.T) {
+	b := newTestTxCommandBuilder(t)
+
+	signMode, err := b.parseSignMode(newTxCmd(t))
+	assert.NilError(t, err)
+	assert.Equal(t, signMode, signingv1beta1.SignMode_SIGN_MODE_DIRECT)
+
+	signMode, err = b.parseSignMode(newTxCmd(t, "--sign-mode", "amino-json"))
+	assert.NilError(t, err)
+	assert.Equal(t, signMode, signingv1beta1.SignMode_SIGN_MODE_LEGACY_AMINO_JSON)
+
+	signMode, err = b.parseSignMode(newTxCmd(t, "--sign-mode", "textual"))
+	assert.NilError(t, err)
+	assert.Equal(t, signMode, signingv1beta1.SignMode_SIGN_MODE_TEXTUAL)
+
+	_, err = b.parseSignMode(newTxCmd(t, "--sign-mode", "712"))
+	assert.ErrorContains(t, err, `invalid sign mode "712", expected one of direct, textual, amino-json`)
+}
+
+func TestChainSignModes(t *testing.T) {
+	const customSignMode = signingv1beta1.SignMode(712)
+	RegisterSignModeHandler(customSignMode, func(*ChainInfo, grpc.ClientConnInterface) (txsigning.SignModeHandler, error) {
+		return customSignModeHandler{}, nil
+	})
+	t.Cleanup(func() { delete(signModeHandlerFactories, customSignMode) })
+
+	// only the registered sign modes advertised by the chain are accepted
+	b := newTestTxCommandBuilder(t)
+	b.chainInfo.SignModes = []signingv1beta1.SignMode{customSignMode, signingv1beta1.SignMode_SIGN_MODE_DIRECT, signingv1beta1.SignMode_SIGN_MODE_DIRECT_AUX}
+
+	cmd := &cobra.Command{}
+	addTxFlags(cmd, sortedSignModes(signModeHandlers(b.chainInfo)))
+	assert.Equal(t, cmd.Flags().Lookup(flags.FlagSignMode).Usage, "Choose sign mode (direct|712)")
+	assert.Equal(t, cmd.Flags().Lookup(flags.FlagSignMode).DefValue, "direct")
+
+	assert.NilError(t, cmd.Flags().Parse([]string{"--sign-mode", "712"}))
+	signMode, err := b.parseSignMode(cmd)
+	assert.NilError(t, err)
+	assert.Equal(t, signMode, customSignMode)
+
+	handler, err := b.signModeHandler(nil, customSignMode)
+	assert.NilError(t, err)
+	assert.Equal(t, handler.Mode(), customSignMode)
+
+	_, err = b.parseSignMode(newTxCmd(t, "--sign-mode", "amino-json"))
+	assert.ErrorContains(t, err, `invalid sign mode "amino-json", expected one of direct, 712`)
+	_, err = b.signModeHandler(nil, signingv1beta1.SignMode_SIGN_MODE_LEGACY_AMINO_JSON)
+	assert.ErrorContains(t, err, "unsupported sign mode")
+
+	// none of the sign modes of the chain is registered
+	b.chainInfo.SignModes = []signingv1beta1.SignMode{signingv1beta1.SignMode_SIGN_MODE_DIRECT_AUX}
+	_, err = b.parseSignMode(newTxCmd(t))
+	assert.ErrorContains(t, err, "can't sign with any of the sign modes advertised by the chain")
+}
+
+// customSignModeHandler is the handler of a custom sign mode, signing the body bytes.
+type customSignModeHandler struct{}
+
+func (customSignModeHandler) Mode() signingv1beta1.SignMode {
+	return signingv1beta1.SignMode(712)
+}
+
+func (customSignModeHandler) GetSignBytes(_ context.Context, _ txsigning.SignerData, txData txsigning.TxData) ([]byte, error) {
+	return txData.BodyBytes, nil
+}
+
+func TestGenerateOnlyOffline(t *testing.T) {
+	b := newTestTxCommandBuilder(t)
+	from, err := b.builder.AddressCodec.BytesToString(bytes.Repeat([]byte{1}, 20))
+	assert.NilError(t, err)
+	to, err := b.builder.AddressCodec.BytesToString(bytes.Repeat([]byte{2}, 20))
+	assert.NilError(t, err)
+
+	newMsg := func() *bankv1beta1.MsgSend {
+		return &bankv1beta1.MsgSend{ToAddress: to, Amount: []*basev1beta1.Coin{{Denom: "stake", Amount: "10"}}}
+	}
+
+	// the address is not in the keyring, so the transaction can only be generated
+	cmd := newTxCmd(t, "--account-number", "7", "--sequence", "3")
+	cmd.SetContext(context.Background())
+	assert.ErrorContains(t, b.generateOrBroadcastTx(cmd, from, newMsg().ProtoReflect()), "not found")
+
+	// without the account number and sequence, they are queried from the node
+	cmd = newTxCmd(t, "--generate-only")
+	cmd.SetContext(context.Background())
+	assert.ErrorContains(t, b.generateOrBroadcastTx(cmd, from, newMsg().ProtoReflect()), "error loading gRPC client")
+
+	cmd = newTxCmd(t, "--generate-only", "--account-number", "7", "--sequence", "3", "--sign-mode", "amino-json", "--fees", "5stake")
+	cmd.SetContext(context.Background())
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	assert.NilError(t, b.generateOrBroadcastTx(cmd, from, newMsg().ProtoReflect()))
+
+	tx := &txv1beta1.Tx{}
+	assert.NilError(t, protojson.Unmarshal(out.Bytes(), tx))
+	assert.Equal(t, len(tx.AuthInfo.SignerInfos), 1)
+	signerInfo := tx.AuthInfo.SignerInfos[0]
+	assert.Assert(t, signerInfo.PublicKey == nil)
+	assert.Equal(t, signerInfo.Sequence, uint64(3))
+	assert.Equal(t, signerInfo.ModeInfo.GetSingle().Mode, signingv1beta1.SignMode_SIGN_MODE_LEGACY_AMINO_JSON)
+	assert.Equal(t, tx.AuthInfo.Fee.Amount[0].Amount, "5")
+
+	// the signer of the message is set to the given address
+	msg := &bankv1beta1.MsgSend{}
+	assert.NilError(t, tx.Body.Messages[0].UnmarshalTo(msg))
+	assert.Equal(t, msg.FromAddress, from)
+}
+
+func TestSignTx(t *testing.T) {
+	b := newTestTxCommandBuilder(t)
+	key, _, err := b.keyring.NewMnemonic("alice", keyring.English, sdk.FullFundraiserPath, keyring.DefaultBIP39Passphrase, hd.Secp256k1)
+	assert.NilError(t, err)
+
+	pubKey, err := key.GetPubKey()
+	assert.NilError(t, err)
+	pubKeyAny, err := codectypes.NewAnyWithValue(pubKey)
+	assert.NilError(t, err)
+	keyAddr, err := key.GetAddress()
+	assert.NilError(t, err)
+	signer, err := b.builder.AddressCodec.BytesToString(keyAddr)
+	assert.NilError(t, err)
+
+	msgAny, err := anyutil.New(&bankv1beta1.MsgSend{FromAddress: signer, ToAddress: signer, Amount: []*basev1beta1.Coin{{Denom: "stake", Amount: "10"}}})
+	assert.NilError(t, err)
+
+	signerData := txsigning.SignerData{
+		Address:       signer,
+		ChainID:       "test-chain",
+		AccountNumber: 7,
+		Sequence:      3,
+		PubKey:        &anypb.Any{TypeUrl: pubKeyAny.TypeUrl, Value: pubKeyAny.Value},
+	}
+
+	for _, signMode := range []signingv1beta1.SignMode{signingv1beta1.SignMode_SIGN_MODE_DIRECT, signingv1beta1.SignMode_SIGN_MODE_LEGACY_AMINO_JSON} {
+		t.Run(signMode.String(), func(t *testing.T) {
+			body := &txv1beta1.TxBody{Messages: []*anypb.Any{msgAny}, Memo: "memo"}
+			authInfo := &txv1beta1.AuthInfo{
+				SignerInfos: []*txv1beta1.SignerInfo{{
+					PublicKey: signerData.PubKey,
+					ModeInfo:  &txv1beta1.ModeInfo{Sum: &txv1beta1.ModeInfo_Single_{Single: &txv1beta1.ModeInfo_Single{Mode: signMode}}},
+					Sequence:  signerData.Sequence,
+				}},
+				Fee: &txv1beta1.Fee{GasLimit: 200000},
+			}
+
+			txRaw, err := b.signTx(context.Background(), nil, key, signMode, body, authInfo, signerData)
+			assert.NilError(t, err)
+			assert.Equal(t, len(txRaw.Signatures), 1)
+
+			handler, err := b.signModeHandler(nil, signMode)
+			assert.NilError(t, err)
+			signBytes, err := handler.GetSignBytes(context.Background(), signerData, txsigning.TxData{
+				Body:          body,
+				AuthInfo:      authInfo,
+				BodyBytes:     txRaw.BodyBytes,
+				AuthInfoBytes: txRaw.AuthInfoBytes,
+			})
+			assert.NilError(t, err)
+			assert.Assert(t, pubKey.VerifySignature(signBytes, txRaw.Signatures[0]))
+		})
+	}
+}
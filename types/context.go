@@ -338,12 +338,12 @@ func (c Context) Value(key interface{}) interface{} {
 
 // KVStore fetches a KVStore from the MultiStore.
 func (c Context) KVStore(key storetypes.StoreKey) storetypes.KVStore {
-	return gaskv.NewStore(c.ms.GetKVStore(key), c.gasMeter, c.kvGasConfig)
+	return traceKVStore(c.baseCtx, gaskv.NewStoreWithName(c.ms.GetKVStore(key), c.gasMeter, c.kvGasConfig, key.Name()), key.Name())
 }
 
 // TransientStore fetches a TransientStore from the MultiStore.
 func (c Context) TransientStore(key storetypes.StoreKey) storetypes.KVStore {
-	return gaskv.NewStore(c.ms.GetKVStore(key), c.gasMeter, c.transientKVGasConfig)
+	return gaskv.NewStoreWithName(c.ms.GetKVStore(key), c.gasMeter, c.transientKVGasConfig, key.Name())
 }
 
 // CacheContext returns a new Context with the multi-store cached and a new
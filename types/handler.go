@@ -1,5 +1,14 @@
 package types
 
+import (
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/cosmos/cosmos-sdk/telemetry"
+)
+
 // AnteHandler authenticates transactions, before their internal messages are
 // executed. The provided ctx is expected to contain all relevant information
 // needed to process the transaction, e.g. fee payment information. If new data
@@ -51,8 +60,37 @@ func ChainAnteDecorators(chain ...AnteDecorator) AnteHandler {
 	}
 	for i := 0; i < len(chain); i++ {
 		ii := i
-		handlerChain[ii] = func(ctx Context, tx Tx, _ bool) (Context, error) {
-			return chain[ii].AnteHandle(ctx, tx, ctx.ExecMode() == ExecModeSimulate, handlerChain[ii+1])
+		decorator := attribute.String("decorator", fmt.Sprintf("%T", chain[ii]))
+		handlerChain[ii] = func(ctx Context, tx Tx, _ bool) (newCtx Context, err error) {
+			parentCtx := ctx.Context()
+			if parentCtx == nil {
+				return chain[ii].AnteHandle(ctx, tx, ctx.ExecMode() == ExecModeSimulate, handlerChain[ii+1])
+			}
+			spanCtx, span := telemetry.StartSpan(parentCtx, "ante", decorator)
+			if !span.IsRecording() {
+				span.End()
+				return chain[ii].AnteHandle(ctx, tx, ctx.ExecMode() == ExecModeSimulate, handlerChain[ii+1])
+			}
+			gasMeter := ctx.GasMeter()
+			gasBefore := gasMeter.GasConsumed()
+			defer func() {
+				// the gas meter of the transaction may be replaced by the decorators, its
+				// gas is then all used by them
+				gasUsed := gasMeter.GasConsumed() - gasBefore
+				if !newCtx.IsZero() && newCtx.GasMeter() != gasMeter {
+					gasUsed = newCtx.GasMeter().GasConsumed()
+				}
+				span.SetAttributes(attribute.Int64("gas_used", int64(gasUsed)))
+				telemetry.EndSpan(span, err)
+			}()
+
+			// the span of the next decorators is a child of the span of this one
+			newCtx, err = chain[ii].AnteHandle(ctx.WithContext(spanCtx), tx, ctx.ExecMode() == ExecModeSimulate, handlerChain[ii+1])
+			if !newCtx.IsZero() && trace.SpanFromContext(newCtx.Context()) == span {
+				// restore the span of the caller, keeping the values set by the decorators
+				newCtx = newCtx.WithContext(trace.ContextWithSpan(newCtx.Context(), trace.SpanFromContext(parentCtx)))
+			}
+			return newCtx, err
 		}
 	}
 
@@ -0,0 +1,190 @@
+package mempool
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	"cosmossdk.io/math"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+var _ Mempool = (*LanedMempool)(nil)
+
+// ErrNoLane is returned when inserting a transaction matching none of the lanes of a
+// LanedMempool.
+var ErrNoLane = errors.New("tx matches no lane")
+
+// LaneMatchFn returns whether a transaction belongs to a lane.
+type LaneMatchFn func(ctx context.Context, tx sdk.Tx) bool
+
+// Lane is a part of the block reserved to the transactions matching a rule, with its own
+// mempool.
+type Lane struct {
+	// Name identifies the lane.
+	Name string
+	// Mempool holds the transactions of the lane.
+	Mempool Mempool
+	// Match returns whether a transaction belongs to the lane.
+	Match LaneMatchFn
+	// MaxBlockSpace is the maximum share of the block bytes and gas the transactions of
+	// the lane may use, between 0 (exclusive) and 1 (inclusive).
+	MaxBlockSpace math.LegacyDec
+}
+
+// MatchAll matches every transaction. It is typically the rule of the last, default lane.
+func MatchAll() LaneMatchFn {
+	return func(context.Context, sdk.Tx) bool { return true }
+}
+
+// MatchMsgTypeURLs matches the transactions whose messages all have one of the given type
+// URLs.
+func MatchMsgTypeURLs(typeURLs ...string) LaneMatchFn {
+	allowed := make(map[string]struct{}, len(typeURLs))
+	for _, typeURL := range typeURLs {
+		allowed[typeURL] = struct{}{}
+	}
+	return func(_ context.Context, tx sdk.Tx) bool {
+		msgs := tx.GetMsgs()
+		if len(msgs) == 0 {
+			return false
+		}
+		for _, msg := range msgs {
+			if _, ok := allowed[sdk.MsgTypeURL(msg)]; !ok {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// MatchFeePayers matches the transactions whose fees are paid by one of the given
+// addresses.
+func MatchFeePayers(payers ...sdk.AccAddress) LaneMatchFn {
+	return func(_ context.Context, tx sdk.Tx) bool {
+		feeTx, ok := tx.(sdk.FeeTx)
+		if !ok {
+			return false
+		}
+		feePayer := feeTx.FeePayer()
+		for _, payer := range payers {
+			if bytes.Equal(payer, feePayer) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// LanedMempool is a mempool made of lanes, in priority order. A transaction is inserted
+// in the first lane it matches, and the transactions of a lane are selected before the
+// transactions of the following lanes.
+type LanedMempool struct {
+	lanes []Lane
+}
+
+// NewLanedMempool creates a mempool from lanes, in priority order.
+func NewLanedMempool(lanes ...Lane) (*LanedMempool, error) {
+	if len(lanes) == 0 {
+		return nil, errors.New("no lanes")
+	}
+	names := make(map[string]struct{}, len(lanes))
+	for _, lane := range lanes {
+		if _, ok := names[lane.Name]; ok {
+			return nil, fmt.Errorf("duplicate lane %s", lane.Name)
+		}
+		names[lane.Name] = struct{}{}
+
+		if lane.Mempool == nil || lane.Match == nil {
+			return nil, fmt.Errorf("lane %s: mempool and match rule must be set", lane.Name)
+		}
+		if lane.MaxBlockSpace.IsNil() || !lane.MaxBlockSpace.IsPositive() || lane.MaxBlockSpace.GT(math.LegacyOneDec()) {
+			return nil, fmt.Errorf("lane %s: max block space must be in (0, 1], got %s", lane.Name, lane.MaxBlockSpace)
+		}
+	}
+	return &LanedMempool{lanes: lanes}, nil
+}
+
+// Lanes returns the lanes of the mempool, in priority order.
+func (mp *LanedMempool) Lanes() []Lane {
+	return mp.lanes
+}
+
+// LaneIndex returns the index of the first lane matching the transaction, or false if it
+// matches none.
+func (mp *LanedMempool) LaneIndex(ctx context.Context, tx sdk.Tx) (int, bool) {
+	for i, lane := range mp.lanes {
+		if lane.Match(ctx, tx) {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// Insert inserts the transaction in the first lane it matches.
+func (mp *LanedMempool) Insert(ctx context.Context, tx sdk.Tx) error {
+	i, ok := mp.LaneIndex(ctx, tx)
+	if !ok {
+		return ErrNoLane
+	}
+	return mp.lanes[i].Mempool.Insert(ctx, tx)
+}
+
+// Select returns an iterator over the transactions of every lane, in priority order.
+func (mp *LanedMempool) Select(ctx context.Context, txs [][]byte) Iterator {
+	return newLanesIterator(ctx, mp.lanes, txs, 0)
+}
+
+// CountTx returns the number of transactions of every lane.
+func (mp *LanedMempool) CountTx() int {
+	count := 0
+	for _, lane := range mp.lanes {
+		count += lane.Mempool.CountTx()
+	}
+	return count
+}
+
+// Remove removes the transaction from the lane holding it.
+func (mp *LanedMempool) Remove(tx sdk.Tx) error {
+	for _, lane := range mp.lanes {
+		err := lane.Mempool.Remove(tx)
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, ErrTxNotFound) {
+			return err
+		}
+	}
+	return ErrTxNotFound
+}
+
+// lanesIterator iterates over the transactions of lanes, one lane after the other.
+type lanesIterator struct {
+	ctx   context.Context
+	lanes []Lane
+	txs   [][]byte
+	lane  int
+	iter  Iterator
+}
+
+func newLanesIterator(ctx context.Context, lanes []Lane, txs [][]byte, lane int) Iterator {
+	for ; lane < len(lanes); lane++ {
+		if iter := lanes[lane].Mempool.Select(ctx, txs); iter != nil {
+			return &lanesIterator{ctx: ctx, lanes: lanes, txs: txs, lane: lane, iter: iter}
+		}
+	}
+	return nil
+}
+
+func (it *lanesIterator) Next() Iterator {
+	if it.iter = it.iter.Next(); it.iter != nil {
+		return it
+	}
+	return newLanesIterator(it.ctx, it.lanes, it.txs, it.lane+1)
+}
+
+func (it *lanesIterator) Tx() sdk.Tx {
+	return it.iter.Tx()
+}
@@ -0,0 +1,108 @@
+package mempool_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"cosmossdk.io/core/log"
+	"cosmossdk.io/math"
+
+	countertypes "github.com/cosmos/cosmos-sdk/testutil/x/counter/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/mempool"
+)
+
+// laneTx is a testTx with messages and a fee payer.
+type laneTx struct {
+	testTx
+	msgs     []sdk.Msg
+	feePayer sdk.AccAddress
+}
+
+var _ sdk.FeeTx = laneTx{}
+
+func (tx laneTx) GetMsgs() []sdk.Msg { return tx.msgs }
+
+func (tx laneTx) GetGas() uint64 { return 0 }
+
+func (tx laneTx) GetFee() sdk.Coins { return nil }
+
+func (tx laneTx) FeePayer() []byte { return tx.feePayer }
+
+func (tx laneTx) FeeGranter() []byte { return nil }
+
+func TestLaneMatchFns(t *testing.T) {
+	ctx := sdk.NewContext(nil, false, log.NewNopLogger())
+	oracle, relayer := sdk.AccAddress("oracle"), sdk.AccAddress("relayer")
+	msg := &countertypes.MsgIncreaseCounter{}
+
+	matchMsgs := mempool.MatchMsgTypeURLs(sdk.MsgTypeURL(msg))
+	require.True(t, matchMsgs(ctx, laneTx{msgs: []sdk.Msg{msg, msg}}))
+	require.False(t, matchMsgs(ctx, laneTx{msgs: []sdk.Msg{msg, &countertypes.MsgIncreaseCountResponse{}}}))
+	require.False(t, matchMsgs(ctx, laneTx{}))
+
+	matchPayers := mempool.MatchFeePayers(oracle)
+	require.True(t, matchPayers(ctx, laneTx{feePayer: oracle}))
+	require.False(t, matchPayers(ctx, laneTx{feePayer: relayer}))
+	require.False(t, matchPayers(ctx, testTx{}))
+
+	require.True(t, mempool.MatchAll()(ctx, testTx{}))
+}
+
+func TestLanedMempool(t *testing.T) {
+	ctx := sdk.NewContext(nil, false, log.NewNopLogger())
+	oracle, alice, bob := sdk.AccAddress("oracle"), sdk.AccAddress("alice"), sdk.AccAddress("bob")
+
+	newLane := func(name string, match mempool.LaneMatchFn) mempool.Lane {
+		return mempool.Lane{
+			Name:          name,
+			Mempool:       mempool.NewSenderNonceMempool(mempool.SenderNonceMaxTxOpt(100)),
+			Match:         match,
+			MaxBlockSpace: math.LegacyNewDecWithPrec(5, 1),
+		}
+	}
+
+	_, err := mempool.NewLanedMempool()
+	require.Error(t, err)
+	_, err = mempool.NewLanedMempool(newLane("default", mempool.MatchAll()), newLane("default", mempool.MatchAll()))
+	require.ErrorContains(t, err, "duplicate lane")
+	invalid := newLane("default", mempool.MatchAll())
+	invalid.MaxBlockSpace = math.LegacyNewDec(2)
+	_, err = mempool.NewLanedMempool(invalid)
+	require.ErrorContains(t, err, "max block space")
+
+	oracleLane := newLane("oracle", mempool.MatchFeePayers(oracle))
+	mp, err := mempool.NewLanedMempool(oracleLane, newLane("alice", mempool.MatchFeePayers(alice)))
+	require.NoError(t, err)
+
+	txs := []laneTx{
+		{testTx: testTx{id: 0, address: alice, nonce: 0}, feePayer: alice},
+		{testTx: testTx{id: 1, address: oracle, nonce: 0}, feePayer: oracle},
+		{testTx: testTx{id: 2, address: alice, nonce: 1}, feePayer: alice},
+		{testTx: testTx{id: 3, address: oracle, nonce: 1}, feePayer: oracle},
+	}
+	for _, tx := range txs {
+		require.NoError(t, mp.Insert(ctx, tx))
+	}
+	require.ErrorIs(t, mp.Insert(ctx, laneTx{testTx: testTx{address: bob}, feePayer: bob}), mempool.ErrNoLane)
+	require.Equal(t, 4, mp.CountTx())
+	require.Equal(t, 2, oracleLane.Mempool.CountTx())
+
+	i, ok := mp.LaneIndex(ctx, txs[0])
+	require.True(t, ok)
+	require.Equal(t, 1, i)
+	_, ok = mp.LaneIndex(ctx, testTx{})
+	require.False(t, ok)
+
+	// the transactions of the oracle lane are selected first
+	var ids []int
+	for iter := mp.Select(ctx, nil); iter != nil; iter = iter.Next() {
+		ids = append(ids, iter.Tx().(laneTx).id)
+	}
+	require.Equal(t, []int{1, 3, 0, 2}, ids)
+
+	require.NoError(t, mp.Remove(txs[1]))
+	require.ErrorIs(t, mp.Remove(txs[1]), mempool.ErrTxNotFound)
+	require.Equal(t, 3, mp.CountTx())
+}
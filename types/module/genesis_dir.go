@@ -0,0 +1,518 @@
+package module
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+
+	"cosmossdk.io/core/appmodule"
+	"cosmossdk.io/core/genesis"
+	storetypes "cosmossdk.io/store/types"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// StreamedGenesisKey is the key of the app state of a genesis file whose module
+// states are stored in a directory.
+const StreamedGenesisKey = "streamed_genesis"
+
+// StreamedGenesis references the directory of the module states of a genesis file,
+// written by Manager.ExportGenesisToDir. It is set as the app state of the genesis
+// file, under StreamedGenesisKey, so that the genesis file stays small.
+//
+// The directory holds a <module>.json file per module, or a <module> directory for
+// core API modules and modules implementing HasGenesisExportToTarget, with a file per
+// genesis field. The fields holding arrays (e.g. collections) are split in NDJSON
+// chunks, see genesis.DirTarget.
+//
+// The genesis file commits to the module states with the hash of the directory, see
+// HashGenesisDir, which is verified before the module states are read.
+type StreamedGenesis struct {
+	// Dir is the directory of the module states. A relative directory is relative
+	// to the directory of the genesis file.
+	Dir string `json:"dir"`
+	// Hash is the hex encoded hash of the directory of the module states.
+	Hash string `json:"hash"`
+}
+
+// AppState returns the app state referencing the streamed genesis.
+func (g StreamedGenesis) AppState() (json.RawMessage, error) {
+	return json.Marshal(map[string]StreamedGenesis{StreamedGenesisKey: g})
+}
+
+// Path returns the directory of the module states, genesisDir being the directory
+// of the genesis file.
+func (g StreamedGenesis) Path(genesisDir string) string {
+	if filepath.IsAbs(g.Dir) {
+		return g.Dir
+	}
+
+	return filepath.Join(genesisDir, g.Dir)
+}
+
+// ParseStreamedGenesis returns the streamed genesis referenced by an app state, if any.
+func ParseStreamedGenesis(appState json.RawMessage) (StreamedGenesis, bool) {
+	// avoid decoding large app states
+	if !bytes.Contains(appState, []byte(`"`+StreamedGenesisKey+`"`)) {
+		return StreamedGenesis{}, false
+	}
+
+	var state map[string]json.RawMessage
+	if err := json.Unmarshal(appState, &state); err != nil || len(state) != 1 {
+		return StreamedGenesis{}, false
+	}
+
+	var g StreamedGenesis
+	if err := json.Unmarshal(state[StreamedGenesisKey], &g); err != nil || g.Dir == "" {
+		return StreamedGenesis{}, false
+	}
+
+	return g, true
+}
+
+// HashGenesisDir returns the hex encoded SHA-256 hash of the files of a directory
+// written by ExportGenesisToDir. The files are hashed in lexical order of their
+// path, each with its path and size, so that the hash commits to the layout of
+// the directory as well as to the content of its files.
+func HashGenesisDir(dir string) (string, error) {
+	h := sha256.New()
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return fmt.Errorf("%s is not a regular file", path)
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		info, err := f.Stat()
+		if err != nil {
+			return err
+		}
+
+		_, _ = h.Write([]byte(filepath.ToSlash(rel)))
+		_, _ = h.Write([]byte{0})
+		_, _ = h.Write(binary.BigEndian.AppendUint64(nil, uint64(info.Size())))
+		_, err = io.Copy(h, f)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// verifyGenesisDir verifies the hash of a directory written by ExportGenesisToDir.
+func verifyGenesisDir(dir, hash string) error {
+	if hash == "" {
+		return errors.New("the streamed genesis has no hash")
+	}
+
+	actual, err := HashGenesisDir(dir)
+	if err != nil {
+		return err
+	}
+	if actual != hash {
+		return fmt.Errorf("the hash of the module states in %s is %s, the genesis file commits to %s", dir, actual, hash)
+	}
+
+	return nil
+}
+
+// ExportGenesisToDir exports the state of the modules to dir, see StreamedGenesis,
+// and returns the hash of dir. Unlike ExportGenesisForModules, the modules are
+// exported one at a time, and core API modules and modules implementing
+// HasGenesisExportToTarget are streamed to their files, which bounds the memory
+// used by the export.
+func (m *Manager) ExportGenesisToDir(ctx sdk.Context, dir string, modulesToExport []string) (string, error) {
+	if len(modulesToExport) == 0 {
+		modulesToExport = m.OrderExportGenesis
+	}
+	// verify modules exists in app, so that we don't panic in the middle of an export
+	if err := m.checkModulesExists(modulesToExport); err != nil {
+		return "", err
+	}
+
+	// the hash must only cover the exported module states
+	if entries, err := os.ReadDir(dir); err == nil && len(entries) > 0 {
+		return "", fmt.Errorf("the directory %s is not empty", dir)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+
+	ctx = ctx.WithGasMeter(storetypes.NewInfiniteGasMeter())
+	for _, moduleName := range modulesToExport {
+		if err := m.exportModuleGenesisToDir(ctx, dir, moduleName); err != nil {
+			return "", fmt.Errorf("genesis export error in %s: %w", moduleName, err)
+		}
+	}
+
+	return HashGenesisDir(dir)
+}
+
+func (m *Manager) exportModuleGenesisToDir(ctx sdk.Context, dir, moduleName string) error {
+	var (
+		bz  json.RawMessage
+		err error
+	)
+	moduleDir := filepath.Join(dir, moduleName)
+	if module, ok := genesisAuto(m.Modules[moduleName]); ok {
+		// core API genesis, streamed to the module directory
+		return module.ExportGenesis(ctx, genesis.DirTarget(moduleDir, genesis.DefaultChunkSize))
+	}
+	if module, ok := m.Modules[moduleName].(HasGenesisExportToTarget); ok {
+		return module.ExportGenesisToTarget(ctx, genesis.DirTarget(moduleDir, genesis.DefaultChunkSize))
+	}
+
+	switch module := m.Modules[moduleName].(type) {
+	case HasGenesis:
+		bz, err = module.ExportGenesis(ctx)
+	case HasABCIGenesis:
+		bz, err = module.ExportGenesis(ctx)
+	default:
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(dir, moduleName+".json"), bz, 0o600)
+}
+
+// genesisAuto returns the core API genesis of a module, unwrapping adapted core API modules.
+func genesisAuto(mod any) (appmodule.HasGenesisAuto, bool) {
+	if adaptor, ok := mod.(coreAppModuleAdaptor); ok {
+		mod = adaptor.module
+	}
+
+	module, ok := mod.(appmodule.HasGenesisAuto)
+	return module, ok
+}
+
+// loadModuleGenesis loads the genesis of a module from a directory written by ExportGenesisToDir.
+// The genesis of a module which is neither a core API module nor a module importing its genesis
+// from a source, see HasGenesisImportFromSource, is read as a JSON document.
+func (m *Manager) loadModuleGenesis(dir, moduleName string) (moduleGenesis, error) {
+	bz, err := os.ReadFile(filepath.Join(dir, moduleName+".json"))
+	if err == nil {
+		return moduleGenesis{raw: bz}, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return moduleGenesis{}, err
+	}
+
+	moduleDir := filepath.Join(dir, moduleName)
+	if info, err := os.Stat(moduleDir); err != nil || !info.IsDir() {
+		// the module has no genesis
+		return moduleGenesis{}, nil
+	}
+
+	if _, ok := genesisAuto(m.Modules[moduleName]); !ok && !hasGenesisImportFromSource(m.Modules[moduleName]) {
+		// exported by HasGenesisExportToTarget
+		bz, err := ReadGenesisDirJSON(moduleDir)
+		return moduleGenesis{raw: bz}, err
+	}
+
+	return moduleGenesis{source: genesis.DirSource(moduleDir)}, nil
+}
+
+// ReadGenesisDirJSON reads the genesis fields written by genesis.DirTarget in dir,
+// e.g. the directory of a module of a streamed genesis, as a JSON object.
+func ReadGenesisDirJSON(dir string) (json.RawMessage, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields []string
+	for _, e := range entries {
+		if e.IsDir() {
+			fields = append(fields, e.Name())
+		} else if field, ok := strings.CutSuffix(e.Name(), ".json"); ok {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+
+	source := genesis.DirSource(dir)
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, field := range fields {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		name, err := json.Marshal(field)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+
+		rdr, err := source(field)
+		if err != nil {
+			return nil, err
+		}
+		_, err = io.Copy(&buf, rdr)
+		_ = rdr.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
+
+// InitGenesisFromDir performs init genesis functionality for modules, from the module states
+// stored in dir by ExportGenesisToDir, after verifying the hash of dir. The state of a module
+// is loaded when it is initialized.
+func (m *Manager) InitGenesisFromDir(ctx sdk.Context, dir, hash string) (*abci.InitChainResponse, error) {
+	ctx.Logger().Info("initializing blockchain state from genesis directory", "dir", dir)
+	if err := verifyGenesisDir(dir, hash); err != nil {
+		return &abci.InitChainResponse{}, err
+	}
+
+	return m.initGenesis(ctx, func(moduleName string) (moduleGenesis, error) {
+		return m.loadModuleGenesis(dir, moduleName)
+	})
+}
+
+// ValidateGenesisDir performs genesis state validation for all modules, from the module states
+// stored in dir by ExportGenesisToDir, after verifying the hash of dir. The modules are
+// validated one at a time.
+func (m *Manager) ValidateGenesisDir(dir, hash string) error {
+	if err := verifyGenesisDir(dir, hash); err != nil {
+		return err
+	}
+
+	for _, name := range m.ModuleNames() {
+		data, err := m.loadModuleGenesis(dir, name)
+		if err != nil {
+			return fmt.Errorf("failed to load genesis of %s: %w", name, err)
+		}
+
+		b := m.Modules[name]
+		if mod, ok := genesisAuto(b); ok {
+			source := data.source
+			if source == nil {
+				if data.raw == nil {
+					continue
+				}
+				if source, err = genesis.SourceFromRawJSON(data.raw); err != nil {
+					return err
+				}
+			}
+			if err := mod.ValidateGenesis(source); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if data.raw == nil && data.source != nil {
+			if err := validateGenesisFromSource(b, data.source); err != nil {
+				return err
+			}
+			continue
+		}
+		if mod, ok := b.(HasGenesisBasics); ok {
+			if err := mod.ValidateGenesis(data.raw); err != nil {
+				return err
+			}
+		} else if mod, ok := b.(appmodule.HasGenesis); ok {
+			if err := mod.ValidateGenesis(data.raw); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// hasGenesisImportFromSource reports whether a module imports its genesis from a source.
+func hasGenesisImportFromSource(mod any) bool {
+	switch mod.(type) {
+	case HasGenesisImportFromSource, HasABCIGenesisImportFromSource:
+		return true
+	default:
+		return false
+	}
+}
+
+// validateGenesisFromSource validates the genesis source of a module importing its genesis
+// from a source.
+func validateGenesisFromSource(mod any, source appmodule.GenesisSource) error {
+	switch mod := mod.(type) {
+	case HasGenesisImportFromSource:
+		return mod.ValidateGenesisFromSource(source)
+	case HasABCIGenesisImportFromSource:
+		return mod.ValidateGenesisFromSource(source)
+	default:
+		return errors.New("genesis must be a JSON document")
+	}
+}
+
+// WriteGenesisFields writes the given fields of a JSON object, e.g. the JSON genesis of a
+// module holding its scalar fields only, to a genesis target.
+func WriteGenesisFields(target appmodule.GenesisTarget, obj json.RawMessage, fields ...string) error {
+	var values map[string]json.RawMessage
+	if err := json.Unmarshal(obj, &values); err != nil {
+		return err
+	}
+
+	for _, field := range fields {
+		value, ok := values[field]
+		if !ok {
+			return fmt.Errorf("missing genesis field %s", field)
+		}
+
+		w, err := target(field)
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(value); err != nil {
+			_ = w.Close()
+			return err
+		}
+		if err := w.Close(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GenesisArrayWriter writes a JSON array field of a genesis target one item at a time.
+type GenesisArrayWriter struct {
+	w     io.WriteCloser
+	items int
+}
+
+// NewGenesisArrayWriter returns a writer of the JSON array field of a genesis target.
+func NewGenesisArrayWriter(target appmodule.GenesisTarget, field string) (*GenesisArrayWriter, error) {
+	w, err := target(field)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write([]byte("[")); err != nil {
+		_ = w.Close()
+		return nil, err
+	}
+
+	return &GenesisArrayWriter{w: w}, nil
+}
+
+// Write writes an item of the array.
+func (w *GenesisArrayWriter) Write(item json.RawMessage) error {
+	if w.items > 0 {
+		if _, err := w.w.Write([]byte(",")); err != nil {
+			return err
+		}
+	}
+	w.items++
+	_, err := w.w.Write(item)
+	return err
+}
+
+// Close terminates the array and closes the field.
+func (w *GenesisArrayWriter) Close() error {
+	if _, err := w.w.Write([]byte("]")); err != nil {
+		_ = w.w.Close()
+		return err
+	}
+	return w.w.Close()
+}
+
+// ReadGenesisFields reads the given fields of a genesis source, e.g. the scalar fields of the
+// genesis of a module, as a JSON object. Missing fields are omitted.
+func ReadGenesisFields(source appmodule.GenesisSource, fields ...string) (json.RawMessage, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for _, field := range fields {
+		rdr, err := source(field)
+		if err != nil {
+			return nil, err
+		}
+		if rdr == nil {
+			continue
+		}
+
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+		name, err := json.Marshal(field)
+		if err != nil {
+			_ = rdr.Close()
+			return nil, err
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		_, err = io.Copy(&buf, rdr)
+		_ = rdr.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
+
+// ReadGenesisArray reads a JSON array field of a genesis source one item at a time, passing
+// each item to fn, so that the array is never held in memory. A missing field is an empty array.
+func ReadGenesisArray(source appmodule.GenesisSource, field string, fn func(item json.RawMessage) error) error {
+	rdr, err := source(field)
+	if err != nil {
+		return err
+	}
+	if rdr == nil {
+		return nil
+	}
+	defer rdr.Close()
+
+	dec := json.NewDecoder(rdr)
+	if tok, err := dec.Token(); err != nil {
+		return fmt.Errorf("genesis field %s: %w", field, err)
+	} else if tok == nil {
+		// null
+		return nil
+	} else if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+		return fmt.Errorf("genesis field %s must be an array", field)
+	}
+
+	for dec.More() {
+		var item json.RawMessage
+		if err := dec.Decode(&item); err != nil {
+			return fmt.Errorf("genesis field %s: %w", field, err)
+		}
+		if err := fn(item); err != nil {
+			return err
+		}
+	}
+
+	if _, err := dec.Token(); err != nil {
+		return fmt.Errorf("genesis field %s: %w", field, err)
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package module
+
+import (
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// MigrationReport describes the migration of a module run by RunMigrations.
+type MigrationReport struct {
+	Module      string `json:"module"`
+	FromVersion uint64 `json:"from_version"`
+	ToVersion   uint64 `json:"to_version"`
+	// InitGenesis is true when the module is new, and was initialized with its default genesis.
+	InitGenesis bool          `json:"init_genesis,omitempty"`
+	Duration    time.Duration `json:"duration"`
+	GasUsed     uint64        `json:"gas_used"`
+	Error       string        `json:"error,omitempty"`
+}
+
+type migrationObserverKey struct{}
+
+// WithMigrationObserver returns a context in which RunMigrations reports each module
+// migration or initialization to the observer.
+func WithMigrationObserver(ctx sdk.Context, observer func(MigrationReport)) sdk.Context {
+	return ctx.WithValue(migrationObserverKey{}, observer)
+}
+
+// observeMigration runs the migration of a module, and reports it to the observer of the context, if any.
+func observeMigration(ctx sdk.Context, report MigrationReport, migrate func() error) error {
+	observer, ok := ctx.Value(migrationObserverKey{}).(func(MigrationReport))
+	if !ok {
+		return migrate()
+	}
+
+	start, gasBefore := time.Now(), ctx.GasMeter().GasConsumed()
+	err := migrate()
+	report.Duration = time.Since(start)
+	report.GasUsed = ctx.GasMeter().GasConsumed() - gasBefore
+	if err != nil {
+		report.Error = err.Error()
+	}
+
+	observer(report)
+	return err
+}
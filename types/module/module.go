@@ -97,6 +97,30 @@ type HasABCIGenesis interface {
 	ExportGenesis(context.Context) (json.RawMessage, error)
 }
 
+// HasGenesisExportToTarget is an extension interface for modules implementing HasGenesis or
+// HasABCIGenesis, streaming their genesis export to a genesis target one field of their JSON
+// genesis at a time, instead of holding it in memory, see Manager.ExportGenesisToDir. The
+// fields holding arrays are written one item at a time with a GenesisArrayWriter.
+type HasGenesisExportToTarget interface {
+	ExportGenesisToTarget(ctx context.Context, target appmodule.GenesisTarget) error
+}
+
+// HasGenesisImportFromSource is an extension interface for modules implementing HasGenesis and
+// HasGenesisExportToTarget, importing and validating the genesis they stream to a target one field
+// at a time, instead of holding it in memory, see Manager.InitGenesisFromDir. The fields holding
+// arrays are read one item at a time with ReadGenesisArray.
+type HasGenesisImportFromSource interface {
+	InitGenesisFromSource(ctx context.Context, source appmodule.GenesisSource) error
+	ValidateGenesisFromSource(source appmodule.GenesisSource) error
+}
+
+// HasABCIGenesisImportFromSource is the HasGenesisImportFromSource extension interface of modules
+// implementing HasABCIGenesis.
+type HasABCIGenesisImportFromSource interface {
+	InitGenesisFromSource(ctx context.Context, source appmodule.GenesisSource) ([]ValidatorUpdate, error)
+	ValidateGenesisFromSource(source appmodule.GenesisSource) error
+}
+
 // HasInvariants is the interface for registering invariants.
 type HasInvariants interface {
 	// RegisterInvariants registers module invariants.
@@ -426,19 +450,71 @@ func (m *Manager) RegisterServices(cfg Configurator) error {
 // module must return a non-empty validator set update to correctly initialize
 // the chain.
 func (m *Manager) InitGenesis(ctx sdk.Context, genesisData map[string]json.RawMessage) (*abci.InitChainResponse, error) {
-	var validatorUpdates []ValidatorUpdate
 	ctx.Logger().Info("initializing blockchain state from genesis.json")
+	return m.initGenesis(ctx, func(moduleName string) (moduleGenesis, error) {
+		return moduleGenesis{raw: genesisData[moduleName]}, nil
+	})
+}
+
+// moduleGenesis is the genesis of a module, either a raw JSON message or,
+// for core API modules only, a genesis source.
+type moduleGenesis struct {
+	raw    json.RawMessage
+	source appmodule.GenesisSource
+}
+
+func (g moduleGenesis) empty() bool {
+	return g.raw == nil && g.source == nil
+}
+
+// initGenesis initializes the modules in order, loading the genesis of each module
+// when it is initialized.
+func (m *Manager) initGenesis(ctx sdk.Context, load func(moduleName string) (moduleGenesis, error)) (*abci.InitChainResponse, error) {
+	var validatorUpdates []ValidatorUpdate
 	for _, moduleName := range m.OrderInitGenesis {
-		if genesisData[moduleName] == nil {
+		data, err := load(moduleName)
+		if err != nil {
+			return &abci.InitChainResponse{}, fmt.Errorf("failed to load genesis of %s: %w", moduleName, err)
+		}
+		if data.empty() {
 			continue
 		}
 
 		mod := m.Modules[moduleName]
+		if data.raw == nil {
+			// genesis sources are only supported by core API modules and modules importing
+			// their genesis from a source
+			var moduleValUpdates []ValidatorUpdate
+			if module, ok := genesisAuto(mod); ok {
+				ctx.Logger().Debug("running initialization for module", "module", moduleName)
+				err = module.InitGenesis(ctx, data.source)
+			} else if module, ok := mod.(HasGenesisImportFromSource); ok {
+				ctx.Logger().Debug("running initialization for module", "module", moduleName)
+				err = module.InitGenesisFromSource(ctx, data.source)
+			} else if module, ok := mod.(HasABCIGenesisImportFromSource); ok {
+				ctx.Logger().Debug("running initialization for module", "module", moduleName)
+				moduleValUpdates, err = module.InitGenesisFromSource(ctx, data.source)
+			} else {
+				return &abci.InitChainResponse{}, fmt.Errorf("genesis of %s must be a JSON document", moduleName)
+			}
+			if err != nil {
+				return &abci.InitChainResponse{}, err
+			}
+
+			if len(moduleValUpdates) > 0 {
+				if len(validatorUpdates) > 0 {
+					return &abci.InitChainResponse{}, errors.New("validator InitGenesis updates already set by a previous module")
+				}
+				validatorUpdates = moduleValUpdates
+			}
+			continue
+		}
+
 		// we might get an adapted module, a native core API module or a legacy module
 		if module, ok := mod.(appmodule.HasGenesisAuto); ok {
 			ctx.Logger().Debug("running initialization for module", "module", moduleName)
 			// core API genesis
-			source, err := genesis.SourceFromRawJSON(genesisData[moduleName])
+			source, err := genesis.SourceFromRawJSON(data.raw)
 			if err != nil {
 				return &abci.InitChainResponse{}, err
 			}
@@ -449,12 +525,12 @@ func (m *Manager) InitGenesis(ctx sdk.Context, genesisData map[string]json.RawMe
 			}
 		} else if module, ok := mod.(HasGenesis); ok {
 			ctx.Logger().Debug("running initialization for module", "module", moduleName)
-			if err := module.InitGenesis(ctx, genesisData[moduleName]); err != nil {
+			if err := module.InitGenesis(ctx, data.raw); err != nil {
 				return &abci.InitChainResponse{}, err
 			}
 		} else if module, ok := mod.(HasABCIGenesis); ok {
 			ctx.Logger().Debug("running initialization for module", "module", moduleName)
-			moduleValUpdates, err := module.InitGenesis(ctx, genesisData[moduleName])
+			moduleValUpdates, err := module.InitGenesis(ctx, data.raw)
 			if err != nil {
 				return &abci.InitChainResponse{}, err
 			}
@@ -685,29 +761,47 @@ func (m Manager) RunMigrations(ctx context.Context, cfg Configurator, fromVM app
 		// empty genesis state.
 		// 2. An existing chain is upgrading from version < 0.43 to v0.43+ for the first time.
 		// In this case, all modules have yet to be added to x/upgrade's VersionMap store.
+		report := MigrationReport{Module: moduleName, FromVersion: fromVersion, ToVersion: toVersion}
 		if exists {
-			err := c.runModuleMigrations(sdkCtx, moduleName, fromVersion, toVersion)
+			migrate := func() error {
+				return c.runModuleMigrations(sdkCtx, moduleName, fromVersion, toVersion)
+			}
+
+			var err error
+			if fromVersion != toVersion {
+				err = observeMigration(sdkCtx, report, migrate)
+			} else {
+				err = migrate()
+			}
 			if err != nil {
 				return nil, err
 			}
 		} else {
 			sdkCtx.Logger().Info(fmt.Sprintf("adding a new module: %s", moduleName))
-			if module, ok := m.Modules[moduleName].(HasGenesis); ok {
-				if err := module.InitGenesis(sdkCtx, module.DefaultGenesis()); err != nil {
-					return nil, err
+			report.InitGenesis = true
+			err := observeMigration(sdkCtx, report, func() error {
+				if module, ok := m.Modules[moduleName].(HasGenesis); ok {
+					if err := module.InitGenesis(sdkCtx, module.DefaultGenesis()); err != nil {
+						return err
+					}
 				}
-			}
-			if module, ok := m.Modules[moduleName].(HasABCIGenesis); ok {
-				moduleValUpdates, err := module.InitGenesis(sdkCtx, module.DefaultGenesis())
-				if err != nil {
-					return nil, err
+				if module, ok := m.Modules[moduleName].(HasABCIGenesis); ok {
+					moduleValUpdates, err := module.InitGenesis(sdkCtx, module.DefaultGenesis())
+					if err != nil {
+						return err
+					}
+
+					// The module manager assumes only one module will update the
+					// validator set, and it can't be a new module.
+					if len(moduleValUpdates) > 0 {
+						return errorsmod.Wrapf(sdkerrors.ErrLogic, "validator InitGenesis update is already set by another module")
+					}
 				}
 
-				// The module manager assumes only one module will update the
-				// validator set, and it can't be a new module.
-				if len(moduleValUpdates) > 0 {
-					return nil, errorsmod.Wrapf(sdkerrors.ErrLogic, "validator InitGenesis update is already set by another module")
-				}
+				return nil
+			})
+			if err != nil {
+				return nil, err
 			}
 		}
 
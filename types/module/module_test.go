@@ -4,7 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
 	"testing"
 
 	abci "github.com/cometbft/cometbft/abci/types"
@@ -252,6 +257,277 @@ func TestManager_ExportGenesis(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestManager_GenesisDir(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+
+	mockAppModule1 := mock.NewMockAppModuleWithAllExtensionsABCI(mockCtrl)
+	mockAppModule1.EXPECT().Name().AnyTimes().Return("module1")
+	mm := module.NewManager(mockAppModule1, module.CoreAppModuleAdaptor("mockCoreAppModule", MockCoreAppModule{}))
+	require.NotNil(t, mm)
+
+	ctx := sdk.NewContext(nil, false, log.NewNopLogger())
+	dir := filepath.Join(t.TempDir(), "app_state")
+	module1Genesis := json.RawMessage(`{"key1": "value1"}`)
+	mockAppModule1.EXPECT().ExportGenesis(gomock.Any()).Times(1).Return(module1Genesis, nil)
+	hash, err := mm.ExportGenesisToDir(ctx, dir, nil)
+	require.NoError(t, err)
+	actual, err := module.HashGenesisDir(dir)
+	require.NoError(t, err)
+	require.Equal(t, hash, actual)
+
+	// legacy modules are written in a file, core API modules in a directory
+	bz, err := os.ReadFile(filepath.Join(dir, "module1.json"))
+	require.NoError(t, err)
+	require.Equal(t, string(module1Genesis), string(bz))
+	bz, err = os.ReadFile(filepath.Join(dir, "mockCoreAppModule", "someField.json"))
+	require.NoError(t, err)
+	require.Equal(t, `"someKey"`, string(bz))
+
+	// the validation of the mock core API module always fails
+	mockAppModule1.EXPECT().ValidateGenesis(gomock.Eq(module1Genesis)).AnyTimes().Return(nil)
+	require.ErrorIs(t, mm.ValidateGenesisDir(dir, hash), errFoo)
+
+	mockAppModule1.EXPECT().InitGenesis(gomock.Any(), gomock.Eq(module1Genesis)).Times(1).Return([]module.ValidatorUpdate{{}}, nil)
+	_, err = mm.InitGenesisFromDir(ctx, dir, hash)
+	require.NoError(t, err)
+
+	// the module states must match the hash committed by the genesis file
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "module1.json"), []byte(`{"key1": "value2"}`), 0o600))
+	_, err = mm.InitGenesisFromDir(ctx, dir, hash)
+	require.ErrorContains(t, err, "the genesis file commits to "+hash)
+	require.ErrorContains(t, mm.ValidateGenesisDir(dir, hash), "the genesis file commits to "+hash)
+	_, err = mm.InitGenesisFromDir(ctx, dir, "")
+	require.ErrorContains(t, err, "the streamed genesis has no hash")
+
+	_, err = mm.ExportGenesisForModules(ctx, []string{"module1", "modulefoo"})
+	require.Error(t, err)
+	_, err = mm.ExportGenesisToDir(ctx, filepath.Join(t.TempDir(), "app_state"), []string{"modulefoo"})
+	require.Error(t, err)
+	_, err = mm.ExportGenesisToDir(ctx, dir, []string{"module1"})
+	require.ErrorContains(t, err, "is not empty")
+
+	// the app state references the directory relatively to the genesis file
+	appState, err := module.StreamedGenesis{Dir: "app_state", Hash: hash}.AppState()
+	require.NoError(t, err)
+	streamed, ok := module.ParseStreamedGenesis(appState)
+	require.True(t, ok)
+	require.Equal(t, dir, streamed.Path(filepath.Dir(dir)))
+	require.Equal(t, hash, streamed.Hash)
+
+	_, ok = module.ParseStreamedGenesis(json.RawMessage(`{"module1": {"key1": "value1"}}`))
+	require.False(t, ok)
+}
+
+// streamedGenesisModule is a legacy genesis module streaming its genesis export.
+type streamedGenesisModule struct {
+	items    int
+	exported *bool
+	genesis  *json.RawMessage
+}
+
+func (streamedGenesisModule) IsOnePerModuleType()             {}
+func (streamedGenesisModule) IsAppModule()                    {}
+func (streamedGenesisModule) DefaultGenesis() json.RawMessage { return nil }
+func (streamedGenesisModule) ValidateGenesis(json.RawMessage) error {
+	return nil
+}
+
+func (m streamedGenesisModule) InitGenesis(_ context.Context, data json.RawMessage) error {
+	*m.genesis = data
+	return nil
+}
+
+func (m streamedGenesisModule) ExportGenesis(context.Context) (json.RawMessage, error) {
+	return nil, errors.New("the genesis must be streamed")
+}
+
+func (m streamedGenesisModule) ExportGenesisToTarget(_ context.Context, target appmodule.GenesisTarget) error {
+	*m.exported = true
+	if err := module.WriteGenesisFields(target, json.RawMessage(`{"params":{"max":3},"items":[]}`), "params"); err != nil {
+		return err
+	}
+
+	w, err := module.NewGenesisArrayWriter(target, "items")
+	if err != nil {
+		return err
+	}
+	for i := 0; i < m.items; i++ {
+		if err := w.Write(json.RawMessage(fmt.Sprintf(`{"id":%d}`, i))); err != nil {
+			return err
+		}
+	}
+	return w.Close()
+}
+
+func TestManager_GenesisDirExportToTarget(t *testing.T) {
+	var (
+		exported bool
+		genesis  json.RawMessage
+	)
+	mm := module.NewManagerFromMap(map[string]appmodule.AppModule{
+		"streamed": streamedGenesisModule{items: 3, exported: &exported, genesis: &genesis},
+	})
+
+	ctx := sdk.NewContext(nil, false, log.NewNopLogger())
+	dir := filepath.Join(t.TempDir(), "app_state")
+	hash, err := mm.ExportGenesisToDir(ctx, dir, nil)
+	require.NoError(t, err)
+	require.True(t, exported)
+
+	// the module is exported by field, its arrays in chunks
+	bz, err := os.ReadFile(filepath.Join(dir, "streamed", "params.json"))
+	require.NoError(t, err)
+	require.Equal(t, `{"max":3}`, string(bz))
+	bz, err = os.ReadFile(filepath.Join(dir, "streamed", "items", "000000.ndjson"))
+	require.NoError(t, err)
+	require.Equal(t, "{\"id\":0}\n{\"id\":1}\n{\"id\":2}\n", string(bz))
+
+	// and initialized from a JSON document
+	require.NoError(t, mm.ValidateGenesisDir(dir, hash))
+	_, err = mm.InitGenesisFromDir(ctx, dir, hash)
+	// no module of the manager returns validator updates
+	require.ErrorContains(t, err, "validator set is empty after InitGenesis")
+	require.JSONEq(t, `{"items":[{"id":0},{"id":1},{"id":2}],"params":{"max":3}}`, string(genesis))
+}
+
+// streamedImportModule is a legacy genesis module streaming its genesis export and import.
+type streamedImportModule struct {
+	streamedGenesisModule
+	ids *[]int
+}
+
+func (m streamedImportModule) InitGenesisFromSource(_ context.Context, source appmodule.GenesisSource) error {
+	params, err := module.ReadGenesisFields(source, "params", "missing")
+	if err != nil {
+		return err
+	}
+	if string(params) != `{"params":{"max":3}}` {
+		return fmt.Errorf("unexpected params %s", params)
+	}
+
+	return module.ReadGenesisArray(source, "items", func(item json.RawMessage) error {
+		var v struct{ ID int }
+		if err := json.Unmarshal(item, &v); err != nil {
+			return err
+		}
+		*m.ids = append(*m.ids, v.ID)
+		return nil
+	})
+}
+
+func (m streamedImportModule) ValidateGenesisFromSource(source appmodule.GenesisSource) error {
+	return module.ReadGenesisArray(source, "items", func(json.RawMessage) error {
+		return errFoo
+	})
+}
+
+func TestManager_GenesisDirImportFromSource(t *testing.T) {
+	var (
+		exported bool
+		genesis  json.RawMessage
+		ids      []int
+	)
+	mm := module.NewManagerFromMap(map[string]appmodule.AppModule{
+		"streamed": streamedImportModule{
+			streamedGenesisModule: streamedGenesisModule{items: 3, exported: &exported, genesis: &genesis},
+			ids:                   &ids,
+		},
+	})
+
+	ctx := sdk.NewContext(nil, false, log.NewNopLogger())
+	dir := filepath.Join(t.TempDir(), "app_state")
+	hash, err := mm.ExportGenesisToDir(ctx, dir, nil)
+	require.NoError(t, err)
+
+	// the module is validated and initialized from the source of its directory
+	require.ErrorIs(t, mm.ValidateGenesisDir(dir, hash), errFoo)
+	_, err = mm.InitGenesisFromDir(ctx, dir, hash)
+	require.ErrorContains(t, err, "validator set is empty after InitGenesis")
+	require.Nil(t, genesis)
+	require.Equal(t, []int{0, 1, 2}, ids)
+}
+
+// itemsReader generates a JSON array of items, without holding it in memory.
+type itemsReader struct {
+	items   int
+	next    int
+	pending []byte
+}
+
+func (r *itemsReader) Read(p []byte) (int, error) {
+	if len(r.pending) == 0 {
+		switch {
+		case r.next > r.items:
+			return 0, io.EOF
+		case r.next == r.items:
+			r.pending = []byte("]")
+		case r.next == 0:
+			r.pending = fmt.Appendf(nil, `[{"id":%d,"data":"%0128d"}`, r.next, 0)
+		default:
+			r.pending = fmt.Appendf(nil, `,{"id":%d,"data":"%0128d"}`, r.next, 0)
+		}
+		r.next++
+	}
+
+	n := copy(p, r.pending)
+	r.pending = r.pending[n:]
+	return n, nil
+}
+
+func TestReadGenesisArray(t *testing.T) {
+	const items = 100_000 // about 15MB
+	source := func(field string) (io.ReadCloser, error) {
+		switch field {
+		case "items":
+			return io.NopCloser(&itemsReader{items: items}), nil
+		case "object":
+			return io.NopCloser(strings.NewReader(`{"id":1}`)), nil
+		case "null":
+			return io.NopCloser(strings.NewReader(`null`)), nil
+		default:
+			return nil, nil
+		}
+	}
+
+	// the array is read one item at a time, the live heap doesn't grow with the array
+	var stats runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&stats)
+	baseline, peak := stats.HeapAlloc, stats.HeapAlloc
+	count := 0
+	err := module.ReadGenesisArray(source, "items", func(item json.RawMessage) error {
+		var v struct{ ID int }
+		if err := json.Unmarshal(item, &v); err != nil {
+			return err
+		}
+		if v.ID != count {
+			return fmt.Errorf("expected item %d, got %d", count, v.ID)
+		}
+		count++
+
+		if count%10_000 == 0 {
+			runtime.GC()
+			runtime.ReadMemStats(&stats)
+			peak = max(peak, stats.HeapAlloc)
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, items, count)
+	require.Less(t, peak-baseline, uint64(1<<20), "the live heap grew by %d bytes", peak-baseline)
+
+	// a missing or null field is an empty array
+	for _, field := range []string{"missing", "null"} {
+		require.NoError(t, module.ReadGenesisArray(source, field, func(json.RawMessage) error {
+			return errFoo
+		}))
+	}
+	require.ErrorContains(t, module.ReadGenesisArray(source, "object", func(json.RawMessage) error {
+		return nil
+	}), "genesis field object must be an array")
+}
+
 func TestManager_EndBlock(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	t.Cleanup(mockCtrl.Finish)
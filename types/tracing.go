@@ -0,0 +1,75 @@
+package types
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	storetypes "cosmossdk.io/store/types"
+
+	"github.com/cosmos/cosmos-sdk/telemetry"
+)
+
+// spanKVStore traces the batch reads of a store: each iteration is covered by a span,
+// from the creation of the iterator until it is closed, which records the number of
+// entries and bytes read.
+type spanKVStore struct {
+	storetypes.KVStore
+	ctx   context.Context
+	store string
+}
+
+// traceKVStore returns the store tracing its batch reads in the span of ctx, if it is
+// recording.
+func traceKVStore(ctx context.Context, store storetypes.KVStore, name string) storetypes.KVStore {
+	if ctx == nil || !trace.SpanFromContext(ctx).IsRecording() {
+		return store
+	}
+	return spanKVStore{KVStore: store, ctx: ctx, store: name}
+}
+
+func (s spanKVStore) Iterator(start, end []byte) storetypes.Iterator {
+	return s.iterate(false, func() storetypes.Iterator { return s.KVStore.Iterator(start, end) })
+}
+
+func (s spanKVStore) ReverseIterator(start, end []byte) storetypes.Iterator {
+	return s.iterate(true, func() storetypes.Iterator { return s.KVStore.ReverseIterator(start, end) })
+}
+
+func (s spanKVStore) iterate(reverse bool, open func() storetypes.Iterator) storetypes.Iterator {
+	_, span := telemetry.StartSpan(s.ctx, "store.iterate",
+		attribute.String("store", s.store),
+		attribute.Bool("reverse", reverse),
+	)
+	return &spanIterator{Iterator: open(), span: span}
+}
+
+// spanIterator ends the span of an iteration when closed.
+type spanIterator struct {
+	storetypes.Iterator
+	span    trace.Span
+	entries int
+	bytes   int
+}
+
+func (it *spanIterator) Next() {
+	it.entries++
+	it.bytes += len(it.Iterator.Key()) + len(it.Iterator.Value())
+	it.Iterator.Next()
+}
+
+func (it *spanIterator) Close() error {
+	if it.Iterator.Valid() {
+		// the current entry was read, but not moved past
+		it.entries++
+		it.bytes += len(it.Iterator.Key()) + len(it.Iterator.Value())
+	}
+	err := it.Iterator.Close()
+	it.span.SetAttributes(
+		attribute.Int("entries", it.entries),
+		attribute.Int("bytes", it.bytes),
+	)
+	telemetry.EndSpan(it.span, err)
+	return err
+}
@@ -0,0 +1,65 @@
+package types_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	storetypes "cosmossdk.io/store/types"
+
+	"github.com/cosmos/cosmos-sdk/telemetry"
+	"github.com/cosmos/cosmos-sdk/testutil"
+)
+
+func TestKVStoreIterateSpans(t *testing.T) {
+	key := storetypes.NewKVStoreKey(t.Name())
+	ctx := testutil.DefaultContext(key, storetypes.NewTransientStoreKey("transient_"+t.Name()))
+	store := ctx.KVStore(key)
+	store.Set([]byte("a"), []byte("1"))
+	store.Set([]byte("b"), []byte("2"))
+	store.Set([]byte("c"), []byte("3"))
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(provider) })
+
+	spanCtx, span := telemetry.StartSpan(context.Background(), "tx")
+	tracedCtx := ctx.WithContext(spanCtx)
+
+	iter := tracedCtx.KVStore(key).Iterator(nil, nil)
+	for ; iter.Valid(); iter.Next() {
+	}
+	require.NoError(t, iter.Close())
+	// the iteration stops at the first entry
+	iter = tracedCtx.KVStore(key).ReverseIterator(nil, nil)
+	require.NoError(t, iter.Close())
+	span.End()
+
+	// stores aren't traced without a recording span
+	iter = ctx.KVStore(key).Iterator(nil, nil)
+	require.NoError(t, iter.Close())
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 3)
+	for _, s := range spans[:2] {
+		require.Equal(t, "store.iterate", s.Name())
+		require.Equal(t, span.SpanContext().SpanID(), s.Parent().SpanID())
+	}
+	require.Subset(t, spans[0].Attributes(), []attribute.KeyValue{
+		attribute.String("store", t.Name()),
+		attribute.Bool("reverse", false),
+		attribute.Int("entries", 3),
+		attribute.Int("bytes", 6),
+	})
+	require.Subset(t, spans[1].Attributes(), []attribute.KeyValue{
+		attribute.Bool("reverse", true),
+		attribute.Int("entries", 1),
+		attribute.Int("bytes", 2),
+	})
+}
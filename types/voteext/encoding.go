@@ -0,0 +1,123 @@
+package voteext
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// InjectedTxPrefix prefixes the pseudo-transactions injecting the results of the
+// providers in the proposals, so that they can't be mistaken for transactions.
+var InjectedTxPrefix = []byte("\x00cosmos-sdk/voteext/v1\x00")
+
+// IsInjectedTx returns whether the transaction is a pseudo-transaction injecting the
+// results of the providers.
+func IsInjectedTx(txBz []byte) bool {
+	return bytes.HasPrefix(txBz, InjectedTxPrefix)
+}
+
+// section is the encoded value or result of a provider.
+type section struct {
+	name string
+	data []byte
+}
+
+// injectedTx is the pseudo-transaction injecting the results of the providers, along
+// with the extended commit they are aggregated from.
+type injectedTx struct {
+	extCommit []byte
+	results   []section
+}
+
+// encodeSections appends the sections to the buffer, each as its length-prefixed name and
+// data.
+func encodeSections(buf []byte, sections []section) []byte {
+	for _, s := range sections {
+		buf = appendBytes(buf, []byte(s.name))
+		buf = appendBytes(buf, s.data)
+	}
+	return buf
+}
+
+func decodeSections(bz []byte) ([]section, error) {
+	var (
+		sections []section
+		name     []byte
+		data     []byte
+		err      error
+	)
+	for len(bz) > 0 {
+		if name, bz, err = readBytes(bz); err != nil {
+			return nil, err
+		}
+		if data, bz, err = readBytes(bz); err != nil {
+			return nil, err
+		}
+		if len(sections) > 0 && sections[len(sections)-1].name >= string(name) {
+			return nil, errors.New("sections must be sorted by name without duplicates")
+		}
+		sections = append(sections, section{name: string(name), data: data})
+	}
+	return sections, nil
+}
+
+func encodeInjectedTx(tx injectedTx) []byte {
+	buf := append([]byte{}, InjectedTxPrefix...)
+	buf = appendBytes(buf, tx.extCommit)
+	return encodeSections(buf, tx.results)
+}
+
+func decodeInjectedTx(bz []byte) (injectedTx, error) {
+	var (
+		tx  injectedTx
+		err error
+	)
+	if !IsInjectedTx(bz) {
+		return tx, errors.New("not an injected tx")
+	}
+	if tx.extCommit, bz, err = readBytes(bz[len(InjectedTxPrefix):]); err != nil {
+		return tx, err
+	}
+	if tx.results, err = decodeSections(bz); err != nil {
+		return tx, err
+	}
+	return tx, nil
+}
+
+func appendBytes(buf, bz []byte) []byte {
+	buf = binary.AppendUvarint(buf, uint64(len(bz)))
+	return append(buf, bz...)
+}
+
+func readBytes(bz []byte) ([]byte, []byte, error) {
+	size, n := binary.Uvarint(bz)
+	if n <= 0 {
+		return nil, nil, errors.New("invalid length prefix")
+	}
+	bz = bz[n:]
+	if uint64(len(bz)) < size {
+		return nil, nil, fmt.Errorf("expected %d bytes, got %d", size, len(bz))
+	}
+	return bz[:size], bz[size:], nil
+}
+
+type injectedTxKey struct{}
+
+// ContextWithInjectedTx returns a context carrying the pseudo-transaction of the block
+// being executed, applied by ApplyFromContext.
+func ContextWithInjectedTx(ctx context.Context, txBz []byte) context.Context {
+	return context.WithValue(ctx, injectedTxKey{}, txBz)
+}
+
+// ApplyFromContext applies the results of the pseudo-transaction carried by the context,
+// if any. It is called by the PreBlock of a module when the consensus server carries the
+// pseudo-transaction in the context, as the one of server/v2/cometbft.
+func (m *Manager) ApplyFromContext(ctx context.Context) error {
+	txBz, ok := ctx.Value(injectedTxKey{}).([]byte)
+	if !ok {
+		return nil
+	}
+	return m.ApplyInjectedTx(ctx, txBz)
+}
@@ -0,0 +1,262 @@
+// Package voteext implements a framework multiplexing the vote extensions of modules.
+//
+// Modules register typed providers with a Manager. Every provider contributes a value to
+// the vote extension of a validator, and aggregates the values voted by the validators,
+// weighted by their voting power, into a result. The proposer injects the results of every
+// provider in the proposal as a pseudo-transaction, along with the extended commit they
+// are computed from, so that the other validators verify the results by computing them
+// again. The results are then applied before the transactions of the block are executed.
+//
+// The ABCI handlers using a Manager are implemented by baseapp.VoteExtensionHandler, and
+// by the consensus server of server/v2/cometbft.
+package voteext
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+	cmtproto "github.com/cometbft/cometbft/api/cometbft/types/v1"
+
+	"cosmossdk.io/collections/codec"
+)
+
+// Vote is the value voted by a validator, weighted by its voting power.
+type Vote[V any] struct {
+	// Validator is the consensus address of the validator.
+	Validator []byte
+	// Power is the voting power of the validator.
+	Power int64
+	// Value is the value voted by the validator.
+	Value V
+}
+
+// Provider contributes a typed value V to the vote extensions, and aggregates the values
+// voted by the validators into a typed result R injected in the proposals.
+type Provider[V, R any] interface {
+	// ExtendVote returns the value the validator votes for at the given height.
+	ExtendVote(ctx context.Context, height int64) (V, error)
+	// VerifyVote verifies the value voted by a validator at the given height.
+	VerifyVote(ctx context.Context, height int64, value V) error
+	// Aggregate aggregates the values voted by the validators of the last commit into the
+	// result injected in the proposal. It must be deterministic, as the other validators
+	// compute the result again to verify it.
+	Aggregate(ctx context.Context, votes []Vote[V]) (R, error)
+	// ApplyResult applies the result injected in the block, before its transactions are
+	// executed.
+	ApplyResult(ctx context.Context, result R) error
+}
+
+// handler is a Provider working on encoded values.
+type handler interface {
+	extendVote(ctx context.Context, height int64) ([]byte, error)
+	verifyVote(ctx context.Context, height int64, bz []byte) error
+	aggregate(ctx context.Context, votes []Vote[[]byte]) ([]byte, error)
+	applyResult(ctx context.Context, bz []byte) error
+}
+
+type typedHandler[V, R any] struct {
+	provider    Provider[V, R]
+	voteCodec   codec.ValueCodec[V]
+	resultCodec codec.ValueCodec[R]
+}
+
+func (h typedHandler[V, R]) extendVote(ctx context.Context, height int64) ([]byte, error) {
+	value, err := h.provider.ExtendVote(ctx, height)
+	if err != nil {
+		return nil, err
+	}
+	return h.voteCodec.Encode(value)
+}
+
+func (h typedHandler[V, R]) verifyVote(ctx context.Context, height int64, bz []byte) error {
+	value, err := h.voteCodec.Decode(bz)
+	if err != nil {
+		return err
+	}
+	return h.provider.VerifyVote(ctx, height, value)
+}
+
+func (h typedHandler[V, R]) aggregate(ctx context.Context, votes []Vote[[]byte]) ([]byte, error) {
+	values := make([]Vote[V], 0, len(votes))
+	for _, vote := range votes {
+		// the votes were verified by the validators, a vote failing to decode is ignored
+		value, err := h.voteCodec.Decode(vote.Value)
+		if err != nil {
+			continue
+		}
+		values = append(values, Vote[V]{Validator: vote.Validator, Power: vote.Power, Value: value})
+	}
+	result, err := h.provider.Aggregate(ctx, values)
+	if err != nil {
+		return nil, err
+	}
+	return h.resultCodec.Encode(result)
+}
+
+func (h typedHandler[V, R]) applyResult(ctx context.Context, bz []byte) error {
+	result, err := h.resultCodec.Decode(bz)
+	if err != nil {
+		return err
+	}
+	return h.provider.ApplyResult(ctx, result)
+}
+
+// Manager multiplexes the vote extensions of the registered providers.
+type Manager struct {
+	handlers map[string]handler
+	// names are the names of the providers, sorted.
+	names []string
+}
+
+// NewManager creates a Manager without providers.
+func NewManager() *Manager {
+	return &Manager{handlers: make(map[string]handler)}
+}
+
+// Register registers a provider with the manager, with the codecs of its values and
+// results. The name identifies the values and results of the provider in the vote
+// extensions and in the proposals, and must not change.
+func Register[V, R any](m *Manager, name string, provider Provider[V, R], voteCodec codec.ValueCodec[V], resultCodec codec.ValueCodec[R]) error {
+	if name == "" {
+		return errors.New("provider name cannot be empty")
+	}
+	if _, ok := m.handlers[name]; ok {
+		return fmt.Errorf("provider %s already registered", name)
+	}
+	m.handlers[name] = typedHandler[V, R]{provider: provider, voteCodec: voteCodec, resultCodec: resultCodec}
+	m.names = append(m.names, name)
+	sort.Strings(m.names)
+	return nil
+}
+
+// ExtendVote returns the vote extension multiplexing the values of the providers. A
+// provider failing to return a value is left out of the extension, and its error is
+// returned along with the extension.
+func (m *Manager) ExtendVote(ctx context.Context, height int64) ([]byte, error) {
+	var (
+		sections []section
+		errs     []error
+	)
+	for _, name := range m.names {
+		bz, err := m.handlers[name].extendVote(ctx, height)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("provider %s: %w", name, err))
+			continue
+		}
+		sections = append(sections, section{name: name, data: bz})
+	}
+	return encodeSections(nil, sections), errors.Join(errs...)
+}
+
+// VerifyVoteExtension verifies the vote extension of a validator. Every value of the
+// extension must belong to a registered provider, and be verified by it. Providers may be
+// missing from the extension.
+func (m *Manager) VerifyVoteExtension(ctx context.Context, height int64, extension []byte) error {
+	sections, err := decodeSections(extension)
+	if err != nil {
+		return err
+	}
+	for _, s := range sections {
+		h, ok := m.handlers[s.name]
+		if !ok {
+			return fmt.Errorf("unknown provider %s", s.name)
+		}
+		if err := h.verifyVote(ctx, height, s.data); err != nil {
+			return fmt.Errorf("provider %s: %w", s.name, err)
+		}
+	}
+	return nil
+}
+
+// BuildInjectedTx aggregates the vote extensions of the extended commit into the results
+// of the providers, and returns the pseudo-transaction injecting them in the proposal.
+// Only the votes for the committed block are aggregated. The signatures of the vote
+// extensions must be verified by the caller.
+func (m *Manager) BuildInjectedTx(ctx context.Context, extCommit abci.ExtendedCommitInfo) ([]byte, error) {
+	results, err := m.aggregate(ctx, extCommit)
+	if err != nil {
+		return nil, err
+	}
+	commitBz, err := extCommit.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	return encodeInjectedTx(injectedTx{extCommit: commitBz, results: results}), nil
+}
+
+// VerifyInjectedTx verifies that the results of the pseudo-transaction are the ones
+// aggregated from its extended commit, and returns the extended commit, whose signatures
+// must be verified by the caller.
+func (m *Manager) VerifyInjectedTx(ctx context.Context, txBz []byte) (abci.ExtendedCommitInfo, error) {
+	var extCommit abci.ExtendedCommitInfo
+	tx, err := decodeInjectedTx(txBz)
+	if err != nil {
+		return extCommit, err
+	}
+	if err := extCommit.Unmarshal(tx.extCommit); err != nil {
+		return extCommit, fmt.Errorf("invalid extended commit: %w", err)
+	}
+
+	results, err := m.aggregate(ctx, extCommit)
+	if err != nil {
+		return extCommit, err
+	}
+	if !bytes.Equal(encodeSections(nil, results), encodeSections(nil, tx.results)) {
+		return extCommit, errors.New("injected results don't match the extended commit")
+	}
+	return extCommit, nil
+}
+
+// ApplyInjectedTx applies the results of the pseudo-transaction with their providers.
+func (m *Manager) ApplyInjectedTx(ctx context.Context, txBz []byte) error {
+	tx, err := decodeInjectedTx(txBz)
+	if err != nil {
+		return err
+	}
+	for _, s := range tx.results {
+		h, ok := m.handlers[s.name]
+		if !ok {
+			return fmt.Errorf("unknown provider %s", s.name)
+		}
+		if err := h.applyResult(ctx, s.data); err != nil {
+			return fmt.Errorf("provider %s: %w", s.name, err)
+		}
+	}
+	return nil
+}
+
+// aggregate aggregates the votes of the extended commit with every provider.
+func (m *Manager) aggregate(ctx context.Context, extCommit abci.ExtendedCommitInfo) ([]section, error) {
+	votes := make(map[string][]Vote[[]byte], len(m.names))
+	for _, vote := range extCommit.Votes {
+		if vote.BlockIdFlag != cmtproto.BlockIDFlagCommit || len(vote.VoteExtension) == 0 {
+			continue
+		}
+		sections, err := decodeSections(vote.VoteExtension)
+		if err != nil {
+			// the extensions were verified by the validators, an invalid one is ignored
+			continue
+		}
+		for _, s := range sections {
+			votes[s.name] = append(votes[s.name], Vote[[]byte]{
+				Validator: vote.Validator.Address,
+				Power:     vote.Validator.Power,
+				Value:     s.data,
+			})
+		}
+	}
+
+	results := make([]section, 0, len(m.names))
+	for _, name := range m.names {
+		bz, err := m.handlers[name].aggregate(ctx, votes[name])
+		if err != nil {
+			return nil, fmt.Errorf("provider %s: %w", name, err)
+		}
+		results = append(results, section{name: name, data: bz})
+	}
+	return results, nil
+}
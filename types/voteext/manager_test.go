@@ -0,0 +1,179 @@
+package voteext_test
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+	cmtproto "github.com/cometbft/cometbft/api/cometbft/types/v1"
+	"github.com/stretchr/testify/require"
+
+	"cosmossdk.io/collections"
+
+	"github.com/cosmos/cosmos-sdk/types/voteext"
+)
+
+// priceProvider votes for a price, and aggregates the prices into their stake-weighted
+// median.
+type priceProvider struct {
+	price   int64
+	applied []int64
+}
+
+func (p *priceProvider) ExtendVote(context.Context, int64) (int64, error) {
+	if p.price == 0 {
+		return 0, errors.New("no price")
+	}
+	return p.price, nil
+}
+
+func (p *priceProvider) VerifyVote(_ context.Context, _ int64, price int64) error {
+	if price <= 0 {
+		return errors.New("price must be positive")
+	}
+	return nil
+}
+
+func (p *priceProvider) Aggregate(_ context.Context, votes []voteext.Vote[int64]) (int64, error) {
+	if len(votes) == 0 {
+		return 0, nil
+	}
+	sort.Slice(votes, func(i, j int) bool { return votes[i].Value < votes[j].Value })
+	var total, sum int64
+	for _, v := range votes {
+		total += v.Power
+	}
+	for _, v := range votes {
+		sum += v.Power
+		if sum*2 >= total {
+			return v.Value, nil
+		}
+	}
+	return votes[len(votes)-1].Value, nil
+}
+
+func (p *priceProvider) ApplyResult(_ context.Context, price int64) error {
+	p.applied = append(p.applied, price)
+	return nil
+}
+
+// countProvider votes for a constant, and counts the votes.
+type countProvider struct {
+	applied []uint64
+}
+
+func (p *countProvider) ExtendVote(context.Context, int64) (string, error) { return "ok", nil }
+
+func (p *countProvider) VerifyVote(context.Context, int64, string) error { return nil }
+
+func (p *countProvider) Aggregate(_ context.Context, votes []voteext.Vote[string]) (uint64, error) {
+	return uint64(len(votes)), nil
+}
+
+func (p *countProvider) ApplyResult(_ context.Context, count uint64) error {
+	p.applied = append(p.applied, count)
+	return nil
+}
+
+func newManager(t *testing.T, price int64) (*voteext.Manager, *priceProvider, *countProvider) {
+	t.Helper()
+	prices, counts := &priceProvider{price: price}, &countProvider{}
+	m := voteext.NewManager()
+	require.NoError(t, voteext.Register[int64, int64](m, "price", prices, collections.Int64Value, collections.Int64Value))
+	require.NoError(t, voteext.Register[string, uint64](m, "count", counts, collections.StringValue, collections.Uint64Value))
+	return m, prices, counts
+}
+
+func extVote(t *testing.T, addr string, power, price int64, flag cmtproto.BlockIDFlag) abci.ExtendedVoteInfo {
+	t.Helper()
+	m, _, _ := newManager(t, price)
+	ext, err := m.ExtendVote(context.Background(), 1)
+	require.NoError(t, err)
+	return abci.ExtendedVoteInfo{
+		Validator:     abci.Validator{Address: []byte(addr), Power: power},
+		VoteExtension: ext,
+		BlockIdFlag:   flag,
+	}
+}
+
+func TestRegister(t *testing.T) {
+	m, _, _ := newManager(t, 1)
+	require.Error(t, voteext.Register[int64, int64](m, "price", &priceProvider{}, collections.Int64Value, collections.Int64Value))
+	require.Error(t, voteext.Register[int64, int64](m, "", &priceProvider{}, collections.Int64Value, collections.Int64Value))
+}
+
+func TestExtendVote(t *testing.T) {
+	ctx := context.Background()
+	m, _, _ := newManager(t, 10)
+	ext, err := m.ExtendVote(ctx, 1)
+	require.NoError(t, err)
+	require.NoError(t, m.VerifyVoteExtension(ctx, 1, ext))
+
+	// a failing provider is left out of the extension
+	m, _, _ = newManager(t, 0)
+	ext, err = m.ExtendVote(ctx, 1)
+	require.ErrorContains(t, err, "provider price: no price")
+	require.NotEmpty(t, ext)
+	require.NoError(t, m.VerifyVoteExtension(ctx, 1, ext))
+
+	// values are verified by their provider
+	invalid := voteext.NewManager()
+	require.NoError(t, voteext.Register[int64, int64](invalid, "price", &priceProvider{price: -1}, collections.Int64Value, collections.Int64Value))
+	ext, err = invalid.ExtendVote(ctx, 1)
+	require.NoError(t, err)
+	require.ErrorContains(t, m.VerifyVoteExtension(ctx, 1, ext), "price must be positive")
+
+	// values of unknown providers are rejected
+	unknown := voteext.NewManager()
+	require.NoError(t, voteext.Register[int64, int64](unknown, "other", &priceProvider{price: 1}, collections.Int64Value, collections.Int64Value))
+	ext, err = unknown.ExtendVote(ctx, 1)
+	require.NoError(t, err)
+	require.ErrorContains(t, m.VerifyVoteExtension(ctx, 1, ext), "unknown provider other")
+
+	require.Error(t, m.VerifyVoteExtension(ctx, 1, []byte{0xff}))
+}
+
+func TestInjectedTx(t *testing.T) {
+	ctx := context.Background()
+	extCommit := abci.ExtendedCommitInfo{
+		Round: 1,
+		Votes: []abci.ExtendedVoteInfo{
+			extVote(t, "val1", 10, 100, cmtproto.BlockIDFlagCommit),
+			extVote(t, "val2", 50, 200, cmtproto.BlockIDFlagCommit),
+			extVote(t, "val3", 30, 300, cmtproto.BlockIDFlagCommit),
+			// votes which aren't for the committed block are ignored
+			extVote(t, "val4", 100, 1, cmtproto.BlockIDFlagNil),
+		},
+	}
+
+	proposer, _, _ := newManager(t, 1)
+	txBz, err := proposer.BuildInjectedTx(ctx, extCommit)
+	require.NoError(t, err)
+	require.True(t, voteext.IsInjectedTx(txBz))
+	require.False(t, voteext.IsInjectedTx([]byte("tx")))
+
+	m, prices, counts := newManager(t, 1)
+	got, err := m.VerifyInjectedTx(ctx, txBz)
+	require.NoError(t, err)
+	require.Equal(t, extCommit.Votes[1].Validator.Address, got.Votes[1].Validator.Address)
+
+	require.NoError(t, m.ApplyInjectedTx(ctx, txBz))
+	require.Equal(t, []int64{200}, prices.applied)
+	require.Equal(t, []uint64{3}, counts.applied)
+
+	// the results are carried by the context
+	require.NoError(t, m.ApplyFromContext(ctx))
+	require.NoError(t, m.ApplyFromContext(voteext.ContextWithInjectedTx(ctx, txBz)))
+	require.Equal(t, []int64{200, 200}, prices.applied)
+
+	// the results must match the extended commit
+	tampered := append([]byte{}, txBz...)
+	tampered[len(tampered)-1]++
+	_, err = m.VerifyInjectedTx(ctx, tampered)
+	require.ErrorContains(t, err, "injected results don't match the extended commit")
+
+	_, err = m.VerifyInjectedTx(ctx, []byte("tx"))
+	require.Error(t, err)
+}
@@ -0,0 +1,99 @@
+package voteext
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+	cmtproto "github.com/cometbft/cometbft/api/cometbft/types/v1"
+	cryptoenc "github.com/cometbft/cometbft/crypto/encoding"
+	protoio "github.com/cosmos/gogoproto/io"
+
+	cryptocodec "github.com/cosmos/cosmos-sdk/crypto/codec"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// ValidatorStore returns the public keys of the validators, to verify the signatures of
+// their vote extensions. It is typically implemented by x/staking.
+type ValidatorStore interface {
+	GetPubKeyByConsAddr(context.Context, sdk.ConsAddress) (cryptotypes.PubKey, error)
+}
+
+// InProposal returns whether the proposals at the given height carry the vote extensions
+// of the previous height, i.e. whether vote extensions were enabled at the previous height.
+func InProposal(cp *cmtproto.ConsensusParams, height int64) bool {
+	if cp == nil {
+		return false
+	}
+	if f := cp.Feature; f != nil && f.VoteExtensionsEnableHeight != nil && f.VoteExtensionsEnableHeight.Value != 0 {
+		return height > f.VoteExtensionsEnableHeight.Value
+	}
+	return cp.Abci != nil && cp.Abci.VoteExtensionsEnableHeight != 0 && height > cp.Abci.VoteExtensionsEnableHeight // nolint:staticcheck // ignore linting error
+}
+
+// VerifyExtendedCommit verifies the signatures of the vote extensions of an extended
+// commit, carried by the proposal at the given height, and that the validators which
+// signed them hold more than 2/3 of the voting power.
+func VerifyExtendedCommit(ctx context.Context, valStore ValidatorStore, chainID string, height int64, extCommit abci.ExtendedCommitInfo) error {
+	var (
+		// Total voting power of all vote extensions.
+		totalVP int64
+		// Total voting power of all validators that submitted valid vote extensions.
+		sumVP int64
+	)
+
+	for _, vote := range extCommit.Votes {
+		totalVP += vote.Validator.Power
+
+		// Only check + include power if the vote is a commit vote.
+		if vote.BlockIdFlag != cmtproto.BlockIDFlagCommit {
+			continue
+		}
+		if len(vote.ExtensionSignature) == 0 {
+			return fmt.Errorf("received empty vote extension signature at height %d", height)
+		}
+
+		valConsAddr := sdk.ConsAddress(vote.Validator.Address)
+		pubKey, err := valStore.GetPubKeyByConsAddr(ctx, valConsAddr)
+		if err != nil {
+			return fmt.Errorf("failed to get validator %X public key: %w", valConsAddr, err)
+		}
+		cmtpk, err := cryptocodec.ToCmtProtoPublicKey(pubKey)
+		if err != nil {
+			return fmt.Errorf("failed to convert validator %X public key: %w", valConsAddr, err)
+		}
+		cmtPubKey, err := cryptoenc.PubKeyFromProto(cmtpk)
+		if err != nil {
+			return fmt.Errorf("failed to convert validator %X public key: %w", valConsAddr, err)
+		}
+
+		cve := cmtproto.CanonicalVoteExtension{
+			Extension: vote.VoteExtension,
+			Height:    height - 1, // the vote extension was signed in the previous height
+			Round:     int64(extCommit.Round),
+			ChainId:   chainID,
+		}
+		var buf bytes.Buffer
+		if err := protoio.NewDelimitedWriter(&buf).WriteMsg(&cve); err != nil {
+			return fmt.Errorf("failed to encode CanonicalVoteExtension: %w", err)
+		}
+		if !cmtPubKey.VerifySignature(buf.Bytes(), vote.ExtensionSignature) {
+			return fmt.Errorf("failed to verify validator %X vote extension signature", valConsAddr)
+		}
+
+		sumVP += vote.Validator.Power
+	}
+
+	if totalVP <= 0 {
+		return fmt.Errorf("total voting power must be positive, got: %d", totalVP)
+	}
+	if requiredVP := ((totalVP * 2) / 3) + 1; sumVP < requiredVP {
+		return fmt.Errorf(
+			"insufficient cumulative voting power received to verify vote extensions; got: %d, expected: >=%d",
+			sumVP, requiredVP,
+		)
+	}
+	return nil
+}
@@ -17,9 +17,11 @@ import (
 	authsigning "cosmossdk.io/x/auth/signing"
 	"cosmossdk.io/x/auth/types"
 	txsigning "cosmossdk.io/x/tx/signing"
+	"cosmossdk.io/x/tx/signing/eip712"
 
 	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
 	"github.com/cosmos/cosmos-sdk/crypto/keys/ed25519"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/ethsecp256k1"
 	kmultisig "github.com/cosmos/cosmos-sdk/crypto/keys/multisig"
 	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
 	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256r1"
@@ -124,6 +126,11 @@ func verifyIsOnCurve(pubKey cryptotypes.PubKey) (err error) {
 			return errorsmod.Wrap(sdkerrors.ErrInvalidPubKey, "secp256k1 key is not on curve")
 		}
 
+	case *ethsecp256k1.PubKey:
+		if _, err := secp256k1dcrd.ParsePubKey(typedPubKey.Bytes()); err != nil {
+			return errorsmod.Wrap(sdkerrors.ErrInvalidPubKey, "eth_secp256k1 key is not on curve")
+		}
+
 	case *secp256r1.PubKey:
 		pubKeyObject := typedPubKey.Key.PublicKey
 		if !pubKeyObject.IsOnCurve(pubKeyObject.X, pubKeyObject.Y) {
@@ -350,6 +357,14 @@ func (svd SigVerificationDecorator) verifySig(ctx sdk.Context, tx sdk.Tx, acc sd
 			Value:   anyPk.Value,
 		},
 	}
+	// EIP-712 sign bytes are hashed with Keccak-256 by Ethereum wallets, so they
+	// can only be verified with eth_secp256k1 keys.
+	if data, ok := sig.Data.(*signing.SingleSignatureData); ok && data.SignMode == signing.SignMode(eip712.SignMode) {
+		if _, ok := pubKey.(*ethsecp256k1.PubKey); !ok {
+			return errorsmod.Wrapf(sdkerrors.ErrInvalidPubKey, "SIGN_MODE_EIP_712 requires an eth_secp256k1 key, got %T", pubKey)
+		}
+	}
+
 	adaptableTx, ok := tx.(authsigning.V2AdaptableTx)
 	if !ok {
 		return fmt.Errorf("expected tx to implement V2AdaptableTx, got %T", tx)
@@ -498,6 +513,10 @@ func DefaultSigVerificationGasConsumer(meter storetypes.GasMeter, sig signing.Si
 		meter.ConsumeGas(params.SigVerifyCostSecp256k1, "ante verify: secp256k1")
 		return nil
 
+	case *ethsecp256k1.PubKey:
+		meter.ConsumeGas(params.SigVerifyCostSecp256k1, "ante verify: eth_secp256k1")
+		return nil
+
 	case *secp256r1.PubKey:
 		meter.ConsumeGas(params.SigVerifyCostSecp256r1(), "ante verify: secp256r1")
 		return nil
@@ -16,9 +16,11 @@ import (
 	txmodule "cosmossdk.io/x/auth/tx/config"
 	"cosmossdk.io/x/auth/types"
 	txsigning "cosmossdk.io/x/tx/signing"
+	"cosmossdk.io/x/tx/signing/eip712"
 
 	"github.com/cosmos/cosmos-sdk/codec"
 	"github.com/cosmos/cosmos-sdk/crypto/keys/ed25519"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/ethsecp256k1"
 	kmultisig "github.com/cosmos/cosmos-sdk/crypto/keys/multisig"
 	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
 	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256r1"
@@ -26,6 +28,7 @@ import (
 	"github.com/cosmos/cosmos-sdk/crypto/types/multisig"
 	"github.com/cosmos/cosmos-sdk/testutil/testdata"
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
 	"github.com/cosmos/cosmos-sdk/types/tx/signing"
 )
 
@@ -71,6 +74,7 @@ func TestConsumeSignatureVerificationGas(t *testing.T) {
 	}{
 		{"PubKeyEd25519", args{storetypes.NewInfiniteGasMeter(), nil, ed25519.GenPrivKey().PubKey(), params}, p.SigVerifyCostED25519, true},
 		{"PubKeySecp256k1", args{storetypes.NewInfiniteGasMeter(), nil, secp256k1.GenPrivKey().PubKey(), params}, p.SigVerifyCostSecp256k1, false},
+		{"PubKeyEthSecp256k1", args{storetypes.NewInfiniteGasMeter(), nil, ethsecp256k1.GenPrivKey().PubKey(), params}, p.SigVerifyCostSecp256k1, false},
 		{"PubKeySecp256r1", args{storetypes.NewInfiniteGasMeter(), nil, skR1.PubKey(), params}, p.SigVerifyCostSecp256r1(), false},
 		{"Multisig", args{storetypes.NewInfiniteGasMeter(), multisignature1, multisigKey1, params}, expectedCost1, false},
 		{"Multisig simulation", args{storetypes.NewInfiniteGasMeter(), multisigSimulationSignature, multisigKey1, params}, simulationExpectedCost, false},
@@ -236,6 +240,78 @@ func TestSigVerification(t *testing.T) {
 	}
 }
 
+func TestSigVerificationEIP712(t *testing.T) {
+	suite := SetupTestSuite(t, true)
+
+	// EIP-712 is registered as a custom sign mode, next to SIGN_MODE_DIRECT
+	cdc := codec.NewProtoCodec(suite.encCfg.InterfaceRegistry)
+	txConfig, err := authtx.NewTxConfigWithOptions(cdc, authtx.ConfigOptions{
+		EnabledSignModes: []signing.SignMode{signing.SignMode_SIGN_MODE_DIRECT},
+		CustomSignModes:  []txsigning.SignModeHandler{eip712.NewSignModeHandler(eip712.SignModeHandlerOptions{EIP155ChainID: 9000})},
+		SigningOptions: &txsigning.Options{
+			AddressCodec:          cdc.InterfaceRegistry().SigningContext().AddressCodec(),
+			ValidatorAddressCodec: cdc.InterfaceRegistry().SigningContext().ValidatorAddressCodec(),
+		},
+	})
+	require.NoError(t, err)
+	suite.clientCtx.TxConfig = txConfig
+
+	svd := ante.NewSigVerificationDecorator(suite.accountKeeper, txConfig.SignModeHandler(), ante.DefaultSigVerificationGasConsumer, nil)
+	antehandler := sdk.ChainAnteDecorators(svd)
+
+	ethPriv := ethsecp256k1.GenPrivKey()
+	secpPriv := secp256k1.GenPrivKey()
+	accNums := make(map[string]uint64)
+	for i, priv := range []cryptotypes.PrivKey{ethPriv, secpPriv} {
+		acc := suite.accountKeeper.NewAccountWithAddress(suite.ctx, sdk.AccAddress(priv.PubKey().Address()))
+		require.NoError(t, acc.SetAccountNumber(uint64(i)+1000))
+		suite.accountKeeper.SetAccount(suite.ctx, acc)
+		accNums[priv.Type()] = acc.GetAccountNumber()
+	}
+
+	testCases := []struct {
+		name     string
+		priv     cryptotypes.PrivKey
+		signMode signing.SignMode
+		memo     string
+		expErr   error
+	}{
+		{"eth_secp256k1 key with EIP-712", ethPriv, signing.SignMode(eip712.SignMode), "", nil},
+		{"eth_secp256k1 key with direct", ethPriv, signing.SignMode_SIGN_MODE_DIRECT, "", nil},
+		{"tx changed after signing", ethPriv, signing.SignMode(eip712.SignMode), "changed", sdkerrors.ErrUnauthorized},
+		{"secp256k1 key with EIP-712", secpPriv, signing.SignMode(eip712.SignMode), "", sdkerrors.ErrInvalidPubKey},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, _ := suite.ctx.CacheContext()
+			ctx = ctx.WithIsSigverifyTx(true)
+			suite.txBuilder = txConfig.NewTxBuilder()
+
+			require.NoError(t, suite.txBuilder.SetMsgs(testdata.NewTestMsg(sdk.AccAddress(tc.priv.PubKey().Address()))))
+			suite.txBuilder.SetFeeAmount(testdata.NewTestFeeAmount())
+			suite.txBuilder.SetGasLimit(testdata.NewTestGasLimit())
+
+			tx, err := suite.CreateTestTx(ctx, []cryptotypes.PrivKey{tc.priv}, []uint64{accNums[tc.priv.Type()]}, []uint64{0}, ctx.ChainID(), tc.signMode)
+			require.NoError(t, err)
+			if tc.memo != "" {
+				suite.txBuilder.SetMemo(tc.memo)
+				tx = suite.txBuilder.GetTx()
+			}
+
+			_, err = antehandler(ctx, tx, false)
+			if tc.expErr != nil {
+				require.ErrorIs(t, err, tc.expErr)
+				return
+			}
+			require.NoError(t, err)
+
+			acc := suite.accountKeeper.GetAccount(ctx, sdk.AccAddress(tc.priv.PubKey().Address()))
+			require.Equal(t, uint64(1), acc.GetSequence())
+			require.True(t, acc.GetPubKey().Equals(tc.priv.PubKey()))
+		})
+	}
+}
+
 func TestSigIntegration(t *testing.T) {
 	// generate private keys
 	privs := []cryptotypes.PrivKey{
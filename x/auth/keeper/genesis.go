@@ -24,21 +24,48 @@ func (ak AccountKeeper) InitGenesis(ctx context.Context, data types.GenesisState
 	}
 	accounts = types.SanitizeGenesisAccounts(accounts)
 
-	// Set the accounts and make sure the global account number matches the largest account number (even if zero).
-	var lastAccNum *uint64
+	importer := ak.NewGenesisImporter(ctx)
 	for _, acc := range accounts {
-		accNum := acc.GetAccountNumber()
-		for lastAccNum == nil || *lastAccNum < accNum {
-			n := ak.NextAccountNumber(ctx)
-			lastAccNum = &n
+		if err := importer.ImportAccount(acc); err != nil {
+			return err
 		}
-		ak.SetAccount(ctx, acc)
 	}
 
-	ak.GetModuleAccount(ctx, types.FeeCollectorName)
+	importer.Finish()
 	return nil
 }
 
+// GenesisImporter imports the accounts of a genesis state one at a time, e.g. from a
+// streamed genesis.
+type GenesisImporter struct {
+	ak         AccountKeeper
+	ctx        context.Context
+	lastAccNum *uint64
+}
+
+// NewGenesisImporter returns an importer of genesis accounts.
+func (ak AccountKeeper) NewGenesisImporter(ctx context.Context) *GenesisImporter {
+	return &GenesisImporter{ak: ak, ctx: ctx}
+}
+
+// ImportAccount sets an account, and makes sure the global account number is greater than
+// its account number. Unlike InitGenesis, the accounts aren't sanitized, so an account number
+// used twice is an error.
+func (g *GenesisImporter) ImportAccount(acc sdk.AccountI) error {
+	accNum := acc.GetAccountNumber()
+	for g.lastAccNum == nil || *g.lastAccNum < accNum {
+		n := g.ak.NextAccountNumber(g.ctx)
+		g.lastAccNum = &n
+	}
+
+	return g.ak.Accounts.Set(g.ctx, acc.GetAddress(), acc)
+}
+
+// Finish creates the fee collector account if it wasn't imported.
+func (g *GenesisImporter) Finish() {
+	g.ak.GetModuleAccount(g.ctx, types.FeeCollectorName)
+}
+
 // ExportGenesis returns a GenesisState for a given context and keeper
 func (ak AccountKeeper) ExportGenesis(ctx context.Context) (*types.GenesisState, error) {
 	params := ak.GetParams(ctx)
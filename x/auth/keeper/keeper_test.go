@@ -7,6 +7,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 
+	"cosmossdk.io/core/genesis"
 	"cosmossdk.io/core/header"
 	"cosmossdk.io/core/log"
 	storetypes "cosmossdk.io/store/types"
@@ -23,6 +24,7 @@ import (
 	"github.com/cosmos/cosmos-sdk/runtime"
 	"github.com/cosmos/cosmos-sdk/testutil"
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/module"
 	moduletestutil "github.com/cosmos/cosmos-sdk/types/module/testutil"
 )
 
@@ -241,3 +243,50 @@ func (suite *KeeperTestSuite) TestInitGenesis() {
 	// we expect nextNum to be 2 because we initialize fee_collector as account number 1
 	suite.Require().Equal(2, int(nextNum))
 }
+
+func (suite *KeeperTestSuite) TestExportGenesisToTarget() {
+	ctx := suite.ctx
+	pubKey := ed25519.GenPrivKey().PubKey()
+	genState := types.GenesisState{
+		Params: types.DefaultParams(),
+		Accounts: []*codectypes.Any{
+			codectypes.UnsafePackAny(&types.BaseAccount{
+				Address:       sdk.AccAddress(pubKey.Address()).String(),
+				PubKey:        codectypes.UnsafePackAny(pubKey),
+				AccountNumber: 0,
+				Sequence:      5,
+			}),
+			codectypes.UnsafePackAny(types.NewEmptyModuleAccount(multiPerm, "burner", "minter")),
+		},
+	}
+	suite.Require().NoError(suite.accountKeeper.InitGenesis(ctx, genState))
+
+	expected, err := suite.accountKeeper.ExportGenesis(ctx)
+	suite.Require().NoError(err)
+	expectedBz, err := suite.encCfg.Codec.MarshalJSON(expected)
+	suite.Require().NoError(err)
+
+	dir := suite.T().TempDir()
+	am := auth.NewAppModule(suite.encCfg.Codec, suite.accountKeeper, suite.acctsModKeeper, nil)
+	suite.Require().NoError(am.ExportGenesisToTarget(ctx, genesis.DirTarget(dir, 1)))
+
+	bz, err := module.ReadGenesisDirJSON(dir)
+	suite.Require().NoError(err)
+	suite.Require().JSONEq(string(expectedBz), string(bz))
+
+	var streamed types.GenesisState
+	suite.Require().NoError(suite.encCfg.Codec.UnmarshalJSON(bz, &streamed))
+	suite.Require().Len(streamed.Accounts, 3) // with the fee collector
+
+	// and imported one account at a time in a new state
+	suite.Require().NoError(am.ValidateGenesisFromSource(genesis.DirSource(dir)))
+	suite.SetupTest()
+	am = auth.NewAppModule(suite.encCfg.Codec, suite.accountKeeper, suite.acctsModKeeper, nil)
+	suite.Require().NoError(am.InitGenesisFromSource(suite.ctx, genesis.DirSource(dir)))
+	imported, err := suite.accountKeeper.ExportGenesis(suite.ctx)
+	suite.Require().NoError(err)
+	importedBz, err := suite.encCfg.Codec.MarshalJSON(imported)
+	suite.Require().NoError(err)
+	suite.Require().JSONEq(string(expectedBz), string(importedBz))
+	suite.Require().Equal(uint64(3), suite.accountKeeper.NextAccountNumber(suite.ctx))
+}
@@ -3,6 +3,7 @@ package auth
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 
 	gwruntime "github.com/grpc-ecosystem/grpc-gateway/runtime"
@@ -17,6 +18,8 @@ import (
 
 	"github.com/cosmos/cosmos-sdk/client"
 	"github.com/cosmos/cosmos-sdk/codec"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/cosmos/cosmos-sdk/types/module"
 	simtypes "github.com/cosmos/cosmos-sdk/types/simulation"
 )
@@ -35,6 +38,9 @@ var (
 	_ appmodule.AppModule     = AppModule{}
 	_ appmodule.HasServices   = AppModule{}
 	_ appmodule.HasMigrations = AppModule{}
+
+	_ module.HasGenesisExportToTarget   = AppModule{}
+	_ module.HasGenesisImportFromSource = AppModule{}
 )
 
 // AppModule implements an application module for the auth module.
@@ -147,6 +153,117 @@ func (am AppModule) ExportGenesis(ctx context.Context) (json.RawMessage, error)
 	return am.cdc.MarshalJSON(gs)
 }
 
+// ExportGenesisToTarget streams the exported genesis state of the auth module to
+// target, one account at a time.
+func (am AppModule) ExportGenesisToTarget(ctx context.Context, target appmodule.GenesisTarget) error {
+	bz, err := am.cdc.MarshalJSON(&types.GenesisState{Params: am.accountKeeper.GetParams(ctx)})
+	if err != nil {
+		return err
+	}
+	if err := module.WriteGenesisFields(target, bz, "params"); err != nil {
+		return err
+	}
+
+	w, err := module.NewGenesisArrayWriter(target, "accounts")
+	if err != nil {
+		return err
+	}
+	err = am.accountKeeper.Accounts.Walk(ctx, nil, func(key sdk.AccAddress, value sdk.AccountI) (stop bool, err error) {
+		genAcc, ok := value.(types.GenesisAccount)
+		if !ok {
+			return true, fmt.Errorf("unable to convert account with address %s into a genesis account: type %T", key, value)
+		}
+		accAny, err := codectypes.NewAnyWithValue(genAcc)
+		if err != nil {
+			return true, err
+		}
+		bz, err := am.cdc.MarshalJSON(accAny)
+		if err != nil {
+			return true, err
+		}
+		return false, w.Write(bz)
+	})
+	if err != nil {
+		return err
+	}
+	return w.Close()
+}
+
+// ValidateGenesisFromSource validates the genesis state of the auth module streamed from
+// source, one account at a time.
+func (am AppModule) ValidateGenesisFromSource(source appmodule.GenesisSource) error {
+	params, err := am.readGenesisParams(source)
+	if err != nil {
+		return err
+	}
+	if err := params.Validate(); err != nil {
+		return err
+	}
+
+	v := types.NewGenesisAccountsValidator()
+	return module.ReadGenesisArray(source, "accounts", func(item json.RawMessage) error {
+		acc, err := am.unmarshalGenesisAccount(item)
+		if err != nil {
+			return err
+		}
+		return v.Validate(acc)
+	})
+}
+
+// InitGenesisFromSource initializes the auth module from the genesis state streamed from
+// source, one account at a time.
+func (am AppModule) InitGenesisFromSource(ctx context.Context, source appmodule.GenesisSource) error {
+	params, err := am.readGenesisParams(source)
+	if err != nil {
+		return err
+	}
+	if err := am.accountKeeper.Params.Set(ctx, params); err != nil {
+		return err
+	}
+
+	importer := am.accountKeeper.NewGenesisImporter(ctx)
+	if err := module.ReadGenesisArray(source, "accounts", func(item json.RawMessage) error {
+		acc, err := am.unmarshalGenesisAccount(item)
+		if err != nil {
+			return err
+		}
+		return importer.ImportAccount(acc)
+	}); err != nil {
+		return err
+	}
+
+	importer.Finish()
+	return nil
+}
+
+// readGenesisParams reads the params of the genesis state streamed from source.
+func (am AppModule) readGenesisParams(source appmodule.GenesisSource) (types.Params, error) {
+	bz, err := module.ReadGenesisFields(source, "params")
+	if err != nil {
+		return types.Params{}, err
+	}
+
+	var gs types.GenesisState
+	if err := am.cdc.UnmarshalJSON(bz, &gs); err != nil {
+		return types.Params{}, fmt.Errorf("failed to unmarshal %s genesis state: %w", types.ModuleName, err)
+	}
+	return gs.Params, nil
+}
+
+// unmarshalGenesisAccount unmarshals an account of the genesis state, packed in an Any.
+func (am AppModule) unmarshalGenesisAccount(bz json.RawMessage) (types.GenesisAccount, error) {
+	var acc sdk.AccountI
+	if err := am.cdc.UnmarshalInterfaceJSON(bz, &acc); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal %s genesis account: %w", types.ModuleName, err)
+	}
+
+	genAcc, ok := acc.(types.GenesisAccount)
+	if !ok {
+		return nil, errors.New("expected genesis account")
+	}
+	return genAcc, nil
+}
+
 // ConsensusVersion implements HasConsensusVersion
 func (AppModule) ConsensusVersion() uint64 { return ConsensusVersion }
 
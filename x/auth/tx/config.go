@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 
+	signingv1beta1 "cosmossdk.io/api/cosmos/tx/signing/v1beta1"
 	"cosmossdk.io/core/address"
 	txdecode "cosmossdk.io/x/tx/decode"
 	txsigning "cosmossdk.io/x/tx/signing"
@@ -27,6 +28,7 @@ type config struct {
 	protoCodec     codec.Codec
 	signingContext *txsigning.Context
 	txDecoder      *txdecode.Decoder
+	checkTxDecoder sdk.TxDecoder
 }
 
 // ConfigOptions define the configuration of a TxConfig when calling NewTxConfigWithOptions.
@@ -47,8 +49,14 @@ type ConfigOptions struct {
 	// TextualCoinMetadataQueryFn is the function that will be used to query coin metadata when constructing
 	// textual sign mode handler. This is required if SIGN_MODE_TEXTUAL is enabled.
 	TextualCoinMetadataQueryFn textual.CoinMetadataQueryFn
+	// TextualCustomRenderers are the value renderers registered by modules for their scalars and messages,
+	// used by the textual sign mode handler.
+	TextualCustomRenderers []textual.CustomRenderers
 	// CustomSignModes are the custom sign modes that will be added to the txsigning.HandlerMap.
 	CustomSignModes []txsigning.SignModeHandler
+	// DecodePolicy is the policy enforced by the decoder returned by CheckTxDecoder, bounding e.g. the
+	// number of messages of the transactions received by the node. It is ignored if ProtoDecoder is set.
+	DecodePolicy DecodePolicy
 	// ProtoDecoder is the decoder that will be used to decode protobuf transactions.
 	ProtoDecoder sdk.TxDecoder
 	// ProtoEncoder is the encoder that will be used to encode protobuf transactions.
@@ -147,6 +155,7 @@ func NewSigningHandlerMap(configOpts ConfigOptions) (*txsigning.HandlerMap, erro
 				CoinMetadataQuerier: configOpts.TextualCoinMetadataQueryFn,
 				FileResolver:        signingOpts.FileResolver,
 				TypeResolver:        signingOpts.TypeResolver,
+				CustomRenderers:     configOpts.TextualCustomRenderers,
 			})
 			if configOpts.TextualCoinMetadataQueryFn == nil {
 				return nil, fmt.Errorf("cannot enable SIGN_MODE_TEXTUAL without a TextualCoinMetadataQueryFn")
@@ -157,9 +166,23 @@ func NewSigningHandlerMap(configOpts ConfigOptions) (*txsigning.HandlerMap, erro
 		}
 	}
 	for i, m := range configOpts.CustomSignModes {
+		if m == nil {
+			return nil, errors.New("nil custom sign mode handler")
+		}
 		handlers[i+lenSignModes] = m
 	}
 
+	seen := make(map[signingv1beta1.SignMode]bool, len(handlers))
+	for _, h := range handlers {
+		if h == nil {
+			continue
+		}
+		if seen[h.Mode()] {
+			return nil, fmt.Errorf("duplicate sign mode handler for %s", h.Mode())
+		}
+		seen[h.Mode()] = true
+	}
+
 	handler := txsigning.NewHandlerMap(handlers...)
 	return handler, nil
 }
@@ -196,6 +219,15 @@ func NewTxConfigWithOptions(protoCodec codec.Codec, configOptions ConfigOptions)
 		}
 		txConfig.decoder = txV2toInterface(configOptions.SigningOptions.AddressCodec, protoCodec, dec)
 		txConfig.txDecoder = dec
+
+		// the decode policy is node local, it must not apply to the transactions
+		// of proposals and blocks, which are decoded by the TxDecoder.
+		if !configOptions.DecodePolicy.IsZero() {
+			txConfig.checkTxDecoder, err = newCheckTxDecoder(configOptions.DecodePolicy, configOptions.SigningContext, txConfig.decoder)
+			if err != nil {
+				return nil, err
+			}
+		}
 	}
 	if configOptions.ProtoEncoder == nil {
 		txConfig.encoder = DefaultTxEncoder()
@@ -248,6 +280,16 @@ func (g config) TxDecoder() sdk.TxDecoder {
 	return g.decoder
 }
 
+// CheckTxDecoder returns the TxDecoder enforcing the DecodePolicy of the TxConfig, to be set
+// on BaseApp with SetCheckTxDecoder. It returns nil if no policy is set or if the TxConfig
+// wasn't built by NewTxConfigWithOptions.
+func CheckTxDecoder(txConfig client.TxConfig) sdk.TxDecoder {
+	if cfg, ok := txConfig.(*config); ok {
+		return cfg.checkTxDecoder
+	}
+	return nil
+}
+
 func (g config) TxJSONEncoder() sdk.TxEncoder {
 	return g.jsonEncoder
 }
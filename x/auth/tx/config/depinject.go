@@ -53,9 +53,22 @@ type ModuleInputs struct {
 	AccountKeeper          ante.AccountKeeper                 `optional:"true"`
 	FeeGrantKeeper         ante.FeegrantKeeper                `optional:"true"`
 	CustomSignModeHandlers func() []txsigning.SignModeHandler `optional:"true"`
+	CustomSignModes        []CustomSignMode                   `optional:"true"`
 	CustomGetSigners       []txsigning.CustomGetSigner        `optional:"true"`
+	TextualRenderers       []textual.CustomRenderers          `optional:"true"`
+	DecodePolicy           tx.DecodePolicy                    `optional:"true"`
 }
 
+// CustomSignMode is a custom sign mode handler contributed by a module, e.g. an
+// EIP-712 typed-data sign mode. All the custom sign modes provided in the app
+// container are enabled in the TxConfig, next to the default ones.
+type CustomSignMode struct {
+	Handler txsigning.SignModeHandler
+}
+
+// IsManyPerContainerType indicates that this is a depinject.ManyPerContainerType.
+func (CustomSignMode) IsManyPerContainerType() {}
+
 type ModuleOutputs struct {
 	depinject.Out
 
@@ -74,6 +87,10 @@ func ProvideModule(in ModuleInputs) ModuleOutputs {
 		customSignModeHandlers = in.CustomSignModeHandlers()
 	}
 
+	for _, mode := range in.CustomSignModes {
+		customSignModeHandlers = append(customSignModeHandlers, mode.Handler)
+	}
+
 	txConfigOptions := tx.ConfigOptions{
 		EnabledSignModes: tx.DefaultSignModes,
 		SigningOptions: &txsigning.Options{
@@ -83,6 +100,7 @@ func ProvideModule(in ModuleInputs) ModuleOutputs {
 			CustomGetSigners:      make(map[protoreflect.FullName]txsigning.GetSignersFunc),
 		},
 		CustomSignModes: customSignModeHandlers,
+		DecodePolicy:    in.DecodePolicy,
 	}
 
 	for _, mode := range in.CustomGetSigners {
@@ -93,6 +111,7 @@ func ProvideModule(in ModuleInputs) ModuleOutputs {
 	if in.MetadataBankKeeper != nil {
 		txConfigOptions.EnabledSignModes = append(txConfigOptions.EnabledSignModes, signingtypes.SignMode_SIGN_MODE_TEXTUAL)
 		txConfigOptions.TextualCoinMetadataQueryFn = NewBankKeeperCoinMetadataQueryFn(in.MetadataBankKeeper)
+		txConfigOptions.TextualCustomRenderers = in.TextualRenderers
 	}
 
 	txConfig, err := tx.NewTxConfigWithOptions(in.Codec, txConfigOptions)
@@ -101,6 +120,11 @@ func ProvideModule(in ModuleInputs) ModuleOutputs {
 	}
 
 	baseAppOption := func(app *baseapp.BaseApp) {
+		// the decode policy only applies to the transactions received by CheckTx
+		if checkTxDecoder := tx.CheckTxDecoder(txConfig); checkTxDecoder != nil {
+			app.SetCheckTxDecoder(checkTxDecoder)
+		}
+
 		// AnteHandlers
 		if !in.Config.SkipAnteHandler {
 			anteHandler, err := newAnteHandler(txConfig, in)
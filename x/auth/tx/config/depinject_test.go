@@ -0,0 +1,63 @@
+package tx_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	txconfigv1 "cosmossdk.io/api/cosmos/tx/config/v1"
+	signingv1beta1 "cosmossdk.io/api/cosmos/tx/signing/v1beta1"
+	"cosmossdk.io/core/address"
+	"cosmossdk.io/core/appmodule"
+	"cosmossdk.io/depinject"
+	txconfig "cosmossdk.io/x/auth/tx/config"
+	"cosmossdk.io/x/tx/signing"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/codec"
+	"github.com/cosmos/cosmos-sdk/codec/testutil"
+)
+
+type customSignModeHandler struct{}
+
+func (customSignModeHandler) Mode() signingv1beta1.SignMode { return signingv1beta1.SignMode(712) }
+
+func (customSignModeHandler) GetSignBytes(context.Context, signing.SignerData, signing.TxData) ([]byte, error) {
+	return []byte("custom"), nil
+}
+
+func ProvideCustomSignMode() txconfig.CustomSignMode {
+	return txconfig.CustomSignMode{Handler: customSignModeHandler{}}
+}
+
+var interfaceRegistry = testutil.CodecOptions{}.NewInterfaceRegistry()
+
+func ProvideCodecs() (codec.Codec, address.Codec, address.ValidatorAddressCodec) {
+	signingCtx := interfaceRegistry.SigningContext()
+	return codec.NewProtoCodec(interfaceRegistry), signingCtx.AddressCodec(), signingCtx.ValidatorAddressCodec()
+}
+
+func TestProvideCustomSignMode(t *testing.T) {
+	var txConfig client.TxConfig
+	err := depinject.Inject(
+		depinject.Configs(
+			depinject.Supply(
+				&txconfigv1.Config{SkipAnteHandler: true, SkipPostHandler: true},
+				appmodule.Environment{},
+			),
+			depinject.Provide(
+				ProvideCodecs,
+				txconfig.ProvideModule,
+				txconfig.ProvideProtoRegistry,
+				ProvideCustomSignMode,
+			),
+		),
+		&txConfig,
+	)
+	require.NoError(t, err)
+
+	// the sign mode provided by the module is enabled next to the default ones
+	require.Contains(t, txConfig.SignModeHandler().SupportedModes(), signingv1beta1.SignMode(712))
+	require.Contains(t, txConfig.SignModeHandler().SupportedModes(), signingv1beta1.SignMode_SIGN_MODE_DIRECT)
+}
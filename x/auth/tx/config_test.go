@@ -1,21 +1,30 @@
 package tx_test
 
 import (
+	"context"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
 
+	bankv1beta1 "cosmossdk.io/api/cosmos/bank/v1beta1"
 	_ "cosmossdk.io/api/cosmos/crypto/secp256k1"
+	signingv1beta1 "cosmossdk.io/api/cosmos/tx/signing/v1beta1"
 	coretransaction "cosmossdk.io/core/transaction"
 	"cosmossdk.io/x/auth/tx"
 	txtestutil "cosmossdk.io/x/auth/tx/testutil"
 	"cosmossdk.io/x/tx/signing"
+	"cosmossdk.io/x/tx/signing/textual"
 
 	"github.com/cosmos/cosmos-sdk/codec"
 	"github.com/cosmos/cosmos-sdk/codec/testutil"
 	"github.com/cosmos/cosmos-sdk/std"
 	"github.com/cosmos/cosmos-sdk/testutil/testdata"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	signingtypes "github.com/cosmos/cosmos-sdk/types/tx/signing"
 )
 
 func TestGenerator(t *testing.T) {
@@ -40,3 +49,127 @@ func TestConfigOptions(t *testing.T) {
 	handler := txConfig.SignModeHandler()
 	require.NotNil(t, handler)
 }
+
+type customSignModeHandler struct {
+	mode signingv1beta1.SignMode
+}
+
+func (h customSignModeHandler) Mode() signingv1beta1.SignMode { return h.mode }
+
+func (h customSignModeHandler) GetSignBytes(context.Context, signing.SignerData, signing.TxData) ([]byte, error) {
+	return []byte("custom"), nil
+}
+
+func TestCustomSignModes(t *testing.T) {
+	interfaceRegistry := testutil.CodecOptions{}.NewInterfaceRegistry()
+	protoCodec := codec.NewProtoCodec(interfaceRegistry)
+	signingOptions := &signing.Options{
+		AddressCodec:          interfaceRegistry.SigningContext().AddressCodec(),
+		ValidatorAddressCodec: interfaceRegistry.SigningContext().ValidatorAddressCodec(),
+	}
+
+	customMode := signingv1beta1.SignMode(712)
+	txConfig, err := tx.NewTxConfigWithOptions(protoCodec, tx.ConfigOptions{
+		SigningOptions:  signingOptions,
+		CustomSignModes: []signing.SignModeHandler{customSignModeHandler{mode: customMode}},
+	})
+	require.NoError(t, err)
+	require.Contains(t, txConfig.SignModeHandler().SupportedModes(), customMode)
+	require.Equal(t, signingv1beta1.SignMode_SIGN_MODE_DIRECT, txConfig.SignModeHandler().DefaultMode())
+
+	// a custom sign mode cannot override an enabled one
+	_, err = tx.NewTxConfigWithOptions(protoCodec, tx.ConfigOptions{
+		SigningOptions:  signingOptions,
+		CustomSignModes: []signing.SignModeHandler{customSignModeHandler{mode: signingv1beta1.SignMode_SIGN_MODE_DIRECT}},
+	})
+	require.ErrorContains(t, err, "duplicate sign mode handler")
+}
+
+// sendRenderer renders a MsgSend as its recipient only.
+type sendRenderer struct {
+	lossy bool
+}
+
+func (vr sendRenderer) Format(_ context.Context, v protoreflect.Value) ([]textual.Screen, error) {
+	toAddress := v.Message().Get(v.Message().Descriptor().Fields().ByName("to_address")).String()
+	return []textual.Screen{{Content: "Send to " + toAddress}}, nil
+}
+
+func (vr sendRenderer) Parse(_ context.Context, screens []textual.Screen) (protoreflect.Value, error) {
+	msg := &bankv1beta1.MsgSend{}
+	if !vr.lossy {
+		msg.ToAddress = strings.TrimPrefix(screens[0].Content, "Send to ")
+	}
+	return protoreflect.ValueOfMessage(msg.ProtoReflect()), nil
+}
+
+func TestTextualCustomRenderers(t *testing.T) {
+	interfaceRegistry := testutil.CodecOptions{}.NewInterfaceRegistry()
+	protoCodec := codec.NewProtoCodec(interfaceRegistry)
+	newConfigOptions := func(vr textual.ValueRenderer) tx.ConfigOptions {
+		return tx.ConfigOptions{
+			EnabledSignModes: append(tx.DefaultSignModes, signingtypes.SignMode_SIGN_MODE_TEXTUAL),
+			SigningOptions: &signing.Options{
+				AddressCodec:          interfaceRegistry.SigningContext().AddressCodec(),
+				ValidatorAddressCodec: interfaceRegistry.SigningContext().ValidatorAddressCodec(),
+			},
+			TextualCoinMetadataQueryFn: func(context.Context, string) (*bankv1beta1.Metadata, error) { return nil, nil },
+			TextualCustomRenderers: []textual.CustomRenderers{{
+				Messages: map[protoreflect.FullName]textual.MessageRendererCreator{
+					"cosmos.bank.v1beta1.MsgSend": func(*textual.SignModeHandler) textual.ValueRenderer { return vr },
+				},
+				Examples: []proto.Message{&bankv1beta1.MsgSend{ToAddress: "cosmos1ulav3hsenupswqfkw2y3sup5kgtqwnvqa8eyhs"}},
+			}},
+		}
+	}
+
+	txConfig, err := tx.NewTxConfigWithOptions(protoCodec, newConfigOptions(sendRenderer{}))
+	require.NoError(t, err)
+	require.Contains(t, txConfig.SignModeHandler().SupportedModes(), signingv1beta1.SignMode_SIGN_MODE_TEXTUAL)
+
+	// the examples of the custom renderers are validated by the textual sign mode handler
+	_, err = tx.NewTxConfigWithOptions(protoCodec, newConfigOptions(sendRenderer{lossy: true}))
+	require.ErrorContains(t, err, "cosmos.bank.v1beta1.MsgSend does not round trip")
+}
+
+func TestDecodePolicy(t *testing.T) {
+	interfaceRegistry := testutil.CodecOptions{}.NewInterfaceRegistry()
+	std.RegisterInterfaces(interfaceRegistry)
+	interfaceRegistry.RegisterImplementations((*coretransaction.Msg)(nil), &testdata.TestMsg{})
+	protoCodec := codec.NewProtoCodec(interfaceRegistry)
+	txConfig, err := tx.NewTxConfigWithOptions(protoCodec, tx.ConfigOptions{
+		SigningOptions: &signing.Options{
+			AddressCodec:          interfaceRegistry.SigningContext().AddressCodec(),
+			ValidatorAddressCodec: interfaceRegistry.SigningContext().ValidatorAddressCodec(),
+		},
+		DecodePolicy: tx.DecodePolicy{MaxMsgs: 1},
+	})
+	require.NoError(t, err)
+
+	_, _, addr := testdata.KeyTestPubAddr()
+	addrStr, err := interfaceRegistry.SigningContext().AddressCodec().BytesToString(addr)
+	require.NoError(t, err)
+	msg := testdata.NewTestMsg()
+	msg.Signers = []string{addrStr}
+
+	builder := txConfig.NewTxBuilder()
+	require.NoError(t, builder.SetMsgs(msg))
+	txBytes, err := txConfig.TxEncoder()(builder.GetTx())
+	require.NoError(t, err)
+	checkTxDecoder := tx.CheckTxDecoder(txConfig)
+	require.NotNil(t, checkTxDecoder)
+	_, err = checkTxDecoder(txBytes)
+	require.NoError(t, err)
+
+	require.NoError(t, builder.SetMsgs(msg, msg))
+	txBytes, err = txConfig.TxEncoder()(builder.GetTx())
+	require.NoError(t, err)
+	_, err = checkTxDecoder(txBytes)
+	require.ErrorIs(t, err, sdkerrors.ErrTxDecode)
+	require.ErrorContains(t, err, "too many messages")
+	// the policy is node local, the transactions of blocks are decoded regardless of it
+	_, err = txConfig.TxDecoder()(txBytes)
+	require.NoError(t, err)
+
+	require.Nil(t, tx.CheckTxDecoder(tx.NewTxConfig(protoCodec, interfaceRegistry.SigningContext().AddressCodec(), interfaceRegistry.SigningContext().ValidatorAddressCodec(), tx.DefaultSignModes)))
+}
@@ -0,0 +1,243 @@
+package tx
+
+import (
+	"fmt"
+
+	"github.com/cosmos/cosmos-proto/anyutil"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	txv1beta1 "cosmossdk.io/api/cosmos/tx/v1beta1"
+	errorsmod "cosmossdk.io/errors"
+	txsigning "cosmossdk.io/x/tx/signing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+)
+
+const anyFullName = "google.protobuf.Any"
+
+// DecodePolicy bounds the shape of the transactions received by a node. It is
+// enforced by the TxDecoder returned by CheckTxDecoder, before the ante handlers
+// run, so that nodes can cheaply reject spam transactions before verifying their
+// signatures. It is node local, and never applies to the transactions of
+// proposals and blocks. The zero value imposes no limits.
+type DecodePolicy struct {
+	// MaxMsgs is the maximum number of messages of a transaction, or 0 for no limit.
+	MaxMsgs int
+	// MaxMsgSize is the maximum size in bytes of an encoded message, or 0 for
+	// no limit.
+	MaxMsgSize int
+	// MaxAnyDepth is the maximum nesting depth of Anys in a transaction, or 0
+	// for no limit. The messages of a transaction are at depth 1, so a
+	// MaxAnyDepth of 1 rejects messages containing Anys, e.g. an authz MsgExec.
+	MaxAnyDepth int
+	// AllowedTypeURLs, if not empty, are the only type URLs accepted for the
+	// messages of a transaction and the Anys nested in them, e.g. the messages
+	// executed by an authz MsgExec or the public key of a MsgCreateValidator.
+	AllowedTypeURLs []string
+	// DeniedTypeURLs are the type URLs rejected for the messages of a
+	// transaction and the Anys nested in them.
+	DeniedTypeURLs []string
+}
+
+// IsZero returns true if the policy imposes no limits.
+func (p DecodePolicy) IsZero() bool {
+	return p.MaxMsgs == 0 && p.MaxMsgSize == 0 && p.MaxAnyDepth == 0 &&
+		len(p.AllowedTypeURLs) == 0 && len(p.DeniedTypeURLs) == 0
+}
+
+// Validate validates the policy.
+func (p DecodePolicy) Validate() error {
+	switch {
+	case p.MaxMsgs < 0:
+		return fmt.Errorf("max messages cannot be negative, got %d", p.MaxMsgs)
+	case p.MaxMsgSize < 0:
+		return fmt.Errorf("max message size cannot be negative, got %d", p.MaxMsgSize)
+	case p.MaxAnyDepth < 0:
+		return fmt.Errorf("max any depth cannot be negative, got %d", p.MaxAnyDepth)
+	}
+
+	for _, typeURL := range p.DeniedTypeURLs {
+		for _, allowed := range p.AllowedTypeURLs {
+			if typeURL == allowed {
+				return fmt.Errorf("type URL %s is both allowed and denied", typeURL)
+			}
+		}
+	}
+
+	return nil
+}
+
+// newCheckTxDecoder returns a TxDecoder checking the transactions against the
+// policy before decoding them with decoder.
+func newCheckTxDecoder(policy DecodePolicy, signingCtx *txsigning.Context, decoder sdk.TxDecoder) (sdk.TxDecoder, error) {
+	checker, err := newDecodePolicyChecker(policy, signingCtx.FileResolver(), signingCtx.TypeResolver())
+	if err != nil {
+		return nil, fmt.Errorf("invalid decode policy: %w", err)
+	}
+
+	return func(txBytes []byte) (sdk.Tx, error) {
+		if err := checker.check(txBytes); err != nil {
+			return nil, err
+		}
+		return decoder(txBytes)
+	}, nil
+}
+
+// decodePolicyChecker checks transactions against a DecodePolicy.
+type decodePolicyChecker struct {
+	DecodePolicy
+	allowed map[string]bool
+	denied  map[string]bool
+
+	fileResolver txsigning.ProtoFileResolver
+	typeResolver protoregistry.MessageTypeResolver
+}
+
+func newDecodePolicyChecker(policy DecodePolicy, fileResolver txsigning.ProtoFileResolver, typeResolver protoregistry.MessageTypeResolver) (*decodePolicyChecker, error) {
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+
+	c := &decodePolicyChecker{DecodePolicy: policy, fileResolver: fileResolver, typeResolver: typeResolver}
+	if len(policy.AllowedTypeURLs) > 0 {
+		c.allowed = make(map[string]bool, len(policy.AllowedTypeURLs))
+		for _, typeURL := range policy.AllowedTypeURLs {
+			c.allowed[typeURL] = true
+		}
+	}
+	if len(policy.DeniedTypeURLs) > 0 {
+		c.denied = make(map[string]bool, len(policy.DeniedTypeURLs))
+		for _, typeURL := range policy.DeniedTypeURLs {
+			c.denied[typeURL] = true
+		}
+	}
+
+	return c, nil
+}
+
+// check checks the body of an encoded transaction. Malformed transactions are
+// left to the decoder to reject.
+func (c *decodePolicyChecker) check(txBytes []byte) error {
+	var raw txv1beta1.TxRaw
+	if err := proto.Unmarshal(txBytes, &raw); err != nil {
+		return nil
+	}
+	var body txv1beta1.TxBody
+	if err := proto.Unmarshal(raw.BodyBytes, &body); err != nil {
+		return nil
+	}
+
+	if err := c.checkBody(&body); err != nil {
+		return err
+	}
+	if !c.checksNested() {
+		return nil
+	}
+
+	for _, anyMsg := range body.Messages {
+		msg, err := anyutil.Unpack(anyMsg, c.fileResolver, c.typeResolver)
+		if err != nil {
+			return errorsmod.Wrap(sdkerrors.ErrTxDecode, err.Error())
+		}
+		if err := c.checkNested(msg.ProtoReflect(), 1); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checksNested returns true if the policy requires walking the Anys nested
+// in the messages.
+func (c *decodePolicyChecker) checksNested() bool {
+	return c.MaxAnyDepth > 0 || c.allowed != nil || c.denied != nil
+}
+
+// checkBody checks the messages count, sizes and type URLs of a transaction
+// body, which only requires the body to be unmarshalled.
+func (c *decodePolicyChecker) checkBody(body *txv1beta1.TxBody) error {
+	if c.MaxMsgs > 0 && len(body.Messages) > c.MaxMsgs {
+		return errorsmod.Wrapf(sdkerrors.ErrTxDecode, "decode policy violation: too many messages: got %d, max %d", len(body.Messages), c.MaxMsgs)
+	}
+
+	for _, anyMsg := range body.Messages {
+		if c.MaxMsgSize > 0 && len(anyMsg.Value) > c.MaxMsgSize {
+			return errorsmod.Wrapf(sdkerrors.ErrTxDecode, "decode policy violation: message %s is too large: got %d bytes, max %d", anyMsg.TypeUrl, len(anyMsg.Value), c.MaxMsgSize)
+		}
+		if err := c.checkTypeURL(anyMsg.TypeUrl); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *decodePolicyChecker) checkTypeURL(typeURL string) error {
+	if c.denied[typeURL] {
+		return errorsmod.Wrapf(sdkerrors.ErrTxDecode, "decode policy violation: type URL %s is denied", typeURL)
+	}
+	if c.allowed != nil && !c.allowed[typeURL] {
+		return errorsmod.Wrapf(sdkerrors.ErrTxDecode, "decode policy violation: type URL %s is not allowed", typeURL)
+	}
+	return nil
+}
+
+// checkNested checks the depth and type URLs of the Anys nested in msg, which
+// is at the given depth.
+func (c *decodePolicyChecker) checkNested(msg protoreflect.Message, depth int) error {
+	var err error
+	msg.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		switch {
+		case fd.IsMap():
+			if fd.MapValue().Kind() != protoreflect.MessageKind {
+				return true
+			}
+			v.Map().Range(func(_ protoreflect.MapKey, v protoreflect.Value) bool {
+				err = c.checkValue(v.Message(), depth)
+				return err == nil
+			})
+		case fd.Kind() != protoreflect.MessageKind && fd.Kind() != protoreflect.GroupKind:
+		case fd.IsList():
+			list := v.List()
+			for i := 0; i < list.Len() && err == nil; i++ {
+				err = c.checkValue(list.Get(i).Message(), depth)
+			}
+		default:
+			err = c.checkValue(v.Message(), depth)
+		}
+		return err == nil
+	})
+	return err
+}
+
+// checkValue checks a message value found in a message at the given depth.
+func (c *decodePolicyChecker) checkValue(msg protoreflect.Message, depth int) error {
+	if msg.Descriptor().FullName() != anyFullName {
+		return c.checkNested(msg, depth)
+	}
+
+	depth++
+	if c.MaxAnyDepth > 0 && depth > c.MaxAnyDepth {
+		return errorsmod.Wrapf(sdkerrors.ErrTxDecode, "decode policy violation: anys are nested too deeply: max depth %d", c.MaxAnyDepth)
+	}
+
+	fields := msg.Descriptor().Fields()
+	anyMsg := &anypb.Any{
+		TypeUrl: msg.Get(fields.ByName("type_url")).String(),
+		Value:   msg.Get(fields.ByName("value")).Bytes(),
+	}
+	if err := c.checkTypeURL(anyMsg.TypeUrl); err != nil {
+		return err
+	}
+
+	nested, err := anyutil.Unpack(anyMsg, c.fileResolver, c.typeResolver)
+	if err != nil {
+		return errorsmod.Wrap(sdkerrors.ErrTxDecode, err.Error())
+	}
+
+	return c.checkNested(nested.ProtoReflect(), depth)
+}
@@ -0,0 +1,128 @@
+package tx
+
+import (
+	"testing"
+
+	"github.com/cosmos/cosmos-proto/anyutil"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	authzv1beta1 "cosmossdk.io/api/cosmos/authz/v1beta1"
+	bankv1beta1 "cosmossdk.io/api/cosmos/bank/v1beta1"
+	basev1beta1 "cosmossdk.io/api/cosmos/base/v1beta1"
+	govv1 "cosmossdk.io/api/cosmos/gov/v1"
+	txv1beta1 "cosmossdk.io/api/cosmos/tx/v1beta1"
+
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+)
+
+const (
+	sendTypeURL = "/cosmos.bank.v1beta1.MsgSend"
+	execTypeURL = "/cosmos.authz.v1beta1.MsgExec"
+	voteTypeURL = "/cosmos.gov.v1.MsgVote"
+)
+
+func TestDecodePolicyChecker(t *testing.T) {
+	newAny := func(msg proto.Message) *anypb.Any {
+		anyMsg, err := anyutil.New(msg)
+		require.NoError(t, err)
+		return anyMsg
+	}
+	send := &bankv1beta1.MsgSend{FromAddress: "aaaa", ToAddress: "bbbb", Amount: []*basev1beta1.Coin{{Denom: "stake", Amount: "1"}}}
+	vote := &govv1.MsgVote{ProposalId: 1, Voter: "aaaa", Option: govv1.VoteOption_VOTE_OPTION_YES, Metadata: "ipfs://some-long-proposal-vote-metadata"}
+	exec := &authzv1beta1.MsgExec{Grantee: "cccc", Msgs: []*anypb.Any{newAny(send)}}
+	nestedExec := &authzv1beta1.MsgExec{Grantee: "cccc", Msgs: []*anypb.Any{newAny(exec)}}
+
+	testCases := []struct {
+		name   string
+		policy DecodePolicy
+		msgs   []proto.Message
+		error  string
+	}{
+		{
+			name: "no limits",
+			msgs: []proto.Message{send, vote, nestedExec},
+		},
+		{
+			name:   "within limits",
+			policy: DecodePolicy{MaxMsgs: 2, MaxMsgSize: 100, MaxAnyDepth: 2, AllowedTypeURLs: []string{sendTypeURL, execTypeURL}},
+			msgs:   []proto.Message{send, exec},
+		},
+		{
+			name:   "too many messages",
+			policy: DecodePolicy{MaxMsgs: 2},
+			msgs:   []proto.Message{send, send, send},
+			error:  "too many messages: got 3, max 2",
+		},
+		{
+			name:   "message too large",
+			policy: DecodePolicy{MaxMsgSize: 30},
+			msgs:   []proto.Message{send, vote},
+			error:  "message /cosmos.gov.v1.MsgVote is too large",
+		},
+		{
+			name:   "anys nested too deeply",
+			policy: DecodePolicy{MaxAnyDepth: 2},
+			msgs:   []proto.Message{exec, nestedExec},
+			error:  "anys are nested too deeply: max depth 2",
+		},
+		{
+			name:   "denied message",
+			policy: DecodePolicy{DeniedTypeURLs: []string{voteTypeURL}},
+			msgs:   []proto.Message{send, vote},
+			error:  "type URL /cosmos.gov.v1.MsgVote is denied",
+		},
+		{
+			name:   "denied nested message",
+			policy: DecodePolicy{DeniedTypeURLs: []string{sendTypeURL}},
+			msgs:   []proto.Message{exec},
+			error:  "type URL /cosmos.bank.v1beta1.MsgSend is denied",
+		},
+		{
+			name:   "message not allowed",
+			policy: DecodePolicy{AllowedTypeURLs: []string{sendTypeURL}},
+			msgs:   []proto.Message{vote},
+			error:  "type URL /cosmos.gov.v1.MsgVote is not allowed",
+		},
+		{
+			name:   "nested message not allowed",
+			policy: DecodePolicy{AllowedTypeURLs: []string{execTypeURL}},
+			msgs:   []proto.Message{exec},
+			error:  "type URL /cosmos.bank.v1beta1.MsgSend is not allowed",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			checker, err := newDecodePolicyChecker(tc.policy, protoregistry.GlobalFiles, protoregistry.GlobalTypes)
+			require.NoError(t, err)
+
+			body := &txv1beta1.TxBody{}
+			for _, msg := range tc.msgs {
+				body.Messages = append(body.Messages, newAny(msg))
+			}
+			bodyBytes, err := proto.Marshal(body)
+			require.NoError(t, err)
+			txBytes, err := proto.Marshal(&txv1beta1.TxRaw{BodyBytes: bodyBytes})
+			require.NoError(t, err)
+
+			err = checker.check(txBytes)
+			if tc.error != "" {
+				require.ErrorIs(t, err, sdkerrors.ErrTxDecode)
+				require.ErrorContains(t, err, tc.error)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestInvalidDecodePolicy(t *testing.T) {
+	_, err := newDecodePolicyChecker(DecodePolicy{MaxMsgs: -1}, protoregistry.GlobalFiles, protoregistry.GlobalTypes)
+	require.ErrorContains(t, err, "max messages cannot be negative")
+
+	_, err = newDecodePolicyChecker(DecodePolicy{AllowedTypeURLs: []string{sendTypeURL}, DeniedTypeURLs: []string{sendTypeURL}}, protoregistry.GlobalFiles, protoregistry.GlobalTypes)
+	require.ErrorContains(t, err, "is both allowed and denied")
+}
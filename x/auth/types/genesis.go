@@ -124,21 +124,39 @@ func SanitizeGenesisAccounts(genAccs GenesisAccounts) GenesisAccounts {
 
 // ValidateGenAccounts validates an array of GenesisAccounts and checks for duplicates
 func ValidateGenAccounts(accounts GenesisAccounts) error {
-	addrMap := make(map[string]bool, len(accounts))
-
+	v := NewGenesisAccountsValidator()
 	for _, acc := range accounts {
-		// check for duplicated accounts
-		addrStr := acc.GetAddress().String()
-		if _, ok := addrMap[addrStr]; ok {
-			return fmt.Errorf("duplicate account found in genesis state; address: %s", addrStr)
+		if err := v.Validate(acc); err != nil {
+			return err
 		}
+	}
+	return nil
+}
+
+// GenesisAccountsValidator validates genesis accounts one at a time, e.g. from a streamed
+// genesis, see ValidateGenAccounts. Only the addresses of the accounts are kept.
+type GenesisAccountsValidator struct {
+	addrMap map[string]bool
+}
 
-		addrMap[addrStr] = true
+// NewGenesisAccountsValidator returns a validator of genesis accounts.
+func NewGenesisAccountsValidator() *GenesisAccountsValidator {
+	return &GenesisAccountsValidator{addrMap: make(map[string]bool)}
+}
 
-		// check account specific validation
-		if err := acc.Validate(); err != nil {
-			return fmt.Errorf("invalid account found in genesis state; address: %s, error: %w", addrStr, err)
-		}
+// Validate validates a genesis account, which must not have been validated before.
+func (v *GenesisAccountsValidator) Validate(acc GenesisAccount) error {
+	// check for duplicated accounts
+	addrStr := acc.GetAddress().String()
+	if _, ok := v.addrMap[addrStr]; ok {
+		return fmt.Errorf("duplicate account found in genesis state; address: %s", addrStr)
+	}
+
+	v.addrMap[addrStr] = true
+
+	// check account specific validation
+	if err := acc.Validate(); err != nil {
+		return fmt.Errorf("invalid account found in genesis state; address: %s, error: %w", addrStr, err)
 	}
 	return nil
 }
@@ -13,15 +13,14 @@ import (
 
 // InitGenesis initializes the bank module's state from a given genesis state.
 func (k BaseKeeper) InitGenesis(ctx context.Context, genState *types.GenesisState) error {
-	var err error
-	if err = k.SetParams(ctx, genState.Params); err != nil {
+	importer, err := k.NewGenesisImporter(ctx, genState.Params)
+	if err != nil {
 		return err
 	}
 
 	for _, se := range genState.GetAllSendEnabled() {
-		k.SetSendEnabled(ctx, se.Denom, se.Enabled)
+		importer.ImportSendEnabled(se)
 	}
-	totalSupplyMap := sdk.NewMapCoins(sdk.Coins{})
 
 	genState.Balances, err = types.SanitizeGenesisBalances(genState.Balances, k.ak.AddressCodec())
 	if err != nil {
@@ -29,33 +28,76 @@ func (k BaseKeeper) InitGenesis(ctx context.Context, genState *types.GenesisStat
 	}
 
 	for _, balance := range genState.Balances {
-		addr := balance.GetAddress()
-		bz, err := k.ak.AddressCodec().StringToBytes(addr)
-		if err != nil {
+		if err := importer.ImportBalance(balance); err != nil {
 			return err
 		}
+	}
 
-		for _, coin := range balance.Coins {
-			err := k.Balances.Set(ctx, collections.Join(sdk.AccAddress(bz), coin.Denom), coin.Amount)
-			if err != nil {
-				return err
-			}
-		}
+	if err := importer.Finish(genState.Supply); err != nil {
+		return err
+	}
+
+	for _, meta := range genState.DenomMetadata {
+		importer.ImportDenomMetadata(meta)
+	}
+	return nil
+}
+
+// GenesisImporter imports the entries of a genesis state one at a time, e.g. from a
+// streamed genesis. Only the total supply of the balances is kept.
+type GenesisImporter struct {
+	k           BaseKeeper
+	ctx         context.Context
+	totalSupply sdk.MapCoins
+}
+
+// NewGenesisImporter sets the params of a genesis state and returns an importer of its entries.
+func (k BaseKeeper) NewGenesisImporter(ctx context.Context, params types.Params) (*GenesisImporter, error) {
+	if err := k.SetParams(ctx, params); err != nil {
+		return nil, err
+	}
 
-		totalSupplyMap.Add(balance.Coins...)
+	return &GenesisImporter{k: k, ctx: ctx, totalSupply: sdk.NewMapCoins(sdk.Coins{})}, nil
+}
+
+// ImportSendEnabled sets a send enabled entry.
+func (g *GenesisImporter) ImportSendEnabled(se types.SendEnabled) {
+	g.k.SetSendEnabled(g.ctx, se.Denom, se.Enabled)
+}
+
+// ImportBalance sets the balance of an account.
+func (g *GenesisImporter) ImportBalance(balance types.Balance) error {
+	bz, err := g.k.ak.AddressCodec().StringToBytes(balance.GetAddress())
+	if err != nil {
+		return err
 	}
-	totalSupply := totalSupplyMap.ToCoins()
 
-	if !genState.Supply.Empty() && !genState.Supply.Equal(totalSupply) {
-		return fmt.Errorf("genesis supply is incorrect, expected %v, got %v", genState.Supply, totalSupply)
+	for _, coin := range balance.Coins {
+		err := g.k.Balances.Set(g.ctx, collections.Join(sdk.AccAddress(bz), coin.Denom), coin.Amount)
+		if err != nil {
+			return err
+		}
 	}
 
-	for _, supply := range totalSupply {
-		k.setSupply(ctx, supply)
+	g.totalSupply.Add(balance.Coins...)
+	return nil
+}
+
+// ImportDenomMetadata sets the metadata of a denom.
+func (g *GenesisImporter) ImportDenomMetadata(meta types.Metadata) {
+	g.k.SetDenomMetaData(g.ctx, meta)
+}
+
+// Finish sets the total supply of the imported balances, which must be equal to supply
+// unless supply is empty.
+func (g *GenesisImporter) Finish(supply sdk.Coins) error {
+	totalSupply := g.totalSupply.ToCoins()
+	if !supply.Empty() && !supply.Equal(totalSupply) {
+		return fmt.Errorf("genesis supply is incorrect, expected %v, got %v", supply, totalSupply)
 	}
 
-	for _, meta := range genState.DenomMetadata {
-		k.SetDenomMetaData(ctx, meta)
+	for _, coin := range totalSupply {
+		g.k.setSupply(g.ctx, coin)
 	}
 	return nil
 }
@@ -1,11 +1,14 @@
 package keeper_test
 
 import (
+	"cosmossdk.io/core/genesis"
 	sdkmath "cosmossdk.io/math"
+	"cosmossdk.io/x/bank"
 	"cosmossdk.io/x/bank/types"
 
 	codectestutil "github.com/cosmos/cosmos-sdk/codec/testutil"
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/module"
 	"github.com/cosmos/cosmos-sdk/types/query"
 )
 
@@ -49,6 +52,54 @@ func (suite *KeeperTestSuite) TestExportGenesis() {
 	suite.Require().Equal(expectedMetadata, exportGenesis.DenomMetadata)
 }
 
+func (suite *KeeperTestSuite) TestExportGenesisToTarget() {
+	ctx := suite.ctx
+
+	expectedMetadata := suite.getTestMetadata()
+	expectedBalances, _ := suite.getTestBalancesAndSupply()
+	for i := range expectedBalances {
+		suite.bankKeeper.SetDenomMetaData(ctx, expectedMetadata[i])
+		accAddr, err := suite.authKeeper.AddressCodec().StringToBytes(expectedBalances[i].Address)
+		suite.Require().NoError(err)
+		suite.mockMintCoins(mintAcc)
+		suite.Require().NoError(suite.bankKeeper.MintCoins(ctx, types.MintModuleName, expectedBalances[i].Coins))
+		suite.mockSendCoinsFromModuleToAccount(mintAcc, accAddr)
+		suite.Require().NoError(suite.bankKeeper.SendCoinsFromModuleToAccount(ctx, types.MintModuleName, accAddr, expectedBalances[i].Coins))
+	}
+	suite.bankKeeper.SetSendEnabled(ctx, "foocoin", false)
+
+	expected, err := suite.bankKeeper.ExportGenesis(ctx)
+	suite.Require().NoError(err)
+
+	// a chunk size of 1 writes each item to its own chunk
+	dir := suite.T().TempDir()
+	am := bank.NewAppModule(suite.encCfg.Codec, suite.bankKeeper, suite.authKeeper)
+	suite.Require().NoError(am.ExportGenesisToTarget(ctx, genesis.DirTarget(dir, 1)))
+
+	bz, err := module.ReadGenesisDirJSON(dir)
+	suite.Require().NoError(err)
+	var streamed types.GenesisState
+	suite.Require().NoError(suite.encCfg.Codec.UnmarshalJSON(bz, &streamed))
+	suite.Require().Len(streamed.Balances, len(expectedBalances))
+	// the JSON round trip doesn't preserve nil slices
+	expectedBz, err := suite.encCfg.Codec.MarshalJSON(expected)
+	suite.Require().NoError(err)
+	streamedBz, err := suite.encCfg.Codec.MarshalJSON(&streamed)
+	suite.Require().NoError(err)
+	suite.Require().JSONEq(string(expectedBz), string(streamedBz))
+
+	// and imported one entry at a time in a new state, the test metadata being invalid
+	suite.Require().ErrorContains(am.ValidateGenesisFromSource(genesis.DirSource(dir)), "metadata's first denomination unit must be the one with base denom")
+	suite.SetupTest()
+	am = bank.NewAppModule(suite.encCfg.Codec, suite.bankKeeper, suite.authKeeper)
+	suite.Require().NoError(am.InitGenesisFromSource(suite.ctx, genesis.DirSource(dir)))
+	imported, err := suite.bankKeeper.ExportGenesis(suite.ctx)
+	suite.Require().NoError(err)
+	importedBz, err := suite.encCfg.Codec.MarshalJSON(imported)
+	suite.Require().NoError(err)
+	suite.Require().JSONEq(string(expectedBz), string(importedBz))
+}
+
 func (suite *KeeperTestSuite) getTestBalancesAndSupply() ([]types.Balance, sdk.Coins) {
 	ac := codectestutil.CodecOptions{}.GetAddressCodec()
 	addr2, err := suite.authKeeper.AddressCodec().StringToBytes("cosmos1f9xjhxm0plzrh9cskf4qee4pc2xwp0n0556gh0")
@@ -27,6 +27,7 @@ type Keeper interface {
 	WithMintCoinsRestriction(types.MintingRestrictionFn) BaseKeeper
 
 	InitGenesis(context.Context, *types.GenesisState) error
+	NewGenesisImporter(context.Context, types.Params) (*GenesisImporter, error)
 	ExportGenesis(context.Context) (*types.GenesisState, error)
 
 	GetSupply(ctx context.Context, denom string) sdk.Coin
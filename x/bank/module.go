@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"github.com/cosmos/gogoproto/proto"
 	gwruntime "github.com/grpc-ecosystem/grpc-gateway/runtime"
 	"github.com/spf13/cobra"
 	"google.golang.org/grpc"
@@ -34,6 +35,9 @@ var (
 	_ module.AppModuleSimulation = AppModule{}
 	_ module.HasInvariants       = AppModule{}
 
+	_ module.HasGenesisExportToTarget   = AppModule{}
+	_ module.HasGenesisImportFromSource = AppModule{}
+
 	_ appmodule.AppModule             = AppModule{}
 	_ appmodule.HasServices           = AppModule{}
 	_ appmodule.HasMigrations         = AppModule{}
@@ -152,6 +156,234 @@ func (am AppModule) ExportGenesis(ctx context.Context) (json.RawMessage, error)
 	return am.cdc.MarshalJSON(gs)
 }
 
+// ExportGenesisToTarget streams the exported genesis state of the bank module to
+// target, one balance, supply coin, denom metadata and send enabled entry at a time.
+func (am AppModule) ExportGenesisToTarget(ctx context.Context, target appmodule.GenesisTarget) error {
+	// the send enabled entries of the params are migrated to the send_enabled field,
+	// they are few and kept in memory
+	gs := types.NewGenesisState(am.keeper.GetParams(ctx), nil, nil, nil, am.keeper.GetAllSendEnabledEntries(ctx))
+	bz, err := am.cdc.MarshalJSON(&types.GenesisState{Params: gs.Params})
+	if err != nil {
+		return err
+	}
+	if err := module.WriteGenesisFields(target, bz, "params"); err != nil {
+		return err
+	}
+
+	if err := am.exportBalances(ctx, target); err != nil {
+		return err
+	}
+
+	w, err := module.NewGenesisArrayWriter(target, "supply")
+	if err != nil {
+		return err
+	}
+	am.keeper.IterateTotalSupply(ctx, func(coin sdk.Coin) bool {
+		err = writeGenesisItem(am.cdc, w, &coin)
+		return err != nil
+	})
+	if err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	w, err = module.NewGenesisArrayWriter(target, "denom_metadata")
+	if err != nil {
+		return err
+	}
+	am.keeper.IterateAllDenomMetaData(ctx, func(metadata types.Metadata) bool {
+		err = writeGenesisItem(am.cdc, w, &metadata)
+		return err != nil
+	})
+	if err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	w, err = module.NewGenesisArrayWriter(target, "send_enabled")
+	if err != nil {
+		return err
+	}
+	for i := range gs.SendEnabled {
+		if err := writeGenesisItem(am.cdc, w, &gs.SendEnabled[i]); err != nil {
+			return err
+		}
+	}
+	return w.Close()
+}
+
+// exportBalances streams the balances of the accounts, the balances being stored
+// by address then denom.
+func (am AppModule) exportBalances(ctx context.Context, target appmodule.GenesisTarget) error {
+	w, err := module.NewGenesisArrayWriter(target, "balances")
+	if err != nil {
+		return err
+	}
+
+	var balance *types.Balance
+	am.keeper.IterateAllBalances(ctx, func(addr sdk.AccAddress, coin sdk.Coin) bool {
+		var addrStr string
+		if addrStr, err = am.accountKeeper.AddressCodec().BytesToString(addr); err != nil {
+			return true
+		}
+		if balance != nil && balance.Address != addrStr {
+			if err = writeGenesisItem(am.cdc, w, balance); err != nil {
+				return true
+			}
+			balance = nil
+		}
+		if balance == nil {
+			balance = &types.Balance{Address: addrStr}
+		}
+		balance.Coins = balance.Coins.Add(coin)
+		return false
+	})
+	if err != nil {
+		return err
+	}
+	if balance != nil {
+		if err := writeGenesisItem(am.cdc, w, balance); err != nil {
+			return err
+		}
+	}
+
+	return w.Close()
+}
+
+func writeGenesisItem(cdc codec.JSONCodec, w *module.GenesisArrayWriter, item proto.Message) error {
+	bz, err := cdc.MarshalJSON(item)
+	if err != nil {
+		return err
+	}
+	return w.Write(bz)
+}
+
+// ValidateGenesisFromSource validates the genesis state of the bank module streamed from
+// source, one balance, denom metadata and send enabled entry at a time.
+func (am AppModule) ValidateGenesisFromSource(source appmodule.GenesisSource) error {
+	params, err := am.readGenesisParams(source)
+	if err != nil {
+		return err
+	}
+	v, err := types.NewGenesisValidator(params)
+	if err != nil {
+		return err
+	}
+
+	var se types.SendEnabled
+	if err := readGenesisItems(am.cdc, source, "send_enabled", &se, func() error {
+		return v.ValidateSendEnabled(se)
+	}); err != nil {
+		return err
+	}
+
+	var balance types.Balance
+	if err := readGenesisItems(am.cdc, source, "balances", &balance, func() error {
+		return v.ValidateBalance(balance)
+	}); err != nil {
+		return err
+	}
+
+	var metadata types.Metadata
+	if err := readGenesisItems(am.cdc, source, "denom_metadata", &metadata, func() error {
+		return v.ValidateMetadata(metadata)
+	}); err != nil {
+		return err
+	}
+
+	supply, err := am.readGenesisSupply(source)
+	if err != nil {
+		return err
+	}
+	return v.ValidateSupply(supply)
+}
+
+// InitGenesisFromSource initializes the bank module from the genesis state streamed from
+// source, one balance, denom metadata and send enabled entry at a time.
+func (am AppModule) InitGenesisFromSource(ctx context.Context, source appmodule.GenesisSource) error {
+	params, err := am.readGenesisParams(source)
+	if err != nil {
+		return err
+	}
+	importer, err := am.keeper.NewGenesisImporter(ctx, params)
+	if err != nil {
+		return err
+	}
+
+	var se types.SendEnabled
+	if err := readGenesisItems(am.cdc, source, "send_enabled", &se, func() error {
+		importer.ImportSendEnabled(se)
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	var balance types.Balance
+	if err := readGenesisItems(am.cdc, source, "balances", &balance, func() error {
+		return importer.ImportBalance(balance)
+	}); err != nil {
+		return err
+	}
+
+	supply, err := am.readGenesisSupply(source)
+	if err != nil {
+		return err
+	}
+	if err := importer.Finish(supply); err != nil {
+		return err
+	}
+
+	var metadata types.Metadata
+	return readGenesisItems(am.cdc, source, "denom_metadata", &metadata, func() error {
+		importer.ImportDenomMetadata(metadata)
+		return nil
+	})
+}
+
+// readGenesisParams reads the params of the genesis state streamed from source.
+func (am AppModule) readGenesisParams(source appmodule.GenesisSource) (types.Params, error) {
+	bz, err := module.ReadGenesisFields(source, "params")
+	if err != nil {
+		return types.Params{}, err
+	}
+
+	var gs types.GenesisState
+	if err := am.cdc.UnmarshalJSON(bz, &gs); err != nil {
+		return types.Params{}, fmt.Errorf("failed to unmarshal %s genesis state: %w", types.ModuleName, err)
+	}
+	return gs.Params, nil
+}
+
+// readGenesisSupply reads the supply of the genesis state streamed from source, which
+// holds a coin per denom.
+func (am AppModule) readGenesisSupply(source appmodule.GenesisSource) (sdk.Coins, error) {
+	var (
+		supply sdk.Coins
+		coin   sdk.Coin
+	)
+	err := readGenesisItems(am.cdc, source, "supply", &coin, func() error {
+		supply = append(supply, coin)
+		return nil
+	})
+	return supply, err
+}
+
+// readGenesisItems unmarshals the items of an array field of source into item, one at a
+// time, calling fn after each item.
+func readGenesisItems(cdc codec.JSONCodec, source appmodule.GenesisSource, field string, item proto.Message, fn func() error) error {
+	return module.ReadGenesisArray(source, field, func(bz json.RawMessage) error {
+		item.Reset()
+		if err := cdc.UnmarshalJSON(bz, item); err != nil {
+			return fmt.Errorf("failed to unmarshal %s %s: %w", types.ModuleName, field, err)
+		}
+		return fn()
+	})
+}
+
 // ConsensusVersion implements HasConsensusVersion
 func (AppModule) ConsensusVersion() uint64 { return ConsensusVersion }
 
@@ -16,61 +16,111 @@ func (gs GenesisState) Validate() error {
 		return errors.New("send_enabled defined in both the send_enabled field and in params (deprecated)")
 	}
 
-	if err := gs.Params.Validate(); err != nil {
+	v, err := NewGenesisValidator(gs.Params)
+	if err != nil {
 		return err
 	}
 
-	seenSendEnabled := make(map[string]bool)
-	seenBalances := make(map[string]bool)
-	seenMetadatas := make(map[string]bool)
-
-	totalSupply := sdk.Coins{}
-
 	for _, p := range gs.GetAllSendEnabled() {
-		if _, exists := seenSendEnabled[p.Denom]; exists {
-			return fmt.Errorf("duplicate send enabled found: '%s'", p.Denom)
-		}
-		if err := p.Validate(); err != nil {
+		if err := v.ValidateSendEnabled(p); err != nil {
 			return err
 		}
-		seenSendEnabled[p.Denom] = true
 	}
 
 	for _, balance := range gs.Balances {
-		if seenBalances[balance.Address] {
-			return fmt.Errorf("duplicate balance for address %s", balance.Address)
+		if err := v.ValidateBalance(balance); err != nil {
+			return err
 		}
+	}
 
-		if err := balance.Validate(); err != nil {
+	for _, metadata := range gs.DenomMetadata {
+		if err := v.ValidateMetadata(metadata); err != nil {
 			return err
 		}
+	}
+
+	return v.ValidateSupply(gs.Supply)
+}
 
-		seenBalances[balance.Address] = true
+// GenesisValidator validates the entries of a genesis state one at a time, e.g. from a
+// streamed genesis, see GenesisState.Validate. Only the keys of the entries and the total
+// supply of the balances are kept.
+type GenesisValidator struct {
+	seenSendEnabled map[string]bool
+	seenBalances    map[string]bool
+	seenMetadatas   map[string]bool
+	totalSupply     sdk.Coins
+}
 
-		totalSupply = totalSupply.Add(balance.Coins...)
+// NewGenesisValidator validates the params of a genesis state and returns a validator of
+// its entries.
+func NewGenesisValidator(params Params) (*GenesisValidator, error) {
+	if err := params.Validate(); err != nil {
+		return nil, err
 	}
 
-	for _, metadata := range gs.DenomMetadata {
-		if seenMetadatas[metadata.Base] {
-			return fmt.Errorf("duplicate client metadata for denom %s", metadata.Base)
-		}
+	return &GenesisValidator{
+		seenSendEnabled: make(map[string]bool),
+		seenBalances:    make(map[string]bool),
+		seenMetadatas:   make(map[string]bool),
+		totalSupply:     sdk.Coins{},
+	}, nil
+}
 
-		if err := metadata.Validate(); err != nil {
-			return err
-		}
+// ValidateSendEnabled validates a send enabled entry.
+func (v *GenesisValidator) ValidateSendEnabled(p SendEnabled) error {
+	if _, exists := v.seenSendEnabled[p.Denom]; exists {
+		return fmt.Errorf("duplicate send enabled found: '%s'", p.Denom)
+	}
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	v.seenSendEnabled[p.Denom] = true
+	return nil
+}
 
-		seenMetadatas[metadata.Base] = true
+// ValidateBalance validates the balance of an account.
+func (v *GenesisValidator) ValidateBalance(balance Balance) error {
+	if v.seenBalances[balance.Address] {
+		return fmt.Errorf("duplicate balance for address %s", balance.Address)
+	}
+
+	if err := balance.Validate(); err != nil {
+		return err
 	}
 
-	if !gs.Supply.Empty() {
+	v.seenBalances[balance.Address] = true
+
+	v.totalSupply = v.totalSupply.Add(balance.Coins...)
+	return nil
+}
+
+// ValidateMetadata validates the metadata of a denom.
+func (v *GenesisValidator) ValidateMetadata(metadata Metadata) error {
+	if v.seenMetadatas[metadata.Base] {
+		return fmt.Errorf("duplicate client metadata for denom %s", metadata.Base)
+	}
+
+	if err := metadata.Validate(); err != nil {
+		return err
+	}
+
+	v.seenMetadatas[metadata.Base] = true
+	return nil
+}
+
+// ValidateSupply validates the supply of the genesis state, which must be equal to the total
+// supply of the validated balances unless it is empty.
+func (v *GenesisValidator) ValidateSupply(supply sdk.Coins) error {
+	if !supply.Empty() {
 		// NOTE: this errors if supply for any given coin is zero
-		err := gs.Supply.Validate()
+		err := supply.Validate()
 		if err != nil {
 			return err
 		}
 
-		if !gs.Supply.Equal(totalSupply) {
-			return fmt.Errorf("genesis supply is incorrect, expected %v, got %v", gs.Supply, totalSupply)
+		if !supply.Equal(v.totalSupply) {
+			return fmt.Errorf("genesis supply is incorrect, expected %v, got %v", supply, v.totalSupply)
 		}
 	}
 
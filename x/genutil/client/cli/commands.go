@@ -36,6 +36,8 @@ func CommandsWithCustomMigrationMap(txConfig client.TxConfig, mm *module.Manager
 		ValidateGenesisCmd(mm),
 		AddGenesisAccountCmd(txConfig.SigningContext().AddressCodec()),
 		ExportCmd(appExport),
+		DiffGenesisCmd(),
+		EditGenesisCmd(mm),
 	)
 
 	return cmd
@@ -0,0 +1,198 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cosmos/cosmos-sdk/client/flags"
+	"github.com/cosmos/cosmos-sdk/types/module"
+	"github.com/cosmos/cosmos-sdk/version"
+	"github.com/cosmos/cosmos-sdk/x/genutil"
+	"github.com/cosmos/cosmos-sdk/x/genutil/types"
+)
+
+const (
+	flagModules = "modules"
+
+	// maxDiffValueLength is the length above which the values are truncated in the text output of diffs.
+	maxDiffValueLength = 120
+)
+
+// DiffGenesisCmd returns a command comparing two genesis files module by module.
+func DiffGenesisCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "diff [old-genesis-file] [new-genesis-file]",
+		Short: "Compare two genesis files module by module",
+		Long: `Compare two genesis files module by module.
+Array items are matched by their identifying fields, such as addresses, rather than by position,
+and lists of coins are compared by denomination, with their delta. The validator sets are summarized.`,
+		Example: fmt.Sprintf("%s genesis diff mainnet.json testnet.json --modules bank,staking", version.AppName),
+		Args:    cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			modules, err := cmd.Flags().GetStringSlice(flagModules)
+			if err != nil {
+				return err
+			}
+
+			oldGenesis, oldState, err := appStateFromGenFile(args[0])
+			if err != nil {
+				return err
+			}
+			newGenesis, newState, err := appStateFromGenFile(args[1])
+			if err != nil {
+				return err
+			}
+
+			diff, err := genutil.DiffGenesis(oldGenesis, newGenesis, oldState, newState, modules)
+			if err != nil {
+				return err
+			}
+
+			output, err := cmd.Flags().GetString(flags.FlagOutput)
+			if err != nil {
+				return err
+			}
+			if output == flags.OutputFormatJSON {
+				enc := json.NewEncoder(cmd.OutOrStdout())
+				enc.SetIndent("", "  ")
+				return enc.Encode(diff)
+			}
+
+			printGenesisDiff(cmd.OutOrStdout(), diff)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringSlice(flagModules, nil, "Compare only the given modules (default: all modules and the genesis fields outside the app state)")
+	cmd.Flags().StringP(flags.FlagOutput, "o", flags.OutputFormatText, "Output format (text|json)")
+
+	return cmd
+}
+
+func printGenesisDiff(out io.Writer, diff genutil.GenesisDiff) {
+	fmt.Fprintf(out, "Validator set: %d validators (power %d) -> %d validators (power %d)\n",
+		diff.OldValidatorSet.Validators, diff.OldValidatorSet.Power, diff.NewValidatorSet.Validators, diff.NewValidatorSet.Power)
+
+	if len(diff.Changes) == 0 {
+		fmt.Fprintln(out, "\nNo differences")
+		return
+	}
+
+	module := "-"
+	for _, c := range diff.Changes {
+		if c.Module != module {
+			module = c.Module
+			name := module
+			if name == "" {
+				name = "genesis"
+			}
+			fmt.Fprintf(out, "\n%s\n", name)
+		}
+
+		if c.Path == "" {
+			// the whole module was added or removed
+			fmt.Fprintf(out, "  %s %s\n", changeSymbol(c.Kind), c.Kind)
+			continue
+		}
+
+		switch c.Kind {
+		case genutil.ChangeAdded:
+			fmt.Fprintf(out, "  + %s: %s\n", c.Path, formatDiffValue(c.New))
+		case genutil.ChangeRemoved:
+			fmt.Fprintf(out, "  - %s: %s\n", c.Path, formatDiffValue(c.Old))
+		default:
+			line := fmt.Sprintf("  ~ %s: %s -> %s", c.Path, formatDiffValue(c.Old), formatDiffValue(c.New))
+			if c.Delta != "" {
+				line += fmt.Sprintf(" (%s)", c.Delta)
+			}
+			fmt.Fprintln(out, line)
+		}
+	}
+}
+
+func changeSymbol(kind genutil.ChangeKind) string {
+	switch kind {
+	case genutil.ChangeAdded:
+		return "+"
+	case genutil.ChangeRemoved:
+		return "-"
+	default:
+		return "~"
+	}
+}
+
+func formatDiffValue(v any) string {
+	var s string
+	if str, ok := v.(string); ok {
+		s = str
+	} else {
+		bz, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprintf("%v", v)
+		}
+		s = string(bz)
+	}
+
+	if len(s) > maxDiffValueLength {
+		s = s[:maxDiffValueLength] + "..."
+	}
+
+	return s
+}
+
+// appStateFromGenFile reads a genesis file and its app state. The module states of a
+// streamed genesis are read from their directory.
+func appStateFromGenFile(genFile string) (*types.AppGenesis, map[string]json.RawMessage, error) {
+	appGenesis, err := types.AppGenesisFromFile(genFile)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if streamed, ok := module.ParseStreamedGenesis(appGenesis.AppState); ok {
+		appState, err := readStreamedAppState(streamed.Path(filepath.Dir(genFile)))
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read the module states of %s: %w", genFile, err)
+		}
+		return appGenesis, appState, nil
+	}
+
+	var appState map[string]json.RawMessage
+	if err := json.Unmarshal(appGenesis.AppState, &appState); err != nil {
+		return nil, nil, fmt.Errorf("failed to JSON unmarshal the app state of %s: %w", genFile, err)
+	}
+
+	return appGenesis, appState, nil
+}
+
+// readStreamedAppState reads the module states of a streamed genesis in memory.
+func readStreamedAppState(dir string) (map[string]json.RawMessage, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	appState := make(map[string]json.RawMessage, len(entries))
+	for _, e := range entries {
+		if !e.IsDir() {
+			if name, ok := strings.CutSuffix(e.Name(), ".json"); ok {
+				if appState[name], err = os.ReadFile(filepath.Join(dir, e.Name())); err != nil {
+					return nil, err
+				}
+			}
+			continue
+		}
+
+		// the modules streamed by field have a file, or a directory of chunks, per field
+		if appState[e.Name()], err = module.ReadGenesisDirJSON(filepath.Join(dir, e.Name())); err != nil {
+			return nil, err
+		}
+	}
+
+	return appState, nil
+}
@@ -0,0 +1,40 @@
+package cli_test
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/testutil"
+	clitestutil "github.com/cosmos/cosmos-sdk/testutil/cli"
+	"github.com/cosmos/cosmos-sdk/x/genutil/client/cli"
+)
+
+func TestDiffGenesis(t *testing.T) {
+	bz, err := os.ReadFile("../../types/testdata/app_genesis.json")
+	require.NoError(t, err)
+	oldGenesis := testutil.WriteToNewTempFile(t, string(bz))
+
+	// the chain id and the balance of an account are changed
+	modified := strings.Replace(string(bz), `"chain_id":"demo"`, `"chain_id":"fork"`, 1)
+	modified = strings.Replace(modified, `{"amount":"1000","denom":"stake"}`, `{"amount":"1500","denom":"stake"}`, 1)
+	require.NotEqual(t, string(bz), modified)
+	newGenesis := testutil.WriteToNewTempFile(t, modified)
+
+	out, err := clitestutil.ExecTestCLICmd(client.Context{}, cli.DiffGenesisCmd(), []string{oldGenesis.Name(), newGenesis.Name()})
+	require.NoError(t, err)
+	require.Contains(t, out.String(), "~ chain_id: demo -> fork")
+	require.Contains(t, out.String(), "~ balances[cosmos1pnt5523etwtzv6mj7haryfw6w8h5tkcuhd99m8].coins: 1000stake -> 1500stake (+500stake)")
+
+	out, err = clitestutil.ExecTestCLICmd(client.Context{}, cli.DiffGenesisCmd(), []string{oldGenesis.Name(), oldGenesis.Name(), "--output", "json"})
+	require.NoError(t, err)
+	var diff struct {
+		Changes []any `json:"changes"`
+	}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &diff))
+	require.Empty(t, diff.Changes)
+}
@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"cosmossdk.io/math"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/client/flags"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/module"
+	"github.com/cosmos/cosmos-sdk/version"
+	"github.com/cosmos/cosmos-sdk/x/genutil"
+)
+
+// FlagPowerReduction defines a flag to set the power reduction converting the power of validators into tokens.
+const FlagPowerReduction = "power-reduction"
+
+// EditGenesisCmd returns a command applying scripted edits to a genesis file.
+func EditGenesisCmd(mm *module.Manager) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "edit [genesis-file] [edits-file]",
+		Short: "Apply scripted edits to a genesis file, e.g. to fork a testnet from an exported state",
+		Long: `Apply scripted edits to a genesis file, e.g. to fork a testnet from an exported state, and print
+the edited genesis to STDOUT. The edits file is a JSON list of edits, applied in order:
+
+[
+  {"replace_validators": {"validators": [{"operator": "cosmos1...", "pub_key": {"@type": "/cosmos.crypto.ed25519.PubKey", "key": "..."}, "power": 100, "moniker": "val-0"}]}},
+  {"set_balance": {"address": "cosmos1...", "coins": "1000000stake"}},
+  {"add_balance": {"address": "cosmos1...", "coins": "1000000stake"}},
+  {"sub_balance": {"address": "cosmos1...", "coins": "1000000stake"}},
+  {"reset_gov_proposals": {}}
+]
+
+replace_validators replaces the validator set by validators self-delegating their power, converted into
+tokens with --power-reduction, and refunds the delegations to the current validators. reset_gov_proposals
+removes the governance proposals and refunds their deposits. The bank supply is recomputed from the
+balances, and the edited genesis is validated, including the consistency of the module accounts balances
+with the staking pools, the distribution rewards and the governance deposits.`,
+		Example: fmt.Sprintf("%s genesis edit exported.json edits.json --output-document testnet.json", version.AppName),
+		Args:    cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			clientCtx := client.GetClientContextFromCmd(cmd)
+
+			powerReductionStr, _ := cmd.Flags().GetString(FlagPowerReduction)
+			powerReduction, ok := math.NewIntFromString(powerReductionStr)
+			if !ok {
+				return fmt.Errorf("invalid power reduction %q", powerReductionStr)
+			}
+
+			appGenesis, appState, err := appStateFromGenFile(args[0])
+			if err != nil {
+				return err
+			}
+
+			bz, err := os.ReadFile(args[1])
+			if err != nil {
+				return err
+			}
+			edits, err := genutil.ParseGenesisEdits(bz)
+			if err != nil {
+				return err
+			}
+
+			if err := genutil.EditGenesis(
+				clientCtx.Codec, clientCtx.AddressCodec, clientCtx.ValidatorAddressCodec, clientCtx.ConsensusAddressCodec,
+				powerReduction, appGenesis, appState, edits,
+			); err != nil {
+				return err
+			}
+
+			if mm != nil {
+				if err := mm.ValidateGenesis(appState); err != nil {
+					return fmt.Errorf("the edited genesis is invalid: %w", err)
+				}
+			}
+			if err := genutil.CheckGenesisInvariants(clientCtx.Codec, clientCtx.AddressCodec, appState); err != nil {
+				return fmt.Errorf("the edited genesis is inconsistent: %w", err)
+			}
+
+			if appGenesis.AppState, err = json.Marshal(appState); err != nil {
+				return fmt.Errorf("failed to marshal app state: %w", err)
+			}
+			if err := appGenesis.ValidateAndComplete(); err != nil {
+				return err
+			}
+
+			outputDocument, _ := cmd.Flags().GetString(flags.FlagOutputDocument)
+			if outputDocument != "" {
+				return appGenesis.SaveAs(outputDocument)
+			}
+
+			bz, err = json.Marshal(appGenesis)
+			if err != nil {
+				return fmt.Errorf("failed to marshal app genesis: %w", err)
+			}
+			cmd.Println(string(bz))
+			return nil
+		},
+	}
+
+	cmd.Flags().String(FlagPowerReduction, sdk.DefaultPowerReduction.String(), "Power reduction of the app, converting the power of the new validators into tokens")
+	cmd.Flags().String(flags.FlagOutputDocument, "", "Edited genesis is written to the given file instead of STDOUT")
+
+	return cmd
+}
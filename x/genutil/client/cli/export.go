@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 
 	"github.com/spf13/cobra"
 
@@ -13,6 +14,7 @@ import (
 	"github.com/cosmos/cosmos-sdk/client/flags"
 	"github.com/cosmos/cosmos-sdk/server"
 	servertypes "github.com/cosmos/cosmos-sdk/server/types"
+	"github.com/cosmos/cosmos-sdk/types/module"
 	"github.com/cosmos/cosmos-sdk/version"
 	genutiltypes "github.com/cosmos/cosmos-sdk/x/genutil/types"
 )
@@ -23,6 +25,17 @@ const (
 	flagForZeroHeight    = "for-zero-height"
 	flagJailAllowedAddrs = "jail-allowed-addrs"
 	flagModulesToExport  = "modules-to-export"
+	flagOutputDir        = "output-dir"
+
+	// AppStateDirOption is the app option holding the directory to which the app exporter
+	// should stream the module states, see module.Manager.ExportGenesisToDir. It is set by
+	// the export command when --output-dir is used. The app exporter then returns an app
+	// state referencing the directory, see module.StreamedGenesis.
+	AppStateDirOption = "export-app-state-dir"
+
+	// streamedAppStateDir is the directory of the module states of a streamed export,
+	// relative to the exported genesis file.
+	streamedAppStateDir = "app_state"
 )
 
 // ExportCmd dumps app state to JSON.
@@ -79,6 +92,14 @@ func ExportCmd(appExporter servertypes.AppExporter) *cobra.Command {
 			jailAllowedAddrs, _ := cmd.Flags().GetStringSlice(flagJailAllowedAddrs)
 			modulesToExport, _ := cmd.Flags().GetStringSlice(flagModulesToExport)
 			outputDocument, _ := cmd.Flags().GetString(flags.FlagOutputDocument)
+			outputDir, _ := cmd.Flags().GetString(flagOutputDir)
+			if outputDir != "" {
+				if outputDocument != "" {
+					return fmt.Errorf("--%s and --%s cannot be used together", flagOutputDir, flags.FlagOutputDocument)
+				}
+
+				viper.Set(AppStateDirOption, filepath.Join(outputDir, streamedAppStateDir))
+			}
 
 			exported, err := appExporter(logger, db, traceWriter, height, forZeroHeight, jailAllowedAddrs, viper, modulesToExport)
 			if err != nil {
@@ -98,6 +119,22 @@ func ExportCmd(appExporter servertypes.AppExporter) *cobra.Command {
 			appGenesis.InitialHeight = exported.Height
 			appGenesis.Consensus = genutiltypes.NewConsensusGenesis(exported.ConsensusParams, exported.Validators)
 
+			if outputDir != "" {
+				streamed, ok := module.ParseStreamedGenesis(exported.AppState)
+				if !ok {
+					return fmt.Errorf("the app does not support exporting to a directory")
+				}
+
+				// the module states are referenced relatively to the genesis file
+				streamed.Dir = streamedAppStateDir
+				appGenesis.AppState, err = streamed.AppState()
+				if err != nil {
+					return err
+				}
+
+				return appGenesis.SaveAs(filepath.Join(outputDir, "genesis.json"))
+			}
+
 			out, err := json.Marshal(appGenesis)
 			if err != nil {
 				return err
@@ -122,6 +159,7 @@ func ExportCmd(appExporter servertypes.AppExporter) *cobra.Command {
 	cmd.Flags().StringSlice(flagJailAllowedAddrs, []string{}, "Comma-separated list of operator addresses of jailed validators to unjail")
 	cmd.Flags().StringSlice(flagModulesToExport, []string{}, "Comma-separated list of modules to export. If empty, will export all modules")
 	cmd.Flags().String(flags.FlagOutputDocument, "", "Exported state is written to the given file instead of STDOUT")
+	cmd.Flags().String(flagOutputDir, "", "Exported state is written to genesis.json in the given directory, with the state of each module streamed to a file of its app_state directory, bounding the memory used by the export")
 
 	return cmd
 }
@@ -124,6 +124,7 @@ type mockExporter struct {
 		ForZeroHeight    bool
 		JailAllowedAddrs []string
 		ModulesToExport  []string
+		AppStateDir      string
 	}
 }
 
@@ -172,6 +173,7 @@ func (e *mockExporter) Export(
 	e.Called.ForZeroHeight = forZeroHeight
 	e.Called.JailAllowedAddrs = jailAllowedAddrs
 	e.Called.ModulesToExport = modulesToExport
+	e.Called.AppStateDir, _ = opts.Get(cli.AppStateDirOption).(string)
 
 	return e.ExportApp, e.Err
 }
@@ -289,6 +291,49 @@ func TestExportCLI(t *testing.T) {
 		CheckExportedGenesis(t, j)
 	})
 
+	t.Run("writes a streamed genesis with --output-dir", func(t *testing.T) {
+		t.Parallel()
+
+		e := new(mockExporter)
+		e.SetDefaultExportApp()
+
+		sys := NewExportSystem(t, e.Export)
+		_ = sys.MustRun(t, "init", "some_moniker")
+
+		outDir := t.TempDir()
+		var err error
+		e.ExportApp.AppState, err = module.StreamedGenesis{Dir: filepath.Join(outDir, "app_state"), Hash: "0a1b"}.AppState()
+		require.NoError(t, err)
+
+		res := sys.MustRun(t, "export", "--output-dir", outDir)
+		require.Empty(t, res.Stdout.String())
+		require.Equal(t, filepath.Join(outDir, "app_state"), e.Called.AppStateDir)
+
+		j, err := os.ReadFile(filepath.Join(outDir, "genesis.json"))
+		require.NoError(t, err)
+		CheckExportedGenesis(t, j)
+
+		var ag genutiltypes.AppGenesis
+		require.NoError(t, json.Unmarshal(j, &ag))
+		streamed, ok := module.ParseStreamedGenesis(ag.AppState)
+		require.True(t, ok)
+		require.Equal(t, "app_state", streamed.Dir)
+		require.Equal(t, "0a1b", streamed.Hash)
+	})
+
+	t.Run("fails with --output-dir when the app does not stream its state", func(t *testing.T) {
+		t.Parallel()
+
+		e := new(mockExporter)
+		e.SetDefaultExportApp()
+
+		sys := NewExportSystem(t, e.Export)
+		_ = sys.MustRun(t, "init", "some_moniker")
+
+		res := sys.Run("export", "--output-dir", t.TempDir())
+		require.ErrorContains(t, res.Err, "the app does not support exporting to a directory")
+	})
+
 	t.Run("prints genesis to stdout when no app exporter defined", func(t *testing.T) {
 		t.Parallel()
 
@@ -3,6 +3,7 @@ package cli
 import (
 	"encoding/json"
 	"fmt"
+	"path/filepath"
 
 	"github.com/spf13/cobra"
 
@@ -40,6 +41,17 @@ func ValidateGenesisCmd(mm *module.Manager) *cobra.Command {
 				return fmt.Errorf("make sure that you have correctly migrated all CometBFT consensus params. Refer the UPGRADING.md (%s): %w", chainUpgradeGuide, err)
 			}
 
+			if streamed, ok := module.ParseStreamedGenesis(appGenesis.AppState); ok {
+				if mm != nil {
+					if err = mm.ValidateGenesisDir(streamed.Path(filepath.Dir(genesis)), streamed.Hash); err != nil {
+						return fmt.Errorf("error validating genesis file %s: %w", genesis, err)
+					}
+				}
+
+				fmt.Fprintf(cmd.OutOrStdout(), "File at %s is a valid genesis file\n", genesis)
+				return nil
+			}
+
 			var genState map[string]json.RawMessage
 			if err = json.Unmarshal(appGenesis.AppState, &genState); err != nil {
 				return fmt.Errorf("error unmarshalling genesis doc %s: %w", genesis, err)
@@ -1,15 +1,22 @@
 package cli_test
 
 import (
+	"encoding/json"
+	"errors"
 	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
 
 	"github.com/cosmos/cosmos-sdk/client"
 	"github.com/cosmos/cosmos-sdk/testutil"
 	clitestutil "github.com/cosmos/cosmos-sdk/testutil/cli"
+	"github.com/cosmos/cosmos-sdk/testutil/mock"
+	"github.com/cosmos/cosmos-sdk/types/module"
 	"github.com/cosmos/cosmos-sdk/x/genutil/client/cli"
+	"github.com/cosmos/cosmos-sdk/x/genutil/types"
 )
 
 // An example exported genesis file from a 0.37 chain. Note that evidence
@@ -68,3 +75,40 @@ func TestValidateGenesis(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateStreamedGenesis(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+
+	mockModule := mock.NewMockAppModuleWithAllExtensions(mockCtrl)
+	mockModule.EXPECT().Name().AnyTimes().Return("module1")
+	mm := module.NewManager(mockModule)
+
+	dir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "app_state"), 0o755))
+	moduleGenesis := json.RawMessage(`{"key":"value"}`)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "app_state", "module1.json"), moduleGenesis, 0o600))
+	hash, err := module.HashGenesisDir(filepath.Join(dir, "app_state"))
+	require.NoError(t, err)
+
+	appGenesis, err := types.AppGenesisFromFile("../../types/testdata/app_genesis.json")
+	require.NoError(t, err)
+	appGenesis.AppState, err = module.StreamedGenesis{Dir: "app_state", Hash: hash}.AppState()
+	require.NoError(t, err)
+	genesisFile := filepath.Join(dir, "genesis.json")
+	require.NoError(t, appGenesis.SaveAs(genesisFile))
+
+	// the module state is read from the app_state directory
+	mockModule.EXPECT().ValidateGenesis(gomock.Eq(moduleGenesis)).Times(1).Return(nil)
+	_, err = clitestutil.ExecTestCLICmd(client.Context{}, cli.ValidateGenesisCmd(mm), []string{genesisFile})
+	require.NoError(t, err)
+
+	mockModule.EXPECT().ValidateGenesis(gomock.Eq(moduleGenesis)).Times(1).Return(errors.New("invalid module1 state"))
+	_, err = clitestutil.ExecTestCLICmd(client.Context{}, cli.ValidateGenesisCmd(mm), []string{genesisFile})
+	require.ErrorContains(t, err, "invalid module1 state")
+
+	// the module states must match the hash the genesis file commits to
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "app_state", "module1.json"), []byte(`{"key":"other"}`), 0o600))
+	_, err = clitestutil.ExecTestCLICmd(client.Context{}, cli.ValidateGenesisCmd(mm), []string{genesisFile})
+	require.ErrorContains(t, err, "the genesis file commits to "+hash)
+}
@@ -0,0 +1,512 @@
+package genutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"cosmossdk.io/math"
+	stakingtypes "cosmossdk.io/x/staking/types"
+
+	genutiltypes "github.com/cosmos/cosmos-sdk/x/genutil/types"
+)
+
+// ChangeKind is the kind of a change between two genesis files.
+type ChangeKind string
+
+const (
+	ChangeAdded   ChangeKind = "added"
+	ChangeRemoved ChangeKind = "removed"
+	ChangeChanged ChangeKind = "changed"
+)
+
+// GenesisChange is a change between two genesis files.
+type GenesisChange struct {
+	// Module is the module of the change, or empty for the genesis fields outside the app state.
+	Module string     `json:"module,omitempty"`
+	Path   string     `json:"path"`
+	Kind   ChangeKind `json:"kind"`
+	Old    any        `json:"old,omitempty"`
+	New    any        `json:"new,omitempty"`
+	// Delta is the difference of two lists of coins.
+	Delta string `json:"delta,omitempty"`
+}
+
+// ValidatorSetSummary summarizes the validator set of a genesis file.
+type ValidatorSetSummary struct {
+	Validators int   `json:"validators"`
+	Power      int64 `json:"power"`
+}
+
+// GenesisDiff is the difference between two genesis files.
+type GenesisDiff struct {
+	OldValidatorSet ValidatorSetSummary `json:"old_validator_set"`
+	NewValidatorSet ValidatorSetSummary `json:"new_validator_set"`
+	Changes         []GenesisChange     `json:"changes"`
+}
+
+// diffKeyFields are the fields identifying the items of arrays, so that items are matched
+// by identity rather than by position (e.g. balances by address, delegations by delegator
+// and validator addresses). The fields present in all the items of an array are used.
+var diffKeyFields = []string{
+	"proposal_id", "delegator_address", "validator_address", "validator_src_address", "validator_dst_address",
+	"operator_address", "address", "voter", "depositor", "granter", "grantee", "base", "id", "period", "height",
+}
+
+// DiffGenesis returns the difference between two genesis files, module by module. The app
+// states are given separately, as maps of module names to module states. Only the given
+// modules are compared, or all modules and the genesis fields outside the app state if
+// none is given.
+//
+// Arrays items are matched by their identifying fields, such as addresses, and lists of
+// coins are compared denomination by denomination. Proto3 default values are equal to
+// missing fields.
+func DiffGenesis(oldGenesis, newGenesis *genutiltypes.AppGenesis, oldState, newState map[string]json.RawMessage, modules []string) (GenesisDiff, error) {
+	var diff GenesisDiff
+
+	emit := func(module string) func(GenesisChange) {
+		return func(c GenesisChange) {
+			c.Module = module
+			diff.Changes = append(diff.Changes, c)
+		}
+	}
+
+	if len(modules) == 0 {
+		oldHeader, err := genesisHeader(oldGenesis)
+		if err != nil {
+			return diff, err
+		}
+		newHeader, err := genesisHeader(newGenesis)
+		if err != nil {
+			return diff, err
+		}
+		diffValues("", oldHeader, newHeader, emit(""))
+
+		for name := range oldState {
+			modules = append(modules, name)
+		}
+		for name := range newState {
+			if _, ok := oldState[name]; !ok {
+				modules = append(modules, name)
+			}
+		}
+	}
+	sort.Strings(modules)
+
+	for _, name := range modules {
+		oldBz, oldOk := oldState[name]
+		newBz, newOk := newState[name]
+		switch {
+		case !oldOk && !newOk:
+			continue
+		case !oldOk:
+			diff.Changes = append(diff.Changes, GenesisChange{Module: name, Kind: ChangeAdded})
+			continue
+		case !newOk:
+			diff.Changes = append(diff.Changes, GenesisChange{Module: name, Kind: ChangeRemoved})
+			continue
+		}
+
+		oldValue, err := decodeJSON(oldBz)
+		if err != nil {
+			return diff, fmt.Errorf("failed to decode %s genesis: %w", name, err)
+		}
+		newValue, err := decodeJSON(newBz)
+		if err != nil {
+			return diff, fmt.Errorf("failed to decode %s genesis: %w", name, err)
+		}
+		diffValues("", oldValue, newValue, emit(name))
+	}
+
+	var err error
+	if diff.OldValidatorSet, err = validatorSetSummary(oldState); err != nil {
+		return diff, err
+	}
+	if diff.NewValidatorSet, err = validatorSetSummary(newState); err != nil {
+		return diff, err
+	}
+
+	return diff, nil
+}
+
+// genesisHeader returns the genesis fields outside the app state.
+func genesisHeader(appGenesis *genutiltypes.AppGenesis) (any, error) {
+	header := *appGenesis
+	header.AppState = nil
+	bz, err := json.Marshal(header)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeJSON(bz)
+}
+
+// validatorSetSummary summarizes the last validator powers of the staking genesis.
+func validatorSetSummary(appState map[string]json.RawMessage) (ValidatorSetSummary, error) {
+	var summary ValidatorSetSummary
+	bz, ok := appState[stakingtypes.ModuleName]
+	if !ok {
+		return summary, nil
+	}
+
+	var staking struct {
+		LastValidatorPowers []struct {
+			Power json.Number `json:"power"`
+		} `json:"last_validator_powers"`
+	}
+	if err := json.Unmarshal(bz, &staking); err != nil {
+		return summary, fmt.Errorf("failed to decode staking genesis: %w", err)
+	}
+
+	for _, v := range staking.LastValidatorPowers {
+		power, err := v.Power.Int64()
+		if err != nil && v.Power != "" {
+			return summary, fmt.Errorf("invalid validator power %q", v.Power)
+		}
+		summary.Validators++
+		summary.Power += power
+	}
+
+	return summary, nil
+}
+
+func decodeJSON(bz []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(bz))
+	dec.UseNumber()
+
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// diffValues emits the changes between two JSON values.
+func diffValues(path string, a, b any, emit func(GenesisChange)) {
+	switch a := a.(type) {
+	case map[string]any:
+		if b, ok := b.(map[string]any); ok {
+			diffObjects(path, a, b, emit)
+			return
+		}
+	case []any:
+		if b, ok := b.([]any); ok {
+			diffArrays(path, a, b, emit)
+			return
+		}
+	}
+
+	if !equalValues(a, b) {
+		emit(GenesisChange{Path: path, Kind: ChangeChanged, Old: a, New: b})
+	}
+}
+
+func diffObjects(path string, a, b map[string]any, emit func(GenesisChange)) {
+	keys := make([]string, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		av, aok := a[k]
+		bv, bok := b[k]
+		switch {
+		case aok && bok:
+			diffValues(joinPath(path, k), av, bv, emit)
+		case !aok && !isDefaultValue(bv):
+			emit(GenesisChange{Path: joinPath(path, k), Kind: ChangeAdded, New: bv})
+		case !bok && !isDefaultValue(av):
+			emit(GenesisChange{Path: joinPath(path, k), Kind: ChangeRemoved, Old: av})
+		}
+	}
+}
+
+func diffArrays(path string, a, b []any, emit func(GenesisChange)) {
+	if oldCoins, ok := parseCoins(a); ok {
+		if newCoins, ok := parseCoins(b); ok {
+			if delta := coinsDelta(oldCoins, newCoins); delta != "" {
+				emit(GenesisChange{Path: path, Kind: ChangeChanged, Old: formatCoins(oldCoins), New: formatCoins(newCoins), Delta: delta})
+			}
+			return
+		}
+	}
+
+	if fields := arrayKeyFields(a, b); len(fields) > 0 {
+		oldItems, oldKeys := indexItems(a, fields)
+		newItems, newKeys := indexItems(b, fields)
+		for _, key := range oldKeys {
+			itemPath := fmt.Sprintf("%s[%s]", path, key)
+			if newItem, ok := newItems[key]; ok {
+				diffValues(itemPath, oldItems[key], newItem, emit)
+			} else {
+				emit(GenesisChange{Path: itemPath, Kind: ChangeRemoved, Old: oldItems[key]})
+			}
+		}
+		for _, key := range newKeys {
+			if _, ok := oldItems[key]; !ok {
+				emit(GenesisChange{Path: fmt.Sprintf("%s[%s]", path, key), Kind: ChangeAdded, New: newItems[key]})
+			}
+		}
+		return
+	}
+
+	for i := 0; i < len(a) || i < len(b); i++ {
+		itemPath := fmt.Sprintf("%s[%d]", path, i)
+		switch {
+		case i >= len(a):
+			emit(GenesisChange{Path: itemPath, Kind: ChangeAdded, New: b[i]})
+		case i >= len(b):
+			emit(GenesisChange{Path: itemPath, Kind: ChangeRemoved, Old: a[i]})
+		default:
+			diffValues(itemPath, a[i], b[i], emit)
+		}
+	}
+}
+
+// arrayKeyFields returns the key fields present in all the items of both arrays, if they
+// identify the items.
+func arrayKeyFields(a, b []any) []string {
+	if len(a) == 0 && len(b) == 0 {
+		return nil
+	}
+
+	var fields []string
+	for _, field := range diffKeyFields {
+		present := true
+		for _, items := range [][]any{a, b} {
+			for _, item := range items {
+				if _, ok := keyField(item, field); !ok {
+					present = false
+					break
+				}
+			}
+		}
+		if present {
+			fields = append(fields, field)
+		}
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+
+	// the key fields must be unique
+	for _, items := range [][]any{a, b} {
+		seen := make(map[string]bool, len(items))
+		for _, item := range items {
+			key := itemKey(item, fields)
+			if seen[key] {
+				return nil
+			}
+			seen[key] = true
+		}
+	}
+
+	return fields
+}
+
+// keyField returns a scalar field of an object, looking into the embedded base accounts
+// of accounts.
+func keyField(item any, field string) (string, bool) {
+	obj, ok := item.(map[string]any)
+	if !ok {
+		return "", false
+	}
+
+	if v, ok := obj[field]; ok {
+		switch v := v.(type) {
+		case string:
+			return v, true
+		case json.Number:
+			return v.String(), true
+		}
+		return "", false
+	}
+
+	for _, embedded := range []string{"base_account", "base_vesting_account"} {
+		if v, ok := obj[embedded]; ok {
+			return keyField(v, field)
+		}
+	}
+
+	return "", false
+}
+
+func itemKey(item any, fields []string) string {
+	values := make([]string, len(fields))
+	for i, field := range fields {
+		values[i], _ = keyField(item, field)
+	}
+
+	return strings.Join(values, "/")
+}
+
+// indexItems returns the items of an array by key, and the keys in order.
+func indexItems(items []any, fields []string) (map[string]any, []string) {
+	index := make(map[string]any, len(items))
+	keys := make([]string, 0, len(items))
+	for _, item := range items {
+		key := itemKey(item, fields)
+		index[key] = item
+		keys = append(keys, key)
+	}
+
+	return index, keys
+}
+
+// parseCoins parses a JSON array of coins, or decimal coins, as amounts by denomination.
+func parseCoins(items []any) (map[string]math.LegacyDec, bool) {
+	coins := make(map[string]math.LegacyDec, len(items))
+	for _, item := range items {
+		obj, ok := item.(map[string]any)
+		if !ok || len(obj) != 2 {
+			return nil, false
+		}
+		denom, ok := obj["denom"].(string)
+		if !ok {
+			return nil, false
+		}
+		amount, ok := obj["amount"].(string)
+		if !ok {
+			return nil, false
+		}
+		dec, err := math.LegacyNewDecFromStr(amount)
+		if err != nil {
+			return nil, false
+		}
+		if prev, ok := coins[denom]; ok {
+			dec = dec.Add(prev)
+		}
+		coins[denom] = dec
+	}
+
+	return coins, true
+}
+
+// coinsDelta returns the difference between two lists of coins, e.g. "+10stake,-2atom".
+func coinsDelta(a, b map[string]math.LegacyDec) string {
+	var deltas []string
+	for _, denom := range coinDenoms(a, b) {
+		old, ok := a[denom]
+		if !ok {
+			old = math.LegacyZeroDec()
+		}
+		amount, ok := b[denom]
+		if !ok {
+			amount = math.LegacyZeroDec()
+		}
+
+		delta := amount.Sub(old)
+		switch {
+		case delta.IsPositive():
+			deltas = append(deltas, "+"+formatDec(delta)+denom)
+		case delta.IsNegative():
+			deltas = append(deltas, formatDec(delta)+denom)
+		}
+	}
+
+	return strings.Join(deltas, ",")
+}
+
+func formatCoins(coins map[string]math.LegacyDec) string {
+	var formatted []string
+	for _, denom := range coinDenoms(coins) {
+		if !coins[denom].IsZero() {
+			formatted = append(formatted, formatDec(coins[denom])+denom)
+		}
+	}
+	if len(formatted) == 0 {
+		return "0"
+	}
+
+	return strings.Join(formatted, ",")
+}
+
+func coinDenoms(coins ...map[string]math.LegacyDec) []string {
+	seen := make(map[string]bool)
+	var denoms []string
+	for _, c := range coins {
+		for denom := range c {
+			if !seen[denom] {
+				seen[denom] = true
+				denoms = append(denoms, denom)
+			}
+		}
+	}
+	sort.Strings(denoms)
+
+	return denoms
+}
+
+func formatDec(d math.LegacyDec) string {
+	if d.IsInteger() {
+		return d.TruncateInt().String()
+	}
+
+	return strings.TrimRight(d.String(), "0")
+}
+
+// equalValues compares JSON scalars, proto3 64 bits integers being encoded as strings.
+func equalValues(a, b any) bool {
+	if isDefaultValue(a) && isDefaultValue(b) {
+		return true
+	}
+
+	as, aok := scalarString(a)
+	bs, bok := scalarString(b)
+	if aok && bok {
+		return as == bs
+	}
+
+	aj, _ := json.Marshal(a)
+	bj, _ := json.Marshal(b)
+	return bytes.Equal(aj, bj)
+}
+
+func scalarString(v any) (string, bool) {
+	switch v := v.(type) {
+	case string:
+		return v, true
+	case json.Number:
+		return v.String(), true
+	}
+
+	return "", false
+}
+
+// isDefaultValue reports whether a JSON value is a proto3 default value, which may be
+// omitted from the JSON encoding.
+func isDefaultValue(v any) bool {
+	switch v := v.(type) {
+	case nil:
+		return true
+	case bool:
+		return !v
+	case string:
+		return v == "" || v == "0"
+	case json.Number:
+		return v == "0"
+	case []any:
+		return len(v) == 0
+	case map[string]any:
+		return len(v) == 0
+	}
+
+	return false
+}
+
+func joinPath(path, field string) string {
+	if path == "" {
+		return field
+	}
+
+	return path + "." + field
+}
@@ -0,0 +1,70 @@
+package genutil
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	genutiltypes "github.com/cosmos/cosmos-sdk/x/genutil/types"
+)
+
+func TestDiffGenesis(t *testing.T) {
+	oldState := map[string]json.RawMessage{
+		"bank": json.RawMessage(`{
+			"balances": [
+				{"address": "addr1", "coins": [{"denom": "stake", "amount": "100"}]},
+				{"address": "addr2", "coins": [{"denom": "stake", "amount": "5"}]}
+			],
+			"supply": [{"denom": "stake", "amount": "105"}]
+		}`),
+		"staking": json.RawMessage(`{
+			"last_validator_powers": [{"address": "val1", "power": "10"}, {"address": "val2", "power": "20"}],
+			"exported": false
+		}`),
+		"removed": json.RawMessage(`{}`),
+	}
+	newState := map[string]json.RawMessage{
+		"bank": json.RawMessage(`{
+			"balances": [
+				{"address": "addr3", "coins": [{"denom": "atom", "amount": "1"}]},
+				{"address": "addr1", "coins": [{"denom": "stake", "amount": "150"}, {"denom": "atom", "amount": "2"}]}
+			],
+			"supply": [{"denom": "stake", "amount": "150"}, {"denom": "atom", "amount": "3"}]
+		}`),
+		"staking": json.RawMessage(`{
+			"last_validator_powers": [{"address": "val1", "power": 10}]
+		}`),
+	}
+
+	oldGenesis := genutiltypes.NewAppGenesisWithVersion("old", nil)
+	newGenesis := genutiltypes.NewAppGenesisWithVersion("new", nil)
+	newGenesis.GenesisTime = oldGenesis.GenesisTime
+
+	diff, err := DiffGenesis(oldGenesis, newGenesis, oldState, newState, nil)
+	require.NoError(t, err)
+	require.Equal(t, ValidatorSetSummary{Validators: 2, Power: 30}, diff.OldValidatorSet)
+	require.Equal(t, ValidatorSetSummary{Validators: 1, Power: 10}, diff.NewValidatorSet)
+
+	require.Equal(t, []GenesisChange{
+		{Path: "chain_id", Kind: ChangeChanged, Old: "old", New: "new"},
+		// balances are matched by address, whatever their position
+		{Module: "bank", Path: "balances[addr1].coins", Kind: ChangeChanged, Old: "100stake", New: "2atom,150stake", Delta: "+2atom,+50stake"},
+		{Module: "bank", Path: "balances[addr2]", Kind: ChangeRemoved, Old: map[string]any{
+			"address": "addr2",
+			"coins":   []any{map[string]any{"denom": "stake", "amount": "5"}},
+		}},
+		{Module: "bank", Path: "balances[addr3]", Kind: ChangeAdded, New: map[string]any{
+			"address": "addr3",
+			"coins":   []any{map[string]any{"denom": "atom", "amount": "1"}},
+		}},
+		{Module: "bank", Path: "supply", Kind: ChangeChanged, Old: "105stake", New: "3atom,150stake", Delta: "+3atom,+45stake"},
+		{Module: "removed", Kind: ChangeRemoved},
+		// 64 bits integers encoded as strings and default values are equal
+		{Module: "staking", Path: "last_validator_powers[val2]", Kind: ChangeRemoved, Old: map[string]any{"address": "val2", "power": "20"}},
+	}, diff.Changes)
+
+	diff, err = DiffGenesis(oldGenesis, newGenesis, oldState, newState, []string{"staking"})
+	require.NoError(t, err)
+	require.Len(t, diff.Changes, 1)
+}
@@ -0,0 +1,786 @@
+package genutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	cmttypes "github.com/cometbft/cometbft/types"
+
+	"cosmossdk.io/core/address"
+	"cosmossdk.io/math"
+	authtypes "cosmossdk.io/x/auth/types"
+	banktypes "cosmossdk.io/x/bank/types"
+	stakingtypes "cosmossdk.io/x/staking/types"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	cryptocodec "github.com/cosmos/cosmos-sdk/crypto/codec"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	genutiltypes "github.com/cosmos/cosmos-sdk/x/genutil/types"
+)
+
+// The genesis of these modules is edited as JSON, as their types are not dependencies of genutil.
+const (
+	distributionModuleName = "distribution"
+	slashingModuleName     = "slashing"
+	govModuleName          = "gov"
+)
+
+// GenesisEdit is an edit of a genesis file, applied by EditGenesis. Exactly one of its
+// fields must be set.
+type GenesisEdit struct {
+	// ReplaceValidators replaces the validator set.
+	ReplaceValidators *ReplaceValidatorsEdit `json:"replace_validators,omitempty"`
+	// SetBalance sets the balance of an account.
+	SetBalance *BalanceEdit `json:"set_balance,omitempty"`
+	// AddBalance adds coins to the balance of an account.
+	AddBalance *BalanceEdit `json:"add_balance,omitempty"`
+	// SubBalance removes coins from the balance of an account.
+	SubBalance *BalanceEdit `json:"sub_balance,omitempty"`
+	// ResetGovProposals removes the governance proposals, votes and deposits, and refunds
+	// the deposits.
+	ResetGovProposals *ResetGovProposalsEdit `json:"reset_gov_proposals,omitempty"`
+}
+
+// ReplaceValidatorsEdit replaces the validator set by new validators, each self-delegating
+// its power. The delegations to the current validators are refunded to the delegators and
+// their redelegations are removed, the unbonding delegations are kept. The outstanding rewards
+// of the current validators are moved to the decimal pool of the distribution module.
+type ReplaceValidatorsEdit struct {
+	Validators []EditValidator `json:"validators"`
+}
+
+// EditValidator is a validator of ReplaceValidatorsEdit.
+type EditValidator struct {
+	// Operator is the account address of the operator, which self-delegates the tokens of the validator.
+	Operator string `json:"operator"`
+	// PubKey is the consensus public key, as output by `comet show-validator`.
+	PubKey json.RawMessage `json:"pub_key"`
+	// Power is the consensus power of the validator.
+	Power   int64  `json:"power"`
+	Moniker string `json:"moniker,omitempty"`
+}
+
+// BalanceEdit edits the balance of an account.
+type BalanceEdit struct {
+	Address string `json:"address"`
+	Coins   string `json:"coins"`
+}
+
+// ResetGovProposalsEdit removes the governance proposals.
+type ResetGovProposalsEdit struct{}
+
+// ParseGenesisEdits parses a JSON list of genesis edits.
+func ParseGenesisEdits(bz []byte) ([]GenesisEdit, error) {
+	dec := json.NewDecoder(bytes.NewReader(bz))
+	dec.DisallowUnknownFields()
+
+	var edits []GenesisEdit
+	if err := dec.Decode(&edits); err != nil {
+		return nil, fmt.Errorf("failed to parse genesis edits: %w", err)
+	}
+
+	for i, edit := range edits {
+		set := 0
+		for _, field := range []bool{
+			edit.ReplaceValidators != nil, edit.SetBalance != nil, edit.AddBalance != nil,
+			edit.SubBalance != nil, edit.ResetGovProposals != nil,
+		} {
+			if field {
+				set++
+			}
+		}
+		if set != 1 {
+			return nil, fmt.Errorf("genesis edit %d must have exactly one operation, got %d", i, set)
+		}
+	}
+
+	return edits, nil
+}
+
+// EditGenesis applies edits to the app state of a genesis file, in order. The app state is
+// edited in place, as well as the consensus validators of appGenesis. The bank supply is
+// recomputed from the balances, coins being minted or burned by the edits. powerReduction
+// converts the power of the new validators into tokens, it must be the power reduction of
+// the app, sdk.DefaultPowerReduction unless the app overrides it.
+func EditGenesis(
+	cdc codec.Codec,
+	addressCodec, validatorAddressCodec, consensusAddressCodec address.Codec,
+	powerReduction math.Int,
+	appGenesis *genutiltypes.AppGenesis,
+	appState map[string]json.RawMessage,
+	edits []GenesisEdit,
+) error {
+	if powerReduction.IsNil() || !powerReduction.IsPositive() {
+		return errors.New("the power reduction must be positive")
+	}
+
+	e := &genesisEditor{
+		cdc:                   cdc,
+		addressCodec:          addressCodec,
+		validatorAddressCodec: validatorAddressCodec,
+		consensusAddressCodec: consensusAddressCodec,
+		powerReduction:        powerReduction,
+		appGenesis:            appGenesis,
+		appState:              appState,
+		jsonStates:            make(map[string]map[string]json.RawMessage),
+	}
+
+	bank, ok := appState[banktypes.ModuleName]
+	if !ok {
+		return errors.New("the genesis has no bank state")
+	}
+	e.bank = &banktypes.GenesisState{}
+	if err := cdc.UnmarshalJSON(bank, e.bank); err != nil {
+		return fmt.Errorf("failed to unmarshal bank genesis state: %w", err)
+	}
+	e.balances = make(map[string]sdk.Coins, len(e.bank.Balances))
+	for _, b := range e.bank.Balances {
+		e.balances[b.Address] = e.balances[b.Address].Add(b.Coins...)
+	}
+
+	for i, edit := range edits {
+		var err error
+		switch {
+		case edit.ReplaceValidators != nil:
+			err = e.replaceValidators(edit.ReplaceValidators.Validators)
+		case edit.SetBalance != nil:
+			err = e.editBalance(edit.SetBalance, func(_, coins sdk.Coins) (sdk.Coins, error) { return coins, nil })
+		case edit.AddBalance != nil:
+			err = e.editBalance(edit.AddBalance, func(balance, coins sdk.Coins) (sdk.Coins, error) { return balance.Add(coins...), nil })
+		case edit.SubBalance != nil:
+			err = e.editBalance(edit.SubBalance, subCoins)
+		case edit.ResetGovProposals != nil:
+			err = e.resetGovProposals()
+		}
+		if err != nil {
+			return fmt.Errorf("genesis edit %d: %w", i, err)
+		}
+	}
+
+	return e.save()
+}
+
+// genesisEditor holds the decoded module states edited by EditGenesis.
+type genesisEditor struct {
+	cdc                   codec.Codec
+	addressCodec          address.Codec
+	validatorAddressCodec address.Codec
+	consensusAddressCodec address.Codec
+	powerReduction        math.Int
+	appGenesis            *genutiltypes.AppGenesis
+	appState              map[string]json.RawMessage
+
+	bank     *banktypes.GenesisState
+	balances map[string]sdk.Coins
+	auth     *authtypes.GenesisState
+	staking  *stakingtypes.GenesisState
+	// jsonStates are the top-level fields of the modules edited as JSON
+	jsonStates map[string]map[string]json.RawMessage
+}
+
+func (e *genesisEditor) authState() *authtypes.GenesisState {
+	if e.auth == nil {
+		state := authtypes.GetGenesisStateFromAppState(e.cdc, e.appState)
+		e.auth = &state
+	}
+
+	return e.auth
+}
+
+func (e *genesisEditor) stakingState() *stakingtypes.GenesisState {
+	if e.staking == nil {
+		e.staking = stakingtypes.GetGenesisStateFromAppState(e.cdc, e.appState)
+	}
+
+	return e.staking
+}
+
+// jsonState returns the top-level fields of the state of a module, or nil if the module
+// has no state.
+func (e *genesisEditor) jsonState(module string) (map[string]json.RawMessage, error) {
+	if state, ok := e.jsonStates[module]; ok {
+		return state, nil
+	}
+
+	bz, ok := e.appState[module]
+	if !ok {
+		return nil, nil
+	}
+
+	var state map[string]json.RawMessage
+	if err := json.Unmarshal(bz, &state); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal %s genesis state: %w", module, err)
+	}
+	if state == nil {
+		state = make(map[string]json.RawMessage)
+	}
+	e.jsonStates[module] = state
+
+	return state, nil
+}
+
+// save writes the edited module states to the app state.
+func (e *genesisEditor) save() error {
+	balances := make([]banktypes.Balance, 0, len(e.balances))
+	supply := sdk.NewCoins()
+	for addr, coins := range e.balances {
+		if coins.IsZero() {
+			continue
+		}
+		balances = append(balances, banktypes.Balance{Address: addr, Coins: coins})
+		supply = supply.Add(coins...)
+	}
+
+	var err error
+	if e.bank.Balances, err = banktypes.SanitizeGenesisBalances(balances, e.addressCodec); err != nil {
+		return fmt.Errorf("failed to sanitize genesis balances: %w", err)
+	}
+	e.bank.Supply = supply
+	if e.appState[banktypes.ModuleName], err = e.cdc.MarshalJSON(e.bank); err != nil {
+		return fmt.Errorf("failed to marshal bank genesis state: %w", err)
+	}
+
+	if e.auth != nil {
+		if e.appState[authtypes.ModuleName], err = e.cdc.MarshalJSON(e.auth); err != nil {
+			return fmt.Errorf("failed to marshal auth genesis state: %w", err)
+		}
+	}
+
+	if e.staking != nil {
+		if e.appState[stakingtypes.ModuleName], err = e.cdc.MarshalJSON(e.staking); err != nil {
+			return fmt.Errorf("failed to marshal staking genesis state: %w", err)
+		}
+	}
+
+	for module, state := range e.jsonStates {
+		if e.appState[module], err = json.Marshal(state); err != nil {
+			return fmt.Errorf("failed to marshal %s genesis state: %w", module, err)
+		}
+	}
+
+	return nil
+}
+
+func (e *genesisEditor) moduleAddress(module string) (string, error) {
+	return e.addressCodec.BytesToString(authtypes.NewModuleAddress(module))
+}
+
+func (e *genesisEditor) editBalance(edit *BalanceEdit, apply func(balance, coins sdk.Coins) (sdk.Coins, error)) error {
+	if _, err := e.addressCodec.StringToBytes(edit.Address); err != nil {
+		return fmt.Errorf("invalid address %s: %w", edit.Address, err)
+	}
+
+	coins, err := sdk.ParseCoinsNormalized(edit.Coins)
+	if err != nil {
+		return fmt.Errorf("failed to parse coins: %w", err)
+	}
+
+	balance, err := apply(e.balances[edit.Address], coins)
+	if err != nil {
+		return fmt.Errorf("balance of %s: %w", edit.Address, err)
+	}
+	e.balances[edit.Address] = balance
+
+	return nil
+}
+
+// transfer moves coins between two accounts.
+func (e *genesisEditor) transfer(from, to string, coins sdk.Coins) error {
+	balance, err := subCoins(e.balances[from], coins)
+	if err != nil {
+		return fmt.Errorf("balance of %s: %w", from, err)
+	}
+	e.balances[from] = balance
+	e.balances[to] = e.balances[to].Add(coins...)
+
+	return nil
+}
+
+func subCoins(balance, coins sdk.Coins) (sdk.Coins, error) {
+	result, negative := balance.SafeSub(coins...)
+	if negative {
+		return nil, fmt.Errorf("insufficient funds: %s < %s", balance, coins)
+	}
+
+	return result, nil
+}
+
+// newValidator is a validator replacing the validator set.
+type newValidator struct {
+	operator  string
+	valAddr   string
+	consAddr  string
+	pubKey    cryptotypes.PubKey
+	power     int64
+	tokens    math.Int
+	moniker   string
+	accountID sdk.AccAddress
+}
+
+func (e *genesisEditor) parseValidators(validators []EditValidator) ([]newValidator, error) {
+	if len(validators) == 0 {
+		return nil, errors.New("the validator set cannot be empty")
+	}
+
+	seen := make(map[string]bool)
+	parsed := make([]newValidator, 0, len(validators))
+	for _, v := range validators {
+		accAddr, err := e.addressCodec.StringToBytes(v.Operator)
+		if err != nil {
+			return nil, fmt.Errorf("invalid operator address %s: %w", v.Operator, err)
+		}
+		valAddr, err := e.validatorAddressCodec.BytesToString(accAddr)
+		if err != nil {
+			return nil, err
+		}
+
+		var pubKey cryptotypes.PubKey
+		if err := e.cdc.UnmarshalInterfaceJSON(v.PubKey, &pubKey); err != nil {
+			return nil, fmt.Errorf("invalid public key of validator %s: %w", v.Operator, err)
+		}
+		consAddr, err := e.consensusAddressCodec.BytesToString(pubKey.Address())
+		if err != nil {
+			return nil, err
+		}
+
+		if v.Power <= 0 {
+			return nil, fmt.Errorf("the power of validator %s must be positive", v.Operator)
+		}
+		if seen[valAddr] || seen[consAddr] {
+			return nil, fmt.Errorf("duplicate validator %s", v.Operator)
+		}
+		seen[valAddr], seen[consAddr] = true, true
+
+		moniker := v.Moniker
+		if moniker == "" {
+			moniker = v.Operator
+		}
+
+		parsed = append(parsed, newValidator{
+			operator:  v.Operator,
+			valAddr:   valAddr,
+			consAddr:  consAddr,
+			pubKey:    pubKey,
+			power:     v.Power,
+			tokens:    sdk.TokensFromConsensusPower(v.Power, e.powerReduction),
+			moniker:   moniker,
+			accountID: accAddr,
+		})
+	}
+
+	return parsed, nil
+}
+
+func (e *genesisEditor) replaceValidators(validators []EditValidator) error {
+	newValidators, err := e.parseValidators(validators)
+	if err != nil {
+		return err
+	}
+
+	staking := e.stakingState()
+	bondDenom := staking.Params.BondDenom
+	bondedPool, err := e.moduleAddress(stakingtypes.BondedPoolName)
+	if err != nil {
+		return err
+	}
+	notBondedPool, err := e.moduleAddress(stakingtypes.NotBondedPoolName)
+	if err != nil {
+		return err
+	}
+
+	// refund the delegations to the current validators, the rounding remainder is burned
+	current := make(map[string]stakingtypes.Validator, len(staking.Validators))
+	for _, val := range staking.Validators {
+		current[val.OperatorAddress] = val
+	}
+	for _, del := range staking.Delegations {
+		val, ok := current[del.ValidatorAddress]
+		if !ok {
+			return fmt.Errorf("delegation to unknown validator %s", del.ValidatorAddress)
+		}
+		if val.DelegatorShares.IsZero() {
+			continue
+		}
+
+		tokens := del.Shares.MulInt(val.Tokens).Quo(val.DelegatorShares).TruncateInt()
+		e.balances[del.DelegatorAddress] = e.balances[del.DelegatorAddress].Add(sdk.NewCoin(bondDenom, tokens))
+	}
+	for _, val := range staking.Validators {
+		pool := notBondedPool
+		if val.IsBonded() {
+			pool = bondedPool
+		}
+		if e.balances[pool], err = subCoins(e.balances[pool], sdk.NewCoins(sdk.NewCoin(bondDenom, val.Tokens))); err != nil {
+			return fmt.Errorf("tokens of validator %s: %w", val.OperatorAddress, err)
+		}
+	}
+
+	staking.Validators = make([]stakingtypes.Validator, 0, len(newValidators))
+	staking.Delegations = make([]stakingtypes.Delegation, 0, len(newValidators))
+	staking.LastValidatorPowers = make([]stakingtypes.LastValidatorPower, 0, len(newValidators))
+	staking.Redelegations = nil
+	// the validators are bonded, the distribution and slashing records are set below
+	staking.Exported = true
+	totalPower := int64(0)
+
+	auth := e.authState()
+	accounts, err := authtypes.UnpackAccounts(auth.Accounts)
+	if err != nil {
+		return fmt.Errorf("failed to get accounts from any: %w", err)
+	}
+
+	consensusValidators := make([]cmttypes.GenesisValidator, 0, len(newValidators))
+	for _, v := range newValidators {
+		val, err := stakingtypes.NewValidator(v.valAddr, v.pubKey, stakingtypes.NewDescription(v.moniker, "", "", "", ""))
+		if err != nil {
+			return err
+		}
+		val.Status = stakingtypes.Bonded
+		val.Tokens = v.tokens
+		val.DelegatorShares = math.LegacyNewDecFromInt(v.tokens)
+		val.Commission = stakingtypes.NewCommission(math.LegacyZeroDec(), math.LegacyOneDec(), math.LegacyOneDec())
+
+		staking.Validators = append(staking.Validators, val)
+		staking.Delegations = append(staking.Delegations, stakingtypes.NewDelegation(v.operator, v.valAddr, val.DelegatorShares))
+		staking.LastValidatorPowers = append(staking.LastValidatorPowers, stakingtypes.LastValidatorPower{Address: v.valAddr, Power: v.power})
+		totalPower += v.power
+
+		// the self-delegated tokens are minted
+		e.balances[bondedPool] = e.balances[bondedPool].Add(sdk.NewCoin(bondDenom, v.tokens))
+
+		if !accounts.Contains(v.accountID) {
+			accounts = append(accounts, authtypes.NewBaseAccount(v.accountID, nil, 0, 0))
+		}
+
+		cmtPubKey, err := cryptocodec.ToCmtPubKeyInterface(v.pubKey)
+		if err != nil {
+			return err
+		}
+		consensusValidators = append(consensusValidators, cmttypes.GenesisValidator{
+			Address: cmtPubKey.Address(),
+			PubKey:  cmtPubKey,
+			Power:   v.power,
+			Name:    v.moniker,
+		})
+	}
+	staking.LastTotalPower = math.NewInt(totalPower)
+
+	if auth.Accounts, err = authtypes.PackAccounts(authtypes.SanitizeGenesisAccounts(accounts)); err != nil {
+		return fmt.Errorf("failed to convert accounts into any's: %w", err)
+	}
+
+	if e.appGenesis.Consensus == nil {
+		e.appGenesis.Consensus = &genutiltypes.ConsensusGenesis{}
+	}
+	e.appGenesis.Consensus.Validators = consensusValidators
+
+	if err := e.replaceDistributionValidators(newValidators); err != nil {
+		return err
+	}
+
+	return e.replaceSlashingValidators(newValidators)
+}
+
+// replaceDistributionValidators replaces the validator records of the distribution module,
+// initializing the records of the new validators and their self-delegations as the
+// distribution hooks do.
+func (e *genesisEditor) replaceDistributionValidators(validators []newValidator) error {
+	state, err := e.jsonState(distributionModuleName)
+	if err != nil || state == nil {
+		return err
+	}
+
+	var feePool map[string]json.RawMessage
+	if bz, ok := state["fee_pool"]; ok {
+		if err := json.Unmarshal(bz, &feePool); err != nil {
+			return fmt.Errorf("failed to unmarshal distribution fee pool: %w", err)
+		}
+	}
+	if feePool == nil {
+		feePool = make(map[string]json.RawMessage)
+	}
+
+	var decimalPool sdk.DecCoins
+	if bz, ok := feePool["decimal_pool"]; ok {
+		if err := json.Unmarshal(bz, &decimalPool); err != nil {
+			return fmt.Errorf("failed to unmarshal distribution decimal pool: %w", err)
+		}
+	}
+
+	// the outstanding rewards of the removed validators are moved to the decimal pool,
+	// which keeps the holdings of the module consistent with its balance
+	var outstanding []struct {
+		OutstandingRewards sdk.DecCoins `json:"outstanding_rewards"`
+	}
+	if bz, ok := state["outstanding_rewards"]; ok {
+		if err := json.Unmarshal(bz, &outstanding); err != nil {
+			return fmt.Errorf("failed to unmarshal distribution outstanding rewards: %w", err)
+		}
+	}
+	for _, rewards := range outstanding {
+		decimalPool = decimalPool.Add(rewards.OutstandingRewards...)
+	}
+
+	empty := []any{}
+	var (
+		outstandingRewards []any
+		commissions        []any
+		historicalRewards  []any
+		currentRewards     []any
+		startingInfos      []any
+	)
+	for _, v := range validators {
+		outstandingRewards = append(outstandingRewards, map[string]any{
+			"validator_address":   v.valAddr,
+			"outstanding_rewards": empty,
+		})
+		commissions = append(commissions, map[string]any{
+			"validator_address": v.valAddr,
+			"accumulated":       map[string]any{"commission": empty},
+		})
+		// the period 0 is referenced by the current rewards and the self-delegation
+		historicalRewards = append(historicalRewards, map[string]any{
+			"validator_address": v.valAddr,
+			"period":            "0",
+			"rewards":           map[string]any{"cumulative_reward_ratio": empty, "reference_count": 2},
+		})
+		currentRewards = append(currentRewards, map[string]any{
+			"validator_address": v.valAddr,
+			"rewards":           map[string]any{"rewards": empty, "period": "1"},
+		})
+		startingInfos = append(startingInfos, map[string]any{
+			"delegator_address": v.operator,
+			"validator_address": v.valAddr,
+			"starting_info": map[string]any{
+				"previous_period": "0",
+				"stake":           math.LegacyNewDecFromInt(v.tokens).String(),
+				"height":          "0",
+			},
+		})
+	}
+
+	for field, value := range map[string]any{
+		"outstanding_rewards":               outstandingRewards,
+		"validator_accumulated_commissions": commissions,
+		"validator_historical_rewards":      historicalRewards,
+		"validator_current_rewards":         currentRewards,
+		"delegator_starting_infos":          startingInfos,
+		"validator_slash_events":            empty,
+		"previous_proposer":                 "",
+	} {
+		if state[field], err = json.Marshal(value); err != nil {
+			return err
+		}
+	}
+
+	if feePool["decimal_pool"], err = json.Marshal(decimalPool); err != nil {
+		return err
+	}
+	state["fee_pool"], err = json.Marshal(feePool)
+
+	return err
+}
+
+// replaceSlashingValidators replaces the signing infos of the slashing module.
+func (e *genesisEditor) replaceSlashingValidators(validators []newValidator) error {
+	state, err := e.jsonState(slashingModuleName)
+	if err != nil || state == nil {
+		return err
+	}
+
+	signingInfos := make([]any, 0, len(validators))
+	for _, v := range validators {
+		signingInfos = append(signingInfos, map[string]any{
+			"address": v.consAddr,
+			"validator_signing_info": map[string]any{
+				"address":               v.consAddr,
+				"start_height":          "0",
+				"index_offset":          "0",
+				"jailed_until":          "1970-01-01T00:00:00Z",
+				"tombstoned":            false,
+				"missed_blocks_counter": "0",
+			},
+		})
+	}
+
+	if state["signing_infos"], err = json.Marshal(signingInfos); err != nil {
+		return err
+	}
+	state["missed_blocks"], err = json.Marshal([]any{})
+
+	return err
+}
+
+// resetGovProposals removes the governance proposals, votes and deposits, and refunds the
+// deposits from the governance module account.
+func (e *genesisEditor) resetGovProposals() error {
+	state, err := e.jsonState(govModuleName)
+	if err != nil {
+		return err
+	}
+	if state == nil {
+		return errors.New("the genesis has no gov state")
+	}
+
+	var deposits []struct {
+		Depositor string    `json:"depositor"`
+		Amount    sdk.Coins `json:"amount"`
+	}
+	if bz, ok := state["deposits"]; ok {
+		if err := json.Unmarshal(bz, &deposits); err != nil {
+			return fmt.Errorf("failed to unmarshal gov deposits: %w", err)
+		}
+	}
+
+	govAddr, err := e.moduleAddress(govModuleName)
+	if err != nil {
+		return err
+	}
+	for _, deposit := range deposits {
+		if err := e.transfer(govAddr, deposit.Depositor, deposit.Amount); err != nil {
+			return fmt.Errorf("refund of deposit of %s: %w", deposit.Depositor, err)
+		}
+	}
+
+	for _, field := range []string{"deposits", "votes", "proposals"} {
+		state[field] = json.RawMessage("[]")
+	}
+
+	return nil
+}
+
+// CheckGenesisInvariants checks that the module accounts of an app state hold the tokens
+// accounted by their modules: the bank supply, the staking pools, the distribution holdings
+// and the governance deposits. The InitGenesis of these modules fail otherwise.
+func CheckGenesisInvariants(cdc codec.JSONCodec, addressCodec address.Codec, appState map[string]json.RawMessage) error {
+	bank := banktypes.GetGenesisStateFromAppState(cdc, appState)
+	balances := make(map[string]sdk.Coins, len(bank.Balances))
+	total := sdk.NewCoins()
+	for _, b := range bank.Balances {
+		balances[b.Address] = balances[b.Address].Add(b.Coins...)
+		total = total.Add(b.Coins...)
+	}
+
+	moduleBalance := func(module string) (sdk.Coins, error) {
+		addr, err := addressCodec.BytesToString(authtypes.NewModuleAddress(module))
+		if err != nil {
+			return nil, err
+		}
+		return balances[addr], nil
+	}
+
+	var errs []error
+	if !bank.Supply.Empty() && !bank.Supply.Equal(total) {
+		errs = append(errs, fmt.Errorf("bank supply is different from the total balances: %s <-> %s", bank.Supply, total))
+	}
+
+	if _, ok := appState[stakingtypes.ModuleName]; ok {
+		if err := checkStakingInvariants(stakingtypes.GetGenesisStateFromAppState(cdc, appState), moduleBalance); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if bz, ok := appState[distributionModuleName]; ok {
+		var distribution struct {
+			FeePool struct {
+				DecimalPool sdk.DecCoins `json:"decimal_pool"`
+			} `json:"fee_pool"`
+			OutstandingRewards []struct {
+				OutstandingRewards sdk.DecCoins `json:"outstanding_rewards"`
+			} `json:"outstanding_rewards"`
+		}
+		if err := json.Unmarshal(bz, &distribution); err != nil {
+			return fmt.Errorf("failed to unmarshal distribution genesis state: %w", err)
+		}
+
+		holdings := distribution.FeePool.DecimalPool
+		for _, rewards := range distribution.OutstandingRewards {
+			holdings = holdings.Add(rewards.OutstandingRewards...)
+		}
+		holdingsInt, _ := holdings.TruncateDecimal()
+		balance, err := moduleBalance(distributionModuleName)
+		if err != nil {
+			return err
+		}
+		if !balance.Equal(holdingsInt) {
+			errs = append(errs, fmt.Errorf("distribution module balance is different from the module holdings: %s <-> %s", balance, holdingsInt))
+		}
+	}
+
+	if bz, ok := appState[govModuleName]; ok {
+		var gov struct {
+			Deposits []struct {
+				Amount sdk.Coins `json:"amount"`
+			} `json:"deposits"`
+		}
+		if err := json.Unmarshal(bz, &gov); err != nil {
+			return fmt.Errorf("failed to unmarshal gov genesis state: %w", err)
+		}
+
+		deposits := sdk.NewCoins()
+		for _, deposit := range gov.Deposits {
+			deposits = deposits.Add(deposit.Amount...)
+		}
+		balance, err := moduleBalance(govModuleName)
+		if err != nil {
+			return err
+		}
+		if !balance.Equal(deposits) {
+			errs = append(errs, fmt.Errorf("gov module balance is different from the deposits: %s <-> %s", balance, deposits))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func checkStakingInvariants(staking *stakingtypes.GenesisState, moduleBalance func(string) (sdk.Coins, error)) error {
+	bonded, notBonded := math.ZeroInt(), math.ZeroInt()
+	shares := make(map[string]math.LegacyDec, len(staking.Validators))
+	for _, val := range staking.Validators {
+		if val.IsBonded() {
+			bonded = bonded.Add(val.Tokens)
+		} else {
+			notBonded = notBonded.Add(val.Tokens)
+		}
+		shares[val.OperatorAddress] = math.LegacyZeroDec()
+	}
+	for _, ubd := range staking.UnbondingDelegations {
+		for _, entry := range ubd.Entries {
+			notBonded = notBonded.Add(entry.Balance)
+		}
+	}
+
+	var errs []error
+	for _, del := range staking.Delegations {
+		total, ok := shares[del.ValidatorAddress]
+		if !ok {
+			errs = append(errs, fmt.Errorf("delegation of %s to unknown validator %s", del.DelegatorAddress, del.ValidatorAddress))
+			continue
+		}
+		shares[del.ValidatorAddress] = total.Add(del.Shares)
+	}
+	for _, val := range staking.Validators {
+		if !shares[val.OperatorAddress].Equal(val.DelegatorShares) {
+			errs = append(errs, fmt.Errorf("delegator shares of validator %s are different from its delegations: %s <-> %s", val.OperatorAddress, val.DelegatorShares, shares[val.OperatorAddress]))
+		}
+	}
+
+	for _, pool := range []struct {
+		name   string
+		tokens math.Int
+	}{{stakingtypes.BondedPoolName, bonded}, {stakingtypes.NotBondedPoolName, notBonded}} {
+		balance, err := moduleBalance(pool.name)
+		if err != nil {
+			return err
+		}
+		expected := sdk.NewCoins(sdk.NewCoin(staking.Params.BondDenom, pool.tokens))
+		if !balance.Equal(expected) {
+			errs = append(errs, fmt.Errorf("%s pool balance is different from its tokens: %s <-> %s", pool.name, balance, expected))
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,138 @@
+package genutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"cosmossdk.io/math"
+	authtypes "cosmossdk.io/x/auth/types"
+	banktypes "cosmossdk.io/x/bank/types"
+	stakingtypes "cosmossdk.io/x/staking/types"
+
+	codectestutil "github.com/cosmos/cosmos-sdk/codec/testutil"
+	cryptocodec "github.com/cosmos/cosmos-sdk/crypto/codec"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/ed25519"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	genutiltypes "github.com/cosmos/cosmos-sdk/x/genutil/types"
+)
+
+func TestParseGenesisEdits(t *testing.T) {
+	edits, err := ParseGenesisEdits([]byte(`[{"set_balance": {"address": "addr", "coins": "1stake"}}, {"reset_gov_proposals": {}}]`))
+	require.NoError(t, err)
+	require.Len(t, edits, 2)
+	require.Equal(t, &BalanceEdit{Address: "addr", Coins: "1stake"}, edits[0].SetBalance)
+	require.NotNil(t, edits[1].ResetGovProposals)
+
+	_, err = ParseGenesisEdits([]byte(`[{}]`))
+	require.ErrorContains(t, err, "genesis edit 0 must have exactly one operation, got 0")
+
+	_, err = ParseGenesisEdits([]byte(`[{"set_balance": {}, "add_balance": {}}]`))
+	require.ErrorContains(t, err, "genesis edit 0 must have exactly one operation, got 2")
+
+	_, err = ParseGenesisEdits([]byte(`[{"burn": {}}]`))
+	require.ErrorContains(t, err, "unknown field")
+}
+
+func TestEditGenesis(t *testing.T) {
+	opts := codectestutil.CodecOptions{}
+	cdc := opts.NewCodec()
+	addressCodec := opts.GetAddressCodec()
+
+	addrString := func(addr []byte) string {
+		s, err := addressCodec.BytesToString(addr)
+		require.NoError(t, err)
+		return s
+	}
+	alice := addrString([]byte("alice_______________"))
+	bob := addrString([]byte("bob_________________"))
+	gov := addrString(authtypes.NewModuleAddress(govModuleName))
+
+	bank := banktypes.DefaultGenesisState()
+	bank.Balances = []banktypes.Balance{
+		{Address: alice, Coins: sdk.NewCoins(sdk.NewInt64Coin("stake", 100))},
+		{Address: gov, Coins: sdk.NewCoins(sdk.NewInt64Coin("stake", 10))},
+	}
+	bank.Supply = sdk.NewCoins(sdk.NewInt64Coin("stake", 110))
+	bankState, err := cdc.MarshalJSON(bank)
+	require.NoError(t, err)
+
+	appState := map[string]json.RawMessage{
+		banktypes.ModuleName: bankState,
+		govModuleName: json.RawMessage(fmt.Sprintf(`{
+			"starting_proposal_id": "2",
+			"proposals": [{"id": "1"}],
+			"votes": [{"proposal_id": "1", "voter": %[1]q}],
+			"deposits": [{"proposal_id": "1", "depositor": %[1]q, "amount": [{"denom": "stake", "amount": "10"}]}]
+		}`, bob)),
+	}
+	require.NoError(t, CheckGenesisInvariants(cdc, addressCodec, appState))
+
+	edits, err := ParseGenesisEdits([]byte(fmt.Sprintf(`[
+		{"sub_balance": {"address": %[1]q, "coins": "40stake"}},
+		{"add_balance": {"address": %[2]q, "coins": "5stake,1atom"}},
+		{"reset_gov_proposals": {}}
+	]`, alice, bob)))
+	require.NoError(t, err)
+	require.NoError(t, EditGenesis(cdc, addressCodec, opts.GetValidatorCodec(), addressCodec, sdk.DefaultPowerReduction, &genutiltypes.AppGenesis{}, appState, edits))
+	require.NoError(t, CheckGenesisInvariants(cdc, addressCodec, appState))
+
+	edited := banktypes.GetGenesisStateFromAppState(cdc, appState)
+	require.Equal(t, sdk.NewCoins(sdk.NewInt64Coin("atom", 1), sdk.NewInt64Coin("stake", 75)), edited.Supply)
+	balances := make(map[string]sdk.Coins)
+	for _, b := range edited.Balances {
+		balances[b.Address] = b.Coins
+	}
+	require.Equal(t, map[string]sdk.Coins{
+		alice: sdk.NewCoins(sdk.NewInt64Coin("stake", 60)),
+		// the deposit is refunded
+		bob: sdk.NewCoins(sdk.NewInt64Coin("atom", 1), sdk.NewInt64Coin("stake", 15)),
+	}, balances)
+	require.JSONEq(t, `{"starting_proposal_id": "2", "proposals": [], "votes": [], "deposits": []}`, string(appState[govModuleName]))
+
+	// the funds of an account cannot go negative
+	edits, err = ParseGenesisEdits([]byte(fmt.Sprintf(`[{"sub_balance": {"address": %q, "coins": "100stake"}}]`, alice)))
+	require.NoError(t, err)
+	require.ErrorContains(t, EditGenesis(cdc, addressCodec, opts.GetValidatorCodec(), addressCodec, sdk.DefaultPowerReduction, &genutiltypes.AppGenesis{}, appState, edits), "insufficient funds")
+
+	// the gov deposits must be held by the gov module account
+	edits, err = ParseGenesisEdits([]byte(fmt.Sprintf(`[{"add_balance": {"address": %q, "coins": "1stake"}}]`, gov)))
+	require.NoError(t, err)
+	require.NoError(t, EditGenesis(cdc, addressCodec, opts.GetValidatorCodec(), addressCodec, sdk.DefaultPowerReduction, &genutiltypes.AppGenesis{}, appState, edits))
+	require.ErrorContains(t, CheckGenesisInvariants(cdc, addressCodec, appState), "gov module balance is different from the deposits")
+}
+
+func TestEditGenesisPowerReduction(t *testing.T) {
+	opts := codectestutil.CodecOptions{}
+	cdc := opts.NewCodec()
+	cryptocodec.RegisterInterfaces(cdc.InterfaceRegistry())
+	authtypes.RegisterInterfaces(cdc.InterfaceRegistry())
+	addressCodec := opts.GetAddressCodec()
+
+	operator, err := addressCodec.BytesToString([]byte("operator____________"))
+	require.NoError(t, err)
+	pubKeyJSON, err := cdc.MarshalInterfaceJSON(ed25519.GenPrivKey().PubKey())
+	require.NoError(t, err)
+	edits, err := ParseGenesisEdits([]byte(fmt.Sprintf(`[{"replace_validators": {"validators": [{"operator": %q, "pub_key": %s, "power": 10}]}}]`, operator, pubKeyJSON)))
+	require.NoError(t, err)
+
+	newAppState := func() map[string]json.RawMessage {
+		bankState, err := cdc.MarshalJSON(banktypes.DefaultGenesisState())
+		require.NoError(t, err)
+		stakingState, err := cdc.MarshalJSON(stakingtypes.DefaultGenesisState())
+		require.NoError(t, err)
+		return map[string]json.RawMessage{banktypes.ModuleName: bankState, stakingtypes.ModuleName: stakingState}
+	}
+
+	// the tokens of the validators are their power times the power reduction
+	appState := newAppState()
+	require.NoError(t, EditGenesis(cdc, addressCodec, opts.GetValidatorCodec(), addressCodec, math.NewInt(1000), &genutiltypes.AppGenesis{}, appState, edits))
+	staking := stakingtypes.GetGenesisStateFromAppState(cdc, appState)
+	require.Len(t, staking.Validators, 1)
+	require.Equal(t, math.NewInt(10_000), staking.Validators[0].Tokens)
+	require.Equal(t, sdk.NewCoins(sdk.NewInt64Coin(sdk.DefaultBondDenom, 10_000)), banktypes.GetGenesisStateFromAppState(cdc, appState).Supply)
+
+	require.ErrorContains(t, EditGenesis(cdc, addressCodec, opts.GetValidatorCodec(), addressCodec, math.ZeroInt(), &genutiltypes.AppGenesis{}, newAppState(), edits), "the power reduction must be positive")
+}
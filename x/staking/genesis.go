@@ -62,36 +62,44 @@ func validateGenesisStateValidators(validators []types.Validator) error {
 	addrMap := make(map[string]bool, len(validators))
 
 	for i := 0; i < len(validators); i++ {
-		val := validators[i]
-		consPk, err := val.ConsPubKey()
-		if err != nil {
+		if err := validateGenesisValidator(validators[i], addrMap); err != nil {
 			return err
 		}
+	}
 
-		strKey := string(consPk.Bytes())
+	return nil
+}
 
-		if _, ok := addrMap[strKey]; ok {
-			consAddr, err := val.GetConsAddr()
-			if err != nil {
-				return err
-			}
-			return fmt.Errorf("duplicate validator in genesis state: moniker %v, address %v", val.Description.Moniker, consAddr)
-		}
+// validateGenesisValidator validates a genesis validator, whose consensus key must not be
+// in addrMap, and adds its consensus key to addrMap.
+func validateGenesisValidator(val types.Validator, addrMap map[string]bool) error {
+	consPk, err := val.ConsPubKey()
+	if err != nil {
+		return err
+	}
 
-		if val.Jailed && val.IsBonded() {
-			consAddr, err := val.GetConsAddr()
-			if err != nil {
-				return err
-			}
-			return fmt.Errorf("validator is bonded and jailed in genesis state: moniker %v, address %v", val.Description.Moniker, consAddr)
+	strKey := string(consPk.Bytes())
+
+	if _, ok := addrMap[strKey]; ok {
+		consAddr, err := val.GetConsAddr()
+		if err != nil {
+			return err
 		}
+		return fmt.Errorf("duplicate validator in genesis state: moniker %v, address %v", val.Description.Moniker, consAddr)
+	}
 
-		if val.DelegatorShares.IsZero() && !val.IsUnbonding() {
-			return fmt.Errorf("bonded/unbonded genesis validator cannot have zero delegator shares, validator: %v", val)
+	if val.Jailed && val.IsBonded() {
+		consAddr, err := val.GetConsAddr()
+		if err != nil {
+			return err
 		}
+		return fmt.Errorf("validator is bonded and jailed in genesis state: moniker %v, address %v", val.Description.Moniker, consAddr)
+	}
 
-		addrMap[strKey] = true
+	if val.DelegatorShares.IsZero() && !val.IsUnbonding() {
+		return fmt.Errorf("bonded/unbonded genesis validator cannot have zero delegator shares, validator: %v", val)
 	}
 
+	addrMap[strKey] = true
 	return nil
 }
@@ -18,9 +18,53 @@ import (
 // data. Finally, it updates the bonded validators.
 // Returns final validator set after applying all declaration and delegations
 func (k Keeper) InitGenesis(ctx context.Context, data *types.GenesisState) ([]appmodule.ValidatorUpdate, error) {
-	bondedTokens := math.ZeroInt()
-	notBondedTokens := math.ZeroInt()
+	importer, err := k.NewGenesisImporter(ctx, data.Params, data.LastTotalPower, data.Exported)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, validator := range data.Validators {
+		if err := importer.ImportValidator(validator); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, delegation := range data.Delegations {
+		if err := importer.ImportDelegation(delegation); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, ubd := range data.UnbondingDelegations {
+		if err := importer.ImportUnbondingDelegation(ubd); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, red := range data.Redelegations {
+		if err := importer.ImportRedelegation(red); err != nil {
+			return nil, err
+		}
+	}
 
+	return importer.Finish(data.LastValidatorPowers)
+}
+
+// GenesisImporter imports the validators, delegations, unbonding delegations and
+// redelegations of a genesis state one at a time, e.g. from a streamed genesis, in
+// this order. Only the bonded and not bonded tokens are kept.
+type GenesisImporter struct {
+	k               Keeper
+	ctx             context.Context
+	bondDenom       string
+	exported        bool
+	bondedTokens    math.Int
+	notBondedTokens math.Int
+}
+
+// NewGenesisImporter sets the params and last total power of a genesis state and returns
+// an importer of its entries.
+func (k Keeper) NewGenesisImporter(ctx context.Context, params types.Params, lastTotalPower math.Int, exported bool) (*GenesisImporter, error) {
 	// We need to pretend to be "n blocks before genesis", where "n" is the
 	// validator update delay, so that e.g. slashing periods are correctly
 	// initialized for the validator set e.g. with a one-block offset - the
@@ -30,115 +74,144 @@ func (k Keeper) InitGenesis(ctx context.Context, data *types.GenesisState) ([]ap
 	sdkCtx = sdkCtx.WithBlockHeight(1 - sdk.ValidatorUpdateDelay) // TODO: remove this need for WithBlockHeight
 	ctx = sdkCtx
 
-	if err := k.Params.Set(ctx, data.Params); err != nil {
+	if err := k.Params.Set(ctx, params); err != nil {
 		return nil, err
 	}
 
-	if err := k.LastTotalPower.Set(ctx, data.LastTotalPower); err != nil {
+	if err := k.LastTotalPower.Set(ctx, lastTotalPower); err != nil {
 		return nil, err
 	}
 
-	for _, validator := range data.Validators {
-		if err := k.SetValidator(ctx, validator); err != nil {
-			return nil, err
-		}
+	return &GenesisImporter{
+		k:               k,
+		ctx:             ctx,
+		bondDenom:       params.BondDenom,
+		exported:        exported,
+		bondedTokens:    math.ZeroInt(),
+		notBondedTokens: math.ZeroInt(),
+	}, nil
+}
 
-		// Manually set indices for the first time
-		if err := k.SetValidatorByConsAddr(ctx, validator); err != nil {
-			return nil, err
-		}
+// ImportValidator sets a validator and its indexes.
+func (g *GenesisImporter) ImportValidator(validator types.Validator) error {
+	k, ctx := g.k, g.ctx
+	if err := k.SetValidator(ctx, validator); err != nil {
+		return err
+	}
 
-		if err := k.SetValidatorByPowerIndex(ctx, validator); err != nil {
-			return nil, err
-		}
+	// Manually set indices for the first time
+	if err := k.SetValidatorByConsAddr(ctx, validator); err != nil {
+		return err
+	}
 
-		// Call the creation hook if not exported
-		if !data.Exported {
-			valbz, err := k.ValidatorAddressCodec().StringToBytes(validator.GetOperator())
-			if err != nil {
-				return nil, err
-			}
-			if err := k.Hooks().AfterValidatorCreated(ctx, valbz); err != nil {
-				return nil, err
-			}
+	if err := k.SetValidatorByPowerIndex(ctx, validator); err != nil {
+		return err
+	}
+
+	// Call the creation hook if not exported
+	if !g.exported {
+		valbz, err := k.ValidatorAddressCodec().StringToBytes(validator.GetOperator())
+		if err != nil {
+			return err
+		}
+		if err := k.Hooks().AfterValidatorCreated(ctx, valbz); err != nil {
+			return err
 		}
+	}
 
-		// update timeslice if necessary
-		if validator.IsUnbonding() {
-			if err := k.InsertUnbondingValidatorQueue(ctx, validator); err != nil {
-				return nil, err
-			}
+	// update timeslice if necessary
+	if validator.IsUnbonding() {
+		if err := k.InsertUnbondingValidatorQueue(ctx, validator); err != nil {
+			return err
 		}
+	}
 
-		switch validator.GetStatus() {
-		case sdk.Bonded:
-			bondedTokens = bondedTokens.Add(validator.GetTokens())
+	switch validator.GetStatus() {
+	case sdk.Bonded:
+		g.bondedTokens = g.bondedTokens.Add(validator.GetTokens())
 
-		case sdk.Unbonding, sdk.Unbonded:
-			notBondedTokens = notBondedTokens.Add(validator.GetTokens())
+	case sdk.Unbonding, sdk.Unbonded:
+		g.notBondedTokens = g.notBondedTokens.Add(validator.GetTokens())
 
-		default:
-			return nil, fmt.Errorf("invalid validator status: %v", validator.GetStatus())
-		}
+	default:
+		return fmt.Errorf("invalid validator status: %v", validator.GetStatus())
 	}
 
-	for _, delegation := range data.Delegations {
-		delegatorAddress, err := k.authKeeper.AddressCodec().StringToBytes(delegation.DelegatorAddress)
-		if err != nil {
-			return nil, fmt.Errorf("invalid delegator address: %w", err)
-		}
+	return nil
+}
 
-		valAddr, err := k.validatorAddressCodec.StringToBytes(delegation.GetValidatorAddr())
-		if err != nil {
-			return nil, err
-		}
+// ImportDelegation sets a delegation.
+func (g *GenesisImporter) ImportDelegation(delegation types.Delegation) error {
+	k, ctx := g.k, g.ctx
+	delegatorAddress, err := k.authKeeper.AddressCodec().StringToBytes(delegation.DelegatorAddress)
+	if err != nil {
+		return fmt.Errorf("invalid delegator address: %w", err)
+	}
 
-		// Call the before-creation hook if not exported
-		if !data.Exported {
-			if err := k.Hooks().BeforeDelegationCreated(ctx, delegatorAddress, valAddr); err != nil {
-				return nil, err
-			}
-		}
+	valAddr, err := k.validatorAddressCodec.StringToBytes(delegation.GetValidatorAddr())
+	if err != nil {
+		return err
+	}
 
-		if err := k.SetDelegation(ctx, delegation); err != nil {
-			return nil, err
+	// Call the before-creation hook if not exported
+	if !g.exported {
+		if err := k.Hooks().BeforeDelegationCreated(ctx, delegatorAddress, valAddr); err != nil {
+			return err
 		}
+	}
 
-		// Call the after-modification hook if not exported
-		if !data.Exported {
-			if err := k.Hooks().AfterDelegationModified(ctx, delegatorAddress, valAddr); err != nil {
-				return nil, err
-			}
-		}
+	if err := k.SetDelegation(ctx, delegation); err != nil {
+		return err
 	}
 
-	for _, ubd := range data.UnbondingDelegations {
-		if err := k.SetUnbondingDelegation(ctx, ubd); err != nil {
-			return nil, err
+	// Call the after-modification hook if not exported
+	if !g.exported {
+		if err := k.Hooks().AfterDelegationModified(ctx, delegatorAddress, valAddr); err != nil {
+			return err
 		}
+	}
 
-		for _, entry := range ubd.Entries {
-			if err := k.InsertUBDQueue(ctx, ubd, entry.CompletionTime); err != nil {
-				return nil, err
-			}
-			notBondedTokens = notBondedTokens.Add(entry.Balance)
-		}
+	return nil
+}
+
+// ImportUnbondingDelegation sets an unbonding delegation and queues its entries.
+func (g *GenesisImporter) ImportUnbondingDelegation(ubd types.UnbondingDelegation) error {
+	if err := g.k.SetUnbondingDelegation(g.ctx, ubd); err != nil {
+		return err
 	}
 
-	for _, red := range data.Redelegations {
-		if err := k.SetRedelegation(ctx, red); err != nil {
-			return nil, err
+	for _, entry := range ubd.Entries {
+		if err := g.k.InsertUBDQueue(g.ctx, ubd, entry.CompletionTime); err != nil {
+			return err
 		}
+		g.notBondedTokens = g.notBondedTokens.Add(entry.Balance)
+	}
 
-		for _, entry := range red.Entries {
-			if err := k.InsertRedelegationQueue(ctx, red, entry.CompletionTime); err != nil {
-				return nil, err
-			}
+	return nil
+}
+
+// ImportRedelegation sets a redelegation and queues its entries.
+func (g *GenesisImporter) ImportRedelegation(red types.Redelegation) error {
+	if err := g.k.SetRedelegation(g.ctx, red); err != nil {
+		return err
+	}
+
+	for _, entry := range red.Entries {
+		if err := g.k.InsertRedelegationQueue(g.ctx, red, entry.CompletionTime); err != nil {
+			return err
 		}
 	}
 
-	bondedCoins := sdk.NewCoins(sdk.NewCoin(data.Params.BondDenom, bondedTokens))
-	notBondedCoins := sdk.NewCoins(sdk.NewCoin(data.Params.BondDenom, notBondedTokens))
+	return nil
+}
+
+// Finish checks the balances of the pools against the imported tokens and returns the
+// validator set, using lastValidatorPowers for an exported genesis state, which holds at
+// most one entry per bonded validator.
+func (g *GenesisImporter) Finish(lastValidatorPowers []types.LastValidatorPower) ([]appmodule.ValidatorUpdate, error) {
+	k, ctx := g.k, g.ctx
+	bondedCoins := sdk.NewCoins(sdk.NewCoin(g.bondDenom, g.bondedTokens))
+	notBondedCoins := sdk.NewCoins(sdk.NewCoin(g.bondDenom, g.notBondedTokens))
 
 	// check if the unbonded and bonded pools accounts exists
 	bondedPool := k.GetBondedPool(ctx)
@@ -176,8 +249,8 @@ func (k Keeper) InitGenesis(ctx context.Context, data *types.GenesisState) ([]ap
 
 	// don't need to run CometBFT updates if we exported
 	var moduleValidatorUpdates []appmodule.ValidatorUpdate
-	if data.Exported {
-		for _, lv := range data.LastValidatorPowers {
+	if g.exported {
+		for _, lv := range lastValidatorPowers {
 			valAddr, err := k.validatorAddressCodec.StringToBytes(lv.Address)
 			if err != nil {
 				return nil, err
@@ -9,12 +9,14 @@ import (
 	"github.com/stretchr/testify/suite"
 
 	"cosmossdk.io/collections"
+	"cosmossdk.io/core/genesis"
 	"cosmossdk.io/core/header"
 	"cosmossdk.io/core/log"
 	"cosmossdk.io/math"
 	storetypes "cosmossdk.io/store/types"
 	authtypes "cosmossdk.io/x/auth/types"
 	consensustypes "cosmossdk.io/x/consensus/types"
+	"cosmossdk.io/x/staking"
 	stakingkeeper "cosmossdk.io/x/staking/keeper"
 	stakingtestutil "cosmossdk.io/x/staking/testutil"
 	stakingtypes "cosmossdk.io/x/staking/types"
@@ -29,6 +31,7 @@ import (
 	simtestutil "github.com/cosmos/cosmos-sdk/testutil/sims"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	addresstypes "github.com/cosmos/cosmos-sdk/types/address"
+	"github.com/cosmos/cosmos-sdk/types/module"
 	moduletestutil "github.com/cosmos/cosmos-sdk/types/module/testutil"
 )
 
@@ -154,6 +157,46 @@ func (s *KeeperTestSuite) TestLastTotalPower() {
 	require.True(expTotalPower.Equal(resTotalPower))
 }
 
+func (s *KeeperTestSuite) TestExportGenesisToTarget() {
+	ctx, keeper := s.ctx, s.stakingKeeper
+	require := s.Require()
+
+	s.accountKeeper.EXPECT().AddressCodec().Return(address.NewBech32Codec("cosmos")).AnyTimes()
+	addrDels, valAddrs := createValAddrs(2)
+	valAddressCodec, addressCodec := address.NewBech32Codec("cosmosvaloper"), address.NewBech32Codec("cosmos")
+
+	for i, valAddr := range valAddrs {
+		validator := stakingtestutil.NewValidator(s.T(), valAddr, PKs[i])
+		validator, _ = validator.AddTokensFromDel(math.NewInt(10))
+		stakingkeeper.TestingUpdateValidator(keeper, ctx, validator, true)
+		require.NoError(keeper.SetDelegation(ctx, stakingtypes.NewDelegation(s.addressToString(addrDels[0]), s.valAddressToString(valAddr), math.LegacyNewDec(10))))
+	}
+	require.NoError(keeper.SetUnbondingDelegation(ctx, stakingtypes.NewUnbondingDelegation(
+		addrDels[1], valAddrs[0], 0, time.Unix(0, 0).UTC(), math.NewInt(5), 0, valAddressCodec, addressCodec,
+	)))
+	require.NoError(keeper.SetRedelegation(ctx, stakingtypes.NewRedelegation(
+		addrDels[1], valAddrs[0], valAddrs[1], 0, time.Unix(0, 0).UTC(), math.NewInt(5), math.LegacyNewDec(5), 0, valAddressCodec, addressCodec,
+	)))
+	require.NoError(keeper.LastTotalPower.Set(ctx, math.NewInt(20)))
+
+	expected, err := keeper.ExportGenesis(ctx)
+	require.NoError(err)
+	require.Len(expected.Validators, 2)
+	expectedBz, err := s.cdc.MarshalJSON(expected)
+	require.NoError(err)
+
+	dir := s.T().TempDir()
+	am := staking.NewAppModule(s.cdc, keeper, s.accountKeeper, s.bankKeeper)
+	require.NoError(am.ExportGenesisToTarget(ctx, genesis.DirTarget(dir, 1)))
+
+	bz, err := module.ReadGenesisDirJSON(dir)
+	require.NoError(err)
+	require.JSONEq(string(expectedBz), string(bz))
+
+	// the validators are validated one at a time
+	require.NoError(am.ValidateGenesisFromSource(genesis.DirSource(dir)))
+}
+
 // getREDByValDstIndexKey creates the index-key for a redelegation, stored by destination-validator-index
 // VALUE: none (key rearrangement used)
 func getREDByValDstIndexKey(delAddr sdk.AccAddress, valSrcAddr, valDstAddr sdk.ValAddress) []byte {
@@ -5,10 +5,13 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"github.com/cosmos/gogoproto/proto"
+	gogotypes "github.com/cosmos/gogoproto/types"
 	gwruntime "github.com/grpc-ecosystem/grpc-gateway/runtime"
 	"github.com/spf13/cobra"
 	"google.golang.org/grpc"
 
+	"cosmossdk.io/collections"
 	"cosmossdk.io/core/appmodule"
 	"cosmossdk.io/core/legacy"
 	"cosmossdk.io/core/registry"
@@ -36,6 +39,9 @@ var (
 	_ module.HasABCIGenesis      = AppModule{}
 	_ module.HasABCIEndBlock     = AppModule{}
 
+	_ module.HasGenesisExportToTarget       = AppModule{}
+	_ module.HasABCIGenesisImportFromSource = AppModule{}
+
 	_ appmodule.AppModule             = AppModule{}
 	_ appmodule.HasBeginBlocker       = AppModule{}
 	_ appmodule.HasServices           = AppModule{}
@@ -168,6 +174,189 @@ func (am AppModule) ExportGenesis(ctx context.Context) (json.RawMessage, error)
 	return marshalJSON, nil
 }
 
+// ExportGenesisToTarget streams the exported genesis state of the staking module to
+// target, one validator, delegation, unbonding delegation and redelegation at a time.
+func (am AppModule) ExportGenesisToTarget(ctx context.Context, target appmodule.GenesisTarget) error {
+	params, err := am.keeper.Params.Get(ctx)
+	if err != nil {
+		return err
+	}
+	totalPower, err := am.keeper.LastTotalPower.Get(ctx)
+	if err != nil {
+		return err
+	}
+	bz, err := am.cdc.MarshalJSON(&types.GenesisState{Params: params, LastTotalPower: totalPower, Exported: true})
+	if err != nil {
+		return err
+	}
+	if err := module.WriteGenesisFields(target, bz, "params", "last_total_power", "exported"); err != nil {
+		return err
+	}
+
+	if err := am.writeGenesisArray(target, "last_validator_powers", func(write func(proto.Message) error) error {
+		return am.keeper.LastValidatorPower.Walk(ctx, nil, func(key []byte, value gogotypes.Int64Value) (bool, error) {
+			addr, err := am.keeper.ValidatorAddressCodec().BytesToString(key)
+			if err != nil {
+				return true, err
+			}
+			return false, write(&types.LastValidatorPower{Address: addr, Power: value.Value})
+		})
+	}); err != nil {
+		return err
+	}
+
+	if err := am.writeGenesisArray(target, "validators", func(write func(proto.Message) error) error {
+		return am.keeper.Validators.Walk(ctx, nil, func(_ []byte, validator types.Validator) (bool, error) {
+			return false, write(&validator)
+		})
+	}); err != nil {
+		return err
+	}
+
+	if err := am.writeGenesisArray(target, "delegations", func(write func(proto.Message) error) error {
+		return am.keeper.Delegations.Walk(ctx, nil, func(_ collections.Pair[sdk.AccAddress, sdk.ValAddress], delegation types.Delegation) (bool, error) {
+			return false, write(&delegation)
+		})
+	}); err != nil {
+		return err
+	}
+
+	if err := am.writeGenesisArray(target, "unbonding_delegations", func(write func(proto.Message) error) error {
+		return am.keeper.UnbondingDelegations.Walk(ctx, nil, func(_ collections.Pair[[]byte, []byte], ubd types.UnbondingDelegation) (bool, error) {
+			return false, write(&ubd)
+		})
+	}); err != nil {
+		return err
+	}
+
+	return am.writeGenesisArray(target, "redelegations", func(write func(proto.Message) error) error {
+		return am.keeper.Redelegations.Walk(ctx, nil, func(_ collections.Triple[[]byte, []byte, []byte], red types.Redelegation) (bool, error) {
+			return false, write(&red)
+		})
+	})
+}
+
+// writeGenesisArray writes the items passed to write by walk to an array field of target.
+func (am AppModule) writeGenesisArray(target appmodule.GenesisTarget, field string, walk func(write func(proto.Message) error) error) error {
+	w, err := module.NewGenesisArrayWriter(target, field)
+	if err != nil {
+		return err
+	}
+
+	if err := walk(func(item proto.Message) error {
+		bz, err := am.cdc.MarshalJSON(item)
+		if err != nil {
+			return err
+		}
+		return w.Write(bz)
+	}); err != nil {
+		_ = w.Close()
+		return err
+	}
+
+	return w.Close()
+}
+
+// ValidateGenesisFromSource validates the genesis state of the staking module streamed from
+// source, one validator at a time.
+func (am AppModule) ValidateGenesisFromSource(source appmodule.GenesisSource) error {
+	gs, err := am.readGenesisFields(source)
+	if err != nil {
+		return err
+	}
+
+	addrMap := make(map[string]bool)
+	var validator types.Validator
+	if err := am.readGenesisArray(source, "validators", &validator, func() error {
+		return validateGenesisValidator(validator, addrMap)
+	}); err != nil {
+		return err
+	}
+
+	return gs.Params.Validate()
+}
+
+// InitGenesisFromSource initializes the staking module from the genesis state streamed from
+// source, one validator, delegation, unbonding delegation and redelegation at a time.
+func (am AppModule) InitGenesisFromSource(ctx context.Context, source appmodule.GenesisSource) ([]appmodule.ValidatorUpdate, error) {
+	gs, err := am.readGenesisFields(source)
+	if err != nil {
+		return nil, err
+	}
+	importer, err := am.keeper.NewGenesisImporter(ctx, gs.Params, gs.LastTotalPower, gs.Exported)
+	if err != nil {
+		return nil, err
+	}
+
+	var validator types.Validator
+	if err := am.readGenesisArray(source, "validators", &validator, func() error {
+		return importer.ImportValidator(validator)
+	}); err != nil {
+		return nil, err
+	}
+
+	var delegation types.Delegation
+	if err := am.readGenesisArray(source, "delegations", &delegation, func() error {
+		return importer.ImportDelegation(delegation)
+	}); err != nil {
+		return nil, err
+	}
+
+	var ubd types.UnbondingDelegation
+	if err := am.readGenesisArray(source, "unbonding_delegations", &ubd, func() error {
+		return importer.ImportUnbondingDelegation(ubd)
+	}); err != nil {
+		return nil, err
+	}
+
+	var red types.Redelegation
+	if err := am.readGenesisArray(source, "redelegations", &red, func() error {
+		return importer.ImportRedelegation(red)
+	}); err != nil {
+		return nil, err
+	}
+
+	// the last validator powers are bounded by the max validators
+	var (
+		lastValidatorPowers []types.LastValidatorPower
+		lv                  types.LastValidatorPower
+	)
+	if err := am.readGenesisArray(source, "last_validator_powers", &lv, func() error {
+		lastValidatorPowers = append(lastValidatorPowers, lv)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return importer.Finish(lastValidatorPowers)
+}
+
+// readGenesisFields reads the scalar fields of the genesis state streamed from source.
+func (am AppModule) readGenesisFields(source appmodule.GenesisSource) (*types.GenesisState, error) {
+	bz, err := module.ReadGenesisFields(source, "params", "last_total_power", "exported")
+	if err != nil {
+		return nil, err
+	}
+
+	var gs types.GenesisState
+	if err := am.cdc.UnmarshalJSON(bz, &gs); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal %s genesis state: %w", types.ModuleName, err)
+	}
+	return &gs, nil
+}
+
+// readGenesisArray unmarshals the items of an array field of source into item, one at a
+// time, calling fn after each item.
+func (am AppModule) readGenesisArray(source appmodule.GenesisSource, field string, item proto.Message, fn func() error) error {
+	return module.ReadGenesisArray(source, field, func(bz json.RawMessage) error {
+		item.Reset()
+		if err := am.cdc.UnmarshalJSON(bz, item); err != nil {
+			return fmt.Errorf("failed to unmarshal %s %s: %w", types.ModuleName, field, err)
+		}
+		return fn()
+	})
+}
+
 // ConsensusVersion implements HasConsensusVersion
 func (AppModule) ConsensusVersion() uint64 { return consensusVersion }
 
@@ -0,0 +1,204 @@
+package eip712
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	gogoproto "github.com/cosmos/gogoproto/proto"
+	"google.golang.org/protobuf/reflect/protoregistry"
+
+	signingv1beta1 "cosmossdk.io/api/cosmos/tx/signing/v1beta1"
+	"cosmossdk.io/x/tx/decode"
+	"cosmossdk.io/x/tx/signing"
+)
+
+// SignMode is the sign mode implemented by the SignModeHandler. It is not part
+// of the SignMode enum, so the handler must be registered as a custom sign mode.
+const SignMode = signingv1beta1.SignMode(712)
+
+const signModeName = "SIGN_MODE_EIP_712"
+
+const (
+	// PrimaryType is the EIP-712 primary type of a transaction.
+	PrimaryType = "Tx"
+
+	// DefaultDomainName is the default EIP-712 domain name.
+	DefaultDomainName = "Cosmos Tx"
+	// DefaultDomainVersion is the default EIP-712 domain version.
+	DefaultDomainVersion = "1.0.0"
+)
+
+// SignModeHandler implements an EIP-712 typed-data sign mode, allowing Ethereum
+// wallets to sign Cosmos transactions through eth_signTypedData_v4.
+//
+// The transaction is rendered into an EIP-712 Tx struct holding the chain ID,
+// account number, sequence, timeout height, memo, fee and one msgN member per
+// message, whose types are derived from the protobuf message descriptors.
+// The sign bytes are 0x19 0x01 ‖ domainSeparator ‖ hashStruct(Tx), which
+// Ethereum-style secp256k1 keys hash with Keccak-256 before signing (see
+// VerifySignature).
+type SignModeHandler struct {
+	fileResolver  signing.ProtoFileResolver
+	typeResolver  protoregistry.MessageTypeResolver
+	domainName    string
+	domainVersion string
+	eip155ChainID uint64
+}
+
+// SignModeHandlerOptions are the options for the SignModeHandler.
+type SignModeHandlerOptions struct {
+	FileResolver signing.ProtoFileResolver
+	TypeResolver signing.TypeResolver
+
+	// DomainName is the EIP-712 domain name, defaults to DefaultDomainName.
+	DomainName string
+	// DomainVersion is the EIP-712 domain version, defaults to DefaultDomainVersion.
+	DomainVersion string
+	// EIP155ChainID is the EIP-155 chain ID set in the EIP-712 domain. Wallets
+	// require it to match the chain ID of their active network. If zero, the
+	// domain has no chainId.
+	EIP155ChainID uint64
+}
+
+// NewSignModeHandler returns a new SignModeHandler.
+func NewSignModeHandler(options SignModeHandlerOptions) *SignModeHandler {
+	h := &SignModeHandler{
+		domainName:    options.DomainName,
+		domainVersion: options.DomainVersion,
+		eip155ChainID: options.EIP155ChainID,
+	}
+	if options.FileResolver == nil {
+		h.fileResolver = gogoproto.HybridResolver
+	} else {
+		h.fileResolver = options.FileResolver
+	}
+	if options.TypeResolver == nil {
+		h.typeResolver = protoregistry.GlobalTypes
+	} else {
+		h.typeResolver = options.TypeResolver
+	}
+	if h.domainName == "" {
+		h.domainName = DefaultDomainName
+	}
+	if h.domainVersion == "" {
+		h.domainVersion = DefaultDomainVersion
+	}
+	return h
+}
+
+// Mode implements the Mode method of the SignModeHandler interface.
+func (h SignModeHandler) Mode() signingv1beta1.SignMode {
+	return SignMode
+}
+
+// GetSignBytes implements the GetSignBytes method of the SignModeHandler interface.
+func (h SignModeHandler) GetSignBytes(ctx context.Context, signerData signing.SignerData, txData signing.TxData) ([]byte, error) {
+	typedData, err := h.GetTypedData(ctx, signerData, txData)
+	if err != nil {
+		return nil, err
+	}
+
+	return typedData.SignBytes()
+}
+
+// GetTypedData returns the EIP-712 typed data of the transaction, which is the
+// payload given to wallets to sign.
+func (h SignModeHandler) GetTypedData(_ context.Context, signerData signing.SignerData, txData signing.TxData) (*TypedData, error) {
+	body := txData.Body
+	_, err := decode.RejectUnknownFields(
+		txData.BodyBytes, body.ProtoReflect().Descriptor(), false, h.fileResolver)
+	if err != nil {
+		return nil, err
+	}
+
+	if (len(body.ExtensionOptions) > 0) || (len(body.NonCriticalExtensionOptions) > 0) {
+		return nil, fmt.Errorf("%s does not support protobuf extension options: invalid request", signModeName)
+	}
+
+	if signerData.Address == "" {
+		return nil, fmt.Errorf("got empty address in %s handler: invalid request", signModeName)
+	}
+
+	fee := txData.AuthInfo.Fee
+	if fee == nil {
+		return nil, fmt.Errorf("fee cannot be nil in %s handler: invalid request", signModeName)
+	}
+
+	builder := newTypesBuilder(h.fileResolver, h.typeResolver)
+	feeType, feeValue, err := builder.messageValue(fee.ProtoReflect())
+	if err != nil {
+		return nil, err
+	}
+
+	members := []Type{
+		{Name: "chain_id", Type: "string"},
+		{Name: "account_number", Type: "uint64"},
+		{Name: "sequence", Type: "uint64"},
+		{Name: "timeout_height", Type: "uint64"},
+		{Name: "memo", Type: "string"},
+		{Name: "fee", Type: feeType},
+	}
+	message := map[string]any{
+		"chain_id":       signerData.ChainID,
+		"account_number": strconv.FormatUint(signerData.AccountNumber, 10),
+		"sequence":       strconv.FormatUint(signerData.Sequence, 10),
+		"timeout_height": strconv.FormatUint(body.TimeoutHeight, 10),
+		"memo":           body.Memo,
+		"fee":            feeValue,
+	}
+
+	for i, msg := range body.Messages {
+		typ, value, err := builder.messageValue(msg.ProtoReflect())
+		if err != nil {
+			return nil, fmt.Errorf("failed to render message %d: %w", i, err)
+		}
+		name := fmt.Sprintf("msg%d", i)
+		members = append(members, Type{Name: name, Type: typ})
+		message[name] = value
+	}
+
+	types := builder.types
+	if _, ok := types[PrimaryType]; ok {
+		return nil, fmt.Errorf("message type name %s conflicts with the EIP-712 primary type", PrimaryType)
+	}
+	types[PrimaryType] = members
+	types[DomainTypeName] = h.domainType()
+
+	typedData := &TypedData{
+		Types:       types,
+		PrimaryType: PrimaryType,
+		Domain:      h.domain(),
+		Message:     message,
+	}
+
+	if err := typedData.Validate(); err != nil {
+		return nil, err
+	}
+
+	return typedData, nil
+}
+
+func (h SignModeHandler) domainType() []Type {
+	domainType := []Type{
+		{Name: "name", Type: "string"},
+		{Name: "version", Type: "string"},
+	}
+	if h.eip155ChainID != 0 {
+		domainType = append(domainType, Type{Name: "chainId", Type: "uint256"})
+	}
+	return domainType
+}
+
+func (h SignModeHandler) domain() map[string]any {
+	domain := map[string]any{
+		"name":    h.domainName,
+		"version": h.domainVersion,
+	}
+	if h.eip155ChainID != 0 {
+		domain["chainId"] = h.eip155ChainID
+	}
+	return domain
+}
+
+var _ signing.SignModeHandler = (*SignModeHandler)(nil)
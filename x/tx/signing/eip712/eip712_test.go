@@ -0,0 +1,223 @@
+package eip712_test
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/cosmos/cosmos-proto/anyutil"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"gotest.tools/v3/golden"
+
+	authzv1beta1 "cosmossdk.io/api/cosmos/authz/v1beta1"
+	bankv1beta1 "cosmossdk.io/api/cosmos/bank/v1beta1"
+	basev1beta1 "cosmossdk.io/api/cosmos/base/v1beta1"
+	feegrantv1beta1 "cosmossdk.io/api/cosmos/feegrant/v1beta1"
+	govv1 "cosmossdk.io/api/cosmos/gov/v1"
+	txv1beta1 "cosmossdk.io/api/cosmos/tx/v1beta1"
+	"cosmossdk.io/x/tx/signing"
+	"cosmossdk.io/x/tx/signing/eip712"
+)
+
+const (
+	fromAddress = "cosmos1ulav3hsenupswqfkw2y3sup5kgtqwnvqa8eyhs"
+	toAddress   = "cosmos1ghekyjucln7y67ntx7cf27m9dpuxxemn4c8g4r"
+)
+
+func msgSend() *bankv1beta1.MsgSend {
+	return &bankv1beta1.MsgSend{
+		FromAddress: fromAddress,
+		ToAddress:   toAddress,
+		Amount:      []*basev1beta1.Coin{{Denom: "uatom", Amount: "1000"}},
+	}
+}
+
+func newAny(t *testing.T, msg proto.Message) *anypb.Any {
+	t.Helper()
+	anyMsg, err := anyutil.New(msg)
+	require.NoError(t, err)
+	return anyMsg
+}
+
+func makeTxData(t *testing.T, fee *txv1beta1.Fee, msgs ...proto.Message) signing.TxData {
+	t.Helper()
+	body := &txv1beta1.TxBody{
+		Memo:          "sometestmemo",
+		TimeoutHeight: 100,
+	}
+	for _, msg := range msgs {
+		body.Messages = append(body.Messages, newAny(t, msg))
+	}
+	authInfo := &txv1beta1.AuthInfo{Fee: fee}
+
+	marshalOpts := proto.MarshalOptions{Deterministic: true}
+	bodyBz, err := marshalOpts.Marshal(body)
+	require.NoError(t, err)
+	authInfoBz, err := marshalOpts.Marshal(authInfo)
+	require.NoError(t, err)
+
+	return signing.TxData{
+		Body:          body,
+		AuthInfo:      authInfo,
+		BodyBytes:     bodyBz,
+		AuthInfoBytes: authInfoBz,
+	}
+}
+
+type goldenTypedData struct {
+	TypedData     *eip712.TypedData `json:"typed_data"`
+	SignBytesHash string            `json:"sign_bytes_hash"`
+}
+
+func TestSignModeHandler(t *testing.T) {
+	fee := &txv1beta1.Fee{
+		Amount:   []*basev1beta1.Coin{{Denom: "uatom", Amount: "150"}},
+		GasLimit: 200000,
+	}
+	signerData := signing.SignerData{
+		Address:       fromAddress,
+		ChainID:       "cosmoshub-4",
+		AccountNumber: 1,
+		Sequence:      2,
+	}
+
+	testCases := []struct {
+		name        string
+		golden      string
+		nilFee      bool
+		emptySigner bool
+		msgs        []proto.Message
+		error       string
+	}{
+		{
+			name:   "msg send",
+			golden: "msg_send.json",
+			msgs:   []proto.Message{msgSend()},
+		},
+		{
+			name:   "multiple messages",
+			golden: "multiple_msgs.json",
+			msgs: []proto.Message{
+				msgSend(),
+				&govv1.MsgVote{ProposalId: 7, Voter: fromAddress, Option: govv1.VoteOption_VOTE_OPTION_YES, Metadata: "ipfs://metadata"},
+			},
+		},
+		{
+			name:   "nested any",
+			golden: "authz_exec.json",
+			msgs: []proto.Message{
+				&authzv1beta1.MsgExec{Grantee: toAddress, Msgs: []*anypb.Any{newAny(t, msgSend()), newAny(t, msgSend())}},
+			},
+		},
+		{
+			name:   "timestamp and empty list",
+			golden: "feegrant.json",
+			msgs: []proto.Message{
+				&feegrantv1beta1.MsgGrantAllowance{
+					Granter: fromAddress,
+					Grantee: toAddress,
+					Allowance: newAny(t, &feegrantv1beta1.BasicAllowance{
+						Expiration: timestamppb.New(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)),
+					}),
+				},
+			},
+		},
+		{
+			name:   "unset messages",
+			golden: "unset_msgs.json",
+			msgs: []proto.Message{
+				&feegrantv1beta1.MsgGrantAllowance{Granter: fromAddress, Grantee: toAddress},
+			},
+		},
+		{
+			name: "any fields of different types",
+			msgs: []proto.Message{
+				&feegrantv1beta1.MsgGrantAllowance{Allowance: newAny(t, &feegrantv1beta1.BasicAllowance{})},
+				&feegrantv1beta1.MsgGrantAllowance{Allowance: newAny(t, &feegrantv1beta1.PeriodicAllowance{})},
+			},
+			error: "cannot be represented as a single EIP-712 type",
+		},
+		{
+			name:  "heterogeneous any list",
+			msgs:  []proto.Message{&authzv1beta1.MsgExec{Grantee: toAddress, Msgs: []*anypb.Any{newAny(t, msgSend()), newAny(t, &govv1.MsgVote{})}}},
+			error: "cannot be represented as a single EIP-712 array",
+		},
+		{
+			name:        "empty signer",
+			emptySigner: true,
+			msgs:        []proto.Message{msgSend()},
+			error:       "got empty address in SIGN_MODE_EIP_712 handler",
+		},
+		{
+			name:   "nil fee",
+			nilFee: true,
+			msgs:   []proto.Message{msgSend()},
+			error:  "fee cannot be nil",
+		},
+	}
+
+	handler := eip712.NewSignModeHandler(eip712.SignModeHandlerOptions{EIP155ChainID: 9000})
+	require.Equal(t, eip712.SignMode, handler.Mode())
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			txFee := fee
+			if tc.nilFee {
+				txFee = nil
+			}
+			txData := makeTxData(t, txFee, tc.msgs...)
+			sd := signerData
+			if tc.emptySigner {
+				sd.Address = ""
+			}
+
+			typedData, err := handler.GetTypedData(context.Background(), sd, txData)
+			if tc.error != "" {
+				require.ErrorContains(t, err, tc.error)
+				return
+			}
+			require.NoError(t, err)
+
+			signBytes, err := handler.GetSignBytes(context.Background(), sd, txData)
+			require.NoError(t, err)
+			require.Equal(t, []byte{0x19, 0x01}, signBytes[:2])
+			require.Len(t, signBytes, 66)
+
+			bz, err := json.MarshalIndent(goldenTypedData{
+				TypedData:     typedData,
+				SignBytesHash: hex.EncodeToString(eip712.Keccak256(signBytes)),
+			}, "", "  ")
+			require.NoError(t, err)
+			golden.Assert(t, string(bz), tc.golden)
+
+			// wallets compute the same sign bytes from the JSON typed data
+			var fromJSON eip712.TypedData
+			require.NoError(t, json.Unmarshal(bz, &goldenTypedData{TypedData: &fromJSON}))
+			jsonSignBytes, err := fromJSON.SignBytes()
+			require.NoError(t, err)
+			require.Equal(t, signBytes, jsonSignBytes)
+		})
+	}
+}
+
+func TestDeterministicTypes(t *testing.T) {
+	handler := eip712.NewSignModeHandler(eip712.SignModeHandlerOptions{})
+	signerData := signing.SignerData{Address: fromAddress, ChainID: "test-chain"}
+	fee := &txv1beta1.Fee{GasLimit: 1}
+
+	// the types of a message do not depend on which fields are set
+	full, err := handler.GetTypedData(context.Background(), signerData, makeTxData(t, fee, msgSend()))
+	require.NoError(t, err)
+	empty, err := handler.GetTypedData(context.Background(), signerData, makeTxData(t, fee, &bankv1beta1.MsgSend{}))
+	require.NoError(t, err)
+	require.Equal(t, full.Types, empty.Types)
+
+	// no chainId in the domain without an EIP-155 chain ID
+	require.NotContains(t, full.Domain, "chainId")
+	require.Equal(t, eip712.DefaultDomainName, full.Domain["name"])
+}
@@ -0,0 +1,417 @@
+package eip712
+
+import (
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"cosmossdk.io/x/tx/signing"
+)
+
+const (
+	anyFullName       = "google.protobuf.Any"
+	timestampFullName = "google.protobuf.Timestamp"
+	durationFullName  = "google.protobuf.Duration"
+
+	anyTypeURLFieldName = "type_url"
+	anyValueFieldName   = "value"
+)
+
+// typesBuilder derives EIP-712 struct types from protobuf message descriptors
+// while rendering message values.
+//
+// Struct type names are the message full names with dots replaced by underscores,
+// members are the message fields in declaration order, named after the proto
+// field names. As the concrete type of an Any field is only known from its
+// value, messages with Any fields get the members of the first value rendered
+// and later values must agree.
+type typesBuilder struct {
+	fileResolver signing.ProtoFileResolver
+	typeResolver protoregistry.MessageTypeResolver
+
+	types     Types
+	fullNames map[string]protoreflect.FullName
+}
+
+func newTypesBuilder(fileResolver signing.ProtoFileResolver, typeResolver protoregistry.MessageTypeResolver) *typesBuilder {
+	return &typesBuilder{
+		fileResolver: fileResolver,
+		typeResolver: typeResolver,
+		types:        Types{},
+		fullNames:    map[string]protoreflect.FullName{},
+	}
+}
+
+// typeName returns the EIP-712 struct type name of the given message descriptor.
+func typeName(desc protoreflect.MessageDescriptor) string {
+	return strings.ReplaceAll(string(desc.FullName()), ".", "_")
+}
+
+// defineType registers the members of an EIP-712 struct type, checking that
+// they don't conflict with a previous definition.
+func (b *typesBuilder) defineType(desc protoreflect.MessageDescriptor, members []Type) error {
+	name := typeName(desc)
+	if fullName, ok := b.fullNames[name]; ok && fullName != desc.FullName() {
+		return fmt.Errorf("EIP-712 type name %s is ambiguous between %s and %s", name, fullName, desc.FullName())
+	}
+	b.fullNames[name] = desc.FullName()
+
+	if existing, ok := b.types[name]; ok && existing != nil && !equalMembers(existing, members) {
+		return fmt.Errorf("%s has Any fields of different concrete types which cannot be represented as a single EIP-712 type", desc.FullName())
+	}
+	b.types[name] = members
+
+	return nil
+}
+
+func equalMembers(a, b []Type) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// messageValue renders a message, returning its EIP-712 type and value.
+// Unset messages are rendered as nil.
+func (b *typesBuilder) messageValue(msg protoreflect.Message) (string, any, error) {
+	desc := msg.Descriptor()
+	switch desc.FullName() {
+	case timestampFullName:
+		return "string", formatTimestamp(msg), nil
+	case durationFullName:
+		return "string", formatDuration(msg), nil
+	case anyFullName:
+		if msg.IsValid() {
+			return b.anyValue(msg)
+		}
+	}
+
+	if !msg.IsValid() {
+		typ, err := b.descriptorType(desc)
+		return typ, nil, err
+	}
+
+	name := typeName(desc)
+	if _, ok := b.types[name]; !ok {
+		// register the type name first, so that recursive messages terminate
+		b.types[name] = nil
+	}
+
+	fields := desc.Fields()
+	members := make([]Type, 0, fields.Len())
+	value := make(map[string]any, fields.Len())
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		typ, v, err := b.fieldValue(fd, msg.Get(fd))
+		if err != nil {
+			return "", nil, errors.Wrapf(err, "%s", fd.FullName())
+		}
+		members = append(members, Type{Name: string(fd.Name()), Type: typ})
+		value[string(fd.Name())] = v
+	}
+
+	if err := b.defineType(desc, members); err != nil {
+		return "", nil, err
+	}
+
+	return name, value, nil
+}
+
+// descriptorType derives the EIP-712 type of a message from its descriptor only,
+// which is used for unset messages.
+func (b *typesBuilder) descriptorType(desc protoreflect.MessageDescriptor) (string, error) {
+	switch desc.FullName() {
+	case timestampFullName, durationFullName:
+		return "string", nil
+	}
+
+	name := typeName(desc)
+	if _, ok := b.types[name]; ok {
+		return name, nil
+	}
+	b.types[name] = nil
+
+	fields := desc.Fields()
+	members := make([]Type, 0, fields.Len())
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		typ, err := b.fieldDescriptorType(fd)
+		if err != nil {
+			return "", err
+		}
+		members = append(members, Type{Name: string(fd.Name()), Type: typ})
+	}
+
+	return name, b.defineType(desc, members)
+}
+
+func (b *typesBuilder) fieldDescriptorType(fd protoreflect.FieldDescriptor) (string, error) {
+	var (
+		typ string
+		err error
+	)
+	switch {
+	case fd.IsMap():
+		typ, err = b.descriptorType(fd.Message())
+	case fd.Kind() == protoreflect.MessageKind || fd.Kind() == protoreflect.GroupKind:
+		typ, err = b.descriptorType(fd.Message())
+	default:
+		typ, err = scalarType(fd)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if fd.IsList() || fd.IsMap() {
+		typ += "[]"
+	}
+
+	return typ, nil
+}
+
+func (b *typesBuilder) fieldValue(fd protoreflect.FieldDescriptor, v protoreflect.Value) (string, any, error) {
+	switch {
+	case fd.IsMap():
+		return b.mapValue(fd, v.Map())
+	case fd.IsList():
+		return b.listValue(fd, v.List())
+	case fd.Kind() == protoreflect.MessageKind || fd.Kind() == protoreflect.GroupKind:
+		return b.messageValue(v.Message())
+	}
+
+	typ, err := scalarType(fd)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return typ, scalarValue(fd, v), nil
+}
+
+func (b *typesBuilder) listValue(fd protoreflect.FieldDescriptor, list protoreflect.List) (string, any, error) {
+	values := make([]any, 0, list.Len())
+	if fd.Kind() != protoreflect.MessageKind && fd.Kind() != protoreflect.GroupKind {
+		typ, err := scalarType(fd)
+		if err != nil {
+			return "", nil, err
+		}
+		for i := 0; i < list.Len(); i++ {
+			values = append(values, scalarValue(fd, list.Get(i)))
+		}
+		return typ + "[]", values, nil
+	}
+
+	if list.Len() == 0 {
+		typ, err := b.descriptorType(fd.Message())
+		return typ + "[]", values, err
+	}
+
+	var elemType string
+	for i := 0; i < list.Len(); i++ {
+		typ, v, err := b.messageValue(list.Get(i).Message())
+		if err != nil {
+			return "", nil, err
+		}
+		if elemType != "" && typ != elemType {
+			return "", nil, fmt.Errorf("list elements of types %s and %s cannot be represented as a single EIP-712 array", elemType, typ)
+		}
+		elemType = typ
+		values = append(values, v)
+	}
+
+	return elemType + "[]", values, nil
+}
+
+// mapValue renders a map as the list of its entries sorted by key.
+func (b *typesBuilder) mapValue(fd protoreflect.FieldDescriptor, m protoreflect.Map) (string, any, error) {
+	entryDesc := fd.Message()
+	keyFd, valueFd := fd.MapKey(), fd.MapValue()
+
+	keys := make([]protoreflect.MapKey, 0, m.Len())
+	m.Range(func(key protoreflect.MapKey, _ protoreflect.Value) bool {
+		keys = append(keys, key)
+		return true
+	})
+	sort.Slice(keys, func(i, j int) bool { return lessMapKey(keys[i], keys[j]) })
+
+	keyType, err := scalarType(keyFd)
+	if err != nil {
+		return "", nil, err
+	}
+
+	valueType, err := b.fieldDescriptorType(valueFd)
+	if err != nil {
+		return "", nil, err
+	}
+
+	entries := make([]any, 0, len(keys))
+	for _, key := range keys {
+		typ, v, err := b.fieldValue(valueFd, m.Get(key))
+		if err != nil {
+			return "", nil, err
+		}
+		valueType = typ
+		entries = append(entries, map[string]any{
+			string(keyFd.Name()):   scalarValue(keyFd, key.Value()),
+			string(valueFd.Name()): v,
+		})
+	}
+
+	err = b.defineType(entryDesc, []Type{
+		{Name: string(keyFd.Name()), Type: keyType},
+		{Name: string(valueFd.Name()), Type: valueType},
+	})
+	if err != nil {
+		return "", nil, err
+	}
+
+	return typeName(entryDesc) + "[]", entries, nil
+}
+
+func lessMapKey(a, b protoreflect.MapKey) bool {
+	switch v := a.Interface().(type) {
+	case string:
+		return v < b.String()
+	case bool:
+		return !v && b.Bool()
+	case int32, int64:
+		return a.Int() < b.Int()
+	case uint32, uint64:
+		return a.Uint() < b.Uint()
+	}
+	return a.String() < b.String()
+}
+
+// anyValue renders an Any as its concrete message, the concrete type being
+// committed to by its EIP-712 type name.
+func (b *typesBuilder) anyValue(msg protoreflect.Message) (string, any, error) {
+	fields := msg.Descriptor().Fields()
+	typeURL := msg.Get(fields.ByName(anyTypeURLFieldName)).String()
+	value := msg.Get(fields.ByName(anyValueFieldName)).Bytes()
+
+	concrete, err := b.unpackAny(typeURL, value)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return b.messageValue(concrete)
+}
+
+func (b *typesBuilder) unpackAny(typeURL string, value []byte) (protoreflect.Message, error) {
+	typ, err := b.typeResolver.FindMessageByURL(typeURL)
+	if err == nil {
+		msg := typ.New()
+		if err := proto.Unmarshal(value, msg.Interface()); err != nil {
+			return nil, err
+		}
+		return msg, nil
+	}
+
+	// otherwise we use the dynamicpb API to unmarshal into a dynamic message.
+	name := typeURL
+	if i := strings.LastIndexByte(typeURL, '/'); i >= 0 {
+		name = typeURL[i+1:]
+	}
+	desc, err := b.fileResolver.FindDescriptorByName(protoreflect.FullName(name))
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't resolve type URL %s", typeURL)
+	}
+	msgDesc, ok := desc.(protoreflect.MessageDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("type URL %s does not refer to a message", typeURL)
+	}
+
+	msg := dynamicpb.NewMessage(msgDesc)
+	if err := proto.Unmarshal(value, msg); err != nil {
+		return nil, err
+	}
+
+	return msg, nil
+}
+
+// scalarType returns the EIP-712 type of a non message field.
+// 64 bits integers are rendered as decimal strings, floating point numbers as
+// strings and enums as the name of their value.
+func scalarType(fd protoreflect.FieldDescriptor) (string, error) {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		return "bool", nil
+	case protoreflect.StringKind, protoreflect.EnumKind, protoreflect.FloatKind, protoreflect.DoubleKind:
+		return "string", nil
+	case protoreflect.BytesKind:
+		return "bytes", nil
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		return "int32", nil
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return "int64", nil
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return "uint32", nil
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return "uint64", nil
+	}
+
+	return "", fmt.Errorf("unsupported field kind %s", fd.Kind())
+}
+
+func scalarValue(fd protoreflect.FieldDescriptor, v protoreflect.Value) any {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		return v.Bool()
+	case protoreflect.StringKind:
+		return v.String()
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByNumber(v.Enum()); ev != nil {
+			return string(ev.Name())
+		}
+		return strconv.Itoa(int(v.Enum()))
+	case protoreflect.FloatKind:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 32)
+	case protoreflect.DoubleKind:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 64)
+	case protoreflect.BytesKind:
+		return "0x" + hex.EncodeToString(v.Bytes())
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		return v.Int()
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return strconv.FormatInt(v.Int(), 10)
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return v.Uint()
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return strconv.FormatUint(v.Uint(), 10)
+	}
+
+	return nil
+}
+
+func formatTimestamp(msg protoreflect.Message) string {
+	if !msg.IsValid() {
+		return ""
+	}
+	fields := msg.Descriptor().Fields()
+	seconds := msg.Get(fields.ByName("seconds")).Int()
+	nanos := msg.Get(fields.ByName("nanos")).Int()
+	return time.Unix(seconds, nanos).UTC().Format(time.RFC3339Nano)
+}
+
+func formatDuration(msg protoreflect.Message) string {
+	if !msg.IsValid() {
+		return ""
+	}
+	fields := msg.Descriptor().Fields()
+	seconds := msg.Get(fields.ByName("seconds")).Int()
+	nanos := msg.Get(fields.ByName("nanos")).Int()
+	return (time.Duration(seconds)*time.Second + time.Duration(nanos)).String()
+}
@@ -0,0 +1,101 @@
+package eip712
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+)
+
+// signatureLength is the length of an Ethereum signature, r ‖ s ‖ v.
+const signatureLength = 65
+
+// VerifySignature verifies an Ethereum-style secp256k1 signature of the given
+// sign bytes, as produced by eth_secp256k1 keys: the sign bytes are hashed with
+// Keccak-256 and the signature is r ‖ s ‖ v, v being the recovery id 0 or 1.
+// A signature has a single valid encoding: signatures without recovery id, with
+// the 27 or 28 recovery id returned by wallets, with a recovery id not matching
+// the public key or with a high S are rejected.
+// The public key can be either compressed or uncompressed.
+func VerifySignature(pubKey, signBytes, sig []byte) bool {
+	pk, err := secp256k1.ParsePubKey(pubKey)
+	if err != nil {
+		return false
+	}
+
+	recovered, err := recoverPubKey(signBytes, sig)
+	if err != nil {
+		return false
+	}
+
+	return recovered.IsEqual(pk)
+}
+
+// RecoverPubKey recovers the compressed public key which produced the given
+// r ‖ s ‖ v signature of the sign bytes. Ethereum wallets do not expose public
+// keys, so this is how a client gets the public key to set in the transaction
+// signer infos. The recovery id 27 or 28 returned by wallets must be converted
+// to 0 or 1 first.
+func RecoverPubKey(signBytes, sig []byte) ([]byte, error) {
+	pk, err := recoverPubKey(signBytes, sig)
+	if err != nil {
+		return nil, err
+	}
+
+	return pk.SerializeCompressed(), nil
+}
+
+func recoverPubKey(signBytes, sig []byte) (*secp256k1.PublicKey, error) {
+	r, s, v, err := parseSignature(sig)
+	if err != nil {
+		return nil, err
+	}
+
+	// decred compact signatures are v ‖ r ‖ s with v = 27 + recovery id + 4 for compressed keys.
+	compact := make([]byte, 0, signatureLength)
+	compact = append(compact, 27+v+4)
+	rBytes, sBytes := r.Bytes(), s.Bytes()
+	compact = append(compact, rBytes[:]...)
+	compact = append(compact, sBytes[:]...)
+
+	pk, _, err := ecdsa.RecoverCompact(compact, Keccak256(signBytes))
+	return pk, err
+}
+
+// PubKeyToAddress returns the Ethereum address of a secp256k1 public key, that
+// is the last 20 bytes of the Keccak-256 hash of the uncompressed public key.
+func PubKeyToAddress(pubKey []byte) ([]byte, error) {
+	pk, err := secp256k1.ParsePubKey(pubKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// strip the 0x04 prefix of the uncompressed public key
+	return Keccak256(pk.SerializeUncompressed()[1:])[12:], nil
+}
+
+// parseSignature parses a canonical r ‖ s ‖ v signature.
+func parseSignature(sig []byte) (r, s *secp256k1.ModNScalar, v byte, err error) {
+	if len(sig) != signatureLength {
+		return nil, nil, 0, fmt.Errorf("invalid signature length %d, expected %d", len(sig), signatureLength)
+	}
+	v = sig[signatureLength-1]
+	if v > 1 {
+		return nil, nil, 0, fmt.Errorf("invalid signature recovery id %d, expected 0 or 1", v)
+	}
+
+	r, s = new(secp256k1.ModNScalar), new(secp256k1.ModNScalar)
+	if overflow := r.SetByteSlice(sig[:32]); overflow || r.IsZero() {
+		return nil, nil, 0, errors.New("invalid signature r value")
+	}
+	if overflow := s.SetByteSlice(sig[32:64]); overflow || s.IsZero() {
+		return nil, nil, 0, errors.New("invalid signature s value")
+	}
+	// reject malleable signatures
+	if s.IsOverHalfOrder() {
+		return nil, nil, 0, errors.New("signature s value is over half order")
+	}
+
+	return r, s, v, nil
+}
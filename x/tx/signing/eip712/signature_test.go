@@ -0,0 +1,69 @@
+package eip712_test
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+	"github.com/stretchr/testify/require"
+
+	"cosmossdk.io/x/tx/signing/eip712"
+)
+
+// ethSign signs the Keccak-256 hash of the sign bytes and returns an Ethereum
+// r ‖ s ‖ v signature, v being the recovery id 0 or 1.
+func ethSign(t *testing.T, privKey *secp256k1.PrivateKey, signBytes []byte) []byte {
+	t.Helper()
+	compact := ecdsa.SignCompact(privKey, eip712.Keccak256(signBytes), false)
+	return append(compact[1:], compact[0]-27) // v ‖ r ‖ s -> r ‖ s ‖ v
+}
+
+func TestVerifySignature(t *testing.T) {
+	privKey, err := secp256k1.GeneratePrivateKey()
+	require.NoError(t, err)
+	pubKey := privKey.PubKey().SerializeCompressed()
+
+	signBytes := []byte("\x19\x01sign bytes")
+	sig := ethSign(t, privKey, signBytes)
+
+	require.True(t, eip712.VerifySignature(pubKey, signBytes, sig))
+	require.True(t, eip712.VerifySignature(privKey.PubKey().SerializeUncompressed(), signBytes, sig))
+
+	require.False(t, eip712.VerifySignature(pubKey, []byte("other sign bytes"), sig))
+	require.False(t, eip712.VerifySignature(pubKey, signBytes, sig[:63]))
+
+	// the other encodings of the signature are rejected
+	withRecoveryID := func(v byte) []byte { return append(sig[:64:64], v) }
+	require.False(t, eip712.VerifySignature(pubKey, signBytes, sig[:64]), "without recovery id")
+	require.False(t, eip712.VerifySignature(pubKey, signBytes, withRecoveryID(sig[64]+27)), "wallet recovery id")
+	require.False(t, eip712.VerifySignature(pubKey, signBytes, withRecoveryID(sig[64]^1)), "other recovery id")
+	require.False(t, eip712.VerifySignature(pubKey, signBytes, withRecoveryID(2)))
+	require.False(t, eip712.VerifySignature(pubKey, signBytes, append(sig[:65:65], 0)), "trailing byte")
+
+	// malleated high S signatures are rejected
+	var s secp256k1.ModNScalar
+	s.SetByteSlice(sig[32:64])
+	s.Negate()
+	sBytes := s.Bytes()
+	highS := append(append(sig[:32:32], sBytes[:]...), sig[64]^1)
+	require.False(t, eip712.VerifySignature(pubKey, signBytes, highS))
+
+	recovered, err := eip712.RecoverPubKey(signBytes, sig)
+	require.NoError(t, err)
+	require.Equal(t, pubKey, recovered)
+
+	_, err = eip712.RecoverPubKey(signBytes, sig[:64])
+	require.Error(t, err)
+	_, err = eip712.RecoverPubKey(signBytes, withRecoveryID(sig[64]+27))
+	require.Error(t, err)
+}
+
+func TestPubKeyToAddress(t *testing.T) {
+	// the private key of the EIP-712 specification example is keccak256("cow")
+	privKey := secp256k1.PrivKeyFromBytes(eip712.Keccak256([]byte("cow")))
+
+	address, err := eip712.PubKeyToAddress(privKey.PubKey().SerializeCompressed())
+	require.NoError(t, err)
+	require.Equal(t, "cd2a3d9f938e13cd947ec05abc7fe734df8dd826", hex.EncodeToString(address))
+}
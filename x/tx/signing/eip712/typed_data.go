@@ -0,0 +1,408 @@
+package eip712
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// DomainTypeName is the name of the EIP-712 domain type.
+const DomainTypeName = "EIP712Domain"
+
+// Type is a member of an EIP-712 struct type.
+type Type struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// Types maps EIP-712 struct type names to their members.
+type Types map[string][]Type
+
+// TypedData is the EIP-712 typed structured data of a transaction, as expected
+// by wallets implementing eth_signTypedData_v4 (e.g. MetaMask).
+type TypedData struct {
+	Types       Types          `json:"types"`
+	PrimaryType string         `json:"primaryType"`
+	Domain      map[string]any `json:"domain"`
+	Message     map[string]any `json:"message"`
+}
+
+// SignBytes returns the EIP-712 encoding of the typed data, that is
+// 0x19 0x01 ‖ domainSeparator ‖ hashStruct(message).
+// Its Keccak-256 hash is the digest signed by wallets.
+func (td *TypedData) SignBytes() ([]byte, error) {
+	domainSeparator, err := td.HashStruct(DomainTypeName, td.Domain)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash domain: %w", err)
+	}
+
+	messageHash, err := td.HashStruct(td.PrimaryType, td.Message)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash message: %w", err)
+	}
+
+	signBytes := make([]byte, 0, 2+len(domainSeparator)+len(messageHash))
+	signBytes = append(signBytes, 0x19, 0x01)
+	signBytes = append(signBytes, domainSeparator...)
+	return append(signBytes, messageHash...), nil
+}
+
+// Hash returns the EIP-712 digest of the typed data.
+func (td *TypedData) Hash() ([]byte, error) {
+	signBytes, err := td.SignBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	return Keccak256(signBytes), nil
+}
+
+// HashStruct returns hashStruct(s) = keccak256(typeHash ‖ encodeData(s)) for the
+// given struct type.
+func (td *TypedData) HashStruct(typeName string, data map[string]any) ([]byte, error) {
+	encoded, err := td.encodeData(typeName, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return Keccak256(encoded), nil
+}
+
+// EncodeType returns the encoding of the given struct type, followed by the
+// encodings of the struct types it references sorted by name.
+func (td *TypedData) EncodeType(typeName string) (string, error) {
+	deps := map[string]bool{}
+	if err := td.dependencies(typeName, deps); err != nil {
+		return "", err
+	}
+	delete(deps, typeName)
+
+	sorted := make([]string, 0, len(deps))
+	for dep := range deps {
+		sorted = append(sorted, dep)
+	}
+	sort.Strings(sorted)
+
+	var buf strings.Builder
+	for _, name := range append([]string{typeName}, sorted...) {
+		buf.WriteString(name)
+		buf.WriteByte('(')
+		for i, member := range td.Types[name] {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			buf.WriteString(member.Type)
+			buf.WriteByte(' ')
+			buf.WriteString(member.Name)
+		}
+		buf.WriteByte(')')
+	}
+
+	return buf.String(), nil
+}
+
+// TypeHash returns the Keccak-256 hash of the encoding of the given struct type.
+func (td *TypedData) TypeHash(typeName string) ([]byte, error) {
+	encodedType, err := td.EncodeType(typeName)
+	if err != nil {
+		return nil, err
+	}
+
+	return Keccak256([]byte(encodedType)), nil
+}
+
+func (td *TypedData) dependencies(typeName string, deps map[string]bool) error {
+	if deps[typeName] {
+		return nil
+	}
+
+	members, ok := td.Types[typeName]
+	if !ok {
+		return fmt.Errorf("unknown type %q", typeName)
+	}
+	deps[typeName] = true
+
+	for _, member := range members {
+		elemType := baseType(member.Type)
+		if _, ok := td.Types[elemType]; ok {
+			if err := td.dependencies(elemType, deps); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (td *TypedData) encodeData(typeName string, data map[string]any) ([]byte, error) {
+	typeHash, err := td.TypeHash(typeName)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := bytes.NewBuffer(typeHash)
+	for _, member := range td.Types[typeName] {
+		encoded, err := td.encodeValue(member.Type, data[member.Name])
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s: %w", typeName, member.Name, err)
+		}
+		buf.Write(encoded)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// encodeValue returns the 32 bytes encoding of a value of the given type.
+func (td *TypedData) encodeValue(typ string, value any) ([]byte, error) {
+	if elemType, ok := arrayElemType(typ); ok {
+		var elems []any
+		switch v := value.(type) {
+		case nil:
+		case []any:
+			elems = v
+		default:
+			return nil, fmt.Errorf("expected an array for type %s, got %T", typ, value)
+		}
+
+		var buf bytes.Buffer
+		for _, elem := range elems {
+			encoded, err := td.encodeValue(elemType, elem)
+			if err != nil {
+				return nil, err
+			}
+			buf.Write(encoded)
+		}
+		return Keccak256(buf.Bytes()), nil
+	}
+
+	if _, ok := td.Types[typ]; ok {
+		if value == nil {
+			return make([]byte, 32), nil
+		}
+		data, ok := value.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("expected an object for type %s, got %T", typ, value)
+		}
+		return td.HashStruct(typ, data)
+	}
+
+	switch {
+	case typ == "string":
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected a string, got %T", value)
+		}
+		return Keccak256([]byte(s)), nil
+
+	case typ == "bytes":
+		bz, err := parseBytes(value)
+		if err != nil {
+			return nil, err
+		}
+		return Keccak256(bz), nil
+
+	case typ == "bool":
+		b, ok := value.(bool)
+		if !ok {
+			return nil, fmt.Errorf("expected a bool, got %T", value)
+		}
+		encoded := make([]byte, 32)
+		if b {
+			encoded[31] = 1
+		}
+		return encoded, nil
+
+	case typ == "address":
+		bz, err := parseBytes(value)
+		if err != nil {
+			return nil, err
+		}
+		if len(bz) != 20 {
+			return nil, fmt.Errorf("expected a 20 bytes address, got %d bytes", len(bz))
+		}
+		return leftPad32(bz), nil
+
+	case strings.HasPrefix(typ, "bytes"):
+		size, err := strconv.Atoi(strings.TrimPrefix(typ, "bytes"))
+		if err != nil || size < 1 || size > 32 {
+			return nil, fmt.Errorf("invalid type %s", typ)
+		}
+		bz, err := parseBytes(value)
+		if err != nil {
+			return nil, err
+		}
+		if len(bz) != size {
+			return nil, fmt.Errorf("expected %d bytes, got %d", size, len(bz))
+		}
+		encoded := make([]byte, 32)
+		copy(encoded, bz)
+		return encoded, nil
+
+	case strings.HasPrefix(typ, "uint"), strings.HasPrefix(typ, "int"):
+		return encodeInteger(typ, value)
+	}
+
+	return nil, fmt.Errorf("unknown type %s", typ)
+}
+
+func encodeInteger(typ string, value any) ([]byte, error) {
+	signed := strings.HasPrefix(typ, "int")
+	bits, err := strconv.Atoi(strings.TrimPrefix(strings.TrimPrefix(typ, "u"), "int"))
+	if err != nil || bits < 8 || bits > 256 || bits%8 != 0 {
+		return nil, fmt.Errorf("invalid type %s", typ)
+	}
+
+	n, err := parseInteger(value)
+	if err != nil {
+		return nil, err
+	}
+
+	if signed {
+		limit := new(big.Int).Lsh(big.NewInt(1), uint(bits-1))
+		if n.Cmp(limit) >= 0 || n.Cmp(new(big.Int).Neg(limit)) < 0 {
+			return nil, fmt.Errorf("%s overflows %s", n, typ)
+		}
+	} else if n.Sign() < 0 || n.BitLen() > bits {
+		return nil, fmt.Errorf("%s overflows %s", n, typ)
+	}
+
+	if n.Sign() < 0 {
+		// two's complement on 256 bits
+		n = new(big.Int).Add(n, new(big.Int).Lsh(big.NewInt(1), 256))
+	}
+
+	return n.FillBytes(make([]byte, 32)), nil
+}
+
+func parseInteger(value any) (*big.Int, error) {
+	switch v := value.(type) {
+	case *big.Int:
+		return v, nil
+	case int64:
+		return big.NewInt(v), nil
+	case int32:
+		return big.NewInt(int64(v)), nil
+	case int:
+		return big.NewInt(int64(v)), nil
+	case uint64:
+		return new(big.Int).SetUint64(v), nil
+	case uint32:
+		return new(big.Int).SetUint64(uint64(v)), nil
+	case float64:
+		n, accuracy := big.NewFloat(v).Int(nil)
+		if accuracy != big.Exact {
+			return nil, fmt.Errorf("expected an integer, got %v", v)
+		}
+		return n, nil
+	case json.Number:
+		return parseInteger(v.String())
+	case string:
+		n, ok := new(big.Int).SetString(v, 0)
+		if !ok {
+			return nil, fmt.Errorf("invalid integer %q", v)
+		}
+		return n, nil
+	}
+
+	return nil, fmt.Errorf("expected an integer, got %T", value)
+}
+
+func parseBytes(value any) ([]byte, error) {
+	switch v := value.(type) {
+	case []byte:
+		return v, nil
+	case string:
+		if !strings.HasPrefix(v, "0x") {
+			return nil, fmt.Errorf("expected a 0x prefixed hex string, got %q", v)
+		}
+		return hex.DecodeString(v[2:])
+	}
+
+	return nil, fmt.Errorf("expected a hex string, got %T", value)
+}
+
+func leftPad32(bz []byte) []byte {
+	encoded := make([]byte, 32)
+	copy(encoded[32-len(bz):], bz)
+	return encoded
+}
+
+// arrayElemType returns the element type of an array type such as T[] or T[n].
+func arrayElemType(typ string) (string, bool) {
+	if !strings.HasSuffix(typ, "]") {
+		return "", false
+	}
+
+	i := strings.LastIndexByte(typ, '[')
+	if i < 0 {
+		return "", false
+	}
+
+	return typ[:i], true
+}
+
+// baseType strips all the array suffixes of a type.
+func baseType(typ string) string {
+	for {
+		elemType, ok := arrayElemType(typ)
+		if !ok {
+			return typ
+		}
+		typ = elemType
+	}
+}
+
+// Keccak256 returns the legacy Keccak-256 hash of the given bytes, as used by Ethereum.
+func Keccak256(bz []byte) []byte {
+	hasher := sha3.NewLegacyKeccak256()
+	hasher.Write(bz)
+	return hasher.Sum(nil)
+}
+
+// Validate checks that the typed data only references defined types.
+func (td *TypedData) Validate() error {
+	if td.PrimaryType == "" || len(td.Types) == 0 {
+		return errors.New("empty typed data")
+	}
+
+	if _, ok := td.Types[DomainTypeName]; !ok {
+		return fmt.Errorf("missing %s type", DomainTypeName)
+	}
+
+	for name, members := range td.Types {
+		for _, member := range members {
+			typ := baseType(member.Type)
+			if _, ok := td.Types[typ]; ok || isAtomicType(typ) {
+				continue
+			}
+			return fmt.Errorf("%s.%s has undefined type %s", name, member.Name, member.Type)
+		}
+	}
+
+	return td.dependencies(td.PrimaryType, map[string]bool{})
+}
+
+func isAtomicType(typ string) bool {
+	switch typ {
+	case "string", "bytes", "bool", "address":
+		return true
+	}
+
+	for _, prefix := range []string{"bytes", "uint", "int"} {
+		if size, ok := strings.CutPrefix(typ, prefix); ok {
+			if _, err := strconv.Atoi(size); err == nil {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,139 @@
+package eip712_test
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"cosmossdk.io/x/tx/signing/eip712"
+)
+
+// mailTypedData is the example of the EIP-712 specification.
+const mailTypedData = `{
+  "types": {
+    "EIP712Domain": [
+      {"name": "name", "type": "string"},
+      {"name": "version", "type": "string"},
+      {"name": "chainId", "type": "uint256"},
+      {"name": "verifyingContract", "type": "address"}
+    ],
+    "Person": [
+      {"name": "name", "type": "string"},
+      {"name": "wallet", "type": "address"}
+    ],
+    "Mail": [
+      {"name": "from", "type": "Person"},
+      {"name": "to", "type": "Person"},
+      {"name": "contents", "type": "string"}
+    ]
+  },
+  "primaryType": "Mail",
+  "domain": {
+    "name": "Ether Mail",
+    "version": "1",
+    "chainId": 1,
+    "verifyingContract": "0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC"
+  },
+  "message": {
+    "from": {"name": "Cow", "wallet": "0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826"},
+    "to": {"name": "Bob", "wallet": "0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB"},
+    "contents": "Hello, Bob!"
+  }
+}`
+
+func TestTypedDataSpecExample(t *testing.T) {
+	var td eip712.TypedData
+	require.NoError(t, json.Unmarshal([]byte(mailTypedData), &td))
+	require.NoError(t, td.Validate())
+
+	encodedType, err := td.EncodeType("Mail")
+	require.NoError(t, err)
+	require.Equal(t, "Mail(Person from,Person to,string contents)Person(string name,address wallet)", encodedType)
+
+	domainSeparator, err := td.HashStruct(eip712.DomainTypeName, td.Domain)
+	require.NoError(t, err)
+	require.Equal(t, "f2cee375fa42b42143804025fc449deafd50cc031ca257e0b194a650a912090f", hex.EncodeToString(domainSeparator))
+
+	messageHash, err := td.HashStruct(td.PrimaryType, td.Message)
+	require.NoError(t, err)
+	require.Equal(t, "c52c0ee5d84264471806290a3f2c4cecfc5490626bf912d01f240d7a274b371e", hex.EncodeToString(messageHash))
+
+	hash, err := td.Hash()
+	require.NoError(t, err)
+	require.Equal(t, "be609aee343fb3c4b28e1df9e632fca64fcfaede20f02e86244efddf30957bd2", hex.EncodeToString(hash))
+}
+
+func TestTypedDataEncodeValues(t *testing.T) {
+	td := eip712.TypedData{
+		Types: eip712.Types{
+			eip712.DomainTypeName: {{Name: "name", Type: "string"}},
+			"Values": {
+				{Name: "neg", Type: "int32"},
+				{Name: "big", Type: "uint64"},
+				{Name: "flag", Type: "bool"},
+				{Name: "data", Type: "bytes"},
+				{Name: "list", Type: "string[]"},
+			},
+		},
+		PrimaryType: "Values",
+		Domain:      map[string]any{"name": "test"},
+		Message: map[string]any{
+			"neg":  int64(-1),
+			"big":  "18446744073709551615",
+			"flag": true,
+			"data": "0x0102",
+			"list": []any{"a", "b"},
+		},
+	}
+	require.NoError(t, td.Validate())
+	_, err := td.SignBytes()
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name  string
+		field string
+		value any
+		error string
+	}{
+		{"int overflow", "neg", int64(1 << 40), "overflows int32"},
+		{"uint overflow", "big", "18446744073709551616", "overflows uint64"},
+		{"negative uint", "big", "-1", "overflows uint64"},
+		{"invalid bytes", "data", "0102", "0x prefixed hex string"},
+		{"invalid bool", "flag", "true", "expected a bool"},
+		{"invalid list", "list", "a", "expected an array"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			message := make(map[string]any, len(td.Message))
+			for k, v := range td.Message {
+				message[k] = v
+			}
+			message[tc.field] = tc.value
+
+			invalid := td
+			invalid.Message = message
+			_, err := invalid.SignBytes()
+			require.ErrorContains(t, err, tc.error)
+		})
+	}
+}
+
+func TestTypedDataValidate(t *testing.T) {
+	td := eip712.TypedData{
+		Types: eip712.Types{
+			eip712.DomainTypeName: {{Name: "name", Type: "string"}},
+			"Tx":                  {{Name: "foo", Type: "Foo"}},
+		},
+		PrimaryType: "Tx",
+	}
+	require.ErrorContains(t, td.Validate(), "undefined type Foo")
+
+	td.Types["Foo"] = []eip712.Type{{Name: "bar", Type: "uint256[]"}}
+	require.NoError(t, td.Validate())
+
+	delete(td.Types, eip712.DomainTypeName)
+	require.ErrorContains(t, td.Validate(), "missing EIP712Domain type")
+}
@@ -0,0 +1,124 @@
+package textual
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// MessageRendererCreator is a function returning a value renderer for a
+// message. The SignModeHandler is given so that the renderer can render
+// nested fields with their own value renderers.
+type MessageRendererCreator func(*SignModeHandler) ValueRenderer
+
+// CustomRenderers are value renderers which a module registers for its own
+// Cosmos scalars and messages, e.g. to render a validator address with its
+// moniker or a governance proposal with its summary.
+//
+// Custom renderers cannot override the renderers defined by the ADR-050 spec.
+type CustomRenderers struct {
+	// Scalars maps Cosmos scalars (the cosmos_proto.scalar field option) to the
+	// value renderer of the string fields annotated with them.
+	Scalars map[string]ValueRendererCreator
+
+	// Messages maps message full names to their value renderer.
+	Messages map[protoreflect.FullName]MessageRendererCreator
+
+	// Examples are messages which are rendered with the custom renderers when
+	// creating the SignModeHandler, and which must be parsed back to the same
+	// messages (see SignModeHandler.ValidateRoundTrip). They should cover all
+	// the scalars and messages above.
+	Examples []proto.Message
+}
+
+// IsManyPerContainerType indicates that this is a depinject.ManyPerContainerType.
+func (CustomRenderers) IsManyPerContainerType() {}
+
+// registerCustomRenderers registers the given custom renderers, after the
+// built-in ones, and validates their examples.
+func (r *SignModeHandler) registerCustomRenderers(customRenderers []CustomRenderers) error {
+	builtinScalars := make(map[string]bool, len(r.scalars))
+	for scalar := range r.scalars {
+		builtinScalars[scalar] = true
+	}
+	builtinMessages := make(map[protoreflect.FullName]bool, len(r.messages))
+	for name := range r.messages {
+		builtinMessages[name] = true
+	}
+
+	for _, custom := range customRenderers {
+		for scalar, vr := range custom.Scalars {
+			switch {
+			case scalar == "" || vr == nil:
+				return errors.New("custom scalar renderers must have a scalar name and a renderer")
+			case builtinScalars[scalar]:
+				return fmt.Errorf("cannot override the value renderer of the %s scalar", scalar)
+			case r.scalars[scalar] != nil:
+				return fmt.Errorf("value renderer of the %s scalar registered twice", scalar)
+			}
+			r.scalars[scalar] = vr
+		}
+
+		for name, vr := range custom.Messages {
+			switch {
+			case name == "" || vr == nil:
+				return errors.New("custom message renderers must have a message name and a renderer")
+			case builtinMessages[name]:
+				return fmt.Errorf("cannot override the value renderer of %s", name)
+			case r.messages[name] != nil:
+				return fmt.Errorf("value renderer of %s registered twice", name)
+			}
+			r.messages[name] = vr(r)
+		}
+	}
+
+	for _, custom := range customRenderers {
+		for _, example := range custom.Examples {
+			if err := r.ValidateRoundTrip(context.Background(), example); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// ValidateRoundTrip checks that the given message is parsed back to itself
+// after being formatted with its value renderer.
+func (r *SignModeHandler) ValidateRoundTrip(ctx context.Context, msg proto.Message) error {
+	name := msg.ProtoReflect().Descriptor().FullName()
+	vr, err := r.GetMessageValueRenderer(msg.ProtoReflect().Descriptor())
+	if err != nil {
+		return err
+	}
+
+	screens, err := vr.Format(ctx, protoreflect.ValueOfMessage(msg.ProtoReflect()))
+	if err != nil {
+		return fmt.Errorf("failed to format %s: %w", name, err)
+	}
+
+	parsed, err := vr.Parse(ctx, screens)
+	if err != nil {
+		return fmt.Errorf("failed to parse the screens of %s: %w", name, err)
+	}
+
+	// the parsed message may be a dynamicpb message, so compare their encodings
+	marshalOpts := proto.MarshalOptions{Deterministic: true}
+	want, err := marshalOpts.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	got, err := marshalOpts.Marshal(parsed.Message().Interface())
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(want, got) {
+		return fmt.Errorf("%s does not round trip: parsing its screens returns %v", name, parsed.Message().Interface())
+	}
+
+	return nil
+}
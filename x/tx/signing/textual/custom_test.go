@@ -0,0 +1,205 @@
+package textual_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	basev1beta1 "cosmossdk.io/api/cosmos/base/v1beta1"
+	govv1 "cosmossdk.io/api/cosmos/gov/v1"
+	stakingv1beta1 "cosmossdk.io/api/cosmos/staking/v1beta1"
+	"cosmossdk.io/x/tx/signing/textual"
+)
+
+const (
+	voterAddress     = "cosmos1ulav3hsenupswqfkw2y3sup5kgtqwnvqa8eyhs"
+	validatorAddress = "cosmosvaloper1ghekyjucln7y67ntx7cf27m9dpuxxemnsvnaes"
+)
+
+// monikerRenderer renders a validator address along with its moniker.
+type monikerRenderer struct {
+	monikers map[string]string
+}
+
+func (vr monikerRenderer) Format(_ context.Context, v protoreflect.Value) ([]textual.Screen, error) {
+	return []textual.Screen{{Content: fmt.Sprintf("%s (%s)", vr.monikers[v.String()], v.String())}}, nil
+}
+
+func (vr monikerRenderer) Parse(_ context.Context, screens []textual.Screen) (protoreflect.Value, error) {
+	if len(screens) != 1 {
+		return protoreflect.Value{}, fmt.Errorf("expected 1 screen, got %d", len(screens))
+	}
+	content := screens[0].Content
+	start, end := strings.LastIndexByte(content, '('), strings.LastIndexByte(content, ')')
+	if start < 0 || end < start {
+		return protoreflect.Value{}, fmt.Errorf("invalid validator address screen %q", content)
+	}
+	return protoreflect.ValueOfString(content[start+1 : end]), nil
+}
+
+// voteRenderer renders a vote as a one-line summary.
+type voteRenderer struct{}
+
+func (voteRenderer) Format(_ context.Context, v protoreflect.Value) ([]textual.Screen, error) {
+	vote := &govv1.MsgVote{}
+	if err := copyMessage(v.Message().Interface(), vote); err != nil {
+		return nil, err
+	}
+	return []textual.Screen{
+		{Content: fmt.Sprintf("Vote %s on proposal #%d", vote.Option, vote.ProposalId)},
+		{Title: "Voter", Content: vote.Voter, Indent: 1},
+	}, nil
+}
+
+func (voteRenderer) Parse(_ context.Context, screens []textual.Screen) (protoreflect.Value, error) {
+	if len(screens) != 2 {
+		return protoreflect.Value{}, fmt.Errorf("expected 2 screens, got %d", len(screens))
+	}
+	var (
+		option     string
+		proposalID uint64
+	)
+	if _, err := fmt.Sscanf(screens[0].Content, "Vote %s on proposal #%d", &option, &proposalID); err != nil {
+		return protoreflect.Value{}, err
+	}
+	vote := &govv1.MsgVote{
+		ProposalId: proposalID,
+		Option:     govv1.VoteOption(govv1.VoteOption_value[option]),
+		Voter:      screens[1].Content,
+	}
+	return protoreflect.ValueOfMessage(vote.ProtoReflect()), nil
+}
+
+// lossyRenderer drops the voter, so it does not round trip.
+type lossyRenderer struct{ voteRenderer }
+
+func (lossyRenderer) Parse(context.Context, []textual.Screen) (protoreflect.Value, error) {
+	return protoreflect.ValueOfMessage((&govv1.MsgVote{}).ProtoReflect()), nil
+}
+
+func copyMessage(src, dst proto.Message) error {
+	bz, err := proto.Marshal(src)
+	if err != nil {
+		return err
+	}
+	return proto.Unmarshal(bz, dst)
+}
+
+func voteName() protoreflect.FullName {
+	return (&govv1.MsgVote{}).ProtoReflect().Descriptor().FullName()
+}
+
+func validatorRenderers() textual.CustomRenderers {
+	return textual.CustomRenderers{
+		Scalars: map[string]textual.ValueRendererCreator{
+			"cosmos.ValidatorAddressString": func(protoreflect.FieldDescriptor) textual.ValueRenderer {
+				return monikerRenderer{monikers: map[string]string{validatorAddress: "Cosmos Validator"}}
+			},
+		},
+		Examples: []proto.Message{&stakingv1beta1.MsgUndelegate{ValidatorAddress: validatorAddress}},
+	}
+}
+
+func voteRenderers(vr textual.ValueRenderer) textual.CustomRenderers {
+	return textual.CustomRenderers{
+		Messages: map[protoreflect.FullName]textual.MessageRendererCreator{
+			voteName(): func(*textual.SignModeHandler) textual.ValueRenderer { return vr },
+		},
+		Examples: []proto.Message{
+			&govv1.MsgVote{ProposalId: 7, Voter: voterAddress, Option: govv1.VoteOption_VOTE_OPTION_YES},
+		},
+	}
+}
+
+func TestCustomRenderers(t *testing.T) {
+	tr, err := textual.NewSignModeHandler(textual.SignModeOptions{
+		CoinMetadataQuerier: EmptyCoinMetadataQuerier,
+		CustomRenderers:     []textual.CustomRenderers{validatorRenderers(), voteRenderers(voteRenderer{})},
+	})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	undelegate := &stakingv1beta1.MsgUndelegate{
+		DelegatorAddress: voterAddress,
+		ValidatorAddress: validatorAddress,
+		Amount:           &basev1beta1.Coin{Denom: "uatom", Amount: "10"},
+	}
+	vr, err := tr.GetMessageValueRenderer(undelegate.ProtoReflect().Descriptor())
+	require.NoError(t, err)
+	screens, err := vr.Format(ctx, protoreflect.ValueOfMessage(undelegate.ProtoReflect()))
+	require.NoError(t, err)
+	require.Contains(t, screens, textual.Screen{Title: "Validator address", Content: "Cosmos Validator (" + validatorAddress + ")", Indent: 1})
+	require.NoError(t, tr.ValidateRoundTrip(ctx, undelegate))
+
+	vote := &govv1.MsgVote{ProposalId: 42, Voter: voterAddress, Option: govv1.VoteOption_VOTE_OPTION_NO}
+	vr, err = tr.GetMessageValueRenderer(vote.ProtoReflect().Descriptor())
+	require.NoError(t, err)
+	screens, err = vr.Format(ctx, protoreflect.ValueOfMessage(vote.ProtoReflect()))
+	require.NoError(t, err)
+	require.Equal(t, []textual.Screen{
+		{Content: "Vote VOTE_OPTION_NO on proposal #42"},
+		{Title: "Voter", Content: voterAddress, Indent: 1},
+	}, screens)
+	require.NoError(t, tr.ValidateRoundTrip(ctx, vote))
+}
+
+func TestCustomRenderersErrors(t *testing.T) {
+	anyName := protoreflect.FullName("google.protobuf.Any")
+
+	testCases := []struct {
+		name            string
+		customRenderers []textual.CustomRenderers
+		expErr          string
+	}{
+		{
+			name: "override built-in scalar",
+			customRenderers: []textual.CustomRenderers{{
+				Scalars: map[string]textual.ValueRendererCreator{
+					"cosmos.Int": func(protoreflect.FieldDescriptor) textual.ValueRenderer { return monikerRenderer{} },
+				},
+			}},
+			expErr: "cannot override the value renderer of the cosmos.Int scalar",
+		},
+		{
+			name: "override built-in message",
+			customRenderers: []textual.CustomRenderers{{
+				Messages: map[protoreflect.FullName]textual.MessageRendererCreator{
+					anyName: func(*textual.SignModeHandler) textual.ValueRenderer { return voteRenderer{} },
+				},
+			}},
+			expErr: "cannot override the value renderer of google.protobuf.Any",
+		},
+		{
+			name:            "registered twice",
+			customRenderers: []textual.CustomRenderers{voteRenderers(voteRenderer{}), voteRenderers(voteRenderer{})},
+			expErr:          "value renderer of cosmos.gov.v1.MsgVote registered twice",
+		},
+		{
+			name: "nil renderer",
+			customRenderers: []textual.CustomRenderers{{
+				Scalars: map[string]textual.ValueRendererCreator{"cosmos.ValidatorAddressString": nil},
+			}},
+			expErr: "custom scalar renderers must have a scalar name and a renderer",
+		},
+		{
+			name:            "example does not round trip",
+			customRenderers: []textual.CustomRenderers{voteRenderers(lossyRenderer{})},
+			expErr:          "cosmos.gov.v1.MsgVote does not round trip",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := textual.NewSignModeHandler(textual.SignModeOptions{
+				CoinMetadataQuerier: EmptyCoinMetadataQuerier,
+				CustomRenderers:     tc.customRenderers,
+			})
+			require.ErrorContains(t, err, tc.expErr)
+		})
+	}
+}
@@ -47,6 +47,11 @@ type SignModeOptions struct {
 	// TypeResolver are the protobuf type resolvers to use for resolving message
 	// types. If it is nil, then a dynamicpb will be used on top of FileResolver.
 	TypeResolver protoregistry.MessageTypeResolver
+
+	// CustomRenderers are the value renderers registered by modules for their
+	// own scalars and messages. Their examples are checked to round trip when
+	// creating the SignModeHandler.
+	CustomRenderers []CustomRenderers
 }
 
 // SignModeHandler holds the configuration for dispatching
@@ -85,6 +90,10 @@ func NewSignModeHandler(o SignModeOptions) (*SignModeHandler, error) {
 	}
 	t.init()
 
+	if err := t.registerCustomRenderers(o.CustomRenderers); err != nil {
+		return nil, err
+	}
+
 	return t, nil
 }
 
@@ -0,0 +1,285 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	dbm "github.com/cosmos/cosmos-db"
+	"github.com/spf13/cobra"
+
+	"cosmossdk.io/core/header"
+	"cosmossdk.io/log"
+	storetypes "cosmossdk.io/store/types"
+	"cosmossdk.io/x/upgrade/keeper"
+	"cosmossdk.io/x/upgrade/types"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/client/flags"
+	"github.com/cosmos/cosmos-sdk/server"
+	servertypes "github.com/cosmos/cosmos-sdk/server/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/version"
+)
+
+const (
+	FlagDryRunHeight   = "height"
+	FlagSnapshotHeight = "snapshot-height"
+	FlagSnapshotFormat = "snapshot-format"
+)
+
+// DryRunApp is an application whose upgrades can be rehearsed by the dry-run command.
+// Applications implementing RegisterInvariants(sdk.InvariantRegistry) get their invariants
+// asserted after the upgrade.
+type DryRunApp interface {
+	servertypes.Application
+
+	CommitMultiStore() storetypes.CommitMultiStore
+	LastBlockHeight() int64
+	ChainID() string
+	GetUpgradeKeeper() *keeper.Keeper
+}
+
+type invariantsRegisterer interface {
+	RegisterInvariants(sdk.InvariantRegistry)
+}
+
+// NewDryRunCmd returns a command rehearsing an upgrade on a temporary copy of the application state.
+func NewDryRunCmd[T servertypes.Application](appCreator servertypes.AppCreator[T]) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "upgrade-dry-run [name]",
+		Short: "Rehearse an upgrade on a temporary copy of the application state",
+		Long: `Rehearse an upgrade on a temporary copy of the application state.
+The upgrade handler and the module migrations it triggers are run in-process, and the per-module
+migration timings and gas, the size of the state changes and the broken invariants are reported.
+The copy is discarded afterwards, the node state is left untouched.
+
+The pending upgrade plan is rehearsed, unless an upgrade name is given. The node must be stopped,
+unless the state is restored from a local snapshot with --snapshot-height and --snapshot-format.
+The command fails when the upgrade fails or breaks invariants.`,
+		Example: fmt.Sprintf("%[1]s upgrade-dry-run\n%[1]s upgrade-dry-run v2 --snapshot-height 1000 --snapshot-format 3 --output json", version.AppName),
+		Args:    cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg := client.GetConfigFromCmd(cmd)
+			viper := client.GetViperFromCmd(cmd)
+
+			snapshotHeight, err := cmd.Flags().GetUint64(FlagSnapshotHeight)
+			if err != nil {
+				return err
+			}
+			snapshotFormat, err := cmd.Flags().GetUint32(FlagSnapshotFormat)
+			if err != nil {
+				return err
+			}
+
+			tmpDir, err := os.MkdirTemp("", "upgrade-dry-run")
+			if err != nil {
+				return err
+			}
+			defer os.RemoveAll(tmpDir)
+
+			// the state is copied, or restored from a snapshot, in a temporary data directory
+			dataDir := filepath.Join(tmpDir, "data")
+			if snapshotHeight == 0 {
+				if err := copyDir(filepath.Join(cfg.RootDir, "data", "application.db"), filepath.Join(dataDir, "application.db")); err != nil {
+					return fmt.Errorf("failed to copy the application state: %w", err)
+				}
+			}
+
+			db, err := dbm.NewDB("application", server.GetAppDBBackend(viper), dataDir)
+			if err != nil {
+				return err
+			}
+
+			// the streaming services of the node must not be started by the rehearsal
+			appOpts, err := server.WithoutStreaming(viper)
+			if err != nil {
+				return err
+			}
+
+			logger := log.NewNopLogger()
+			app, ok := any(appCreator(logger, db, nil, appOpts)).(DryRunApp)
+			if !ok {
+				return errors.New("the application does not support upgrade dry-runs")
+			}
+			defer app.Close()
+
+			if snapshotHeight > 0 {
+				if app.SnapshotManager() == nil {
+					return errors.New("snapshots are not enabled")
+				}
+				if err := app.SnapshotManager().RestoreLocalSnapshot(snapshotHeight, snapshotFormat); err != nil {
+					return fmt.Errorf("failed to restore snapshot: %w", err)
+				}
+			}
+
+			ctx := sdk.NewContext(app.CommitMultiStore(), false, logger).
+				WithChainID(app.ChainID()).
+				WithHeaderInfo(header.Info{ChainID: app.ChainID(), Height: app.LastBlockHeight(), Time: time.Now().UTC()})
+
+			plan, err := dryRunPlan(cmd, ctx, app, args)
+			if err != nil {
+				return err
+			}
+
+			var invariants types.Invariants
+			if registerer, ok := app.(invariantsRegisterer); ok {
+				registerer.RegisterInvariants(&invariants)
+			}
+
+			report, err := app.GetUpgradeKeeper().DryRunUpgrade(ctx, plan, invariants)
+			if err != nil {
+				return err
+			}
+
+			output, err := cmd.Flags().GetString(flags.FlagOutput)
+			if err != nil {
+				return err
+			}
+			if output == flags.OutputFormatJSON {
+				enc := json.NewEncoder(cmd.OutOrStdout())
+				enc.SetIndent("", "  ")
+				if err := enc.Encode(report); err != nil {
+					return err
+				}
+			} else {
+				printDryRunReport(cmd.OutOrStdout(), report)
+			}
+
+			switch {
+			case report.Error != "":
+				return fmt.Errorf("upgrade %s failed: %s", plan.Name, report.Error)
+			case len(report.InvariantBreaks) > 0:
+				return fmt.Errorf("upgrade %s broke %d invariant(s)", plan.Name, len(report.InvariantBreaks))
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().Int64(FlagDryRunHeight, 0, "Height of the upgrade when rehearsing an upgrade which is not scheduled (default: the next block height)")
+	cmd.Flags().Uint64(FlagSnapshotHeight, 0, "Height of the local snapshot to restore the state from, instead of the latest state")
+	cmd.Flags().Uint32(FlagSnapshotFormat, 0, "Format of the local snapshot to restore the state from")
+	cmd.Flags().StringP(flags.FlagOutput, "o", flags.OutputFormatText, "Output format (text|json)")
+
+	return cmd
+}
+
+// dryRunPlan returns the plan to rehearse: the pending plan, or a plan for the given upgrade name.
+func dryRunPlan(cmd *cobra.Command, ctx sdk.Context, app DryRunApp, args []string) (types.Plan, error) {
+	pending, err := app.GetUpgradeKeeper().GetUpgradePlan(ctx)
+	hasPending := err == nil
+	if err != nil && !errors.Is(err, types.ErrNoUpgradePlanFound) {
+		return types.Plan{}, err
+	}
+
+	if len(args) == 0 {
+		if !hasPending {
+			return types.Plan{}, errors.New("no upgrade is scheduled, provide the name of the upgrade to rehearse")
+		}
+		return pending, nil
+	}
+
+	if hasPending && pending.Name == args[0] {
+		return pending, nil
+	}
+
+	height, err := cmd.Flags().GetInt64(FlagDryRunHeight)
+	if err != nil {
+		return types.Plan{}, err
+	}
+	if height == 0 {
+		height = app.LastBlockHeight() + 1
+	}
+
+	return types.Plan{Name: args[0], Height: height}, nil
+}
+
+func printDryRunReport(out io.Writer, report types.DryRunReport) {
+	fmt.Fprintf(out, "Upgrade %s at height %d: %s, %d gas\n", report.Plan.Name, report.Plan.Height, report.Duration, report.GasUsed)
+	if report.Error != "" {
+		fmt.Fprintf(out, "Error: %s\n", report.Error)
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(out, "\nMigrations:")
+	if len(report.Migrations) == 0 {
+		fmt.Fprintln(out, "  none")
+	}
+	for _, m := range report.Migrations {
+		migration := fmt.Sprintf("v%d -> v%d", m.FromVersion, m.ToVersion)
+		if m.InitGenesis {
+			migration = fmt.Sprintf("init genesis v%d", m.ToVersion)
+		}
+		fmt.Fprintf(w, "  %s\t%s\t%s\t%d gas\t%s\n", m.Module, migration, m.Duration, m.GasUsed, m.Error)
+	}
+	w.Flush()
+
+	fmt.Fprintln(out, "\nState changes:")
+	if len(report.StateChanges) == 0 {
+		fmt.Fprintln(out, "  none")
+	}
+	for _, c := range report.StateChanges {
+		fmt.Fprintf(w, "  %s\t%d writes\t%d deletes\t%d bytes\n", c.Store, c.Writes, c.Deletes, c.Bytes)
+	}
+	w.Flush()
+
+	fmt.Fprintln(out, "\nInvariant breaks:")
+	if len(report.InvariantBreaks) == 0 {
+		fmt.Fprintln(out, "  none")
+	}
+	for _, b := range report.InvariantBreaks {
+		fmt.Fprintf(out, "  %s/%s: %s\n", b.Module, b.Route, b.Message)
+	}
+}
+
+// copyDir copies the files of the src directory to dst.
+func copyDir(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if d.IsDir() {
+			return os.MkdirAll(target, 0o755)
+		}
+
+		// the lock file of the database is not copied
+		if d.Name() == "LOCK" {
+			return nil
+		}
+
+		return copyFile(path, target)
+	})
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+
+	return out.Close()
+}
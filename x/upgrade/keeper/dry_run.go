@@ -0,0 +1,197 @@
+package keeper
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	storetypes "cosmossdk.io/store/types"
+	"cosmossdk.io/x/upgrade/types"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/module"
+)
+
+// DryRunUpgrade rehearses the upgrade of the given plan: it runs the plan's upgrade handler,
+// and the module migrations it triggers, as at the plan height, on a branch of the state of ctx.
+// The branch is discarded, so the state is left untouched. The invariants are asserted on
+// the upgraded state.
+//
+// An error is returned when the upgrade cannot be rehearsed, e.g. when no handler is registered
+// for the plan. A failing or panicking upgrade handler is reported in the returned report instead.
+func (k Keeper) DryRunUpgrade(ctx context.Context, plan types.Plan, invariants types.Invariants) (types.DryRunReport, error) {
+	report := types.DryRunReport{
+		Plan:            plan,
+		Migrations:      []module.MigrationReport{},
+		StateChanges:    []types.StoreChanges{},
+		InvariantBreaks: []types.InvariantBreak{},
+	}
+	if !k.HasHandler(plan.Name) {
+		return report, fmt.Errorf("no upgrade handler registered for %s", plan.Name)
+	}
+
+	sdkCtx := sdk.UnwrapSDKContext(ctx)
+	info := sdkCtx.HeaderInfo()
+	info.Height = plan.Height
+	sdkCtx = sdkCtx.WithBlockHeight(plan.Height).
+		WithHeaderInfo(info).
+		WithGasMeter(storetypes.NewInfiniteGasMeter()).
+		WithEventManager(sdk.NewEventManager())
+
+	// the upgrade runs on a cache of a traced branch of the state, so that the changes
+	// of the upgrade are traced when the cache is written to the branch
+	branch := sdkCtx.MultiStore().CacheMultiStore()
+	changes := newStoreChangesCounter()
+	upgradeStore := branch.SetTracer(changes).CacheMultiStore()
+
+	upgradeCtx := module.WithMigrationObserver(sdkCtx.WithMultiStore(upgradeStore), func(migration module.MigrationReport) {
+		report.Migrations = append(report.Migrations, migration)
+	})
+
+	start := time.Now()
+	err := k.dryRunApplyUpgrade(upgradeCtx, plan)
+	report.Duration = time.Since(start)
+	report.GasUsed = upgradeCtx.GasMeter().GasConsumed()
+	if err != nil {
+		report.Error = err.Error()
+		return report, nil
+	}
+
+	upgradeStore.Write()
+	report.StateChanges = changes.changes()
+
+	invariantCtx := sdkCtx.WithMultiStore(branch.CacheMultiStore())
+	for _, invariant := range invariants {
+		if msg, broken := assertInvariant(invariantCtx, invariant.Invariant); broken {
+			report.InvariantBreaks = append(report.InvariantBreaks, types.InvariantBreak{
+				Module:  invariant.Module,
+				Route:   invariant.Route,
+				Message: msg,
+			})
+		}
+	}
+
+	return report, nil
+}
+
+// dryRunApplyUpgrade applies the upgrade, a panic of the upgrade handler being returned as
+// an error.
+func (k Keeper) dryRunApplyUpgrade(ctx context.Context, plan types.Plan) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("upgrade handler panicked: %v", r)
+		}
+	}()
+
+	return k.ApplyUpgrade(ctx, plan)
+}
+
+// assertInvariant asserts an invariant, a panic counting as a broken invariant.
+func assertInvariant(ctx sdk.Context, invariant sdk.Invariant) (msg string, broken bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			msg, broken = fmt.Sprintf("invariant panicked: %v", r), true
+		}
+	}()
+
+	return invariant(ctx)
+}
+
+// storeChangesCounter counts the writes and deletes of the store traces, written as
+// JSON lines by the tracing KV stores.
+type storeChangesCounter struct {
+	buf    []byte
+	stores map[string]*types.StoreChanges
+}
+
+func newStoreChangesCounter() *storeChangesCounter {
+	return &storeChangesCounter{stores: map[string]*types.StoreChanges{}}
+}
+
+// traceOperation is a traced KV store operation.
+type traceOperation struct {
+	Operation string         `json:"operation"`
+	Key       string         `json:"key"`
+	Value     string         `json:"value"`
+	Metadata  map[string]any `json:"metadata"`
+}
+
+// Write implements io.Writer.
+func (c *storeChangesCounter) Write(p []byte) (int, error) {
+	c.buf = append(c.buf, p...)
+	for {
+		i := bytes.IndexByte(c.buf, '\n')
+		if i < 0 {
+			return len(p), nil
+		}
+
+		line := c.buf[:i]
+		c.buf = c.buf[i+1:]
+		if err := c.count(line); err != nil {
+			return 0, err
+		}
+	}
+}
+
+var (
+	writeOperation  = []byte(`"operation":"write"`)
+	deleteOperation = []byte(`"operation":"delete"`)
+)
+
+func (c *storeChangesCounter) count(line []byte) error {
+	// skip the reads without decoding them
+	if !bytes.Contains(line, writeOperation) && !bytes.Contains(line, deleteOperation) {
+		return nil
+	}
+
+	var op traceOperation
+	if err := json.Unmarshal(line, &op); err != nil {
+		return fmt.Errorf("invalid store trace: %w", err)
+	}
+
+	if op.Operation != "write" && op.Operation != "delete" {
+		return nil
+	}
+
+	storeName, _ := op.Metadata["store_name"].(string)
+	if storeName == "" {
+		return errors.New("store trace without store name")
+	}
+
+	changes, ok := c.stores[storeName]
+	if !ok {
+		changes = &types.StoreChanges{Store: storeName}
+		c.stores[storeName] = changes
+	}
+
+	if op.Operation == "write" {
+		changes.Writes++
+	} else {
+		changes.Deletes++
+	}
+	changes.Bytes += decodedLen(op.Key) + decodedLen(op.Value)
+	return nil
+}
+
+// decodedLen returns the length of base64 encoded data.
+func decodedLen(s string) int {
+	bz, _ := base64.StdEncoding.DecodeString(s)
+	return len(bz)
+}
+
+// changes returns the changes of each store, sorted by store name.
+func (c *storeChangesCounter) changes() []types.StoreChanges {
+	res := make([]types.StoreChanges, 0, len(c.stores))
+	for _, changes := range c.stores {
+		res = append(res, *changes)
+	}
+
+	sort.Slice(res, func(i, j int) bool { return res[i].Store < res[j].Store })
+	return res
+}
+
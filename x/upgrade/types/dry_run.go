@@ -0,0 +1,58 @@
+package types
+
+import (
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/module"
+)
+
+// DryRunReport is the report of the dry-run of an upgrade, see Keeper.DryRunUpgrade.
+type DryRunReport struct {
+	Plan Plan `json:"plan"`
+	// Duration and GasUsed cover the whole upgrade, i.e. the upgrade handler and the upgrade bookkeeping.
+	Duration time.Duration `json:"duration"`
+	GasUsed  uint64        `json:"gas_used"`
+	// Migrations are the module migrations run by the upgrade handler, in order.
+	Migrations []module.MigrationReport `json:"migrations"`
+	// StateChanges are the changes of the upgrade to each store, sorted by store name.
+	StateChanges []StoreChanges `json:"state_changes"`
+	// InvariantBreaks are the invariants broken after the upgrade.
+	InvariantBreaks []InvariantBreak `json:"invariant_breaks"`
+	// Error is the error of the upgrade handler, if it failed.
+	Error string `json:"error,omitempty"`
+}
+
+// StoreChanges counts the changes of an upgrade to a store.
+type StoreChanges struct {
+	Store   string `json:"store"`
+	Writes  int    `json:"writes"`
+	Deletes int    `json:"deletes"`
+	// Bytes is the size of the written keys and values, and of the deleted keys.
+	Bytes int `json:"bytes"`
+}
+
+// InvariantBreak is an invariant broken after the upgrade.
+type InvariantBreak struct {
+	Module  string `json:"module"`
+	Route   string `json:"route"`
+	Message string `json:"message"`
+}
+
+// Invariant is an invariant asserted after the dry-run of an upgrade.
+type Invariant struct {
+	Module    string
+	Route     string
+	Invariant sdk.Invariant
+}
+
+// Invariants collects invariants, it implements sdk.InvariantRegistry
+// so that it can be passed to module.Manager.RegisterInvariants.
+type Invariants []Invariant
+
+var _ sdk.InvariantRegistry = (*Invariants)(nil)
+
+// RegisterRoute implements sdk.InvariantRegistry.
+func (i *Invariants) RegisterRoute(moduleName, route string, invar sdk.Invariant) {
+	*i = append(*i, Invariant{Module: moduleName, Route: route, Invariant: invar})
+}